@@ -7,6 +7,7 @@ package blockchain
 import (
 	"fmt"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/database"
 	"github.com/btcsuite/btcd/btcutil"
 )
@@ -17,11 +18,14 @@ import (
 // before adding it.  The block is expected to have already gone through
 // ProcessBlock before calling this function with it.
 //
+// The proof-of-work hash of the block, when it has been calculated and
+// verified, is cached in the block index along with the block.  It may be nil.
+//
 // The flags are also passed to checkBlockContext and connectBestChain.  See
 // their documentation for how the flags modify their behavior.
 //
 // This function MUST be called with the chain state lock held (for writes).
-func (b *BlockChain) maybeAcceptBlock(block *btcutil.Block, flags BehaviorFlags) (bool, error) {
+func (b *BlockChain) maybeAcceptBlock(block *btcutil.Block, powHash *chainhash.Hash, flags BehaviorFlags) (bool, error) {
 	// The height of this block is one more than the referenced previous
 	// block.
 	prevHash := &block.MsgBlock().Header.PrevBlock
@@ -66,6 +70,10 @@ func (b *BlockChain) maybeAcceptBlock(block *btcutil.Block, flags BehaviorFlags)
 	blockHeader := &block.MsgBlock().Header
 	newNode := newBlockNode(blockHeader, prevNode)
 	newNode.status = statusDataStored
+	if powHash != nil {
+		newNode.powHash = *powHash
+		newNode.status |= statusPowHashKnown
+	}
 
 	b.index.AddNode(newNode)
 	err = b.index.flushToDB()
@@ -0,0 +1,105 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// assumeValidBurial is the minimum amount of time the network is expected to
+// take to produce the work of the best known chain on top of a block before its
+// scripts are assumed to be valid.  It gives the network time to notice an
+// invalid assumed valid block.
+const assumeValidBurial = time.Hour * 24 * 7 * 2
+
+// AssumeValid returns the hash of the block whose ancestors are assumed to have
+// valid scripts.  It is nil when the scripts of all blocks are validated.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) AssumeValid() *chainhash.Hash {
+	return b.assumeValid
+}
+
+// HasMinimumChainWork returns whether or not the passed amount of cumulative
+// work is at least the minimum chain work required by the passed network
+// parameters before script validation is skipped for the ancestors of the
+// assumed valid block.
+func HasMinimumChainWork(workSum *big.Int, params *chaincfg.Params) bool {
+	return params.MinimumChainWork == nil ||
+		workSum.Cmp(params.MinimumChainWork) >= 0
+}
+
+// HasAssumeValidBurial returns whether or not the passed amount of work on top
+// of a block is deep enough for the scripts of the block to be assumed valid.
+// The work is measured in the time the network is expected to take to produce
+// it at the difficulty given by the passed bits of the best known header, and
+// must be at least two weeks.
+func HasAssumeValidBurial(work *big.Int, bits uint32, params *chaincfg.Params) bool {
+	blockWork := CalcWork(bits)
+	if blockWork.Sign() <= 0 {
+		return false
+	}
+	targetTimePerBlock := int64(params.TargetTimePerBlock / time.Second)
+	equivalentTime := new(big.Int).Mul(work, big.NewInt(targetTimePerBlock))
+	equivalentTime.Div(equivalentTime, blockWork)
+	minTime := big.NewInt(int64(assumeValidBurial / time.Second))
+	return equivalentTime.Cmp(minTime) >= 0
+}
+
+// bestHeader returns the block node with the most cumulative work in the block
+// index that is not known to be invalid.  It is the best chain tip when there
+// are no known blocks with more work.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) bestHeader() *blockNode {
+	best := b.bestChain.Tip()
+	for _, tip := range b.index.Tips() {
+		if b.index.NodeStatus(tip).KnownInvalid() {
+			continue
+		}
+		if tip.workSum.Cmp(best.workSum) > 0 {
+			best = tip
+		}
+	}
+	return best
+}
+
+// isAssumedValid returns whether or not the scripts of the passed block do not
+// need to be validated because it is an ancestor of the assumed valid block.
+// That is the case when the caller has already determined as much from the
+// headers leading to the assumed valid block and beyond as indicated by the
+// BFAssumeValid flag, or when the assumed valid block is in the block index and
+// descends from the block, and the best known header descends from the assumed
+// valid block, has the minimum chain work and buries the block deeply enough.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) isAssumedValid(node *blockNode, flags BehaviorFlags) bool {
+	if b.assumeValid == nil {
+		return false
+	}
+	if flags&BFAssumeValid == BFAssumeValid {
+		return true
+	}
+
+	assumeValidNode := b.index.LookupNode(b.assumeValid)
+	if assumeValidNode == nil ||
+		assumeValidNode.Ancestor(node.height) != node {
+
+		return false
+	}
+
+	bestHeader := b.bestHeader()
+	if bestHeader.Ancestor(assumeValidNode.height) != assumeValidNode ||
+		!HasMinimumChainWork(bestHeader.workSum, b.chainParams) {
+
+		return false
+	}
+	work := new(big.Int).Sub(bestHeader.workSum, node.workSum)
+	return HasAssumeValidBurial(work, bestHeader.bits, b.chainParams)
+}
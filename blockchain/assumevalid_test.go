@@ -0,0 +1,141 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestIsAssumedValid ensures script validation is only skipped for ancestors of
+// the assumed valid block that are buried deeply enough by the best known
+// header with enough work, or blocks flagged as such.
+func TestIsAssumedValid(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain := newFakeChain(&params)
+	genesis := chain.bestChain.Genesis()
+
+	// fakeNodes returns a chain of the passed number of nodes with the
+	// passed bits after the passed parent.
+	fakeNodes := func(parent *blockNode, numNodes int, bits uint32) []*blockNode {
+		nodes := make([]*blockNode, 0, numNodes)
+		blockTime := parent.Header().Timestamp
+		for i := 0; i < numNodes; i++ {
+			blockTime = blockTime.Add(params.TargetTimePerBlock)
+			parent = newFakeNode(parent, 4, bits, blockTime)
+			nodes = append(nodes, parent)
+		}
+		return nodes
+	}
+
+	// Every block has the same work, so two weeks of work is 2016 regtest
+	// blocks.
+	nodes := fakeNodes(genesis, 2031, params.PowLimitBits)
+	sideNodes := fakeNodes(nodes[4], 3, params.PowLimitBits)
+	for _, node := range append(nodes[:21], sideNodes...) {
+		chain.index.AddNode(node)
+	}
+	chain.bestChain.SetTip(nodes[20])
+
+	// Nothing is assumed valid without an assumed valid block.
+	if chain.isAssumedValid(nodes[0], BFAssumeValid) {
+		t.Fatal("isAssumedValid: block assumed valid without an assumed " +
+			"valid block")
+	}
+
+	// Nothing is assumed valid before the best header buries the blocks
+	// deeply enough.
+	const assumeValidIdx = 16
+	chain.assumeValid = &nodes[assumeValidIdx].hash
+	if chain.isAssumedValid(nodes[0], BFNone) {
+		t.Fatal("isAssumedValid: block assumed valid without being " +
+			"buried")
+	}
+
+	// Only the ancestors of the assumed valid block with two weeks of work
+	// on top of them are assumed valid once the best header is at height
+	// 2031.
+	for _, node := range nodes[21:] {
+		chain.index.AddNode(node)
+	}
+	for i, node := range nodes {
+		got := chain.isAssumedValid(node, BFNone)
+		if want := i <= 14; got != want {
+			t.Fatalf("isAssumedValid #%d: unexpected result - got %v, "+
+				"want %v", i, got, want)
+		}
+	}
+	for i, node := range sideNodes {
+		if chain.isAssumedValid(node, BFNone) {
+			t.Fatalf("isAssumedValid: side chain block #%d assumed "+
+				"valid", i)
+		}
+	}
+
+	// Blocks the caller already knows to be ancestors are assumed valid
+	// even though the assumed valid block is not known yet.
+	if !chain.isAssumedValid(tstTip(nodes), BFAssumeValid) {
+		t.Fatal("isAssumedValid: flagged block not assumed valid")
+	}
+
+	// The best header must descend from the assumed valid block unless it
+	// is known to be invalid.
+	heavyNodes := fakeNodes(nodes[5], 2, 0x1d00ffff)
+	for _, node := range heavyNodes {
+		chain.index.AddNode(node)
+	}
+	if chain.isAssumedValid(nodes[0], BFNone) {
+		t.Fatal("isAssumedValid: block assumed valid when the best " +
+			"header does not descend from the assumed valid block")
+	}
+	chain.index.SetStatusFlags(tstTip(heavyNodes), statusValidateFailed)
+	if !chain.isAssumedValid(nodes[0], BFNone) {
+		t.Fatal("isAssumedValid: block not assumed valid when the " +
+			"best header descending elsewhere is invalid")
+	}
+
+	// The best header must have the minimum chain work.
+	params.MinimumChainWork = new(big.Int).Add(tstTip(nodes).workSum,
+		big.NewInt(1))
+	if chain.isAssumedValid(nodes[0], BFNone) {
+		t.Fatal("isAssumedValid: block assumed valid without the " +
+			"minimum chain work")
+	}
+}
+
+// TestHasAssumeValidBurial ensures the work on top of a block is converted to
+// the time the network is expected to take to produce it.
+func TestHasAssumeValidBurial(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.MainNetParams
+	const bits = 0x1b0404cb
+	blockWork := CalcWork(bits)
+	twoWeeks := int64(time.Hour * 24 * 14 / params.TargetTimePerBlock)
+
+	tests := []struct {
+		name      string
+		numBlocks int64
+		bits      uint32
+		want      bool
+	}{
+		{"no work", 0, bits, false},
+		{"one block short", twoWeeks - 1, bits, false},
+		{"two weeks", twoWeeks, bits, true},
+		{"no difficulty", twoWeeks, 0, false},
+	}
+
+	for _, test := range tests {
+		work := new(big.Int).Mul(blockWork, big.NewInt(test.numBlocks))
+		got := HasAssumeValidBurial(work, test.bits, params)
+		if got != test.want {
+			t.Errorf("%s: unexpected result - got %v, want %v",
+				test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// bitcoinBlockDataNet is the network of the Bitcoin main network blocks
+	// in the test data.
+	bitcoinBlockDataNet = wire.BitcoinNet(0xd9b4bef9)
+
+	// testPowSHA256d identifies the double SHA-256 proof-of-work hash of
+	// Bitcoin.  It is registered under an identifier which no network
+	// uses so the Bitcoin blocks in the test data can be processed.
+	testPowSHA256d = wire.PowAlgorithm(0xff)
+)
+
+func init() {
+	wire.RegisterPowHashFunc(testPowSHA256d, func(header []byte) (chainhash.Hash, error) {
+		return chainhash.DoubleHashH(header), nil
+	})
+}
+
+var (
+	// bitcoinPowLimit is the highest proof of work value a block can have
+	// on the Bitcoin main network.  It is the value 2^224 - 1.
+	bitcoinPowLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, 224), bigOne)
+
+	// bitcoinGenesisBlock is the genesis block of the Bitcoin main network.
+	bitcoinGenesisBlock = wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    1,
+			PrevBlock:  chainhash.Hash{},
+			MerkleRoot: *newHashFromStr("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"),
+			Timestamp:  time.Unix(1231006505, 0), // 2009-01-03 18:15:05 +0000 UTC
+			Bits:       0x1d00ffff,               // 486604799 [00000000ffff0000000000000000000000000000000000000000000000000000]
+			Nonce:      0x7c2bac1d,               // 2083236893
+		},
+		Transactions: []*wire.MsgTx{{
+			Version: 1,
+			TxIn: []*wire.TxIn{{
+				PreviousOutPoint: wire.OutPoint{
+					Hash:  chainhash.Hash{},
+					Index: 0xffffffff,
+				},
+				SignatureScript: hexToBytes("04ffff001d010445" +
+					"5468652054696d65732030332f4a616e2f" +
+					"32303039204368616e63656c6c6f72206f" +
+					"6e206272696e6b206f66207365636f6e64" +
+					"206261696c6f757420666f72206261686b73"),
+				Sequence: 0xffffffff,
+			}},
+			TxOut: []*wire.TxOut{{
+				Value: 0x12a05f200,
+				PkScript: hexToBytes("4104678afdb0fe5548271967f1" +
+					"a67130b7105cd6a828e03909a67962e0ea1f" +
+					"61deb649f6bc3f4cef38c4f35504e51ec138" +
+					"c4f35504e51ec112de5c384df7ba0b8d578a" +
+					"4c702b6bf11d5fac"),
+			}},
+			LockTime: 0,
+		}},
+	}
+
+	// bitcoinMainNetParams defines the parameters of the Bitcoin main
+	// network which are needed to process the Bitcoin blocks in the test
+	// data.  Those blocks predate any of the Vertcoin networks, so they
+	// can't be processed with the chaincfg parameters.
+	bitcoinMainNetParams = chaincfg.Params{
+		Name:        "bitcoin-mainnet",
+		Net:         bitcoinBlockDataNet,
+		DefaultPort: "8333",
+
+		// Chain parameters
+		GenesisBlock:             &bitcoinGenesisBlock,
+		GenesisHash:              newHashFromStr("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"),
+		PowLimit:                 bitcoinPowLimit,
+		PowLimitBits:             0x1d00ffff,
+		BIP0034Height:            227931,
+		BIP0065Height:            388381,
+		BIP0066Height:            363725,
+		CoinbaseMaturity:         100,
+		SubsidyReductionInterval: 210000,
+		TargetTimespan:           time.Hour * 24 * 14, // 14 days
+		TargetTimePerBlock:       time.Minute * 10,    // 10 minutes
+		RetargetAdjustmentFactor: 4,                   // 25% less, 400% more
+		ReduceMinDifficulty:      false,
+		MinDiffReductionTime:     0,
+		GenerateSupported:        false,
+
+		DifficultyForks: []chaincfg.DifficultyFork{
+			{Height: 0, Algorithm: chaincfg.DiffBitcoin},
+		},
+		PowForks: []chaincfg.PowFork{
+			{Height: 0, Algorithm: testPowSHA256d},
+		},
+
+		// Consensus rule change deployments.
+		RuleChangeActivationThreshold: 1916, // 95% of MinerConfirmationWindow
+		MinerConfirmationWindow:       2016,
+		Deployments: [chaincfg.DefinedDeployments]chaincfg.ConsensusDeployment{
+			chaincfg.DeploymentTestDummy: {
+				BitNumber:  28,
+				StartTime:  1199145601, // January 1, 2008 UTC
+				ExpireTime: 1230767999, // December 31, 2008 UTC
+			},
+			chaincfg.DeploymentCSV: {
+				BitNumber:  0,
+				StartTime:  1462060800, // May 1st, 2016
+				ExpireTime: 1493596800, // May 1st, 2017
+			},
+			chaincfg.DeploymentSegwit: {
+				BitNumber:  1,
+				StartTime:  1479168000, // November 15, 2016 UTC
+				ExpireTime: 1510704000, // November 15, 2017 UTC.
+			},
+			chaincfg.DeploymentTaproot: {
+				BitNumber:  2,
+				StartTime:  1619222400, // April 24th, 2021 UTC.
+				ExpireTime: 1628640000, // August 11th, 2021 UTC.
+			},
+		},
+
+		// Address encoding magics
+		PubKeyHashAddrID: 0x00, // starts with 1
+		ScriptHashAddrID: 0x05, // starts with 3
+		PrivateKeyID:     0x80, // starts with 5 (uncompressed) or K (compressed)
+	}
+)
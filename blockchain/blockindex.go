@@ -33,6 +33,16 @@ const (
 	// has failed validation, thus the block is also invalid.
 	statusInvalidAncestor
 
+	// statusPowHashKnown indicates that the proof-of-work hash of the block
+	// header has been calculated and verified against its target, and that
+	// it is cached in the block node so it never needs to be recalculated.
+	statusPowHashKnown
+
+	// statusPrecious indicates that the block has been marked as precious,
+	// and that the sequence number of the most recent time it was marked
+	// is stored in the block node.
+	statusPrecious
+
 	// statusNone indicates that the block has no validation state flags set.
 	//
 	// NOTE: This must be defined last in order to avoid influencing iota.
@@ -60,6 +70,17 @@ func (status blockStatus) KnownInvalid() bool {
 	return status&(statusValidateFailed|statusInvalidAncestor) != 0
 }
 
+// PowHashKnown returns whether the verified proof-of-work hash of the block
+// header is cached in the block node.
+func (status blockStatus) PowHashKnown() bool {
+	return status&statusPowHashKnown != 0
+}
+
+// Precious returns whether the block has been marked as precious.
+func (status blockStatus) Precious() bool {
+	return status&statusPrecious != 0
+}
+
 // blockNode represents a block within the block chain and is primarily used to
 // aid in selecting the best chain to be the main chain.  The main chain is
 // stored into the block database.
@@ -94,11 +115,24 @@ type blockNode struct {
 	timestamp  int64
 	merkleRoot chainhash.Hash
 
+	// powHash is the proof-of-work hash of the block header.  It is only
+	// valid when the statusPowHashKnown flag is set and, like the status
+	// field, it may be written to and so should only be accessed using the
+	// concurrent-safe PowHash method on blockIndex once the node has been
+	// added to the global index.
+	powHash chainhash.Hash
+
 	// status is a bitfield representing the validation state of the block. The
 	// status field, unlike the other fields, may be written to and so should
 	// only be accessed using the concurrent-safe NodeStatus method on
 	// blockIndex once the node has been added to the global index.
 	status blockStatus
+
+	// preciousSeq is the sequence number of the most recent time the block
+	// was marked as precious.  It is only valid when the statusPrecious
+	// flag is set and, like the status field, should only be accessed
+	// using the concurrent-safe methods on blockIndex.
+	preciousSeq uint32
 }
 
 // initBlockNode initializes a block node from the given header and parent node,
@@ -232,6 +266,14 @@ type blockIndex struct {
 	sync.RWMutex
 	index map[chainhash.Hash]*blockNode
 	dirty map[*blockNode]struct{}
+
+	// tips houses all of the nodes in the index which do not have any
+	// children, that is the tips of the main chain and of all side chains.
+	tips map[*blockNode]struct{}
+
+	// lastPreciousSeq is the sequence number of the node most recently
+	// marked as precious.
+	lastPreciousSeq uint32
 }
 
 // newBlockIndex returns a new empty instance of a block index.  The index will
@@ -243,6 +285,7 @@ func newBlockIndex(db database.DB, chainParams *chaincfg.Params) *blockIndex {
 		chainParams: chainParams,
 		index:       make(map[chainhash.Hash]*blockNode),
 		dirty:       make(map[*blockNode]struct{}),
+		tips:        make(map[*blockNode]struct{}),
 	}
 }
 
@@ -279,11 +322,30 @@ func (bi *blockIndex) AddNode(node *blockNode) {
 }
 
 // addNode adds the provided node to the block index, but does not mark it as
-// dirty. This can be used while initializing the block index.
+// dirty. This can be used while initializing the block index.  The node becomes
+// a tip in place of its parent, so nodes must be added after their parents.
 //
 // This function is NOT safe for concurrent access.
 func (bi *blockIndex) addNode(node *blockNode) {
 	bi.index[node.hash] = node
+	delete(bi.tips, node.parent)
+	bi.tips[node] = struct{}{}
+	if node.status.Precious() && node.preciousSeq > bi.lastPreciousSeq {
+		bi.lastPreciousSeq = node.preciousSeq
+	}
+}
+
+// Tips returns all block nodes in the index which do not have any children.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) Tips() []*blockNode {
+	bi.RLock()
+	tips := make([]*blockNode, 0, len(bi.tips))
+	for n := range bi.tips {
+		tips = append(tips, n)
+	}
+	bi.RUnlock()
+	return tips
 }
 
 // NodeStatus provides concurrent-safe access to the status field of a node.
@@ -319,6 +381,69 @@ func (bi *blockIndex) UnsetStatusFlags(node *blockNode, flags blockStatus) {
 	bi.Unlock()
 }
 
+// PowHash returns the cached proof-of-work hash of the block node along with
+// whether or not it is known.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) PowHash(node *blockNode) (chainhash.Hash, bool) {
+	bi.RLock()
+	powHash, known := node.powHash, node.status.PowHashKnown()
+	bi.RUnlock()
+	return powHash, known
+}
+
+// SetPowHash caches the provided verified proof-of-work hash in the block node
+// and marks it dirty so the hash is persisted with the block index.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) SetPowHash(node *blockNode, powHash *chainhash.Hash) {
+	bi.Lock()
+	node.powHash = *powHash
+	node.status |= statusPowHashKnown
+	bi.dirty[node] = struct{}{}
+	bi.Unlock()
+}
+
+// SetPrecious marks the block node as precious with a sequence number higher
+// than that of any other node marked before, so it is preferred over them, and
+// marks it dirty so the sequence number is persisted with the block index.
+// Nothing is done when the node is already the most recently marked one.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) SetPrecious(node *blockNode) {
+	bi.Lock()
+	if !node.status.Precious() || node.preciousSeq != bi.lastPreciousSeq {
+		bi.lastPreciousSeq++
+		node.preciousSeq = bi.lastPreciousSeq
+		node.status |= statusPrecious
+		bi.dirty[node] = struct{}{}
+	}
+	bi.Unlock()
+}
+
+// Preferred returns whether the first passed block node should be the tip of
+// the main chain rather than the second one.  That is the case when it has
+// more cumulative work, or the same work and it was marked as precious more
+// recently.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) Preferred(node, other *blockNode) bool {
+	if cmp := node.workSum.Cmp(other.workSum); cmp != 0 {
+		return cmp > 0
+	}
+
+	bi.RLock()
+	var nodeSeq, otherSeq uint32
+	if node.status.Precious() {
+		nodeSeq = node.preciousSeq
+	}
+	if other.status.Precious() {
+		otherSeq = other.preciousSeq
+	}
+	bi.RUnlock()
+	return nodeSeq > otherSeq
+}
+
 // flushToDB writes all dirty block nodes to the database. If all writes
 // succeed, this clears the dirty set.
 func (bi *blockIndex) flushToDB() error {
@@ -8,6 +8,7 @@ package blockchain
 import (
 	"container/list"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -23,6 +24,16 @@ const (
 	// maxOrphanBlocks is the maximum number of orphan blocks that can be
 	// queued.
 	maxOrphanBlocks = 100
+
+	// maxHeaderPowHashes is the maximum number of proof-of-work hashes of
+	// block headers verified by VerifyHeaderProofOfWork which are kept
+	// until their blocks are processed.  The hashes are only kept in
+	// memory, roughly 30 MiB at the limit, so the least recently used one
+	// is evicted once it is reached.  The hash of an evicted header is
+	// simply calculated again when its block is processed, which only
+	// happens when a headers-first sync runs further ahead of the block
+	// downloads than the limit.
+	maxHeaderPowHashes = 250000
 )
 
 // BlockLocator is used to help locate a specific block.  The algorithm for
@@ -43,10 +54,23 @@ type BlockLocator []*chainhash.Hash
 
 // orphanBlock represents a block that we don't yet have the parent for.  It
 // is a normal block plus an expiration time to prevent caching the orphan
-// forever.
+// forever.  The proof-of-work hash which satisfied the target of the block,
+// and the algorithm it was calculated with, are kept so it does not need to be
+// calculated again when the parent turns out to be at the expected height.
 type orphanBlock struct {
 	block      *btcutil.Block
 	expiration time.Time
+	powAlgo    wire.PowAlgorithm
+	powHash    *chainhash.Hash
+}
+
+// headerPowHash is a proof-of-work hash of a block header which was verified
+// ahead of its block, along with the height it was verified for since the
+// algorithm depends on it.
+type headerPowHash struct {
+	hash    chainhash.Hash
+	height  int32
+	powHash chainhash.Hash
 }
 
 // BestState houses information about the current best block and other info
@@ -101,6 +125,8 @@ type BlockChain struct {
 	sigCache            *txscript.SigCache
 	indexManager        IndexManager
 	hashCache           *txscript.HashCache
+	pruneTarget         uint64
+	assumeValid         *chainhash.Hash
 
 	// The following fields are calculated based upon the provided chain
 	// parameters.  They are also set when the instance is created and
@@ -126,6 +152,30 @@ type BlockChain struct {
 	index     *blockIndex
 	bestChain *chainView
 
+	// utxoCache caches the utxo set in front of the database.  It has its
+	// own lock, however it is only modified while the chain lock is held
+	// for writes, with the exception of loading entries into it.
+	utxoCache *utxoCache
+
+	// utxoStats tracks statistics about the utxo set as of the end of the
+	// main chain.  It is replaced with an updated copy whenever a block is
+	// connected or disconnected.
+	utxoStats *utxoStats
+
+	// snapshot tracks the validation of the blocks below a loaded utxo
+	// snapshot.  It is nil when no snapshot is being validated.
+	snapshot *snapshotValidation
+
+	// snapshotInvalid is set once the blocks below a loaded utxo snapshot
+	// were found not to match it, after which no more blocks are
+	// processed.
+	snapshotInvalid bool
+
+	// pruneRetryHeight is the height the best chain must reach before
+	// pruning is attempted again after it was held back by blocks which
+	// were not deep enough to be deleted yet.
+	pruneRetryHeight int32
+
 	// These fields are related to handling of orphan blocks.  They are
 	// protected by a combination of the chain lock and the orphan lock.
 	orphanLock   sync.RWMutex
@@ -133,6 +183,16 @@ type BlockChain struct {
 	prevOrphans  map[chainhash.Hash][]*orphanBlock
 	oldestOrphan *orphanBlock
 
+	// headerPowHashes houses the proof-of-work hashes of block headers
+	// which were verified before their blocks are processed, such as
+	// during a headers-first sync, so they are not calculated again.  The
+	// list orders them from the most to the least recently used one, which
+	// is evicted first once there are maxHeaderPowHashes of them.  Both
+	// are protected by the header pow hash lock.
+	headerPowHashLock  sync.Mutex
+	headerPowHashes    map[chainhash.Hash]*list.Element
+	headerPowHashesLRU *list.List // Contains *headerPowHash entries.
+
 	// These fields are related to checkpoint handling.  They are protected
 	// by the chain lock.
 	nextCheckpoint *chaincfg.Checkpoint
@@ -282,7 +342,10 @@ func (b *BlockChain) removeOrphanBlock(orphan *orphanBlock) {
 // It also imposes a maximum limit on the number of outstanding orphan
 // blocks and will remove the oldest received orphan block if the limit is
 // exceeded.
-func (b *BlockChain) addOrphanBlock(block *btcutil.Block) {
+//
+// The passed proof-of-work hash, when not nil, is the verified hash of the
+// block calculated with the passed algorithm.
+func (b *BlockChain) addOrphanBlock(block *btcutil.Block, powAlgo wire.PowAlgorithm, powHash *chainhash.Hash) {
 	// Remove expired orphan blocks.
 	for _, oBlock := range b.orphans {
 		if time.Now().After(oBlock.expiration) {
@@ -316,6 +379,8 @@ func (b *BlockChain) addOrphanBlock(block *btcutil.Block) {
 	oBlock := &orphanBlock{
 		block:      block,
 		expiration: expiration,
+		powAlgo:    powAlgo,
+		powHash:    powHash,
 	}
 	b.orphans[*block.Hash()] = oBlock
 
@@ -596,6 +661,11 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 	state := newBestState(node, blockSize, blockWeight, numTxns,
 		curTotalTxns+numTxns, node.CalcPastMedianTime())
 
+	// Update the statistics of the utxo set for the outputs spent and
+	// created by the block.
+	utxoSetStats := b.utxoStats.copy()
+	utxoSetStats.connectBlock(block, node.height, stxos)
+
 	// Atomically insert info into the database.
 	err = b.db.Update(func(dbTx database.Tx) error {
 		// Update best block state.
@@ -604,17 +674,16 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 			return err
 		}
 
-		// Add the block hash and height to the block index which tracks
-		// the main chain.
-		err = dbPutBlockIndex(dbTx, block.Hash(), node.height)
+		// Update the utxo set statistics along with the best state so
+		// they always match the end of the main chain.
+		err = dbPutUtxoStats(dbTx, &node.hash, utxoSetStats)
 		if err != nil {
 			return err
 		}
 
-		// Update the utxo set using the state of the utxo view.  This
-		// entails removing all of the utxos spent and adding the new
-		// ones created by the block.
-		err = dbPutUtxoView(dbTx, view)
+		// Add the block hash and height to the block index which tracks
+		// the main chain.
+		err = dbPutBlockIndex(dbTx, block.Hash(), node.height)
 		if err != nil {
 			return err
 		}
@@ -642,12 +711,19 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 		return err
 	}
 
+	// Update the utxo cache using the state of the utxo view.  This entails
+	// removing all of the utxos spent and adding the new ones created by
+	// the block.  The changes are written to the database when the cache
+	// is flushed.
+	b.utxoCache.commit(view)
+
 	// Prune fully spent entries and mark all entries in the view unmodified
-	// now that the modifications have been committed to the database.
+	// now that the modifications have been committed to the cache.
 	view.commit()
 
 	// This node is now the end of the best chain.
 	b.bestChain.SetTip(node)
+	b.utxoStats = utxoSetStats
 
 	// Update the state for the best block.  Notice how this replaces the
 	// entire struct instead of updating the existing one.  This effectively
@@ -658,6 +734,18 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 	b.stateSnapshot = state
 	b.stateLock.Unlock()
 
+	// Write the utxo cache to the database when it has grown too large or
+	// has not been written for a while.
+	if err := b.utxoCache.flush(FlushPeriodic, &node.hash); err != nil {
+		return err
+	}
+
+	// Delete the oldest blocks once the stored blocks exceed the prune
+	// target.
+	if err := b.pruneBlocks(node); err != nil {
+		return err
+	}
+
 	// Notify the caller that the block was connected to the main chain.
 	// The caller would typically want to react with actions such as
 	// updating wallets.
@@ -697,6 +785,14 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 		return err
 	}
 
+	// The utxo set is updated directly in the database when disconnecting
+	// blocks, so write the utxo cache out first to ensure the database
+	// reflects the current tip.
+	err = b.utxoCache.flush(FlushRequired, &node.hash)
+	if err != nil {
+		return err
+	}
+
 	// Generate a new best state snapshot that will be used to update the
 	// database and later memory if all database updates are successful.
 	b.stateLock.RLock()
@@ -709,6 +805,7 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 	state := newBestState(prevNode, blockSize, blockWeight, numTxns,
 		newTotalTxns, prevNode.CalcPastMedianTime())
 
+	var utxoSetStats *utxoStats
 	err = b.db.Update(func(dbTx database.Tx) error {
 		// Update best block state.
 		err := dbPutBestState(dbTx, state, node.workSum)
@@ -730,6 +827,10 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 		if err != nil {
 			return err
 		}
+		err = dbPutUtxoStateConsistency(dbTx, &prevNode.hash)
+		if err != nil {
+			return err
+		}
 
 		// Before we delete the spend journal entry for this back,
 		// we'll fetch it as is so the indexers can utilize if needed.
@@ -738,6 +839,15 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 			return err
 		}
 
+		// Update the statistics of the utxo set for the outputs
+		// restored and removed by disconnecting the block.
+		utxoSetStats = b.utxoStats.copy()
+		utxoSetStats.disconnectBlock(block, node.height, stxos)
+		err = dbPutUtxoStats(dbTx, &prevNode.hash, utxoSetStats)
+		if err != nil {
+			return err
+		}
+
 		// Update the transaction spend journal by removing the record
 		// that contains all txos spent by the block.
 		err = dbRemoveSpendJournalEntry(dbTx, block.Hash())
@@ -764,9 +874,11 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 	// Prune fully spent entries and mark all entries in the view unmodified
 	// now that the modifications have been committed to the database.
 	view.commit()
+	b.utxoCache.markFlushed(&prevNode.hash)
 
 	// This node's parent is now the end of the best chain.
 	b.bestChain.SetTip(node.parent)
+	b.utxoStats = utxoSetStats
 
 	// Update the state for the best block.  Notice how this replaces the
 	// entire struct instead of updating the existing one.  This effectively
@@ -837,6 +949,15 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		}
 	}
 
+	// Disconnecting blocks may require looking up legacy spend journal
+	// details directly in the database, so make sure it is up to date.
+	if detachNodes.Len() != 0 {
+		err := b.utxoCache.flush(FlushRequired, &tip.hash)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Track the old and new best chains heads.
 	oldBest := tip
 	newBest := tip
@@ -875,7 +996,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 
 		// Load all of the utxos referenced by the block that aren't
 		// already in the view.
-		err = view.fetchInputUtxos(b.db, block)
+		err = view.fetchInputUtxos(b.utxoCache, block)
 		if err != nil {
 			return err
 		}
@@ -942,7 +1063,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		// checkConnectBlock gets skipped, we still need to update the UTXO
 		// view.
 		if b.index.NodeStatus(n).KnownValid() {
-			err = view.fetchInputUtxos(b.db, block)
+			err = view.fetchInputUtxos(b.utxoCache, block)
 			if err != nil {
 				return err
 			}
@@ -963,7 +1084,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		// In the case the block is determined to be invalid due to a
 		// rule violation, mark it as invalid and mark all of its
 		// descendants as having an invalid ancestor.
-		err = b.checkConnectBlock(n, block, view, nil)
+		err = b.checkConnectBlock(n, block, view, nil, BFNone)
 		if err != nil {
 			if _, ok := err.(RuleError); ok {
 				b.index.SetStatusFlags(n, statusValidateFailed)
@@ -979,6 +1100,29 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		newBest = n
 	}
 
+	// Notify the caller about the reorganization as a whole before the
+	// notifications for the individual blocks so they can be handled as
+	// part of it.  Only connecting blocks to the end of the main chain is
+	// not a reorganization.
+	if detachNodes.Len() != 0 {
+		forkPoint := detachNodes.Back().Value.(*blockNode).parent
+		reorg := &ReorgData{
+			ForkHash:       forkPoint.hash,
+			ForkHeight:     forkPoint.height,
+			DetachedHashes: make([]chainhash.Hash, 0, len(detachBlocks)),
+			AttachedHashes: make([]chainhash.Hash, 0, len(attachBlocks)),
+		}
+		for _, block := range detachBlocks {
+			reorg.DetachedHashes = append(reorg.DetachedHashes,
+				*block.Hash())
+		}
+		for _, block := range attachBlocks {
+			reorg.AttachedHashes = append(reorg.AttachedHashes,
+				*block.Hash())
+		}
+		b.sendNotification(NTChainReorg, reorg)
+	}
+
 	// Reset the view for the actual connection code below.  This is
 	// required because the view was previously modified when checking if
 	// the reorg would be successful and the connection code requires the
@@ -994,7 +1138,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 
 		// Load all of the utxos referenced by the block that aren't
 		// already in the view.
-		err := view.fetchInputUtxos(b.db, block)
+		err := view.fetchInputUtxos(b.utxoCache, block)
 		if err != nil {
 			return err
 		}
@@ -1021,7 +1165,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 
 		// Load all of the utxos referenced by the block that aren't
 		// already in the view.
-		err := view.fetchInputUtxos(b.db, block)
+		err := view.fetchInputUtxos(b.utxoCache, block)
 		if err != nil {
 			return err
 		}
@@ -1069,6 +1213,8 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 // The flags modify the behavior of this function as follows:
 //  - BFFastAdd: Avoids several expensive transaction validation operations.
 //    This is useful when using checkpoints.
+//  - BFAssumeValid: Avoids validating the transaction scripts when the chain
+//    has an assumed valid block.
 //
 // This function MUST be called with the chain state lock held (for writes).
 func (b *BlockChain) connectBestChain(node *blockNode, block *btcutil.Block, flags BehaviorFlags) (bool, error) {
@@ -1099,7 +1245,8 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *btcutil.Block, fla
 		view.SetBestHash(parentHash)
 		stxos := make([]SpentTxOut, 0, countSpentOutputs(block))
 		if !fastAdd {
-			err := b.checkConnectBlock(node, block, view, &stxos)
+			err := b.checkConnectBlock(node, block, view, &stxos,
+				flags)
 			if err == nil {
 				b.index.SetStatusFlags(node, statusValid)
 			} else if _, ok := err.(RuleError); ok {
@@ -1120,7 +1267,7 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *btcutil.Block, fla
 		// utxos, spend them, and add the new utxos being created by
 		// this block.
 		if fastAdd {
-			err := view.fetchInputUtxos(b.db, block)
+			err := view.fetchInputUtxos(b.utxoCache, block)
 			if err != nil {
 				return false, err
 			}
@@ -1267,6 +1414,146 @@ func (b *BlockChain) HeaderByHash(hash *chainhash.Hash) (wire.BlockHeader, error
 	return node.Header(), nil
 }
 
+// ChainWork returns the total amount of work in the chain up to and including
+// the block identified by the given hash or an error if it doesn't exist.  Note
+// that this will return the work of blocks from both the main and side chains.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ChainWork(hash *chainhash.Hash) (*big.Int, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		err := fmt.Errorf("block %s is not known", hash)
+		return nil, err
+	}
+
+	return new(big.Int).Set(node.workSum), nil
+}
+
+// BlockPowHash returns the proof-of-work hash of the block with the given hash
+// as determined by the proof-of-work algorithm in effect at its height.  The
+// hash is taken from the block index when it has already been verified and is
+// otherwise calculated, checked against the target claimed by the block
+// header, and cached in the block index so it is never calculated again.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockPowHash(hash *chainhash.Hash) (chainhash.Hash, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		err := fmt.Errorf("block %s is not known", hash)
+		return chainhash.Hash{}, err
+	}
+	return b.nodePowHash(node)
+}
+
+// nodePowHash returns the proof-of-work hash of the passed block node from the
+// block index, or calculates, checks and caches it there when it has not been
+// verified yet.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) nodePowHash(node *blockNode) (chainhash.Hash, error) {
+	if powHash, ok := b.index.PowHash(node); ok {
+		return powHash, nil
+	}
+
+	header := node.Header()
+	powAlgo := b.chainParams.PowAlgorithmAt(node.height)
+	powHash, err := header.PowHash(powAlgo)
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
+	err = checkPowHash(&powHash, CompactToBig(node.bits))
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
+	b.index.SetPowHash(node, &powHash)
+	return powHash, nil
+}
+
+// VerifyHeaderProofOfWork ensures the proof of work of the passed block header,
+// which is expected to be at the passed height, satisfies the target it claims
+// as CheckHeaderProofOfWork does.  The verified hash is kept until the block is
+// processed so it is not calculated again then, and the cached hash of a block
+// already in the block index is used instead of calculating it.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) VerifyHeaderProofOfWork(header *wire.BlockHeader, height int32) error {
+	hash := header.BlockHash()
+	if node := b.index.LookupNode(&hash); node != nil && node.height == height {
+		_, err := b.nodePowHash(node)
+		return err
+	}
+	if b.headerPowHash(&hash, height) != nil {
+		return nil
+	}
+
+	powAlgo := b.chainParams.PowAlgorithmAt(height)
+	powHash, err := calcAndCheckProofOfWork(header, b.chainParams.PowLimit,
+		powAlgo, BFNone)
+	if err != nil {
+		return err
+	}
+
+	b.addHeaderPowHash(&hash, height, powHash)
+	return nil
+}
+
+// addHeaderPowHash keeps the passed proof-of-work hash verified for the block
+// header with the passed hash at the passed height, evicting the least recently
+// used hash when there are already maxHeaderPowHashes of them.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) addHeaderPowHash(hash *chainhash.Hash, height int32, powHash *chainhash.Hash) {
+	entry := &headerPowHash{hash: *hash, height: height, powHash: *powHash}
+	b.headerPowHashLock.Lock()
+	defer b.headerPowHashLock.Unlock()
+	if elem, ok := b.headerPowHashes[*hash]; ok {
+		// The header was verified for another height in the meantime.
+		elem.Value = entry
+		b.headerPowHashesLRU.MoveToFront(elem)
+		return
+	}
+	if b.headerPowHashesLRU.Len() >= maxHeaderPowHashes {
+		lru := b.headerPowHashesLRU.Back()
+		b.headerPowHashesLRU.Remove(lru)
+		delete(b.headerPowHashes, lru.Value.(*headerPowHash).hash)
+	}
+	b.headerPowHashes[*hash] = b.headerPowHashesLRU.PushFront(entry)
+}
+
+// headerPowHash returns the proof-of-work hash of the block header with the
+// passed hash verified by VerifyHeaderProofOfWork for the passed height, or nil
+// when there is none.  A found hash becomes the most recently used one.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) headerPowHash(hash *chainhash.Hash, height int32) *chainhash.Hash {
+	b.headerPowHashLock.Lock()
+	defer b.headerPowHashLock.Unlock()
+	elem, ok := b.headerPowHashes[*hash]
+	if !ok {
+		return nil
+	}
+	entry := elem.Value.(*headerPowHash)
+	if entry.height != height {
+		return nil
+	}
+	b.headerPowHashesLRU.MoveToFront(elem)
+	powHash := entry.powHash
+	return &powHash
+}
+
+// removeHeaderPowHash removes the proof-of-work hash of the block header with
+// the passed hash verified by VerifyHeaderProofOfWork, if any.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) removeHeaderPowHash(hash *chainhash.Hash) {
+	b.headerPowHashLock.Lock()
+	if elem, ok := b.headerPowHashes[*hash]; ok {
+		b.headerPowHashesLRU.Remove(elem)
+		delete(b.headerPowHashes, *hash)
+	}
+	b.headerPowHashLock.Unlock()
+}
+
 // MainChainHasBlock returns whether or not the block with the given hash is in
 // the main chain.
 //
@@ -1700,6 +1987,51 @@ type Config struct {
 	// This field can be nil if the caller is not interested in using a
 	// signature cache.
 	HashCache *txscript.HashCache
+
+	// UtxoCacheMaxSize defines the maximum size in bytes of the utxo cache
+	// which holds changes to the utxo set in memory before they are
+	// written to the database.
+	//
+	// A value of zero causes the changes made by every block to be written
+	// to the database as soon as it is connected.
+	UtxoCacheMaxSize uint64
+
+	// UtxoCacheFlushInterval defines the maximum amount of time changes to
+	// the utxo set are held in the utxo cache while blocks are being
+	// connected before they are written to the database.
+	//
+	// A value of zero causes DefaultUtxoCacheFlushInterval to be used.
+	UtxoCacheFlushInterval time.Duration
+
+	// PruneTarget defines the target size in bytes of the blocks stored in
+	// the database.  Once it is exceeded, the oldest blocks are deleted
+	// along with their spend journal entries, although the most recent
+	// MinBlocksToKeep blocks are always kept.
+	//
+	// A value of zero disables pruning.
+	PruneTarget uint64
+
+	// AssumeValid is the hash of a block whose ancestors are assumed to have
+	// valid scripts, so script validation is skipped when connecting them
+	// as long as the headers leading to it have at least the minimum chain
+	// work defined by ChainParams.
+	//
+	// This field can be nil to validate the scripts of all blocks.
+	AssumeValid *chainhash.Hash
+
+	// VerifyLevel and VerifyDepth define the level and the number of most
+	// recent blocks of the verification of the main chain performed when
+	// it is loaded after an unclean shutdown.  See VerifyChain for details.
+	//
+	// A VerifyDepth of zero disables the verification.
+	VerifyLevel int32
+	VerifyDepth int32
+
+	// ReindexChainState specifies whether the chain state, which consists
+	// of the block index, the spend journal and the utxo set, is wiped and
+	// rebuilt by processing all blocks stored in the database again.  An
+	// interrupted reindex is resumed regardless of this field.
+	ReindexChainState bool
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -1749,14 +2081,27 @@ func New(config *Config) (*BlockChain, error) {
 		maxRetargetTimespan: targetTimespan * adjustmentFactor,
 		blocksPerRetarget:   int32(targetTimespan / targetTimePerBlock),
 		index:               newBlockIndex(config.DB, params),
+		utxoCache: newUtxoCache(config.DB, config.UtxoCacheMaxSize,
+			config.UtxoCacheFlushInterval),
 		hashCache:           config.HashCache,
+		pruneTarget:         config.PruneTarget,
+		assumeValid:         config.AssumeValid,
 		bestChain:           newChainView(nil),
 		orphans:             make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:         make(map[chainhash.Hash][]*orphanBlock),
+		headerPowHashes:     make(map[chainhash.Hash]*list.Element),
+		headerPowHashesLRU:  list.New(),
 		warningCaches:       newThresholdCaches(vbNumBits),
 		deploymentCaches:    newThresholdCaches(chaincfg.DefinedDeployments),
 	}
 
+	// Wipe the chain state when it is to be rebuilt from the stored blocks.
+	reindex, err := initReindexChainState(config.DB,
+		config.ReindexChainState, config.Interrupt)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize the chain state from the passed database.  When the db
 	// does not yet contain any chain state, both it and the chain state
 	// will be initialized to contain only the genesis block.
@@ -1769,6 +2114,30 @@ func New(config *Config) (*BlockChain, error) {
 		return nil, err
 	}
 
+	// Make sure the utxo set is consistent with the best chain in case the
+	// utxo cache was not written to the database before the last shutdown.
+	uncleanShutdown, err := b.initUtxoCacheState(config.Interrupt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Continue validating the blocks below a loaded utxo snapshot.  The
+	// optional indexes need all blocks, so they can't be used until then.
+	if err := b.initSnapshotState(config.Interrupt); err != nil {
+		return nil, err
+	}
+	if b.snapshot != nil && config.IndexManager != nil {
+		return nil, AssertError("blockchain.New optional indexes can't " +
+			"be used until the blocks below the loaded utxo snapshot " +
+			"are validated")
+	}
+
+	// Load the statistics of the utxo set, calculating them first if
+	// needed.
+	if err := b.initUtxoStats(); err != nil {
+		return nil, err
+	}
+
 	// Initialize and catch up all of the currently active optional indexes
 	// as needed.
 	if config.IndexManager != nil {
@@ -1783,6 +2152,29 @@ func New(config *Config) (*BlockChain, error) {
 		return nil, err
 	}
 
+	// Rebuild the wiped chain state from the stored blocks.
+	if reindex {
+		if err := b.replayStoredBlocks(config.Interrupt); err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify the most recent blocks after an unclean shutdown since the
+	// database might have been left in an inconsistent state.
+	if uncleanShutdown && config.VerifyDepth > 0 {
+		report, err := b.verifyChain(config.VerifyLevel,
+			config.VerifyDepth, BFNone, config.Interrupt)
+		if err != nil {
+			return nil, err
+		}
+		if !report.Verified() {
+			return nil, fmt.Errorf("corrupt chain database detected "+
+				"after an unclean shutdown: %v", report)
+		}
+		log.Infof("Verified %d blocks after an unclean shutdown",
+			report.StartHeight-report.EndHeight+1)
+	}
+
 	bestNode := b.bestChain.Tip()
 	log.Infof("Chain state (height %d, hash %v, totaltx %d, work %v)",
 		bestNode.height, bestNode.hash, b.stateSnapshot.TotalTxns,
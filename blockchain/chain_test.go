@@ -5,6 +5,8 @@
 package blockchain
 
 import (
+	"container/list"
+	"encoding/binary"
 	"reflect"
 	"testing"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
 	"github.com/btcsuite/btcd/btcutil"
 )
 
@@ -37,7 +40,7 @@ func TestHaveBlock(t *testing.T) {
 
 	// Create a new database and chain instance to run tests against.
 	chain, teardownFunc, err := chainSetup("haveblock",
-		&chaincfg.MainNetParams)
+		&bitcoinMainNetParams)
 	if err != nil {
 		t.Errorf("Failed to setup chain instance: %v", err)
 		return
@@ -61,9 +64,10 @@ func TestHaveBlock(t *testing.T) {
 		}
 	}
 
-	// Insert an orphan block.
+	// Insert an orphan block.  Its proof of work is that of the bitcoin
+	// block it was taken from, so skip the check orphans are subject to.
 	_, isOrphan, err := chain.ProcessBlock(btcutil.NewBlock(&Block100000),
-		BFNone)
+		BFNoPoWCheck)
 	if err != nil {
 		t.Errorf("Unable to process block: %v", err)
 		return
@@ -79,7 +83,7 @@ func TestHaveBlock(t *testing.T) {
 		want bool
 	}{
 		// Genesis block should be present (in the main chain).
-		{hash: chaincfg.MainNetParams.GenesisHash.String(), want: true},
+		{hash: bitcoinMainNetParams.GenesisHash.String(), want: true},
 
 		// Block 3a should be present (on a side chain).
 		{hash: "00000000474284d20067a4d33f6a02284e6ef70764a3a26d6a5b9df52ef663dd", want: true},
@@ -964,3 +968,216 @@ func TestIntervalBlockHashes(t *testing.T) {
 		}
 	}
 }
+
+// TestBlockPowHash ensures the proof-of-work hash of a block is calculated with
+// the algorithm in effect at its height, verified against its target and
+// cached in the block index so it is only ever calculated once.
+func TestBlockPowHash(t *testing.T) {
+	// A deliberately unused algorithm identifier is used so the test does
+	// not interfere with the real hash functions.
+	const testAlgo = wire.PowAlgorithm(0xfe)
+	var numCalls int
+	powHash := chainhash.Hash{0x01}
+	wire.RegisterPowHashFunc(testAlgo, func([]byte) (chainhash.Hash, error) {
+		numCalls++
+		return powHash, nil
+	})
+
+	params := chaincfg.RegressionNetParams
+	params.PowForks = []chaincfg.PowFork{{Height: 0, Algorithm: testAlgo}}
+	chain := newFakeChain(&params)
+	genesis := chain.bestChain.Tip()
+	node := newFakeNode(genesis, 1, params.PowLimitBits, time.Now())
+	chain.index.AddNode(node)
+
+	// The hash is calculated and cached the first time it is requested.
+	for i := 0; i < 2; i++ {
+		got, err := chain.BlockPowHash(&node.hash)
+		if err != nil {
+			t.Fatalf("BlockPowHash #%d: unexpected error: %v", i, err)
+		}
+		if got != powHash {
+			t.Fatalf("BlockPowHash #%d: got %v, want %v", i, got,
+				powHash)
+		}
+		if numCalls != 1 {
+			t.Fatalf("BlockPowHash #%d: hash calculated %d times, "+
+				"want 1", i, numCalls)
+		}
+	}
+	if _, ok := chain.index.dirty[node]; !ok {
+		t.Fatal("BlockPowHash: node not marked dirty after caching hash")
+	}
+
+	// A hash which does not satisfy the target is rejected and not cached.
+	powHash = chainhash.Hash{31: 0xff}
+	node2 := newFakeNode(node, 1, params.PowLimitBits, time.Now())
+	chain.index.AddNode(node2)
+	_, err := chain.BlockPowHash(&node2.hash)
+	if rerr, ok := err.(RuleError); !ok || rerr.ErrorCode != ErrHighHash {
+		t.Fatalf("BlockPowHash: unexpected error - got %v, want %v", err,
+			ErrHighHash)
+	}
+	if _, ok := chain.index.PowHash(node2); ok {
+		t.Fatal("BlockPowHash: cached a hash that failed verification")
+	}
+
+	// Unknown blocks are reported.
+	if _, err := chain.BlockPowHash(&chainhash.Hash{}); err == nil {
+		t.Fatal("BlockPowHash: expected error for unknown block")
+	}
+}
+
+// TestPowHashReuse ensures proof-of-work hashes verified ahead of processing a
+// block, whether from its header or when it was an orphan, are used instead of
+// calculating them again, and that verifying the chain uses the hashes cached
+// in the block index.
+func TestPowHashReuse(t *testing.T) {
+	// A deliberately unused algorithm identifier is used so the test does
+	// not interfere with the real hash functions.
+	const testAlgo = wire.PowAlgorithm(0xfd)
+	var numCalls int
+	wire.RegisterPowHashFunc(testAlgo, func([]byte) (chainhash.Hash, error) {
+		numCalls++
+		return chainhash.Hash{0x01}, nil
+	})
+
+	params := chaincfg.RegressionNetParams
+	params.PowForks = []chaincfg.PowFork{{Height: 0, Algorithm: testAlgo}}
+	chain, teardownFunc, err := chainSetup("powhashreuse", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Every block spends the coinbase of the previous one.
+	var blocks []*btcutil.Block
+	prev := &params.GenesisBlock.Header
+	for height := int32(1); height <= 3; height++ {
+		var spends []testhelper.SpendableOut
+		if height > 1 {
+			spends = append(spends, coinbaseSpend(blocks[height-2]))
+		}
+		block := newTestBlock(&params, prev, height, spends...)
+		blocks = append(blocks, block)
+		prev = &block.MsgBlock().Header
+	}
+	checkCalls := func(step string, want int) {
+		t.Helper()
+		if numCalls != want {
+			t.Fatalf("%s: hash calculated %d times, want %d", step,
+				numCalls, want)
+		}
+	}
+
+	// The hash verified for a header is kept until its block is processed.
+	for i := 0; i < 2; i++ {
+		header := &blocks[0].MsgBlock().Header
+		if err := chain.VerifyHeaderProofOfWork(header, 1); err != nil {
+			t.Fatalf("VerifyHeaderProofOfWork: unexpected error: %v",
+				err)
+		}
+	}
+	checkCalls("VerifyHeaderProofOfWork", 1)
+	if _, _, err := chain.ProcessBlock(blocks[0], BFNone); err != nil {
+		t.Fatalf("ProcessBlock: unexpected error: %v", err)
+	}
+	checkCalls("ProcessBlock", 1)
+	node := chain.index.LookupNode(blocks[0].Hash())
+	if _, ok := chain.index.PowHash(node); !ok {
+		t.Fatal("ProcessBlock: hash not cached in the block index")
+	}
+	if len(chain.headerPowHashes) != 0 {
+		t.Fatal("ProcessBlock: verified header hash was not removed")
+	}
+
+	// Headers of blocks in the block index use the hash cached there.
+	header := &blocks[0].MsgBlock().Header
+	if err := chain.VerifyHeaderProofOfWork(header, 1); err != nil {
+		t.Fatalf("VerifyHeaderProofOfWork: unexpected error: %v", err)
+	}
+	checkCalls("VerifyHeaderProofOfWork", 1)
+
+	// The hash verified for an orphan is used once its parent is known.
+	_, isOrphan, err := chain.ProcessBlock(blocks[2], BFNone)
+	if err != nil || !isOrphan {
+		t.Fatalf("ProcessBlock: unexpected result %v: %v", isOrphan, err)
+	}
+	checkCalls("ProcessBlock orphan", 2)
+	if _, _, err := chain.ProcessBlock(blocks[1], BFNone); err != nil {
+		t.Fatalf("ProcessBlock: unexpected error: %v", err)
+	}
+	checkCalls("ProcessBlock parent", 3)
+	if chain.BestSnapshot().Hash != *blocks[2].Hash() {
+		t.Fatal("ProcessBlock: orphan was not connected")
+	}
+
+	// Verifying the chain uses the cached hashes.
+	report, err := chain.VerifyChain(VerifyLevelSanity, 0)
+	if err != nil || len(report.Failures) != 0 {
+		t.Fatalf("VerifyChain: unexpected result %v: %v", report, err)
+	}
+	checkCalls("VerifyChain", 3)
+}
+
+// TestHeaderPowHashEviction ensures the proof-of-work hashes verified ahead of
+// their blocks are bounded and the least recently used one is evicted first.
+func TestHeaderPowHashEviction(t *testing.T) {
+	chain := &BlockChain{
+		headerPowHashes:    make(map[chainhash.Hash]*list.Element),
+		headerPowHashesLRU: list.New(),
+	}
+	hashAt := func(i int) *chainhash.Hash {
+		var hash chainhash.Hash
+		binary.LittleEndian.PutUint32(hash[:], uint32(i))
+		return &hash
+	}
+	powHash := &chainhash.Hash{0x01}
+	for i := 0; i < maxHeaderPowHashes; i++ {
+		chain.addHeaderPowHash(hashAt(i), int32(i), powHash)
+	}
+
+	// Using the oldest hash makes the second oldest one the least
+	// recently used, so it is evicted by the next hash.
+	if chain.headerPowHash(hashAt(0), 0) == nil {
+		t.Fatal("headerPowHash: oldest hash not found")
+	}
+	chain.addHeaderPowHash(hashAt(maxHeaderPowHashes),
+		maxHeaderPowHashes, powHash)
+	if len(chain.headerPowHashes) != maxHeaderPowHashes ||
+		chain.headerPowHashesLRU.Len() != maxHeaderPowHashes {
+
+		t.Fatalf("got %d hashes in the map and %d in the list, want %d",
+			len(chain.headerPowHashes), chain.headerPowHashesLRU.Len(),
+			maxHeaderPowHashes)
+	}
+	if chain.headerPowHash(hashAt(1), 1) != nil {
+		t.Fatal("least recently used hash was not evicted")
+	}
+	for _, i := range []int{0, 2, maxHeaderPowHashes} {
+		if chain.headerPowHash(hashAt(i), int32(i)) == nil {
+			t.Fatalf("hash #%d was evicted", i)
+		}
+	}
+
+	// Verifying a kept header again, such as for another height, replaces
+	// its hash instead of adding another one.
+	chain.addHeaderPowHash(hashAt(2), 3, powHash)
+	if chain.headerPowHashesLRU.Len() != maxHeaderPowHashes {
+		t.Fatalf("got %d hashes, want %d", chain.headerPowHashesLRU.Len(),
+			maxHeaderPowHashes)
+	}
+	if chain.headerPowHash(hashAt(2), 2) != nil ||
+		chain.headerPowHash(hashAt(2), 3) == nil {
+
+		t.Fatal("hash verified again was not replaced")
+	}
+
+	chain.removeHeaderPowHash(hashAt(2))
+	if len(chain.headerPowHashes) != maxHeaderPowHashes-1 ||
+		chain.headerPowHashesLRU.Len() != maxHeaderPowHashes-1 {
+
+		t.Fatal("removeHeaderPowHash: hash was not removed")
+	}
+}
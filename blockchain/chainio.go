@@ -67,6 +67,10 @@ var (
 	// unspent transaction output set.
 	utxoSetBucketName = []byte("utxosetv2")
 
+	// utxoStateConsistencyKeyName is the name of the db key used to store
+	// the hash of the block the utxo set in the database represents.
+	utxoStateConsistencyKeyName = []byte("utxostateconsistency")
+
 	// byteOrder is the preferred byte order used for serializing numeric
 	// fields for storage in the database.
 	byteOrder = binary.LittleEndian
@@ -730,10 +734,16 @@ func dbFetchUtxoEntryByHash(dbTx database.Tx, hash *chainhash.Hash) (*UtxoEntry,
 // When there is no entry for the provided output, nil will be returned for both
 // the entry and the error.
 func dbFetchUtxoEntry(dbTx database.Tx, outpoint wire.OutPoint) (*UtxoEntry, error) {
+	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+	return dbFetchBucketUtxoEntry(utxoBucket, outpoint)
+}
+
+// dbFetchBucketUtxoEntry fetches the specified transaction output from the utxo
+// set housed in the passed bucket.  See dbFetchUtxoEntry for details.
+func dbFetchBucketUtxoEntry(utxoBucket database.Bucket, outpoint wire.OutPoint) (*UtxoEntry, error) {
 	// Fetch the unspent transaction output information for the passed
 	// transaction output.  Return now when there is no entry.
 	key := outpointKey(outpoint)
-	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
 	serializedUtxo := utxoBucket.Get(*key)
 	recycleOutpointKey(key)
 	if serializedUtxo == nil {
@@ -771,8 +781,22 @@ func dbFetchUtxoEntry(dbTx database.Tx, outpoint wire.OutPoint) (*UtxoEntry, err
 // particular, only the entries that have been marked as modified are written
 // to the database.
 func dbPutUtxoView(dbTx database.Tx, view *UtxoViewpoint) error {
+	return dbPutUtxoEntries(dbTx, view.entries)
+}
+
+// dbPutUtxoEntries uses an existing database transaction to update the utxo
+// set in the database with the passed entries.  Only the entries that have been
+// marked as modified are written to the database and those which are spent are
+// removed from it.
+func dbPutUtxoEntries(dbTx database.Tx, entries map[wire.OutPoint]*UtxoEntry) error {
 	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
-	for outpoint, entry := range view.entries {
+	return dbPutBucketUtxoEntries(utxoBucket, entries)
+}
+
+// dbPutBucketUtxoEntries updates the utxo set housed in the passed bucket with
+// the passed entries.  See dbPutUtxoEntries for details.
+func dbPutBucketUtxoEntries(utxoBucket database.Bucket, entries map[wire.OutPoint]*UtxoEntry) error {
+	for outpoint, entry := range entries {
 		// No need to update the database if the entry was not modified.
 		if entry == nil || !entry.isModified() {
 			continue
@@ -809,6 +833,32 @@ func dbPutUtxoView(dbTx database.Tx, view *UtxoViewpoint) error {
 	return nil
 }
 
+// dbPutUtxoStateConsistency uses an existing database transaction to record
+// the hash of the block the utxo set in the database represents.
+func dbPutUtxoStateConsistency(dbTx database.Tx, hash *chainhash.Hash) error {
+	return dbTx.Metadata().Put(utxoStateConsistencyKeyName, hash[:])
+}
+
+// dbFetchUtxoStateConsistency uses an existing database transaction to fetch
+// the hash of the block the utxo set in the database represents.  It returns
+// nil when the database does not record it.
+func dbFetchUtxoStateConsistency(dbTx database.Tx) *chainhash.Hash {
+	return dbFetchUtxoState(dbTx, utxoStateConsistencyKeyName)
+}
+
+// dbFetchUtxoState uses an existing database transaction to fetch the hash of
+// the block a utxo set in the database represents from the passed key.  It
+// returns nil when the key does not exist.
+func dbFetchUtxoState(dbTx database.Tx, key []byte) *chainhash.Hash {
+	serialized := dbTx.Metadata().Get(key)
+	if len(serialized) != chainhash.HashSize {
+		return nil
+	}
+	var hash chainhash.Hash
+	copy(hash[:], serialized)
+	return &hash
+}
+
 // -----------------------------------------------------------------------------
 // The block index consists of two buckets with an entry for every block in the
 // main chain.  One bucket is for the hash to height mapping and the other is
@@ -1153,7 +1203,8 @@ func (b *BlockChain) initChainState() error {
 		var lastNode *blockNode
 		cursor := blockIndexBucket.Cursor()
 		for ok := cursor.First(); ok; ok = cursor.Next() {
-			header, status, err := deserializeBlockRow(cursor.Value())
+			header, status, powHash, preciousSeq, err :=
+				deserializeBlockRow(cursor.Value())
 			if err != nil {
 				return err
 			}
@@ -1187,6 +1238,10 @@ func (b *BlockChain) initChainState() error {
 			node := new(blockNode)
 			initBlockNode(node, header, parent)
 			node.status = status
+			if powHash != nil {
+				node.powHash = *powHash
+			}
+			node.preciousSeq = preciousSeq
 			b.index.addNode(node)
 
 			lastNode = node
@@ -1212,12 +1267,24 @@ func (b *BlockChain) initChainState() error {
 			return err
 		}
 
+		// The blocks up to and including the block of a utxo snapshot
+		// that is still being validated are only known to be valid once
+		// they have been validated in the background.
+		snapshotState, err := dbFetchSnapshotState(dbTx)
+		if err != nil {
+			return err
+		}
+		var snapshotBase *blockNode
+		if snapshotState != nil {
+			snapshotBase = b.index.LookupNode(&snapshotState.baseHash)
+		}
+
 		// As a final consistency check, we'll run through all the
 		// nodes which are ancestors of the current chain tip, and mark
 		// them as valid if they aren't already marked as such.  This
 		// is a safe assumption as all the block before the current tip
 		// are valid by definition.
-		for iterNode := tip; iterNode != nil; iterNode = iterNode.parent {
+		for iterNode := tip; iterNode != nil && iterNode != snapshotBase; iterNode = iterNode.parent {
 			// If this isn't already marked as valid in the index, then
 			// we'll mark it as valid now to ensure consistency once
 			// we're up and running.
@@ -1251,22 +1318,52 @@ func (b *BlockChain) initChainState() error {
 }
 
 // deserializeBlockRow parses a value in the block index bucket into a block
-// header and block status bitfield.
-func deserializeBlockRow(blockRow []byte) (*wire.BlockHeader, blockStatus, error) {
+// header, block status bitfield and, when they are known, the cached
+// proof-of-work hash of the header and the sequence number of the most recent
+// time the block was marked as precious.
+//
+// The proof-of-work hash follows the status byte and is only present when the
+// status has the statusPowHashKnown flag set.  It is followed by the precious
+// sequence number, which is only present when the status has the
+// statusPrecious flag set.  Rows that were written without them, such as by
+// older versions that preserve unknown status flags but drop the data after
+// the status byte when they rewrite the row, have the flags cleared.
+func deserializeBlockRow(blockRow []byte) (*wire.BlockHeader, blockStatus, *chainhash.Hash, uint32, error) {
 	buffer := bytes.NewReader(blockRow)
 
 	var header wire.BlockHeader
 	err := header.Deserialize(buffer)
 	if err != nil {
-		return nil, statusNone, err
+		return nil, statusNone, nil, 0, err
 	}
 
 	statusByte, err := buffer.ReadByte()
 	if err != nil {
-		return nil, statusNone, err
+		return nil, statusNone, nil, 0, err
+	}
+	status := blockStatus(statusByte)
+
+	var powHash *chainhash.Hash
+	if status.PowHashKnown() {
+		if buffer.Len() < chainhash.HashSize {
+			status &^= statusPowHashKnown
+		} else {
+			powHash = new(chainhash.Hash)
+			buffer.Read(powHash[:])
+		}
 	}
 
-	return &header, blockStatus(statusByte), nil
+	var preciousSeq uint32
+	if status.Precious() {
+		var seqBytes [4]byte
+		if buffer.Len() < len(seqBytes) {
+			status &^= statusPrecious
+		} else {
+			buffer.Read(seqBytes[:])
+			preciousSeq = byteOrder.Uint32(seqBytes[:])
+		}
+	}
+	return &header, status, powHash, preciousSeq, nil
 }
 
 // dbFetchHeaderByHash uses an existing database transaction to retrieve the
@@ -1317,11 +1414,12 @@ func dbFetchBlockByNode(dbTx database.Tx, node *blockNode) (*btcutil.Block, erro
 	return block, nil
 }
 
-// dbStoreBlockNode stores the block header and validation status to the block
+// dbStoreBlockNode stores the block header, validation status and, when they
+// are known, the proof-of-work hash and precious sequence number to the block
 // index bucket. This overwrites the current entry if there exists one.
 func dbStoreBlockNode(dbTx database.Tx, node *blockNode) error {
 	// Serialize block data to be stored.
-	w := bytes.NewBuffer(make([]byte, 0, blockHdrSize+1))
+	w := bytes.NewBuffer(make([]byte, 0, blockHdrSize+1+chainhash.HashSize+4))
 	header := node.Header()
 	err := header.Serialize(w)
 	if err != nil {
@@ -1331,6 +1429,20 @@ func dbStoreBlockNode(dbTx database.Tx, node *blockNode) error {
 	if err != nil {
 		return err
 	}
+	if node.status.PowHashKnown() {
+		_, err = w.Write(node.powHash[:])
+		if err != nil {
+			return err
+		}
+	}
+	if node.status.Precious() {
+		var seqBytes [4]byte
+		byteOrder.PutUint32(seqBytes[:], node.preciousSeq)
+		_, err = w.Write(seqBytes[:])
+		if err != nil {
+			return err
+		}
+	}
 	value := w.Bytes()
 
 	// Write block header data to block index bucket.
@@ -1373,6 +1485,11 @@ func (b *BlockChain) BlockByHeight(blockHeight int32) (*btcutil.Block, error) {
 		return nil, errNotInMainChain(str)
 	}
 
+	// The block data is no longer available when it has been pruned.
+	if !b.index.NodeStatus(node).HaveData() {
+		return nil, blockPrunedError(node)
+	}
+
 	// Load the block from the database and return it.
 	var block *btcutil.Block
 	err := b.db.View(func(dbTx database.Tx) error {
@@ -1396,6 +1513,11 @@ func (b *BlockChain) BlockByHash(hash *chainhash.Hash) (*btcutil.Block, error) {
 		return nil, errNotInMainChain(str)
 	}
 
+	// The block data is no longer available when it has been pruned.
+	if !b.index.NodeStatus(node).HaveData() {
+		return nil, blockPrunedError(node)
+	}
+
 	// Load the block from the database and return it.
 	var block *btcutil.Block
 	err := b.db.View(func(dbTx database.Tx) error {
@@ -10,7 +10,9 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/database"
 	"github.com/btcsuite/btcd/wire"
 )
@@ -719,3 +721,105 @@ func TestBestChainStateDeserializeErrors(t *testing.T) {
 		}
 	}
 }
+
+// TestBlockRowDeserialize ensures block index rows, both with and without a
+// cached proof-of-work hash and precious sequence number, are deserialized as
+// expected.
+func TestBlockRowDeserialize(t *testing.T) {
+	t.Parallel()
+
+	header := wire.BlockHeader{
+		Version:   1,
+		Timestamp: time.Unix(1600000000, 0),
+		Bits:      0x207fffff,
+		Nonce:     2,
+	}
+	var buf bytes.Buffer
+	if err := header.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	headerBytes := buf.Bytes()
+	powHash := chainhash.Hash{0x01, 0x02, 0x03}
+	row := func(status blockStatus, extra []byte) []byte {
+		r := append([]byte(nil), headerBytes...)
+		r = append(r, byte(status))
+		return append(r, extra...)
+	}
+
+	seqBytes := []byte{0x05, 0x00, 0x00, 0x00}
+	tests := []struct {
+		name            string
+		row             []byte
+		wantStatus      blockStatus
+		wantPowHash     *chainhash.Hash
+		wantPreciousSeq uint32
+	}{
+		{
+			name:       "no cached hash",
+			row:        row(statusDataStored|statusValid, nil),
+			wantStatus: statusDataStored | statusValid,
+		},
+		{
+			name:        "cached hash",
+			row:         row(statusDataStored|statusPowHashKnown, powHash[:]),
+			wantStatus:  statusDataStored | statusPowHashKnown,
+			wantPowHash: &powHash,
+		},
+		{
+			// Older versions preserve unknown status flags but drop
+			// the hash when they rewrite the row.
+			name:       "flag without hash",
+			row:        row(statusDataStored|statusPowHashKnown, nil),
+			wantStatus: statusDataStored,
+		},
+		{
+			name:            "precious",
+			row:             row(statusDataStored|statusPrecious, seqBytes),
+			wantStatus:      statusDataStored | statusPrecious,
+			wantPreciousSeq: 5,
+		},
+		{
+			name: "cached hash and precious",
+			row: row(statusPowHashKnown|statusPrecious,
+				append(powHash[:], seqBytes...)),
+			wantStatus:      statusPowHashKnown | statusPrecious,
+			wantPowHash:     &powHash,
+			wantPreciousSeq: 5,
+		},
+		{
+			name:       "flags without hash and sequence number",
+			row:        row(statusPowHashKnown|statusPrecious, nil),
+			wantStatus: statusNone,
+		},
+	}
+
+	for _, test := range tests {
+		gotHeader, gotStatus, gotPowHash, gotPreciousSeq, err :=
+			deserializeBlockRow(test.row)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if gotHeader.BlockHash() != header.BlockHash() {
+			t.Errorf("%s: mismatched header - got %v, want %v",
+				test.name, gotHeader, header)
+		}
+		if gotStatus != test.wantStatus {
+			t.Errorf("%s: mismatched status - got %v, want %v",
+				test.name, gotStatus, test.wantStatus)
+		}
+		if !reflect.DeepEqual(gotPowHash, test.wantPowHash) {
+			t.Errorf("%s: mismatched pow hash - got %v, want %v",
+				test.name, gotPowHash, test.wantPowHash)
+		}
+		if gotPreciousSeq != test.wantPreciousSeq {
+			t.Errorf("%s: mismatched precious sequence number - got "+
+				"%d, want %d", test.name, gotPreciousSeq,
+				test.wantPreciousSeq)
+		}
+	}
+
+	if _, _, _, _, err := deserializeBlockRow(headerBytes); err == nil {
+		t.Error("deserializeBlockRow: expected error for missing status")
+	}
+}
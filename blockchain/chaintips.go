@@ -0,0 +1,140 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// ChainTipStatus describes the state of the branch which leads to a chain tip.
+type ChainTipStatus int
+
+const (
+	// ChainTipActive indicates the tip is the tip of the main chain.
+	ChainTipActive ChainTipStatus = iota
+
+	// ChainTipValidFork indicates all blocks of the branch are stored and
+	// have been fully validated, but the branch is not part of the main
+	// chain.
+	ChainTipValidFork
+
+	// ChainTipValidHeaders indicates all blocks of the branch are stored,
+	// but some of them have not been fully validated.
+	ChainTipValidHeaders
+
+	// ChainTipHeadersOnly indicates the data of some of the blocks of the
+	// branch is not available, for example because it has been pruned.
+	ChainTipHeadersOnly
+
+	// ChainTipInvalid indicates the branch contains at least one block
+	// which is known to be invalid.
+	ChainTipInvalid
+)
+
+// Map of chain tip statuses back to their constant names for pretty printing.
+var chainTipStatusStrings = map[ChainTipStatus]string{
+	ChainTipActive:       "active",
+	ChainTipValidFork:    "valid-fork",
+	ChainTipValidHeaders: "valid-headers",
+	ChainTipHeadersOnly:  "headers-only",
+	ChainTipInvalid:      "invalid",
+}
+
+// String returns the ChainTipStatus as the human-readable name used by the
+// getchaintips RPC.
+func (status ChainTipStatus) String() string {
+	if s := chainTipStatusStrings[status]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown ChainTipStatus (%d)", int(status))
+}
+
+// ChainTip describes a block in the block index which does not have any
+// children, that is the tip of either the main chain or of a side chain.
+type ChainTip struct {
+	// Height is the height of the tip.
+	Height int32
+
+	// Hash is the hash of the tip.
+	Hash chainhash.Hash
+
+	// BranchLen is the number of blocks between the tip and the block it
+	// forks the main chain from.  It is zero for the main chain tip.
+	BranchLen int32
+
+	// Status is the state of the branch which leads to the tip.
+	Status ChainTipStatus
+}
+
+// ChainTips returns the tips of the main chain and of all of the side chains
+// known to the block index ordered by descending height.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ChainTips() []ChainTip {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	// The tip of the main chain has children in the block index when the
+	// blocks after it have been invalidated, so include it explicitly.
+	tips := b.index.Tips()
+	active := b.bestChain.Tip()
+	haveActive := false
+	for _, tip := range tips {
+		if tip == active {
+			haveActive = true
+			break
+		}
+	}
+	if !haveActive {
+		tips = append(tips, active)
+	}
+	sort.Slice(tips, func(i, j int) bool {
+		if tips[i].height != tips[j].height {
+			return tips[i].height > tips[j].height
+		}
+		return bytes.Compare(tips[i].hash[:], tips[j].hash[:]) < 0
+	})
+
+	chainTips := make([]ChainTip, 0, len(tips))
+	for _, tip := range tips {
+		chainTip := ChainTip{
+			Height: tip.height,
+			Hash:   tip.hash,
+			Status: ChainTipActive,
+		}
+
+		// Determine the status of the branch from the blocks between the
+		// tip and the fork point.  Invalid blocks take precedence over
+		// missing blocks which in turn take precedence over blocks that
+		// have not been validated.
+		if !b.bestChain.Contains(tip) {
+			chainTip.Status = ChainTipValidFork
+			fork := b.bestChain.FindFork(tip)
+			for n := tip; n != nil && n != fork; n = n.parent {
+				status := b.index.NodeStatus(n)
+				switch {
+				case status.KnownInvalid():
+					chainTip.Status = ChainTipInvalid
+				case !status.HaveData():
+					if chainTip.Status != ChainTipInvalid {
+						chainTip.Status = ChainTipHeadersOnly
+					}
+				case !status.KnownValid():
+					if chainTip.Status == ChainTipValidFork {
+						chainTip.Status = ChainTipValidHeaders
+					}
+				}
+			}
+			chainTip.BranchLen = tip.height - fork.height
+		}
+
+		chainTips = append(chainTips, chainTip)
+	}
+	return chainTips
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// TestChainTips ensures the tips of the main chain and the side chains are
+// reported with the expected branch lengths and statuses.
+func TestChainTips(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("chaintips", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Create the following chain where b2 and b2a both spend the coinbase
+	// of b1 and b2a is not validated since it does not have more work than
+	// b2:
+	//
+	//   genesis -> b1 -> b2
+	//                \-> b2a
+	b1 := newTestBlock(&params, &params.GenesisBlock.Header, 1)
+	b2 := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	b2a := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	for _, block := range []*btcutil.Block{b1, b2, b2a} {
+		_, _, err := chain.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock %v: unexpected error: %v",
+				block.Hash(), err)
+		}
+	}
+
+	assertTips := func(name string, want ...ChainTip) {
+		t.Helper()
+		got := chain.ChainTips()
+		if len(got) != len(want) {
+			t.Fatalf("%s: unexpected number of chain tips %d, want %d",
+				name, len(got), len(want))
+		}
+		wantTips := make(map[chainhash.Hash]ChainTip, len(want))
+		for _, tip := range want {
+			wantTips[tip.Hash] = tip
+		}
+		for i, tip := range got {
+			if i > 0 && tip.Height > got[i-1].Height {
+				t.Fatalf("%s: chain tips not ordered by height", name)
+			}
+			if tip != wantTips[tip.Hash] {
+				t.Fatalf("%s: unexpected chain tip %+v, want %+v",
+					name, tip, wantTips[tip.Hash])
+			}
+		}
+	}
+	assertTips("ProcessBlock",
+		ChainTip{2, *b2.Hash(), 0, ChainTipActive},
+		ChainTip{2, *b2a.Hash(), 1, ChainTipValidHeaders})
+
+	// The previous tip was fully validated.
+	if err := chain.PreciousBlock(b2a.Hash()); err != nil {
+		t.Fatalf("PreciousBlock: unexpected error: %v", err)
+	}
+	assertTips("PreciousBlock",
+		ChainTip{2, *b2a.Hash(), 0, ChainTipActive},
+		ChainTip{2, *b2.Hash(), 1, ChainTipValidFork})
+
+	// The main chain tip is reported even when all of its children are
+	// invalid.
+	if err := chain.InvalidateBlock(b2.Hash()); err != nil {
+		t.Fatalf("InvalidateBlock: unexpected error: %v", err)
+	}
+	if err := chain.InvalidateBlock(b2a.Hash()); err != nil {
+		t.Fatalf("InvalidateBlock: unexpected error: %v", err)
+	}
+	assertTips("InvalidateBlock",
+		ChainTip{2, *b2a.Hash(), 1, ChainTipInvalid},
+		ChainTip{2, *b2.Hash(), 1, ChainTipInvalid},
+		ChainTip{1, *b1.Hash(), 0, ChainTipActive})
+}
+
+// TestChainTipStatusStringer tests the stringized output for the
+// ChainTipStatus type.
+func TestChainTipStatusStringer(t *testing.T) {
+	tests := []struct {
+		in   ChainTipStatus
+		want string
+	}{
+		{ChainTipActive, "active"},
+		{ChainTipValidFork, "valid-fork"},
+		{ChainTipValidHeaders, "valid-headers"},
+		{ChainTipHeadersOnly, "headers-only"},
+		{ChainTipInvalid, "invalid"},
+		{0xffff, "Unknown ChainTipStatus (65535)"},
+	}
+
+	for i, test := range tests {
+		result := test.in.String()
+		if result != test.want {
+			t.Errorf("String #%d\n got: %s want: %s", i, result,
+				test.want)
+		}
+	}
+}
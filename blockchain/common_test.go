@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/database"
@@ -60,10 +61,13 @@ func isSupportedDbType(dbType string) bool {
 // loadBlocks reads files containing bitcoin block data (gzipped but otherwise
 // in the format bitcoind writes) from disk and returns them as an array of
 // btcutil.Block.  This is largely borrowed from the test code in btcdb.
+//
+// The files contain blocks of the Bitcoin main network, which must be processed
+// with bitcoinMainNetParams.
 func loadBlocks(filename string) (blocks []*btcutil.Block, err error) {
 	filename = filepath.Join("testdata/", filename)
 
-	var network = wire.MainNet
+	var network = bitcoinBlockDataNet
 	var dr io.Reader
 	var fi io.ReadCloser
 
@@ -382,3 +386,61 @@ func newFakeNode(parent *blockNode, blockVersion int32, bits uint32, timestamp t
 	}
 	return newBlockNode(header, parent)
 }
+
+// newTestBlock returns a block which extends the passed block at the passed
+// height and spends the passed outputs.  Its transactions are built the same
+// way as the ones of the full block tests: the coinbase pays the subsidy along
+// with the fees to an OP_TRUE script and every output is spent by a transaction
+// paying its amount minus a fee to an OP_TRUE script along with a unique
+// OP_RETURN output.
+//
+// The block is not solved, so its proof of work must either not be checked or
+// be solved with solveTestBlock.
+func newTestBlock(params *chaincfg.Params, prev *wire.BlockHeader, height int32, spends ...testhelper.SpendableOut) *btcutil.Block {
+	coinbase := testhelper.CreateCoinbaseTx(height,
+		CalcBlockSubsidy(height, params))
+	txns := []*wire.MsgTx{coinbase}
+	for i := range spends {
+		coinbase.TxOut[0].Value += int64(testhelper.LowFee)
+		txns = append(txns, testhelper.CreateSpendTx(&spends[i],
+			testhelper.LowFee))
+	}
+
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   1,
+			PrevBlock: prev.BlockHash(),
+			Timestamp: prev.Timestamp.Add(time.Minute),
+			Bits:      params.PowLimitBits,
+		},
+		Transactions: txns,
+	}
+	block := btcutil.NewBlock(msgBlock)
+	merkles := BuildMerkleTreeStore(block.Transactions(), false)
+	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+	return block
+}
+
+// solveTestBlock finds a nonce for which the proof-of-work hash of the passed
+// block at the passed height satisfies the proof-of-work limit and returns the
+// solved block.
+func solveTestBlock(params *chaincfg.Params, block *btcutil.Block, height int32) (*btcutil.Block, error) {
+	header := &block.MsgBlock().Header
+	algo := params.PowAlgorithmAt(height)
+	for {
+		powHash, err := header.PowHash(algo)
+		if err != nil {
+			return nil, err
+		}
+		if HashToBig(&powHash).Cmp(params.PowLimit) <= 0 {
+			return btcutil.NewBlock(block.MsgBlock()), nil
+		}
+		header.Nonce++
+	}
+}
+
+// coinbaseSpend returns the output of the coinbase of the passed block so it
+// can be spent by a block created with newTestBlock.
+func coinbaseSpend(block *btcutil.Block) testhelper.SpendableOut {
+	return testhelper.MakeSpendableOut(block.MsgBlock(), 0, 0)
+}
@@ -5,12 +5,34 @@
 package blockchain
 
 import (
+	"fmt"
+	"math"
 	"math/big"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 )
 
+const (
+	// kgwPastSecondsMin is the minimum amount of past block time, in
+	// seconds, that the Kimoto Gravity Well examines before it is allowed
+	// to stop early.  It is a quarter of a day.
+	kgwPastSecondsMin = 60 * 60 * 24 / 4
+
+	// kgwPastSecondsMax is the maximum amount of past block time, in
+	// seconds, that the Kimoto Gravity Well examines.  It is a week.
+	kgwPastSecondsMax = 60 * 60 * 24 * 7
+
+	// kgwTimeWarpFixHeight is the height after which the Kimoto Gravity
+	// Well measures the time taken by the examined blocks from the latest
+	// timestamp it has seen rather than from the timestamp of the previous
+	// block, and treats that time as at least one second.  This prevents
+	// blocks with timestamps before those of their ancestors from lowering
+	// the required difficulty.
+	kgwTimeWarpFixHeight = 16000
+)
+
 var (
 	// bigOne is 1 represented as a big.Int.  It is defined here to avoid
 	// the overhead of creating it multiple times.
@@ -213,12 +235,11 @@ func (b *BlockChain) findPrevTestNetDifficulty(startNode *blockNode) uint32 {
 	return lastBits
 }
 
-// calcNextRequiredDifficulty calculates the required difficulty for the block
-// after the passed previous block node based on the difficulty retarget rules.
-// This function differs from the exported CalcNextRequiredDifficulty in that
-// the exported version uses the current best chain as the previous block node
-// while this function accepts any block node.
-func (b *BlockChain) calcNextRequiredDifficulty_Bitcoin(lastNode *blockNode, newBlockTime time.Time) (uint32, error) {
+// calcNextRequiredDifficultyBitcoin calculates the required difficulty for the
+// block after the passed previous block node based on the original Bitcoin
+// difficulty retarget rules which only adjust the difficulty once every
+// blocksPerRetarget blocks.
+func (b *BlockChain) calcNextRequiredDifficultyBitcoin(lastNode *blockNode, newBlockTime time.Time) (uint32, error) {
 	// Genesis block.
 	if lastNode == nil {
 		return b.chainParams.PowLimitBits, nil
@@ -307,6 +328,160 @@ func (b *BlockChain) calcNextRequiredDifficulty_Bitcoin(lastNode *blockNode, new
 	return newTargetBits, nil
 }
 
+// kgwEventHorizonDeviation returns the deviation from the expected block rate
+// beyond which the Kimoto Gravity Well stops examining blocks once the passed
+// number of blocks have been examined.  It is calculated as:
+//
+//	1 + 0.7084 * (pastBlocksMass / 144)^-1.228
+//
+// The reference implementation performs the calculation with double precision
+// floating point arithmetic, so it is reproduced with the same operations.
+func kgwEventHorizonDeviation(pastBlocksMass int64) float64 {
+	// The conversion of the product prevents it from being fused with the
+	// addition, which would round differently.
+	power := math.Pow(float64(pastBlocksMass)/144, -1.228)
+	return 1 + float64(0.7084*power)
+}
+
+// calcNextRequiredDifficultyKGW calculates the required difficulty for the
+// block after the passed previous block node based on the Kimoto Gravity Well
+// retarget rules.
+//
+// The Kimoto Gravity Well walks backwards from the previous block, averaging
+// the targets of the blocks it visits, until the observed block rate deviates
+// from the expected rate by more than an "event horizon" that narrows as more
+// blocks are examined.  The averaged target is then scaled by the ratio of the
+// actual to the expected time taken by the examined blocks.
+func (b *BlockChain) calcNextRequiredDifficultyKGW(lastNode *blockNode, newBlockTime time.Time) (uint32, error) {
+	// For networks that support it, allow special reduction of the
+	// required difficulty once too much time has elapsed without mining a
+	// block.
+	if b.chainParams.ReduceMinDifficulty {
+		reductionTime := int64(b.chainParams.MinDiffReductionTime /
+			time.Second)
+		allowMinTime := lastNode.timestamp + reductionTime
+		if newBlockTime.Unix() > allowMinTime {
+			return b.chainParams.PowLimitBits, nil
+		}
+	}
+
+	targetSpacing := int64(b.chainParams.TargetTimePerBlock / time.Second)
+	pastBlocksMin := int64(kgwPastSecondsMin) / targetSpacing
+	pastBlocksMax := int64(kgwPastSecondsMax) / targetSpacing
+
+	// Use the minimum difficulty until there are enough blocks for the
+	// gravity well to examine.
+	if lastNode.height == 0 || int64(lastNode.height) < pastBlocksMin {
+		return b.chainParams.PowLimitBits, nil
+	}
+
+	var pastBlocksMass, actualSeconds, targetSeconds int64
+	var avgTarget, prevAvgTarget *big.Int
+	latestBlockTime := lastNode.timestamp
+	iterNode := lastNode
+	for i := int64(1); iterNode != nil && iterNode.height > 0; i++ {
+		if pastBlocksMax > 0 && i > pastBlocksMax {
+			break
+		}
+		pastBlocksMass++
+
+		// Maintain a running average of the targets of the examined
+		// blocks.  The quotient is truncated towards zero in order to
+		// match the unsigned arithmetic of the reference implementation
+		// in both directions.
+		avgTarget = CompactToBig(iterNode.bits)
+		if i > 1 {
+			delta := new(big.Int).Sub(avgTarget, prevAvgTarget)
+			delta.Quo(delta, big.NewInt(i))
+			avgTarget.Add(prevAvgTarget, delta)
+		}
+		prevAvgTarget = avgTarget
+
+		// Measure the time taken by the examined blocks.  After the
+		// time warp fix, it is measured from the latest timestamp seen
+		// so far and is at least one second, while before it is
+		// measured from the previous block and is at least zero.
+		timeWarpFixed := iterNode.height > kgwTimeWarpFixHeight
+		if timeWarpFixed && iterNode.timestamp > latestBlockTime {
+			latestBlockTime = iterNode.timestamp
+		}
+		actualSeconds = latestBlockTime - iterNode.timestamp
+		targetSeconds = targetSpacing * pastBlocksMass
+		if timeWarpFixed && actualSeconds < 1 {
+			actualSeconds = 1
+		} else if actualSeconds < 0 {
+			actualSeconds = 0
+		}
+		adjustmentRatio := 1.0
+		if actualSeconds != 0 && targetSeconds != 0 {
+			adjustmentRatio = float64(targetSeconds) /
+				float64(actualSeconds)
+		}
+
+		// Stop once the rate of the examined blocks falls outside of
+		// the event horizon for the number of blocks examined so far.
+		if pastBlocksMass >= pastBlocksMin {
+			eventHorizonDeviation := kgwEventHorizonDeviation(
+				pastBlocksMass)
+			if adjustmentRatio <= 1/eventHorizonDeviation ||
+				adjustmentRatio >= eventHorizonDeviation {
+
+				break
+			}
+		}
+
+		iterNode = iterNode.parent
+	}
+
+	// Calculate new target difficulty as:
+	//  averageTarget * (actualSeconds / targetSeconds)
+	// The result uses integer division which means it will be slightly
+	// rounded down just as it is in the reference implementation.
+	newTarget := new(big.Int).Set(avgTarget)
+	if actualSeconds != 0 && targetSeconds != 0 {
+		newTarget.Mul(newTarget, big.NewInt(actualSeconds))
+		newTarget.Div(newTarget, big.NewInt(targetSeconds))
+	}
+
+	// Limit new value to the proof of work limit.
+	if newTarget.Cmp(b.chainParams.PowLimit) > 0 {
+		newTarget.Set(b.chainParams.PowLimit)
+	}
+
+	newTargetBits := BigToCompact(newTarget)
+	log.Tracef("KGW retarget at block height %d examined %d blocks: "+
+		"old target %08x, new target %08x", lastNode.height+1,
+		pastBlocksMass, lastNode.bits, newTargetBits)
+
+	return newTargetBits, nil
+}
+
+// calcNextRequiredDifficulty calculates the required difficulty for the block
+// after the passed previous block node based on the difficulty retarget rules
+// that are in effect at its height.  This function differs from the exported
+// CalcNextRequiredDifficulty in that the exported version uses the current
+// best chain as the previous block node while this function accepts any block
+// node.
+func (b *BlockChain) calcNextRequiredDifficulty(lastNode *blockNode, newBlockTime time.Time) (uint32, error) {
+	// Genesis block.
+	if lastNode == nil {
+		return b.chainParams.PowLimitBits, nil
+	}
+
+	algorithm := b.chainParams.DifficultyAlgorithmAt(lastNode.height + 1)
+	switch algorithm {
+	case chaincfg.DiffBitcoin:
+		return b.calcNextRequiredDifficultyBitcoin(lastNode, newBlockTime)
+
+	case chaincfg.DiffKimotoGravityWell:
+		return b.calcNextRequiredDifficultyKGW(lastNode, newBlockTime)
+	}
+
+	str := fmt.Sprintf("unknown difficulty algorithm %v at height %d",
+		algorithm, lastNode.height+1)
+	return 0, AssertError(str)
+}
+
 // CalcNextRequiredDifficulty calculates the required difficulty for the block
 // after the end of the current best chain based on the difficulty retarget
 // rules.
@@ -5,8 +5,12 @@
 package blockchain
 
 import (
+	"math"
 	"math/big"
 	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
 )
 
 // TestBigToCompact ensures BigToCompact converts big integers to the expected
@@ -73,3 +77,287 @@ func TestCalcWork(t *testing.T) {
 		}
 	}
 }
+
+// TestCalcNextRequiredDifficultyKGW ensures the Kimoto Gravity Well retarget
+// produces the expected difficulty for chains with known block rates.
+func TestCalcNextRequiredDifficultyKGW(t *testing.T) {
+	t.Parallel()
+
+	const startBits = 0x1b0404cb
+	startTarget := CompactToBig(startBits)
+
+	// scaledBits returns the compact form of the start target multiplied
+	// by num/den.
+	scaledBits := func(num, den int64) uint32 {
+		n := new(big.Int).Mul(startTarget, big.NewInt(num))
+		return BigToCompact(n.Div(n, big.NewInt(den)))
+	}
+
+	tests := []struct {
+		name      string
+		numBlocks int32  // number of blocks after genesis
+		spacing   int64  // seconds between the generated blocks
+		wantBits  uint32 // expected bits for the next block
+	}{{
+		// Not enough blocks for the gravity well to examine.
+		name:      "short chain",
+		numBlocks: 143,
+		spacing:   150,
+		wantBits:  chaincfg.MainNetParams.PowLimitBits,
+	}, {
+		// Exactly enough blocks for the gravity well to examine.  The
+		// genesis block is never examined, so the actual timespan is
+		// one block interval short of the target.
+		name:      "minimum blocks",
+		numBlocks: 144,
+		spacing:   150,
+		wantBits:  scaledBits(143, 144),
+	}, {
+		// Blocks at the target rate up to the maximum number of blocks
+		// the gravity well examines.
+		name:      "maximum blocks",
+		numBlocks: 4032,
+		spacing:   150,
+		wantBits:  scaledBits(4031, 4032),
+	}, {
+		// Blocks at exactly the target rate never leave the event
+		// horizon, so the full week of blocks is examined and the
+		// actual timespan is one block interval short of the target.
+		name:      "target rate",
+		numBlocks: 5000,
+		spacing:   150,
+		wantBits:  scaledBits(4031, 4032),
+	}, {
+		// Blocks at twice the target rate are outside of the event
+		// horizon as soon as the minimum number of blocks have been
+		// examined.
+		name:      "double rate",
+		numBlocks: 5000,
+		spacing:   75,
+		wantBits:  scaledBits(143*75, 144*150),
+	}, {
+		// Blocks at half the target rate are outside of the event
+		// horizon as soon as the minimum number of blocks have been
+		// examined.
+		name:      "half rate",
+		numBlocks: 5000,
+		spacing:   300,
+		wantBits:  scaledBits(143*300, 144*150),
+	}}
+
+	for _, test := range tests {
+		params := chaincfg.MainNetParams
+		bc := newFakeChain(&params)
+		node := bc.bestChain.Tip()
+		blockTime := node.Header().Timestamp
+		for i := int32(0); i < test.numBlocks; i++ {
+			blockTime = blockTime.Add(time.Duration(test.spacing) *
+				time.Second)
+			node = newFakeNode(node, 1, startBits, blockTime)
+			bc.index.AddNode(node)
+		}
+
+		nextTime := blockTime.Add(time.Duration(test.spacing) * time.Second)
+		gotBits, err := bc.calcNextRequiredDifficulty(node, nextTime)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if gotBits != test.wantBits {
+			t.Errorf("%s: mismatched bits - got %08x, want %08x",
+				test.name, gotBits, test.wantBits)
+		}
+	}
+}
+
+// TestCalcNextRequiredDifficultyKGWTimeWarp ensures the Kimoto Gravity Well
+// measures the time taken by the examined blocks according to the rules in
+// effect at their heights when timestamps are out of order or equal.
+func TestCalcNextRequiredDifficultyKGWTimeWarp(t *testing.T) {
+	t.Parallel()
+
+	const startBits = 0x1b0404cb
+	startTarget := CompactToBig(startBits)
+
+	// scaledBits returns the compact form of the start target multiplied
+	// by num/den.
+	scaledBits := func(num, den int64) uint32 {
+		n := new(big.Int).Mul(startTarget, big.NewInt(num))
+		return BigToCompact(n.Div(n, big.NewInt(den)))
+	}
+
+	tests := []struct {
+		name      string
+		numBlocks int32  // number of blocks after genesis
+		spacing   int64  // seconds between the generated blocks
+		tipOffset int64  // seconds between the tip and its parent
+		wantBits  uint32 // expected bits for the next block
+	}{{
+		// Before the fix, the time is measured from the tip, which
+		// is ten block intervals before its parent.  The eleven blocks
+		// after it count as zero seconds.
+		name:      "tip in the past before fix",
+		numBlocks: 5000,
+		spacing:   150,
+		tipOffset: -1500,
+		wantBits:  scaledBits(4020, 4032),
+	}, {
+		// After the fix, the time is measured from the latest
+		// timestamp, which is the one of the parent of the tip.
+		name:      "tip in the past after fix",
+		numBlocks: 20100,
+		spacing:   150,
+		tipOffset: -1500,
+		wantBits:  scaledBits(4030, 4032),
+	}, {
+		// Before the fix, blocks with equal timestamps take zero
+		// seconds, so the rate never leaves the event horizon and the
+		// average target is not scaled.
+		name:      "equal timestamps before fix",
+		numBlocks: 5000,
+		spacing:   0,
+		tipOffset: 0,
+		wantBits:  startBits,
+	}, {
+		// After the fix, blocks with equal timestamps take one second,
+		// so the rate is outside of the event horizon as soon as the
+		// minimum number of blocks have been examined.
+		name:      "equal timestamps after fix",
+		numBlocks: 20100,
+		spacing:   0,
+		tipOffset: 0,
+		wantBits:  scaledBits(1, 144*150),
+	}}
+
+	for _, test := range tests {
+		params := chaincfg.MainNetParams
+		bc := newFakeChain(&params)
+		node := bc.bestChain.Tip()
+		blockTime := node.Header().Timestamp
+		for i := int32(0); i < test.numBlocks; i++ {
+			spacing := test.spacing
+			if i == test.numBlocks-1 {
+				spacing = test.tipOffset
+			}
+			blockTime = blockTime.Add(time.Duration(spacing) *
+				time.Second)
+			node = newFakeNode(node, 1, startBits, blockTime)
+			bc.index.AddNode(node)
+		}
+
+		gotBits, err := bc.calcNextRequiredDifficulty(node, blockTime)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if gotBits != test.wantBits {
+			t.Errorf("%s: mismatched bits - got %08x, want %08x",
+				test.name, gotBits, test.wantBits)
+		}
+	}
+}
+
+// TestKGWEventHorizonDeviation ensures the event horizon of the Kimoto Gravity
+// Well classifies block rates the same way as the reference implementation.
+// The expected deviations were calculated by the pow function of glibc 2.36.
+// They include every number of examined blocks on the main network for which
+// math.Pow rounds to a different double, so the test checks every actual
+// timespan next to both edges of the event horizon for them.
+func TestKGWEventHorizonDeviation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pastBlocksMass int64
+		want           uint64 // IEEE 754 bits of the deviation
+	}{
+		{144, 0x3ffb559b3d07c84c}, {145, 0x3ffb3d0d59b02f4a}, {148, 0x3ffaf59a6de0ec88},
+		{154, 0x3ffa6ff94b0d3d45}, {158, 0x3ffa1d25ad5b123b}, {162, 0x3ff9cedd340462c5},
+		{163, 0x3ff9bbf60c22c994}, {167, 0x3ff972ddb3b25b7e}, {182, 0x3ff8806803eb21cf},
+		{183, 0x3ff871cf97407457}, {230, 0x3ff660b2af7c70eb}, {238, 0x3ff61d90abd9a40c},
+		{255, 0x3ff59e6389cb0cde}, {258, 0x3ff589e0951497a0}, {259, 0x3ff583285ae81a66},
+		{261, 0x3ff575e3ec0fe880}, {263, 0x3ff568d8fc173dee}, {265, 0x3ff55c0624b7a96b},
+		{288, 0x3ff4d6b8b267de5c}, {294, 0x3ff4b7c00d480dd6}, {306, 0x3ff47dd9f73668da},
+		{319, 0x3ff444941dd45c85}, {325, 0x3ff42bdc82514320}, {338, 0x3ff3f9a618fcef5a},
+		{348, 0x3ff3d5db717727b2}, {362, 0x3ff3a76f4e297362}, {366, 0x3ff39ae56ec5c3e5},
+		{381, 0x3ff36e7ad2fa5b84}, {415, 0x3ff316f11fc8fa65}, {424, 0x3ff3026015df3bef},
+		{441, 0x3ff2de11bcb099be}, {458, 0x3ff2bcc0b6a8ca22}, {496, 0x3ff27b69becbb63f},
+		{570, 0x3ff217aa4141fae6}, {617, 0x3ff1e60057476f62}, {619, 0x3ff1e412e0a168d4},
+		{621, 0x3ff1e228f41bc818}, {686, 0x3ff1aaadea008ddc}, {702, 0x3ff19ec4b638c512},
+		{712, 0x3ff197a055eb0984}, {753, 0x3ff17c8aef3c515b}, {802, 0x3ff1603192c91319},
+		{834, 0x3ff14fac2750630d}, {865, 0x3ff140f5f08bde18}, {903, 0x3ff13074799f3be0},
+		{904, 0x3ff1300a9d117d04}, {1000, 0x3ff10c99dbc45892}, {1014, 0x3ff1080dde270cb5},
+		{1018, 0x3ff106c7d8282254}, {1052, 0x3ff0fc63dd769050}, {1100, 0x3ff0eeef07810f99},
+		{1108, 0x3ff0ecd124c32fe2}, {1194, 0x3ff0d80bc9d5f3b1}, {1253, 0x3ff0cb9f1d7cda42},
+		{1345, 0x3ff0baa75aeba1c2}, {1596, 0x3ff09747d5cb6596}, {1779, 0x3ff08466ae27b26a},
+		{1815, 0x3ff0812efbabbc6f}, {1848, 0x3ff07e5b444dbee8}, {2435, 0x3ff05a0cf8339d40},
+		{2587, 0x3ff05398fa00c571}, {2983, 0x3ff0462ee9284155}, {3205, 0x3ff04042f13e0871},
+		{3208, 0x3ff040300d6d587a}, {3414, 0x3ff03b76fdd8ec0c}, {3966, 0x3ff03177fcd6b696},
+		{4031, 0x3ff0307daf2240ff}, {4032, 0x3ff03079e749b386},
+	}
+
+	// outside returns whether the passed block rate is outside of the
+	// event horizon with the passed deviation.
+	outside := func(adjustmentRatio, deviation float64) bool {
+		return adjustmentRatio <= 1/deviation ||
+			adjustmentRatio >= deviation
+	}
+
+	params := &chaincfg.MainNetParams
+	targetSpacing := int64(params.TargetTimePerBlock / time.Second)
+	for _, test := range tests {
+		got := kgwEventHorizonDeviation(test.pastBlocksMass)
+		want := math.Float64frombits(test.want)
+		targetSeconds := float64(targetSpacing * test.pastBlocksMass)
+		for _, edge := range []float64{targetSeconds / want,
+			targetSeconds * want} {
+
+			for actual := int64(edge) - 2; actual <= int64(edge)+2; actual++ {
+				ratio := targetSeconds / float64(actual)
+				if outside(ratio, got) != outside(ratio, want) {
+					t.Errorf("mass %d: mismatched event horizon "+
+						"for %d seconds - got deviation %v, "+
+						"want %v", test.pastBlocksMass, actual,
+						got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestCalcNextRequiredDifficultyKGWMinDiff ensures networks that allow minimum
+// difficulty blocks return the proof of work limit when a block has not been
+// found for longer than the reduction time.
+func TestCalcNextRequiredDifficultyKGWMinDiff(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.TestNet3Params
+	bc := newFakeChain(&params)
+	node := bc.bestChain.Tip()
+	blockTime := node.Header().Timestamp
+	for i := 0; i < 200; i++ {
+		blockTime = blockTime.Add(75 * time.Second)
+		node = newFakeNode(node, 1, 0x1c0ffff0, blockTime)
+		bc.index.AddNode(node)
+	}
+
+	// A block within the reduction time must follow the normal rules.
+	gotBits, err := bc.calcNextRequiredDifficulty(node,
+		blockTime.Add(params.MinDiffReductionTime))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotBits == params.PowLimitBits {
+		t.Fatalf("unexpected minimum difficulty for timely block")
+	}
+
+	// A block after the reduction time is allowed the minimum difficulty.
+	gotBits, err = bc.calcNextRequiredDifficulty(node,
+		blockTime.Add(params.MinDiffReductionTime+time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotBits != params.PowLimitBits {
+		t.Fatalf("mismatched bits - got %08x, want %08x", gotBits,
+			params.PowLimitBits)
+	}
+}
@@ -220,6 +220,11 @@ const (
 	// current chain tip. This is not a block validation rule, but is required
 	// for block proposals submitted via getblocktemplate RPC.
 	ErrPrevBlockNotBest
+
+	// ErrMissingDeploymentSignal indicates that a block does not signal for
+	// a deployment although it is in the period all blocks are required to
+	// signal for it in order to lock it in on timeout.
+	ErrMissingDeploymentSignal
 )
 
 // Map of ErrorCode values back to their constant names for pretty printing.
@@ -267,6 +272,7 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrPreviousBlockUnknown:      "ErrPreviousBlockUnknown",
 	ErrInvalidAncestorBlock:      "ErrInvalidAncestorBlock",
 	ErrPrevBlockNotBest:          "ErrPrevBlockNotBest",
+	ErrMissingDeploymentSignal:   "ErrMissingDeploymentSignal",
 }
 
 // String returns the ErrorCode as a human-readable name.
@@ -69,7 +69,7 @@ func ExampleBlockChain_ProcessBlock() {
 	fmt.Printf("Block accepted. Is it an orphan?: %v", isOrphan)
 
 	// Output:
-	// Failed to process block: already have block 000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f
+	// Failed to process block: already have block 4d96a915f49d40b1e5c2844d1ee2dccb90013a990ccea12c492d22110489f0c4
 }
 
 // This example demonstrates how to convert the compact "bits" in a block header
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -43,16 +44,6 @@ const (
 	numLargeReorgBlocks = 1088
 )
 
-var (
-	// opTrueScript is simply a public key script that contains the OP_TRUE
-	// opcode.  It is defined here to reduce garbage creation.
-	opTrueScript = []byte{txscript.OP_TRUE}
-
-	// lowFee is a single satoshi and exists to make the test code more
-	// readable.
-	lowFee = btcutil.Amount(1)
-)
-
 // TestInstance is an interface that describes a specific test instance returned
 // by the tests generated in this package.  It should be type asserted to one
 // of the concrete test instance types in order to test accordingly.
@@ -150,31 +141,6 @@ type RejectedNonCanonicalBlock struct {
 // This implements the TestInstance interface.
 func (b RejectedNonCanonicalBlock) FullBlockTestInstance() {}
 
-// spendableOut represents a transaction output that is spendable along with
-// additional metadata such as the block its in and how much it pays.
-type spendableOut struct {
-	prevOut wire.OutPoint
-	amount  btcutil.Amount
-}
-
-// makeSpendableOutForTx returns a spendable output for the given transaction
-// and transaction output index within the transaction.
-func makeSpendableOutForTx(tx *wire.MsgTx, txOutIndex uint32) spendableOut {
-	return spendableOut{
-		prevOut: wire.OutPoint{
-			Hash:  tx.TxHash(),
-			Index: txOutIndex,
-		},
-		amount: btcutil.Amount(tx.TxOut[txOutIndex].Value),
-	}
-}
-
-// makeSpendableOut returns a spendable output for the given block, transaction
-// index within the block, and transaction output index within the transaction.
-func makeSpendableOut(block *wire.MsgBlock, txIndex, txOutIndex uint32) spendableOut {
-	return makeSpendableOutForTx(block.Transactions[txIndex], txOutIndex)
-}
-
 // testGenerator houses state used to easy the process of generating test blocks
 // that build from one another along with housing other useful things such as
 // available spendable outputs used throughout the tests.
@@ -188,7 +154,7 @@ type testGenerator struct {
 	blockHeights map[string]int32
 
 	// Used for tracking spendable coinbase outputs.
-	spendableOuts     []spendableOut
+	spendableOuts     []testhelper.SpendableOut
 	prevCollectedHash chainhash.Hash
 
 	// Common key for any tests which require signed transactions.
@@ -240,62 +206,12 @@ func pushDataScript(items ...[]byte) []byte {
 	return script
 }
 
-// standardCoinbaseScript returns a standard script suitable for use as the
-// signature script of the coinbase transaction of a new block.  In particular,
-// it starts with the block height that is required by version 2 blocks.
-func standardCoinbaseScript(blockHeight int32, extraNonce uint64) ([]byte, error) {
-	return txscript.NewScriptBuilder().AddInt64(int64(blockHeight)).
-		AddInt64(int64(extraNonce)).Script()
-}
-
-// opReturnScript returns a provably-pruneable OP_RETURN script with the
-// provided data.
-func opReturnScript(data []byte) []byte {
-	builder := txscript.NewScriptBuilder()
-	script, err := builder.AddOp(txscript.OP_RETURN).AddData(data).Script()
-	if err != nil {
-		panic(err)
-	}
-	return script
-}
-
-// uniqueOpReturnScript returns a standard provably-pruneable OP_RETURN script
-// with a random uint64 encoded as the data.
-func uniqueOpReturnScript() []byte {
-	rand, err := wire.RandomUint64()
-	if err != nil {
-		panic(err)
-	}
-
-	data := make([]byte, 8)
-	binary.LittleEndian.PutUint64(data[0:8], rand)
-	return opReturnScript(data)
-}
-
 // createCoinbaseTx returns a coinbase transaction paying an appropriate
 // subsidy based on the passed block height.  The coinbase signature script
 // conforms to the requirements of version 2 blocks.
 func (g *testGenerator) createCoinbaseTx(blockHeight int32) *wire.MsgTx {
-	extraNonce := uint64(0)
-	coinbaseScript, err := standardCoinbaseScript(blockHeight, extraNonce)
-	if err != nil {
-		panic(err)
-	}
-
-	tx := wire.NewMsgTx(1)
-	tx.AddTxIn(&wire.TxIn{
-		// Coinbase transactions have no inputs, so previous outpoint is
-		// zero hash and max index.
-		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
-			wire.MaxPrevOutIndex),
-		Sequence:        wire.MaxTxInSequenceNum,
-		SignatureScript: coinbaseScript,
-	})
-	tx.AddTxOut(&wire.TxOut{
-		Value:    blockchain.CalcBlockSubsidy(blockHeight, g.params),
-		PkScript: opTrueScript,
-	})
-	return tx
+	return testhelper.CreateCoinbaseTx(blockHeight,
+		blockchain.CalcBlockSubsidy(blockHeight, g.params))
 }
 
 // calcMerkleRoot creates a merkle tree from the slice of transactions and
@@ -321,7 +237,7 @@ func calcMerkleRoot(txns []*wire.MsgTx) chainhash.Hash {
 // NOTE: This function will never solve blocks with a nonce of 0.  This is done
 // so the 'nextBlock' function can properly detect when a nonce was modified by
 // a munge function.
-func solveBlock(header *wire.BlockHeader) bool {
+func solveBlock(header *wire.BlockHeader, powAlgo wire.PowAlgorithm) bool {
 	// sbResult is used by the solver goroutines to send results.
 	type sbResult struct {
 		found bool
@@ -342,7 +258,11 @@ func solveBlock(header *wire.BlockHeader) bool {
 				return
 			default:
 				hdr.Nonce = i
-				hash := hdr.PowHash()
+				hash, err := hdr.PowHash(powAlgo)
+				if err != nil {
+					results <- sbResult{false, 0}
+					return
+				}
 				if blockchain.HashToBig(&hash).Cmp(
 					targetDifficulty) <= 0 {
 
@@ -430,35 +350,6 @@ func additionalTx(tx *wire.MsgTx) func(*wire.MsgBlock) {
 	}
 }
 
-// createSpendTx creates a transaction that spends from the provided spendable
-// output and includes an additional unique OP_RETURN output to ensure the
-// transaction ends up with a unique hash.  The script is a simple OP_TRUE
-// script which avoids the need to track addresses and signature scripts in the
-// tests.
-func createSpendTx(spend *spendableOut, fee btcutil.Amount) *wire.MsgTx {
-	spendTx := wire.NewMsgTx(1)
-	spendTx.AddTxIn(&wire.TxIn{
-		PreviousOutPoint: spend.prevOut,
-		Sequence:         wire.MaxTxInSequenceNum,
-		SignatureScript:  nil,
-	})
-	spendTx.AddTxOut(wire.NewTxOut(int64(spend.amount-fee),
-		opTrueScript))
-	spendTx.AddTxOut(wire.NewTxOut(0, uniqueOpReturnScript()))
-
-	return spendTx
-}
-
-// createSpendTxForTx creates a transaction that spends from the first output of
-// the provided transaction and includes an additional unique OP_RETURN output
-// to ensure the transaction ends up with a unique hash.  The public key script
-// is a simple OP_TRUE script which avoids the need to track addresses and
-// signature scripts in the tests.  The signature script is nil.
-func createSpendTxForTx(tx *wire.MsgTx, fee btcutil.Amount) *wire.MsgTx {
-	spend := makeSpendableOutForTx(tx, 0)
-	return createSpendTx(&spend, fee)
-}
-
 // nextBlock builds a new block that extends the current tip associated with the
 // generator and updates the generator's tip to the newly generated block.
 //
@@ -478,7 +369,7 @@ func createSpendTxForTx(tx *wire.MsgTx, fee btcutil.Amount) *wire.MsgTx {
 // applied after all munge functions have been invoked:
 // - The merkle root will be recalculated unless it was manually changed
 // - The block will be solved unless the nonce was changed
-func (g *testGenerator) nextBlock(blockName string, spend *spendableOut, mungers ...func(*wire.MsgBlock)) *wire.MsgBlock {
+func (g *testGenerator) nextBlock(blockName string, spend *testhelper.SpendableOut, mungers ...func(*wire.MsgBlock)) *wire.MsgBlock {
 	// Create coinbase transaction for the block using any additional
 	// subsidy if specified.
 	nextHeight := g.tipHeight + 1
@@ -496,7 +387,7 @@ func (g *testGenerator) nextBlock(blockName string, spend *spendableOut, mungers
 		// add it to the list of transactions to include in the block.
 		// The script is a simple OP_TRUE script in order to avoid the
 		// need to track addresses and signature scripts in the tests.
-		txns = append(txns, createSpendTx(spend, fee))
+		txns = append(txns, testhelper.CreateSpendTx(spend, fee))
 	}
 
 	// Use a timestamp that is one second after the previous block unless
@@ -533,7 +424,8 @@ func (g *testGenerator) nextBlock(blockName string, spend *spendableOut, mungers
 
 	// Only solve the block if the nonce wasn't manually changed by a munge
 	// function.
-	if block.Header.Nonce == curNonce && !solveBlock(&block.Header) {
+	if block.Header.Nonce == curNonce && !solveBlock(&block.Header,
+		g.params.PowAlgorithmAt(nextHeight)) {
 		panic(fmt.Sprintf("Unable to solve block at height %d",
 			nextHeight))
 	}
@@ -580,7 +472,7 @@ func (g *testGenerator) setTip(blockName string) {
 
 // oldestCoinbaseOuts removes the oldest coinbase output that was previously
 // saved to the generator and returns the set as a slice.
-func (g *testGenerator) oldestCoinbaseOut() spendableOut {
+func (g *testGenerator) oldestCoinbaseOut() testhelper.SpendableOut {
 	op := g.spendableOuts[0]
 	g.spendableOuts = g.spendableOuts[1:]
 	return op
@@ -589,7 +481,7 @@ func (g *testGenerator) oldestCoinbaseOut() spendableOut {
 // saveTipCoinbaseOut adds the coinbase tx output in the current tip block to
 // the list of spendable outputs.
 func (g *testGenerator) saveTipCoinbaseOut() {
-	g.spendableOuts = append(g.spendableOuts, makeSpendableOut(g.tip, 0, 0))
+	g.spendableOuts = append(g.spendableOuts, testhelper.MakeSpendableOut(g.tip, 0, 0))
 	g.prevCollectedHash = g.tip.BlockHash()
 }
 
@@ -811,7 +703,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 
 	// Create a test generator instance initialized with the genesis block
 	// as the tip.
-	g, err := makeTestGenerator(regressionNetParams)
+	g, err := makeTestGenerator(FbRegressionNetParams)
 	if err != nil {
 		return nil, err
 	}
@@ -924,7 +816,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	tests = append(tests, testInstances)
 
 	// Collect spendable outputs.  This simplifies the code below.
-	var outs []*spendableOut
+	var outs []*testhelper.SpendableOut
 	for i := uint16(0); i < coinbaseMaturity; i++ {
 		op := g.oldestCoinbaseOut()
 		outs = append(outs, &op)
@@ -962,7 +854,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	//               \-> b3(1)
 	g.setTip("b1")
 	g.nextBlock("b3", outs[1])
-	b3Tx1Out := makeSpendableOut(g.tip, 1, 0)
+	b3Tx1Out := testhelper.MakeSpendableOut(g.tip, 1, 0)
 	acceptedToSideChainWithExpectedTip("b2")
 
 	// Extend b3 fork to make the alternative chain longer and force reorg.
@@ -1275,9 +1167,9 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	//                 \-> b38(b37.tx[1])
 	//
 	g.setTip("b35")
-	doubleSpendTx := createSpendTx(outs[11], lowFee)
+	doubleSpendTx := testhelper.CreateSpendTx(outs[11], testhelper.LowFee)
 	g.nextBlock("b37", outs[11], additionalTx(doubleSpendTx))
-	b37Tx1Out := makeSpendableOut(g.tip, 1, 0)
+	b37Tx1Out := testhelper.MakeSpendableOut(g.tip, 1, 0)
 	rejected(blockchain.ErrMissingTxOut)
 
 	g.setTip("b35")
@@ -1313,7 +1205,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 		txnsNeeded := (maxBlockSigOps / redeemScriptSigOps) + 1
 		prevTx := b.Transactions[1]
 		for i := 0; i < txnsNeeded; i++ {
-			prevTx = createSpendTxForTx(prevTx, lowFee)
+			prevTx = testhelper.CreateSpendTxForTx(prevTx, testhelper.LowFee)
 			prevTx.TxOut[0].Value -= 2
 			prevTx.AddTxOut(wire.NewTxOut(2, p2shScript))
 			b.AddTransaction(prevTx)
@@ -1333,8 +1225,8 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 		for i := 0; i < txnsNeeded; i++ {
 			// Create a signed transaction that spends from the
 			// associated p2sh output in b39.
-			spend := makeSpendableOutForTx(b39.Transactions[i+2], 2)
-			tx := createSpendTx(&spend, lowFee)
+			spend := testhelper.MakeSpendableOutForTx(b39.Transactions[i+2], 2)
+			tx := testhelper.CreateSpendTx(&spend, testhelper.LowFee)
 			sig, err := txscript.RawTxInSignature(tx, 0,
 				redeemScript, txscript.SigHashAll, g.privKey)
 			if err != nil {
@@ -1350,7 +1242,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 		// the block one over the max allowed.
 		fill := maxBlockSigOps - (txnsNeeded * redeemScriptSigOps) + 1
 		finalTx := b.Transactions[len(b.Transactions)-1]
-		tx := createSpendTxForTx(finalTx, lowFee)
+		tx := testhelper.CreateSpendTxForTx(finalTx, testhelper.LowFee)
 		tx.TxOut[0].PkScript = repeatOpcode(txscript.OP_CHECKSIG, fill)
 		b.AddTransaction(tx)
 	})
@@ -1364,8 +1256,8 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	g.nextBlock("b41", outs[12], func(b *wire.MsgBlock) {
 		txnsNeeded := (maxBlockSigOps / redeemScriptSigOps)
 		for i := 0; i < txnsNeeded; i++ {
-			spend := makeSpendableOutForTx(b39.Transactions[i+2], 2)
-			tx := createSpendTx(&spend, lowFee)
+			spend := testhelper.MakeSpendableOutForTx(b39.Transactions[i+2], 2)
+			tx := testhelper.CreateSpendTx(&spend, testhelper.LowFee)
 			sig, err := txscript.RawTxInSignature(tx, 0,
 				redeemScript, txscript.SigHashAll, g.privKey)
 			if err != nil {
@@ -1384,7 +1276,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 			return
 		}
 		finalTx := b.Transactions[len(b.Transactions)-1]
-		tx := createSpendTxForTx(finalTx, lowFee)
+		tx := testhelper.CreateSpendTxForTx(finalTx, testhelper.LowFee)
 		tx.TxOut[0].PkScript = repeatOpcode(txscript.OP_CHECKSIG, fill)
 		b.AddTransaction(tx)
 	})
@@ -1414,7 +1306,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	//   ... -> b43(13)
 	//                 \-> b44(14)
 	g.nextBlock("b44", nil, func(b *wire.MsgBlock) {
-		nonCoinbaseTx := createSpendTx(outs[14], lowFee)
+		nonCoinbaseTx := testhelper.CreateSpendTx(outs[14], testhelper.LowFee)
 		b.Transactions[0] = nonCoinbaseTx
 	})
 	rejected(blockchain.ErrFirstTxNotCoinbase)
@@ -1440,12 +1332,16 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	// requires an unsolved block.
 	{
 		origHash := b46.BlockHash()
+		powAlgo := g.params.PowAlgorithmAt(g.tipHeight)
 		for {
-			// Keep incrementing the nonce until the hash treated as
-			// a uint256 is higher than the limit.
+			// Keep incrementing the nonce until the proof-of-work
+			// hash treated as a uint256 is higher than the limit.
 			b46.Header.Nonce++
-			blockHash := b46.BlockHash()
-			hashNum := blockchain.HashToBig(&blockHash)
+			powHash, err := b46.Header.PowHash(powAlgo)
+			if err != nil {
+				return nil, err
+			}
+			hashNum := blockchain.HashToBig(&powHash)
 			if hashNum.Cmp(g.params.PowLimit) >= 0 {
 				break
 			}
@@ -1619,7 +1515,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	g.setTip("b55")
 	b57 := g.nextBlock("b57", outs[16], func(b *wire.MsgBlock) {
 		tx2 := b.Transactions[1]
-		tx3 := createSpendTxForTx(tx2, lowFee)
+		tx3 := testhelper.CreateSpendTxForTx(tx2, testhelper.LowFee)
 		b.AddTransaction(tx3)
 	})
 	g.assertTipBlockNumTxns(3)
@@ -1664,7 +1560,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 		// in the block.
 		spendTx := b.Transactions[1]
 		for i := 0; i < 4; i++ {
-			spendTx = createSpendTxForTx(spendTx, lowFee)
+			spendTx = testhelper.CreateSpendTxForTx(spendTx, testhelper.LowFee)
 			b.AddTransaction(spendTx)
 		}
 
@@ -1696,7 +1592,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	//                 \-> b59(17)
 	g.setTip("b57")
 	g.nextBlock("b59", outs[17], func(b *wire.MsgBlock) {
-		b.Transactions[1].TxOut[0].Value = int64(outs[17].amount) + 1
+		b.Transactions[1].TxOut[0].Value = int64(outs[17].Amount) + 1
 	})
 	rejected(blockchain.ErrSpendTooHigh)
 
@@ -1801,7 +1697,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	//   ... b64(18) -> b65(19)
 	g.setTip("b64")
 	g.nextBlock("b65", outs[19], func(b *wire.MsgBlock) {
-		tx3 := createSpendTxForTx(b.Transactions[1], lowFee)
+		tx3 := testhelper.CreateSpendTxForTx(b.Transactions[1], testhelper.LowFee)
 		b.AddTransaction(tx3)
 	})
 	accepted()
@@ -1811,8 +1707,8 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	//   ... -> b65(19)
 	//                 \-> b66(20)
 	g.nextBlock("b66", nil, func(b *wire.MsgBlock) {
-		tx2 := createSpendTx(outs[20], lowFee)
-		tx3 := createSpendTxForTx(tx2, lowFee)
+		tx2 := testhelper.CreateSpendTx(outs[20], testhelper.LowFee)
+		tx3 := testhelper.CreateSpendTxForTx(tx2, testhelper.LowFee)
 		b.AddTransaction(tx3)
 		b.AddTransaction(tx2)
 	})
@@ -1826,8 +1722,8 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	g.setTip("b65")
 	g.nextBlock("b67", outs[20], func(b *wire.MsgBlock) {
 		tx2 := b.Transactions[1]
-		tx3 := createSpendTxForTx(tx2, lowFee)
-		tx4 := createSpendTxForTx(tx2, lowFee)
+		tx3 := testhelper.CreateSpendTxForTx(tx2, testhelper.LowFee)
+		tx4 := testhelper.CreateSpendTxForTx(tx2, testhelper.LowFee)
 		b.AddTransaction(tx3)
 		b.AddTransaction(tx4)
 	})
@@ -1950,7 +1846,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 		txscript.OP_ELSE, txscript.OP_TRUE, txscript.OP_ENDIF}
 	g.nextBlock("b74", outs[23], replaceSpendScript(script), func(b *wire.MsgBlock) {
 		tx2 := b.Transactions[1]
-		tx3 := createSpendTxForTx(tx2, lowFee)
+		tx3 := testhelper.CreateSpendTxForTx(tx2, testhelper.LowFee)
 		tx3.TxIn[0].SignatureScript = []byte{txscript.OP_FALSE}
 		b.AddTransaction(tx3)
 	})
@@ -1971,23 +1867,23 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 		const zeroCoin = int64(0)
 		spendTx := b.Transactions[1]
 		for i := 0; i < numAdditionalOutputs; i++ {
-			spendTx.AddTxOut(wire.NewTxOut(zeroCoin, opTrueScript))
+			spendTx.AddTxOut(wire.NewTxOut(zeroCoin, testhelper.OpTrueScript))
 		}
 
 		// Add transactions spending from the outputs added above that
 		// each contain an OP_RETURN output.
 		//
-		// NOTE: The createSpendTx func adds the OP_RETURN output.
+		// NOTE: The testhelper.CreateSpendTx func adds the OP_RETURN output.
 		zeroFee := btcutil.Amount(0)
 		for i := uint32(0); i < numAdditionalOutputs; i++ {
-			spend := makeSpendableOut(b, 1, i+2)
-			tx := createSpendTx(&spend, zeroFee)
+			spend := testhelper.MakeSpendableOut(b, 1, i+2)
+			tx := testhelper.CreateSpendTx(&spend, zeroFee)
 			b.AddTransaction(tx)
 		}
 	})
 	g.assertTipBlockNumTxns(6)
 	g.assertTipBlockTxOutOpReturn(5, 1)
-	b75OpReturnOut := makeSpendableOut(g.tip, 5, 1)
+	b75OpReturnOut := testhelper.MakeSpendableOut(g.tip, 5, 1)
 	accepted()
 
 	// Reorg to a side chain that does not contain the OP_RETURNs.
@@ -2020,7 +1916,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	// An OP_RETURN output doesn't have any value and the default behavior
 	// of nextBlock is to assign a fee of one, so increment the amount here
 	// to effective negate that behavior.
-	b75OpReturnOut.amount++
+	b75OpReturnOut.Amount++
 	g.nextBlock("b80", &b75OpReturnOut)
 	rejected(blockchain.ErrMissingTxOut)
 
@@ -2036,7 +1932,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 		const zeroCoin = int64(0)
 		spendTx := b.Transactions[1]
 		for i := 0; i < numAdditionalOutputs; i++ {
-			opRetScript := uniqueOpReturnScript()
+			opRetScript := testhelper.UniqueOpReturnScript()
 			spendTx.AddTxOut(wire.NewTxOut(zeroCoin, opRetScript))
 		}
 	})
@@ -6,6 +6,7 @@ package fullblocktests
 
 import (
 	"encoding/hex"
+	"math"
 	"math/big"
 	"time"
 
@@ -85,14 +86,15 @@ var (
 	}
 )
 
-// regressionNetParams defines the network parameters for the regression test
-// network.
+// FbRegressionNetParams defines the network parameters for the regression test
+// network the tests are generated for.  Consumers must process the generated
+// blocks with these parameters since the tests build on its genesis block.
 //
 // NOTE: The test generator intentionally does not use the existing definitions
 // in the chaincfg package since the intent is to be able to generate known
 // good tests which exercise that code.  Using the chaincfg parameters would
 // allow them to change out from under the tests potentially invalidating them.
-var regressionNetParams = &chaincfg.Params{
+var FbRegressionNetParams = &chaincfg.Params{
 	Name:        "regtest",
 	Net:         wire.TestNet,
 	DefaultPort: "18444",
@@ -117,6 +119,30 @@ var regressionNetParams = &chaincfg.Params{
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
 
+	// Consensus rule change deployments.
+	//
+	// The miner confirmation window is defined as:
+	//   target proof of work timespan / target proof of work spacing
+	RuleChangeActivationThreshold: 108, // 75%  of MinerConfirmationWindow
+	MinerConfirmationWindow:       144,
+	Deployments: [chaincfg.DefinedDeployments]chaincfg.ConsensusDeployment{
+		chaincfg.DeploymentTestDummy: {
+			BitNumber:  28,
+			StartTime:  0,             // Always available for vote
+			ExpireTime: math.MaxInt64, // Never expires
+		},
+		chaincfg.DeploymentCSV: {
+			BitNumber:  0,
+			StartTime:  0,             // Always available for vote
+			ExpireTime: math.MaxInt64, // Never expires
+		},
+		chaincfg.DeploymentSegwit: {
+			BitNumber:  1,
+			StartTime:  0,             // Always available for vote
+			ExpireTime: math.MaxInt64, // Never expires
+		},
+	},
+
 	// Mempool parameters
 	RelayNonStdTxs: true,
 
@@ -404,8 +404,13 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 		bestHeight)
 	for height := lowestHeight + 1; height <= bestHeight; height++ {
 		// Load the block for the height since it is required to index
-		// it.
+		// it.  Indexes can't be caught up across blocks which have been
+		// pruned.
 		block, err := chain.BlockByHeight(height)
+		if blockchain.IsPrunedBlockErr(err) {
+			return fmt.Errorf("unable to catch up indexes from "+
+				"height %d: %v", lowestHeight, err)
+		}
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,136 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package testhelper
+
+import (
+	"encoding/binary"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// OpTrueScript is simply a public key script that contains the OP_TRUE
+	// opcode.  It is defined here to reduce garbage creation.
+	OpTrueScript = []byte{txscript.OP_TRUE}
+
+	// LowFee is a single satoshi and exists to make the test code more
+	// readable.
+	LowFee = btcutil.Amount(1)
+)
+
+// SpendableOut represents a transaction output that is spendable along with
+// additional metadata such as the block its in and how much it pays.
+type SpendableOut struct {
+	PrevOut wire.OutPoint
+	Amount  btcutil.Amount
+}
+
+// MakeSpendableOutForTx returns a spendable output for the given transaction
+// and transaction output index within the transaction.
+func MakeSpendableOutForTx(tx *wire.MsgTx, txOutIndex uint32) SpendableOut {
+	return SpendableOut{
+		PrevOut: wire.OutPoint{
+			Hash:  tx.TxHash(),
+			Index: txOutIndex,
+		},
+		Amount: btcutil.Amount(tx.TxOut[txOutIndex].Value),
+	}
+}
+
+// MakeSpendableOut returns a spendable output for the given block, transaction
+// index within the block, and transaction output index within the transaction.
+func MakeSpendableOut(block *wire.MsgBlock, txIndex, txOutIndex uint32) SpendableOut {
+	return MakeSpendableOutForTx(block.Transactions[txIndex], txOutIndex)
+}
+
+// StandardCoinbaseScript returns a standard script suitable for use as the
+// signature script of the coinbase transaction of a new block.  In particular,
+// it starts with the block height that is required by version 2 blocks.
+func StandardCoinbaseScript(blockHeight int32, extraNonce uint64) ([]byte, error) {
+	return txscript.NewScriptBuilder().AddInt64(int64(blockHeight)).
+		AddInt64(int64(extraNonce)).Script()
+}
+
+// OpReturnScript returns a provably-pruneable OP_RETURN script with the
+// provided data.
+func OpReturnScript(data []byte) []byte {
+	builder := txscript.NewScriptBuilder()
+	script, err := builder.AddOp(txscript.OP_RETURN).AddData(data).Script()
+	if err != nil {
+		panic(err)
+	}
+	return script
+}
+
+// UniqueOpReturnScript returns a standard provably-pruneable OP_RETURN script
+// with a random uint64 encoded as the data.
+func UniqueOpReturnScript() []byte {
+	rand, err := wire.RandomUint64()
+	if err != nil {
+		panic(err)
+	}
+
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data[0:8], rand)
+	return OpReturnScript(data)
+}
+
+// CreateCoinbaseTx returns a coinbase transaction paying the passed subsidy to
+// an OP_TRUE script.  The coinbase signature script conforms to the
+// requirements of version 2 blocks.
+func CreateCoinbaseTx(blockHeight int32, subsidy int64) *wire.MsgTx {
+	extraNonce := uint64(0)
+	coinbaseScript, err := StandardCoinbaseScript(blockHeight, extraNonce)
+	if err != nil {
+		panic(err)
+	}
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(&wire.TxIn{
+		// Coinbase transactions have no inputs, so previous outpoint is
+		// zero hash and max index.
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		Sequence:        wire.MaxTxInSequenceNum,
+		SignatureScript: coinbaseScript,
+	})
+	tx.AddTxOut(&wire.TxOut{
+		Value:    subsidy,
+		PkScript: OpTrueScript,
+	})
+	return tx
+}
+
+// CreateSpendTx creates a transaction that spends from the provided spendable
+// output and includes an additional unique OP_RETURN output to ensure the
+// transaction ends up with a unique hash.  The script is a simple OP_TRUE
+// script which avoids the need to track addresses and signature scripts in the
+// tests.
+func CreateSpendTx(spend *SpendableOut, fee btcutil.Amount) *wire.MsgTx {
+	spendTx := wire.NewMsgTx(1)
+	spendTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: spend.PrevOut,
+		Sequence:         wire.MaxTxInSequenceNum,
+		SignatureScript:  nil,
+	})
+	spendTx.AddTxOut(wire.NewTxOut(int64(spend.Amount-fee),
+		OpTrueScript))
+	spendTx.AddTxOut(wire.NewTxOut(0, UniqueOpReturnScript()))
+
+	return spendTx
+}
+
+// CreateSpendTxForTx creates a transaction that spends from the first output of
+// the provided transaction and includes an additional unique OP_RETURN output
+// to ensure the transaction ends up with a unique hash.  The public key script
+// is a simple OP_TRUE script which avoids the need to track addresses and
+// signature scripts in the tests.  The signature script is nil.
+func CreateSpendTxForTx(tx *wire.MsgTx, fee btcutil.Amount) *wire.MsgTx {
+	spend := MakeSpendableOutForTx(tx, 0)
+	return CreateSpendTx(&spend, fee)
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package testhelper provides the transactions and scripts the full block tests
+build their blocks from, so the tests of the blockchain package itself can
+build blocks which spend earlier outputs the same way.
+
+It can't depend on the blockchain package since the tests of that package
+import it, so the parts which need consensus functions, such as calculating the
+subsidy or solving blocks, remain with the callers.
+*/
+package testhelper
@@ -0,0 +1,381 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+)
+
+// activateBatchSize is the maximum number of blocks activateBestChain loads
+// and connects to the main chain at once.
+const activateBatchSize = 32
+
+// bestChainCandidate returns the block node with the most cumulative work which
+// could become the tip of the main chain, preferring the one most recently
+// marked as precious among those with the same work.  That is the node itself
+// and all of its ancestors which are not already in the main chain must have
+// their data stored and must not be known to be invalid.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) bestChainCandidate() *blockNode {
+	var best *blockNode
+	for _, tip := range b.index.Tips() {
+		// Walk back to the main chain and trim the tip back to below
+		// any block which can't be connected.
+		candidate := tip
+		for n := tip; n != nil && !b.bestChain.Contains(n); n = n.parent {
+			status := b.index.NodeStatus(n)
+			if status.KnownInvalid() || !status.HaveData() {
+				candidate = n.parent
+			}
+		}
+		if candidate == nil {
+			continue
+		}
+		if best == nil || b.index.Preferred(candidate, best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// descendants returns all block nodes in the index which descend from the
+// passed node ordered by height, so every node comes after its parent.
+//
+// Block nodes only link to their parents, so the branches leading to the tips
+// of the block index are walked down to the height of the node.  The walk of a
+// branch stops at the first node already known to descend from the node or
+// not, and at the main chain when the node is not part of it, so unrelated
+// parts of the index are not visited.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) descendants(node *blockNode) []*blockNode {
+	onMainChain := b.bestChain.Contains(node)
+	descends := make(map[*blockNode]bool)
+	var descendants, branch []*blockNode
+	for _, tip := range b.index.Tips() {
+		branch = branch[:0]
+		n, isDescendant := tip, false
+		for ; n.height > node.height; n = n.parent {
+			known, ok := descends[n]
+			if ok {
+				isDescendant = known
+				break
+			}
+			if !onMainChain && b.bestChain.Contains(n) {
+				break
+			}
+			branch = append(branch, n)
+		}
+		if n == node {
+			isDescendant = true
+		}
+
+		for _, n := range branch {
+			descends[n] = isDescendant
+			if isDescendant {
+				descendants = append(descendants, n)
+			}
+		}
+	}
+
+	sort.Slice(descendants, func(i, j int) bool {
+		return descendants[i].height < descendants[j].height
+	})
+	return descendants
+}
+
+// checkDisconnectData ensures the data of all blocks of the main chain after
+// the passed node is stored, so they can be disconnected.  A pruned block error
+// is returned otherwise.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) checkDisconnectData(node *blockNode) error {
+	for n := b.bestChain.Tip(); n != node; n = n.parent {
+		if !b.index.NodeStatus(n).HaveData() {
+			str := fmt.Sprintf("blocks after block %s at height %d "+
+				"can not be disconnected since block %s at height "+
+				"%d has been pruned", node.hash, node.height, n.hash,
+				n.height)
+			return errBlockPruned(str)
+		}
+	}
+	return nil
+}
+
+// disconnectTip disconnects the tip of the main chain.  Only the block and its
+// spend journal entry are loaded, so disconnecting any number of blocks one at
+// a time needs a bounded amount of memory, unlike disconnecting them with a
+// single call to reorganizeChain.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) disconnectTip() error {
+	tip := b.bestChain.Tip()
+
+	// Disconnecting the block may require looking up legacy spend journal
+	// details directly in the database, so make sure it is up to date.
+	err := b.utxoCache.flush(FlushRequired, &tip.hash)
+	if err != nil {
+		return err
+	}
+
+	var block *btcutil.Block
+	var stxos []SpentTxOut
+	err = b.db.View(func(dbTx database.Tx) error {
+		var err error
+		block, err = dbFetchBlockByNode(dbTx, tip)
+		if err != nil {
+			return err
+		}
+		stxos, err = dbFetchSpendJournalEntry(dbTx, block)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Update a view to unspend all of the spent txos and remove the utxos
+	// created by the block, then update the database and chain state.
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&tip.hash)
+	err = view.fetchInputUtxos(b.utxoCache, block)
+	if err != nil {
+		return err
+	}
+	err = view.disconnectTransactions(b.db, block, stxos)
+	if err != nil {
+		return err
+	}
+	return b.disconnectBlock(tip, block, view)
+}
+
+// activateBestChain reorganizes the chain to the candidate tip with the most
+// cumulative work when it is preferred over the current tip, that is when it
+// has more work or the same work and was marked as precious more recently.  Should
+// a block of the candidate chain turn out to be invalid, it is marked as such
+// and the next best candidate is tried instead.
+//
+// The blocks of the main chain after the fork point are disconnected one at a
+// time and the blocks of the candidate chain are connected in batches of at
+// most activateBatchSize blocks, so the memory used does not depend on the
+// depth of the reorganization.  Consequently, callers notify it as a whole with
+// notifyReorg once it has completed.  Nothing is disconnected when any of the blocks to disconnect has been
+// pruned.
+//
+// This function may modify node statuses in the block index without flushing.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) activateBestChain() error {
+	for {
+		best := b.bestChainCandidate()
+		if best == nil || !b.index.Preferred(best, b.bestChain.Tip()) {
+			return nil
+		}
+
+		fork := b.bestChain.FindFork(best)
+		if err := b.checkDisconnectData(fork); err != nil {
+			return err
+		}
+		for b.bestChain.Tip() != fork {
+			if err := b.disconnectTip(); err != nil {
+				return err
+			}
+		}
+
+		var attachNodes []*blockNode
+		for n := best; n != fork; n = n.parent {
+			attachNodes = append(attachNodes, n)
+		}
+		var err error
+		for i := len(attachNodes) - 1; i >= 0 && err == nil; {
+			batch := list.New()
+			for ; i >= 0 && batch.Len() < activateBatchSize; i-- {
+				batch.PushBack(attachNodes[i])
+			}
+			err = b.reorganizeChain(list.New(), batch)
+		}
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(RuleError); !ok {
+			return err
+		}
+		log.Infof("Block %v (height %d) failed to connect: %v", best.hash,
+			best.height, err)
+	}
+}
+
+// notifyReorg sends an NTChainReorg notification for the reorganization of the
+// main chain from the passed old tip to the current tip which was performed in
+// steps.  Only the hashes of the blocks are collected, so the notification does
+// not need memory for the blocks themselves regardless of the depth of the
+// reorganization.  Nothing is sent when blocks were only connected to the old
+// tip.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) notifyReorg(oldTip *blockNode) {
+	fork := b.bestChain.FindFork(oldTip)
+	if fork == oldTip {
+		return
+	}
+
+	tip := b.bestChain.Tip()
+	reorg := &ReorgData{
+		ForkHash:       fork.hash,
+		ForkHeight:     fork.height,
+		DetachedHashes: make([]chainhash.Hash, 0, oldTip.height-fork.height),
+		AttachedHashes: make([]chainhash.Hash, tip.height-fork.height),
+	}
+	for n := oldTip; n != fork; n = n.parent {
+		reorg.DetachedHashes = append(reorg.DetachedHashes, n.hash)
+	}
+	for n := tip; n != fork; n = n.parent {
+		reorg.AttachedHashes[n.height-fork.height-1] = n.hash
+	}
+	b.sendNotification(NTChainReorg, reorg)
+}
+
+// InvalidateBlock marks the block identified by the passed hash as invalid and
+// all of its descendants as having an invalid ancestor.  When the block is part
+// of the main chain, it is disconnected along with all of the blocks after it,
+// one block at a time, and the chain is reorganized to the remaining valid
+// chain with the most cumulative work.  The reorganization is notified with
+// NTChainReorg once it has completed.  The block can be made eligible for the
+// main chain again with ReconsiderBlock.
+//
+// An error for which IsPrunedBlockErr returns true is returned without
+// disconnecting any blocks when the data of any of the blocks to disconnect
+// has been pruned.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) InvalidateBlock(hash *chainhash.Hash) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %s is not known", hash)
+	}
+	if node.parent == nil {
+		return fmt.Errorf("the genesis block can not be invalidated")
+	}
+	if b.snapshot != nil && b.snapshot.base.Ancestor(node.height) == node {
+		return fmt.Errorf("block %s is part of the history of the utxo "+
+			"snapshot which is still being validated", hash)
+	}
+	defer b.notifyReorg(b.bestChain.Tip())
+
+	// Disconnect the block and all of the blocks after it from the main
+	// chain.  This is done before changing the status of any blocks so the
+	// block index is left untouched when they can't be disconnected.
+	if b.bestChain.Contains(node) {
+		if err := b.checkDisconnectData(node.parent); err != nil {
+			return err
+		}
+		for b.bestChain.Contains(node) {
+			if err := b.disconnectTip(); err != nil {
+				return err
+			}
+		}
+	}
+
+	b.index.SetStatusFlags(node, statusValidateFailed)
+	for _, n := range b.descendants(node) {
+		b.index.SetStatusFlags(n, statusInvalidAncestor)
+	}
+
+	err := b.activateBestChain()
+	if writeErr := b.index.flushToDB(); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	return err
+}
+
+// ReconsiderBlock removes the invalid status from the block identified by the
+// passed hash along with its ancestors and descendants so they are eligible to
+// become part of the main chain again.  The chain is then reorganized to the
+// chain with the most cumulative work.  Blocks which are actually invalid will
+// simply fail validation again when they are connected.  The reorganization is
+// notified with NTChainReorg once it has completed.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ReconsiderBlock(hash *chainhash.Hash) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %s is not known", hash)
+	}
+
+	defer b.notifyReorg(b.bestChain.Tip())
+
+	const invalidFlags = statusValidateFailed | statusInvalidAncestor
+	for n := node; n != nil; n = n.parent {
+		if b.index.NodeStatus(n).KnownInvalid() {
+			b.index.UnsetStatusFlags(n, invalidFlags)
+		}
+	}
+	for _, n := range b.descendants(node) {
+		if b.index.NodeStatus(n).KnownInvalid() {
+			b.index.UnsetStatusFlags(n, invalidFlags)
+		}
+	}
+
+	err := b.activateBestChain()
+	if writeErr := b.index.flushToDB(); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	return err
+}
+
+// PreciousBlock treats the block identified by the passed hash as if it was
+// received before any other block with the same cumulative work.  That is the
+// block is marked as precious with a sequence number which is persisted with
+// the block index, so it is preferred over the blocks with the same work marked
+// before it, and the chain is reorganized to the block when it has as much work
+// as the current main chain.  Nothing is done when the block is already part
+// of the main chain or has less work than it.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PreciousBlock(hash *chainhash.Hash) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %s is not known", hash)
+	}
+	if b.bestChain.Contains(node) ||
+		node.workSum.Cmp(b.bestChain.Tip().workSum) < 0 {
+
+		return nil
+	}
+
+	for n := node; !b.bestChain.Contains(n); n = n.parent {
+		status := b.index.NodeStatus(n)
+		if status.KnownInvalid() {
+			return fmt.Errorf("block %s is invalid", n.hash)
+		}
+		if !status.HaveData() {
+			return fmt.Errorf("data for block %s is not available",
+				n.hash)
+		}
+	}
+	defer b.notifyReorg(b.bestChain.Tip())
+
+	b.index.SetPrecious(node)
+	err := b.activateBestChain()
+	if writeErr := b.index.flushToDB(); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	return err
+}
@@ -0,0 +1,375 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// TestInvalidateBlock ensures blocks can be invalidated, reconsidered and
+// preferred over other blocks with the same work and that the chain is
+// reorganized accordingly.
+func TestInvalidateBlock(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("invalidateblock", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Create the following chain where b2 and b2a both spend the coinbase
+	// of b1 and b2a has the same work as b2 and does not become the tip:
+	//
+	//   genesis -> b1 -> b2
+	//                \-> b2a
+	b1 := newTestBlock(&params, &params.GenesisBlock.Header, 1)
+	b2 := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	b2a := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	for _, block := range []*btcutil.Block{b1, b2, b2a} {
+		_, _, err := chain.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock %v: unexpected error: %v",
+				block.Hash(), err)
+		}
+	}
+
+	assertTip := func(name string, want *btcutil.Block) {
+		t.Helper()
+		if tip := chain.BestSnapshot().Hash; tip != *want.Hash() {
+			t.Fatalf("%s: unexpected tip %v, want %v", name, tip,
+				want.Hash())
+		}
+	}
+	assertTip("ProcessBlock", b2)
+
+	// Preferring either block with the same work makes it the tip.
+	if err := chain.PreciousBlock(b2a.Hash()); err != nil {
+		t.Fatalf("PreciousBlock: unexpected error: %v", err)
+	}
+	assertTip("PreciousBlock", b2a)
+	if err := chain.PreciousBlock(b2.Hash()); err != nil {
+		t.Fatalf("PreciousBlock: unexpected error: %v", err)
+	}
+	assertTip("PreciousBlock", b2)
+
+	// Invalidating the tip reorganizes to the other block with the same
+	// work, which is notified as a single reorganization.
+	var reorgs []*ReorgData
+	chain.Subscribe(func(n *Notification) {
+		if n.Type == NTChainReorg {
+			reorgs = append(reorgs, n.Data.(*ReorgData))
+		}
+	})
+	if err := chain.InvalidateBlock(b2.Hash()); err != nil {
+		t.Fatalf("InvalidateBlock: unexpected error: %v", err)
+	}
+	assertTip("InvalidateBlock", b2a)
+	if len(reorgs) != 1 || reorgs[0].ForkHash != *b1.Hash() ||
+		len(reorgs[0].DetachedHashes) != 1 ||
+		reorgs[0].DetachedHashes[0] != *b2.Hash() ||
+		len(reorgs[0].AttachedHashes) != 1 ||
+		reorgs[0].AttachedHashes[0] != *b2a.Hash() {
+
+		t.Fatalf("InvalidateBlock: unexpected reorganizations %v", reorgs)
+	}
+	if err := chain.PreciousBlock(b2.Hash()); err == nil {
+		t.Fatal("PreciousBlock: preferred an invalid block")
+	}
+
+	// Invalidating a common ancestor leaves only the genesis block.
+	if err := chain.InvalidateBlock(b1.Hash()); err != nil {
+		t.Fatalf("InvalidateBlock: unexpected error: %v", err)
+	}
+	if tip := chain.BestSnapshot().Hash; tip != *params.GenesisHash {
+		t.Fatalf("InvalidateBlock: unexpected tip %v", tip)
+	}
+	b2aNode := chain.index.LookupNode(b2a.Hash())
+	if !chain.index.NodeStatus(b2aNode).KnownInvalid() {
+		t.Fatal("InvalidateBlock: descendant not marked invalid")
+	}
+
+	// Reconsidering a block also reconsiders its ancestors but not the
+	// other descendants of them.
+	if err := chain.ReconsiderBlock(b2.Hash()); err != nil {
+		t.Fatalf("ReconsiderBlock: unexpected error: %v", err)
+	}
+	assertTip("ReconsiderBlock", b2)
+	if !chain.index.NodeStatus(b2aNode).KnownInvalid() {
+		t.Fatal("ReconsiderBlock: unrelated block reconsidered")
+	}
+
+	if err := chain.InvalidateBlock(params.GenesisHash); err == nil {
+		t.Fatal("InvalidateBlock: invalidated the genesis block")
+	}
+}
+
+// TestPreciousBlockPersisted ensures the preference for a block marked as
+// precious is persisted with the block index and is still used to choose
+// between blocks with the same work after the block index is loaded again.
+func TestPreciousBlockPersisted(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("preciousblock", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	//   genesis -> b1 -> b2
+	//                \-> b2a
+	b1 := newTestBlock(&params, &params.GenesisBlock.Header, 1)
+	b2 := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	b2a := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	for _, block := range []*btcutil.Block{b1, b2, b2a} {
+		_, _, err := chain.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock %v: unexpected error: %v",
+				block.Hash(), err)
+		}
+	}
+	if err := chain.PreciousBlock(b2a.Hash()); err != nil {
+		t.Fatalf("PreciousBlock: unexpected error: %v", err)
+	}
+	if err := chain.PreciousBlock(b2.Hash()); err != nil {
+		t.Fatalf("PreciousBlock: unexpected error: %v", err)
+	}
+	if err := chain.FlushUtxoCache(FlushRequired); err != nil {
+		t.Fatalf("FlushUtxoCache: unexpected error: %v", err)
+	}
+
+	loaded, err := New(&Config{
+		DB:          chain.db,
+		ChainParams: &params,
+		TimeSource:  NewMedianTime(),
+		SigCache:    txscript.NewSigCache(1000),
+	})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	for _, test := range []struct {
+		block *btcutil.Block
+		seq   uint32
+	}{{b2a, 1}, {b2, 2}} {
+		node := loaded.index.LookupNode(test.block.Hash())
+		if !loaded.index.NodeStatus(node).Precious() ||
+			node.preciousSeq != test.seq {
+
+			t.Fatalf("block %v: unexpected precious sequence number "+
+				"%d, want %d", test.block.Hash(), node.preciousSeq,
+				test.seq)
+		}
+	}
+	if loaded.index.lastPreciousSeq != 2 {
+		t.Fatalf("unexpected last precious sequence number %d, want 2",
+			loaded.index.lastPreciousSeq)
+	}
+
+	// Invalidating and reconsidering the common ancestor chooses the block
+	// most recently marked as precious among the blocks with the same work.
+	if err := loaded.InvalidateBlock(b1.Hash()); err != nil {
+		t.Fatalf("InvalidateBlock: unexpected error: %v", err)
+	}
+	if err := loaded.ReconsiderBlock(b2a.Hash()); err != nil {
+		t.Fatalf("ReconsiderBlock: unexpected error: %v", err)
+	}
+	if err := loaded.ReconsiderBlock(b2.Hash()); err != nil {
+		t.Fatalf("ReconsiderBlock: unexpected error: %v", err)
+	}
+	if tip := loaded.BestSnapshot().Hash; tip != *b2.Hash() {
+		t.Fatalf("unexpected tip %v, want %v", tip, b2.Hash())
+	}
+}
+
+// TestInvalidateBlockDisconnect ensures invalidating a block of the main chain
+// disconnects the blocks after it one at a time and that nothing is
+// disconnected when the data of any of them has been pruned.
+func TestInvalidateBlockDisconnect(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("invalidatedisconnect", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Every block spends the coinbase of the previous one.
+	var blocks []*btcutil.Block
+	prev := &params.GenesisBlock.Header
+	for height := int32(1); height <= 4; height++ {
+		var spends []testhelper.SpendableOut
+		if height > 1 {
+			spends = append(spends, coinbaseSpend(blocks[height-2]))
+		}
+		block := newTestBlock(&params, prev, height, spends...)
+		_, _, err := chain.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock %d: unexpected error: %v", height,
+				err)
+		}
+		blocks = append(blocks, block)
+		prev = &block.MsgBlock().Header
+	}
+
+	var types []NotificationType
+	var disconnected []*btcutil.Block
+	var reorg *ReorgData
+	chain.Subscribe(func(n *Notification) {
+		types = append(types, n.Type)
+		switch n.Type {
+		case NTBlockDisconnected:
+			disconnected = append(disconnected, n.Data.(*btcutil.Block))
+		case NTChainReorg:
+			reorg = n.Data.(*ReorgData)
+		}
+	})
+
+	// The blocks after a pruned block can't be disconnected.
+	prunedNode := chain.index.LookupNode(blocks[2].Hash())
+	chain.index.UnsetStatusFlags(prunedNode, statusDataStored)
+	err = chain.InvalidateBlock(blocks[1].Hash())
+	if !IsPrunedBlockErr(err) {
+		t.Fatalf("InvalidateBlock: unexpected error: %v", err)
+	}
+	if chain.BestSnapshot().Hash != *blocks[3].Hash() || len(types) != 0 {
+		t.Fatal("InvalidateBlock: disconnected blocks of a pruned range")
+	}
+	node := chain.index.LookupNode(blocks[1].Hash())
+	if chain.index.NodeStatus(node).KnownInvalid() {
+		t.Fatal("InvalidateBlock: marked block of a pruned range invalid")
+	}
+	chain.index.SetStatusFlags(prunedNode, statusDataStored)
+
+	// The blocks are disconnected from the tip one at a time and the
+	// reorganization is notified as a whole afterwards.
+	if err := chain.InvalidateBlock(blocks[1].Hash()); err != nil {
+		t.Fatalf("InvalidateBlock: unexpected error: %v", err)
+	}
+	if chain.BestSnapshot().Hash != *blocks[0].Hash() {
+		t.Fatalf("InvalidateBlock: unexpected tip %v",
+			chain.BestSnapshot().Hash)
+	}
+	wantTypes := []NotificationType{NTBlockDisconnected,
+		NTBlockDisconnected, NTBlockDisconnected, NTChainReorg}
+	if len(types) != len(wantTypes) {
+		t.Fatalf("InvalidateBlock: unexpected notifications %v", types)
+	}
+	for i := range types {
+		if types[i] != wantTypes[i] {
+			t.Fatalf("InvalidateBlock: unexpected notifications %v",
+				types)
+		}
+	}
+	for i, block := range disconnected {
+		if *block.Hash() != *blocks[3-i].Hash() {
+			t.Fatalf("InvalidateBlock: block #%d disconnected out of "+
+				"order", 3-i)
+		}
+	}
+	if reorg.ForkHash != *blocks[0].Hash() || reorg.ForkHeight != 1 ||
+		len(reorg.DetachedHashes) != 3 || len(reorg.AttachedHashes) != 0 {
+
+		t.Fatalf("InvalidateBlock: unexpected reorganization %v", reorg)
+	}
+	for i, hash := range reorg.DetachedHashes {
+		if hash != *blocks[3-i].Hash() {
+			t.Fatalf("InvalidateBlock: unexpected detached blocks %v",
+				reorg.DetachedHashes)
+		}
+	}
+
+	// Reconsidering the block connects the blocks again, which is not a
+	// reorganization.
+	types = types[:0]
+	if err := chain.ReconsiderBlock(blocks[1].Hash()); err != nil {
+		t.Fatalf("ReconsiderBlock: unexpected error: %v", err)
+	}
+	for _, typ := range types {
+		if typ == NTChainReorg {
+			t.Fatal("ReconsiderBlock: notified a reorganization")
+		}
+	}
+	if chain.BestSnapshot().Hash != *blocks[3].Hash() {
+		t.Fatalf("ReconsiderBlock: unexpected tip %v",
+			chain.BestSnapshot().Hash)
+	}
+}
+
+// TestDescendants ensures the descendants of a block node are all nodes of the
+// branches after it, ordered by height, whether or not it is part of the main
+// chain.
+func TestDescendants(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain := newFakeChain(&params)
+
+	// Create the following block index where the a branch is the main
+	// chain:
+	//
+	//   genesis -> a1 -> a2 -> a3 -> a4
+	//          |     \-> b2 -> b3
+	//          |            \-> c3
+	//          \-> d1
+	genesis := chain.bestChain.Tip()
+	timestamp := time.Unix(genesis.timestamp, 0)
+	nodes := make(map[string]*blockNode)
+	for _, n := range []struct{ name, parent string }{
+		{"a1", ""}, {"a2", "a1"}, {"a3", "a2"}, {"a4", "a3"},
+		{"b2", "a1"}, {"b3", "b2"}, {"c3", "b2"}, {"d1", ""},
+	} {
+		parent := genesis
+		if n.parent != "" {
+			parent = nodes[n.parent]
+		}
+		timestamp = timestamp.Add(time.Second)
+		node := newFakeNode(parent, 1, params.PowLimitBits, timestamp)
+		chain.index.AddNode(node)
+		nodes[n.name] = node
+	}
+	chain.bestChain.SetTip(nodes["a4"])
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"a1", []string{"a2", "b2", "a3", "b3", "c3", "a4"}},
+		{"a3", []string{"a4"}},
+		{"a4", nil},
+		{"b2", []string{"b3", "c3"}},
+		{"c3", nil},
+		{"d1", nil},
+	}
+	for _, test := range tests {
+		descendants := chain.descendants(nodes[test.name])
+		want := make(map[*blockNode]struct{}, len(test.want))
+		for _, name := range test.want {
+			want[nodes[name]] = struct{}{}
+		}
+		if len(descendants) != len(want) {
+			t.Errorf("%s: unexpected number of descendants %d, want "+
+				"%d", test.name, len(descendants), len(want))
+			continue
+		}
+		for i, n := range descendants {
+			if _, ok := want[n]; !ok {
+				t.Errorf("%s: unexpected descendant at height %d",
+					test.name, n.height)
+			}
+			if i > 0 && n.height < descendants[i-1].height {
+				t.Errorf("%s: descendants not ordered by height",
+					test.name)
+			}
+		}
+	}
+}
@@ -6,6 +6,8 @@ package blockchain
 
 import (
 	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 )
 
 // NotificationType represents the type of a notification message.
@@ -29,6 +31,17 @@ const (
 	// NTBlockDisconnected indicates the associated block was disconnected
 	// from the main chain.
 	NTBlockDisconnected
+
+	// NTChainReorg indicates the main chain is being reorganized.  It is
+	// sent once the reorganization has been validated and before the
+	// individual NTBlockDisconnected and NTBlockConnected notifications of
+	// the blocks involved, so they can be handled as part of it.  The chain
+	// lock is held while it is sent, so callbacks must not call back into
+	// the chain.  Reorganizations caused by invalidating, reconsidering or
+	// preferring blocks are performed in steps, so they are instead sent
+	// once they have completed, after the notifications of the individual
+	// blocks.
+	NTChainReorg
 )
 
 // notificationTypeStrings is a map of notification types back to their constant
@@ -37,6 +50,7 @@ var notificationTypeStrings = map[NotificationType]string{
 	NTBlockAccepted:     "NTBlockAccepted",
 	NTBlockConnected:    "NTBlockConnected",
 	NTBlockDisconnected: "NTBlockDisconnected",
+	NTChainReorg:        "NTChainReorg",
 }
 
 // String returns the NotificationType in human-readable form.
@@ -53,11 +67,32 @@ func (n NotificationType) String() string {
 // 	- NTBlockAccepted:     *btcutil.Block
 // 	- NTBlockConnected:    *btcutil.Block
 // 	- NTBlockDisconnected: *btcutil.Block
+// 	- NTChainReorg:        *ReorgData
 type Notification struct {
 	Type NotificationType
 	Data interface{}
 }
 
+// ReorgData houses the details of a reorganization of the main chain which are
+// sent with an NTChainReorg notification.
+type ReorgData struct {
+	// ForkHash and ForkHeight identify the block the old and new main
+	// chains have in common, which is the new tip of the main chain when
+	// blocks were only disconnected.
+	ForkHash   chainhash.Hash
+	ForkHeight int32
+
+	// DetachedHashes are the hashes of the blocks disconnected from the
+	// main chain in the order they were disconnected, that is starting
+	// with the old tip.
+	DetachedHashes []chainhash.Hash
+
+	// AttachedHashes are the hashes of the blocks connected to the main
+	// chain in the order they were connected, that is ending with the new
+	// tip.
+	AttachedHashes []chainhash.Hash
+}
+
 // Subscribe to block chain notifications. Registers a callback to be executed
 // when various events take place. See the documentation on Notification and
 // NotificationType for details on the types and contents of notifications.
@@ -7,7 +7,9 @@ package blockchain
 import (
 	"testing"
 
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 )
 
 // TestNotifications ensures that notification callbacks are fired on events.
@@ -19,7 +21,7 @@ func TestNotifications(t *testing.T) {
 
 	// Create a new database and chain instance to run tests against.
 	chain, teardownFunc, err := chainSetup("notifications",
-		&chaincfg.MainNetParams)
+		&bitcoinMainNetParams)
 	if err != nil {
 		t.Fatalf("Failed to setup chain instance: %v", err)
 	}
@@ -49,3 +51,88 @@ func TestNotifications(t *testing.T) {
 			"times, found %d", numSubscribers, notificationCount)
 	}
 }
+
+// TestChainReorgNotification ensures a reorganization of the main chain is
+// notified along with the fork point and the detached and attached blocks before
+// the notifications of the individual blocks and any change to the main chain.
+func TestChainReorgNotification(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("reorgnotification", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Create the following chain where b2 and b2a both spend the coinbase
+	// of b1, b3a spends the coinbase of b2a and the b2a branch has more
+	// work:
+	//
+	//   genesis -> b1 -> b2
+	//                \-> b2a -> b3a
+	b1 := newTestBlock(&params, &params.GenesisBlock.Header, 1)
+	b2 := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	b2a := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	b3a := newTestBlock(&params, &b2a.MsgBlock().Header, 3,
+		coinbaseSpend(b2a))
+
+	var types []NotificationType
+	var reorgs []*ReorgData
+	var reorgTip chainhash.Hash
+	chain.Subscribe(func(n *Notification) {
+		if n.Type == NTBlockAccepted {
+			return
+		}
+		types = append(types, n.Type)
+		if n.Type == NTChainReorg {
+			reorgs = append(reorgs, n.Data.(*ReorgData))
+			reorgTip = chain.BestSnapshot().Hash
+		}
+	})
+
+	for _, block := range []*btcutil.Block{b1, b2, b2a, b3a} {
+		_, _, err := chain.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock %v: unexpected error: %v",
+				block.Hash(), err)
+		}
+	}
+
+	wantTypes := []NotificationType{NTBlockConnected, NTBlockConnected,
+		NTChainReorg, NTBlockDisconnected, NTBlockConnected,
+		NTBlockConnected}
+	if len(types) != len(wantTypes) {
+		t.Fatalf("unexpected notifications %v, want %v", types,
+			wantTypes)
+	}
+	for i := range types {
+		if types[i] != wantTypes[i] {
+			t.Fatalf("unexpected notifications %v, want %v", types,
+				wantTypes)
+		}
+	}
+
+	if reorgTip != *b2.Hash() {
+		t.Fatalf("main chain changed to %v before the reorganization "+
+			"was notified", reorgTip)
+	}
+
+	reorg := reorgs[0]
+	if reorg.ForkHash != *b1.Hash() || reorg.ForkHeight != 1 {
+		t.Fatalf("unexpected fork point %v (height %d)", reorg.ForkHash,
+			reorg.ForkHeight)
+	}
+	if len(reorg.DetachedHashes) != 1 ||
+		reorg.DetachedHashes[0] != *b2.Hash() {
+
+		t.Fatalf("unexpected detached blocks %v", reorg.DetachedHashes)
+	}
+	if len(reorg.AttachedHashes) != 2 ||
+		reorg.AttachedHashes[0] != *b2a.Hash() ||
+		reorg.AttachedHashes[1] != *b3a.Hash() {
+
+		t.Fatalf("unexpected attached blocks %v", reorg.AttachedHashes)
+	}
+}
@@ -5,11 +5,13 @@
 package blockchain
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcd/btcutil"
 )
 
@@ -29,6 +31,13 @@ const (
 	// not be performed.
 	BFNoPoWCheck
 
+	// BFAssumeValid may be set to indicate the block is already known to be
+	// an ancestor of the assumed valid block of the chain through headers
+	// with enough work, so its scripts do not need to be validated.  It is
+	// ignored when the chain does not have an assumed valid block.  This is
+	// primarily used for headers-first mode.
+	BFAssumeValid
+
 	// BFNone is a convenience value to specifically indicate no flags.
 	BFNone BehaviorFlags = 0
 )
@@ -114,8 +123,34 @@ func (b *BlockChain) processOrphans(hash *chainhash.Hash, flags BehaviorFlags) e
 			b.removeOrphanBlock(orphan)
 			i--
 
+			// The height of orphans is not known when they are
+			// first processed, so their proof of work was checked
+			// against all algorithms they could have been mined
+			// with.  Check it with the algorithm in effect at their
+			// height now that the parent is known, unless that is
+			// the one which was already verified.
+			prevNode := b.index.LookupNode(processHash)
+			if prevNode == nil {
+				return AssertError(fmt.Sprintf("processOrphans: "+
+					"parent %v of orphan %v is not in the block "+
+					"index", processHash, orphanHash))
+			}
+			height := prevNode.height + 1
+			powHash := orphan.powHash
+			if powHash == nil ||
+				orphan.powAlgo != b.chainParams.PowAlgorithmAt(height) {
+
+				var err error
+				powHash, err = b.checkBlockProofOfWork(
+					&orphan.block.MsgBlock().Header, height,
+					flags)
+				if err != nil {
+					return err
+				}
+			}
+
 			// Potentially accept the block into the block chain.
-			_, err := b.maybeAcceptBlock(orphan.block, flags)
+			_, err := b.maybeAcceptBlock(orphan.block, powHash, flags)
 			if err != nil {
 				return err
 			}
@@ -129,6 +164,67 @@ func (b *BlockChain) processOrphans(hash *chainhash.Hash, flags BehaviorFlags) e
 	return nil
 }
 
+// checkBlockProofOfWork ensures the proof of work of the passed block header,
+// which is at the passed height, satisfies its claimed target and returns the
+// proof-of-work hash.  A hash which was already verified for the block index or
+// by VerifyHeaderProofOfWork is used rather than calculating it again.  The
+// returned hash is nil when the BFNoPoWCheck flag is set and no verified hash
+// is known.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) checkBlockProofOfWork(header *wire.BlockHeader, height int32, flags BehaviorFlags) (*chainhash.Hash, error) {
+	hash := header.BlockHash()
+	if node := b.index.LookupNode(&hash); node != nil && node.height == height {
+		if powHash, ok := b.index.PowHash(node); ok {
+			return &powHash, nil
+		}
+	}
+	if powHash := b.headerPowHash(&hash, height); powHash != nil {
+		b.removeHeaderPowHash(&hash)
+		return powHash, nil
+	}
+
+	powAlgo := b.chainParams.PowAlgorithmAt(height)
+	return calcAndCheckProofOfWork(header, b.chainParams.PowLimit, powAlgo,
+		flags)
+}
+
+// checkOrphanProofOfWork ensures the proof of work of a block whose parent is
+// not known satisfies its claimed target.  The height of such a block is not
+// known either, so the check passes when the proof-of-work hash of any
+// algorithm which can be in effect after the current best block satisfies the
+// target.  Orphans are only useful when they extend the best chain, so older
+// algorithms are not tried.  The algorithm which passed and its hash are
+// returned so the hash can be used once the height is known.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) checkOrphanProofOfWork(header *wire.BlockHeader, flags BehaviorFlags) (wire.PowAlgorithm, *chainhash.Hash, error) {
+	algos := b.chainParams.PowAlgorithmsFrom(b.bestChain.Tip().height + 1)
+	var checkErr error
+	for _, algo := range algos {
+		powHash, err := calcAndCheckProofOfWork(header,
+			b.chainParams.PowLimit, algo, flags)
+		if err == nil {
+			return algo, powHash, nil
+		}
+
+		// Algorithms whose hash function has not been registered can not
+		// be checked, so a rule violation of another one takes precedence.
+		if _, ok := err.(RuleError); ok {
+			checkErr = err
+			continue
+		}
+		if errors.Is(err, wire.ErrPowAlgorithmUnavailable) {
+			if checkErr == nil {
+				checkErr = err
+			}
+			continue
+		}
+		return 0, nil, err
+	}
+	return 0, nil, checkErr
+}
+
 // ProcessBlock is the main workhorse for handling insertion of new blocks into
 // the block chain.  It includes functionality such as rejecting duplicate
 // blocks, ensuring blocks follow all rules, orphan handling, and insertion into
@@ -143,11 +239,25 @@ func (b *BlockChain) ProcessBlock(block *btcutil.Block, flags BehaviorFlags) (bo
 	b.chainLock.Lock()
 	defer b.chainLock.Unlock()
 
+	// The best chain can't be extended once it is known to be built on an
+	// invalid utxo snapshot.
+	if b.snapshotInvalid {
+		return false, false, ErrSnapshotInvalid
+	}
+
 	fastAdd := flags&BFFastAdd == BFFastAdd
 
 	blockHash := block.Hash()
 	log.Tracef("Processing block %v", blockHash)
 
+	// The blocks below a loaded utxo snapshot are already in the block
+	// index, but their data is downloaded afterwards to validate them.
+	if node := b.index.LookupNode(blockHash); node != nil &&
+		b.isSnapshotBlock(node) {
+
+		return false, false, b.processSnapshotBlock(node, block)
+	}
+
 	// The block must not already exist in the main chain or side chains.
 	exists, err := b.blockExists(blockHash)
 	if err != nil {
@@ -164,8 +274,33 @@ func (b *BlockChain) ProcessBlock(block *btcutil.Block, flags BehaviorFlags) (bo
 		return false, false, ruleError(ErrDuplicateBlock, str)
 	}
 
+	// The proof-of-work algorithm depends on the height of the block, which
+	// is only known when its parent is.  The proof of work of orphan blocks
+	// is checked against the algorithms they could have been mined with so
+	// they can not be used to fill the orphan pool for free.
+	//
+	// Otherwise, the proof of work is checked here, ahead of the remaining
+	// sanity checks just as checkBlockSanity would, so the hash can be
+	// cached in the block index rather than calculated again later.
+	blockHeader := &block.MsgBlock().Header
+	var powAlgo wire.PowAlgorithm
+	var powHash *chainhash.Hash
+	prevNode := b.index.LookupNode(&blockHeader.PrevBlock)
+	if prevNode != nil {
+		powAlgo = b.chainParams.PowAlgorithmAt(prevNode.height + 1)
+		powHash, err = b.checkBlockProofOfWork(blockHeader,
+			prevNode.height+1, flags)
+	} else {
+		powAlgo, powHash, err = b.checkOrphanProofOfWork(blockHeader,
+			flags)
+	}
+	if err != nil {
+		return false, false, err
+	}
+
 	// Perform preliminary sanity checks on the block and its transactions.
-	err = checkBlockSanity(block, b.chainParams.PowLimit, b.timeSource, flags)
+	err = checkBlockSanity(block, b.chainParams.PowLimit, powAlgo,
+		b.timeSource, flags|BFNoPoWCheck)
 	if err != nil {
 		return false, false, err
 	}
@@ -176,7 +311,6 @@ func (b *BlockChain) ProcessBlock(block *btcutil.Block, flags BehaviorFlags) (bo
 	// rejecting easy to mine, but otherwise bogus, blocks that could be
 	// used to eat memory, and ensuring expected (versus claimed) proof of
 	// work requirements since the previous checkpoint are met.
-	blockHeader := &block.MsgBlock().Header
 	checkpointNode, err := b.findPreviousCheckpoint()
 	if err != nil {
 		return false, false, err
@@ -218,14 +352,14 @@ func (b *BlockChain) ProcessBlock(block *btcutil.Block, flags BehaviorFlags) (bo
 	}
 	if !prevHashExists {
 		log.Infof("Adding orphan block %v with parent %v", blockHash, prevHash)
-		b.addOrphanBlock(block)
+		b.addOrphanBlock(block, powAlgo, powHash)
 
 		return false, true, nil
 	}
 
 	// The block has passed all context independent checks and appears sane
 	// enough to potentially accept it into the block chain.
-	isMainChain, err := b.maybeAcceptBlock(block, flags)
+	isMainChain, err := b.maybeAcceptBlock(block, powHash, flags)
 	if err != nil {
 		return false, false, err
 	}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestOrphanProofOfWork ensures the proof of work of blocks whose parent is
+// not known is checked before they are added to the orphan pool.
+func TestOrphanProofOfWork(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("orphanpow", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	unknownParent := &wire.BlockHeader{
+		PrevBlock: chainhash.Hash{0x01},
+		Timestamp: params.GenesisBlock.Header.Timestamp.Add(time.Hour),
+	}
+
+	// A block whose claimed target no hash can satisfy must be rejected
+	// rather than added to the orphan pool.
+	block := newTestBlock(&params, unknownParent, 10)
+	block.MsgBlock().Header.Bits = 0x03000001
+	_, _, err = chain.ProcessBlock(block, BFNone)
+	if rerr, ok := err.(RuleError); !ok || rerr.ErrorCode != ErrHighHash {
+		t.Fatalf("ProcessBlock: unexpected error: %v", err)
+	}
+	if chain.IsKnownOrphan(block.Hash()) {
+		t.Fatal("block with invalid proof of work is a known orphan")
+	}
+
+	// Grind a nonce which satisfies the proof-of-work limit.
+	block, err = solveTestBlock(&params, newTestBlock(&params, unknownParent,
+		10), 10)
+	if err != nil {
+		t.Fatalf("solveTestBlock: unexpected error: %v", err)
+	}
+	_, isOrphan, err := chain.ProcessBlock(block, BFNone)
+	if err != nil {
+		t.Fatalf("ProcessBlock: unexpected error: %v", err)
+	}
+	if !isOrphan || !chain.IsKnownOrphan(block.Hash()) {
+		t.Fatal("block with valid proof of work is not a known orphan")
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+)
+
+const (
+	// MinBlocksToKeep is the minimum number of blocks at the end of the
+	// main chain which are never pruned.  It matches the number of recent
+	// blocks a node signalling wire.SFNodeNetworkLimited promises to serve
+	// and leaves enough blocks to handle any realistic reorganization.
+	MinBlocksToKeep = 288
+)
+
+// errBlockPruned signifies that the data for a requested block has been
+// deleted from the database by pruning.
+type errBlockPruned string
+
+// Error implements the error interface.
+func (e errBlockPruned) Error() string {
+	return string(e)
+}
+
+// IsPrunedBlockErr returns whether or not the passed error indicates the data
+// for a requested block has been pruned.
+func IsPrunedBlockErr(err error) bool {
+	_, ok := err.(errBlockPruned)
+	return ok
+}
+
+// pruneBlocks deletes the oldest blocks from the database along with their
+// spend journal entries once the stored blocks exceed the prune target.  Blocks
+// which are less than MinBlocksToKeep blocks deep relative to the passed tip
+// are never deleted.
+//
+// The utxo cache is written to the database in the same transaction as the
+// blocks are deleted since recovering it after an unclean shutdown requires the
+// blocks connected since it was last flushed.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) pruneBlocks(tip *blockNode) error {
+	if b.pruneTarget == 0 || tip.height < b.pruneRetryHeight {
+		return nil
+	}
+
+	// Keep track of the highest block which had to be kept so pruning is
+	// not attempted again until it is deep enough to be deleted.
+	keptHeight := int32(-1)
+	keep := func(hash *chainhash.Hash) bool {
+		node := b.index.LookupNode(hash)
+		if node == nil || tip.height-node.height >= MinBlocksToKeep {
+			return false
+		}
+		if node.height > keptHeight {
+			keptHeight = node.height
+		}
+		return true
+	}
+
+	cache := b.utxoCache
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	var pruned []chainhash.Hash
+	err := b.db.Update(func(dbTx database.Tx) error {
+		var err error
+		pruned, err = dbTx.PruneBlocks(b.pruneTarget, keep)
+		if err != nil || len(pruned) == 0 {
+			return err
+		}
+
+		for i := range pruned {
+			err := dbRemoveSpendJournalEntry(dbTx, &pruned[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		return cache.dbPutEntries(dbTx, &tip.hash)
+	})
+	if err != nil {
+		return err
+	}
+	if keptHeight != -1 {
+		b.pruneRetryHeight = keptHeight + MinBlocksToKeep
+	}
+	if len(pruned) == 0 {
+		return nil
+	}
+	cache.flushed(&tip.hash)
+
+	// Mark the pruned blocks as no longer having their data stored.
+	for i := range pruned {
+		node := b.index.LookupNode(&pruned[i])
+		if node != nil {
+			b.index.UnsetStatusFlags(node, statusDataStored)
+		}
+	}
+
+	log.Infof("Pruned %d blocks from the database (lowest remaining "+
+		"block height %d)", len(pruned), b.PruneHeight())
+	return nil
+}
+
+// IsPruned returns whether or not the chain is configured to delete old blocks
+// from the database.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsPruned() bool {
+	return b.pruneTarget != 0
+}
+
+// PruneHeight returns the height of the lowest block in the main chain whose
+// data has not been pruned.  It is zero when no blocks have been pruned.
+//
+// Blocks are pruned in the order they were stored, which is effectively the
+// order of the main chain, so this is the first block in the main chain which
+// still has its data.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PruneHeight() int32 {
+	low, high := int32(0), b.bestChain.Height()
+	for low < high {
+		mid := low + (high-low)/2
+		node := b.bestChain.NodeByHeight(mid)
+		if b.index.NodeStatus(node).HaveData() {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low
+}
+
+// IsBlockPruned returns whether or not the block with the passed hash is known
+// but its data has been deleted from the database by pruning.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsBlockPruned(hash *chainhash.Hash) bool {
+	node := b.index.LookupNode(hash)
+	return node != nil && !b.index.NodeStatus(node).HaveData()
+}
+
+// blockPrunedError returns an error for a request for the passed block whose
+// data has been pruned.
+func blockPrunedError(node *blockNode) error {
+	str := fmt.Sprintf("block %s at height %d has been pruned", node.hash,
+		node.height)
+	return errBlockPruned(str)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// TestPrunedBlocks ensures the lowest unpruned block in the main chain is found
+// and that requests for pruned blocks are reported as such.
+func TestPrunedBlocks(t *testing.T) {
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	genesis := chain.bestChain.Genesis()
+	nodes := append([]*blockNode{genesis}, chainedNodes(genesis, 20)...)
+	for _, node := range nodes {
+		node.status = statusDataStored | statusValid
+		chain.index.AddNode(node)
+	}
+	chain.bestChain.SetTip(tstTip(nodes))
+
+	if height := chain.PruneHeight(); height != 0 {
+		t.Fatalf("PruneHeight: unexpected height without pruning - got "+
+			"%d, want 0", height)
+	}
+
+	// Prune the data for the first blocks.
+	const numPruned = 7
+	for _, node := range nodes[:numPruned] {
+		chain.index.UnsetStatusFlags(node, statusDataStored)
+	}
+	if height := chain.PruneHeight(); height != numPruned {
+		t.Fatalf("PruneHeight: unexpected height - got %d, want %d",
+			height, numPruned)
+	}
+	for i, node := range nodes {
+		if got, want := chain.IsBlockPruned(&node.hash), i < numPruned; got != want {
+			t.Fatalf("IsBlockPruned #%d: unexpected result - got %v, "+
+				"want %v", i, got, want)
+		}
+	}
+	if chain.IsBlockPruned(&chainhash.Hash{}) {
+		t.Fatal("IsBlockPruned: unknown block reported as pruned")
+	}
+
+	// Ensure fetching a pruned block reports it was pruned.
+	_, err := chain.BlockByHeight(numPruned - 1)
+	if !IsPrunedBlockErr(err) {
+		t.Fatalf("BlockByHeight: unexpected error - got %v, want pruned "+
+			"block error", err)
+	}
+	_, err = chain.BlockByHash(&nodes[0].hash)
+	if !IsPrunedBlockErr(err) {
+		t.Fatalf("BlockByHash: unexpected error - got %v, want pruned "+
+			"block error", err)
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+)
+
+const (
+	// reindexStateWiping and reindexStateReplaying are the phases of
+	// reindexing the chain state recorded in the database so an
+	// interrupted reindex is resumed on the next start.
+	reindexStateWiping    = 0
+	reindexStateReplaying = 1
+
+	// maxReindexDeletions is the maximum number of entries deleted from a
+	// chain state bucket in a single database transaction while wiping it,
+	// which bounds the memory needed to wipe large buckets such as the utxo
+	// set.
+	maxReindexDeletions = 250000
+
+	// reindexProgressInterval is the minimum amount of time between the
+	// progress messages logged while replaying the stored blocks.
+	reindexProgressInterval = 10 * time.Second
+)
+
+var (
+	// reindexStateKeyName is the name of the db key used to record the
+	// phase of an ongoing reindex of the chain state.
+	reindexStateKeyName = []byte("reindexchainstate")
+
+	// chainStateBucketNames are the names of the db buckets which house the
+	// chain state that is rebuilt from the stored blocks by a reindex.
+	chainStateBucketNames = [][]byte{
+		blockIndexBucketName,
+		hashIndexBucketName,
+		heightIndexBucketName,
+		spendJournalBucketName,
+		utxoSetBucketName,
+		snapshotUtxoSetBucketName,
+	}
+
+	// chainStateKeyNames are the names of the db keys which house the
+	// chain state that is rebuilt from the stored blocks by a reindex.
+	chainStateKeyNames = [][]byte{
+		chainStateKeyName,
+		spendJournalVersionKeyName,
+		utxoSetVersionKeyName,
+		utxoStateConsistencyKeyName,
+		utxoSetStatsKeyName,
+		snapshotStateKeyName,
+		snapshotUtxoStateKeyName,
+	}
+)
+
+// dbDeleteBucketInBatches deletes the bucket with the passed name from the
+// metadata of the database along with all of its entries.  The entries are
+// deleted over multiple database transactions, so an interrupted deletion
+// leaves the bucket partially deleted.
+func dbDeleteBucketInBatches(db database.DB, bucketName []byte, interrupt <-chan struct{}) error {
+	for {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		var numDeleted int
+		err := db.Update(func(dbTx database.Tx) error {
+			meta := dbTx.Metadata()
+			bucket := meta.Bucket(bucketName)
+			if bucket == nil {
+				return nil
+			}
+
+			cursor := bucket.Cursor()
+			for ok := cursor.First(); ok && numDeleted < maxReindexDeletions; ok = cursor.Next() {
+				if cursor.Value() == nil {
+					continue
+				}
+				if err := cursor.Delete(); err != nil {
+					return err
+				}
+				numDeleted++
+			}
+			if numDeleted != 0 {
+				return nil
+			}
+
+			return meta.DeleteBucket(bucketName)
+		})
+		if err != nil {
+			return err
+		}
+		if numDeleted == 0 {
+			return nil
+		}
+	}
+}
+
+// initReindexChainState wipes the chain state from the database when it is to
+// be rebuilt from the stored blocks, either because it was requested or
+// because an earlier reindex was interrupted, in which case it is resumed.  It
+// returns whether the stored blocks need to be replayed to rebuild the chain
+// state afterwards.
+func initReindexChainState(db database.DB, reindex bool, interrupt <-chan struct{}) (bool, error) {
+	var state []byte
+	err := db.View(func(dbTx database.Tx) error {
+		state = dbTx.Metadata().Get(reindexStateKeyName)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if state == nil && !reindex {
+		return false, nil
+	}
+
+	// Only the stored blocks can be replayed, so a database which has been
+	// pruned or is missing the blocks below a loaded utxo snapshot can't be
+	// reindexed.
+	if state == nil {
+		err := db.Update(func(dbTx database.Tx) error {
+			beenPruned, err := dbTx.BeenPruned()
+			if err != nil {
+				return err
+			}
+			if beenPruned {
+				return errors.New("the chain state can't be " +
+					"reindexed since blocks have been pruned")
+			}
+			if dbTx.Metadata().Get(snapshotStateKeyName) != nil {
+				return errors.New("the chain state can't be " +
+					"reindexed until the blocks below the " +
+					"loaded utxo snapshot are validated")
+			}
+
+			return dbTx.Metadata().Put(reindexStateKeyName,
+				[]byte{reindexStateWiping})
+		})
+		if err != nil {
+			return false, err
+		}
+		state = []byte{reindexStateWiping}
+	}
+	if state[0] == reindexStateReplaying {
+		log.Infof("Resuming the reindex of the chain state")
+		return true, nil
+	}
+
+	log.Infof("Wiping the chain state to reindex it from the stored blocks")
+	for _, bucketName := range chainStateBucketNames {
+		err := dbDeleteBucketInBatches(db, bucketName, interrupt)
+		if err != nil {
+			return false, err
+		}
+	}
+	err = db.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		for _, keyName := range chainStateKeyNames {
+			if err := meta.Delete(keyName); err != nil {
+				return err
+			}
+		}
+
+		return meta.Put(reindexStateKeyName, []byte{reindexStateReplaying})
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// replayStoredBlocks rebuilds the chain state by processing all blocks stored
+// in the database again in the order they were stored.  Blocks are only
+// processed once their parent is known, and blocks which are already in the
+// block index are skipped, which allows an interrupted replay to be resumed.
+// The record of the ongoing reindex is removed once all blocks are processed.
+func (b *BlockChain) replayStoredBlocks(interrupt <-chan struct{}) error {
+	var hashes []chainhash.Hash
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		hashes, err = dbTx.FetchBlockHashes()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Replaying %d stored blocks to reindex the chain state",
+		len(hashes))
+
+	// processBlock processes the stored block with the passed hash.  Blocks
+	// which violate the rules are skipped since the database also stores
+	// blocks which were found to be invalid.
+	var numProcessed int
+	processBlock := func(hash *chainhash.Hash) error {
+		var block *btcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			blockBytes, err := dbTx.FetchBlock(hash)
+			if err != nil {
+				return err
+			}
+			block, err = btcutil.NewBlockFromBytes(blockBytes)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		_, _, err = b.ProcessBlock(block, BFNone)
+		if _, ok := err.(RuleError); ok {
+			log.Warnf("Skipping stored block %v: %v", hash, err)
+			return nil
+		}
+		numProcessed++
+		return err
+	}
+
+	// Process the blocks whose parent is known in the order they were
+	// stored, followed by any of their descendants which were stored
+	// before them.
+	waiting := make(map[chainhash.Hash][]chainhash.Hash)
+	var numWaiting int
+	lastLog := time.Now()
+	for i := range hashes {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		hash := &hashes[i]
+		node := b.index.LookupNode(hash)
+		if node != nil && b.index.NodeStatus(node).HaveData() {
+			continue
+		}
+
+		var header []byte
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			header, err = dbTx.FetchBlockHeader(hash)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if len(header) < blockHdrSize {
+			return fmt.Errorf("stored block %v has a truncated "+
+				"header", hash)
+		}
+		var prevHash chainhash.Hash
+		copy(prevHash[:], header[4:4+chainhash.HashSize])
+		if !b.index.HaveBlock(&prevHash) {
+			waiting[prevHash] = append(waiting[prevHash], *hash)
+			numWaiting++
+			continue
+		}
+
+		pending := []chainhash.Hash{*hash}
+		for len(pending) > 0 {
+			hash := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			if err := processBlock(&hash); err != nil {
+				return err
+			}
+
+			children := waiting[hash]
+			delete(waiting, hash)
+			numWaiting -= len(children)
+			pending = append(pending, children...)
+		}
+
+		if time.Since(lastLog) >= reindexProgressInterval {
+			best := b.BestSnapshot()
+			log.Infof("Replayed %d of %d stored blocks (height %d, %s)",
+				numProcessed, len(hashes), best.Height,
+				best.MedianTime)
+			lastLog = time.Now()
+		}
+	}
+	if numWaiting != 0 {
+		log.Warnf("Skipped %d stored blocks which do not connect to the "+
+			"chain", numWaiting)
+	}
+
+	// The reindex is complete once the rebuilt utxo set is written to the
+	// database.
+	if err := b.FlushUtxoCache(FlushRequired); err != nil {
+		return err
+	}
+	err = b.db.Update(func(dbTx database.Tx) error {
+		return dbTx.Metadata().Delete(reindexStateKeyName)
+	})
+	if err != nil {
+		return err
+	}
+
+	best := b.BestSnapshot()
+	log.Infof("Reindexed the chain state from %d stored blocks (height %d, "+
+		"hash %v)", numProcessed, best.Height, best.Hash)
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestReindexChainState ensures the chain state is rebuilt from the stored
+// blocks when it is reindexed and that an interrupted reindex is resumed.
+func TestReindexChainState(t *testing.T) {
+	// The stored blocks are processed with full validation when they are
+	// replayed, so an unused algorithm identifier whose hash satisfies any
+	// target is used to avoid having to solve them.
+	const testAlgo = wire.PowAlgorithm(0xfd)
+	wire.RegisterPowHashFunc(testAlgo, func([]byte) (chainhash.Hash, error) {
+		return chainhash.Hash{}, nil
+	})
+
+	params := chaincfg.RegressionNetParams
+	params.PowForks = []chaincfg.PowFork{{Height: 0, Algorithm: testAlgo}}
+	params.CoinbaseMaturity = 1
+	chain, teardownFunc, err := chainSetup("reindexchainstate", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Extend the chain, where every block spends the coinbase of the
+	// previous one, and store a side chain block which spends the same
+	// coinbase as the block it competes with.
+	var blocks []*btcutil.Block
+	prev := &params.GenesisBlock.Header
+	for height := int32(1); height <= 3; height++ {
+		var spends []testhelper.SpendableOut
+		if height > 1 {
+			spends = append(spends, coinbaseSpend(blocks[height-2]))
+		}
+		block := newTestBlock(&params, prev, height, spends...)
+		if _, _, err := chain.ProcessBlock(block, BFNone); err != nil {
+			t.Fatalf("ProcessBlock %d: unexpected error: %v", height,
+				err)
+		}
+		blocks = append(blocks, block)
+		prev = &block.MsgBlock().Header
+	}
+	sideBlock := newTestBlock(&params, &blocks[0].MsgBlock().Header, 2,
+		coinbaseSpend(blocks[0]))
+	if _, _, err := chain.ProcessBlock(sideBlock, BFNone); err != nil {
+		t.Fatalf("ProcessBlock side chain: unexpected error: %v", err)
+	}
+	if err := chain.FlushUtxoCache(FlushRequired); err != nil {
+		t.Fatalf("FlushUtxoCache: unexpected error: %v", err)
+	}
+	wantStats := chain.UtxoSetStats()
+
+	// reindex creates a new chain instance from the database and ensures
+	// its chain state matches the original one.
+	reindex := func(name string, reindexChainState bool) {
+		t.Helper()
+
+		reindexed, err := New(&Config{
+			DB:                chain.db,
+			ChainParams:       &params,
+			TimeSource:        NewMedianTime(),
+			SigCache:          txscript.NewSigCache(1000),
+			ReindexChainState: reindexChainState,
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if stats := reindexed.UtxoSetStats(); *stats != *wantStats {
+			t.Fatalf("%s: unexpected utxo set stats: got %+v, want "+
+				"%+v", name, stats, wantStats)
+		}
+		if !reindexed.index.HaveBlock(sideBlock.Hash()) {
+			t.Fatalf("%s: side chain block %v not reindexed", name,
+				sideBlock.Hash())
+		}
+		err = reindexed.db.View(func(dbTx database.Tx) error {
+			if dbTx.Metadata().Get(reindexStateKeyName) != nil {
+				t.Fatalf("%s: reindex state not removed", name)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+	}
+	reindex("reindex", true)
+
+	// An interrupted replay is resumed without being requested again.
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbTx.Metadata().Put(reindexStateKeyName,
+			[]byte{reindexStateReplaying})
+	})
+	if err != nil {
+		t.Fatalf("Put: unexpected error: %v", err)
+	}
+	reindex("resumed replay", false)
+
+	// An interrupted wipe is resumed as well.
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbTx.Metadata().Put(reindexStateKeyName,
+			[]byte{reindexStateWiping})
+	})
+	if err != nil {
+		t.Fatalf("Put: unexpected error: %v", err)
+	}
+	reindex("resumed wipe", false)
+}
@@ -0,0 +1,927 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// snapshotVersion is the current version of the utxo snapshot format.
+	snapshotVersion = 1
+
+	// snapshotBatchSize is the number of coins from a utxo snapshot which
+	// are written to the database in a single transaction while it is
+	// loaded.
+	snapshotBatchSize = 50000
+
+	// snapshotHeaderSize is the size of the fields of a serialized utxo
+	// snapshot which precede the block headers.
+	snapshotHeaderSize = 4 + 2 + chainhash.HashSize + 4 + 8
+
+	// maxSnapshotKeySize is the maximum size of the serialized outpoint of
+	// a coin in a utxo snapshot.
+	maxSnapshotKeySize = chainhash.HashSize + 5
+)
+
+var (
+	// ErrSnapshotInvalid is returned once the blocks below a loaded utxo
+	// snapshot were found not to match it.  The best chain built on top of
+	// the snapshot can't be trusted, so the chain refuses to process any
+	// more blocks and to be loaded again until the data directory is
+	// deleted.
+	ErrSnapshotInvalid = errors.New("the loaded utxo snapshot is invalid " +
+		"-- the data directory must be deleted")
+
+	// snapshotMagic identifies a serialized utxo snapshot.
+	snapshotMagic = [4]byte{'u', 't', 'x', 'o'}
+
+	// snapshotStateKeyName is the name of the db key used to store the
+	// state of a utxo snapshot loaded into the database.  It only exists
+	// until the blocks below the snapshot are validated.
+	snapshotStateKeyName = []byte("utxosnapshotstate")
+
+	// snapshotUtxoSetBucketName is the name of the db bucket used to house
+	// the unspent transaction output set built from the blocks below a
+	// loaded utxo snapshot while they are validated.
+	snapshotUtxoSetBucketName = []byte("utxosnapshotvalidation")
+
+	// snapshotUtxoStateKeyName is the name of the db key used to store the
+	// hash of the block the utxo set built from the blocks below a loaded
+	// utxo snapshot represents.
+	snapshotUtxoStateKeyName = []byte("utxosnapshotvalidationstate")
+)
+
+// -----------------------------------------------------------------------------
+// A utxo snapshot contains the unspent transaction output set as of a block in
+// the main chain along with the headers of the blocks leading up to it and the
+// block itself, which is everything needed to continue the chain from it.
+//
+// The serialized format is:
+//
+//   <magic><version><base hash><base height><num coins><headers><base block><coins>
+//
+//   Field          Type                  Size
+//   magic          [4]byte               4 bytes
+//   version        uint16                2 bytes
+//   base hash      chainhash.Hash        chainhash.HashSize
+//   base height    uint32                4 bytes
+//   num coins      uint64                8 bytes
+//   headers        []wire.BlockHeader    80 bytes * (base height - 1)
+//   base block     wire.MsgBlock         variable
+//   coins          []coin                variable
+//
+// The headers are those of the blocks after the genesis block up to, but not
+// including, the base block.  Each coin is serialized as:
+//
+//   <key><entry>
+//
+//   Field          Type                  Size
+//   key            var bytes             variable
+//   entry          var bytes             variable
+//
+// The key and entry are the outpoint and utxo entry in the format they are
+// stored in the utxo set in the database, which is described in detail above,
+// and the coins are ordered by key.  The hash of a snapshot is the sha256 of
+// the serialized coins, which only depends on the utxo set, so it is what the
+// chain parameters commit to.
+// -----------------------------------------------------------------------------
+
+// UtxoSnapshot describes a snapshot of the unspent transaction output set as of
+// a block in the main chain.
+type UtxoSnapshot struct {
+	BaseHash     chainhash.Hash // The hash of the block.
+	BaseHeight   int32          // The height of the block.
+	NumCoins     uint64         // The number of unspent outputs.
+	SnapshotHash chainhash.Hash // The hash of the serialized outputs.
+	ChainTxCount uint64         // The total number of txns in the chain.
+}
+
+// snapshotStatus identifies how far a utxo snapshot loaded into the database
+// has progressed.
+type snapshotStatus byte
+
+const (
+	// snapshotLoading indicates the coins of the snapshot are being written
+	// to the utxo set, so they must be discarded when it is found on
+	// startup.
+	snapshotLoading snapshotStatus = iota
+
+	// snapshotValidating indicates the snapshot is the utxo set of the best
+	// chain while the blocks below it are validated.
+	snapshotValidating
+
+	// snapshotInvalid indicates the utxo set built from the blocks below
+	// the snapshot did not match it.
+	snapshotInvalid
+)
+
+// snapshotState is the state of a utxo snapshot loaded into the database.
+//
+// The serialized format is:
+//
+//   <status><base hash><snapshot hash>
+//
+//   Field          Type             Size
+//   status         byte             1 byte
+//   base hash      chainhash.Hash   chainhash.HashSize
+//   snapshot hash  chainhash.Hash   chainhash.HashSize
+type snapshotState struct {
+	status       snapshotStatus
+	baseHash     chainhash.Hash
+	snapshotHash chainhash.Hash
+}
+
+// dbPutSnapshotState uses an existing database transaction to store the passed
+// state of a loaded utxo snapshot.
+func dbPutSnapshotState(dbTx database.Tx, state *snapshotState) error {
+	serialized := make([]byte, 1+2*chainhash.HashSize)
+	serialized[0] = byte(state.status)
+	copy(serialized[1:], state.baseHash[:])
+	copy(serialized[1+chainhash.HashSize:], state.snapshotHash[:])
+	return dbTx.Metadata().Put(snapshotStateKeyName, serialized)
+}
+
+// dbFetchSnapshotState uses an existing database transaction to fetch the
+// state of a loaded utxo snapshot.  It returns nil when no snapshot was loaded
+// or the blocks below it have been validated.
+func dbFetchSnapshotState(dbTx database.Tx) (*snapshotState, error) {
+	serialized := dbTx.Metadata().Get(snapshotStateKeyName)
+	if serialized == nil {
+		return nil, nil
+	}
+	if len(serialized) != 1+2*chainhash.HashSize {
+		return nil, database.Error{
+			ErrorCode:   database.ErrCorruption,
+			Description: "corrupt utxo snapshot state",
+		}
+	}
+
+	var state snapshotState
+	state.status = snapshotStatus(serialized[0])
+	copy(state.baseHash[:], serialized[1:])
+	copy(state.snapshotHash[:], serialized[1+chainhash.HashSize:])
+	return &state, nil
+}
+
+// snapshotValidation tracks the validation of the blocks below a loaded utxo
+// snapshot.  They are connected to a separate utxo set as their data becomes
+// available, which must match the snapshot once the base block is reached.
+type snapshotValidation struct {
+	base         *blockNode
+	snapshotHash chainhash.Hash
+
+	// tip is the last block connected to the utxo set in utxoCache.
+	tip       *blockNode
+	utxoCache *utxoCache
+}
+
+// newSnapshotUtxoCache returns a utxo cache for the utxo set which is built from
+// the blocks below a loaded utxo snapshot.
+func (b *BlockChain) newSnapshotUtxoCache() *utxoCache {
+	cache := newUtxoCache(b.db, b.utxoCache.maxSize, b.utxoCache.flushInterval)
+	cache.utxoBucketName = snapshotUtxoSetBucketName
+	cache.stateKeyName = snapshotUtxoStateKeyName
+	return cache
+}
+
+// assumeUtxo returns the utxo snapshot commitment in the chain parameters for
+// the passed block, or nil when there is none.
+func (b *BlockChain) assumeUtxo(hash *chainhash.Hash, height int32) *chaincfg.AssumeUtxo {
+	for i := range b.chainParams.AssumeUtxo {
+		snapshot := &b.chainParams.AssumeUtxo[i]
+		if snapshot.Height == height && snapshot.BlockHash.IsEqual(hash) {
+			return snapshot
+		}
+	}
+	return nil
+}
+
+// writeSnapshotCoins serializes all coins in the passed utxo set bucket to the
+// passed writer in the format of a utxo snapshot.  It returns the number of
+// coins written.
+func writeSnapshotCoins(w io.Writer, utxoBucket database.Bucket) (uint64, error) {
+	var numCoins uint64
+	cursor := utxoBucket.Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		err := wire.WriteVarBytes(w, 0, cursor.Key())
+		if err != nil {
+			return 0, err
+		}
+		err = wire.WriteVarBytes(w, 0, cursor.Value())
+		if err != nil {
+			return 0, err
+		}
+		numCoins++
+	}
+	return numCoins, nil
+}
+
+// DumpUtxoSnapshot writes a snapshot of the unspent transaction output set as
+// of the end of the current best chain to the passed writer.  The snapshot may
+// be loaded by another node with LoadUtxoSnapshot once the chain parameters
+// commit to the returned snapshot hash.
+//
+// The chain state lock is only held while a consistent view of the utxo set
+// is taken, so blocks may be connected while the snapshot is written.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) DumpUtxoSnapshot(w io.Writer) (*UtxoSnapshot, error) {
+	b.chainLock.Lock()
+	tip, chainTxCount, dbTx, blockBytes, err := b.beginUtxoSnapshot()
+	b.chainLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	// The nodes of the main chain up to the tip never change, so their
+	// headers may be collected without holding the chain state lock.
+	nodes := make([]*blockNode, tip.height)
+	for node := tip; node != nil; node = node.parent {
+		if node.height < tip.height {
+			nodes[node.height] = node
+		}
+	}
+
+	snapshot := &UtxoSnapshot{
+		BaseHash:     tip.hash,
+		BaseHeight:   tip.height,
+		ChainTxCount: chainTxCount,
+	}
+	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+	snapshot.NumCoins, err = writeSnapshotCoins(io.Discard, utxoBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	bw := bufio.NewWriter(w)
+	var header [snapshotHeaderSize]byte
+	copy(header[:], snapshotMagic[:])
+	binary.LittleEndian.PutUint16(header[4:], snapshotVersion)
+	copy(header[6:], tip.hash[:])
+	offset := 6 + chainhash.HashSize
+	binary.LittleEndian.PutUint32(header[offset:], uint32(tip.height))
+	binary.LittleEndian.PutUint64(header[offset+4:], snapshot.NumCoins)
+	if _, err := bw.Write(header[:]); err != nil {
+		return nil, err
+	}
+
+	for _, node := range nodes[1:] {
+		header := node.Header()
+		if err := header.Serialize(bw); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := bw.Write(blockBytes); err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	_, err = writeSnapshotCoins(io.MultiWriter(bw, hasher), utxoBucket)
+	if err != nil {
+		return nil, err
+	}
+	copy(snapshot.SnapshotHash[:], hasher.Sum(nil))
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Dumped utxo snapshot with %d coins at height %d (hash %v)",
+		snapshot.NumCoins, snapshot.BaseHeight, snapshot.SnapshotHash)
+	return snapshot, nil
+}
+
+// beginUtxoSnapshot flushes the utxo cache and returns the tip of the best
+// chain, its total number of transactions, a read-only database transaction
+// which views the utxo set as of the tip and the serialized tip block.  The
+// block is fetched right away since it could be pruned once the chain state
+// lock is released.
+//
+// The caller is responsible for rolling back the returned transaction.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) beginUtxoSnapshot() (*blockNode, uint64, database.Tx, []byte, error) {
+	tip := b.bestChain.Tip()
+	if tip.height == 0 {
+		return nil, 0, nil, nil, errors.New("the best chain does not " +
+			"have any blocks after the genesis block")
+	}
+
+	// The snapshot is made from the utxo set in the database, so it must
+	// include all cached changes.
+	if err := b.utxoCache.flush(FlushRequired, &tip.hash); err != nil {
+		return nil, 0, nil, nil, err
+	}
+
+	dbTx, err := b.db.Begin(false)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	blockBytes, err := dbTx.FetchBlock(&tip.hash)
+	if err != nil {
+		dbTx.Rollback()
+		return nil, 0, nil, nil, err
+	}
+	return tip, b.stateSnapshot.TotalTxns, dbTx, blockBytes, nil
+}
+
+// readSnapshotCoins reads the passed number of coins in the format of a utxo
+// snapshot from the passed reader and writes them to the utxo set of the best
+// chain in batches.  The returned hash is calculated over the coins as read.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) readSnapshotCoins(r io.Reader, numCoins uint64, baseHeight int32) (*chainhash.Hash, error) {
+	hasher := sha256.New()
+	r = io.TeeReader(r, hasher)
+
+	var keys, entries [][]byte
+	writeBatch := func() error {
+		err := b.db.Update(func(dbTx database.Tx) error {
+			utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+			for i := range keys {
+				err := utxoBucket.Put(keys[i], entries[i])
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		keys, entries = keys[:0], entries[:0]
+		return err
+	}
+
+	var prevKey []byte
+	for i := uint64(0); i < numCoins; i++ {
+		key, err := wire.ReadVarBytes(r, 0, maxSnapshotKeySize, "key")
+		if err != nil {
+			return nil, err
+		}
+		entry, err := wire.ReadVarBytes(r, 0, wire.MaxBlockPayload,
+			"entry")
+		if err != nil {
+			return nil, err
+		}
+
+		// The coins must be well formed and ordered by key, which also
+		// ensures there are no duplicates.
+		if len(key) <= chainhash.HashSize {
+			return nil, fmt.Errorf("coin %d has a malformed outpoint", i)
+		}
+		_, size := deserializeVLQ(key[chainhash.HashSize:])
+		if size != len(key)-chainhash.HashSize {
+			return nil, fmt.Errorf("coin %d has a malformed outpoint", i)
+		}
+		if prevKey != nil && bytes.Compare(prevKey, key) >= 0 {
+			return nil, fmt.Errorf("coin %d is out of order", i)
+		}
+		utxo, err := deserializeUtxoEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("coin %d: %v", i, err)
+		}
+		if utxo.BlockHeight() > baseHeight {
+			return nil, fmt.Errorf("coin %d is from height %d which "+
+				"is after the snapshot", i, utxo.BlockHeight())
+		}
+		prevKey = key
+
+		keys = append(keys, key)
+		entries = append(entries, entry)
+		if len(keys) == snapshotBatchSize {
+			if err := writeBatch(); err != nil {
+				return nil, err
+			}
+			log.Infof("Loaded %d of %d coins from the utxo snapshot",
+				i+1, numCoins)
+		}
+	}
+	if err := writeBatch(); err != nil {
+		return nil, err
+	}
+
+	var snapshotHash chainhash.Hash
+	copy(snapshotHash[:], hasher.Sum(nil))
+	return &snapshotHash, nil
+}
+
+// dbDiscardSnapshotCoins uses an existing database transaction to remove all
+// coins from the utxo set of the best chain along with the state of a utxo
+// snapshot which was being loaded into it.
+func dbDiscardSnapshotCoins(dbTx database.Tx) error {
+	meta := dbTx.Metadata()
+	if err := meta.DeleteBucket(utxoSetBucketName); err != nil {
+		return err
+	}
+	if _, err := meta.CreateBucket(utxoSetBucketName); err != nil {
+		return err
+	}
+	return meta.Delete(snapshotStateKeyName)
+}
+
+// LoadUtxoSnapshot replaces the chain state with the utxo snapshot read from the
+// passed reader.  The best chain then ends at the block of the snapshot and the
+// blocks below it are validated in the background as they are downloaded, see
+// SnapshotBlocksNeeded.
+//
+// Only snapshots the chain parameters commit to are accepted, and only while
+// the block index contains the genesis block alone and no optional indexes are
+// enabled.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) LoadUtxoSnapshot(r io.Reader) (*UtxoSnapshot, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	if b.indexManager != nil {
+		return nil, errors.New("utxo snapshots can't be loaded with " +
+			"optional indexes enabled")
+	}
+	b.index.RLock()
+	numNodes := len(b.index.index)
+	b.index.RUnlock()
+	if numNodes != 1 {
+		return nil, errors.New("utxo snapshots can only be loaded " +
+			"before any blocks after the genesis block are known")
+	}
+
+	br := bufio.NewReader(r)
+	var header [snapshotHeaderSize]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:4], snapshotMagic[:]) {
+		return nil, errors.New("not a utxo snapshot")
+	}
+	version := binary.LittleEndian.Uint16(header[4:])
+	if version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported utxo snapshot version %d",
+			version)
+	}
+	var snapshot UtxoSnapshot
+	copy(snapshot.BaseHash[:], header[6:])
+	offset := 6 + chainhash.HashSize
+	snapshot.BaseHeight = int32(binary.LittleEndian.Uint32(header[offset:]))
+	snapshot.NumCoins = binary.LittleEndian.Uint64(header[offset+4:])
+
+	// The chain parameters must commit to the snapshot.
+	commitment := b.assumeUtxo(&snapshot.BaseHash, snapshot.BaseHeight)
+	if commitment == nil {
+		return nil, fmt.Errorf("the chain parameters do not list a utxo "+
+			"snapshot for block %v at height %d", snapshot.BaseHash,
+			snapshot.BaseHeight)
+	}
+	snapshot.ChainTxCount = commitment.ChainTxCount
+
+	// Read the headers which lead to the base block.  Their proof of work
+	// does not need to be checked since the chain parameters commit to the
+	// hash of the base block, which commits to all of them.  The blocks
+	// are not marked as valid until they are validated in the background.
+	log.Infof("Loading utxo snapshot at height %d", snapshot.BaseHeight)
+	nodes := make([]*blockNode, 0, snapshot.BaseHeight)
+	parent := b.bestChain.Genesis()
+	for height := int32(1); height < snapshot.BaseHeight; height++ {
+		var header wire.BlockHeader
+		if err := header.Deserialize(br); err != nil {
+			return nil, err
+		}
+		if header.PrevBlock != parent.hash {
+			return nil, fmt.Errorf("header at height %d does not "+
+				"connect to the previous header", height)
+		}
+		node := newBlockNode(&header, parent)
+		nodes = append(nodes, node)
+		parent = node
+	}
+
+	// The base block must be the committed block and its transactions must
+	// match its header.
+	var msgBlock wire.MsgBlock
+	if err := msgBlock.Deserialize(br); err != nil {
+		return nil, err
+	}
+	block := btcutil.NewBlock(&msgBlock)
+	block.SetHeight(snapshot.BaseHeight)
+	if *block.Hash() != snapshot.BaseHash ||
+		msgBlock.Header.PrevBlock != parent.hash {
+
+		return nil, fmt.Errorf("utxo snapshot does not contain block %v",
+			snapshot.BaseHash)
+	}
+	err := checkBlockSanity(block, b.chainParams.PowLimit,
+		b.chainParams.PowAlgorithmAt(snapshot.BaseHeight), b.timeSource,
+		BFNoPoWCheck)
+	if err != nil {
+		return nil, err
+	}
+	base := newBlockNode(&msgBlock.Header, parent)
+	base.status = statusDataStored
+	nodes = append(nodes, base)
+
+	// Record that the snapshot is being loaded before writing its coins to
+	// the utxo set so they are discarded when loading is interrupted.
+	state := snapshotState{
+		status:       snapshotLoading,
+		baseHash:     snapshot.BaseHash,
+		snapshotHash: *commitment.SnapshotHash,
+	}
+	err = b.db.Update(func(dbTx database.Tx) error {
+		return dbPutSnapshotState(dbTx, &state)
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshotHash, err := b.readSnapshotCoins(br, snapshot.NumCoins,
+		snapshot.BaseHeight)
+	if err == nil && *snapshotHash != *commitment.SnapshotHash {
+		err = fmt.Errorf("utxo snapshot hash %v does not match the "+
+			"expected hash %v", snapshotHash, commitment.SnapshotHash)
+	}
+	if err != nil {
+		dbErr := b.db.Update(dbDiscardSnapshotCoins)
+		if dbErr != nil {
+			log.Errorf("Unable to discard utxo snapshot: %v", dbErr)
+		}
+		return nil, err
+	}
+	snapshot.SnapshotHash = *snapshotHash
+
+	// Replace the chain state with the one as of the base block in a single
+	// transaction and set up the utxo set the blocks below the base block
+	// are connected to as they are validated.
+	blockSize := uint64(msgBlock.SerializeSize())
+	blockWeight := uint64(GetBlockWeight(block))
+	numTxns := uint64(len(msgBlock.Transactions))
+	bestState := newBestState(base, blockSize, blockWeight, numTxns,
+		commitment.ChainTxCount, base.CalcPastMedianTime())
+	state.status = snapshotValidating
+	genesisHash := b.bestChain.Genesis().hash
+	var utxoSetStats *utxoStats
+	err = b.db.Update(func(dbTx database.Tx) error {
+		for _, node := range nodes {
+			err := dbStoreBlockNode(dbTx, node)
+			if err != nil {
+				return err
+			}
+			err = dbPutBlockIndex(dbTx, &node.hash, node.height)
+			if err != nil {
+				return err
+			}
+		}
+		err := dbStoreBlock(dbTx, block)
+		if err != nil {
+			return err
+		}
+		err = dbPutBestState(dbTx, bestState, base.workSum)
+		if err != nil {
+			return err
+		}
+		err = dbPutUtxoStateConsistency(dbTx, &base.hash)
+		if err != nil {
+			return err
+		}
+		utxoSetStats, err = calcUtxoStats(dbTx)
+		if err != nil {
+			return err
+		}
+		err = dbPutUtxoStats(dbTx, &base.hash, utxoSetStats)
+		if err != nil {
+			return err
+		}
+
+		_, err = dbTx.Metadata().CreateBucket(snapshotUtxoSetBucketName)
+		if err != nil {
+			return err
+		}
+		err = dbTx.Metadata().Put(snapshotUtxoStateKeyName,
+			genesisHash[:])
+		if err != nil {
+			return err
+		}
+		return dbPutSnapshotState(dbTx, &state)
+	})
+	if err != nil {
+		dbErr := b.db.Update(dbDiscardSnapshotCoins)
+		if dbErr != nil {
+			log.Errorf("Unable to discard utxo snapshot: %v", dbErr)
+		}
+		return nil, err
+	}
+
+	for _, node := range nodes {
+		b.index.addNode(node)
+	}
+	b.bestChain.SetTip(base)
+	b.stateSnapshot = bestState
+	b.utxoStats = utxoSetStats
+	b.utxoCache.markFlushed(&base.hash)
+
+	validation := &snapshotValidation{
+		base:         base,
+		snapshotHash: state.snapshotHash,
+		tip:          b.bestChain.Genesis(),
+		utxoCache:    b.newSnapshotUtxoCache(),
+	}
+	validation.utxoCache.markFlushed(&genesisHash)
+	b.snapshot = validation
+
+	log.Infof("Loaded utxo snapshot with %d coins at height %d -- the "+
+		"blocks below it will be validated in the background",
+		snapshot.NumCoins, snapshot.BaseHeight)
+	return &snapshot, nil
+}
+
+// initSnapshotState resumes the validation of the blocks below a utxo snapshot
+// loaded into the database, if any, and discards the coins of a snapshot which
+// was not loaded completely.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) initSnapshotState(interrupt <-chan struct{}) error {
+	var state *snapshotState
+	var stateHash *chainhash.Hash
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		state, err = dbFetchSnapshotState(dbTx)
+		stateHash = dbFetchUtxoState(dbTx, snapshotUtxoStateKeyName)
+		return err
+	})
+	if err != nil || state == nil {
+		return err
+	}
+
+	switch state.status {
+	case snapshotLoading:
+		log.Warnf("Discarding utxo snapshot which was not loaded " +
+			"completely")
+		return b.db.Update(dbDiscardSnapshotCoins)
+
+	case snapshotInvalid:
+		log.Errorf("The utxo snapshot loaded at block %v does not match "+
+			"the blocks below it -- the best chain is not valid and "+
+			"the data directory must be deleted", state.baseHash)
+		return ErrSnapshotInvalid
+	}
+
+	base := b.index.LookupNode(&state.baseHash)
+	var tip *blockNode
+	if stateHash != nil {
+		tip = b.index.LookupNode(stateHash)
+	}
+	if base == nil || tip == nil || base.Ancestor(tip.height) != tip {
+		return AssertError(fmt.Sprintf("utxo snapshot validation state "+
+			"%v is not an ancestor of the snapshot block %v",
+			stateHash, state.baseHash))
+	}
+
+	b.snapshot = &snapshotValidation{
+		base:         base,
+		snapshotHash: state.snapshotHash,
+		tip:          tip,
+		utxoCache:    b.newSnapshotUtxoCache(),
+	}
+	b.snapshot.utxoCache.markFlushed(&tip.hash)
+	log.Infof("Validating the blocks below the utxo snapshot at height %d "+
+		"(validated through height %d)", base.height, tip.height)
+	return b.validateSnapshotBlocks(interrupt)
+}
+
+// isSnapshotBlock returns whether or not the passed block is below a loaded
+// utxo snapshot and its data is still needed to validate it.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) isSnapshotBlock(node *blockNode) bool {
+	s := b.snapshot
+	return s != nil && node.height > s.tip.height &&
+		s.base.Ancestor(node.height) == node &&
+		!b.index.NodeStatus(node).HaveData()
+}
+
+// processSnapshotBlock stores the data for the passed block below a loaded utxo
+// snapshot and connects as many of the blocks below the snapshot to the utxo set
+// built from them as possible.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) processSnapshotBlock(node *blockNode, block *btcutil.Block) error {
+	// The block is already known to be in the chain from its header, but
+	// its transactions must match it.
+	block.SetHeight(node.height)
+	err := checkBlockSanity(block, b.chainParams.PowLimit,
+		b.chainParams.PowAlgorithmAt(node.height), b.timeSource,
+		BFNoPoWCheck)
+	if err != nil {
+		return err
+	}
+	err = b.checkBlockContext(block, node.parent, BFNone)
+	if err != nil {
+		return err
+	}
+
+	err = b.db.Update(func(dbTx database.Tx) error {
+		return dbStoreBlock(dbTx, block)
+	})
+	if err != nil {
+		return err
+	}
+	b.index.SetStatusFlags(node, statusDataStored)
+	if err := b.index.flushToDB(); err != nil {
+		return err
+	}
+
+	return b.validateSnapshotBlocks(nil)
+}
+
+// validateSnapshotBlocks connects the blocks below a loaded utxo snapshot whose
+// data is available to the utxo set built from them, in order, and checks that
+// it matches the snapshot once the snapshot block has been connected.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) validateSnapshotBlocks(interrupt <-chan struct{}) error {
+	s := b.snapshot
+	for s != nil && s.tip != s.base {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		node := s.base.Ancestor(s.tip.height + 1)
+		if !b.index.NodeStatus(node).HaveData() {
+			return nil
+		}
+		var block *btcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByNode(dbTx, node)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		view := NewUtxoViewpoint()
+		view.SetBestHash(&s.tip.hash)
+		stxos := make([]SpentTxOut, 0, countSpentOutputs(block))
+		err = b.checkConnectBlockUtxos(s.utxoCache, node, block, view,
+			&stxos, BFNone)
+		if _, ok := err.(RuleError); ok {
+			b.index.SetStatusFlags(node, statusValidateFailed)
+			log.Errorf("Block %v (height %d) below the utxo snapshot "+
+				"is invalid: %v", node.hash, node.height, err)
+			return b.invalidateSnapshot()
+		}
+		if err != nil {
+			return err
+		}
+
+		err = b.db.Update(func(dbTx database.Tx) error {
+			return dbPutSpendJournalEntry(dbTx, &node.hash, stxos)
+		})
+		if err != nil {
+			return err
+		}
+		s.utxoCache.commit(view)
+		s.tip = node
+
+		// The block is flushed as valid right away since the utxo set
+		// built from the blocks may be flushed past it, in which case
+		// it is not validated again.
+		b.index.SetStatusFlags(node, statusValid)
+		if err := b.index.flushToDB(); err != nil {
+			return err
+		}
+
+		err = s.utxoCache.flush(FlushPeriodic, &node.hash)
+		if err != nil {
+			return err
+		}
+	}
+	if s == nil {
+		return nil
+	}
+
+	return b.finishSnapshotValidation()
+}
+
+// finishSnapshotValidation compares the utxo set built from the blocks below a
+// loaded utxo snapshot, which must have all been connected to it, to the
+// snapshot and removes it from the database.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) finishSnapshotValidation() error {
+	s := b.snapshot
+	if err := s.utxoCache.flush(FlushRequired, &s.tip.hash); err != nil {
+		return err
+	}
+
+	var snapshotHash chainhash.Hash
+	err := b.db.View(func(dbTx database.Tx) error {
+		hasher := sha256.New()
+		utxoBucket := dbTx.Metadata().Bucket(snapshotUtxoSetBucketName)
+		if _, err := writeSnapshotCoins(hasher, utxoBucket); err != nil {
+			return err
+		}
+		copy(snapshotHash[:], hasher.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if snapshotHash != s.snapshotHash {
+		log.Errorf("The utxo set built from the blocks below the utxo "+
+			"snapshot at height %d has hash %v instead of %v",
+			s.base.height, snapshotHash, s.snapshotHash)
+		return b.invalidateSnapshot()
+	}
+
+	err = b.db.Update(func(dbTx database.Tx) error {
+		if err := dbRemoveSnapshotUtxoSet(dbTx); err != nil {
+			return err
+		}
+		return dbTx.Metadata().Delete(snapshotStateKeyName)
+	})
+	if err != nil {
+		return err
+	}
+	b.snapshot = nil
+
+	log.Infof("Validated the blocks below the utxo snapshot at height %d",
+		s.base.height)
+	return nil
+}
+
+// invalidateSnapshot records that the blocks below a loaded utxo snapshot do
+// not match it and stops validating them.  It returns ErrSnapshotInvalid so the
+// caller halts, and all further blocks are rejected with it as well.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) invalidateSnapshot() error {
+	s := b.snapshot
+	b.snapshot = nil
+
+	log.Errorf("The utxo snapshot loaded at block %v is invalid -- the best "+
+		"chain is not valid and the data directory must be deleted",
+		s.base.hash)
+	state := snapshotState{
+		status:       snapshotInvalid,
+		baseHash:     s.base.hash,
+		snapshotHash: s.snapshotHash,
+	}
+	err := b.db.Update(func(dbTx database.Tx) error {
+		if err := dbRemoveSnapshotUtxoSet(dbTx); err != nil {
+			return err
+		}
+		return dbPutSnapshotState(dbTx, &state)
+	})
+	if err != nil {
+		return err
+	}
+	b.snapshotInvalid = true
+	return ErrSnapshotInvalid
+}
+
+// dbRemoveSnapshotUtxoSet uses an existing database transaction to remove the
+// utxo set built from the blocks below a loaded utxo snapshot.
+func dbRemoveSnapshotUtxoSet(dbTx database.Tx) error {
+	meta := dbTx.Metadata()
+	if err := meta.DeleteBucket(snapshotUtxoSetBucketName); err != nil {
+		return err
+	}
+	return meta.Delete(snapshotUtxoStateKeyName)
+}
+
+// SnapshotBlocksNeeded returns the hashes of up to the passed number of blocks
+// below a loaded utxo snapshot whose data is needed to validate them, starting
+// with the lowest.  It returns nil when no snapshot is being validated.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SnapshotBlocksNeeded(maxHashes int) []chainhash.Hash {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	s := b.snapshot
+	if s == nil {
+		return nil
+	}
+	var hashes []chainhash.Hash
+	for height := s.tip.height + 1; height < s.base.height &&
+		len(hashes) < maxHashes; height++ {
+
+		node := s.base.Ancestor(height)
+		if !b.index.NodeStatus(node).HaveData() {
+			hashes = append(hashes, node.hash)
+		}
+	}
+	return hashes
+}
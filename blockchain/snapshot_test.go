@@ -0,0 +1,427 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestUtxoSnapshot ensures a utxo snapshot round trips from one chain to
+// another and that the blocks below it are validated against it.
+func TestUtxoSnapshot(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	source, teardownFunc, err := chainSetup("snapshotsource", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+
+	source.TstSetCoinbaseMaturity(1)
+
+	// Extend the source chain so there is a utxo set to dump, where the
+	// second block spends the coinbase of the first one.  The blocks are
+	// solved since the first one is validated with the snapshot later.
+	var blocks []*btcutil.Block
+	prev := &params.GenesisBlock.Header
+	for height := int32(1); height <= 2; height++ {
+		var spends []testhelper.SpendableOut
+		if height > 1 {
+			spends = append(spends, coinbaseSpend(blocks[height-2]))
+		}
+		block, err := solveTestBlock(&params,
+			newTestBlock(&params, prev, height, spends...), height)
+		if err != nil {
+			t.Fatalf("solveTestBlock #%d: unexpected error: %v",
+				height, err)
+		}
+		_, _, err = source.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock #%d: unexpected error: %v", height,
+				err)
+		}
+		blocks = append(blocks, block)
+		prev = &block.MsgBlock().Header
+	}
+
+	var buf bytes.Buffer
+	snapshot, err := source.DumpUtxoSnapshot(&buf)
+	teardownFunc()
+	if err != nil {
+		t.Fatalf("DumpUtxoSnapshot: unexpected error: %v", err)
+	}
+	if snapshot.BaseHeight != 2 || snapshot.BaseHash != *blocks[1].Hash() ||
+		snapshot.NumCoins != 2 || snapshot.ChainTxCount != 4 {
+
+		t.Fatalf("DumpUtxoSnapshot: unexpected snapshot %+v", snapshot)
+	}
+
+	// The snapshot can't be loaded without a commitment to it.
+	chain, teardownFunc, err := chainSetup("snapshot", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+	_, err = chain.LoadUtxoSnapshot(bytes.NewReader(buf.Bytes()))
+	if err == nil {
+		t.Fatal("LoadUtxoSnapshot: loaded snapshot without a commitment")
+	}
+
+	// A snapshot which does not match the commitment is rejected and its
+	// coins are discarded.
+	chain.chainParams.AssumeUtxo = []chaincfg.AssumeUtxo{{
+		Height:       snapshot.BaseHeight,
+		BlockHash:    &snapshot.BaseHash,
+		SnapshotHash: &snapshot.SnapshotHash,
+		ChainTxCount: snapshot.ChainTxCount,
+	}}
+	tampered := append([]byte(nil), buf.Bytes()...)
+	tampered[len(tampered)-1] ^= 0x01
+	_, err = chain.LoadUtxoSnapshot(bytes.NewReader(tampered))
+	if err == nil {
+		t.Fatal("LoadUtxoSnapshot: loaded tampered snapshot")
+	}
+	spendOut := wire.OutPoint{Hash: *blocks[1].Transactions()[1].Hash()}
+	if entry := dbUtxoEntry(t, chain.utxoCache, spendOut); entry != nil {
+		t.Fatal("LoadUtxoSnapshot: coins of tampered snapshot were " +
+			"not discarded")
+	}
+
+	// Load the snapshot and ensure the chain continues from its block.
+	loaded, err := chain.LoadUtxoSnapshot(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("LoadUtxoSnapshot: unexpected error: %v", err)
+	}
+	if *loaded != *snapshot {
+		t.Fatalf("LoadUtxoSnapshot: unexpected snapshot %+v, want %+v",
+			loaded, snapshot)
+	}
+	best := chain.BestSnapshot()
+	if best.Hash != snapshot.BaseHash || best.TotalTxns != 4 {
+		t.Fatalf("LoadUtxoSnapshot: unexpected best state %+v", best)
+	}
+	entry, err := chain.FetchUtxoEntry(spendOut)
+	if err != nil || entry == nil {
+		t.Fatalf("FetchUtxoEntry: missing coin from snapshot (err %v)",
+			err)
+	}
+	spentOut := coinbaseSpend(blocks[0]).PrevOut
+	entry, err = chain.FetchUtxoEntry(spentOut)
+	if err != nil || (entry != nil && !entry.IsSpent()) {
+		t.Fatalf("FetchUtxoEntry: spent coin in snapshot (err %v)", err)
+	}
+
+	// The blocks below the snapshot and the snapshot block itself are not
+	// known to be valid until they are validated, also when the block
+	// index is loaded again in the meantime.
+	assertValid := func(name string, c *BlockChain, want bool) {
+		t.Helper()
+		for _, block := range blocks {
+			node := c.index.LookupNode(block.Hash())
+			if got := c.index.NodeStatus(node).KnownValid(); got != want {
+				t.Fatalf("%s: block %v known valid %v, want %v",
+					name, block.Hash(), got, want)
+			}
+		}
+	}
+	assertValid("LoadUtxoSnapshot", chain, false)
+	reloaded, err := New(&Config{
+		DB:          chain.db,
+		ChainParams: chain.chainParams,
+		TimeSource:  NewMedianTime(),
+		SigCache:    txscript.NewSigCache(1000),
+	})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	assertValid("New", reloaded, false)
+
+	// The history below the snapshot is validated once the missing block
+	// is processed.
+	needed := chain.SnapshotBlocksNeeded(10)
+	if len(needed) != 1 || needed[0] != *blocks[0].Hash() {
+		t.Fatalf("SnapshotBlocksNeeded: unexpected blocks %v", needed)
+	}
+	_, _, err = chain.ProcessBlock(blocks[0], BFNone)
+	if err != nil {
+		t.Fatalf("ProcessBlock: unexpected error: %v", err)
+	}
+	if needed := chain.SnapshotBlocksNeeded(10); needed != nil {
+		t.Fatalf("SnapshotBlocksNeeded: unexpected blocks %v", needed)
+	}
+	assertValid("ProcessBlock", chain, true)
+	err = chain.db.View(func(dbTx database.Tx) error {
+		state, err := dbFetchSnapshotState(dbTx)
+		if err != nil {
+			return err
+		}
+		if state != nil {
+			t.Fatalf("unexpected snapshot state %+v after validation",
+				state)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("dbFetchSnapshotState: unexpected error: %v", err)
+	}
+}
+
+// blockingWriter is an io.Writer which signals the first write and then blocks
+// until its unblock channel is closed before writing to the underlying buffer.
+type blockingWriter struct {
+	bytes.Buffer
+	writing chan struct{}
+	unblock chan struct{}
+	once    sync.Once
+}
+
+// Write closes the writing channel on the first call, waits for the unblock
+// channel to be closed and then writes the passed bytes to the buffer.
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.writing) })
+	<-w.unblock
+	return w.Buffer.Write(p)
+}
+
+// TestDumpUtxoSnapshotConcurrent ensures blocks can be processed while a utxo
+// snapshot is being written and that the snapshot is not affected by them.
+func TestDumpUtxoSnapshotConcurrent(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("dumpsnapshot", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Every block spends the coinbase of the previous one.
+	var blocks []*btcutil.Block
+	prev := &params.GenesisBlock.Header
+	for height := int32(1); height <= 3; height++ {
+		var spends []testhelper.SpendableOut
+		if height > 1 {
+			spends = append(spends, coinbaseSpend(blocks[height-2]))
+		}
+		block := newTestBlock(&params, prev, height, spends...)
+		blocks = append(blocks, block)
+		prev = &block.MsgBlock().Header
+	}
+	for i, block := range blocks[:2] {
+		_, _, err := chain.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock #%d: unexpected error: %v", i+1,
+				err)
+		}
+	}
+	tipHash := chain.BestSnapshot().Hash
+
+	w := &blockingWriter{
+		writing: make(chan struct{}),
+		unblock: make(chan struct{}),
+	}
+	type dumpResult struct {
+		snapshot *UtxoSnapshot
+		err      error
+	}
+	dumped := make(chan dumpResult, 1)
+	go func() {
+		snapshot, err := chain.DumpUtxoSnapshot(w)
+		dumped <- dumpResult{snapshot, err}
+	}()
+
+	// Wait for the dump to start writing, which happens after it took its
+	// view of the utxo set.
+	<-w.writing
+	processed := make(chan error, 1)
+	go func() {
+		_, _, err := chain.ProcessBlock(blocks[2], BFNoPoWCheck)
+		processed <- err
+	}()
+	select {
+	case err := <-processed:
+		if err != nil {
+			t.Fatalf("ProcessBlock: unexpected error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("ProcessBlock blocked by DumpUtxoSnapshot")
+	}
+
+	close(w.unblock)
+	result := <-dumped
+	if result.err != nil {
+		t.Fatalf("DumpUtxoSnapshot: unexpected error: %v", result.err)
+	}
+	if result.snapshot.BaseHash != tipHash ||
+		result.snapshot.NumCoins != 2 {
+
+		t.Fatalf("DumpUtxoSnapshot: unexpected snapshot %+v",
+			result.snapshot)
+	}
+}
+
+// TestInvalidUtxoSnapshot ensures a chain refuses to continue, including after
+// a restart, once the blocks below a loaded utxo snapshot turn out not to match
+// it.
+func TestInvalidUtxoSnapshot(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	source, teardownFunc, err := chainSetup("invalidsnapshotsource", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	source.TstSetCoinbaseMaturity(1)
+	var blocks []*btcutil.Block
+	prev := &params.GenesisBlock.Header
+	for height := int32(1); height <= 2; height++ {
+		var spends []testhelper.SpendableOut
+		if height > 1 {
+			spends = append(spends, coinbaseSpend(blocks[height-2]))
+		}
+		block, err := solveTestBlock(&params,
+			newTestBlock(&params, prev, height, spends...), height)
+		if err != nil {
+			t.Fatalf("solveTestBlock #%d: unexpected error: %v",
+				height, err)
+		}
+		_, _, err = source.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock #%d: unexpected error: %v", height,
+				err)
+		}
+		blocks = append(blocks, block)
+		prev = &block.MsgBlock().Header
+	}
+	var buf bytes.Buffer
+	snapshot, err := source.DumpUtxoSnapshot(&buf)
+	teardownFunc()
+	if err != nil {
+		t.Fatalf("DumpUtxoSnapshot: unexpected error: %v", err)
+	}
+
+	// Change the script of the last coin and commit to the changed coins,
+	// so the snapshot loads but does not match the blocks below it.
+	tampered := append([]byte(nil), buf.Bytes()...)
+	tampered[len(tampered)-1] = 0x50
+	coinsOffset := snapshotHeaderSize + wire.MaxBlockHeaderPayload*
+		int(snapshot.BaseHeight-1) + blocks[1].MsgBlock().SerializeSize()
+	snapshotHash := chainhash.Hash(sha256.Sum256(tampered[coinsOffset:]))
+
+	chain, teardownFunc, err := chainSetup("invalidsnapshot", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+	chain.chainParams.AssumeUtxo = []chaincfg.AssumeUtxo{{
+		Height:       snapshot.BaseHeight,
+		BlockHash:    &snapshot.BaseHash,
+		SnapshotHash: &snapshotHash,
+		ChainTxCount: snapshot.ChainTxCount,
+	}}
+	_, err = chain.LoadUtxoSnapshot(bytes.NewReader(tampered))
+	if err != nil {
+		t.Fatalf("LoadUtxoSnapshot: unexpected error: %v", err)
+	}
+
+	// Validating the block below the snapshot invalidates it, after which
+	// no more blocks are processed.
+	_, _, err = chain.ProcessBlock(blocks[0], BFNone)
+	if err != ErrSnapshotInvalid {
+		t.Fatalf("ProcessBlock: unexpected error: %v", err)
+	}
+	block := newTestBlock(&params, &blocks[1].MsgBlock().Header, 3,
+		coinbaseSpend(blocks[1]))
+	_, _, err = chain.ProcessBlock(block, BFNoPoWCheck)
+	if err != ErrSnapshotInvalid {
+		t.Fatalf("ProcessBlock: unexpected error: %v", err)
+	}
+
+	// The chain can't be loaded from the database again either.
+	chain.snapshotInvalid = false
+	if err := chain.initSnapshotState(nil); err != ErrSnapshotInvalid {
+		t.Fatalf("initSnapshotState: unexpected error: %v", err)
+	}
+}
+
+// TestRegtestAssumeUtxo ensures the utxo snapshot the regression test network
+// parameters commit to is the one dumped from its deterministic test chain,
+// which consists of version 4 blocks, spaced ten minutes apart, that only
+// contain a coinbase paying to OP_TRUE and use the lowest nonce which
+// satisfies the proof-of-work limit.
+func TestRegtestAssumeUtxo(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	if len(params.AssumeUtxo) != 1 {
+		t.Fatalf("unexpected number of regtest utxo snapshots %d",
+			len(params.AssumeUtxo))
+	}
+	want := params.AssumeUtxo[0]
+
+	chain, teardownFunc, err := chainSetup("regtestassumeutxo", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	prev := &params.GenesisBlock.Header
+	for height := int32(1); height <= want.Height; height++ {
+		coinbase := wire.NewMsgTx(1)
+		coinbase.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+				wire.MaxPrevOutIndex),
+			SignatureScript: []byte{0x01, byte(height), 0x00},
+			Sequence:        wire.MaxTxInSequenceNum,
+		})
+		coinbase.AddTxOut(wire.NewTxOut(CalcBlockSubsidy(height,
+			&params), testhelper.OpTrueScript))
+		block := btcutil.NewBlock(&wire.MsgBlock{
+			Header: wire.BlockHeader{
+				Version:   4,
+				PrevBlock: prev.BlockHash(),
+				Timestamp: prev.Timestamp.Add(10 * time.Minute),
+				Bits:      params.PowLimitBits,
+			},
+			Transactions: []*wire.MsgTx{coinbase},
+		})
+		merkles := BuildMerkleTreeStore(block.Transactions(), false)
+		block.MsgBlock().Header.MerkleRoot = *merkles[len(merkles)-1]
+		block, err := solveTestBlock(&params, block, height)
+		if err != nil {
+			t.Fatalf("solveTestBlock #%d: unexpected error: %v",
+				height, err)
+		}
+		_, _, err = chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("ProcessBlock #%d: unexpected error: %v", height,
+				err)
+		}
+		prev = &block.MsgBlock().Header
+	}
+
+	snapshot, err := chain.DumpUtxoSnapshot(io.Discard)
+	if err != nil {
+		t.Fatalf("DumpUtxoSnapshot: unexpected error: %v", err)
+	}
+	if snapshot.BaseHeight != want.Height ||
+		snapshot.BaseHash != *want.BlockHash ||
+		snapshot.SnapshotHash != *want.SnapshotHash ||
+		snapshot.ChainTxCount != want.ChainTxCount {
+
+		t.Fatalf("mismatched snapshot -- got %+v, want height %d, "+
+			"block %v, snapshot %v, %d transactions", snapshot,
+			want.Height, want.BlockHash, want.SnapshotHash,
+			want.ChainTxCount)
+	}
+}
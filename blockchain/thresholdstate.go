@@ -21,7 +21,7 @@ const (
 	ThresholdDefined ThresholdState = iota
 
 	// ThresholdStarted is the state for a deployment once its start time
-	// has been reached.
+	// or height has been reached.
 	ThresholdStarted
 
 	// ThresholdLockedIn is the state for a deployment during the retarget
@@ -32,14 +32,21 @@ const (
 
 	// ThresholdActive is the state for a deployment for all blocks after a
 	// retarget period in which the deployment was in the ThresholdLockedIn
-	// state.
+	// state, once its minimum activation height has been reached.
 	ThresholdActive
 
 	// ThresholdFailed is the state for a deployment once its expiration
-	// time has been reached and it did not reach the ThresholdLockedIn
-	// state.
+	// time or timeout height has been reached and it did not reach the
+	// ThresholdLockedIn state.
 	ThresholdFailed
 
+	// ThresholdMustSignal is the state for a height-based deployment with
+	// lock in on timeout during the last retarget period before its timeout
+	// height when it has not yet reached the ThresholdLockedIn state.  All
+	// blocks in the period must signal for the deployment, which locks it
+	// in at the end of the period.
+	ThresholdMustSignal
+
 	// numThresholdsStates is the maximum number of threshold states used in
 	// tests.
 	numThresholdsStates
@@ -48,11 +55,12 @@ const (
 // thresholdStateStrings is a map of ThresholdState values back to their
 // constant names for pretty printing.
 var thresholdStateStrings = map[ThresholdState]string{
-	ThresholdDefined:  "ThresholdDefined",
-	ThresholdStarted:  "ThresholdStarted",
-	ThresholdLockedIn: "ThresholdLockedIn",
-	ThresholdActive:   "ThresholdActive",
-	ThresholdFailed:   "ThresholdFailed",
+	ThresholdDefined:    "ThresholdDefined",
+	ThresholdStarted:    "ThresholdStarted",
+	ThresholdLockedIn:   "ThresholdLockedIn",
+	ThresholdActive:     "ThresholdActive",
+	ThresholdFailed:     "ThresholdFailed",
+	ThresholdMustSignal: "ThresholdMustSignal",
 }
 
 // String returns the ThresholdState as a human-readable name.
@@ -75,6 +83,28 @@ type thresholdConditionChecker interface {
 	// locked in or activated.
 	EndTime() uint64
 
+	// HeightBased returns whether the rule change starts and times out at
+	// the block heights returned by BeginHeight and EndHeight instead of
+	// the median block times returned by BeginTime and EndTime.
+	HeightBased() bool
+
+	// BeginHeight returns the height of the block at which voting on a
+	// rule change starts.
+	BeginHeight() uint32
+
+	// EndHeight returns the height of the block at which an attempted rule
+	// change fails if it has not already been locked in or activated.
+	EndHeight() uint32
+
+	// MinActivationHeight returns the height of the block before which a
+	// locked in rule change can't become active.
+	MinActivationHeight() uint32
+
+	// LockInOnTimeout returns whether blocks must signal for a height-based
+	// rule change during the last window before its end height, which
+	// ensures it is locked in instead of failing.
+	LockInOnTimeout() bool
+
 	// RuleChangeActivationThreshold is the number of blocks for which the
 	// condition must be true in order to lock in a rule change.
 	RuleChangeActivationThreshold() uint32
@@ -121,6 +151,28 @@ func newThresholdCaches(numCaches uint32) []thresholdStateCache {
 	return caches
 }
 
+// thresholdBeginReached returns whether voting on the rule change of the passed
+// checker starts in the window after the passed node, which is the last block
+// of a confirmation window.
+func thresholdBeginReached(checker thresholdConditionChecker, prevNode *blockNode) bool {
+	if checker.HeightBased() {
+		return uint32(prevNode.height+1) >= checker.BeginHeight()
+	}
+	medianTime := prevNode.CalcPastMedianTime()
+	return uint64(medianTime.Unix()) >= checker.BeginTime()
+}
+
+// thresholdEndReached returns whether the rule change of the passed checker
+// times out in the window after the passed node, which is the last block of a
+// confirmation window.
+func thresholdEndReached(checker thresholdConditionChecker, prevNode *blockNode) bool {
+	if checker.HeightBased() {
+		return uint32(prevNode.height+1) >= checker.EndHeight()
+	}
+	medianTime := prevNode.CalcPastMedianTime()
+	return uint64(medianTime.Unix()) >= checker.EndTime()
+}
+
 // thresholdState returns the current rule change threshold state for the block
 // AFTER the given node and deployment ID.  The cache is used to ensure the
 // threshold states for previous windows are only calculated once.
@@ -150,13 +202,9 @@ func (b *BlockChain) thresholdState(prevNode *blockNode, checker thresholdCondit
 			break
 		}
 
-		// The start and expiration times are based on the median block
-		// time, so calculate it now.
-		medianTime := prevNode.CalcPastMedianTime()
-
-		// The state is simply defined if the start time hasn't been
-		// been reached yet.
-		if uint64(medianTime.Unix()) < checker.BeginTime() {
+		// The state is simply defined if the start time or height
+		// hasn't been reached yet.
+		if !thresholdBeginReached(checker, prevNode) {
 			cache.Update(&prevNode.hash, ThresholdDefined)
 			break
 		}
@@ -192,9 +240,7 @@ func (b *BlockChain) thresholdState(prevNode *blockNode, checker thresholdCondit
 		case ThresholdDefined:
 			// The deployment of the rule change fails if it expires
 			// before it is accepted and locked in.
-			medianTime := prevNode.CalcPastMedianTime()
-			medianTimeUnix := uint64(medianTime.Unix())
-			if medianTimeUnix >= checker.EndTime() {
+			if thresholdEndReached(checker, prevNode) {
 				state = ThresholdFailed
 				break
 			}
@@ -202,15 +248,15 @@ func (b *BlockChain) thresholdState(prevNode *blockNode, checker thresholdCondit
 			// The state for the rule moves to the started state
 			// once its start time has been reached (and it hasn't
 			// already expired per the above).
-			if medianTimeUnix >= checker.BeginTime() {
+			if thresholdBeginReached(checker, prevNode) {
 				state = ThresholdStarted
 			}
 
 		case ThresholdStarted:
-			// The deployment of the rule change fails if it expires
-			// before it is accepted and locked in.
-			medianTime := prevNode.CalcPastMedianTime()
-			if uint64(medianTime.Unix()) >= checker.EndTime() {
+			// The deployment of a time-based rule change fails if
+			// it expires before it is accepted and locked in.
+			heightBased := checker.HeightBased()
+			if !heightBased && thresholdEndReached(checker, prevNode) {
 				state = ThresholdFailed
 				break
 			}
@@ -235,15 +281,36 @@ func (b *BlockChain) thresholdState(prevNode *blockNode, checker thresholdCondit
 
 			// The state is locked in if the number of blocks in the
 			// period that voted for the rule change meets the
-			// activation threshold.
-			if count >= checker.RuleChangeActivationThreshold() {
+			// activation threshold.  Otherwise, a height-based rule
+			// change either requires all blocks of the last period
+			// before its timeout to signal for it when it locks in
+			// on timeout or fails once the timeout is reached.
+			nextHeight := uint32(prevNode.height + 1)
+			switch {
+			case count >= checker.RuleChangeActivationThreshold():
 				state = ThresholdLockedIn
+
+			case heightBased && checker.LockInOnTimeout() &&
+				nextHeight+uint32(confirmationWindow) >= checker.EndHeight():
+
+				state = ThresholdMustSignal
+
+			case heightBased && thresholdEndReached(checker, prevNode):
+				state = ThresholdFailed
 			}
 
+		case ThresholdMustSignal:
+			// All blocks in the period signaled for the rule
+			// change, so it is locked in.
+			state = ThresholdLockedIn
+
 		case ThresholdLockedIn:
 			// The new rule becomes active when its previous state
-			// was locked in.
-			state = ThresholdActive
+			// was locked in unless its minimum activation height
+			// hasn't been reached yet.
+			if uint32(prevNode.height+1) >= checker.MinActivationHeight() {
+				state = ThresholdActive
+			}
 
 		// Nothing to do if the previous state is active or failed since
 		// they are both terminal states.
@@ -271,6 +338,174 @@ func (b *BlockChain) ThresholdState(deploymentID uint32) (ThresholdState, error)
 	return state, err
 }
 
+// thresholdStateSince returns the height of the first block which has the same
+// rule change threshold state as the block AFTER the given node.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) thresholdStateSince(prevNode *blockNode, checker thresholdConditionChecker, cache *thresholdStateCache) (int32, error) {
+	state, err := b.thresholdState(prevNode, checker, cache)
+	if err != nil {
+		return 0, err
+	}
+
+	// The state is the same for all blocks within a window, so iterate
+	// backwards through the previous confirmation windows until one with a
+	// different state is found.
+	confirmationWindow := int32(checker.MinerConfirmationWindow())
+	if prevNode == nil || (prevNode.height+1) < confirmationWindow {
+		return 0, nil
+	}
+	prevNode = prevNode.Ancestor(prevNode.height -
+		(prevNode.height+1)%confirmationWindow)
+	for {
+		// The state of the window which contains the genesis block is
+		// defined by definition.
+		prevWindowNode := prevNode.RelativeAncestor(confirmationWindow)
+		if prevWindowNode == nil {
+			if state == ThresholdDefined {
+				return 0, nil
+			}
+			break
+		}
+		prevState, err := b.thresholdState(prevWindowNode, checker, cache)
+		if err != nil {
+			return 0, err
+		}
+		if prevState != state {
+			break
+		}
+		prevNode = prevWindowNode
+	}
+
+	return prevNode.height + 1, nil
+}
+
+// DeploymentStatistics houses the signalling statistics of the confirmation
+// window of a deployment a block is in, counted up to and including the block.
+type DeploymentStatistics struct {
+	// Period is the number of blocks in each confirmation window.
+	Period uint32
+
+	// Threshold is the number of blocks in a confirmation window which
+	// must signal for the deployment in order to lock it in.
+	Threshold uint32
+
+	// Elapsed is the number of blocks in the confirmation window up to and
+	// including the block.
+	Elapsed uint32
+
+	// Count is the number of the elapsed blocks which signal for the
+	// deployment.
+	Count uint32
+
+	// Possible is whether enough of the remaining blocks of the
+	// confirmation window can still signal to lock in the deployment.
+	Possible bool
+}
+
+// DeploymentInfo describes the state of a deployment as of a block.
+type DeploymentInfo struct {
+	// Height is the height of the block.
+	Height int32
+
+	// State is the threshold state of the deployment for the block.
+	State ThresholdState
+
+	// Since is the height of the first block which has the same state.
+	Since int32
+
+	// NextState is the threshold state of the deployment for the block
+	// after the block.
+	NextState ThresholdState
+
+	// Statistics houses the signalling statistics of the confirmation
+	// window the block is in.  It is only set when the state is
+	// ThresholdStarted or ThresholdMustSignal.
+	Statistics *DeploymentStatistics
+}
+
+// DeploymentInfo returns the state of the given deployment ID as of the block
+// with the given hash along with the signalling statistics of the confirmation
+// window the block is in.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) DeploymentInfo(hash *chainhash.Hash, deploymentID uint32) (*DeploymentInfo, error) {
+	if deploymentID >= uint32(len(b.chainParams.Deployments)) {
+		return nil, DeploymentError(deploymentID)
+	}
+
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return nil, fmt.Errorf("block %s is not known", hash)
+	}
+
+	deployment := &b.chainParams.Deployments[deploymentID]
+	switch {
+	case deployment.AlwaysActive:
+		return &DeploymentInfo{
+			Height:    node.height,
+			State:     ThresholdActive,
+			NextState: ThresholdActive,
+		}, nil
+
+	case deployment.NeverActive:
+		return &DeploymentInfo{
+			Height:    node.height,
+			State:     ThresholdFailed,
+			NextState: ThresholdFailed,
+		}, nil
+	}
+	checker := deploymentChecker{deployment: deployment, chain: b}
+	cache := &b.deploymentCaches[deploymentID]
+	state, err := b.thresholdState(node.parent, checker, cache)
+	if err != nil {
+		return nil, err
+	}
+	since, err := b.thresholdStateSince(node.parent, checker, cache)
+	if err != nil {
+		return nil, err
+	}
+	nextState, err := b.thresholdState(node, checker, cache)
+	if err != nil {
+		return nil, err
+	}
+	info := &DeploymentInfo{
+		Height:    node.height,
+		State:     state,
+		Since:     since,
+		NextState: nextState,
+	}
+	if state != ThresholdStarted && state != ThresholdMustSignal {
+		return info, nil
+	}
+
+	// Count the signalling blocks of the confirmation window the block is
+	// in up to and including the block.
+	stats := &DeploymentStatistics{
+		Period:    checker.MinerConfirmationWindow(),
+		Threshold: checker.RuleChangeActivationThreshold(),
+		Elapsed:   uint32(node.height)%checker.MinerConfirmationWindow() + 1,
+	}
+	countNode := node
+	for i := uint32(0); i < stats.Elapsed; i++ {
+		condition, err := checker.Condition(countNode)
+		if err != nil {
+			return nil, err
+		}
+		if condition {
+			stats.Count++
+		}
+		countNode = countNode.parent
+	}
+	stats.Possible = stats.Count+stats.Period-stats.Elapsed >= stats.Threshold
+	info.Statistics = stats
+
+	return info, nil
+}
+
 // IsDeploymentActive returns true if the target deploymentID is active, and
 // false otherwise.
 //
@@ -302,6 +537,13 @@ func (b *BlockChain) deploymentState(prevNode *blockNode, deploymentID uint32) (
 	}
 
 	deployment := &b.chainParams.Deployments[deploymentID]
+	switch {
+	case deployment.AlwaysActive:
+		return ThresholdActive, nil
+
+	case deployment.NeverActive:
+		return ThresholdFailed, nil
+	}
 	checker := deploymentChecker{deployment: deployment, chain: b}
 	cache := &b.deploymentCaches[deploymentID]
 
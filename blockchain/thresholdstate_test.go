@@ -5,9 +5,14 @@
 package blockchain
 
 import (
+	"math"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 )
 
 // TestThresholdStateStringer tests the stringized output for the
@@ -24,6 +29,7 @@ func TestThresholdStateStringer(t *testing.T) {
 		{ThresholdLockedIn, "ThresholdLockedIn"},
 		{ThresholdActive, "ThresholdActive"},
 		{ThresholdFailed, "ThresholdFailed"},
+		{ThresholdMustSignal, "ThresholdMustSignal"},
 		{0xff, "Unknown ThresholdState (255)"},
 	}
 
@@ -132,3 +138,385 @@ nextTest:
 		}
 	}
 }
+
+// TestThresholdStateTransitions ensures the threshold state of time-based and
+// height-based deployments moves through the expected states depending on the
+// signaling of the blocks in each confirmation window.
+func TestThresholdStateTransitions(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	params.MinerConfirmationWindow = 4
+	params.RuleChangeActivationThreshold = 3
+	genesisTime := params.GenesisBlock.Header.Timestamp
+
+	tests := []struct {
+		name       string
+		deployment chaincfg.ConsensusDeployment
+		signals    []bool           // whether the blocks of each window signal
+		want       []ThresholdState // state after each window
+	}{
+		{
+			name: "height-based lock in",
+			deployment: chaincfg.ConsensusDeployment{
+				StartHeight:   8,
+				TimeoutHeight: 24,
+			},
+			signals: []bool{false, false, true, false, false},
+			want: []ThresholdState{ThresholdDefined,
+				ThresholdStarted, ThresholdLockedIn,
+				ThresholdActive, ThresholdActive},
+		},
+		{
+			name: "height-based timeout",
+			deployment: chaincfg.ConsensusDeployment{
+				StartHeight:   8,
+				TimeoutHeight: 16,
+			},
+			signals: []bool{false, false, false, false, true},
+			want: []ThresholdState{ThresholdDefined,
+				ThresholdStarted, ThresholdStarted,
+				ThresholdFailed, ThresholdFailed},
+		},
+		{
+			name: "lock in on timeout",
+			deployment: chaincfg.ConsensusDeployment{
+				StartHeight:     8,
+				TimeoutHeight:   16,
+				LockInOnTimeout: true,
+			},
+			signals: []bool{false, false, false, true, false},
+			want: []ThresholdState{ThresholdDefined,
+				ThresholdStarted, ThresholdMustSignal,
+				ThresholdLockedIn, ThresholdActive},
+		},
+		{
+			name: "lock in before timeout",
+			deployment: chaincfg.ConsensusDeployment{
+				StartHeight:     8,
+				TimeoutHeight:   24,
+				LockInOnTimeout: true,
+			},
+			signals: []bool{false, false, true, false, false},
+			want: []ThresholdState{ThresholdDefined,
+				ThresholdStarted, ThresholdLockedIn,
+				ThresholdActive, ThresholdActive},
+		},
+		{
+			name: "height-based minimum activation height",
+			deployment: chaincfg.ConsensusDeployment{
+				StartHeight:         8,
+				TimeoutHeight:       24,
+				MinActivationHeight: 24,
+			},
+			signals: []bool{false, false, true, false, false, false},
+			want: []ThresholdState{ThresholdDefined,
+				ThresholdStarted, ThresholdLockedIn,
+				ThresholdLockedIn, ThresholdLockedIn,
+				ThresholdActive},
+		},
+		{
+			name: "time-based minimum activation height",
+			deployment: chaincfg.ConsensusDeployment{
+				StartTime:           0,
+				ExpireTime:          math.MaxUint64,
+				MinActivationHeight: 16,
+			},
+			signals: []bool{false, true, false, false},
+			want: []ThresholdState{ThresholdStarted,
+				ThresholdLockedIn, ThresholdLockedIn,
+				ThresholdActive},
+		},
+		{
+			name: "time-based expiry",
+			deployment: chaincfg.ConsensusDeployment{
+				StartTime:  0,
+				ExpireTime: uint64(genesisTime.Unix()),
+			},
+			signals: []bool{true, true},
+			want:    []ThresholdState{ThresholdFailed, ThresholdFailed},
+		},
+	}
+
+	for _, test := range tests {
+		chain := newFakeChain(&params)
+		checker := deploymentChecker{deployment: &test.deployment,
+			chain: chain}
+		cache := &newThresholdCaches(1)[0]
+
+		node := chain.bestChain.Tip()
+		window := int32(params.MinerConfirmationWindow)
+		for i, signal := range test.signals {
+			version := int32(vbTopBits)
+			if signal {
+				version |= 1 << test.deployment.BitNumber
+			}
+			for node.height < window*int32(i+1)-1 {
+				timestamp := genesisTime.Add(time.Duration(
+					node.height+1) * time.Minute)
+				node = newFakeNode(node, version, 0, timestamp)
+			}
+
+			state, err := chain.thresholdState(node, checker, cache)
+			if err != nil {
+				t.Fatalf("%s: unexpected error after window %d: "+
+					"%v", test.name, i, err)
+			}
+			if state != test.want[i] {
+				t.Fatalf("%s: unexpected state after window %d: "+
+					"got %v, want %v", test.name, i, state,
+					test.want[i])
+			}
+		}
+	}
+}
+
+// TestDeploymentSignals ensures blocks are required to signal for a deployment
+// which locks in on timeout during the last window before its timeout and that
+// the expected block version signals for it.
+func TestDeploymentSignals(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	params.MinerConfirmationWindow = 4
+	params.RuleChangeActivationThreshold = 3
+	params.Deployments = [chaincfg.DefinedDeployments]chaincfg.ConsensusDeployment{}
+	params.Deployments[chaincfg.DeploymentTestDummy] = chaincfg.ConsensusDeployment{
+		BitNumber:       5,
+		StartHeight:     8,
+		TimeoutHeight:   16,
+		LockInOnTimeout: true,
+	}
+	chain := newFakeChain(&params)
+	genesisTime := params.GenesisBlock.Header.Timestamp
+
+	const signalVersion = vbTopBits | 1<<5
+	var nodes []*blockNode
+	node := chain.bestChain.Tip()
+	for height := int32(1); height < 16; height++ {
+		timestamp := genesisTime.Add(time.Duration(height) * time.Minute)
+		node = newFakeNode(node, vbTopBits, 0, timestamp)
+		nodes = append(nodes, node)
+	}
+
+	tests := []struct {
+		name        string
+		prevHeight  int32
+		version     int32
+		wantVersion int32
+		wantErr     bool
+	}{
+		{"started without signal", 7, vbTopBits, signalVersion, false},
+		{"must signal with signal", 11, signalVersion, signalVersion, false},
+		{"must signal without signal", 11, vbTopBits, signalVersion, true},
+		{"must signal without top bits", 11, 1 << 5, signalVersion, true},
+		{"locked in without signal", 15, vbTopBits, signalVersion, false},
+	}
+
+	for _, test := range tests {
+		prevNode := nodes[test.prevHeight-1]
+		version, err := chain.calcNextBlockVersion(prevNode)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if version != test.wantVersion {
+			t.Fatalf("%s: unexpected version: got %x, want %x",
+				test.name, version, test.wantVersion)
+		}
+
+		header := &wire.BlockHeader{Version: test.version}
+		err = chain.checkDeploymentSignals(header, prevNode)
+		if !test.wantErr {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", test.name,
+					err)
+			}
+			continue
+		}
+		rerr, ok := err.(RuleError)
+		if !ok || rerr.ErrorCode != ErrMissingDeploymentSignal {
+			t.Fatalf("%s: unexpected error: got %v, want %v",
+				test.name, err, ErrMissingDeploymentSignal)
+		}
+	}
+}
+
+// TestDeploymentInfo ensures the state of a deployment as of a block is
+// reported along with the signalling statistics of its confirmation window.
+func TestDeploymentInfo(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	params.MinerConfirmationWindow = 4
+	params.RuleChangeActivationThreshold = 3
+	params.Deployments = [chaincfg.DefinedDeployments]chaincfg.ConsensusDeployment{}
+	params.Deployments[chaincfg.DeploymentTestDummy] = chaincfg.ConsensusDeployment{
+		BitNumber:     5,
+		StartHeight:   8,
+		TimeoutHeight: 24,
+	}
+	chain := newFakeChain(&params)
+	genesisTime := params.GenesisBlock.Header.Timestamp
+
+	// Only the first two blocks of the first window the deployment is
+	// started in signal for it, followed by a window in which all blocks
+	// signal.
+	nodes := []*blockNode{chain.bestChain.Tip()}
+	for height := int32(1); height < 16; height++ {
+		version := int32(vbTopBits)
+		if height == 8 || height == 9 || height >= 12 {
+			version |= 1 << 5
+		}
+		timestamp := genesisTime.Add(time.Duration(height) * time.Minute)
+		node := newFakeNode(nodes[height-1], version, 0, timestamp)
+		chain.index.AddNode(node)
+		nodes = append(nodes, node)
+	}
+
+	tests := []struct {
+		height int32
+		want   DeploymentInfo
+	}{{
+		height: 5,
+		want: DeploymentInfo{
+			Height:    5,
+			State:     ThresholdDefined,
+			Since:     0,
+			NextState: ThresholdDefined,
+		},
+	}, {
+		height: 9,
+		want: DeploymentInfo{
+			Height:    9,
+			State:     ThresholdStarted,
+			Since:     8,
+			NextState: ThresholdStarted,
+			Statistics: &DeploymentStatistics{Period: 4,
+				Threshold: 3, Elapsed: 2, Count: 2,
+				Possible: true},
+		},
+	}, {
+		height: 11,
+		want: DeploymentInfo{
+			Height:    11,
+			State:     ThresholdStarted,
+			Since:     8,
+			NextState: ThresholdStarted,
+			Statistics: &DeploymentStatistics{Period: 4,
+				Threshold: 3, Elapsed: 4, Count: 2,
+				Possible: false},
+		},
+	}, {
+		height: 15,
+		want: DeploymentInfo{
+			Height:    15,
+			State:     ThresholdStarted,
+			Since:     8,
+			NextState: ThresholdLockedIn,
+			Statistics: &DeploymentStatistics{Period: 4,
+				Threshold: 3, Elapsed: 4, Count: 4,
+				Possible: true},
+		},
+	}}
+
+	for _, test := range tests {
+		info, err := chain.DeploymentInfo(&nodes[test.height].hash,
+			chaincfg.DeploymentTestDummy)
+		if err != nil {
+			t.Fatalf("DeploymentInfo %d: unexpected error: %v",
+				test.height, err)
+		}
+		if !reflect.DeepEqual(info, &test.want) {
+			t.Fatalf("DeploymentInfo %d: got %+v (statistics %+v), "+
+				"want %+v (statistics %+v)", test.height, info,
+				info.Statistics, test.want, test.want.Statistics)
+		}
+	}
+
+	if _, err := chain.DeploymentInfo(&chainhash.Hash{}, 0); err == nil {
+		t.Fatal("DeploymentInfo: unknown block accepted")
+	}
+	_, err := chain.DeploymentInfo(&nodes[0].hash, chaincfg.DefinedDeployments)
+	if _, ok := err.(DeploymentError); !ok {
+		t.Fatalf("DeploymentInfo: unexpected error for unknown "+
+			"deployment: %v", err)
+	}
+}
+
+// TestAlwaysActiveDeployment ensures deployments which are always active are
+// active from the genesis block on without any signalling and aren't signalled
+// for by new blocks.
+func TestAlwaysActiveDeployment(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	if !params.Deployments[chaincfg.DeploymentTaproot].AlwaysActive {
+		t.Fatal("taproot is not always active on the regression test " +
+			"network")
+	}
+	chain := newFakeChain(&params)
+
+	state, err := chain.deploymentState(chain.bestChain.Tip(),
+		chaincfg.DeploymentTaproot)
+	if err != nil {
+		t.Fatalf("deploymentState: unexpected error: %v", err)
+	}
+	if state != ThresholdActive {
+		t.Fatalf("deploymentState: got %v, want %v", state,
+			ThresholdActive)
+	}
+
+	info, err := chain.DeploymentInfo(params.GenesisHash,
+		chaincfg.DeploymentTaproot)
+	if err != nil {
+		t.Fatalf("DeploymentInfo: unexpected error: %v", err)
+	}
+	if info.State != ThresholdActive || info.NextState != ThresholdActive ||
+		info.Statistics != nil {
+
+		t.Fatalf("DeploymentInfo: unexpected info %+v", info)
+	}
+
+	version, err := chain.calcNextBlockVersion(chain.bestChain.Tip())
+	if err != nil {
+		t.Fatalf("calcNextBlockVersion: unexpected error: %v", err)
+	}
+	bit := params.Deployments[chaincfg.DeploymentTaproot].BitNumber
+	if uint32(version)&(1<<bit) != 0 {
+		t.Fatalf("calcNextBlockVersion: version %#08x signals for an "+
+			"always active deployment", version)
+	}
+}
+
+// TestNeverActiveDeployment ensures deployments which are never active are
+// failed from the genesis block on without any signalling.
+func TestNeverActiveDeployment(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.MainNetParams
+	if !params.Deployments[chaincfg.DeploymentTaproot].NeverActive {
+		t.Fatal("taproot is not never active on the main network")
+	}
+	chain := newFakeChain(&params)
+
+	state, err := chain.deploymentState(chain.bestChain.Tip(),
+		chaincfg.DeploymentTaproot)
+	if err != nil {
+		t.Fatalf("deploymentState: unexpected error: %v", err)
+	}
+	if state != ThresholdFailed {
+		t.Fatalf("deploymentState: got %v, want %v", state,
+			ThresholdFailed)
+	}
+
+	info, err := chain.DeploymentInfo(params.GenesisHash,
+		chaincfg.DeploymentTaproot)
+	if err != nil {
+		t.Fatalf("DeploymentInfo: unexpected error: %v", err)
+	}
+	if info.State != ThresholdFailed || info.NextState != ThresholdFailed ||
+		info.Statistics != nil {
+
+		t.Fatalf("DeploymentInfo: unexpected info %+v", info)
+	}
+}
@@ -0,0 +1,432 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	// DefaultUtxoCacheFlushInterval is the default maximum amount of time
+	// modified utxos are kept in the cache before they are written to the
+	// database while blocks are being connected.
+	DefaultUtxoCacheFlushInterval = time.Minute * 5
+
+	// cachedEntryOverhead is an estimate of the number of bytes used by
+	// each entry in the utxo cache in addition to its public key script.
+	// It accounts for the outpoint key and entry pointer stored in the
+	// map, the bookkeeping of the map itself, and the UtxoEntry struct.
+	cachedEntryOverhead = 128
+)
+
+// FlushMode identifies the conditions under which the utxo cache is written to
+// the database.
+type FlushMode uint8
+
+const (
+	// FlushRequired writes the utxo cache to the database unconditionally.
+	FlushRequired FlushMode = iota
+
+	// FlushPeriodic writes the utxo cache to the database when it exceeds
+	// its maximum size or when the flush interval has elapsed since it was
+	// last written.
+	FlushPeriodic
+
+	// FlushIfNeeded writes the utxo cache to the database only when it
+	// exceeds its maximum size.
+	FlushIfNeeded
+)
+
+// utxoCache is a write-back cache which sits between utxo viewpoints and the
+// utxo set stored in the database.  Entries are loaded from the database on
+// demand and the changes made by connected blocks are applied to the cache
+// only.  They are written to the database in a single transaction when the
+// cache is flushed along with the hash of the block the utxo set in the
+// database then represents, which makes it possible to detect and recover
+// from an unclean shutdown between flushes.
+//
+// Cached entries which have been marked fresh do not exist in the database, so
+// spending them only requires removing them from the cache.  Spent entries
+// which are not fresh are kept until the next flush so they can be removed
+// from the database.
+type utxoCache struct {
+	db            database.DB
+	maxSize       uint64
+	flushInterval time.Duration
+
+	// utxoBucketName and stateKeyName are the names of the database bucket
+	// which houses the utxo set behind the cache and the key which records
+	// the hash of the block it represents.
+	utxoBucketName []byte
+	stateKeyName   []byte
+
+	// mtx protects the fields below.  The cache is modified when entries
+	// are loaded from the database, which happens with the chain lock
+	// only held for reads.
+	mtx           sync.Mutex
+	entries       map[wire.OutPoint]*UtxoEntry
+	totalSize     uint64
+	lastFlushHash chainhash.Hash
+	lastFlushTime time.Time
+}
+
+// newUtxoCache returns a new utxo cache backed by the passed database.  A
+// maximum size of zero causes the cache to be flushed after every block, which
+// matches writing every change directly to the database.
+func newUtxoCache(db database.DB, maxSize uint64, flushInterval time.Duration) *utxoCache {
+	if flushInterval <= 0 {
+		flushInterval = DefaultUtxoCacheFlushInterval
+	}
+	return &utxoCache{
+		db:             db,
+		maxSize:        maxSize,
+		flushInterval:  flushInterval,
+		utxoBucketName: utxoSetBucketName,
+		stateKeyName:   utxoStateConsistencyKeyName,
+		entries:        make(map[wire.OutPoint]*UtxoEntry),
+		lastFlushTime:  time.Now(),
+	}
+}
+
+// entrySize returns the estimated number of bytes used by the passed cached
+// entry.
+func entrySize(entry *UtxoEntry) uint64 {
+	return cachedEntryOverhead + uint64(cap(entry.pkScript))
+}
+
+// putEntry adds or replaces the cached entry for the passed outpoint while
+// keeping track of the total size of the cache.
+//
+// This function MUST be called with the cache lock held.
+func (c *utxoCache) putEntry(outpoint wire.OutPoint, entry *UtxoEntry) {
+	if old, ok := c.entries[outpoint]; ok {
+		c.totalSize -= entrySize(old)
+	}
+	c.entries[outpoint] = entry
+	c.totalSize += entrySize(entry)
+}
+
+// removeEntry removes the cached entry for the passed outpoint, if any, while
+// keeping track of the total size of the cache.
+//
+// This function MUST be called with the cache lock held.
+func (c *utxoCache) removeEntry(outpoint wire.OutPoint) {
+	if old, ok := c.entries[outpoint]; ok {
+		c.totalSize -= entrySize(old)
+		delete(c.entries, outpoint)
+	}
+}
+
+// fetchEntries returns the unspent entries for the passed outpoints from the
+// cache, loading those which are not cached from the database.  Outpoints
+// which are spent or otherwise do not exist result in nil entries.
+//
+// The returned entries are owned by the cache and MUST NOT be modified.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) fetchEntries(outpoints map[wire.OutPoint]struct{}) (map[wire.OutPoint]*UtxoEntry, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	entries := make(map[wire.OutPoint]*UtxoEntry, len(outpoints))
+	var missing []wire.OutPoint
+	for outpoint := range outpoints {
+		entry, ok := c.entries[outpoint]
+		if !ok {
+			missing = append(missing, outpoint)
+			continue
+		}
+
+		// Spent entries are only kept until they are removed from the
+		// database by the next flush.
+		if entry.IsSpent() {
+			entry = nil
+		}
+		entries[outpoint] = entry
+	}
+	if len(missing) == 0 {
+		return entries, nil
+	}
+
+	// Load the remaining entries from the database.  They are added to the
+	// cache unless it is already full since only connecting blocks can
+	// cause it to be flushed.
+	err := c.db.View(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(c.utxoBucketName)
+		for _, outpoint := range missing {
+			entry, err := dbFetchBucketUtxoEntry(utxoBucket, outpoint)
+			if err != nil {
+				return err
+			}
+			entries[outpoint] = entry
+
+			if entry != nil && c.totalSize < c.maxSize {
+				c.putEntry(outpoint, entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// fetchEntry returns the unspent entry for the passed outpoint.  See
+// fetchEntries for details.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) fetchEntry(outpoint wire.OutPoint) (*UtxoEntry, error) {
+	entries, err := c.fetchEntries(map[wire.OutPoint]struct{}{
+		outpoint: {},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries[outpoint], nil
+}
+
+// commit applies all of the entries in the passed view which are marked
+// modified to the cache.  The view itself is left untouched.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) commit(view *UtxoViewpoint) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for outpoint, entry := range view.entries {
+		if entry == nil || !entry.isModified() {
+			continue
+		}
+
+		cached := c.entries[outpoint]
+		if entry.IsSpent() {
+			// Fresh entries never made it to the database, so they
+			// can simply be forgotten.  Otherwise a spent marker is
+			// kept so the entry is removed from the database on the
+			// next flush.
+			if cached != nil && cached.isFresh() {
+				c.removeEntry(outpoint)
+				continue
+			}
+			c.putEntry(outpoint, &UtxoEntry{
+				amount:      entry.amount,
+				blockHeight: entry.blockHeight,
+				packedFlags: tfSpent | tfModified,
+			})
+			continue
+		}
+
+		// Copy the public key script so the cached entry does not keep
+		// the rest of the transaction it came from in memory, which
+		// would also throw off the size accounting.
+		pkScript := make([]byte, len(entry.pkScript))
+		copy(pkScript, entry.pkScript)
+		newEntry := &UtxoEntry{
+			amount:      entry.amount,
+			pkScript:    pkScript,
+			blockHeight: entry.blockHeight,
+			packedFlags: entry.packedFlags&tfCoinBase | tfModified,
+		}
+
+		// An output which is not cached can only exist in the database
+		// if it was loaded without being cached, which never happens
+		// for outputs that are being created, or when it is a duplicate
+		// of an earlier unspent coinbase output.  Mark all other new
+		// outputs fresh.
+		switch {
+		case cached != nil && cached.isFresh():
+			newEntry.packedFlags |= tfFresh
+		case cached == nil && !newEntry.IsCoinBase():
+			newEntry.packedFlags |= tfFresh
+		}
+		c.putEntry(outpoint, newEntry)
+	}
+}
+
+// shouldFlush returns whether the cache needs to be written to the database
+// according to the passed flush mode.
+//
+// This function MUST be called with the cache lock held.
+func (c *utxoCache) shouldFlush(mode FlushMode) bool {
+	switch mode {
+	case FlushRequired:
+		return true
+	case FlushPeriodic:
+		return c.totalSize > c.maxSize ||
+			time.Since(c.lastFlushTime) >= c.flushInterval
+	case FlushIfNeeded:
+		return c.totalSize > c.maxSize
+	}
+	return false
+}
+
+// flush writes all modified entries in the cache to the database according to
+// the passed flush mode and records that the utxo set in the database
+// represents the state as of the passed block hash.  All entries are removed
+// from the cache once they have been written.
+//
+// The passed hash MUST be the hash of the block the cache contents currently
+// represent.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) flush(mode FlushMode, bestHash *chainhash.Hash) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if !c.shouldFlush(mode) {
+		return nil
+	}
+
+	// Avoid a needless database update when nothing changed.
+	if len(c.entries) == 0 && c.lastFlushHash == *bestHash {
+		c.lastFlushTime = time.Now()
+		return nil
+	}
+
+	err := c.db.Update(func(dbTx database.Tx) error {
+		return c.dbPutEntries(dbTx, bestHash)
+	})
+	if err != nil {
+		return err
+	}
+
+	c.flushed(bestHash)
+	return nil
+}
+
+// dbPutEntries writes all modified entries in the cache to the database using
+// the passed database transaction and records that the utxo set in the
+// database represents the state as of the passed block hash.  The cache is not
+// cleared, so callers MUST call flushed once the transaction commits.
+//
+// This function MUST be called with the cache lock held.
+func (c *utxoCache) dbPutEntries(dbTx database.Tx, bestHash *chainhash.Hash) error {
+	utxoBucket := dbTx.Metadata().Bucket(c.utxoBucketName)
+	if err := dbPutBucketUtxoEntries(utxoBucket, c.entries); err != nil {
+		return err
+	}
+	return dbTx.Metadata().Put(c.stateKeyName, bestHash[:])
+}
+
+// flushed removes all entries from the cache after they have been written to
+// the database as of the passed block hash by dbPutEntries.
+//
+// This function MUST be called with the cache lock held.
+func (c *utxoCache) flushed(bestHash *chainhash.Hash) {
+	log.Debugf("Flushed %d utxo cache entries (%.2f MiB) to the database "+
+		"at block %v", len(c.entries), float64(c.totalSize)/(1024*1024),
+		bestHash)
+
+	c.entries = make(map[wire.OutPoint]*UtxoEntry)
+	c.totalSize = 0
+	c.lastFlushHash = *bestHash
+	c.lastFlushTime = time.Now()
+}
+
+// markFlushed records that the utxo set in the database was brought to the
+// state as of the passed block hash outside of the cache.  It must only be
+// called when the cache is empty.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) markFlushed(bestHash *chainhash.Hash) {
+	c.mtx.Lock()
+	c.lastFlushHash = *bestHash
+	c.lastFlushTime = time.Now()
+	c.mtx.Unlock()
+}
+
+// initUtxoCacheState ensures the utxo set in the database is consistent with
+// the best chain.  When the node was not shut down cleanly, the utxo set in
+// the database reflects the state as of the last flush of the cache, so the
+// blocks connected since then are reapplied to it.  It returns whether this was
+// the case.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) initUtxoCacheState(interrupt <-chan struct{}) (bool, error) {
+	var stateHash *chainhash.Hash
+	err := b.db.View(func(dbTx database.Tx) error {
+		stateHash = dbFetchUtxoStateConsistency(dbTx)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// Databases which do not record the state of the utxo set were always
+	// updated along with the best chain, so they are consistent with it.
+	tip := b.bestChain.Tip()
+	if stateHash == nil {
+		return false, b.utxoCache.flush(FlushRequired, &tip.hash)
+	}
+	b.utxoCache.markFlushed(stateHash)
+	if *stateHash == tip.hash {
+		return false, nil
+	}
+
+	// The utxo set can only ever lag behind the best chain since blocks
+	// are only disconnected after flushing the cache.
+	stateNode := b.index.LookupNode(stateHash)
+	if stateNode == nil || !b.bestChain.Contains(stateNode) {
+		return false, AssertError(fmt.Sprintf("utxo set state %v is not in "+
+			"the main chain", stateHash))
+	}
+
+	log.Infof("Reapplying blocks %d to %d to the utxo set after an "+
+		"unclean shutdown", stateNode.height+1, tip.height)
+	for height := stateNode.height + 1; height <= tip.height; height++ {
+		if interruptRequested(interrupt) {
+			return false, errInterruptRequested
+		}
+
+		node := b.bestChain.NodeByHeight(height)
+		var block *btcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByNode(dbTx, node)
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+
+		view := NewUtxoViewpoint()
+		err = view.fetchInputUtxos(b.utxoCache, block)
+		if err != nil {
+			return false, err
+		}
+		err = view.connectTransactions(block, nil)
+		if err != nil {
+			return false, err
+		}
+		b.utxoCache.commit(view)
+
+		err = b.utxoCache.flush(FlushIfNeeded, &node.hash)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, b.utxoCache.flush(FlushRequired, &tip.hash)
+}
+
+// FlushUtxoCache writes the utxo cache to the database according to the passed
+// flush mode.  Callers should flush with FlushRequired before shutting down to
+// avoid having to reapply blocks to the utxo set on the next start.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) FlushUtxoCache(mode FlushMode) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	return b.utxoCache.flush(mode, &b.bestChain.Tip().hash)
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// testOutPoint returns a distinct outpoint for the passed index.
+func testOutPoint(i byte) wire.OutPoint {
+	return wire.OutPoint{Hash: chainhash.Hash{i}, Index: uint32(i)}
+}
+
+// testUtxoEntry returns a new unspent entry which is marked modified.
+func testUtxoEntry(amount int64, isCoinBase bool) *UtxoEntry {
+	entry := &UtxoEntry{
+		amount:      amount,
+		pkScript:    []byte{0x51},
+		blockHeight: 1,
+		packedFlags: tfModified,
+	}
+	if isCoinBase {
+		entry.packedFlags |= tfCoinBase
+	}
+	return entry
+}
+
+// dbUtxoEntry returns the entry for the passed outpoint stored in the database
+// of the passed cache.
+func dbUtxoEntry(t *testing.T, c *utxoCache, outpoint wire.OutPoint) *UtxoEntry {
+	var entry *UtxoEntry
+	err := c.db.View(func(dbTx database.Tx) error {
+		var err error
+		entry, err = dbFetchUtxoEntry(dbTx, outpoint)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("dbFetchUtxoEntry: unexpected error: %v", err)
+	}
+	return entry
+}
+
+// TestUtxoCache ensures the utxo cache tracks fresh and spent entries and only
+// writes changes to the database when it is flushed.
+func TestUtxoCache(t *testing.T) {
+	chain, teardownFunc, err := chainSetup("utxocache",
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	c := newUtxoCache(chain.db, 1<<20, time.Hour)
+	dbOut, freshOut, coinbaseOut := testOutPoint(1), testOutPoint(2),
+		testOutPoint(3)
+
+	// Store an entry directly in the database and load it into the cache.
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbPutUtxoEntries(dbTx, map[wire.OutPoint]*UtxoEntry{
+			dbOut: testUtxoEntry(1000, false),
+		})
+	})
+	if err != nil {
+		t.Fatalf("dbPutUtxoEntries: unexpected error: %v", err)
+	}
+	entry, err := c.fetchEntry(dbOut)
+	if err != nil {
+		t.Fatalf("fetchEntry: unexpected error: %v", err)
+	}
+	if entry == nil || entry.Amount() != 1000 || entry.isFresh() {
+		t.Fatalf("fetchEntry: unexpected entry %+v", entry)
+	}
+	if _, ok := c.entries[dbOut]; !ok {
+		t.Fatal("fetchEntry: entry loaded from the database not cached")
+	}
+
+	// Spend the database entry and create two new outputs.  Only the
+	// output that is not from a coinbase can be known not to exist in the
+	// database.
+	view := NewUtxoViewpoint()
+	spent := testUtxoEntry(1000, false)
+	spent.Spend()
+	view.entries[dbOut] = spent
+	view.entries[freshOut] = testUtxoEntry(2000, false)
+	view.entries[coinbaseOut] = testUtxoEntry(3000, true)
+	c.commit(view)
+
+	if entry, err := c.fetchEntry(dbOut); err != nil || entry != nil {
+		t.Fatalf("fetchEntry: spent entry returned - got %+v, %v",
+			entry, err)
+	}
+	if !c.entries[freshOut].isFresh() {
+		t.Fatal("commit: new output not marked fresh")
+	}
+	if c.entries[coinbaseOut].isFresh() {
+		t.Fatal("commit: new coinbase output marked fresh")
+	}
+	if dbUtxoEntry(t, c, dbOut) == nil {
+		t.Fatal("commit: spent entry removed from database before flush")
+	}
+
+	// Spending the fresh output must forget it entirely.
+	view = NewUtxoViewpoint()
+	spent = testUtxoEntry(2000, false)
+	spent.Spend()
+	view.entries[freshOut] = spent
+	c.commit(view)
+	if _, ok := c.entries[freshOut]; ok {
+		t.Fatal("commit: spent fresh entry still cached")
+	}
+
+	// The cache is well below its maximum size and was flushed recently,
+	// so only a required flush writes it.
+	bestHash := chainhash.Hash{0xaa}
+	for _, mode := range []FlushMode{FlushIfNeeded, FlushPeriodic} {
+		if err := c.flush(mode, &bestHash); err != nil {
+			t.Fatalf("flush(%d): unexpected error: %v", mode, err)
+		}
+		if len(c.entries) == 0 {
+			t.Fatalf("flush(%d): cache unexpectedly flushed", mode)
+		}
+	}
+	if err := c.flush(FlushRequired, &bestHash); err != nil {
+		t.Fatalf("flush: unexpected error: %v", err)
+	}
+	if len(c.entries) != 0 || c.totalSize != 0 {
+		t.Fatalf("flush: cache not emptied - %d entries, %d bytes",
+			len(c.entries), c.totalSize)
+	}
+
+	if dbUtxoEntry(t, c, dbOut) != nil {
+		t.Fatal("flush: spent entry not removed from database")
+	}
+	if dbUtxoEntry(t, c, freshOut) != nil {
+		t.Fatal("flush: spent fresh entry written to database")
+	}
+	if entry := dbUtxoEntry(t, c, coinbaseOut); entry == nil ||
+		entry.Amount() != 3000 || !entry.IsCoinBase() {
+
+		t.Fatalf("flush: unexpected database entry %+v", entry)
+	}
+
+	var stateHash *chainhash.Hash
+	err = chain.db.View(func(dbTx database.Tx) error {
+		stateHash = dbFetchUtxoStateConsistency(dbTx)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: unexpected error: %v", err)
+	}
+	if stateHash == nil || *stateHash != bestHash {
+		t.Fatalf("flush: unexpected utxo state hash - got %v, want %v",
+			stateHash, bestHash)
+	}
+
+	// Periodic flushes happen once the flush interval has elapsed.
+	view = NewUtxoViewpoint()
+	view.entries[freshOut] = testUtxoEntry(2000, false)
+	c.commit(view)
+	c.lastFlushTime = time.Now().Add(-2 * time.Hour)
+	if err := c.flush(FlushPeriodic, &bestHash); err != nil {
+		t.Fatalf("flush: unexpected error: %v", err)
+	}
+	if len(c.entries) != 0 || dbUtxoEntry(t, c, freshOut) == nil {
+		t.Fatal("flush: periodic flush did not write the cache")
+	}
+
+	// A cache without a maximum size is flushed whenever it has entries.
+	c.maxSize = 0
+	view = NewUtxoViewpoint()
+	view.entries[dbOut] = testUtxoEntry(1000, false)
+	c.commit(view)
+	if err := c.flush(FlushIfNeeded, &bestHash); err != nil {
+		t.Fatalf("flush: unexpected error: %v", err)
+	}
+	if len(c.entries) != 0 || dbUtxoEntry(t, c, dbOut) == nil {
+		t.Fatal("flush: cache without a maximum size not written")
+	}
+}
+
+// TestUtxoCacheView ensures views receive their own copies of cached entries.
+func TestUtxoCacheView(t *testing.T) {
+	chain, teardownFunc, err := chainSetup("utxocacheview",
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	outpoint := testOutPoint(1)
+	view := NewUtxoViewpoint()
+	view.entries[outpoint] = testUtxoEntry(1000, false)
+	chain.utxoCache.commit(view)
+
+	view = NewUtxoViewpoint()
+	err = view.fetchUtxosMain(chain.utxoCache,
+		map[wire.OutPoint]struct{}{outpoint: {}})
+	if err != nil {
+		t.Fatalf("fetchUtxosMain: unexpected error: %v", err)
+	}
+	entry := view.LookupEntry(outpoint)
+	if entry == nil || entry.isModified() || entry.isFresh() {
+		t.Fatalf("fetchUtxosMain: unexpected entry %+v", entry)
+	}
+	entry.Spend()
+	if chain.utxoCache.entries[outpoint].IsSpent() {
+		t.Fatal("fetchUtxosMain: view shares entries with the cache")
+	}
+
+	fetched, err := chain.FetchUtxoEntry(outpoint)
+	if err != nil {
+		t.Fatalf("FetchUtxoEntry: unexpected error: %v", err)
+	}
+	if fetched == nil || fetched.IsSpent() ||
+		!bytes.Equal(fetched.PkScript(), []byte{0x51}) {
+
+		t.Fatalf("FetchUtxoEntry: unexpected entry %+v", fetched)
+	}
+}
+
+// TestInitUtxoCacheState ensures the recorded state of the utxo set is checked
+// against the best chain when the chain is loaded.
+func TestInitUtxoCacheState(t *testing.T) {
+	chain, teardownFunc, err := chainSetup("initutxocachestate",
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// The utxo set of a new database represents the genesis block.
+	tip := chain.bestChain.Tip()
+	var stateHash *chainhash.Hash
+	err = chain.db.View(func(dbTx database.Tx) error {
+		stateHash = dbFetchUtxoStateConsistency(dbTx)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: unexpected error: %v", err)
+	}
+	if stateHash == nil || *stateHash != tip.hash {
+		t.Fatalf("New: unexpected utxo state hash - got %v, want %v",
+			stateHash, tip.hash)
+	}
+
+	// A state which is not part of the main chain can't be recovered from.
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbPutUtxoStateConsistency(dbTx, &chainhash.Hash{0x01})
+	})
+	if err != nil {
+		t.Fatalf("Update: unexpected error: %v", err)
+	}
+	_, err = chain.initUtxoCacheState(nil)
+	if _, ok := err.(AssertError); !ok {
+		t.Fatalf("initUtxoCacheState: unexpected error - got %v, want "+
+			"AssertError", err)
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/muhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// utxoStatsBogoOverhead is the size added to the public key script of
+	// every unspent output for the database independent size of the utxo
+	// set.  It accounts for the transaction hash, output index, height and
+	// coinbase flag, amount and script length.
+	utxoStatsBogoOverhead = 32 + 4 + 4 + 8 + 2
+
+	// serializedUtxoStatsSize is the size of serialized utxo set statistics.
+	serializedUtxoStatsSize = chainhash.HashSize + 8*4 +
+		muhash.SerializedSize
+)
+
+var (
+	// utxoSetStatsKeyName is the name of the db key used to store the
+	// statistics of the utxo set along with the hash of the block they
+	// are for.
+	utxoSetStatsKeyName = []byte("utxosetstats")
+)
+
+// UtxoSetStats houses statistics about the unspent transaction output set as of
+// the end of the main chain.
+type UtxoSetStats struct {
+	// Hash and Height identify the block the statistics are for.
+	Hash   chainhash.Hash
+	Height int32
+
+	// NumUtxos is the number of unspent transaction outputs.
+	NumUtxos uint64
+
+	// TotalAmount is the sum of the amounts of all unspent outputs.
+	TotalAmount btcutil.Amount
+
+	// BogoSize is a database independent measure of the size of the utxo
+	// set.  Every output counts for the size of its public key script plus
+	// a fixed overhead, which matches the bogosize of Bitcoin Core.
+	BogoSize uint64
+
+	// SerializedSize is the total size of the unspent outputs serialized
+	// in the database format, including their keys.
+	SerializedSize uint64
+
+	// MuHash is the MuHash3072 of the utxo set as calculated by Bitcoin
+	// Core.
+	MuHash chainhash.Hash
+}
+
+// utxoStats tracks statistics about the utxo set which are updated with every
+// output that is added to it or removed from it.
+type utxoStats struct {
+	numUtxos       uint64
+	totalAmount    uint64
+	bogoSize       uint64
+	serializedSize uint64
+	muHash         *muhash.MuHash
+}
+
+// newUtxoStats returns statistics for the empty utxo set.
+func newUtxoStats() *utxoStats {
+	return &utxoStats{muHash: muhash.New()}
+}
+
+// copy returns a deep copy of the statistics.
+func (s *utxoStats) copy() *utxoStats {
+	c := *s
+	c.muHash = s.muHash.Copy()
+	return &c
+}
+
+// muHashElement returns the serialization of an unspent output which is added
+// to the MuHash of the utxo set.  It matches the serialization used by Bitcoin
+// Core, that is the outpoint, the height shifted over one bit with the coinbase
+// flag in the lowest bit, and the output.
+func muHashElement(outpoint wire.OutPoint, amount int64, pkScript []byte,
+	height int32, isCoinBase bool) []byte {
+
+	code := uint32(height) << 1
+	if isCoinBase {
+		code |= 0x01
+	}
+
+	size := chainhash.HashSize + 4 + 4 + 8 +
+		wire.VarIntSerializeSize(uint64(len(pkScript))) + len(pkScript)
+	var buf bytes.Buffer
+	buf.Grow(size)
+	buf.Write(outpoint.Hash[:])
+	var scratch [8]byte
+	binary.LittleEndian.PutUint32(scratch[:], outpoint.Index)
+	buf.Write(scratch[:4])
+	binary.LittleEndian.PutUint32(scratch[:], code)
+	buf.Write(scratch[:4])
+	binary.LittleEndian.PutUint64(scratch[:], uint64(amount))
+	buf.Write(scratch[:])
+	// Writing to a bytes.Buffer never fails.
+	_ = wire.WriteVarBytes(&buf, 0, pkScript)
+	return buf.Bytes()
+}
+
+// utxoSerializedSize returns the size of the passed unspent output serialized
+// in the database format, including its key.
+func utxoSerializedSize(outpoint wire.OutPoint, amount int64, pkScript []byte,
+	height int32) int {
+
+	// The coinbase flag does not influence the size of the header code.
+	headerCode := uint64(height) << 1
+	return chainhash.HashSize + serializeSizeVLQ(uint64(outpoint.Index)) +
+		serializeSizeVLQ(headerCode) +
+		compressedTxOutSize(uint64(amount), pkScript)
+}
+
+// add adds the passed unspent output to the statistics.
+func (s *utxoStats) add(outpoint wire.OutPoint, amount int64, pkScript []byte,
+	height int32, isCoinBase bool) {
+
+	s.numUtxos++
+	s.totalAmount += uint64(amount)
+	s.bogoSize += uint64(utxoStatsBogoOverhead + len(pkScript))
+	s.serializedSize += uint64(utxoSerializedSize(outpoint, amount,
+		pkScript, height))
+	s.muHash.Add(muHashElement(outpoint, amount, pkScript, height,
+		isCoinBase))
+}
+
+// remove removes the passed unspent output from the statistics.
+func (s *utxoStats) remove(outpoint wire.OutPoint, amount int64, pkScript []byte,
+	height int32, isCoinBase bool) {
+
+	s.numUtxos--
+	s.totalAmount -= uint64(amount)
+	s.bogoSize -= uint64(utxoStatsBogoOverhead + len(pkScript))
+	s.serializedSize -= uint64(utxoSerializedSize(outpoint, amount,
+		pkScript, height))
+	s.muHash.Remove(muHashElement(outpoint, amount, pkScript, height,
+		isCoinBase))
+}
+
+// connectBlock updates the statistics for the passed block being connected at
+// the passed height.  The spent txouts must be the ones of the block in the
+// order generated when connecting it.
+func (s *utxoStats) connectBlock(block *btcutil.Block, height int32,
+	stxos []SpentTxOut) {
+
+	stxoIdx := 0
+	for txIdx, tx := range block.Transactions() {
+		if txIdx != 0 {
+			for _, txIn := range tx.MsgTx().TxIn {
+				stxo := &stxos[stxoIdx]
+				stxoIdx++
+				s.remove(txIn.PreviousOutPoint, stxo.Amount,
+					stxo.PkScript, stxo.Height, stxo.IsCoinBase)
+			}
+		}
+
+		outpoint := wire.OutPoint{Hash: *tx.Hash()}
+		for txOutIdx, txOut := range tx.MsgTx().TxOut {
+			if txscript.IsUnspendable(txOut.PkScript) {
+				continue
+			}
+			outpoint.Index = uint32(txOutIdx)
+			s.add(outpoint, txOut.Value, txOut.PkScript, height,
+				txIdx == 0)
+		}
+	}
+}
+
+// disconnectBlock updates the statistics for the passed block at the passed
+// height being disconnected.  The spent txouts must be the ones of the block
+// as stored in the spend journal.
+func (s *utxoStats) disconnectBlock(block *btcutil.Block, height int32,
+	stxos []SpentTxOut) {
+
+	stxoIdx := 0
+	for txIdx, tx := range block.Transactions() {
+		if txIdx != 0 {
+			for _, txIn := range tx.MsgTx().TxIn {
+				stxo := &stxos[stxoIdx]
+				stxoIdx++
+				s.add(txIn.PreviousOutPoint, stxo.Amount,
+					stxo.PkScript, stxo.Height, stxo.IsCoinBase)
+			}
+		}
+
+		outpoint := wire.OutPoint{Hash: *tx.Hash()}
+		for txOutIdx, txOut := range tx.MsgTx().TxOut {
+			if txscript.IsUnspendable(txOut.PkScript) {
+				continue
+			}
+			outpoint.Index = uint32(txOutIdx)
+			s.remove(outpoint, txOut.Value, txOut.PkScript, height,
+				txIdx == 0)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// The utxo set statistics are stored under a single key along with the hash of
+// the block they are for.
+//
+// The serialized format is:
+//
+//   <block hash><num utxos><total amount><bogo size><serialized size><muhash>
+//
+//   Field            Type             Size
+//   block hash       chainhash.Hash   chainhash.HashSize
+//   num utxos        uint64           8 bytes
+//   total amount     uint64           8 bytes
+//   bogo size        uint64           8 bytes
+//   serialized size  uint64           8 bytes
+//   muhash           muhash.MuHash    muhash.SerializedSize
+// -----------------------------------------------------------------------------
+
+// dbPutUtxoStats uses an existing database transaction to store the passed utxo
+// set statistics for the block with the passed hash.
+func dbPutUtxoStats(dbTx database.Tx, hash *chainhash.Hash, stats *utxoStats) error {
+	serialized := make([]byte, serializedUtxoStatsSize)
+	offset := copy(serialized, hash[:])
+	byteOrder.PutUint64(serialized[offset:], stats.numUtxos)
+	offset += 8
+	byteOrder.PutUint64(serialized[offset:], stats.totalAmount)
+	offset += 8
+	byteOrder.PutUint64(serialized[offset:], stats.bogoSize)
+	offset += 8
+	byteOrder.PutUint64(serialized[offset:], stats.serializedSize)
+	offset += 8
+	copy(serialized[offset:], stats.muHash.Serialize())
+	return dbTx.Metadata().Put(utxoSetStatsKeyName, serialized)
+}
+
+// dbFetchUtxoStats uses an existing database transaction to fetch the stored
+// utxo set statistics along with the hash of the block they are for.  It
+// returns nil statistics when they are not stored.
+func dbFetchUtxoStats(dbTx database.Tx) (*chainhash.Hash, *utxoStats, error) {
+	serialized := dbTx.Metadata().Get(utxoSetStatsKeyName)
+	if serialized == nil {
+		return nil, nil, nil
+	}
+	if len(serialized) != serializedUtxoStatsSize {
+		return nil, nil, database.Error{
+			ErrorCode:   database.ErrCorruption,
+			Description: "corrupt utxo set statistics",
+		}
+	}
+
+	var hash chainhash.Hash
+	offset := copy(hash[:], serialized)
+	stats := &utxoStats{}
+	stats.numUtxos = byteOrder.Uint64(serialized[offset:])
+	offset += 8
+	stats.totalAmount = byteOrder.Uint64(serialized[offset:])
+	offset += 8
+	stats.bogoSize = byteOrder.Uint64(serialized[offset:])
+	offset += 8
+	stats.serializedSize = byteOrder.Uint64(serialized[offset:])
+	offset += 8
+	muHash, err := muhash.Deserialize(serialized[offset:])
+	if err != nil {
+		return nil, nil, database.Error{
+			ErrorCode:   database.ErrCorruption,
+			Description: fmt.Sprintf("corrupt utxo set statistics: %v", err),
+		}
+	}
+	stats.muHash = muHash
+	return &hash, stats, nil
+}
+
+// calcUtxoStats calculates the statistics of the utxo set in the database from
+// scratch.
+func calcUtxoStats(dbTx database.Tx) (*utxoStats, error) {
+	stats := newUtxoStats()
+	cursor := dbTx.Metadata().Bucket(utxoSetBucketName).Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		key := cursor.Key()
+		if len(key) <= chainhash.HashSize {
+			return nil, AssertError(fmt.Sprintf("malformed utxo "+
+				"key %x", key))
+		}
+		var outpoint wire.OutPoint
+		copy(outpoint.Hash[:], key)
+		index, _ := deserializeVLQ(key[chainhash.HashSize:])
+		outpoint.Index = uint32(index)
+
+		entry, err := deserializeUtxoEntry(cursor.Value())
+		if err != nil {
+			return nil, err
+		}
+		stats.add(outpoint, entry.Amount(), entry.PkScript(),
+			entry.BlockHeight(), entry.IsCoinBase())
+	}
+	return stats, nil
+}
+
+// initUtxoStats loads the statistics of the utxo set from the database.  They
+// are calculated from scratch when they have not been stored yet or are not for
+// the end of the main chain, for example because an older version of the
+// software was used in the meantime.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) initUtxoStats() error {
+	tip := b.bestChain.Tip()
+	var hash *chainhash.Hash
+	var stats *utxoStats
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		hash, stats, err = dbFetchUtxoStats(dbTx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if stats != nil && *hash == tip.hash {
+		b.utxoStats = stats
+		return nil
+	}
+
+	// The utxo set in the database must be up to date to calculate the
+	// statistics from it.
+	err = b.utxoCache.flush(FlushRequired, &tip.hash)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Calculating utxo set statistics...")
+	err = b.db.Update(func(dbTx database.Tx) error {
+		var err error
+		stats, err = calcUtxoStats(dbTx)
+		if err != nil {
+			return err
+		}
+		return dbPutUtxoStats(dbTx, &tip.hash, stats)
+	})
+	if err != nil {
+		return err
+	}
+	b.utxoStats = stats
+	log.Infof("Calculated utxo set statistics for %d utxos",
+		stats.numUtxos)
+	return nil
+}
+
+// UtxoSetStats returns statistics about the unspent transaction output set as
+// of the end of the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) UtxoSetStats() *UtxoSetStats {
+	b.chainLock.RLock()
+	tip := b.bestChain.Tip()
+	stats := b.utxoStats.copy()
+	b.chainLock.RUnlock()
+
+	return &UtxoSetStats{
+		Hash:           tip.hash,
+		Height:         tip.height,
+		NumUtxos:       stats.numUtxos,
+		TotalAmount:    btcutil.Amount(stats.totalAmount),
+		BogoSize:       stats.bogoSize,
+		SerializedSize: stats.serializedSize,
+		MuHash:         stats.muHash.Finalize(),
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/muhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestUtxoStats ensures the utxo set statistics are kept up to date as blocks
+// are connected and disconnected and match the statistics calculated from the
+// utxo set in the database.
+func TestUtxoStats(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("utxostats", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Create the following chain where b2 and b2a both spend the coinbase
+	// of b1 and b3 spends both the coinbase of b2 and the output of its
+	// spending transaction:
+	//
+	//   genesis -> b1 -> b2 -> b3
+	//                \-> b2a
+	b1 := newTestBlock(&params, &params.GenesisBlock.Header, 1)
+	b2 := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	b3 := newTestBlock(&params, &b2.MsgBlock().Header, 3,
+		coinbaseSpend(b2),
+		testhelper.MakeSpendableOut(b2.MsgBlock(), 1, 0))
+	b2a := newTestBlock(&params, &b1.MsgBlock().Header, 2,
+		coinbaseSpend(b1))
+	for _, block := range []*btcutil.Block{b1, b2, b3, b2a} {
+		_, _, err := chain.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock %v: unexpected error: %v",
+				block.Hash(), err)
+		}
+	}
+
+	// assertStats ensures the statistics are for the unspent outputs of the
+	// passed main chain blocks, of which there must be the passed number,
+	// and match the ones calculated from the database.
+	assertStats := func(name string, numUtxos uint64, blocks ...*btcutil.Block) {
+		t.Helper()
+
+		type utxo struct {
+			amount     int64
+			pkScript   []byte
+			height     int32
+			isCoinBase bool
+		}
+		utxos := make(map[wire.OutPoint]utxo)
+		for i, block := range blocks {
+			for txIdx, tx := range block.Transactions() {
+				if txIdx != 0 {
+					for _, txIn := range tx.MsgTx().TxIn {
+						delete(utxos, txIn.PreviousOutPoint)
+					}
+				}
+				for txOutIdx, txOut := range tx.MsgTx().TxOut {
+					if txscript.IsUnspendable(txOut.PkScript) {
+						continue
+					}
+					outpoint := wire.OutPoint{
+						Hash:  *tx.Hash(),
+						Index: uint32(txOutIdx),
+					}
+					utxos[outpoint] = utxo{txOut.Value,
+						txOut.PkScript, int32(i + 1), txIdx == 0}
+				}
+			}
+		}
+		wantHash := muhash.New()
+		var wantAmount int64
+		var wantBogoSize uint64
+		for outpoint, utxo := range utxos {
+			wantHash.Add(muHashElement(outpoint, utxo.amount,
+				utxo.pkScript, utxo.height, utxo.isCoinBase))
+			wantAmount += utxo.amount
+			wantBogoSize += utxoStatsBogoOverhead +
+				uint64(len(utxo.pkScript))
+		}
+
+		stats := chain.UtxoSetStats()
+		if len(utxos) != int(numUtxos) || stats.NumUtxos != numUtxos ||
+			stats.Hash != *blocks[len(blocks)-1].Hash() ||
+			stats.Height != int32(len(blocks)) ||
+			stats.TotalAmount != btcutil.Amount(wantAmount) ||
+			stats.BogoSize != wantBogoSize ||
+			stats.MuHash != wantHash.Finalize() {
+
+			t.Fatalf("%s: unexpected utxo set statistics %+v", name,
+				stats)
+		}
+
+		err := chain.utxoCache.flush(FlushRequired, &stats.Hash)
+		if err != nil {
+			t.Fatalf("%s: flush: unexpected error: %v", name, err)
+		}
+		var dbStats *utxoStats
+		err = chain.db.View(func(dbTx database.Tx) error {
+			var err error
+			dbStats, err = calcUtxoStats(dbTx)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("%s: calcUtxoStats: unexpected error: %v", name,
+				err)
+		}
+		if dbStats.numUtxos != stats.NumUtxos ||
+			dbStats.serializedSize != stats.SerializedSize ||
+			dbStats.muHash.Finalize() != stats.MuHash {
+
+			t.Fatalf("%s: statistics do not match the database", name)
+		}
+	}
+	// Connecting b3 spent the coinbase of b2 and the output of its
+	// spending transaction, which spent the coinbase of b1.
+	assertStats("ProcessBlock", 3, b1, b2, b3)
+
+	// Disconnecting b3 restores the outputs it spent.
+	if err := chain.InvalidateBlock(b3.Hash()); err != nil {
+		t.Fatalf("InvalidateBlock: unexpected error: %v", err)
+	}
+	assertStats("InvalidateBlock", 2, b1, b2)
+
+	// Reorganizing disconnects b2, which restores the coinbase of b1, and
+	// connects b2a, which spends it again.
+	if err := chain.PreciousBlock(b2a.Hash()); err != nil {
+		t.Fatalf("PreciousBlock: unexpected error: %v", err)
+	}
+	assertStats("PreciousBlock", 2, b1, b2a)
+
+	// The statistics are calculated from scratch when they are missing or
+	// out of date.
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbPutUtxoStats(dbTx, b1.Hash(), newUtxoStats())
+	})
+	if err != nil {
+		t.Fatalf("dbPutUtxoStats: unexpected error: %v", err)
+	}
+	chain.utxoStats = nil
+	if err := chain.initUtxoStats(); err != nil {
+		t.Fatalf("initUtxoStats: unexpected error: %v", err)
+	}
+	assertStats("initUtxoStats", 2, b1, b2a)
+}
@@ -28,6 +28,10 @@ const (
 	// tfModified indicates that a txout has been modified since it was
 	// loaded.
 	tfModified
+
+	// tfFresh indicates that a txout in the utxo cache does not exist in
+	// the database.
+	tfFresh
 )
 
 // UtxoEntry houses details about an individual transaction output in a utxo
@@ -58,6 +62,12 @@ func (entry *UtxoEntry) isModified() bool {
 	return entry.packedFlags&tfModified == tfModified
 }
 
+// isFresh returns whether or not the output is known not to exist in the
+// database.
+func (entry *UtxoEntry) isFresh() bool {
+	return entry.packedFlags&tfFresh == tfFresh
+}
+
 // IsCoinBase returns whether or not the output was contained in a coinbase
 // transaction.
 func (entry *UtxoEntry) IsCoinBase() bool {
@@ -159,6 +169,23 @@ func (view *UtxoViewpoint) LookupEntry(outpoint wire.OutPoint) *UtxoEntry {
 	return view.entries[outpoint]
 }
 
+// FetchPrevOutput returns the transaction output for the passed outpoint
+// according to the current state of the view.  It will return nil if the output
+// is not available in the view.
+//
+// This function is part of the txscript.PrevOutputFetcher interface.
+func (view *UtxoViewpoint) FetchPrevOutput(outpoint wire.OutPoint) *wire.TxOut {
+	entry := view.entries[outpoint]
+	if entry == nil {
+		return nil
+	}
+
+	return &wire.TxOut{
+		Value:    entry.Amount(),
+		PkScript: entry.PkScript(),
+	}
+}
+
 // addTxOut adds the specified output to the view if it is not provably
 // unspendable.  When the view already has an entry for the output, it will be
 // marked unspent.  All fields will be updated for existing entries since it's
@@ -475,7 +502,7 @@ func (view *UtxoViewpoint) commit() {
 			continue
 		}
 
-		entry.packedFlags ^= tfModified
+		entry.packedFlags &^= tfModified
 	}
 }
 
@@ -486,7 +513,7 @@ func (view *UtxoViewpoint) commit() {
 // Upon completion of this function, the view will contain an entry for each
 // requested outpoint.  Spent outputs, or those which otherwise don't exist,
 // will result in a nil entry in the view.
-func (view *UtxoViewpoint) fetchUtxosMain(db database.DB, outpoints map[wire.OutPoint]struct{}) error {
+func (view *UtxoViewpoint) fetchUtxosMain(cache *utxoCache, outpoints map[wire.OutPoint]struct{}) error {
 	// Nothing to do if there are no requested outputs.
 	if len(outpoints) == 0 {
 		return nil
@@ -499,24 +526,27 @@ func (view *UtxoViewpoint) fetchUtxosMain(db database.DB, outpoints map[wire.Out
 	// will result in nil entries in the view.  This is intentionally done
 	// so other code can use the presence of an entry in the store as a way
 	// to unnecessarily avoid attempting to reload it from the database.
-	return db.View(func(dbTx database.Tx) error {
-		for outpoint := range outpoints {
-			entry, err := dbFetchUtxoEntry(dbTx, outpoint)
-			if err != nil {
-				return err
-			}
-
-			view.entries[outpoint] = entry
+	entries, err := cache.fetchEntries(outpoints)
+	if err != nil {
+		return err
+	}
+	for outpoint, entry := range entries {
+		// The entries belong to the cache, so give the view its own
+		// copies which start out unmodified.
+		if entry != nil {
+			entry = entry.Clone()
+			entry.packedFlags &^= tfModified | tfFresh
 		}
+		view.entries[outpoint] = entry
+	}
 
-		return nil
-	})
+	return nil
 }
 
 // fetchUtxos loads the unspent transaction outputs for the provided set of
 // outputs into the view from the database as needed unless they already exist
 // in the view in which case they are ignored.
-func (view *UtxoViewpoint) fetchUtxos(db database.DB, outpoints map[wire.OutPoint]struct{}) error {
+func (view *UtxoViewpoint) fetchUtxos(cache *utxoCache, outpoints map[wire.OutPoint]struct{}) error {
 	// Nothing to do if there are no requested outputs.
 	if len(outpoints) == 0 {
 		return nil
@@ -533,8 +563,8 @@ func (view *UtxoViewpoint) fetchUtxos(db database.DB, outpoints map[wire.OutPoin
 		neededSet[outpoint] = struct{}{}
 	}
 
-	// Request the input utxos from the database.
-	return view.fetchUtxosMain(db, neededSet)
+	// Request the input utxos from the cache.
+	return view.fetchUtxosMain(cache, neededSet)
 }
 
 // fetchInputUtxos loads the unspent transaction outputs for the inputs
@@ -542,7 +572,7 @@ func (view *UtxoViewpoint) fetchUtxos(db database.DB, outpoints map[wire.OutPoin
 // database as needed.  In particular, referenced entries that are earlier in
 // the block are added to the view and entries that are already in the view are
 // not modified.
-func (view *UtxoViewpoint) fetchInputUtxos(db database.DB, block *btcutil.Block) error {
+func (view *UtxoViewpoint) fetchInputUtxos(cache *utxoCache, block *btcutil.Block) error {
 	// Build a map of in-flight transactions because some of the inputs in
 	// this block could be referencing other transactions earlier in this
 	// block which are not yet in the chain.
@@ -588,8 +618,8 @@ func (view *UtxoViewpoint) fetchInputUtxos(db database.DB, block *btcutil.Block)
 		}
 	}
 
-	// Request the input utxos from the database.
-	return view.fetchUtxosMain(db, neededSet)
+	// Request the input utxos from the cache.
+	return view.fetchUtxosMain(cache, neededSet)
 }
 
 // NewUtxoViewpoint returns a new empty unspent transaction output view.
@@ -625,7 +655,7 @@ func (b *BlockChain) FetchUtxoView(tx *btcutil.Tx) (*UtxoViewpoint, error) {
 	// chain.
 	view := NewUtxoViewpoint()
 	b.chainLock.RLock()
-	err := view.fetchUtxosMain(b.db, neededSet)
+	err := view.fetchUtxosMain(b.utxoCache, neededSet)
 	b.chainLock.RUnlock()
 	return view, err
 }
@@ -644,15 +674,11 @@ func (b *BlockChain) FetchUtxoEntry(outpoint wire.OutPoint) (*UtxoEntry, error)
 	b.chainLock.RLock()
 	defer b.chainLock.RUnlock()
 
-	var entry *UtxoEntry
-	err := b.db.View(func(dbTx database.Tx) error {
-		var err error
-		entry, err = dbFetchUtxoEntry(dbTx, outpoint)
-		return err
-	})
+	entry, err := b.utxoCache.fetchEntry(outpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	return entry, nil
+	// The entry belongs to the cache, so return a copy of it.
+	return entry.Clone(), nil
 }
@@ -298,49 +298,78 @@ func CheckTransactionSanity(tx *btcutil.Tx) error {
 }
 
 // checkProofOfWork ensures the block header bits which indicate the target
-// difficulty is in min/max range and that the block hash is less than the
-// target difficulty as claimed.
+// difficulty is in min/max range and that the proof-of-work hash of the block,
+// calculated with the passed algorithm, is less than the target difficulty as
+// claimed.
 //
 // The flags modify the behavior of this function as follows:
 //  - BFNoPoWCheck: The check to ensure the block hash is less than the target
 //    difficulty is not performed.
-func checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, flags BehaviorFlags) error {
+func checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, powAlgo wire.PowAlgorithm, flags BehaviorFlags) error {
+	_, err := calcAndCheckProofOfWork(header, powLimit, powAlgo, flags)
+	return err
+}
+
+// calcAndCheckProofOfWork performs the same checks as checkProofOfWork and
+// additionally returns the proof-of-work hash it calculated so the caller can
+// cache it in the block index.  The returned hash is nil when the BFNoPoWCheck
+// flag is set since no hash is calculated in that case.
+func calcAndCheckProofOfWork(header *wire.BlockHeader, powLimit *big.Int, powAlgo wire.PowAlgorithm, flags BehaviorFlags) (*chainhash.Hash, error) {
 	// The target difficulty must be larger than zero.
 	target := CompactToBig(header.Bits)
 	if target.Sign() <= 0 {
 		str := fmt.Sprintf("block target difficulty of %064x is too low",
 			target)
-		return ruleError(ErrUnexpectedDifficulty, str)
+		return nil, ruleError(ErrUnexpectedDifficulty, str)
 	}
 
 	// The target difficulty must be less than the maximum allowed.
 	if target.Cmp(powLimit) > 0 {
 		str := fmt.Sprintf("block target difficulty of %064x is "+
 			"higher than max of %064x", target, powLimit)
-		return ruleError(ErrUnexpectedDifficulty, str)
+		return nil, ruleError(ErrUnexpectedDifficulty, str)
 	}
 
 	// The block hash must be less than the claimed target unless the flag
 	// to avoid proof of work checks is set.
-	if flags&BFNoPoWCheck != BFNoPoWCheck {
-		// The block hash must be less than the claimed target.
-		hash := header.PowHash()
-		hashNum := HashToBig(&hash)
-		if hashNum.Cmp(target) > 0 {
-			str := fmt.Sprintf("block hash of %064x is higher than "+
-				"expected max of %064x", hashNum, target)
-			return ruleError(ErrHighHash, str)
-		}
+	if flags&BFNoPoWCheck == BFNoPoWCheck {
+		return nil, nil
 	}
+	hash, err := header.PowHash(powAlgo)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPowHash(&hash, target); err != nil {
+		return nil, err
+	}
+	return &hash, nil
+}
 
+// checkPowHash ensures the passed proof-of-work hash is less than the target
+// difficulty.
+func checkPowHash(powHash *chainhash.Hash, target *big.Int) error {
+	hashNum := HashToBig(powHash)
+	if hashNum.Cmp(target) > 0 {
+		str := fmt.Sprintf("block hash of %064x is higher than "+
+			"expected max of %064x", hashNum, target)
+		return ruleError(ErrHighHash, str)
+	}
 	return nil
 }
 
 // CheckProofOfWork ensures the block header bits which indicate the target
-// difficulty is in min/max range and that the block hash is less than the
-// target difficulty as claimed.
-func CheckProofOfWork(block *btcutil.Block, powLimit *big.Int) error {
-	return checkProofOfWork(&block.MsgBlock().Header, powLimit, BFNone)
+// difficulty is in min/max range and that the proof-of-work hash of the block,
+// calculated with the passed algorithm, is less than the target difficulty as
+// claimed.
+func CheckProofOfWork(block *btcutil.Block, powLimit *big.Int, powAlgo wire.PowAlgorithm) error {
+	return checkProofOfWork(&block.MsgBlock().Header, powLimit, powAlgo, BFNone)
+}
+
+// CheckHeaderProofOfWork performs the same checks as CheckProofOfWork on a
+// block header alone.  This is useful when only headers are available such as
+// during a headers-first sync.
+func CheckHeaderProofOfWork(header *wire.BlockHeader, powLimit *big.Int, powAlgo wire.PowAlgorithm) error {
+	return checkProofOfWork(header, powLimit, powAlgo, BFNone)
 }
 
 // CountSigOps returns the number of signature operations for all transaction
@@ -425,13 +454,15 @@ func CountP2SHSigOps(tx *btcutil.Tx, isCoinBaseTx bool, utxoView *UtxoViewpoint)
 // ensure it is sane before continuing with processing.  These checks are
 // context free.
 //
+// The proof-of-work algorithm is the one in effect at the height of the block.
+//
 // The flags do not modify the behavior of this function directly, however they
 // are needed to pass along to checkProofOfWork.
-func checkBlockHeaderSanity(header *wire.BlockHeader, powLimit *big.Int, timeSource MedianTimeSource, flags BehaviorFlags) error {
+func checkBlockHeaderSanity(header *wire.BlockHeader, powLimit *big.Int, powAlgo wire.PowAlgorithm, timeSource MedianTimeSource, flags BehaviorFlags) error {
 	// Ensure the proof of work bits in the block header is in min/max range
 	// and the block hash is less than the target value described by the
 	// bits.
-	err := checkProofOfWork(header, powLimit, flags)
+	err := checkProofOfWork(header, powLimit, powAlgo, flags)
 	if err != nil {
 		return err
 	}
@@ -464,10 +495,10 @@ func checkBlockHeaderSanity(header *wire.BlockHeader, powLimit *big.Int, timeSou
 //
 // The flags do not modify the behavior of this function directly, however they
 // are needed to pass along to checkBlockHeaderSanity.
-func checkBlockSanity(block *btcutil.Block, powLimit *big.Int, timeSource MedianTimeSource, flags BehaviorFlags) error {
+func checkBlockSanity(block *btcutil.Block, powLimit *big.Int, powAlgo wire.PowAlgorithm, timeSource MedianTimeSource, flags BehaviorFlags) error {
 	msgBlock := block.MsgBlock()
 	header := &msgBlock.Header
-	err := checkBlockHeaderSanity(header, powLimit, timeSource, flags)
+	err := checkBlockHeaderSanity(header, powLimit, powAlgo, timeSource, flags)
 	if err != nil {
 		return err
 	}
@@ -570,9 +601,11 @@ func checkBlockSanity(block *btcutil.Block, powLimit *big.Int, timeSource Median
 }
 
 // CheckBlockSanity performs some preliminary checks on a block to ensure it is
-// sane before continuing with block processing.  These checks are context free.
-func CheckBlockSanity(block *btcutil.Block, powLimit *big.Int, timeSource MedianTimeSource) error {
-	return checkBlockSanity(block, powLimit, timeSource, BFNone)
+// sane before continuing with block processing.  These checks are context free
+// aside from the proof-of-work algorithm, which must be the one in effect at
+// the height of the block.
+func CheckBlockSanity(block *btcutil.Block, powLimit *big.Int, powAlgo wire.PowAlgorithm, timeSource MedianTimeSource) error {
+	return checkBlockSanity(block, powLimit, powAlgo, timeSource, BFNone)
 }
 
 // ExtractCoinbaseHeight attempts to extract the height of the block from the
@@ -709,7 +742,9 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 		return ruleError(ErrBlockVersionTooOld, str)
 	}
 
-	return nil
+	// Reject blocks which do not signal for deployments that must be
+	// locked in on timeout as defined by BIP0008.
+	return b.checkDeploymentSignals(header, prevNode)
 }
 
 // checkBlockContext peforms several validation checks on the block which depend
@@ -829,7 +864,7 @@ func (b *BlockChain) checkBlockContext(block *btcutil.Block, prevNode *blockNode
 // http://r6.ca/blog/20120206T005236Z.html.
 //
 // This function MUST be called with the chain state lock held (for reads).
-func (b *BlockChain) checkBIP0030(node *blockNode, block *btcutil.Block, view *UtxoViewpoint) error {
+func (b *BlockChain) checkBIP0030(cache *utxoCache, node *blockNode, block *btcutil.Block, view *UtxoViewpoint) error {
 	// Fetch utxos for all of the transaction ouputs in this block.
 	// Typically, there will not be any utxos for any of the outputs.
 	fetchSet := make(map[wire.OutPoint]struct{})
@@ -840,7 +875,7 @@ func (b *BlockChain) checkBIP0030(node *blockNode, block *btcutil.Block, view *U
 			fetchSet[prevOut] = struct{}{}
 		}
 	}
-	err := view.fetchUtxos(b.db, fetchSet)
+	err := view.fetchUtxos(cache, fetchSet)
 	if err != nil {
 		return err
 	}
@@ -984,8 +1019,22 @@ func CheckTransactionInputs(tx *btcutil.Tx, txHeight int32, utxoView *UtxoViewpo
 // connects to the end of the current main chain and then calls this function
 // with that node.
 //
+// The flags modify the behavior of this function as follows:
+//  - BFAssumeValid: The transaction scripts are not validated when the chain
+//    has an assumed valid block.
+//
 // This function MUST be called with the chain state lock held (for writes).
-func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, view *UtxoViewpoint, stxos *[]SpentTxOut) error {
+func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, view *UtxoViewpoint, stxos *[]SpentTxOut, flags BehaviorFlags) error {
+	return b.checkConnectBlockUtxos(b.utxoCache, node, block, view, stxos, flags)
+}
+
+// checkConnectBlockUtxos is checkConnectBlock with the utxos the view does not
+// already contain loaded from the passed utxo cache rather than the one for the
+// best chain.  This allows the blocks below a loaded utxo snapshot to be
+// validated against the utxo set built from them.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) checkConnectBlockUtxos(cache *utxoCache, node *blockNode, block *btcutil.Block, view *UtxoViewpoint, stxos *[]SpentTxOut, flags BehaviorFlags) error {
 	// If the side chain blocks end up in the database, a call to
 	// CheckBlockSanity should be done here in case a previous version
 	// allowed a block that is no longer valid.  However, since the
@@ -1024,7 +1073,7 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 	// BIP0030 check is expensive since it involves a ton of cache misses in
 	// the utxoset.
 	if !isBIP0030Node(node) && (node.height < b.chainParams.BIP0034Height) {
-		err := b.checkBIP0030(node, block, view)
+		err := b.checkBIP0030(cache, node, block, view)
 		if err != nil {
 			return err
 		}
@@ -1035,7 +1084,7 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 	//
 	// These utxo entries are needed for verification of things such as
 	// transaction inputs, counting pay-to-script-hashes, and scripts.
-	err := view.fetchInputUtxos(b.db, block)
+	err := view.fetchInputUtxos(cache, block)
 	if err != nil {
 		return err
 	}
@@ -1152,6 +1201,13 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 		runScripts = false
 	}
 
+	// Similarly, don't run scripts for ancestors of the assumed valid block
+	// since the block, and therefore all of the transactions of its
+	// ancestors, are known to have been validated by the network.
+	if runScripts && b.isAssumedValid(node, flags) {
+		runScripts = false
+	}
+
 	// Blocks created after the BIP0016 activation time need to have the
 	// pay-to-script-hash checks enabled.
 	var scriptFlags txscript.ScriptFlags
@@ -1218,6 +1274,17 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 		scriptFlags |= txscript.ScriptStrictMultiSig
 	}
 
+	// Enforce the taproot soft-fork rules for spends of version 1 witness
+	// programs once the soft-fork deployment is fully active.
+	taprootState, err := b.deploymentState(node.parent,
+		chaincfg.DeploymentTaproot)
+	if err != nil {
+		return err
+	}
+	if taprootState == ThresholdActive {
+		scriptFlags |= txscript.ScriptVerifyTaproot
+	}
+
 	// Now that the inexpensive checks are done and have passed, verify the
 	// transactions are actually allowed to spend the coins by running the
 	// expensive ECDSA signature check scripts.  Doing this last helps
@@ -1259,7 +1326,9 @@ func (b *BlockChain) CheckConnectBlockTemplate(block *btcutil.Block) error {
 		return ruleError(ErrPrevBlockNotBest, str)
 	}
 
-	err := checkBlockSanity(block, b.chainParams.PowLimit, b.timeSource, flags)
+	powAlgo := b.chainParams.PowAlgorithmAt(tip.height + 1)
+	err := checkBlockSanity(block, b.chainParams.PowLimit, powAlgo,
+		b.timeSource, flags)
 	if err != nil {
 		return err
 	}
@@ -1274,5 +1343,5 @@ func (b *BlockChain) CheckConnectBlockTemplate(block *btcutil.Block) error {
 	view := NewUtxoViewpoint()
 	view.SetBestHash(&tip.hash)
 	newNode := newBlockNode(&header, tip)
-	return b.checkConnectBlock(newNode, block, view, nil)
+	return b.checkConnectBlock(newNode, block, view, nil, BFNone)
 }
@@ -0,0 +1,509 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// VerifyLevelReadBlocks only ensures the blocks can be read from the
+	// database and match the block index.
+	VerifyLevelReadBlocks = 0
+
+	// VerifyLevelSanity additionally performs the context-free sanity checks
+	// on the blocks.
+	VerifyLevelSanity = 1
+
+	// VerifyLevelSpendJournal additionally ensures the spend journal entries
+	// of the blocks can be read and are consistent with the blocks.
+	VerifyLevelSpendJournal = 2
+
+	// VerifyLevelDisconnect additionally disconnects the blocks from the
+	// tip of the main chain in a scratch utxo view, which ensures the
+	// outputs they create are unspent in the utxo set and restores the
+	// outputs they spend from the spend journal.
+	VerifyLevelDisconnect = 3
+
+	// VerifyLevelReconnect additionally connects the disconnected blocks to
+	// the scratch utxo view again with full validation and ensures the
+	// outputs they spend match the spend journal.
+	VerifyLevelReconnect = 4
+
+	// MaxVerifyLevel is the most thorough level of VerifyChain.
+	MaxVerifyLevel = VerifyLevelReconnect
+
+	// defaultVerifyMemoryLimit is the estimated number of bytes the blocks
+	// disconnected by VerifyChain may use in memory when the utxo cache is
+	// flushed after every block and therefore has no size.
+	defaultVerifyMemoryLimit = 250 * 1024 * 1024
+)
+
+// VerifyChainFailure describes a block of the main chain which failed one of
+// the checks performed by VerifyChain.
+type VerifyChainFailure struct {
+	// Level is the verification level of the failed check.
+	Level int32
+
+	// Hash and Height identify the block which failed the check.
+	Hash   chainhash.Hash
+	Height int32
+
+	// Err is the reason the check failed.
+	Err error
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (f *VerifyChainFailure) Error() string {
+	return fmt.Sprintf("level %d check of block %v (height %d) failed: %v",
+		f.Level, f.Hash, f.Height, f.Err)
+}
+
+// Unwrap returns the reason the check failed.
+func (f *VerifyChainFailure) Unwrap() error {
+	return f.Err
+}
+
+// VerifyChainReport houses the results of VerifyChain.
+type VerifyChainReport struct {
+	// Level is the verification level which was performed.
+	Level int32
+
+	// StartHeight and EndHeight are the heights of the most recent and the
+	// oldest block which were checked.  EndHeight exceeds StartHeight when
+	// no blocks were checked.
+	StartHeight int32
+	EndHeight   int32
+
+	// DisconnectEndHeight is the height of the oldest block which was
+	// disconnected, and reconnected at VerifyLevelReconnect.  The blocks
+	// are disconnected in memory, so the older blocks are only checked at
+	// the lower levels once the disconnected blocks would exceed the size
+	// of the utxo cache.  It exceeds StartHeight when no blocks were
+	// disconnected.
+	DisconnectEndHeight int32
+
+	// Failures are the checks which failed.  The checks of the lower levels
+	// are performed for all blocks, while disconnecting and reconnecting
+	// the blocks is only attempted when they pass and stops at the first
+	// failure.
+	Failures []VerifyChainFailure
+}
+
+// Verified returns whether all checked blocks passed all checks.
+func (r *VerifyChainReport) Verified() bool {
+	return len(r.Failures) == 0
+}
+
+// String returns a human-readable summary of the report which includes every
+// failure.
+func (r *VerifyChainReport) String() string {
+	var s strings.Builder
+	numBlocks := r.StartHeight - r.EndHeight + 1
+	if numBlocks < 0 {
+		numBlocks = 0
+	}
+	fmt.Fprintf(&s, "verified %d blocks (heights %d to %d) at level %d: "+
+		"%d failures", numBlocks, r.EndHeight, r.StartHeight, r.Level,
+		len(r.Failures))
+	if r.Level >= VerifyLevelDisconnect && r.DisconnectEndHeight > r.EndHeight &&
+		r.DisconnectEndHeight <= r.StartHeight {
+
+		fmt.Fprintf(&s, " (blocks below height %d checked at level %d "+
+			"due to the memory limit)", r.DisconnectEndHeight,
+			VerifyLevelSpendJournal)
+	}
+	for i := range r.Failures {
+		fmt.Fprintf(&s, "\n  %v", &r.Failures[i])
+	}
+	return s.String()
+}
+
+// fail records a failure of the passed block at the passed level.
+func (r *VerifyChainReport) fail(level int32, node *blockNode, err error) {
+	r.Failures = append(r.Failures, VerifyChainFailure{
+		Level:  level,
+		Hash:   node.hash,
+		Height: node.height,
+		Err:    err,
+	})
+}
+
+// checkSpendJournalEntry ensures the passed spend journal entry, which was
+// deserialized from the passed data, is consistent with the block it is for.
+// The number of entries is already ensured by deserializing it.  Outputs which
+// are spent by the block they are created in must match the outputs in the
+// block, while all other outputs must have been created by an earlier block.
+func checkSpendJournalEntry(block *btcutil.Block, serialized []byte, stxos []SpentTxOut) error {
+	// Entries in the current format serialize to the data they were
+	// deserialized from, which detects trailing data.  Entries in the
+	// legacy format do not record the height of every output.
+	legacy := false
+	for i := range stxos {
+		legacy = legacy || stxos[i].Height == 0
+	}
+	if !legacy && !bytes.Equal(serializeSpendJournalEntry(stxos), serialized) {
+		return fmt.Errorf("spend journal entry of %d bytes does not "+
+			"match the %d spent outputs", len(serialized), len(stxos))
+	}
+
+	txns := block.Transactions()
+	txIndexes := make(map[chainhash.Hash]int, len(txns))
+	for i, tx := range txns {
+		txIndexes[*tx.Hash()] = i
+	}
+
+	var stxoIdx int
+	for _, tx := range txns[1:] {
+		for _, txIn := range tx.MsgTx().TxIn {
+			stxo := &stxos[stxoIdx]
+			stxoIdx++
+
+			if stxo.Amount < 0 || stxo.Amount > btcutil.MaxSatoshi {
+				return fmt.Errorf("spent output %v has an invalid "+
+					"amount of %d", txIn.PreviousOutPoint,
+					stxo.Amount)
+			}
+
+			prevOut := &txIn.PreviousOutPoint
+			txIdx, ok := txIndexes[prevOut.Hash]
+			if !ok {
+				if stxo.Height >= block.Height() {
+					return fmt.Errorf("spent output %v was "+
+						"created at height %d", *prevOut,
+						stxo.Height)
+				}
+				continue
+			}
+
+			originTx := txns[txIdx].MsgTx()
+			if prevOut.Index >= uint32(len(originTx.TxOut)) {
+				return fmt.Errorf("spent output %v does not "+
+					"exist", *prevOut)
+			}
+			txOut := originTx.TxOut[prevOut.Index]
+			// Entries in the legacy format do not record the height
+			// of outputs which were not the last unspent output of
+			// their transaction.
+			if stxo.Amount != txOut.Value ||
+				!bytes.Equal(stxo.PkScript, txOut.PkScript) ||
+				(stxo.Height != 0 && (stxo.Height != block.Height() ||
+					stxo.IsCoinBase != (txIdx == 0))) {
+
+				return fmt.Errorf("spent output %v does not match "+
+					"the output in the block", *prevOut)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkDisconnectUtxos ensures the outputs created by the passed block are
+// unspent in the passed view which is for the block, with the exception of the
+// outputs spent by the block itself.  The outputs which are not in the view are
+// loaded from the passed utxo cache.
+func checkDisconnectUtxos(cache *utxoCache, view *UtxoViewpoint, block *btcutil.Block) error {
+	spentInBlock := make(map[wire.OutPoint]struct{})
+	for _, tx := range block.Transactions()[1:] {
+		for _, txIn := range tx.MsgTx().TxIn {
+			spentInBlock[txIn.PreviousOutPoint] = struct{}{}
+		}
+	}
+
+	created := make(map[wire.OutPoint]*wire.TxOut)
+	for _, tx := range block.Transactions() {
+		prevOut := wire.OutPoint{Hash: *tx.Hash()}
+		for txOutIdx, txOut := range tx.MsgTx().TxOut {
+			prevOut.Index = uint32(txOutIdx)
+			if _, ok := spentInBlock[prevOut]; ok ||
+				txscript.IsUnspendable(txOut.PkScript) {
+
+				continue
+			}
+			created[prevOut] = txOut
+		}
+	}
+
+	needed := make(map[wire.OutPoint]struct{}, len(created))
+	for outpoint := range created {
+		needed[outpoint] = struct{}{}
+	}
+	if err := view.fetchUtxos(cache, needed); err != nil {
+		return err
+	}
+
+	for outpoint, txOut := range created {
+		entry := view.LookupEntry(outpoint)
+		if entry == nil || entry.IsSpent() {
+			return fmt.Errorf("output %v created by the block is "+
+				"missing from the utxo set", outpoint)
+		}
+		if entry.Amount() != txOut.Value ||
+			!bytes.Equal(entry.PkScript(), txOut.PkScript) ||
+			entry.BlockHeight() != block.Height() {
+
+			return fmt.Errorf("output %v created by the block does "+
+				"not match the utxo set", outpoint)
+		}
+	}
+
+	return nil
+}
+
+// verifyChain performs the checks of the passed level and all levels below it
+// on the passed number of most recent blocks of the main chain.  A depth of
+// zero checks all blocks.  See VerifyChain for details.
+//
+// The flags are passed to the context-free sanity checks.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) verifyChain(level, depth int32, flags BehaviorFlags,
+	interrupt <-chan struct{}) (*VerifyChainReport, error) {
+
+	if level < 0 || level > MaxVerifyLevel {
+		return nil, fmt.Errorf("verification level %d is not between 0 "+
+			"and %d", level, MaxVerifyLevel)
+	}
+
+	// The genesis block is never checked since it does not spend or create
+	// any outputs in the utxo set, and neither are blocks whose data is not
+	// available because they were pruned or are below a loaded utxo
+	// snapshot.
+	tip := b.bestChain.Tip()
+	endHeight := int32(1)
+	if depth > 0 && tip.height-depth+1 > endHeight {
+		endHeight = tip.height - depth + 1
+	}
+	if pruneHeight := b.PruneHeight(); pruneHeight > endHeight {
+		endHeight = pruneHeight
+	}
+	report := &VerifyChainReport{
+		Level:               level,
+		StartHeight:         tip.height,
+		EndHeight:           endHeight,
+		DisconnectEndHeight: tip.height + 1,
+	}
+	if b.snapshot != nil && b.snapshot.base.height >= endHeight {
+		report.EndHeight = b.snapshot.base.height + 1
+	}
+	log.Infof("Verifying %d blocks of the main chain at level %d",
+		report.StartHeight-report.EndHeight+1, level)
+
+	// Disconnecting the blocks from the tip requires them to be consistent
+	// with the utxo set, so the disconnected blocks are kept to reconnect
+	// them afterwards.  They are limited to the size of the utxo cache in
+	// memory along with the scratch view, so not all blocks are
+	// disconnected when checking deep into the chain.
+	memoryLimit := b.utxoCache.maxSize
+	if memoryLimit == 0 {
+		memoryLimit = defaultVerifyMemoryLimit
+	}
+	var memoryUsage uint64
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&tip.hash)
+	var detachNodes []*blockNode
+	var detachBlocks []*btcutil.Block
+	var detachSpentTxOuts [][]SpentTxOut
+	for height := report.StartHeight; height >= report.EndHeight; height-- {
+		if interruptRequested(interrupt) {
+			return nil, errInterruptRequested
+		}
+
+		// Level 0 ensures the block can be read from the database.
+		node := b.bestChain.NodeByHeight(height)
+		var block *btcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByNode(dbTx, node)
+			return err
+		})
+		if err == nil && *block.Hash() != node.hash {
+			err = fmt.Errorf("block hash %v does not match the block "+
+				"index", block.Hash())
+		}
+		if err != nil {
+			report.fail(VerifyLevelReadBlocks, node, err)
+			continue
+		}
+
+		// Level 1 performs the context-free sanity checks.  The block
+		// matches its node, so the proof of work is checked with the
+		// hash cached in the block index when it is known.
+		if level >= VerifyLevelSanity {
+			var err error
+			if flags&BFNoPoWCheck == 0 {
+				_, err = b.nodePowHash(node)
+			}
+			if err == nil {
+				err = checkBlockSanity(block,
+					b.chainParams.PowLimit,
+					b.chainParams.PowAlgorithmAt(height),
+					b.timeSource, flags|BFNoPoWCheck)
+			}
+			if err != nil {
+				report.fail(VerifyLevelSanity, node, err)
+				continue
+			}
+		}
+
+		// Level 2 ensures the spend journal entry of the block can be
+		// loaded and is consistent with it.
+		var stxos []SpentTxOut
+		if level >= VerifyLevelSpendJournal {
+			var serialized []byte
+			err := b.db.View(func(dbTx database.Tx) error {
+				spendBucket := dbTx.Metadata().Bucket(spendJournalBucketName)
+				serialized = spendBucket.Get(block.Hash()[:])
+				var err error
+				stxos, err = dbFetchSpendJournalEntry(dbTx, block)
+				return err
+			})
+			if err == nil {
+				err = checkSpendJournalEntry(block, serialized, stxos)
+			}
+			if err != nil {
+				report.fail(VerifyLevelSpendJournal, node, err)
+				continue
+			}
+		}
+
+		// Level 3 disconnects the block in the scratch view as long as
+		// all of the blocks after it were disconnected without any
+		// failures and it fits in memory.
+		if level < VerifyLevelDisconnect || len(report.Failures) != 0 ||
+			report.DisconnectEndHeight != height+1 {
+
+			continue
+		}
+		blockUsage := disconnectMemoryUsage(block, stxos)
+		if memoryUsage+blockUsage > memoryLimit {
+			log.Infof("Verifying blocks below height %d at level %d "+
+				"only since disconnecting them would exceed the "+
+				"memory limit", height+1, VerifyLevelSpendJournal)
+			continue
+		}
+		memoryUsage += blockUsage
+		err = checkDisconnectUtxos(b.utxoCache, view, block)
+		if err == nil {
+			err = view.fetchInputUtxos(b.utxoCache, block)
+		}
+		if err == nil {
+			err = view.disconnectTransactions(b.db, block, stxos)
+		}
+		if err != nil {
+			report.fail(VerifyLevelDisconnect, node, err)
+			continue
+		}
+		report.DisconnectEndHeight = height
+		detachNodes = append(detachNodes, node)
+		detachBlocks = append(detachBlocks, block)
+		detachSpentTxOuts = append(detachSpentTxOuts, stxos)
+	}
+
+	// Level 4 connects the disconnected blocks again in the order they are
+	// in the main chain and ensures the outputs they spend are the ones
+	// recorded in the spend journal.
+	if level >= VerifyLevelReconnect && len(report.Failures) == 0 {
+		for i := len(detachNodes) - 1; i >= 0; i-- {
+			if interruptRequested(interrupt) {
+				return nil, errInterruptRequested
+			}
+
+			node, block := detachNodes[i], detachBlocks[i]
+			stxos := make([]SpentTxOut, 0, countSpentOutputs(block))
+			err := b.checkConnectBlock(node, block, view, &stxos, BFNone)
+			if err == nil {
+				err = checkSpentTxOuts(stxos, detachSpentTxOuts[i])
+			}
+			if err != nil {
+				report.fail(VerifyLevelReconnect, node, err)
+				break
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// disconnectMemoryUsage returns the estimated number of bytes disconnecting the
+// passed block with the passed spent outputs uses in memory, which includes the
+// entries for the outputs it creates and spends in the scratch view as well as
+// the block and spent outputs kept to reconnect it.
+func disconnectMemoryUsage(block *btcutil.Block, stxos []SpentTxOut) uint64 {
+	usage := uint64(block.MsgBlock().SerializeSize())
+	for _, tx := range block.Transactions() {
+		for _, txOut := range tx.MsgTx().TxOut {
+			usage += cachedEntryOverhead + uint64(len(txOut.PkScript))
+		}
+	}
+	for i := range stxos {
+		usage += 2 * (cachedEntryOverhead + uint64(len(stxos[i].PkScript)))
+	}
+	return usage
+}
+
+// checkSpentTxOuts ensures the passed outputs spent by connecting a block match
+// the ones recorded in its spend journal entry.
+func checkSpentTxOuts(stxos, journal []SpentTxOut) error {
+	if len(stxos) != len(journal) {
+		return fmt.Errorf("block spends %d outputs while its spend "+
+			"journal entry has %d", len(stxos), len(journal))
+	}
+	for i := range stxos {
+		stxo, entry := &stxos[i], &journal[i]
+
+		// The legacy spend journal format does not always record the
+		// height and coinbase flag.
+		if stxo.Amount != entry.Amount ||
+			!bytes.Equal(stxo.PkScript, entry.PkScript) ||
+			(entry.Height != 0 && (stxo.Height != entry.Height ||
+				stxo.IsCoinBase != entry.IsCoinBase)) {
+
+			return fmt.Errorf("spent output %d does not match the "+
+				"spend journal entry", i)
+		}
+	}
+	return nil
+}
+
+// VerifyChain checks the passed number of most recent blocks of the main chain
+// at the passed level, which is one of the VerifyLevel constants, and returns a
+// report of the checks which failed.  A depth of zero checks all blocks.  Every
+// level performs the checks of the levels below it:
+//
+//   - VerifyLevelReadBlocks ensures the blocks can be read from the database
+//   - VerifyLevelSanity performs the context-free sanity checks on them
+//   - VerifyLevelSpendJournal ensures their spend journal entries are
+//     consistent with them
+//   - VerifyLevelDisconnect disconnects them from the tip of the main chain in
+//     a scratch utxo view, which requires them to be consistent with the utxo
+//     set
+//   - VerifyLevelReconnect connects them to the scratch utxo view again with
+//     full validation
+//
+// The blocks disconnected by the last two levels are kept in memory, so only
+// as many of the most recent blocks are disconnected as fit into the size of
+// the utxo cache, and the older blocks are checked at the lower levels.
+//
+// Neither the chain state nor the database are modified.  An error is only
+// returned when the checks could not be performed, while the failures of the
+// checks are part of the report.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) VerifyChain(level, depth int32) (*VerifyChainReport, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	return b.verifyChain(level, depth, BFNone, nil)
+}
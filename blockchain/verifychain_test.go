@@ -0,0 +1,143 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestVerifyChain ensures the checks of every verification level pass for a
+// consistent chain and detect inconsistencies of the spend journal and the utxo
+// set.
+func TestVerifyChain(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("verifychain", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Every block spends the coinbase of the previous one.
+	var blocks []*btcutil.Block
+	prev := &params.GenesisBlock.Header
+	for height := int32(1); height <= 3; height++ {
+		var spends []testhelper.SpendableOut
+		if height > 1 {
+			spends = append(spends, coinbaseSpend(blocks[height-2]))
+		}
+		block := newTestBlock(&params, prev, height, spends...)
+		_, _, err := chain.ProcessBlock(block, BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock %d: unexpected error: %v", height,
+				err)
+		}
+		blocks = append(blocks, block)
+		prev = &block.MsgBlock().Header
+	}
+
+	// verify runs the verification and ensures it reports a failure of the
+	// passed block at the passed level or none when the block is nil.
+	verify := func(name string, level, depth int32, wantLevel int32,
+		wantBlock *btcutil.Block) {
+
+		t.Helper()
+
+		report, err := chain.verifyChain(level, depth, BFNoPoWCheck, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if wantBlock == nil {
+			if !report.Verified() {
+				t.Fatalf("%s: unexpected failures: %v", name, report)
+			}
+			return
+		}
+		if len(report.Failures) != 1 {
+			t.Fatalf("%s: unexpected failures: %v", name, report)
+		}
+		failure := report.Failures[0]
+		if failure.Level != wantLevel || failure.Hash != *wantBlock.Hash() {
+			t.Fatalf("%s: unexpected failure: %v", name, &failure)
+		}
+	}
+
+	for level := int32(0); level <= MaxVerifyLevel; level++ {
+		verify("consistent chain", level, 0, 0, nil)
+	}
+	report, err := chain.verifyChain(MaxVerifyLevel, 2, BFNoPoWCheck, nil)
+	if err != nil {
+		t.Fatalf("verifyChain: unexpected error: %v", err)
+	}
+	if report.StartHeight != 3 || report.EndHeight != 2 {
+		t.Fatalf("verifyChain: unexpected heights %d to %d",
+			report.EndHeight, report.StartHeight)
+	}
+	if _, err := chain.verifyChain(MaxVerifyLevel+1, 0, BFNone, nil); err == nil {
+		t.Fatal("verifyChain: invalid level accepted")
+	}
+
+	// Spending an unspent output of the second block in the utxo set is
+	// detected when disconnecting it, but not by the levels below.
+	outpoint := wire.OutPoint{Hash: *blocks[1].Transactions()[1].Hash()}
+	entry, err := chain.utxoCache.fetchEntry(outpoint)
+	if err != nil || entry == nil {
+		t.Fatalf("fetchEntry: unexpected entry %v (err %v)", entry, err)
+	}
+	entry = entry.Clone()
+	entry.Spend()
+	view := NewUtxoViewpoint()
+	view.entries[outpoint] = entry
+	chain.utxoCache.commit(view)
+	verify("missing utxo", VerifyLevelSpendJournal, 0, 0, nil)
+	verify("missing utxo", VerifyLevelDisconnect, 0, VerifyLevelDisconnect,
+		blocks[1])
+	verify("missing utxo", MaxVerifyLevel, 1, 0, nil)
+
+	// Only the blocks which fit into the memory limit are disconnected, so
+	// the missing utxo goes unnoticed when only the tip fits.
+	var stxos []SpentTxOut
+	err = chain.db.View(func(dbTx database.Tx) error {
+		var err error
+		stxos, err = dbFetchSpendJournalEntry(dbTx, blocks[2])
+		return err
+	})
+	if err != nil || len(stxos) != 1 {
+		t.Fatalf("dbFetchSpendJournalEntry: unexpected result %v (err "+
+			"%v)", stxos, err)
+	}
+	maxSize := chain.utxoCache.maxSize
+	chain.utxoCache.maxSize = disconnectMemoryUsage(blocks[2], stxos)
+	verify("memory limit", MaxVerifyLevel, 0, 0, nil)
+	report, err = chain.verifyChain(MaxVerifyLevel, 0, BFNoPoWCheck, nil)
+	if err != nil {
+		t.Fatalf("verifyChain: unexpected error: %v", err)
+	}
+	if report.DisconnectEndHeight != 3 || report.EndHeight != 1 {
+		t.Fatalf("verifyChain: unexpected disconnected heights %d to %d "+
+			"of %d to %d", report.DisconnectEndHeight,
+			report.StartHeight, report.EndHeight, report.StartHeight)
+	}
+	chain.utxoCache.maxSize = maxSize
+
+	// A spend journal entry with more outputs than the block spends is
+	// detected.
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbPutSpendJournalEntry(dbTx, blocks[2].Hash(),
+			append(stxos, stxos[0]))
+	})
+	if err != nil {
+		t.Fatalf("dbPutSpendJournalEntry: unexpected error: %v", err)
+	}
+	verify("corrupt spend journal", VerifyLevelSanity, 0, 0, nil)
+	verify("corrupt spend journal", MaxVerifyLevel, 0,
+		VerifyLevelSpendJournal, blocks[2])
+}
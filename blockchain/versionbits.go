@@ -5,9 +5,11 @@
 package blockchain
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
 )
 
 const (
@@ -65,6 +67,60 @@ func (c bitConditionChecker) EndTime() uint64 {
 	return math.MaxUint64
 }
 
+// HeightBased returns whether the rule change starts and times out at block
+// heights instead of median block times.
+//
+// Since this implementation checks for unknown rules, it returns false so the
+// time-based values which treat the rule as always active are used.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c bitConditionChecker) HeightBased() bool {
+	return false
+}
+
+// BeginHeight returns the height of the block at which voting on a rule change
+// starts.
+//
+// Since this implementation checks for unknown rules, it returns 0 so the rule
+// is always treated as active.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c bitConditionChecker) BeginHeight() uint32 {
+	return 0
+}
+
+// EndHeight returns the height of the block at which an attempted rule change
+// fails if it has not already been locked in or activated.
+//
+// Since this implementation checks for unknown rules, it returns the maximum
+// possible height so the rule is always treated as active.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c bitConditionChecker) EndHeight() uint32 {
+	return math.MaxUint32
+}
+
+// MinActivationHeight returns the height of the block before which a locked in
+// rule change can't become active.
+//
+// Since this implementation checks for unknown rules, it returns 0 so they
+// are treated as active as soon as possible.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c bitConditionChecker) MinActivationHeight() uint32 {
+	return 0
+}
+
+// LockInOnTimeout returns whether blocks must signal for a height-based rule
+// change during the last window before its end height.
+//
+// Since this implementation checks for unknown rules, it returns false.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c bitConditionChecker) LockInOnTimeout() bool {
+	return false
+}
+
 // RuleChangeActivationThreshold is the number of blocks for which the condition
 // must be true in order to lock in a rule change.
 //
@@ -146,6 +202,61 @@ func (c deploymentChecker) EndTime() uint64 {
 	return c.deployment.ExpireTime
 }
 
+// HeightBased returns whether the rule change starts and times out at block
+// heights instead of median block times.
+//
+// This implementation returns whether the specific deployment the checker is
+// associated with is height-based as defined by BIP0008.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c deploymentChecker) HeightBased() bool {
+	return c.deployment.HeightBased()
+}
+
+// BeginHeight returns the height of the block at which voting on a rule change
+// starts.
+//
+// This implementation returns the value defined by the specific deployment the
+// checker is associated with.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c deploymentChecker) BeginHeight() uint32 {
+	return c.deployment.StartHeight
+}
+
+// EndHeight returns the height of the block at which an attempted rule change
+// fails if it has not already been locked in or activated.
+//
+// This implementation returns the value defined by the specific deployment the
+// checker is associated with.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c deploymentChecker) EndHeight() uint32 {
+	return c.deployment.TimeoutHeight
+}
+
+// MinActivationHeight returns the height of the block before which a locked in
+// rule change can't become active.
+//
+// This implementation returns the value defined by the specific deployment the
+// checker is associated with.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c deploymentChecker) MinActivationHeight() uint32 {
+	return c.deployment.MinActivationHeight
+}
+
+// LockInOnTimeout returns whether blocks must signal for a height-based rule
+// change during the last window before its end height.
+//
+// This implementation returns the value defined by the specific deployment the
+// checker is associated with.
+//
+// This is part of the thresholdConditionChecker interface implementation.
+func (c deploymentChecker) LockInOnTimeout() bool {
+	return c.deployment.LockInOnTimeout
+}
+
 // RuleChangeActivationThreshold is the number of blocks for which the condition
 // must be true in order to lock in a rule change.
 //
@@ -190,24 +301,63 @@ func (c deploymentChecker) Condition(node *blockNode) (bool, error) {
 // This function MUST be called with the chain state lock held (for writes).
 func (b *BlockChain) calcNextBlockVersion(prevNode *blockNode) (int32, error) {
 	// Set the appropriate bits for each actively defined rule deployment
-	// that is either in the process of being voted on, or locked in for the
-	// activation at the next threshold window change.
+	// that is either in the process of being voted on, required to signal,
+	// or locked in for the activation at the next threshold window change.
 	expectedVersion := uint32(vbTopBits)
 	for id := 0; id < len(b.chainParams.Deployments); id++ {
 		deployment := &b.chainParams.Deployments[id]
+		if deployment.AlwaysActive || deployment.NeverActive {
+			continue
+		}
 		cache := &b.deploymentCaches[id]
 		checker := deploymentChecker{deployment: deployment, chain: b}
 		state, err := b.thresholdState(prevNode, checker, cache)
 		if err != nil {
 			return 0, err
 		}
-		if state == ThresholdStarted || state == ThresholdLockedIn {
+		switch state {
+		case ThresholdStarted, ThresholdMustSignal, ThresholdLockedIn:
 			expectedVersion |= uint32(1) << deployment.BitNumber
 		}
 	}
 	return int32(expectedVersion), nil
 }
 
+// checkDeploymentSignals ensures the passed block header signals for every
+// deployment which requires all blocks after the passed previous block node to
+// signal for it in order to lock it in on timeout.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) checkDeploymentSignals(header *wire.BlockHeader, prevNode *blockNode) error {
+	version := uint32(header.Version)
+	for id := 0; id < len(b.chainParams.Deployments); id++ {
+		deployment := &b.chainParams.Deployments[id]
+		if !deployment.LockInOnTimeout {
+			continue
+		}
+
+		cache := &b.deploymentCaches[id]
+		checker := deploymentChecker{deployment: deployment, chain: b}
+		state, err := b.thresholdState(prevNode, checker, cache)
+		if err != nil {
+			return err
+		}
+		if state != ThresholdMustSignal {
+			continue
+		}
+
+		conditionMask := uint32(1) << deployment.BitNumber
+		if version&vbTopMask != vbTopBits || version&conditionMask == 0 {
+			str := fmt.Sprintf("block version %#08x does not signal "+
+				"for deployment %d with bit %d which must be "+
+				"locked in", version, id, deployment.BitNumber)
+			return ruleError(ErrMissingDeploymentSignal, str)
+		}
+	}
+
+	return nil
+}
+
 // CalcNextBlockVersion calculates the expected version of the block after the
 // end of the current best chain based on the state of started and locked in
 // rule change deployments.
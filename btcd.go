@@ -18,6 +18,8 @@ import (
 	"github.com/btcsuite/btcd/blockchain/indexers"
 	"github.com/btcsuite/btcd/database"
 	"github.com/btcsuite/btcd/limits"
+	"github.com/btcsuite/btcd/verthash"
+	"github.com/btcsuite/btcd/wire"
 )
 
 const (
@@ -98,6 +100,22 @@ func btcdMain(serverChan chan<- *server) error {
 		return nil
 	}
 
+	// Load the Verthash datafile when the active network requires it so
+	// blocks mined with Verthash can be validated.
+	vhDatafile, err := loadVerthashDatafile()
+	if err != nil {
+		btcdLog.Errorf("%v", err)
+		return err
+	}
+	if vhDatafile != nil {
+		defer vhDatafile.Close()
+	}
+
+	// Return now if an interrupt signal was triggered.
+	if interruptRequested(interrupt) {
+		return nil
+	}
+
 	// Load the block database.
 	db, err := loadBlockDB()
 	if err != nil {
@@ -144,6 +162,22 @@ func btcdMain(serverChan chan<- *server) error {
 		return nil
 	}
 
+	// Drop the optional indexes so they are rebuilt along with the chain
+	// state when it is reindexed from the stored blocks.
+	//
+	// NOTE: Dropping the tx index also drops the address index since it
+	// relies on it.
+	if cfg.ReindexChainState {
+		if err := indexers.DropTxIndex(db, interrupt); err != nil {
+			btcdLog.Errorf("%v", err)
+			return err
+		}
+		if err := indexers.DropCfIndex(db, interrupt); err != nil {
+			btcdLog.Errorf("%v", err)
+			return err
+		}
+	}
+
 	// Create server and start it.
 	server, err := newServer(cfg.Listeners, cfg.AgentBlacklist,
 		cfg.AgentWhitelist, db, activeNetParams.Params, interrupt)
@@ -296,6 +330,64 @@ func loadBlockDB() (database.DB, error) {
 	return db, nil
 }
 
+// loadVerthashDatafile opens and verifies the Verthash datafile, generating it
+// first when it does not exist, and registers it as the proof-of-work hash
+// function for Verthash blocks.  Nil is returned without an error when the
+// active network never uses Verthash.
+func loadVerthashDatafile() (*verthash.Datafile, error) {
+	usesVerthash := false
+	for _, fork := range activeNetParams.PowForks {
+		if fork.Algorithm == wire.PowVerthash {
+			usesVerthash = true
+			break
+		}
+	}
+	if !usesVerthash {
+		return nil, nil
+	}
+
+	// The datafile is generated when it does not exist yet, which checks it
+	// against the known checksum, so it doesn't need to be verified again.
+	generated := false
+	if _, err := os.Stat(cfg.VerthashFile); os.IsNotExist(err) {
+		btcdLog.Infof("Generating Verthash datafile '%s' -- this takes "+
+			"a while and needs %d MiB of memory", cfg.VerthashFile,
+			verthash.DatafileSize>>20)
+		err := os.MkdirAll(filepath.Dir(cfg.VerthashFile), 0700)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create Verthash datafile "+
+				"directory: %v", err)
+		}
+		if err := verthash.Generate(cfg.VerthashFile); err != nil {
+			return nil, fmt.Errorf("unable to generate Verthash "+
+				"datafile '%s': %v", cfg.VerthashFile, err)
+		}
+		generated = true
+	}
+
+	btcdLog.Infof("Loading Verthash datafile from '%s'", cfg.VerthashFile)
+	df, err := verthash.Open(cfg.VerthashFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load Verthash datafile: %v -- "+
+			"the datafile is required to validate blocks on %s, use "+
+			"--verthashfile to specify its location", err,
+			activeNetParams.Name)
+	}
+
+	if !generated {
+		btcdLog.Infof("Verifying Verthash datafile")
+		if err := df.Verify(); err != nil {
+			df.Close()
+			return nil, fmt.Errorf("unable to verify Verthash "+
+				"datafile '%s': %v", cfg.VerthashFile, err)
+		}
+	}
+
+	wire.RegisterPowHashFunc(wire.PowVerthash, df.PowHash)
+	btcdLog.Info("Verthash datafile loaded")
+	return df, nil
+}
+
 func main() {
 	// Block and transaction processing can cause bursty allocations.  This
 	// limits the garbage collector from excessively overallocating during
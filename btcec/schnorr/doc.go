@@ -0,0 +1,23 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package schnorr implements the Schnorr signatures over secp256k1 defined in
+BIP0340 along with the key tweaking defined in BIP0341.
+
+Public keys are x-only, which means only the x coordinate of a key is
+serialized and the point with that x coordinate and an even y coordinate is
+used whenever a serialized key is parsed.  A private key is therefore negated
+when signing if its public key has an odd y coordinate.
+
+Signatures are 64 bytes consisting of the x coordinate of the nonce point
+followed by the scalar s.  The nonce is derived from the private key, the
+public key, the message and 32 bytes of auxiliary randomness as specified by
+BIP0340, so signing is deterministic for the same auxiliary randomness.
+
+Taproot outputs commit to an internal key and the root of a script tree by
+tweaking the internal key.  TweakTaprootPubKey calculates the resulting output
+key and TweakTaprootPrivKey the private key which signs for it.
+*/
+package schnorr
@@ -0,0 +1,248 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package musig2
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+)
+
+var (
+	// ErrSignerNotInKeys is returned when a context is created for a
+	// private key whose public key is not one of the aggregated keys.
+	ErrSignerNotInKeys = errors.New("signer public key is not one of " +
+		"the aggregated keys")
+
+	// ErrAlreadyHaveAllNonces is returned when a public nonce is
+	// registered with a session which already has the nonces of all
+	// signers.
+	ErrAlreadyHaveAllNonces = errors.New("already have the nonces of " +
+		"all signers")
+
+	// ErrDuplicateNonce is returned when a public nonce is registered
+	// with a session which already has it, which means a signer sent its
+	// nonce twice rather than the nonces of two signers being registered.
+	ErrDuplicateNonce = errors.New("public nonce is already registered")
+
+	// ErrMissingNonces is returned when a session is asked to sign before
+	// the nonces of all signers were registered.
+	ErrMissingNonces = errors.New("the nonces of all signers are not " +
+		"registered yet")
+
+	// ErrSigningTwice is returned when a session is asked to sign more
+	// than once, which would reuse its nonce.
+	ErrSigningTwice = errors.New("session already signed")
+
+	// ErrAlreadyHaveAllSigs is returned when a partial signature is
+	// combined with a session which already has the partial signatures
+	// of all signers.
+	ErrAlreadyHaveAllSigs = errors.New("already have the partial " +
+		"signatures of all signers")
+
+	// ErrFinalSigInvalid is returned when the aggregated signature is not
+	// valid for the aggregated key, which means one of the partial
+	// signatures was invalid.
+	ErrFinalSigInvalid = errors.New("final signature is invalid")
+)
+
+// Context houses the key material of a signer which is shared by all signing
+// sessions for the same aggregated key.
+type Context struct {
+	privKey *btcec.PrivateKey
+	pubKey  *btcec.PublicKey
+	aggKey  *AggregateKey
+	signers int
+}
+
+// NewContext returns a signing context for the passed private key, which must
+// belong to one of the passed public keys.  The public keys are aggregated in
+// the passed order and the passed tweaks are applied to the aggregated key.
+func NewContext(privKey *btcec.PrivateKey, keys []*btcec.PublicKey,
+	tweaks ...KeyTweak) (*Context, error) {
+
+	pubKey := privKey.PubKey()
+	aggKey, err := AggregateKeys(keys, tweaks...)
+	if err != nil {
+		return nil, err
+	}
+	if !aggKey.hasKey(pubKey.SerializeCompressed()) {
+		return nil, ErrSignerNotInKeys
+	}
+
+	return &Context{
+		privKey: privKey,
+		pubKey:  pubKey,
+		aggKey:  aggKey,
+		signers: len(keys),
+	}, nil
+}
+
+// AggregateKey returns the aggregated key of the context.
+func (c *Context) AggregateKey() *AggregateKey {
+	return c.aggKey
+}
+
+// PubKey returns the public key of the signer.
+func (c *Context) PubKey() *btcec.PublicKey {
+	return c.pubKey
+}
+
+// NewSession starts a signing session for the passed message with freshly
+// generated nonces.  A session signs at most once, so a new session must be
+// started for every signature.
+func (c *Context) NewSession(msg []byte) (*Session, error) {
+	nonces, err := GenNonces(c.pubKey, &NonceOptions{
+		SecretKey:    c.privKey,
+		AggregateKey: c.aggKey,
+		Msg:          msg,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		ctx:       c,
+		msg:       msg,
+		secNonce:  nonces.SecNonce,
+		pubNonce:  nonces.PubNonce,
+		pubNonces: [][PubNonceSize]byte{nonces.PubNonce},
+	}, nil
+}
+
+// Session is a single signing session of a signer.  It collects the public
+// nonces and partial signatures of the other signers and never exposes its
+// secret nonce, which is erased once the session signed.
+type Session struct {
+	ctx *Context
+	msg []byte
+
+	secNonce [SecNonceSize]byte
+	pubNonce [PubNonceSize]byte
+
+	pubNonces [][PubNonceSize]byte
+	aggNonce  *[PubNonceSize]byte
+
+	signed   bool
+	sigs     []*PartialSignature
+	finalSig *schnorr.Signature
+}
+
+// PublicNonce returns the public nonce of the session, which must be shared
+// with the other signers.
+func (s *Session) PublicNonce() [PubNonceSize]byte {
+	return s.pubNonce
+}
+
+// RegisterPubNonce registers the public nonce of another signer with the
+// session.  It returns whether the nonces of all signers are registered, at
+// which point the session is ready to sign.  A nonce which is already
+// registered, including the session's own, is rejected so a signer sending
+// its nonce twice cannot take the place of another signer.
+func (s *Session) RegisterPubNonce(nonce [PubNonceSize]byte) (bool, error) {
+	if len(s.pubNonces) == s.ctx.signers {
+		return false, ErrAlreadyHaveAllNonces
+	}
+	for _, pubNonce := range s.pubNonces {
+		if pubNonce == nonce {
+			return false, ErrDuplicateNonce
+		}
+	}
+	if _, _, err := parseNonce(nonce, false); err != nil {
+		return false, err
+	}
+
+	s.pubNonces = append(s.pubNonces, nonce)
+	if len(s.pubNonces) < s.ctx.signers {
+		return false, nil
+	}
+
+	aggNonce, err := AggregateNonces(s.pubNonces)
+	if err != nil {
+		return false, err
+	}
+	s.aggNonce = &aggNonce
+	return true, nil
+}
+
+// AggregateNonce returns the aggregated nonce of the session, or nil when the
+// nonces of all signers are not registered yet.
+func (s *Session) AggregateNonce() *[PubNonceSize]byte {
+	return s.aggNonce
+}
+
+// Sign creates the partial signature of the session, which must be shared
+// with the other signers.  It fails when the session already signed.
+func (s *Session) Sign() (*PartialSignature, error) {
+	if s.signed {
+		return nil, ErrSigningTwice
+	}
+	if s.aggNonce == nil {
+		return nil, ErrMissingNonces
+	}
+
+	// The session is marked as signed and its secret nonce is erased even
+	// when signing fails so it is never used again.
+	s.signed = true
+	sig, err := Sign(&s.secNonce, s.ctx.privKey, *s.aggNonce,
+		s.ctx.aggKey, s.msg)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sigs = append(s.sigs, sig)
+	if _, err := s.aggregateSigs(); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// CombineSig adds the partial signature of another signer to the session.  It
+// returns whether the partial signatures of all signers, including the one
+// created by the session, are combined, at which point the final signature is
+// available.
+func (s *Session) CombineSig(sig *PartialSignature) (bool, error) {
+	// Leave room for the session's own partial signature until it signed.
+	maxSigs := s.ctx.signers
+	if !s.signed {
+		maxSigs--
+	}
+	if len(s.sigs) >= maxSigs {
+		return false, ErrAlreadyHaveAllSigs
+	}
+	if s.aggNonce == nil {
+		return false, ErrMissingNonces
+	}
+
+	s.sigs = append(s.sigs, sig)
+	return s.aggregateSigs()
+}
+
+// aggregateSigs aggregates the partial signatures of the session into the
+// final signature once those of all signers, including the session's own, are
+// combined.  It returns whether the final signature is available.
+func (s *Session) aggregateSigs() (bool, error) {
+	if !s.signed || len(s.sigs) < s.ctx.signers {
+		return false, nil
+	}
+
+	finalSig, err := AggregatePartialSigs(s.sigs, *s.aggNonce,
+		s.ctx.aggKey, s.msg)
+	if err != nil {
+		return false, err
+	}
+	if !finalSig.Verify(s.msg, s.ctx.aggKey.FinalKey) {
+		return false, ErrFinalSigInvalid
+	}
+	s.finalSig = finalSig
+	return true, nil
+}
+
+// FinalSig returns the final signature of the session, or nil when the
+// partial signatures of all signers are not combined yet.
+func (s *Session) FinalSig() *schnorr.Signature {
+	return s.finalSig
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package musig2
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// TestSessions ensures signers which exchange their nonces and partial
+// signatures through sessions arrive at the same valid signature for a
+// taproot output key, in any order of combining.
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	const numSigners = 3
+	privKeys, pubKeys := testPrivKeys(numSigners)
+	keys := SortKeys(pubKeys)
+	msg := bytes.Repeat([]byte{0x42}, 32)
+	internalKey, err := AggregateKeys(keys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tweak := internalKey.TaprootKeyTweak(nil)
+
+	sessions := make([]*Session, 0, numSigners)
+	for _, privKey := range privKeys {
+		ctx, err := NewContext(privKey, keys, tweak)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		session, err := ctx.NewSession(msg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	// Exchange the public nonces.
+	for i, session := range sessions {
+		for j, other := range sessions {
+			if i == j {
+				continue
+			}
+			haveAll, err := session.RegisterPubNonce(other.PublicNonce())
+			if err != nil {
+				t.Fatalf("session #%d: unexpected error: %v", i, err)
+			}
+			wantAll := j == numSigners-1 || (i == numSigners-1 &&
+				j == numSigners-2)
+			if haveAll != wantAll {
+				t.Fatalf("session #%d: unexpected nonce status %v "+
+					"after nonce #%d", i, haveAll, j)
+			}
+		}
+		if _, err := session.RegisterPubNonce(
+			session.PublicNonce()); err != ErrAlreadyHaveAllNonces {
+
+			t.Fatalf("session #%d: unexpected error: %v", i, err)
+		}
+	}
+
+	// The last session combines the partial signatures of the others
+	// before signing itself.
+	sigs := make([]*PartialSignature, numSigners)
+	for i := 0; i < numSigners-1; i++ {
+		sig, err := sessions[i].Sign()
+		if err != nil {
+			t.Fatalf("session #%d: unexpected error: %v", i, err)
+		}
+		sigs[i] = sig
+	}
+	last := sessions[numSigners-1]
+	for i := 0; i < numSigners-1; i++ {
+		haveAll, err := last.CombineSig(sigs[i])
+		if err != nil || haveAll {
+			t.Fatalf("unexpected combine result %v: %v", haveAll, err)
+		}
+	}
+	if _, err := last.CombineSig(sigs[0]); err != ErrAlreadyHaveAllSigs {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sig, err := last.Sign()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sigs[numSigners-1] = sig
+	if last.FinalSig() == nil {
+		t.Fatal("no final signature after signing last")
+	}
+
+	// The other sessions combine the remaining partial signatures.
+	for i, session := range sessions[:numSigners-1] {
+		for j, sig := range sigs {
+			if i == j {
+				continue
+			}
+			if _, err := session.CombineSig(sig); err != nil {
+				t.Fatalf("session #%d: unexpected error: %v", i,
+					err)
+			}
+		}
+		finalSig := session.FinalSig()
+		if finalSig == nil {
+			t.Fatalf("session #%d: no final signature", i)
+		}
+		if !finalSig.IsEqual(last.FinalSig()) {
+			t.Errorf("session #%d: mismatched final signature", i)
+		}
+	}
+
+	outputKey, err := internalKey.TaprootTweak(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !last.FinalSig().Verify(msg, outputKey.FinalKey) {
+		t.Error("final signature is invalid for the output key")
+	}
+}
+
+// TestSessionErrors ensures sessions refuse to sign before all nonces are
+// registered or more than once, reject duplicate nonces and invalid partial
+// signatures and contexts reject signers outside the aggregated key.
+func TestSessionErrors(t *testing.T) {
+	t.Parallel()
+
+	privKeys, pubKeys := testPrivKeys(3)
+	msg := bytes.Repeat([]byte{0x42}, 32)
+
+	if _, err := NewContext(privKeys[2], pubKeys[:2]); err !=
+		ErrSignerNotInKeys {
+
+		t.Fatalf("NewContext: unexpected error: %v", err)
+	}
+
+	newSession := func(i int) *Session {
+		ctx, err := NewContext(privKeys[i], pubKeys[:2])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		session, err := ctx.NewSession(msg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return session
+	}
+	session, other := newSession(0), newSession(1)
+	if session.PublicNonce() == newSession(0).PublicNonce() {
+		t.Fatal("sessions reused their nonces")
+	}
+
+	// A signer sending its nonce twice must not take the place of a
+	// missing signer, and neither may the session's own nonce.
+	ctx, err := NewContext(privKeys[0], pubKeys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	threeSession, err := ctx.NewSession(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := threeSession.RegisterPubNonce(
+		threeSession.PublicNonce()); err != ErrDuplicateNonce {
+
+		t.Fatalf("RegisterPubNonce: unexpected error: %v", err)
+	}
+	if _, err := threeSession.RegisterPubNonce(
+		other.PublicNonce()); err != nil {
+
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := threeSession.RegisterPubNonce(
+		other.PublicNonce()); err != ErrDuplicateNonce {
+
+		t.Fatalf("RegisterPubNonce: unexpected error: %v", err)
+	}
+	if threeSession.AggregateNonce() != nil {
+		t.Fatal("aggregated nonce despite a missing signer")
+	}
+
+	if _, err := session.Sign(); err != ErrMissingNonces {
+		t.Fatalf("Sign: unexpected error: %v", err)
+	}
+	if _, err := session.CombineSig(&PartialSignature{
+		S: big.NewInt(1),
+	}); err != ErrMissingNonces {
+
+		t.Fatalf("CombineSig: unexpected error: %v", err)
+	}
+	if _, err := session.RegisterPubNonce(other.PublicNonce()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if session.AggregateNonce() == nil {
+		t.Fatal("no aggregated nonce after registering all nonces")
+	}
+
+	if _, err := session.Sign(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := session.Sign(); err != ErrSigningTwice {
+		t.Fatalf("Sign: unexpected error: %v", err)
+	}
+	if session.secNonce != [SecNonceSize]byte{} {
+		t.Fatal("session did not erase its secret nonce")
+	}
+
+	// An invalid partial signature of the other signer results in an
+	// invalid final signature.
+	if _, err := session.CombineSig(&PartialSignature{
+		S: big.NewInt(1),
+	}); err != ErrFinalSigInvalid {
+
+		t.Fatalf("CombineSig: unexpected error: %v", err)
+	}
+	if session.FinalSig() != nil {
+		t.Fatal("final signature despite invalid partial signature")
+	}
+}
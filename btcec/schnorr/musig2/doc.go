@@ -0,0 +1,26 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package musig2 implements the MuSig2 multi-signature scheme defined in BIP0327.
+
+MuSig2 allows a group of signers to aggregate their public keys into a single
+x-only public key and to jointly create BIP0340 Schnorr signatures which are
+valid for it.  Neither the aggregated key nor the final signature reveal that
+more than one signer was involved, so an aggregated key tweaked for taproot is
+indistinguishable from a single-sig taproot output key.
+
+Signing takes two rounds.  First, every signer generates a fresh pair of
+nonces and shares the public part with the other signers.  Once all public
+nonces are known, they are aggregated and every signer creates a partial
+signature of the message, which can be verified individually.  Finally, the
+partial signatures are aggregated into the Schnorr signature.
+
+Signing twice with the same secret nonce reveals the private key.  The stateless
+Sign function therefore erases the passed secret nonce and refuses to sign with
+an erased one.  The Context and Session types provide a higher level API which
+generates the nonces itself, never exposes the secret nonces and only allows
+every session to sign once.
+*/
+package musig2
@@ -0,0 +1,289 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package musig2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+var (
+	// tagKeyAggList is the tag used to hash the list of aggregated keys.
+	tagKeyAggList = []byte("KeyAgg list")
+
+	// tagKeyAggCoefficient is the tag used to compute the coefficient of
+	// an aggregated key.
+	tagKeyAggCoefficient = []byte("KeyAgg coefficient")
+)
+
+// isInfinity returns whether the passed affine coordinates are those of the
+// point at infinity.
+func isInfinity(x, y *big.Int) bool {
+	return x.Sign() == 0 && y.Sign() == 0
+}
+
+// scalarMult returns k*P for the passed point, which may be the point at
+// infinity, and scalar.
+func scalarMult(x, y, k *big.Int) (*big.Int, *big.Int) {
+	if isInfinity(x, y) || k.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	return btcec.S256().ScalarMult(x, y, k.Bytes())
+}
+
+// scalarBaseMult returns k*G for the passed scalar.
+func scalarBaseMult(k *big.Int) (*big.Int, *big.Int) {
+	if k.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	return btcec.S256().ScalarBaseMult(k.Bytes())
+}
+
+// negateIf returns the passed scalar negated modulo the group order when the
+// passed condition holds, and the scalar itself otherwise.
+func negateIf(k *big.Int, negate bool) *big.Int {
+	if !negate {
+		return k
+	}
+	n := btcec.S256().N
+	return new(big.Int).Mod(new(big.Int).Sub(n, k), n)
+}
+
+// parseScalar parses the passed 32 byte big-endian scalar, which must be less
+// than the group order.
+func parseScalar(b []byte) (*big.Int, error) {
+	if len(b) != 32 {
+		return nil, fmt.Errorf("invalid scalar length %d", len(b))
+	}
+	k := new(big.Int).SetBytes(b)
+	if k.Cmp(btcec.S256().N) >= 0 {
+		return nil, errors.New("scalar is not less than the group order")
+	}
+	return k, nil
+}
+
+// serializeScalar returns the 32 byte big-endian serialization of the passed
+// scalar.
+func serializeScalar(k *big.Int) []byte {
+	var b [32]byte
+	k.FillBytes(b[:])
+	return b[:]
+}
+
+// parsePubKey parses the passed compressed public key, whose x coordinate must
+// be less than the field size.
+func parsePubKey(b []byte) (*btcec.PublicKey, error) {
+	if len(b) != btcec.PubKeyBytesLenCompressed {
+		return nil, fmt.Errorf("invalid compressed key length %d", len(b))
+	}
+	curve := btcec.S256()
+	if new(big.Int).SetBytes(b[1:]).Cmp(curve.P) >= 0 {
+		return nil, errors.New("x coordinate exceeds the field size")
+	}
+	return btcec.ParsePubKey(b, curve)
+}
+
+// SortKeys returns the passed public keys sorted by their compressed
+// serialization, which makes the aggregated key independent of the order the
+// signers learned the keys in.
+func SortKeys(keys []*btcec.PublicKey) []*btcec.PublicKey {
+	sorted := make([]*btcec.PublicKey, len(keys))
+	copy(sorted, keys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].SerializeCompressed(),
+			sorted[j].SerializeCompressed()) < 0
+	})
+	return sorted
+}
+
+// KeyTweak is a tweak which is added to an aggregated key.
+type KeyTweak struct {
+	// Tweak is the 32 byte big-endian scalar which is added to the key.
+	Tweak [32]byte
+
+	// IsXOnly is whether the tweak is applied to the x-only key, which is
+	// the case for BIP0341 taproot tweaks, rather than to the plain key,
+	// which is the case for BIP0032 derivation.
+	IsXOnly bool
+}
+
+// AggregateKey is the result of aggregating public keys along with the state
+// needed to sign for it.
+type AggregateKey struct {
+	// FinalKey is the aggregated key after all tweaks were applied.  Its
+	// x-only serialization is the key signatures are valid for.
+	FinalKey *btcec.PublicKey
+
+	// PreTweakedKey is the aggregated key before any tweaks were applied.
+	PreTweakedKey *btcec.PublicKey
+
+	// keys are the compressed serializations of the aggregated keys in the
+	// order they were aggregated in.
+	keys [][]byte
+
+	// keysHash is the hash of the list of aggregated keys and secondKey
+	// is the first key which differs from the first one, whose
+	// coefficient is one.
+	keysHash  []byte
+	secondKey []byte
+
+	// gacc and tacc accumulate the negations and tweaks applied to the
+	// key, which are needed to sign for the final key.
+	gacc *big.Int
+	tacc *big.Int
+}
+
+// coefficient returns the coefficient of the passed compressed public key in
+// the aggregated key.
+func (k *AggregateKey) coefficient(pubKey []byte) *big.Int {
+	if bytes.Equal(pubKey, k.secondKey) {
+		return big.NewInt(1)
+	}
+	hash := chainhash.TaggedHash(tagKeyAggCoefficient, k.keysHash, pubKey)
+	a := new(big.Int).SetBytes(hash[:])
+	return a.Mod(a, btcec.S256().N)
+}
+
+// hasKey returns whether the passed compressed public key is one of the
+// aggregated keys.
+func (k *AggregateKey) hasKey(pubKey []byte) bool {
+	for _, key := range k.keys {
+		if bytes.Equal(key, pubKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregateKeys aggregates the passed compressed public keys, in the passed
+// order, into a single key.
+func aggregateKeys(keys [][]byte) (*AggregateKey, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no keys to aggregate")
+	}
+
+	// The second key is the first key which differs from the first one,
+	// or 33 zero bytes when all keys are equal.
+	secondKey := make([]byte, btcec.PubKeyBytesLenCompressed)
+	for _, key := range keys[1:] {
+		if !bytes.Equal(key, keys[0]) {
+			secondKey = key
+			break
+		}
+	}
+
+	aggKey := &AggregateKey{
+		keys:      keys,
+		keysHash:  chainhash.TaggedHash(tagKeyAggList, keys...)[:],
+		secondKey: secondKey,
+		gacc:      big.NewInt(1),
+		tacc:      new(big.Int),
+	}
+
+	// Q = a_1*P_1 + ... + a_u*P_u
+	curve := btcec.S256()
+	qx, qy := new(big.Int), new(big.Int)
+	for i, key := range keys {
+		pubKey, err := parsePubKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key #%d: %v", i, err)
+		}
+		px, py := scalarMult(pubKey.X, pubKey.Y,
+			aggKey.coefficient(key))
+		qx, qy = curve.Add(qx, qy, px, py)
+	}
+	if isInfinity(qx, qy) {
+		return nil, errors.New("aggregated key is the point at infinity")
+	}
+
+	aggKey.PreTweakedKey = &btcec.PublicKey{Curve: curve, X: qx, Y: qy}
+	aggKey.FinalKey = aggKey.PreTweakedKey
+	return aggKey, nil
+}
+
+// AggregateKeys aggregates the passed public keys, in the passed order, into a
+// single key and applies the passed tweaks to it in order.  Use SortKeys to
+// make the result independent of the order of the keys.
+func AggregateKeys(keys []*btcec.PublicKey,
+	tweaks ...KeyTweak) (*AggregateKey, error) {
+
+	serialized := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		serialized = append(serialized, key.SerializeCompressed())
+	}
+	aggKey, err := aggregateKeys(serialized)
+	if err != nil {
+		return nil, err
+	}
+	for _, tweak := range tweaks {
+		aggKey, err = aggKey.Tweak(tweak)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return aggKey, nil
+}
+
+// Tweak returns the aggregated key with the passed tweak applied.  The key
+// itself is not modified.
+func (k *AggregateKey) Tweak(tweak KeyTweak) (*AggregateKey, error) {
+	t, err := parseScalar(tweak.Tweak[:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid tweak: %v", err)
+	}
+
+	// Q' = g*Q + t*G where g negates the key when the tweak applies to the
+	// x-only key and the y coordinate of the key is odd.
+	curve := btcec.S256()
+	negate := tweak.IsXOnly && k.FinalKey.Y.Bit(0) == 1
+	g := negateIf(big.NewInt(1), negate)
+	qx, qy := scalarMult(k.FinalKey.X, k.FinalKey.Y, g)
+	tx, ty := scalarBaseMult(t)
+	qx, qy = curve.Add(qx, qy, tx, ty)
+	if isInfinity(qx, qy) {
+		return nil, errors.New("tweaked key is the point at infinity")
+	}
+
+	// gacc' = g*gacc mod n and tacc' = t + g*tacc mod n
+	tweaked := *k
+	tweaked.FinalKey = &btcec.PublicKey{Curve: curve, X: qx, Y: qy}
+	tweaked.gacc = negateIf(k.gacc, negate)
+	tweaked.tacc = negateIf(k.tacc, negate)
+	tweaked.tacc = new(big.Int).Add(tweaked.tacc, t)
+	tweaked.tacc.Mod(tweaked.tacc, curve.N)
+	return &tweaked, nil
+}
+
+// TaprootKeyTweak returns the BIP0341 tweak for a taproot output with the final
+// key as its internal key and the passed root hash of its script tree, which is
+// nil for outputs without a script tree.  It can be passed to NewContext along
+// with the tweaks applied to the key so far.
+func (k *AggregateKey) TaprootKeyTweak(scriptRoot []byte) KeyTweak {
+	tweak := KeyTweak{IsXOnly: true}
+	hash := chainhash.TaggedHash(chainhash.TagTapTweak, k.XOnlyKey(),
+		scriptRoot)
+	copy(tweak.Tweak[:], hash[:])
+	return tweak
+}
+
+// TaprootTweak returns the aggregated key with the tweak returned by
+// TaprootKeyTweak applied.  The final key of the result is the output key of
+// the taproot output.
+func (k *AggregateKey) TaprootTweak(scriptRoot []byte) (*AggregateKey, error) {
+	return k.Tweak(k.TaprootKeyTweak(scriptRoot))
+}
+
+// XOnlyKey returns the x-only serialization of the final key, which is the key
+// signatures created with the aggregated key are valid for.
+func (k *AggregateKey) XOnlyKey() []byte {
+	return schnorr.SerializePubKey(k.FinalKey)
+}
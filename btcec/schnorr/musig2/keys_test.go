@@ -0,0 +1,283 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package musig2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+)
+
+// decodeHex decodes the passed hex string and returns the resulting bytes.  It
+// panics if an error occurs.  This is only used in the tests as a helper since
+// the only way it can fail is if there is an error in the test source code.
+func decodeHex(hexStr string) []byte {
+	b, err := hex.DecodeString(hexStr)
+	if err != nil {
+		panic("invalid hex string in test source: err " + err.Error() +
+			", hex: " + hexStr)
+	}
+
+	return b
+}
+
+// testPrivKeys returns the passed number of distinct private keys along with
+// their public keys.
+func testPrivKeys(num int) ([]*btcec.PrivateKey, []*btcec.PublicKey) {
+	privKeys := make([]*btcec.PrivateKey, 0, num)
+	pubKeys := make([]*btcec.PublicKey, 0, num)
+	for i := 0; i < num; i++ {
+		var keyBytes [32]byte
+		keyBytes[0] = 0x55
+		keyBytes[31] = byte(i + 1)
+		privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(),
+			keyBytes[:])
+		privKeys = append(privKeys, privKey)
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return privKeys, pubKeys
+}
+
+// vectorError is the expected error of a BIP0327 test vector.  Signer is the
+// index of the signer whose contribution is invalid, when the error is caused
+// by an invalid contribution of a single signer.
+type vectorError struct {
+	Type    string `json:"type"`
+	Signer  *int   `json:"signer"`
+	Contrib string `json:"contrib"`
+	Message string `json:"message"`
+}
+
+// checkVectorError returns an error when the passed error is nil or, when the
+// expected error blames a single signer, does not name that signer by the
+// passed index prefix, such as "key #".
+func checkVectorError(err error, want vectorError, prefix string) error {
+	if err == nil {
+		return fmt.Errorf("no error, want %s error", want.Type)
+	}
+	if want.Type != "invalid_contribution" || want.Signer == nil ||
+		prefix == "" {
+
+		return nil
+	}
+	blamed := fmt.Sprintf("%s%d", prefix, *want.Signer)
+	if !strings.Contains(err.Error(), blamed) {
+		return fmt.Errorf("error %q does not blame %s", err, blamed)
+	}
+	return nil
+}
+
+// loadVectors decodes the BIP0327 test vectors in the passed file of the
+// testdata directory into the passed value.
+func loadVectors(t *testing.T, filename string, vectors interface{}) {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+	if err != nil {
+		t.Fatalf("unable to read test vectors: %v", err)
+	}
+	if err := json.Unmarshal(data, vectors); err != nil {
+		t.Fatalf("unable to decode test vectors %s: %v", filename, err)
+	}
+}
+
+// selectHex returns the decoded hex strings at the passed indices.
+func selectHex(hexStrs []string, indices []int) [][]byte {
+	selected := make([][]byte, 0, len(indices))
+	for _, i := range indices {
+		selected = append(selected, decodeHex(hexStrs[i]))
+	}
+	return selected
+}
+
+// tweakKey aggregates the passed compressed public keys and applies the
+// tweaks at the passed indices, which are x-only tweaks according to the
+// passed flags.
+func tweakKey(keys [][]byte, tweaks []string, indices []int,
+	isXOnly []bool) (*AggregateKey, error) {
+
+	aggKey, err := aggregateKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, tweakIdx := range indices {
+		tweak := KeyTweak{IsXOnly: isXOnly[i]}
+		copy(tweak.Tweak[:], decodeHex(tweaks[tweakIdx]))
+		aggKey, err = aggKey.Tweak(tweak)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return aggKey, nil
+}
+
+// TestAggregateKeys ensures keys are aggregated according to the key
+// aggregation test vectors from BIP0327 and invalid keys and tweaks are
+// rejected.
+func TestAggregateKeys(t *testing.T) {
+	t.Parallel()
+
+	var vectors struct {
+		PubKeys    []string `json:"pubkeys"`
+		Tweaks     []string `json:"tweaks"`
+		ValidTests []struct {
+			KeyIndices []int  `json:"key_indices"`
+			Expected   string `json:"expected"`
+		} `json:"valid_test_cases"`
+		ErrorTests []struct {
+			KeyIndices   []int       `json:"key_indices"`
+			TweakIndices []int       `json:"tweak_indices"`
+			IsXOnly      []bool      `json:"is_xonly"`
+			Error        vectorError `json:"error"`
+			Comment      string      `json:"comment"`
+		} `json:"error_test_cases"`
+	}
+	loadVectors(t, "key_agg_vectors.json", &vectors)
+
+	for i, test := range vectors.ValidTests {
+		aggKey, err := aggregateKeys(selectHex(vectors.PubKeys,
+			test.KeyIndices))
+		if err != nil {
+			t.Errorf("valid #%d: unexpected error: %v", i, err)
+			continue
+		}
+		if got := aggKey.XOnlyKey(); !bytes.Equal(got,
+			decodeHex(test.Expected)) {
+
+			t.Errorf("valid #%d: mismatched aggregated key - got "+
+				"%X, want %s", i, got, test.Expected)
+		}
+		if !aggKey.PreTweakedKey.IsEqual(aggKey.FinalKey) {
+			t.Errorf("valid #%d: untweaked final key differs from "+
+				"the pre-tweaked key", i)
+		}
+	}
+
+	for i, test := range vectors.ErrorTests {
+		_, err := tweakKey(selectHex(vectors.PubKeys, test.KeyIndices),
+			vectors.Tweaks, test.TweakIndices, test.IsXOnly)
+		err = checkVectorError(err, test.Error, "public key #")
+		if err != nil {
+			t.Errorf("error #%d (%s): %v", i, test.Comment, err)
+		}
+	}
+
+	if _, err := AggregateKeys(nil); err == nil {
+		t.Error("AggregateKeys: no error for empty key list")
+	}
+}
+
+// TestSortKeys ensures keys are sorted according to the key sorting test
+// vectors from BIP0327, sorting keys makes the aggregated key independent of
+// the order of the keys and the passed keys are left unmodified.
+func TestSortKeys(t *testing.T) {
+	t.Parallel()
+
+	var vectors struct {
+		PubKeys       []string `json:"pubkeys"`
+		SortedPubKeys []string `json:"sorted_pubkeys"`
+	}
+	loadVectors(t, "key_sort_vectors.json", &vectors)
+	vectorKeys := make([]*btcec.PublicKey, 0, len(vectors.PubKeys))
+	for _, pubKey := range vectors.PubKeys {
+		key, err := btcec.ParsePubKey(decodeHex(pubKey), btcec.S256())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		vectorKeys = append(vectorKeys, key)
+	}
+	for i, key := range SortKeys(vectorKeys) {
+		want := decodeHex(vectors.SortedPubKeys[i])
+		if got := key.SerializeCompressed(); !bytes.Equal(got, want) {
+			t.Errorf("mismatched sorted vector key #%d - got %X, "+
+				"want %X", i, got, want)
+		}
+	}
+
+	_, keys := testPrivKeys(4)
+	reversed := []*btcec.PublicKey{keys[3], keys[2], keys[1], keys[0]}
+
+	sortedKeys := SortKeys(keys)
+	sortedReversed := SortKeys(reversed)
+	for i := range sortedKeys {
+		if !sortedKeys[i].IsEqual(sortedReversed[i]) {
+			t.Fatalf("mismatched sorted key #%d", i)
+		}
+		if i > 0 && bytes.Compare(sortedKeys[i-1].SerializeCompressed(),
+			sortedKeys[i].SerializeCompressed()) > 0 {
+
+			t.Fatalf("key #%d is not sorted", i)
+		}
+	}
+	if !reversed[0].IsEqual(keys[3]) {
+		t.Fatal("SortKeys modified the passed keys")
+	}
+
+	aggKey, err := AggregateKeys(sortedKeys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reversedAggKey, err := AggregateKeys(sortedReversed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !aggKey.FinalKey.IsEqual(reversedAggKey.FinalKey) {
+		t.Error("aggregated keys of sorted keys differ")
+	}
+}
+
+// TestTweak ensures taproot tweaks of aggregated keys match the output keys of
+// single keys, tweaks leave the tweaked key unmodified and tweaks which are
+// not valid scalars are rejected.
+func TestTweak(t *testing.T) {
+	t.Parallel()
+
+	_, keys := testPrivKeys(3)
+	scriptRoot := bytes.Repeat([]byte{0x01}, 32)
+	for _, root := range [][]byte{nil, scriptRoot} {
+		aggKey, err := AggregateKeys(keys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		untweaked := aggKey.FinalKey
+
+		tweaked, err := aggKey.TaprootTweak(root)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		outputKey, err := schnorr.TweakTaprootPubKey(aggKey.FinalKey,
+			root)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(tweaked.XOnlyKey(),
+			schnorr.SerializePubKey(outputKey)) {
+
+			t.Errorf("mismatched output key for script root %x",
+				root)
+		}
+		if !aggKey.FinalKey.IsEqual(untweaked) {
+			t.Error("TaprootTweak modified the tweaked key")
+		}
+		if !tweaked.PreTweakedKey.IsEqual(untweaked) {
+			t.Error("tweaked key lost its pre-tweaked key")
+		}
+	}
+
+	var tweak KeyTweak
+	copy(tweak.Tweak[:], decodeHex(strings.Repeat("FF", 32)))
+	if _, err := AggregateKeys(keys, tweak); err == nil {
+		t.Error("AggregateKeys: no error for tweak exceeding the " +
+			"group order")
+	}
+}
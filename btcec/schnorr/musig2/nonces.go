@@ -0,0 +1,240 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package musig2
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	// PubNonceSize is the size of a serialized public nonce, which is the
+	// two compressed nonce points.  Aggregated nonces have the same size.
+	PubNonceSize = 2 * btcec.PubKeyBytesLenCompressed
+
+	// SecNonceSize is the size of a serialized secret nonce, which is the
+	// two nonce scalars followed by the compressed public key of the
+	// signer.
+	SecNonceSize = 2*32 + btcec.PubKeyBytesLenCompressed
+)
+
+var (
+	// tagAux is the tag used to hash the randomness which masks the
+	// secret key during nonce generation.
+	tagAux = []byte("MuSig/aux")
+
+	// tagNonce is the tag used to derive the secret nonces.
+	tagNonce = []byte("MuSig/nonce")
+)
+
+// Nonces is a pair of nonces generated for a single signing session.
+type Nonces struct {
+	// PubNonce is the public nonce, which is shared with the other
+	// signers.
+	PubNonce [PubNonceSize]byte
+
+	// SecNonce is the secret nonce, which must be kept secret and must
+	// only be used to sign once.
+	SecNonce [SecNonceSize]byte
+}
+
+// NonceOptions houses the optional inputs to nonce generation.  None of them
+// are required for security when fresh randomness is available, but every
+// provided input makes the nonces more resistant to a broken random number
+// generator.
+type NonceOptions struct {
+	// SecretKey is the private key the nonces will be used with.
+	SecretKey *btcec.PrivateKey
+
+	// AggregateKey is the aggregated key the nonces will be used with.
+	AggregateKey *AggregateKey
+
+	// Msg is the message the nonces will be used to sign.  A nil message
+	// is not committed to, while an empty one is.
+	Msg []byte
+
+	// ExtraIn is arbitrary extra input, such as a session identifier or
+	// a counter.
+	ExtraIn []byte
+
+	// Rand is the source of randomness.  It defaults to crypto/rand.
+	Rand io.Reader
+}
+
+// serializePoint returns the compressed serialization of the passed point, or
+// 33 zero bytes for the point at infinity.
+func serializePoint(x, y *big.Int) []byte {
+	b := make([]byte, btcec.PubKeyBytesLenCompressed)
+	if isInfinity(x, y) {
+		return b
+	}
+	b[0] = 0x02 | byte(y.Bit(0))
+	x.FillBytes(b[1:])
+	return b
+}
+
+// parsePoint parses the passed compressed point.  33 zero bytes are parsed as
+// the point at infinity when the passed flag is set.
+func parsePoint(b []byte, allowInfinity bool) (*big.Int, *big.Int, error) {
+	if allowInfinity && isZero(b) {
+		return new(big.Int), new(big.Int), nil
+	}
+	pubKey, err := parsePubKey(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pubKey.X, pubKey.Y, nil
+}
+
+// parseNonce parses the two points of the passed public or aggregated nonce.
+func parseNonce(nonce [PubNonceSize]byte, allowInfinity bool) ([2]*big.Int,
+	[2]*big.Int, error) {
+
+	var xs, ys [2]*big.Int
+	for i := range xs {
+		var err error
+		xs[i], ys[i], err = parsePoint(nonce[i*33:(i+1)*33],
+			allowInfinity)
+		if err != nil {
+			return xs, ys, fmt.Errorf("invalid nonce point %d: %v",
+				i+1, err)
+		}
+	}
+	return xs, ys, nil
+}
+
+// isZero returns whether all passed bytes are zero.
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// genNonces derives the nonces for the passed compressed public key from the
+// passed 32 bytes of randomness and the optional secret key, x-only aggregated
+// key, message and extra input, which are nil when absent.
+func genNonces(pubKey, randBytes, secretKey, aggPubKey, msg,
+	extraIn []byte) (*Nonces, error) {
+
+	// Mask the secret key with the randomness when it is known.
+	seed := make([]byte, 32)
+	copy(seed, randBytes)
+	if secretKey != nil {
+		hash := chainhash.TaggedHash(tagAux, randBytes)
+		copy(seed, secretKey)
+		for i := range seed {
+			seed[i] ^= hash[i]
+		}
+	}
+
+	// The message is prefixed so an absent message differs from an empty
+	// one.
+	msgPrefixed := []byte{0x00}
+	if msg != nil {
+		msgPrefixed = make([]byte, 9, 9+len(msg))
+		msgPrefixed[0] = 0x01
+		binary.BigEndian.PutUint64(msgPrefixed[1:], uint64(len(msg)))
+		msgPrefixed = append(msgPrefixed, msg...)
+	}
+	var extraInLen [4]byte
+	binary.BigEndian.PutUint32(extraInLen[:], uint32(len(extraIn)))
+
+	// k_i = int(hash_MuSig/nonce(rand || len(pk) || pk || len(aggpk) ||
+	// aggpk || m_prefixed || len(extra_in) || extra_in || i - 1)) mod n
+	var nonces Nonces
+	curve := btcec.S256()
+	for i := 0; i < 2; i++ {
+		hash := chainhash.TaggedHash(tagNonce, seed,
+			[]byte{byte(len(pubKey))}, pubKey,
+			[]byte{byte(len(aggPubKey))}, aggPubKey, msgPrefixed,
+			extraInLen[:], extraIn, []byte{byte(i)})
+		k := new(big.Int).SetBytes(hash[:])
+		k.Mod(k, curve.N)
+		if k.Sign() == 0 {
+			return nil, errors.New("derived nonce is zero")
+		}
+
+		rx, ry := scalarBaseMult(k)
+		copy(nonces.SecNonce[i*32:], serializeScalar(k))
+		copy(nonces.PubNonce[i*33:], serializePoint(rx, ry))
+	}
+	copy(nonces.SecNonce[64:], pubKey)
+	return &nonces, nil
+}
+
+// GenNonces generates a fresh pair of nonces for the signer with the passed
+// public key.  The options may be nil.
+//
+// The secret nonce must be used to sign at most once since signing twice with
+// it reveals the private key.  In particular, it must not be persisted and
+// restored.
+func GenNonces(pubKey *btcec.PublicKey, opts *NonceOptions) (*Nonces, error) {
+	if opts == nil {
+		opts = &NonceOptions{}
+	}
+	randReader := opts.Rand
+	if randReader == nil {
+		randReader = rand.Reader
+	}
+	var randBytes [32]byte
+	if _, err := io.ReadFull(randReader, randBytes[:]); err != nil {
+		return nil, fmt.Errorf("unable to read randomness: %v", err)
+	}
+	if opts.SecretKey != nil && !opts.SecretKey.PubKey().IsEqual(pubKey) {
+		return nil, errors.New("secret key does not belong to the " +
+			"public key")
+	}
+
+	var secretKey, aggPubKey []byte
+	if opts.SecretKey != nil {
+		secretKey = serializeScalar(opts.SecretKey.D)
+	}
+	if opts.AggregateKey != nil {
+		aggPubKey = opts.AggregateKey.XOnlyKey()
+	}
+	return genNonces(pubKey.SerializeCompressed(), randBytes[:], secretKey,
+		aggPubKey, opts.Msg, opts.ExtraIn)
+}
+
+// AggregateNonces aggregates the public nonces of all signers into the
+// aggregated nonce which is needed to create and verify partial signatures.
+func AggregateNonces(pubNonces [][PubNonceSize]byte) ([PubNonceSize]byte,
+	error) {
+
+	var aggNonce [PubNonceSize]byte
+	if len(pubNonces) == 0 {
+		return aggNonce, errors.New("no nonces to aggregate")
+	}
+
+	// R_j = R_1,j + ... + R_u,j
+	curve := btcec.S256()
+	xs := [2]*big.Int{new(big.Int), new(big.Int)}
+	ys := [2]*big.Int{new(big.Int), new(big.Int)}
+	for i, pubNonce := range pubNonces {
+		nonceXs, nonceYs, err := parseNonce(pubNonce, false)
+		if err != nil {
+			return aggNonce, fmt.Errorf("invalid public nonce #%d: %v",
+				i, err)
+		}
+		for j := range xs {
+			xs[j], ys[j] = curve.Add(xs[j], ys[j], nonceXs[j],
+				nonceYs[j])
+		}
+	}
+	for j := range xs {
+		copy(aggNonce[j*33:], serializePoint(xs[j], ys[j]))
+	}
+	return aggNonce, nil
+}
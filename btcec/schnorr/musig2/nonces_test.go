@@ -0,0 +1,304 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package musig2
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// TestGenNonces ensures the public nonces belong to the secret nonces, the
+// optional inputs are committed to and a secret key for another public key is
+// rejected.
+func TestGenNonces(t *testing.T) {
+	t.Parallel()
+
+	privKeys, pubKeys := testPrivKeys(2)
+	aggKey, err := AggregateKeys(pubKeys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts *NonceOptions
+	}{{
+		name: "no options",
+	}, {
+		name: "secret key",
+		opts: &NonceOptions{SecretKey: privKeys[0]},
+	}, {
+		name: "all options",
+		opts: &NonceOptions{
+			SecretKey:    privKeys[0],
+			AggregateKey: aggKey,
+			Msg:          bytes.Repeat([]byte{0x01}, 32),
+			ExtraIn:      []byte{0x08},
+		},
+	}}
+
+	for _, test := range tests {
+		nonces, err := GenNonces(pubKeys[0], test.opts)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		k1, err := parseScalar(nonces.SecNonce[:32])
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		k2, err := parseScalar(nonces.SecNonce[32:64])
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		pubNonce := pubNonceFromSecNonce([2]*big.Int{k1, k2})
+		if pubNonce != nonces.PubNonce {
+			t.Errorf("%s: public nonce does not belong to the "+
+				"secret nonce", test.name)
+		}
+		if !bytes.Equal(nonces.SecNonce[64:],
+			pubKeys[0].SerializeCompressed()) {
+
+			t.Errorf("%s: secret nonce does not commit to the "+
+				"public key", test.name)
+		}
+
+		otherNonces, err := GenNonces(pubKeys[0], test.opts)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if otherNonces.PubNonce == nonces.PubNonce {
+			t.Errorf("%s: nonces were reused", test.name)
+		}
+	}
+
+	// Every input changes the derived nonces, and an absent message
+	// differs from an empty one.
+	pubKey := pubKeys[0].SerializeCompressed()
+	randBytes := bytes.Repeat([]byte{0x42}, 32)
+	inputs := []struct {
+		rand      []byte
+		secretKey []byte
+		aggPubKey []byte
+		msg       []byte
+		extraIn   []byte
+	}{
+		{rand: randBytes},
+		{rand: bytes.Repeat([]byte{0x43}, 32)},
+		{rand: randBytes, secretKey: serializeScalar(privKeys[0].D)},
+		{rand: randBytes, aggPubKey: aggKey.XOnlyKey()},
+		{rand: randBytes, msg: []byte{}},
+		{rand: randBytes, msg: []byte{0x01}},
+		{rand: randBytes, extraIn: []byte{0x08}},
+	}
+	seen := make(map[[PubNonceSize]byte]int)
+	for i, in := range inputs {
+		nonces, err := genNonces(pubKey, in.rand, in.secretKey,
+			in.aggPubKey, in.msg, in.extraIn)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		again, err := genNonces(pubKey, in.rand, in.secretKey,
+			in.aggPubKey, in.msg, in.extraIn)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if *again != *nonces {
+			t.Errorf("#%d: nonce derivation is not deterministic", i)
+		}
+		if j, ok := seen[nonces.PubNonce]; ok {
+			t.Errorf("#%d: same nonces as #%d", i, j)
+		}
+		seen[nonces.PubNonce] = i
+	}
+
+	if _, err := GenNonces(pubKeys[1], &NonceOptions{
+		SecretKey: privKeys[0],
+	}); err == nil {
+		t.Error("GenNonces: no error for secret key of another " +
+			"public key")
+	}
+}
+
+// TestAggregateNonces ensures public nonces are aggregated according to the
+// nonces of the signing test vectors from BIP0327, nonces which cancel out are
+// aggregated to the point at infinity and invalid nonces are rejected.
+func TestAggregateNonces(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		pubNonces []string
+		aggNonce  string
+		valid     bool
+	}{{
+		name: "BIP0327 signing vector nonces",
+		pubNonces: []string{
+			"0337C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA" +
+				"0287BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+			"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798" +
+				"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+			"032DE2662628C90B03F5E720284EB52FF7D71F4284F627B68A853D78C78E1FFE93" +
+				"03E4C5524E83FFE1493B9077CF1CA6BEB2090C93D930321071AD40B2F44E599046",
+		},
+		aggNonce: "028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61" +
+			"037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9",
+		valid: true,
+	}, {
+		name: "points cancel out",
+		pubNonces: []string{
+			"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798" +
+				"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+			"0379BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798" +
+				"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		},
+		aggNonce: "000000000000000000000000000000000000000000000000000000000000000000" +
+			"02C6047F9441ED7D6D3045406E95C07CD85C778E4B8CEF3CA7ABAC09B95C709EE5",
+		valid: true,
+	}, {
+		name: "point at infinity",
+		pubNonces: []string{
+			"000000000000000000000000000000000000000000000000000000000000000000" +
+				"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		},
+	}, {
+		name: "coordinate exceeds the field size",
+		pubNonces: []string{
+			"02FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30" +
+				"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		},
+	}}
+
+	for _, test := range tests {
+		pubNonces := make([][PubNonceSize]byte, 0, len(test.pubNonces))
+		for _, pubNonce := range test.pubNonces {
+			var nonce [PubNonceSize]byte
+			copy(nonce[:], decodeHex(pubNonce))
+			pubNonces = append(pubNonces, nonce)
+		}
+
+		aggNonce, err := AggregateNonces(pubNonces)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: no error for invalid nonces",
+					test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !bytes.Equal(aggNonce[:], decodeHex(test.aggNonce)) {
+			t.Errorf("%s: mismatched aggregated nonce - got %X, "+
+				"want %s", test.name, aggNonce, test.aggNonce)
+		}
+	}
+
+	if _, err := AggregateNonces(nil); err == nil {
+		t.Error("AggregateNonces: no error for empty nonce list")
+	}
+}
+
+// optionalHex decodes the passed optional hex string of a test vector, which
+// is nil when absent.  An empty string decodes to an empty, non-nil slice.
+func optionalHex(hexStr *string) []byte {
+	if hexStr == nil {
+		return nil
+	}
+	return append([]byte{}, decodeHex(*hexStr)...)
+}
+
+// TestNonceGenVectors ensures nonces are derived according to the nonce
+// generation test vectors from BIP0327.
+func TestNonceGenVectors(t *testing.T) {
+	t.Parallel()
+
+	var vectors struct {
+		Tests []struct {
+			Rand      string  `json:"rand_"`
+			SecretKey *string `json:"sk"`
+			PubKey    string  `json:"pk"`
+			AggPubKey *string `json:"aggpk"`
+			Msg       *string `json:"msg"`
+			ExtraIn   *string `json:"extra_in"`
+			Expected  string  `json:"expected"`
+		} `json:"test_cases"`
+	}
+	loadVectors(t, "nonce_gen_vectors.json", &vectors)
+
+	for i, test := range vectors.Tests {
+		nonces, err := genNonces(decodeHex(test.PubKey),
+			decodeHex(test.Rand), optionalHex(test.SecretKey),
+			optionalHex(test.AggPubKey), optionalHex(test.Msg),
+			optionalHex(test.ExtraIn))
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if !bytes.Equal(nonces.SecNonce[:], decodeHex(test.Expected)) {
+			t.Errorf("#%d: mismatched secret nonce - got %X, want %s",
+				i, nonces.SecNonce, test.Expected)
+		}
+	}
+}
+
+// TestNonceAggVectors ensures public nonces are aggregated according to the
+// nonce aggregation test vectors from BIP0327 and the signer of an invalid
+// public nonce is blamed.
+func TestNonceAggVectors(t *testing.T) {
+	t.Parallel()
+
+	var vectors struct {
+		PubNonces  []string `json:"pnonces"`
+		ValidTests []struct {
+			PubNonceIndices []int  `json:"pnonce_indices"`
+			Expected        string `json:"expected"`
+		} `json:"valid_test_cases"`
+		ErrorTests []struct {
+			PubNonceIndices []int       `json:"pnonce_indices"`
+			Error           vectorError `json:"error"`
+			Comment         string      `json:"comment"`
+		} `json:"error_test_cases"`
+	}
+	loadVectors(t, "nonce_agg_vectors.json", &vectors)
+
+	for i, test := range vectors.ValidTests {
+		aggNonce, err := AggregateNonces(selectNonces(vectors.PubNonces,
+			test.PubNonceIndices))
+		if err != nil {
+			t.Errorf("valid #%d: unexpected error: %v", i, err)
+			continue
+		}
+		if !bytes.Equal(aggNonce[:], decodeHex(test.Expected)) {
+			t.Errorf("valid #%d: mismatched aggregated nonce - got "+
+				"%X, want %s", i, aggNonce, test.Expected)
+		}
+	}
+
+	for i, test := range vectors.ErrorTests {
+		_, err := AggregateNonces(selectNonces(vectors.PubNonces,
+			test.PubNonceIndices))
+		err = checkVectorError(err, test.Error, "public nonce #")
+		if err != nil {
+			t.Errorf("error #%d (%s): %v", i, test.Comment, err)
+		}
+	}
+}
+
+// selectNonces returns the decoded public nonces at the passed indices.
+func selectNonces(hexStrs []string, indices []int) [][PubNonceSize]byte {
+	nonces := make([][PubNonceSize]byte, 0, len(indices))
+	for _, nonce := range selectHex(hexStrs, indices) {
+		var pubNonce [PubNonceSize]byte
+		copy(pubNonce[:], nonce)
+		nonces = append(nonces, pubNonce)
+	}
+	return nonces
+}
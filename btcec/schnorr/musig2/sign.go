@@ -0,0 +1,245 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package musig2
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// PartialSignatureSize is the size of a serialized partial signature.
+const PartialSignatureSize = 32
+
+// tagNonceCoef is the tag used to compute the coefficient of the second
+// aggregated nonce point.
+var tagNonceCoef = []byte("MuSig/noncecoef")
+
+// PartialSignature is the signature of a single signer, which is aggregated
+// with the partial signatures of the other signers into the final signature.
+type PartialSignature struct {
+	S *big.Int
+}
+
+// ParsePartialSignature parses a 32 byte partial signature.
+func ParsePartialSignature(sig []byte) (*PartialSignature, error) {
+	s, err := parseScalar(sig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid partial signature: %v", err)
+	}
+	return &PartialSignature{S: s}, nil
+}
+
+// Serialize returns the 32 byte serialization of the partial signature.
+func (sig *PartialSignature) Serialize() []byte {
+	return serializeScalar(sig.S)
+}
+
+// sessionValues houses the values derived from the aggregated nonce, the
+// aggregated key and the message which are shared by all signers.
+type sessionValues struct {
+	b      *big.Int
+	rx, ry *big.Int
+	e      *big.Int
+}
+
+// computeSessionValues derives the session values for the passed aggregated
+// nonce, aggregated key and message.
+func computeSessionValues(aggNonce [PubNonceSize]byte, aggKey *AggregateKey,
+	msg []byte) (*sessionValues, error) {
+
+	xs, ys, err := parseNonce(aggNonce, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid aggregated nonce: %v", err)
+	}
+
+	// b = int(hash_MuSig/noncecoef(aggnonce || xbytes(Q) || m)) mod n
+	curve := btcec.S256()
+	aggPubKey := aggKey.XOnlyKey()
+	hash := chainhash.TaggedHash(tagNonceCoef, aggNonce[:], aggPubKey, msg)
+	b := new(big.Int).SetBytes(hash[:])
+	b.Mod(b, curve.N)
+
+	// R = R_1 + b*R_2, or G when it is the point at infinity.
+	bx, by := scalarMult(xs[1], ys[1], b)
+	rx, ry := curve.Add(xs[0], ys[0], bx, by)
+	if isInfinity(rx, ry) {
+		rx, ry = curve.Gx, curve.Gy
+	}
+
+	// e = int(hash_BIP0340/challenge(xbytes(R) || xbytes(Q) || m)) mod n
+	hash = chainhash.TaggedHash(chainhash.TagBIP0340Challenge,
+		serializeScalar(rx), aggPubKey, msg)
+	e := new(big.Int).SetBytes(hash[:])
+	e.Mod(e, curve.N)
+
+	return &sessionValues{b: b, rx: rx, ry: ry, e: e}, nil
+}
+
+// keyParityFactor returns the factor g which negates the signing keys when
+// the final aggregated key has an odd y coordinate, multiplied by the
+// accumulated negations of the tweaks.
+func keyParityFactor(aggKey *AggregateKey) *big.Int {
+	g := negateIf(aggKey.gacc, aggKey.FinalKey.Y.Bit(0) == 1)
+	return new(big.Int).Set(g)
+}
+
+// Sign creates the partial signature of the passed message by the passed
+// private key using the passed secret nonce, aggregated nonce and aggregated
+// key.
+//
+// The secret nonce is erased before signing so it can never be used twice,
+// which would reveal the private key.  Signing with an erased secret nonce
+// fails.
+func Sign(secNonce *[SecNonceSize]byte, privKey *btcec.PrivateKey,
+	aggNonce [PubNonceSize]byte, aggKey *AggregateKey,
+	msg []byte) (*PartialSignature, error) {
+
+	// Erase the secret nonce before doing anything else so it is unusable
+	// regardless of whether signing succeeds.
+	nonce := *secNonce
+	*secNonce = [SecNonceSize]byte{}
+	if isZero(nonce[:64]) {
+		return nil, errors.New("secret nonce was already used")
+	}
+
+	var ks [2]*big.Int
+	for i := range ks {
+		k, err := parseScalar(nonce[i*32 : (i+1)*32])
+		if err != nil || k.Sign() == 0 {
+			return nil, errors.New("invalid secret nonce")
+		}
+		ks[i] = k
+	}
+
+	curve := btcec.S256()
+	d := privKey.D
+	if d.Sign() <= 0 || d.Cmp(curve.N) >= 0 {
+		return nil, errors.New("private key is not a valid scalar")
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	if string(pubKey) != string(nonce[64:]) {
+		return nil, errors.New("secret nonce was generated for a " +
+			"different public key")
+	}
+	if !aggKey.hasKey(pubKey) {
+		return nil, errors.New("public key is not part of the " +
+			"aggregated key")
+	}
+
+	values, err := computeSessionValues(aggNonce, aggKey, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Negate the nonces when the final nonce point has an odd y
+	// coordinate.
+	negateNonces := values.ry.Bit(0) == 1
+	k1 := negateIf(ks[0], negateNonces)
+	k2 := negateIf(ks[1], negateNonces)
+
+	// s = k1 + b*k2 + e*a*g*gacc*d mod n
+	s := new(big.Int).Mul(values.e, aggKey.coefficient(pubKey))
+	s.Mul(s, keyParityFactor(aggKey))
+	s.Mul(s, d)
+	s.Add(s, new(big.Int).Mul(values.b, k2))
+	s.Add(s, k1)
+	s.Mod(s, curve.N)
+
+	sig := &PartialSignature{S: s}
+	if !VerifyPartialSig(sig, pubNonceFromSecNonce(ks), aggNonce,
+		privKey.PubKey(), aggKey, msg) {
+
+		return nil, errors.New("created partial signature is invalid")
+	}
+	return sig, nil
+}
+
+// pubNonceFromSecNonce returns the public nonce for the passed secret nonce
+// scalars.
+func pubNonceFromSecNonce(ks [2]*big.Int) [PubNonceSize]byte {
+	var pubNonce [PubNonceSize]byte
+	for i, k := range ks {
+		rx, ry := scalarBaseMult(k)
+		copy(pubNonce[i*33:], serializePoint(rx, ry))
+	}
+	return pubNonce
+}
+
+// VerifyPartialSig returns whether the passed partial signature is a valid
+// partial signature of the passed message by the signer with the passed public
+// nonce and public key for the passed aggregated nonce and aggregated key.
+func VerifyPartialSig(sig *PartialSignature, pubNonce [PubNonceSize]byte,
+	aggNonce [PubNonceSize]byte, pubKey *btcec.PublicKey,
+	aggKey *AggregateKey, msg []byte) bool {
+
+	curve := btcec.S256()
+	if sig.S.Sign() < 0 || sig.S.Cmp(curve.N) >= 0 {
+		return false
+	}
+	serializedKey := pubKey.SerializeCompressed()
+	if !aggKey.hasKey(serializedKey) {
+		return false
+	}
+	values, err := computeSessionValues(aggNonce, aggKey, msg)
+	if err != nil {
+		return false
+	}
+	xs, ys, err := parseNonce(pubNonce, false)
+	if err != nil {
+		return false
+	}
+
+	// Re = R_1 + b*R_2 of the signer, negated when the final nonce point
+	// has an odd y coordinate.
+	bx, by := scalarMult(xs[1], ys[1], values.b)
+	rex, rey := curve.Add(xs[0], ys[0], bx, by)
+	if !isInfinity(rex, rey) && values.ry.Bit(0) == 1 {
+		rey = new(big.Int).Sub(curve.P, rey)
+	}
+
+	// s*G == Re + e*a*g*gacc*P
+	ep := new(big.Int).Mul(values.e, aggKey.coefficient(serializedKey))
+	ep.Mul(ep, keyParityFactor(aggKey))
+	ep.Mod(ep, curve.N)
+	px, py := scalarMult(pubKey.X, pubKey.Y, ep)
+	wantX, wantY := curve.Add(rex, rey, px, py)
+	gotX, gotY := scalarBaseMult(sig.S)
+	return gotX.Cmp(wantX) == 0 && gotY.Cmp(wantY) == 0
+}
+
+// AggregatePartialSigs aggregates the partial signatures of all signers into
+// the BIP0340 signature of the passed message by the final key of the passed
+// aggregated key.  The partial signatures are not verified, so the resulting
+// signature should be verified before it is used.
+func AggregatePartialSigs(sigs []*PartialSignature,
+	aggNonce [PubNonceSize]byte, aggKey *AggregateKey,
+	msg []byte) (*schnorr.Signature, error) {
+
+	values, err := computeSessionValues(aggNonce, aggKey, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// s = s_1 + ... + s_u + e*g*tacc mod n
+	curve := btcec.S256()
+	g := negateIf(big.NewInt(1), aggKey.FinalKey.Y.Bit(0) == 1)
+	s := new(big.Int).Mul(values.e, g)
+	s.Mul(s, aggKey.tacc)
+	for i, sig := range sigs {
+		if sig.S.Sign() < 0 || sig.S.Cmp(curve.N) >= 0 {
+			return nil, fmt.Errorf("partial signature #%d is not "+
+				"a valid scalar", i)
+		}
+		s.Add(s, sig.S)
+	}
+	s.Mod(s, curve.N)
+
+	return schnorr.NewSignature(new(big.Int).Set(values.rx), s), nil
+}
@@ -0,0 +1,592 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package musig2
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+)
+
+// TestSignVerifyVectors ensures partial signatures are created and verified
+// according to the signing and verification test vectors from BIP0327.
+func TestSignVerifyVectors(t *testing.T) {
+	t.Parallel()
+
+	var vectors struct {
+		SecretKey  string   `json:"sk"`
+		PubKeys    []string `json:"pubkeys"`
+		SecNonces  []string `json:"secnonces"`
+		PubNonces  []string `json:"pnonces"`
+		AggNonces  []string `json:"aggnonces"`
+		Msgs       []string `json:"msgs"`
+		ValidTests []struct {
+			KeyIndices    []int  `json:"key_indices"`
+			NonceIndices  []int  `json:"nonce_indices"`
+			AggNonceIndex int    `json:"aggnonce_index"`
+			MsgIndex      int    `json:"msg_index"`
+			SignerIndex   int    `json:"signer_index"`
+			Expected      string `json:"expected"`
+		} `json:"valid_test_cases"`
+		SignErrorTests []struct {
+			KeyIndices    []int       `json:"key_indices"`
+			AggNonceIndex int         `json:"aggnonce_index"`
+			MsgIndex      int         `json:"msg_index"`
+			SecNonceIndex int         `json:"secnonce_index"`
+			Error         vectorError `json:"error"`
+			Comment       string      `json:"comment"`
+		} `json:"sign_error_test_cases"`
+		VerifyFailTests []struct {
+			Sig          string `json:"sig"`
+			KeyIndices   []int  `json:"key_indices"`
+			NonceIndices []int  `json:"nonce_indices"`
+			MsgIndex     int    `json:"msg_index"`
+			SignerIndex  int    `json:"signer_index"`
+			Comment      string `json:"comment"`
+		} `json:"verify_fail_test_cases"`
+		VerifyErrorTests []struct {
+			Sig          string      `json:"sig"`
+			KeyIndices   []int       `json:"key_indices"`
+			NonceIndices []int       `json:"nonce_indices"`
+			MsgIndex     int         `json:"msg_index"`
+			SignerIndex  int         `json:"signer_index"`
+			Error        vectorError `json:"error"`
+			Comment      string      `json:"comment"`
+		} `json:"verify_error_test_cases"`
+	}
+	loadVectors(t, "sign_verify_vectors.json", &vectors)
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(),
+		decodeHex(vectors.SecretKey))
+
+	// verify returns whether the passed partial signature is valid for the
+	// signer at the passed index, or an error when one of the inputs is
+	// invalid.
+	verify := func(sig *PartialSignature, keyIndices, nonceIndices []int,
+		msgIndex, signerIndex int) (bool, error) {
+
+		aggKey, err := aggregateKeys(selectHex(vectors.PubKeys,
+			keyIndices))
+		if err != nil {
+			return false, err
+		}
+		pubNonces := selectNonces(vectors.PubNonces, nonceIndices)
+		aggNonce, err := AggregateNonces(pubNonces)
+		if err != nil {
+			return false, err
+		}
+		pubKey, err := btcec.ParsePubKey(aggKey.keys[signerIndex],
+			btcec.S256())
+		if err != nil {
+			return false, err
+		}
+		return VerifyPartialSig(sig, pubNonces[signerIndex], aggNonce,
+			pubKey, aggKey, decodeHex(vectors.Msgs[msgIndex])), nil
+	}
+
+	for i, test := range vectors.ValidTests {
+		aggKey, err := aggregateKeys(selectHex(vectors.PubKeys,
+			test.KeyIndices))
+		if err != nil {
+			t.Errorf("valid #%d: unexpected error: %v", i, err)
+			continue
+		}
+		aggNonce := selectNonces(vectors.AggNonces,
+			[]int{test.AggNonceIndex})[0]
+		gotAggNonce, err := AggregateNonces(selectNonces(
+			vectors.PubNonces, test.NonceIndices))
+		if err != nil || gotAggNonce != aggNonce {
+			t.Errorf("valid #%d: mismatched aggregated nonce - got "+
+				"%X, want %X (err %v)", i, gotAggNonce, aggNonce,
+				err)
+			continue
+		}
+
+		var secNonce [SecNonceSize]byte
+		copy(secNonce[:], decodeHex(vectors.SecNonces[0]))
+		msg := decodeHex(vectors.Msgs[test.MsgIndex])
+		sig, err := Sign(&secNonce, privKey, aggNonce, aggKey, msg)
+		if err != nil {
+			t.Errorf("valid #%d: unexpected error: %v", i, err)
+			continue
+		}
+		if got := sig.Serialize(); !bytes.Equal(got,
+			decodeHex(test.Expected)) {
+
+			t.Errorf("valid #%d: mismatched partial signature - "+
+				"got %X, want %s", i, got, test.Expected)
+		}
+		valid, err := verify(sig, test.KeyIndices, test.NonceIndices,
+			test.MsgIndex, test.SignerIndex)
+		if err != nil || !valid {
+			t.Errorf("valid #%d: partial signature is invalid "+
+				"(err %v)", i, err)
+		}
+	}
+
+	for i, test := range vectors.SignErrorTests {
+		aggKey, err := aggregateKeys(selectHex(vectors.PubKeys,
+			test.KeyIndices))
+		if err == nil {
+			var secNonce [SecNonceSize]byte
+			copy(secNonce[:],
+				decodeHex(vectors.SecNonces[test.SecNonceIndex]))
+			aggNonce := selectNonces(vectors.AggNonces,
+				[]int{test.AggNonceIndex})[0]
+			_, err = Sign(&secNonce, privKey, aggNonce, aggKey,
+				decodeHex(vectors.Msgs[test.MsgIndex]))
+		}
+		err = checkVectorError(err, test.Error, "public key #")
+		if err != nil {
+			t.Errorf("sign error #%d (%s): %v", i, test.Comment, err)
+		}
+	}
+
+	// Signatures which exceed the group order are not parsed so the range
+	// check of the verification is exercised as well.
+	for i, test := range vectors.VerifyFailTests {
+		sig := &PartialSignature{S: new(big.Int).SetBytes(
+			decodeHex(test.Sig))}
+		valid, err := verify(sig, test.KeyIndices, test.NonceIndices,
+			test.MsgIndex, test.SignerIndex)
+		if err != nil {
+			t.Errorf("verify fail #%d (%s): unexpected error: %v", i,
+				test.Comment, err)
+			continue
+		}
+		if valid {
+			t.Errorf("verify fail #%d (%s): invalid partial "+
+				"signature verified", i, test.Comment)
+		}
+	}
+
+	for i, test := range vectors.VerifyErrorTests {
+		sig, err := ParsePartialSignature(decodeHex(test.Sig))
+		if err != nil {
+			t.Fatalf("verify error #%d: unexpected error: %v", i, err)
+		}
+		valid, err := verify(sig, test.KeyIndices, test.NonceIndices,
+			test.MsgIndex, test.SignerIndex)
+		if valid {
+			t.Errorf("verify error #%d (%s): partial signature with "+
+				"invalid %s verified", i, test.Comment,
+				test.Error.Contrib)
+			continue
+		}
+		prefix := "public key #"
+		if test.Error.Contrib == "pubnonce" {
+			prefix = "public nonce #"
+		}
+		if err := checkVectorError(err, test.Error, prefix); err != nil {
+			t.Errorf("verify error #%d (%s): %v", i, test.Comment,
+				err)
+		}
+	}
+}
+
+// TestTweakVectors ensures partial signatures for tweaked aggregated keys are
+// created according to the tweak test vectors from BIP0327 and tweaks which
+// are not valid scalars are rejected.
+func TestTweakVectors(t *testing.T) {
+	t.Parallel()
+
+	var vectors struct {
+		SecretKey  string   `json:"sk"`
+		PubKeys    []string `json:"pubkeys"`
+		SecNonce   string   `json:"secnonce"`
+		PubNonces  []string `json:"pnonces"`
+		AggNonce   string   `json:"aggnonce"`
+		Tweaks     []string `json:"tweaks"`
+		Msg        string   `json:"msg"`
+		ValidTests []struct {
+			KeyIndices   []int  `json:"key_indices"`
+			NonceIndices []int  `json:"nonce_indices"`
+			TweakIndices []int  `json:"tweak_indices"`
+			IsXOnly      []bool `json:"is_xonly"`
+			SignerIndex  int    `json:"signer_index"`
+			Expected     string `json:"expected"`
+			Comment      string `json:"comment"`
+		} `json:"valid_test_cases"`
+		ErrorTests []struct {
+			KeyIndices   []int       `json:"key_indices"`
+			TweakIndices []int       `json:"tweak_indices"`
+			IsXOnly      []bool      `json:"is_xonly"`
+			Error        vectorError `json:"error"`
+			Comment      string      `json:"comment"`
+		} `json:"error_test_cases"`
+	}
+	loadVectors(t, "tweak_vectors.json", &vectors)
+	privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(),
+		decodeHex(vectors.SecretKey))
+	aggNonce := selectNonces([]string{vectors.AggNonce}, []int{0})[0]
+	msg := decodeHex(vectors.Msg)
+
+	for i, test := range vectors.ValidTests {
+		aggKey, err := tweakKey(selectHex(vectors.PubKeys,
+			test.KeyIndices), vectors.Tweaks, test.TweakIndices,
+			test.IsXOnly)
+		if err != nil {
+			t.Errorf("valid #%d (%s): unexpected error: %v", i,
+				test.Comment, err)
+			continue
+		}
+
+		var secNonce [SecNonceSize]byte
+		copy(secNonce[:], decodeHex(vectors.SecNonce))
+		sig, err := Sign(&secNonce, privKey, aggNonce, aggKey, msg)
+		if err != nil {
+			t.Errorf("valid #%d (%s): unexpected error: %v", i,
+				test.Comment, err)
+			continue
+		}
+		if got := sig.Serialize(); !bytes.Equal(got,
+			decodeHex(test.Expected)) {
+
+			t.Errorf("valid #%d (%s): mismatched partial signature "+
+				"- got %X, want %s", i, test.Comment, got,
+				test.Expected)
+		}
+		pubNonces := selectNonces(vectors.PubNonces, test.NonceIndices)
+		if !VerifyPartialSig(sig, pubNonces[test.SignerIndex], aggNonce,
+			pubKey, aggKey, msg) {
+
+			t.Errorf("valid #%d (%s): partial signature is invalid",
+				i, test.Comment)
+		}
+	}
+
+	for i, test := range vectors.ErrorTests {
+		_, err := tweakKey(selectHex(vectors.PubKeys, test.KeyIndices),
+			vectors.Tweaks, test.TweakIndices, test.IsXOnly)
+		if err := checkVectorError(err, test.Error, ""); err != nil {
+			t.Errorf("error #%d (%s): %v", i, test.Comment, err)
+		}
+	}
+}
+
+// TestSigAggVectors ensures partial signatures are aggregated according to
+// the signature aggregation test vectors from BIP0327 and the signer of a
+// partial signature which is not a valid scalar is blamed.
+func TestSigAggVectors(t *testing.T) {
+	t.Parallel()
+
+	type sigAggTest struct {
+		AggNonce     string      `json:"aggnonce"`
+		NonceIndices []int       `json:"nonce_indices"`
+		KeyIndices   []int       `json:"key_indices"`
+		TweakIndices []int       `json:"tweak_indices"`
+		IsXOnly      []bool      `json:"is_xonly"`
+		PsigIndices  []int       `json:"psig_indices"`
+		Expected     string      `json:"expected"`
+		Error        vectorError `json:"error"`
+		Comment      string      `json:"comment"`
+	}
+	var vectors struct {
+		PubKeys    []string     `json:"pubkeys"`
+		PubNonces  []string     `json:"pnonces"`
+		Tweaks     []string     `json:"tweaks"`
+		Psigs      []string     `json:"psigs"`
+		Msg        string       `json:"msg"`
+		ValidTests []sigAggTest `json:"valid_test_cases"`
+		ErrorTests []sigAggTest `json:"error_test_cases"`
+	}
+	loadVectors(t, "sig_agg_vectors.json", &vectors)
+	msg := decodeHex(vectors.Msg)
+
+	// aggregate aggregates the partial signatures of the passed test.
+	// They are not parsed so partial signatures which are not valid
+	// scalars reach the aggregation.
+	aggregate := func(test sigAggTest) (*AggregateKey, *schnorr.Signature,
+		error) {
+
+		aggKey, err := tweakKey(selectHex(vectors.PubKeys,
+			test.KeyIndices), vectors.Tweaks, test.TweakIndices,
+			test.IsXOnly)
+		if err != nil {
+			return nil, nil, err
+		}
+		aggNonce := selectNonces([]string{test.AggNonce}, []int{0})[0]
+		gotAggNonce, err := AggregateNonces(selectNonces(
+			vectors.PubNonces, test.NonceIndices))
+		if err != nil {
+			return nil, nil, err
+		}
+		if gotAggNonce != aggNonce {
+			return nil, nil, fmt.Errorf("mismatched aggregated "+
+				"nonce - got %X, want %X", gotAggNonce, aggNonce)
+		}
+
+		sigs := make([]*PartialSignature, 0, len(test.PsigIndices))
+		for _, sig := range selectHex(vectors.Psigs, test.PsigIndices) {
+			sigs = append(sigs, &PartialSignature{
+				S: new(big.Int).SetBytes(sig),
+			})
+		}
+		finalSig, err := AggregatePartialSigs(sigs, aggNonce, aggKey,
+			msg)
+		return aggKey, finalSig, err
+	}
+
+	for i, test := range vectors.ValidTests {
+		aggKey, finalSig, err := aggregate(test)
+		if err != nil {
+			t.Errorf("valid #%d: unexpected error: %v", i, err)
+			continue
+		}
+		if got := finalSig.Serialize(); !bytes.Equal(got,
+			decodeHex(test.Expected)) {
+
+			t.Errorf("valid #%d: mismatched signature - got %X, "+
+				"want %s", i, got, test.Expected)
+		}
+		if !finalSig.Verify(msg, aggKey.FinalKey) {
+			t.Errorf("valid #%d: signature is invalid", i)
+		}
+	}
+
+	for i, test := range vectors.ErrorTests {
+		_, _, err := aggregate(test)
+		err = checkVectorError(err, test.Error, "partial signature #")
+		if err != nil {
+			t.Errorf("error #%d (%s): %v", i, test.Comment, err)
+		}
+	}
+}
+
+// signAll creates the signature of the passed message with the passed private
+// keys for the aggregated key and verifies every partial signature.
+func signAll(t *testing.T, privKeys []*btcec.PrivateKey, aggKey *AggregateKey,
+	msg []byte) *schnorr.Signature {
+
+	t.Helper()
+
+	nonces := make([]*Nonces, 0, len(privKeys))
+	pubNonces := make([][PubNonceSize]byte, 0, len(privKeys))
+	for _, privKey := range privKeys {
+		signerNonces, err := GenNonces(privKey.PubKey(), &NonceOptions{
+			SecretKey:    privKey,
+			AggregateKey: aggKey,
+			Msg:          msg,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		nonces = append(nonces, signerNonces)
+		pubNonces = append(pubNonces, signerNonces.PubNonce)
+	}
+	aggNonce, err := AggregateNonces(pubNonces)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sigs := make([]*PartialSignature, 0, len(privKeys))
+	for i, privKey := range privKeys {
+		sig, err := Sign(&nonces[i].SecNonce, privKey, aggNonce, aggKey,
+			msg)
+		if err != nil {
+			t.Fatalf("signer #%d: unexpected error: %v", i, err)
+		}
+		if !VerifyPartialSig(sig, pubNonces[i], aggNonce,
+			privKey.PubKey(), aggKey, msg) {
+
+			t.Fatalf("signer #%d: partial signature is invalid", i)
+		}
+
+		// The partial signature must not verify for another signer.
+		other := privKeys[(i+1)%len(privKeys)].PubKey()
+		if !other.IsEqual(privKey.PubKey()) && VerifyPartialSig(sig,
+			pubNonces[i], aggNonce, other, aggKey, msg) {
+
+			t.Fatalf("signer #%d: partial signature is valid for "+
+				"another signer", i)
+		}
+		sigs = append(sigs, sig)
+	}
+
+	finalSig, err := AggregatePartialSigs(sigs, aggNonce, aggKey, msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return finalSig
+}
+
+// TestSignAggregate ensures signatures aggregated from partial signatures are
+// valid BIP0340 signatures for the final aggregated key with and without
+// tweaks.
+func TestSignAggregate(t *testing.T) {
+	t.Parallel()
+
+	privKeys, pubKeys := testPrivKeys(4)
+	var plainTweak, xOnlyTweak KeyTweak
+	plainTweak.Tweak[31] = 0x07
+	xOnlyTweak.Tweak[31] = 0x0b
+	xOnlyTweak.IsXOnly = true
+
+	tests := []struct {
+		name     string
+		signers  int
+		tweaks   []KeyTweak
+		taproot  bool
+		msg      []byte
+		repeated bool
+	}{{
+		name:    "single signer",
+		signers: 1,
+		msg:     bytes.Repeat([]byte{0x42}, 32),
+	}, {
+		name:    "two signers",
+		signers: 2,
+		msg:     bytes.Repeat([]byte{0x42}, 32),
+	}, {
+		name:    "four signers with an empty message",
+		signers: 4,
+		msg:     []byte{},
+	}, {
+		name:     "repeated signer",
+		signers:  2,
+		repeated: true,
+		msg:      bytes.Repeat([]byte{0x42}, 32),
+	}, {
+		name:    "plain and x-only tweaks",
+		signers: 3,
+		tweaks:  []KeyTweak{plainTweak, xOnlyTweak, plainTweak},
+		msg:     bytes.Repeat([]byte{0x42}, 32),
+	}, {
+		name:    "taproot output without script tree",
+		signers: 3,
+		taproot: true,
+		msg:     bytes.Repeat([]byte{0x42}, 32),
+	}, {
+		name:    "taproot output after plain tweak",
+		signers: 3,
+		tweaks:  []KeyTweak{plainTweak},
+		taproot: true,
+		msg:     bytes.Repeat([]byte{0x42}, 100),
+	}}
+
+	for _, test := range tests {
+		signerKeys := privKeys[:test.signers]
+		keys := pubKeys[:test.signers]
+		if test.repeated {
+			signerKeys = append(signerKeys, signerKeys...)
+			keys = append(keys, keys...)
+		}
+		aggKey, err := AggregateKeys(keys, test.tweaks...)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if test.taproot {
+			aggKey, err = aggKey.TaprootTweak(nil)
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+				continue
+			}
+		}
+
+		sig := signAll(t, signerKeys, aggKey, test.msg)
+		if !sig.Verify(test.msg, aggKey.FinalKey) {
+			t.Errorf("%s: final signature is invalid", test.name)
+		}
+		if sig.Verify(append([]byte{0x00}, test.msg...), aggKey.FinalKey) {
+			t.Errorf("%s: final signature is valid for another "+
+				"message", test.name)
+		}
+		if test.tweaks != nil || test.taproot {
+			if sig.Verify(test.msg, aggKey.PreTweakedKey) {
+				t.Errorf("%s: final signature is valid for the "+
+					"untweaked key", test.name)
+			}
+		}
+	}
+}
+
+// TestSignNonceReuse ensures signing erases the secret nonce, refuses to sign
+// twice with it and rejects signers which do not belong to the nonce or the
+// aggregated key.
+func TestSignNonceReuse(t *testing.T) {
+	t.Parallel()
+
+	privKeys, pubKeys := testPrivKeys(3)
+	aggKey, err := AggregateKeys(pubKeys[:2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := bytes.Repeat([]byte{0x42}, 32)
+
+	genNonce := func(i int) *Nonces {
+		nonces, err := GenNonces(pubKeys[i], nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return nonces
+	}
+	nonces, otherNonces := genNonce(0), genNonce(1)
+	aggNonce, err := AggregateNonces([][PubNonceSize]byte{
+		nonces.PubNonce, otherNonces.PubNonce,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	secNonce := nonces.SecNonce
+	if _, err := Sign(&secNonce, privKeys[0], aggNonce, aggKey,
+		msg); err != nil {
+
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secNonce != [SecNonceSize]byte{} {
+		t.Error("Sign did not erase the secret nonce")
+	}
+	if _, err := Sign(&secNonce, privKeys[0], aggNonce, aggKey,
+		msg); err == nil {
+
+		t.Error("Sign: no error for reused secret nonce")
+	}
+
+	// A secret nonce of another signer is rejected and erased as well.
+	secNonce = otherNonces.SecNonce
+	if _, err := Sign(&secNonce, privKeys[0], aggNonce, aggKey,
+		msg); err == nil {
+
+		t.Error("Sign: no error for secret nonce of another signer")
+	}
+	if secNonce != [SecNonceSize]byte{} {
+		t.Error("Sign did not erase the rejected secret nonce")
+	}
+
+	// Signers which are not part of the aggregated key are rejected.
+	secNonce = genNonce(2).SecNonce
+	if _, err := Sign(&secNonce, privKeys[2], aggNonce, aggKey,
+		msg); err == nil {
+
+		t.Error("Sign: no error for signer outside the aggregated key")
+	}
+}
+
+// TestPartialSignatureParse ensures partial signatures survive a serialization
+// round trip and values out of range are rejected.
+func TestPartialSignatureParse(t *testing.T) {
+	t.Parallel()
+
+	sig := &PartialSignature{S: big.NewInt(0x1234)}
+	parsed, err := ParsePartialSignature(sig.Serialize())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.S.Cmp(sig.S) != 0 {
+		t.Errorf("mismatched parsed partial signature %x", parsed.S)
+	}
+
+	if _, err := ParsePartialSignature(btcec.S256().N.Bytes()); err == nil {
+		t.Error("ParsePartialSignature: no error for group order")
+	}
+	if _, err := ParsePartialSignature([]byte{0x01}); err == nil {
+		t.Error("ParsePartialSignature: no error for short signature")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package schnorr
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// PubKeyBytesLen is the size of a serialized x-only public key.
+const PubKeyBytesLen = 32
+
+// ParsePubKey parses a BIP0340 x-only public key, which is the point with the
+// passed x coordinate and an even y coordinate.
+func ParsePubKey(pubKey []byte) (*btcec.PublicKey, error) {
+	if len(pubKey) != PubKeyBytesLen {
+		return nil, fmt.Errorf("malformed x-only public key: invalid "+
+			"length %d", len(pubKey))
+	}
+
+	// Unlike the compressed public key parsing, an x coordinate which is
+	// not a field element is not silently reduced.
+	curve := btcec.S256()
+	if new(big.Int).SetBytes(pubKey).Cmp(curve.P) >= 0 {
+		return nil, fmt.Errorf("x-only public key is not a field element")
+	}
+
+	compressed := make([]byte, 0, btcec.PubKeyBytesLenCompressed)
+	compressed = append(compressed, 0x02)
+	compressed = append(compressed, pubKey...)
+	return btcec.ParsePubKey(compressed, curve)
+}
+
+// SerializePubKey returns the 32 byte x-only serialization of the passed public
+// key.  Keys with either y coordinate result in the same serialization.
+func SerializePubKey(pubKey *btcec.PublicKey) []byte {
+	var serialized [PubKeyBytesLen]byte
+	pubKey.X.FillBytes(serialized[:])
+	return serialized[:]
+}
+
+// evenY returns the y coordinate of the point with the x coordinate of the
+// passed public key and an even y coordinate, which is the point an x-only
+// public key refers to.
+func evenY(pubKey *btcec.PublicKey) *big.Int {
+	if pubKey.Y.Bit(0) == 0 {
+		return pubKey.Y
+	}
+	return new(big.Int).Sub(btcec.S256().P, pubKey.Y)
+}
+
+// evenPrivKeyScalar returns the scalar of the passed private key, negated when
+// its public key has an odd y coordinate so the resulting scalar belongs to the
+// x-only public key.
+func evenPrivKeyScalar(privKey *btcec.PrivateKey) *big.Int {
+	d := new(big.Int).Set(privKey.D)
+	if privKey.PubKey().Y.Bit(0) == 1 {
+		d.Sub(btcec.S256().N, d)
+	}
+	return d
+}
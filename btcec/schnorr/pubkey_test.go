@@ -0,0 +1,77 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package schnorr
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// TestPubKeys ensures x-only public keys are parsed to the point with an even y
+// coordinate, keys of either parity serialize to their x coordinate and invalid
+// keys are rejected.
+func TestPubKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		pubKey string
+		valid  bool
+	}{{
+		name:   "generator",
+		pubKey: "79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		valid:  true,
+	}, {
+		name:   "BIP0340 vector 1",
+		pubKey: "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		valid:  true,
+	}, {
+		name:   "not on the curve",
+		pubKey: "EEFDEA4CDB677750A420FEE807EACF21EB9898AE79B9768766E4FAA04A2D4A34",
+	}, {
+		name:   "exceeds the field size",
+		pubKey: "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30",
+	}, {
+		name:   "compressed",
+		pubKey: "0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+	}}
+
+	for _, test := range tests {
+		pubKeyBytes := decodeHex(test.pubKey)
+		pubKey, err := ParsePubKey(pubKeyBytes)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: no error for invalid key", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if pubKey.Y.Bit(0) != 0 {
+			t.Errorf("%s: parsed key has an odd y coordinate",
+				test.name)
+		}
+		if !bytes.Equal(SerializePubKey(pubKey), pubKeyBytes) {
+			t.Errorf("%s: mismatched serialization %X", test.name,
+				SerializePubKey(pubKey))
+		}
+
+		// The negated key has the same x-only serialization.
+		negated := &btcec.PublicKey{
+			Curve: pubKey.Curve,
+			X:     pubKey.X,
+			Y:     new(big.Int).Sub(btcec.S256().P, pubKey.Y),
+		}
+		if !bytes.Equal(SerializePubKey(negated), pubKeyBytes) {
+			t.Errorf("%s: mismatched serialization of negated key",
+				test.name)
+		}
+	}
+}
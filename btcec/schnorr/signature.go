@@ -0,0 +1,180 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package schnorr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	// SignatureSize is the size of a serialized Schnorr signature.
+	SignatureSize = 64
+
+	// AuxRandSize is the size of the auxiliary randomness used to derive
+	// the nonce of a signature.
+	AuxRandSize = 32
+)
+
+// Signature is a BIP0340 Schnorr signature.
+type Signature struct {
+	// R is the x coordinate of the nonce point, whose y coordinate is
+	// even.
+	R *big.Int
+
+	// S is the scalar of the signature.
+	S *big.Int
+}
+
+// NewSignature returns a signature with the passed x coordinate of the nonce
+// point and scalar.
+func NewSignature(r, s *big.Int) *Signature {
+	return &Signature{R: r, S: s}
+}
+
+// ParseSignature parses a 64 byte Schnorr signature.  The x coordinate of the
+// nonce point must be a field element and the scalar must be less than the
+// group order.
+func ParseSignature(sig []byte) (*Signature, error) {
+	if len(sig) != SignatureSize {
+		return nil, fmt.Errorf("malformed signature: invalid length %d",
+			len(sig))
+	}
+
+	curve := btcec.S256()
+	r := new(big.Int).SetBytes(sig[:32])
+	if r.Cmp(curve.P) >= 0 {
+		return nil, errors.New("signature r is not a field element")
+	}
+	s := new(big.Int).SetBytes(sig[32:])
+	if s.Cmp(curve.N) >= 0 {
+		return nil, errors.New("signature s is not less than the group " +
+			"order")
+	}
+
+	return NewSignature(r, s), nil
+}
+
+// Serialize returns the 64 byte serialization of the signature, which is the
+// x coordinate of the nonce point followed by the scalar.
+func (sig *Signature) Serialize() []byte {
+	b := make([]byte, SignatureSize)
+	sig.R.FillBytes(b[:32])
+	sig.S.FillBytes(b[32:])
+	return b
+}
+
+// IsEqual returns whether the signature is equal to the passed one.
+func (sig *Signature) IsEqual(otherSig *Signature) bool {
+	return sig.R.Cmp(otherSig.R) == 0 && sig.S.Cmp(otherSig.S) == 0
+}
+
+// challenge returns e = int(hash_BIP0340/challenge(r || P || m)) mod n.
+func challenge(r []byte, pubKey []byte, msg []byte) *big.Int {
+	hash := chainhash.TaggedHash(chainhash.TagBIP0340Challenge, r, pubKey,
+		msg)
+	e := new(big.Int).SetBytes(hash[:])
+	return e.Mod(e, btcec.S256().N)
+}
+
+// Verify returns whether the signature is a valid BIP0340 signature of the
+// passed message by the x-only public key of the passed public key.
+func (sig *Signature) Verify(msg []byte, pubKey *btcec.PublicKey) bool {
+	curve := btcec.S256()
+	if sig.R.Cmp(curve.P) >= 0 || sig.S.Cmp(curve.N) >= 0 {
+		return false
+	}
+
+	serialized := sig.Serialize()
+	e := challenge(serialized[:32], SerializePubKey(pubKey), msg)
+
+	// R = s*G - e*P must not be the point at infinity, must have an even y
+	// coordinate and its x coordinate must be r.
+	e.Sub(curve.N, e)
+	e.Mod(e, curve.N)
+	sx, sy := curve.ScalarBaseMult(serialized[32:])
+	ex, ey := curve.ScalarMult(pubKey.X, evenY(pubKey), e.Bytes())
+	rx, ry := curve.Add(sx, sy, ex, ey)
+	if rx.Sign() == 0 && ry.Sign() == 0 {
+		return false
+	}
+	return ry.Bit(0) == 0 && rx.Cmp(sig.R) == 0
+}
+
+// Sign returns the BIP0340 signature of the passed message, which is typically
+// a 32 byte hash, by the passed private key.  The nonce is derived with all
+// zero auxiliary randomness, so the signature is deterministic.  Use
+// SignWithAuxRand to provide fresh randomness, which BIP0340 recommends as a
+// protection against side-channel attacks.
+func Sign(privKey *btcec.PrivateKey, msg []byte) (*Signature, error) {
+	var auxRand [AuxRandSize]byte
+	return SignWithAuxRand(privKey, msg, auxRand[:])
+}
+
+// SignWithAuxRand returns the BIP0340 signature of the passed message by the
+// passed private key using the nonce derived from the private key, the public
+// key, the message and the passed 32 bytes of auxiliary randomness.
+func SignWithAuxRand(privKey *btcec.PrivateKey, msg,
+	auxRand []byte) (*Signature, error) {
+
+	if len(auxRand) != AuxRandSize {
+		return nil, fmt.Errorf("auxiliary randomness must be %d bytes",
+			AuxRandSize)
+	}
+	curve := btcec.S256()
+	if privKey.D.Sign() <= 0 || privKey.D.Cmp(curve.N) >= 0 {
+		return nil, errors.New("private key is not a valid scalar")
+	}
+
+	// Negate the private key when its public key has an odd y coordinate
+	// since only the x coordinate of the public key is committed to.
+	d := evenPrivKeyScalar(privKey)
+	var dBytes [32]byte
+	d.FillBytes(dBytes[:])
+	pubKey := SerializePubKey(privKey.PubKey())
+
+	// Derive the nonce from the private key masked with the auxiliary
+	// randomness, the public key and the message.
+	t := chainhash.TaggedHash(chainhash.TagBIP0340Aux, auxRand)
+	for i := range t {
+		t[i] ^= dBytes[i]
+	}
+	rand := chainhash.TaggedHash(chainhash.TagBIP0340Nonce, t[:], pubKey,
+		msg)
+	k := new(big.Int).SetBytes(rand[:])
+	k.Mod(k, curve.N)
+	if k.Sign() == 0 {
+		return nil, errors.New("derived nonce is zero")
+	}
+
+	// Negate the nonce when the nonce point has an odd y coordinate.
+	var kBytes [32]byte
+	k.FillBytes(kBytes[:])
+	rx, ry := curve.ScalarBaseMult(kBytes[:])
+	if ry.Bit(0) == 1 {
+		k.Sub(curve.N, k)
+	}
+
+	// s = k + e*d mod n
+	var rBytes [32]byte
+	rx.FillBytes(rBytes[:])
+	s := challenge(rBytes[:], pubKey, msg)
+	s.Mul(s, d)
+	s.Add(s, k)
+	s.Mod(s, curve.N)
+	sig := NewSignature(rx, s)
+
+	// Verify the signature to protect against faults during signing
+	// leaking the private key, as recommended by BIP0340.
+	if !sig.Verify(msg, privKey.PubKey()) {
+		return nil, errors.New("created signature is invalid")
+	}
+
+	return sig, nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package schnorr
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// taprootTweak returns the BIP0341 tweak committing a taproot output key to the
+// passed internal key and root hash of its script tree.  An error is returned
+// in the cryptographically negligible case that the tweak is not a valid
+// scalar.
+func taprootTweak(internalKey *btcec.PublicKey, scriptRoot []byte) (*big.Int, error) {
+	// t = int(hash_TapTweak(bytes(P) || root))
+	hash := chainhash.TaggedHash(chainhash.TagTapTweak,
+		SerializePubKey(internalKey), scriptRoot)
+	tweak := new(big.Int).SetBytes(hash[:])
+	if tweak.Cmp(btcec.S256().N) >= 0 {
+		return nil, errors.New("taproot tweak exceeds the group order")
+	}
+	return tweak, nil
+}
+
+// TweakTaprootPubKey returns the output key of a taproot output with the passed
+// internal key and root hash of its script tree, which is nil for outputs
+// without a script tree.  Only the x coordinate of the internal key is
+// committed to, so keys with either y coordinate result in the same output
+// key.
+func TweakTaprootPubKey(internalKey *btcec.PublicKey,
+	scriptRoot []byte) (*btcec.PublicKey, error) {
+
+	tweak, err := taprootTweak(internalKey, scriptRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	// Q = P + t*G where P is the point with the x coordinate of the
+	// internal key and an even y coordinate.
+	curve := btcec.S256()
+	tx, ty := curve.ScalarBaseMult(tweak.Bytes())
+	qx, qy := curve.Add(internalKey.X, evenY(internalKey), tx, ty)
+	if qx.Sign() == 0 && qy.Sign() == 0 {
+		return nil, errors.New("taproot output key is the point at " +
+			"infinity")
+	}
+
+	return &btcec.PublicKey{Curve: curve, X: qx, Y: qy}, nil
+}
+
+// TweakTaprootPrivKey returns the private key of the output key of a taproot
+// output with the internal key of the passed private key and the passed root
+// hash of its script tree, which is nil for outputs without a script tree.  The
+// returned key signs for the output key calculated by TweakTaprootPubKey.
+func TweakTaprootPrivKey(privKey *btcec.PrivateKey,
+	scriptRoot []byte) (*btcec.PrivateKey, error) {
+
+	tweak, err := taprootTweak(privKey.PubKey(), scriptRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	// d' = d + t mod n where d is negated when the internal key has an
+	// odd y coordinate.
+	curve := btcec.S256()
+	d := evenPrivKeyScalar(privKey)
+	d.Add(d, tweak)
+	d.Mod(d, curve.N)
+	if d.Sign() == 0 {
+		return nil, errors.New("tweaked private key is zero")
+	}
+
+	var dBytes [32]byte
+	d.FillBytes(dBytes[:])
+	tweaked, _ := btcec.PrivKeyFromBytes(curve, dBytes[:])
+	return tweaked, nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package schnorr
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// TestTweakTaprootPubKey ensures output keys are calculated according to the
+// scriptPubKey test vectors from BIP0341.
+func TestTweakTaprootPubKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		internalKey string
+		scriptRoot  string
+		outputKey   string
+	}{{
+		name:        "no script tree",
+		internalKey: "d6889cb081036e0faefa3a35157ad71086b123b2b144b649798b494c300a961d",
+		outputKey:   "53a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343",
+	}, {
+		name:        "single leaf",
+		internalKey: "187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27",
+		scriptRoot:  "5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21",
+		outputKey:   "147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3",
+	}, {
+		name:        "two leaves",
+		internalKey: "ee4fe085983462a184015d1f782d6a5f8b9c2b60130aff050ce221ecf3786592",
+		scriptRoot:  "6c2dc106ab816b73f9d07e3cd1ef2c8c1256f519748e0813e4edd2405d277bef",
+		outputKey:   "712447206d7a5238acc7ff53fbe94a3b64539ad291c7cdbc490b7577e4b17df5",
+	}}
+
+	for _, test := range tests {
+		internalKey, err := ParsePubKey(decodeHex(test.internalKey))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		var scriptRoot []byte
+		if test.scriptRoot != "" {
+			scriptRoot = decodeHex(test.scriptRoot)
+		}
+		outputKey, err := TweakTaprootPubKey(internalKey, scriptRoot)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if got := SerializePubKey(outputKey); !bytes.Equal(got,
+			decodeHex(test.outputKey)) {
+
+			t.Errorf("%s: mismatched output key - got %x, want %s",
+				test.name, got, test.outputKey)
+		}
+	}
+}
+
+// TestTweakTaprootPrivKey ensures tweaked private keys belong to the output key
+// of the tweaked public key and sign for it, regardless of the parity of the y
+// coordinate of the internal key.
+func TestTweakTaprootPrivKey(t *testing.T) {
+	t.Parallel()
+
+	msg := bytes.Repeat([]byte{0x42}, 32)
+	scriptRoot := bytes.Repeat([]byte{0x01}, 32)
+	for i := byte(1); i <= 8; i++ {
+		var keyBytes [32]byte
+		keyBytes[31] = i
+		privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(),
+			keyBytes[:])
+
+		for _, root := range [][]byte{nil, scriptRoot} {
+			outputKey, err := TweakTaprootPubKey(pubKey, root)
+			if err != nil {
+				t.Fatalf("#%d: unexpected error: %v", i, err)
+			}
+			outputPrivKey, err := TweakTaprootPrivKey(privKey, root)
+			if err != nil {
+				t.Fatalf("#%d: unexpected error: %v", i, err)
+			}
+			if !outputPrivKey.PubKey().IsEqual(outputKey) {
+				t.Errorf("#%d: tweaked private key does not "+
+					"belong to the output key", i)
+				continue
+			}
+
+			sig, err := Sign(outputPrivKey, msg)
+			if err != nil {
+				t.Fatalf("#%d: unexpected error: %v", i, err)
+			}
+			parsedKey, err := ParsePubKey(SerializePubKey(outputKey))
+			if err != nil {
+				t.Fatalf("#%d: unexpected error: %v", i, err)
+			}
+			if !sig.Verify(msg, parsedKey) {
+				t.Errorf("#%d: signature is invalid for the "+
+					"output key", i)
+			}
+		}
+	}
+}
@@ -83,7 +83,8 @@ func NewCreateRawTransactionCmd(inputs []TransactionInput, amounts map[string]fl
 
 // DecodeRawTransactionCmd defines the decoderawtransaction JSON-RPC command.
 type DecodeRawTransactionCmd struct {
-	HexTx string
+	HexTx   string
+	Explain *bool `jsonrpcdefault:"false"`
 }
 
 // NewDecodeRawTransactionCmd returns a new instance which can be used to issue
@@ -94,6 +95,31 @@ func NewDecodeRawTransactionCmd(hexTx string) *DecodeRawTransactionCmd {
 	}
 }
 
+// NewExplainDecodeRawTransactionCmd returns a new instance which can be used to
+// issue a decoderawtransaction JSON-RPC command which also explains whether
+// the transaction is standard.
+func NewExplainDecodeRawTransactionCmd(hexTx string) *DecodeRawTransactionCmd {
+	return &DecodeRawTransactionCmd{
+		HexTx:   hexTx,
+		Explain: Bool(true),
+	}
+}
+
+// DebugScriptCmd defines the debugscript JSON-RPC command.
+type DebugScriptCmd struct {
+	HexTx string
+	Vin   uint32
+}
+
+// NewDebugScriptCmd returns a new instance which can be used to issue a
+// debugscript JSON-RPC command.
+func NewDebugScriptCmd(hexTx string, vin uint32) *DebugScriptCmd {
+	return &DebugScriptCmd{
+		HexTx: hexTx,
+		Vin:   vin,
+	}
+}
+
 // DecodeScriptCmd defines the decodescript JSON-RPC command.
 type DecodeScriptCmd struct {
 	HexScript string
@@ -122,6 +148,19 @@ func NewDeriveAddressesCmd(descriptor string, descriptorRange *DescriptorRange)
 	}
 }
 
+// DumpTxOutSetCmd defines the dumptxoutset JSON-RPC command.
+type DumpTxOutSetCmd struct {
+	Path string
+}
+
+// NewDumpTxOutSetCmd returns a new instance which can be used to issue a
+// dumptxoutset JSON-RPC command.
+func NewDumpTxOutSetCmd(path string) *DumpTxOutSetCmd {
+	return &DumpTxOutSetCmd{
+		Path: path,
+	}
+}
+
 // ChangeType defines the different output types to use for the change address
 // of a transaction built by the node.
 type ChangeType string
@@ -500,6 +539,22 @@ func NewGetDescriptorInfoCmd(descriptor string) *GetDescriptorInfoCmd {
 	}
 }
 
+// GetDeploymentInfoCmd defines the getdeploymentinfo JSON-RPC command.
+type GetDeploymentInfoCmd struct {
+	BlockHash *string
+}
+
+// NewGetDeploymentInfoCmd returns a new instance which can be used to issue a
+// getdeploymentinfo JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetDeploymentInfoCmd(blockHash *string) *GetDeploymentInfoCmd {
+	return &GetDeploymentInfoCmd{
+		BlockHash: blockHash,
+	}
+}
+
 // GetDifficultyCmd defines the getdifficulty JSON-RPC command.
 type GetDifficultyCmd struct{}
 
@@ -757,6 +812,19 @@ func NewInvalidateBlockCmd(blockHash string) *InvalidateBlockCmd {
 	}
 }
 
+// LoadTxOutSetCmd defines the loadtxoutset JSON-RPC command.
+type LoadTxOutSetCmd struct {
+	Path string
+}
+
+// NewLoadTxOutSetCmd returns a new instance which can be used to issue a
+// loadtxoutset JSON-RPC command.
+func NewLoadTxOutSetCmd(path string) *LoadTxOutSetCmd {
+	return &LoadTxOutSetCmd{
+		Path: path,
+	}
+}
+
 // PingCmd defines the ping JSON-RPC command.
 type PingCmd struct{}
 
@@ -874,6 +942,7 @@ func (a *AllowHighFeesOrMaxFeeRate) UnmarshalJSON(data []byte) error {
 type SendRawTransactionCmd struct {
 	HexTx      string
 	FeeSetting *AllowHighFeesOrMaxFeeRate `jsonrpcdefault:"false"`
+	Explain    *bool                      `jsonrpcdefault:"false"`
 }
 
 // NewSendRawTransactionCmd returns a new instance which can be used to issue a
@@ -890,6 +959,22 @@ func NewSendRawTransactionCmd(hexTx string, allowHighFees *bool) *SendRawTransac
 	}
 }
 
+// NewExplainSendRawTransactionCmd returns a new instance which can be used to
+// issue a sendrawtransaction JSON-RPC command which explains why the
+// transaction is rejected when it is.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewExplainSendRawTransactionCmd(hexTx string, allowHighFees *bool) *SendRawTransactionCmd {
+	return &SendRawTransactionCmd{
+		HexTx: hexTx,
+		FeeSetting: &AllowHighFeesOrMaxFeeRate{
+			Value: allowHighFees,
+		},
+		Explain: Bool(true),
+	}
+}
+
 // NewSendRawTransactionCmd returns a new instance which can be used to issue a
 // sendrawtransaction JSON-RPC command to a bitcoind node.
 //
@@ -1048,9 +1133,11 @@ func init() {
 
 	MustRegisterCmd("addnode", (*AddNodeCmd)(nil), flags)
 	MustRegisterCmd("createrawtransaction", (*CreateRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("debugscript", (*DebugScriptCmd)(nil), flags)
 	MustRegisterCmd("decoderawtransaction", (*DecodeRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decodescript", (*DecodeScriptCmd)(nil), flags)
 	MustRegisterCmd("deriveaddresses", (*DeriveAddressesCmd)(nil), flags)
+	MustRegisterCmd("dumptxoutset", (*DumpTxOutSetCmd)(nil), flags)
 	MustRegisterCmd("fundrawtransaction", (*FundRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("getaddednodeinfo", (*GetAddedNodeInfoCmd)(nil), flags)
 	MustRegisterCmd("getbestblockhash", (*GetBestBlockHashCmd)(nil), flags)
@@ -1067,6 +1154,7 @@ func init() {
 	MustRegisterCmd("getchaintips", (*GetChainTipsCmd)(nil), flags)
 	MustRegisterCmd("getchaintxstats", (*GetChainTxStatsCmd)(nil), flags)
 	MustRegisterCmd("getconnectioncount", (*GetConnectionCountCmd)(nil), flags)
+	MustRegisterCmd("getdeploymentinfo", (*GetDeploymentInfoCmd)(nil), flags)
 	MustRegisterCmd("getdescriptorinfo", (*GetDescriptorInfoCmd)(nil), flags)
 	MustRegisterCmd("getdifficulty", (*GetDifficultyCmd)(nil), flags)
 	MustRegisterCmd("getgenerate", (*GetGenerateCmd)(nil), flags)
@@ -1088,6 +1176,7 @@ func init() {
 	MustRegisterCmd("getwork", (*GetWorkCmd)(nil), flags)
 	MustRegisterCmd("help", (*HelpCmd)(nil), flags)
 	MustRegisterCmd("invalidateblock", (*InvalidateBlockCmd)(nil), flags)
+	MustRegisterCmd("loadtxoutset", (*LoadTxOutSetCmd)(nil), flags)
 	MustRegisterCmd("ping", (*PingCmd)(nil), flags)
 	MustRegisterCmd("preciousblock", (*PreciousBlockCmd)(nil), flags)
 	MustRegisterCmd("reconsiderblock", (*ReconsiderBlockCmd)(nil), flags)
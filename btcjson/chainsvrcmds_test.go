@@ -206,8 +206,36 @@ func TestChainSvrCmds(t *testing.T) {
 			staticCmd: func() interface{} {
 				return btcjson.NewDecodeRawTransactionCmd("123")
 			},
-			marshalled:   `{"jsonrpc":"1.0","method":"decoderawtransaction","params":["123"],"id":1}`,
-			unmarshalled: &btcjson.DecodeRawTransactionCmd{HexTx: "123"},
+			marshalled: `{"jsonrpc":"1.0","method":"decoderawtransaction","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.DecodeRawTransactionCmd{
+				HexTx:   "123",
+				Explain: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "decoderawtransaction optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("decoderawtransaction", "123", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewExplainDecodeRawTransactionCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"decoderawtransaction","params":["123",true],"id":1}`,
+			unmarshalled: &btcjson.DecodeRawTransactionCmd{
+				HexTx:   "123",
+				Explain: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "debugscript",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("debugscript", "123", 1)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDebugScriptCmd("123", 1)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"debugscript","params":["123",1],"id":1}`,
+			unmarshalled: &btcjson.DebugScriptCmd{HexTx: "123", Vin: 1},
 		},
 		{
 			name: "decodescript",
@@ -265,6 +293,17 @@ func TestChainSvrCmds(t *testing.T) {
 				Range:      &btcjson.DescriptorRange{Value: []int{0, 2}},
 			},
 		},
+		{
+			name: "dumptxoutset",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("dumptxoutset", "utxo.dat")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDumpTxOutSetCmd("utxo.dat")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"dumptxoutset","params":["utxo.dat"],"id":1}`,
+			unmarshalled: &btcjson.DumpTxOutSetCmd{Path: "utxo.dat"},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -655,6 +694,30 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getconnectioncount","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetConnectionCountCmd{},
 		},
+		{
+			name: "getdeploymentinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdeploymentinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDeploymentInfoCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdeploymentinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetDeploymentInfoCmd{},
+		},
+		{
+			name: "getdeploymentinfo optional blockhash",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdeploymentinfo", btcjson.String("0000afaf"))
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDeploymentInfoCmd(btcjson.String("0000afaf"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdeploymentinfo","params":["0000afaf"],"id":1}`,
+			unmarshalled: &btcjson.GetDeploymentInfoCmd{
+				BlockHash: btcjson.String("0000afaf"),
+			},
+		},
 		{
 			name: "getdifficulty",
 			newCmd: func() (interface{}, error) {
@@ -1025,6 +1088,17 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "loadtxoutset",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("loadtxoutset", "utxo.dat")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewLoadTxOutSetCmd("utxo.dat")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"loadtxoutset","params":["utxo.dat"],"id":1}`,
+			unmarshalled: &btcjson.LoadTxOutSetCmd{Path: "utxo.dat"},
+		},
 		{
 			name: "ping",
 			newCmd: func() (interface{}, error) {
@@ -1235,6 +1309,7 @@ func TestChainSvrCmds(t *testing.T) {
 				FeeSetting: &btcjson.AllowHighFeesOrMaxFeeRate{
 					Value: btcjson.Bool(false),
 				},
+				Explain: btcjson.Bool(false),
 			},
 		},
 		{
@@ -1251,6 +1326,7 @@ func TestChainSvrCmds(t *testing.T) {
 				FeeSetting: &btcjson.AllowHighFeesOrMaxFeeRate{
 					Value: btcjson.Bool(false),
 				},
+				Explain: btcjson.Bool(false),
 			},
 		},
 		{
@@ -1267,6 +1343,24 @@ func TestChainSvrCmds(t *testing.T) {
 				FeeSetting: &btcjson.AllowHighFeesOrMaxFeeRate{
 					Value: btcjson.Int32(1234),
 				},
+				Explain: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "sendrawtransaction explain",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendrawtransaction", "1122", &btcjson.AllowHighFeesOrMaxFeeRate{Value: btcjson.Bool(false)}, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewExplainSendRawTransactionCmd("1122", btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendrawtransaction","params":["1122",false,true],"id":1}`,
+			unmarshalled: &btcjson.SendRawTransactionCmd{
+				HexTx: "1122",
+				FeeSetting: &btcjson.AllowHighFeesOrMaxFeeRate{
+					Value: btcjson.Bool(false),
+				},
+				Explain: btcjson.Bool(true),
 			},
 		},
 		{
@@ -117,6 +117,58 @@ type GetBlockVerboseTxResult struct {
 	NextHash      string        `json:"nextblockhash,omitempty"`
 }
 
+// GetChainTipsResult models the data returned from the getchaintips command.
+type GetChainTipsResult struct {
+	Height    int32  `json:"height"`
+	Hash      string `json:"hash"`
+	BranchLen int32  `json:"branchlen"`
+	Status    string `json:"status"`
+}
+
+// DeploymentStatistics models the signalling statistics of the current period
+// of a deployment returned from the getdeploymentinfo command.
+type DeploymentStatistics struct {
+	Period    uint32 `json:"period"`
+	Threshold uint32 `json:"threshold"`
+	Elapsed   uint32 `json:"elapsed"`
+	Count     uint32 `json:"count"`
+	Possible  bool   `json:"possible"`
+}
+
+// Bip9DeploymentInfo models the version bits details of a deployment returned
+// from the getdeploymentinfo command.  Time-based deployments set the start
+// time and timeout while height-based ones set the start and timeout heights.
+type Bip9DeploymentInfo struct {
+	Bit                 uint8                 `json:"bit"`
+	StartTime           int64                 `json:"start_time,omitempty"`
+	Timeout             int64                 `json:"timeout,omitempty"`
+	StartHeight         int32                 `json:"start_height,omitempty"`
+	TimeoutHeight       int32                 `json:"timeout_height,omitempty"`
+	LockInOnTimeout     bool                  `json:"lockinontimeout,omitempty"`
+	MinActivationHeight int32                 `json:"min_activation_height"`
+	Status              string                `json:"status"`
+	Since               int32                 `json:"since"`
+	StatusNext          string                `json:"status_next"`
+	Statistics          *DeploymentStatistics `json:"statistics,omitempty"`
+}
+
+// DeploymentInfo models the state of a deployment returned from the
+// getdeploymentinfo command.
+type DeploymentInfo struct {
+	Type   string              `json:"type"`
+	Active bool                `json:"active"`
+	Height int32               `json:"height,omitempty"`
+	Bip9   *Bip9DeploymentInfo `json:"bip9,omitempty"`
+}
+
+// GetDeploymentInfoResult models the data returned from the getdeploymentinfo
+// command.
+type GetDeploymentInfoResult struct {
+	Hash        string                     `json:"hash"`
+	Height      int32                      `json:"height"`
+	Deployments map[string]*DeploymentInfo `json:"deployments"`
+}
+
 // GetChainTxStatsResult models the data from the getchaintxstats command.
 type GetChainTxStatsResult struct {
 	Time                   int64   `json:"time"`
@@ -136,6 +188,38 @@ type CreateMultiSigResult struct {
 	RedeemScript string `json:"redeemScript"`
 }
 
+// DebugScriptSigCheck models a signature check performed by a step of the
+// debugscript command.
+type DebugScriptSigCheck struct {
+	PubKey    string `json:"pubkey"`
+	Signature string `json:"signature"`
+	Valid     bool   `json:"valid"`
+}
+
+// DebugScriptStep models a step of the script execution traced by the
+// debugscript command.
+type DebugScriptStep struct {
+	Script    int                   `json:"script"`
+	Index     int                   `json:"index"`
+	Opcode    string                `json:"opcode,omitempty"`
+	Stack     []string              `json:"stack"`
+	AltStack  []string              `json:"altstack"`
+	CondStack []string              `json:"condstack"`
+	SigChecks []DebugScriptSigCheck `json:"sigchecks,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
+
+// DebugScriptResult models the data returned from the debugscript command.
+type DebugScriptResult struct {
+	Txid         string            `json:"txid"`
+	Vin          uint32            `json:"vin"`
+	ScriptPubKey string            `json:"scriptpubkey"`
+	Amount       float64           `json:"amount"`
+	Valid        bool              `json:"valid"`
+	Error        string            `json:"error,omitempty"`
+	Steps        []DebugScriptStep `json:"steps"`
+}
+
 // DecodeScriptResult models the data returned from the decodescript command.
 type DecodeScriptResult struct {
 	Asm       string   `json:"asm"`
@@ -448,10 +532,44 @@ type GetTxOutSetInfoResult struct {
 	TxOuts         int64          `json:"txouts"`
 	BogoSize       int64          `json:"bogosize"`
 	HashSerialized chainhash.Hash `json:"hash_serialized_2"`
+	MuHash         chainhash.Hash `json:"muhash"`
 	DiskSize       int64          `json:"disk_size"`
 	TotalAmount    btcutil.Amount `json:"total_amount"`
 }
 
+// MarshalJSON marshals the result of the gettxoutsetinfo JSON-RPC call.  The
+// hashes are marshalled as strings and omitted when they were not calculated,
+// and the total amount is marshalled in BTC.
+func (g GetTxOutSetInfoResult) MarshalJSON() ([]byte, error) {
+	aux := struct {
+		Height         int64   `json:"height"`
+		BestBlock      string  `json:"bestblock"`
+		Transactions   int64   `json:"transactions,omitempty"`
+		TxOuts         int64   `json:"txouts"`
+		BogoSize       int64   `json:"bogosize"`
+		HashSerialized string  `json:"hash_serialized_2,omitempty"`
+		MuHash         string  `json:"muhash,omitempty"`
+		DiskSize       int64   `json:"disk_size"`
+		TotalAmount    float64 `json:"total_amount"`
+	}{
+		Height:       g.Height,
+		BestBlock:    g.BestBlock.String(),
+		Transactions: g.Transactions,
+		TxOuts:       g.TxOuts,
+		BogoSize:     g.BogoSize,
+		DiskSize:     g.DiskSize,
+		TotalAmount:  g.TotalAmount.ToBTC(),
+	}
+	if g.HashSerialized != (chainhash.Hash{}) {
+		aux.HashSerialized = g.HashSerialized.String()
+	}
+	if g.MuHash != (chainhash.Hash{}) {
+		aux.MuHash = g.MuHash.String()
+	}
+
+	return json.Marshal(aux)
+}
+
 // UnmarshalJSON unmarshals the result of the gettxoutsetinfo JSON-RPC call
 func (g *GetTxOutSetInfoResult) UnmarshalJSON(data []byte) error {
 	// Step 1: Create type aliases of the original struct.
@@ -462,6 +580,7 @@ func (g *GetTxOutSetInfoResult) UnmarshalJSON(data []byte) error {
 	aux := &struct {
 		BestBlock      string  `json:"bestblock"`
 		HashSerialized string  `json:"hash_serialized_2"`
+		MuHash         string  `json:"muhash"`
 		TotalAmount    float64 `json:"total_amount"`
 		*Alias
 	}{
@@ -488,6 +607,13 @@ func (g *GetTxOutSetInfoResult) UnmarshalJSON(data []byte) error {
 
 	g.HashSerialized = *serializedHash
 
+	muHash, err := chainhash.NewHashFromStr(aux.MuHash)
+	if err != nil {
+		return err
+	}
+
+	g.MuHash = *muHash
+
 	amount, err := btcutil.NewAmount(aux.TotalAmount)
 	if err != nil {
 		return err
@@ -498,6 +624,24 @@ func (g *GetTxOutSetInfoResult) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// DumpTxOutSetResult models the data from the dumptxoutset command.
+type DumpTxOutSetResult struct {
+	CoinsWritten uint64 `json:"coins_written"`
+	BaseHash     string `json:"base_hash"`
+	BaseHeight   int32  `json:"base_height"`
+	Path         string `json:"path"`
+	TxOutSetHash string `json:"txoutset_hash"`
+	NChainTx     uint64 `json:"nchaintx"`
+}
+
+// LoadTxOutSetResult models the data from the loadtxoutset command.
+type LoadTxOutSetResult struct {
+	CoinsLoaded uint64 `json:"coins_loaded"`
+	TipHash     string `json:"tip_hash"`
+	BaseHeight  int32  `json:"base_height"`
+	Path        string `json:"path"`
+}
+
 // GetNetTotalsResult models the data returned from the getnettotals command.
 type GetNetTotalsResult struct {
 	TotalBytesRecv uint64 `json:"totalbytesrecv"`
@@ -743,11 +887,55 @@ type SearchRawTransactionsResult struct {
 
 // TxRawDecodeResult models the data from the decoderawtransaction command.
 type TxRawDecodeResult struct {
-	Txid     string `json:"txid"`
-	Version  int32  `json:"version"`
-	Locktime uint32 `json:"locktime"`
-	Vin      []Vin  `json:"vin"`
-	Vout     []Vout `json:"vout"`
+	Txid         string              `json:"txid"`
+	Version      int32               `json:"version"`
+	Locktime     uint32              `json:"locktime"`
+	Vin          []Vin               `json:"vin"`
+	Vout         []Vout              `json:"vout"`
+	Standardness *StandardnessResult `json:"standardness,omitempty"`
+}
+
+// StandardnessRuleResult models the result of a policy rule evaluated when
+// explaining the standardness of a transaction.
+type StandardnessRuleResult struct {
+	Rule       string `json:"rule"`
+	Passed     bool   `json:"passed"`
+	RejectCode string `json:"rejectcode,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// StandardnessVinResult models the results of the policy rules evaluated
+// against a transaction input.
+type StandardnessVinResult struct {
+	N              uint32                   `json:"n"`
+	PrevOutMissing bool                     `json:"prevoutmissing"`
+	Rules          []StandardnessRuleResult `json:"rules"`
+}
+
+// StandardnessVoutResult models the results of the policy rules evaluated
+// against a transaction output.
+type StandardnessVoutResult struct {
+	N     uint32                   `json:"n"`
+	Rules []StandardnessRuleResult `json:"rules"`
+}
+
+// StandardnessResult models the explanation of the standardness of a
+// transaction returned by the decoderawtransaction and sendrawtransaction
+// commands.
+type StandardnessResult struct {
+	Standard bool                     `json:"standard"`
+	Rules    []StandardnessRuleResult `json:"rules"`
+	Vin      []StandardnessVinResult  `json:"vin"`
+	Vout     []StandardnessVoutResult `json:"vout"`
+}
+
+// SendRawTransactionResult models the data from the sendrawtransaction command
+// when an explanation of the standardness of the transaction is requested.
+type SendRawTransactionResult struct {
+	TxID         string              `json:"txid"`
+	Accepted     bool                `json:"accepted"`
+	RejectReason string              `json:"rejectreason,omitempty"`
+	Standardness *StandardnessResult `json:"standardness"`
 }
 
 // ValidateAddressChainResult models the data returned by the chain server
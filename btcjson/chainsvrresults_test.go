@@ -72,6 +72,19 @@ func TestChainSvrCustomResults(t *testing.T) {
 			},
 			expected: `{"txid":"123","vout":1,"scriptSig":{"asm":"0","hex":"00"},"prevOut":{"addresses":["addr1"],"value":0},"sequence":4294967295}`,
 		},
+		{
+			name: "custom gettxoutsetinfo marshal with muhash",
+			result: &btcjson.GetTxOutSetInfoResult{
+				Height:      123,
+				BestBlock:   chainhash.Hash{0x01},
+				TxOuts:      2,
+				BogoSize:    100,
+				MuHash:      chainhash.Hash{0x02},
+				DiskSize:    80,
+				TotalAmount: 20000000,
+			},
+			expected: `{"height":123,"bestblock":"0000000000000000000000000000000000000000000000000000000000000001","txouts":2,"bogosize":100,"muhash":"0000000000000000000000000000000000000000000000000000000000000002","disk_size":80,"total_amount":0.2}`,
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -136,6 +149,33 @@ func TestGetTxOutSetInfoResult(t *testing.T) {
 				}(),
 			},
 		},
+		{
+			name:   "GetTxOutSetInfoResult - muhash",
+			result: `{"height":123,"bestblock":"000000000000005f94116250e2407310463c0a7cf950f1af9ebe935b1c0687ab","txouts":1,"bogosize":1,"muhash":"10d312b100cbd32ada024a6646e40d3482fcff103668d2625f10002a607d5863","disk_size":1,"total_amount":0.2}`,
+			want: btcjson.GetTxOutSetInfoResult{
+				Height: 123,
+				BestBlock: func() chainhash.Hash {
+					h, err := chainhash.NewHashFromStr("000000000000005f94116250e2407310463c0a7cf950f1af9ebe935b1c0687ab")
+					if err != nil {
+						panic(err)
+					}
+
+					return *h
+				}(),
+				TxOuts:   1,
+				BogoSize: 1,
+				MuHash: func() chainhash.Hash {
+					h, err := chainhash.NewHashFromStr("10d312b100cbd32ada024a6646e40d3482fcff103668d2625f10002a607d5863")
+					if err != nil {
+						panic(err)
+					}
+
+					return *h
+				}(),
+				DiskSize:    1,
+				TotalAmount: 20000000,
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
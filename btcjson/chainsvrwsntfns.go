@@ -31,6 +31,13 @@ const (
 	// disconnected.
 	FilteredBlockDisconnectedNtfnMethod = "filteredblockdisconnected"
 
+	// ChainReorgNtfnMethod is the method used for notifications from the
+	// chain server that the main chain has been reorganized.  It is sent
+	// before the notifications for the individual blocks, except after
+	// them for the reorganizations caused by invalidateblock,
+	// reconsiderblock and preciousblock.
+	ChainReorgNtfnMethod = "chainreorg"
+
 	// RecvTxNtfnMethod is the legacy, deprecated method used for
 	// notifications from the chain server that a transaction which pays to
 	// a registered address has been processed.
@@ -161,6 +168,30 @@ type BlockDetails struct {
 	Time   int64  `json:"time"`
 }
 
+// ChainReorgNtfn defines the chainreorg JSON-RPC notification.
+type ChainReorgNtfn struct {
+	ForkHash   string
+	ForkHeight int32
+	Detached   []string
+	Attached   []string
+}
+
+// NewChainReorgNtfn returns a new instance which can be used to issue a
+// chainreorg JSON-RPC notification.  The detached block hashes are in the
+// order the blocks were disconnected, starting with the old best block, and
+// the attached block hashes in the order the blocks were connected, ending
+// with the new best block.
+func NewChainReorgNtfn(forkHash string, forkHeight int32, detached,
+	attached []string) *ChainReorgNtfn {
+
+	return &ChainReorgNtfn{
+		ForkHash:   forkHash,
+		ForkHeight: forkHeight,
+		Detached:   detached,
+		Attached:   attached,
+	}
+}
+
 // RecvTxNtfn defines the recvtx JSON-RPC notification.
 //
 // Deprecated: Use RelevantTxAcceptedNtfn and FilteredBlockConnectedNtfn
@@ -294,6 +325,7 @@ func init() {
 	MustRegisterCmd(BlockDisconnectedNtfnMethod, (*BlockDisconnectedNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockConnectedNtfnMethod, (*FilteredBlockConnectedNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockDisconnectedNtfnMethod, (*FilteredBlockDisconnectedNtfn)(nil), flags)
+	MustRegisterCmd(ChainReorgNtfnMethod, (*ChainReorgNtfn)(nil), flags)
 	MustRegisterCmd(RecvTxNtfnMethod, (*RecvTxNtfn)(nil), flags)
 	MustRegisterCmd(RedeemingTxNtfnMethod, (*RedeemingTxNtfn)(nil), flags)
 	MustRegisterCmd(RescanFinishedNtfnMethod, (*RescanFinishedNtfn)(nil), flags)
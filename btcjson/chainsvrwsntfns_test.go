@@ -88,6 +88,22 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				Header: "header",
 			},
 		},
+		{
+			name: "chainreorg",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("chainreorg", "123", 100000, []string{"456"}, []string{"789", "abc"})
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewChainReorgNtfn("123", 100000, []string{"456"}, []string{"789", "abc"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"chainreorg","params":["123",100000,["456"],["789","abc"]],"id":null}`,
+			unmarshalled: &btcjson.ChainReorgNtfn{
+				ForkHash:   "123",
+				ForkHeight: 100000,
+				Detached:   []string{"456"},
+				Attached:   []string{"789", "abc"},
+			},
+		},
 		{
 			name: "recvtx",
 			newNtfn: func() (interface{}, error) {
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// checksumLength is the number of characters of a descriptor checksum.
+	checksumLength = 8
+
+	// inputCharset is the set of characters a descriptor may consist of.
+	// The position of every character determines how it's committed to by
+	// the checksum, such that the most common typos are detected.
+	inputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+	// checksumCharset is the set of characters of descriptor checksums.
+	checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// checksumGenerator houses the generator of the BCH code of descriptor
+// checksums.
+var checksumGenerator = [5]uint64{
+	0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd,
+}
+
+// polymod updates the passed checksum state with the passed 5-bit symbol.
+func polymod(chk uint64, symbol uint64) uint64 {
+	top := chk >> 35
+	chk = (chk&0x7ffffffff)<<5 ^ symbol
+	for i := 0; i < 5; i++ {
+		if (top>>uint(i))&1 == 1 {
+			chk ^= checksumGenerator[i]
+		}
+	}
+	return chk
+}
+
+// Checksum returns the checksum of the passed descriptor, which must not
+// include a checksum itself.
+func Checksum(desc string) (string, error) {
+	chk := uint64(1)
+	var groups []uint64
+	for i := 0; i < len(desc); i++ {
+		pos := strings.IndexByte(inputCharset, desc[i])
+		if pos == -1 {
+			return "", fmt.Errorf("invalid character %q in "+
+				"descriptor", desc[i])
+		}
+
+		// Every character contributes its lower 5 bits as a symbol and
+		// the groups of its upper bits are combined into an additional
+		// symbol for every three characters.
+		chk = polymod(chk, uint64(pos&31))
+		groups = append(groups, uint64(pos>>5))
+		if len(groups) == 3 {
+			chk = polymod(chk, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+	switch len(groups) {
+	case 1:
+		chk = polymod(chk, groups[0])
+	case 2:
+		chk = polymod(chk, groups[0]*3+groups[1])
+	}
+	for i := 0; i < checksumLength; i++ {
+		chk = polymod(chk, 0)
+	}
+	chk ^= 1
+
+	var checksum [checksumLength]byte
+	for i := range checksum {
+		shift := uint(5 * (checksumLength - 1 - i))
+		checksum[i] = checksumCharset[(chk>>shift)&31]
+	}
+	return string(checksum[:]), nil
+}
+
+// splitChecksum splits the checksum off the passed descriptor and verifies it
+// when there is one.  It returns the descriptor without its checksum and
+// whether it had one.
+func splitChecksum(desc string) (string, bool, error) {
+	idx := strings.IndexByte(desc, '#')
+	if idx == -1 {
+		return desc, false, nil
+	}
+
+	desc, checksum := desc[:idx], desc[idx+1:]
+	if len(checksum) != checksumLength {
+		return "", false, fmt.Errorf("checksum %q is not %d characters",
+			checksum, checksumLength)
+	}
+	expected, err := Checksum(desc)
+	if err != nil {
+		return "", false, err
+	}
+	if checksum != expected {
+		return "", false, fmt.Errorf("checksum %q doesn't match the "+
+			"expected checksum %q", checksum, expected)
+	}
+	return desc, true, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import "testing"
+
+// TestChecksum ensures descriptor checksums are calculated according to the
+// test vectors of BIP0380.
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		checksum string
+	}{{
+		desc:     "raw(deadbeef)",
+		checksum: "89f8spxm",
+	}, {
+		desc:     "addr(mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j)",
+		checksum: "02wpgw69",
+	}, {
+		desc: "sh(multi(2,[00000000/111'/222]xpub6ERApfZwUNrhLCkDtcHT" +
+			"cxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY" +
+			"4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL,xpub68NZiKmJWnxxS6aaHm" +
+			"n81bvJeTESw724CRDs6HbuccFQN9Ku14VQrADWgqbhhTHBaohPX4CjN" +
+			"Lf9fq9MYo6oDaPPLPxSb7gwQN3ih19Zm4Y/0))",
+		checksum: "tjg09x5t",
+	}}
+
+	for i, test := range tests {
+		checksum, err := Checksum(test.desc)
+		if err != nil {
+			t.Errorf("Checksum #%d: unexpected error: %v", i, err)
+			continue
+		}
+		if checksum != test.checksum {
+			t.Errorf("Checksum #%d: mismatched checksum - got %s, "+
+				"want %s", i, checksum, test.checksum)
+		}
+	}
+
+	if _, err := Checksum("raw(deadbeef)\x00"); err == nil {
+		t.Fatal("Checksum: no error for invalid character")
+	}
+}
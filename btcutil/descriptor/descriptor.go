@@ -0,0 +1,675 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	// maxBareMultisigKeys and maxP2SHMultisigKeys are the maximum number
+	// of keys of multisig scripts in bare and P2SH outputs respectively,
+	// which is lower than the consensus limit of the number of keys for
+	// standardness and the script size limit of P2SH outputs.
+	maxBareMultisigKeys = 3
+	maxP2SHMultisigKeys = 15
+
+	// maxTapTreeDepth is the maximum depth of taproot script trees.
+	maxTapTreeDepth = txscript.ControlBlockMaxNodeCount
+)
+
+// ErrNoAddress is returned when the outputs of a descriptor don't have an
+// address, such as bare multisig outputs.
+var ErrNoAddress = errors.New("descriptor outputs don't have an address")
+
+// scriptContext identifies where a script expression is used, which determines
+// the expressions and keys it may contain.
+type scriptContext uint8
+
+const (
+	ctxTop    scriptContext = iota // top-level expression
+	ctxP2SH                        // inside sh()
+	ctxP2WPKH                      // key of wpkh()
+	ctxP2WSH                       // inside wsh()
+	ctxP2TR                        // key or script tree of tr()
+)
+
+// descKind identifies the kind of a script expression.
+type descKind uint8
+
+const (
+	kindPk          descKind = iota // pk(KEY)
+	kindPkh                         // pkh(KEY)
+	kindWpkh                        // wpkh(KEY)
+	kindSh                          // sh(SCRIPT)
+	kindWsh                         // wsh(SCRIPT)
+	kindMulti                       // multi(k,KEY,...)
+	kindSortedMulti                 // sortedmulti(k,KEY,...)
+	kindTr                          // tr(KEY) or tr(KEY,TREE)
+	kindAddr                        // addr(ADDR)
+	kindRaw                         // raw(HEX)
+)
+
+// kindNames maps the kinds of script expressions to their names.
+var kindNames = map[descKind]string{
+	kindPk:          "pk",
+	kindPkh:         "pkh",
+	kindWpkh:        "wpkh",
+	kindSh:          "sh",
+	kindWsh:         "wsh",
+	kindMulti:       "multi",
+	kindSortedMulti: "sortedmulti",
+	kindTr:          "tr",
+	kindAddr:        "addr",
+	kindRaw:         "raw",
+}
+
+// Descriptor is a parsed output script descriptor, or a script expression
+// within one.
+type Descriptor struct {
+	kind descKind
+
+	// keys are the keys of pk, pkh, wpkh, multi and sortedmulti
+	// expressions and the internal key of tr expressions.
+	keys []*keyExpr
+
+	// threshold is the number of required signatures of multisig
+	// expressions.
+	threshold int
+
+	// sub is the script expression of sh and wsh expressions.
+	sub *Descriptor
+
+	// tree is the script tree of tr expressions, which is nil for
+	// outputs without one.
+	tree *tapTree
+
+	// addr and script are the address of addr expressions and the script
+	// of raw expressions.
+	addr   btcutil.Address
+	script []byte
+
+	net *chaincfg.Params
+}
+
+// tapTree is a node of a taproot script tree, which is either a leaf with a
+// script expression or a branch with two children.
+type tapTree struct {
+	leaf        *Descriptor
+	left, right *tapTree
+}
+
+// Parse parses the passed output script descriptor for the passed network.
+// The checksum of the descriptor is verified when it has one.
+func Parse(desc string, net *chaincfg.Params) (*Descriptor, error) {
+	desc, _, err := splitChecksum(desc)
+	if err != nil {
+		return nil, err
+	}
+	return parseScriptExpr(desc, ctxTop, net)
+}
+
+// ParseWithChecksum parses the passed output script descriptor for the passed
+// network like Parse, but requires it to have a valid checksum.
+func ParseWithChecksum(desc string, net *chaincfg.Params) (*Descriptor,
+	error) {
+
+	desc, ok, err := splitChecksum(desc)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("missing descriptor checksum")
+	}
+	return parseScriptExpr(desc, ctxTop, net)
+}
+
+// splitArgs splits the passed arguments of an expression at the commas which
+// aren't nested in parentheses, braces or brackets.
+func splitArgs(str string) ([]string, error) {
+	var args []string
+	depth, start := 0, 0
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '(', '{', '[':
+			depth++
+		case ')', '}', ']':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced %q in %q",
+					str[i], str)
+			}
+		case ',':
+			if depth == 0 {
+				args = append(args, str[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets in %q", str)
+	}
+	return append(args, str[start:]), nil
+}
+
+// parseCall splits the passed expression of the form name(args) into its name
+// and arguments.
+func parseCall(expr string) (string, []string, error) {
+	start := strings.IndexByte(expr, '(')
+	if start == -1 || !strings.HasSuffix(expr, ")") {
+		return "", nil, fmt.Errorf("invalid script expression %q", expr)
+	}
+	args, err := splitArgs(expr[start+1 : len(expr)-1])
+	if err != nil {
+		return "", nil, err
+	}
+	return expr[:start], args, nil
+}
+
+// checkArgs returns an error when the passed number of arguments of the
+// expression with the passed name isn't the expected number.
+func checkArgs(name string, args []string, num int) error {
+	if len(args) != num {
+		return fmt.Errorf("%s takes %d arguments instead of %d", name,
+			num, len(args))
+	}
+	return nil
+}
+
+// parseScriptExpr parses the passed script expression used in the passed
+// context.
+func parseScriptExpr(expr string, ctx scriptContext,
+	net *chaincfg.Params) (*Descriptor, error) {
+
+	name, args, err := parseCall(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only pk expressions are allowed in taproot script trees, and sh,
+	// tr, addr and raw expressions only at the top level.
+	d := &Descriptor{net: net}
+	switch {
+	case ctx == ctxP2TR && name != "pk":
+		return nil, fmt.Errorf("%s is not allowed in taproot script "+
+			"trees", name)
+	case ctx != ctxTop && (name == "sh" || name == "tr" ||
+		name == "addr" || name == "raw"):
+
+		return nil, fmt.Errorf("%s is only allowed at the top level",
+			name)
+	}
+
+	switch name {
+	case "pk", "pkh", "wpkh":
+		if err := checkArgs(name, args, 1); err != nil {
+			return nil, err
+		}
+		d.kind = kindPk
+		keyCtx := ctx
+		switch name {
+		case "pkh":
+			d.kind = kindPkh
+		case "wpkh":
+			if ctx != ctxTop && ctx != ctxP2SH {
+				return nil, errors.New("wpkh is only allowed at " +
+					"the top level or inside sh")
+			}
+			d.kind = kindWpkh
+			keyCtx = ctxP2WPKH
+		}
+		key, err := parseKeyExpr(args[0], keyCtx, net)
+		if err != nil {
+			return nil, err
+		}
+		d.keys = []*keyExpr{key}
+		return d, nil
+
+	case "sh", "wsh":
+		if err := checkArgs(name, args, 1); err != nil {
+			return nil, err
+		}
+		d.kind = kindSh
+		subCtx := ctxP2SH
+		if name == "wsh" {
+			if ctx != ctxTop && ctx != ctxP2SH {
+				return nil, errors.New("wsh is only allowed at " +
+					"the top level or inside sh")
+			}
+			d.kind = kindWsh
+			subCtx = ctxP2WSH
+		}
+		d.sub, err = parseScriptExpr(args[0], subCtx, net)
+		if err != nil {
+			return nil, err
+		}
+		return d, nil
+
+	case "multi", "sortedmulti":
+		d.kind = kindMulti
+		if name == "sortedmulti" {
+			d.kind = kindSortedMulti
+		}
+		return d, d.parseMulti(args, ctx)
+
+	case "tr":
+		if len(args) != 1 && len(args) != 2 {
+			return nil, fmt.Errorf("tr takes 1 or 2 arguments instead "+
+				"of %d", len(args))
+		}
+		d.kind = kindTr
+		key, err := parseKeyExpr(args[0], ctxP2TR, net)
+		if err != nil {
+			return nil, err
+		}
+		d.keys = []*keyExpr{key}
+		if len(args) == 2 {
+			d.tree, err = parseTapTree(args[1], 0, net)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return d, nil
+
+	case "addr":
+		if err := checkArgs(name, args, 1); err != nil {
+			return nil, err
+		}
+		d.kind = kindAddr
+		d.addr, err = btcutil.DecodeAddress(args[0], net)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %v", args[0],
+				err)
+		}
+		if !d.addr.IsForNet(net) {
+			return nil, fmt.Errorf("address %q is not for network %s",
+				args[0], net.Name)
+		}
+		d.script, err = txscript.PayToAddrScript(d.addr)
+		if err != nil {
+			return nil, err
+		}
+		return d, nil
+
+	case "raw":
+		if err := checkArgs(name, args, 1); err != nil {
+			return nil, err
+		}
+		d.kind = kindRaw
+		d.script, err = hex.DecodeString(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex script %q", args[0])
+		}
+		return d, nil
+	}
+
+	return nil, fmt.Errorf("unknown script expression %q", name)
+}
+
+// parseMulti parses the passed threshold and keys of a multisig expression
+// used in the passed context.
+func (d *Descriptor) parseMulti(args []string, ctx scriptContext) error {
+	if len(args) < 2 {
+		return fmt.Errorf("%s requires a threshold and at least one key",
+			kindNames[d.kind])
+	}
+
+	numKeys := len(args) - 1
+	maxKeys := txscript.MaxPubKeysPerMultiSig
+	switch ctx {
+	case ctxTop:
+		maxKeys = maxBareMultisigKeys
+	case ctxP2SH:
+		maxKeys = maxP2SHMultisigKeys
+	}
+	if numKeys > maxKeys {
+		return fmt.Errorf("%s with %d keys exceeds the maximum of %d",
+			kindNames[d.kind], numKeys, maxKeys)
+	}
+
+	threshold, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil || threshold == 0 || threshold > uint64(numKeys) {
+		return fmt.Errorf("invalid threshold %q: expected a number "+
+			"between 1 and %d", args[0], numKeys)
+	}
+	d.threshold = int(threshold)
+
+	for _, arg := range args[1:] {
+		key, err := parseKeyExpr(arg, ctx, d.net)
+		if err != nil {
+			return err
+		}
+		d.keys = append(d.keys, key)
+	}
+	return nil
+}
+
+// parseTapTree parses the passed taproot script tree at the passed depth,
+// which is either a script expression or a pair of trees in braces.
+func parseTapTree(str string, depth int, net *chaincfg.Params) (*tapTree,
+	error) {
+
+	if !strings.HasPrefix(str, "{") {
+		leaf, err := parseScriptExpr(str, ctxP2TR, net)
+		if err != nil {
+			return nil, err
+		}
+		return &tapTree{leaf: leaf}, nil
+	}
+
+	if depth == maxTapTreeDepth {
+		return nil, fmt.Errorf("taproot script tree exceeds the "+
+			"maximum depth of %d", maxTapTreeDepth)
+	}
+	if !strings.HasSuffix(str, "}") {
+		return nil, fmt.Errorf("missing closing brace in %q", str)
+	}
+	children, err := splitArgs(str[1 : len(str)-1])
+	if err != nil {
+		return nil, err
+	}
+	if len(children) != 2 {
+		return nil, fmt.Errorf("taproot script tree branch with %d "+
+			"children instead of 2", len(children))
+	}
+	left, err := parseTapTree(children[0], depth+1, net)
+	if err != nil {
+		return nil, err
+	}
+	right, err := parseTapTree(children[1], depth+1, net)
+	if err != nil {
+		return nil, err
+	}
+	return &tapTree{left: left, right: right}, nil
+}
+
+// walk calls the passed function for the expression and all of its nested
+// script expressions.
+func (d *Descriptor) walk(f func(d *Descriptor)) {
+	f(d)
+	if d.sub != nil {
+		d.sub.walk(f)
+	}
+	var walkTree func(tree *tapTree)
+	walkTree = func(tree *tapTree) {
+		if tree == nil {
+			return
+		}
+		if tree.leaf != nil {
+			tree.leaf.walk(f)
+		}
+		walkTree(tree.left)
+		walkTree(tree.right)
+	}
+	walkTree(d.tree)
+}
+
+// IsRange returns whether the descriptor describes a range of outputs, which
+// is the case when it contains a key with a wildcard.
+func (d *Descriptor) IsRange() bool {
+	isRange := false
+	d.walk(func(d *Descriptor) {
+		for _, key := range d.keys {
+			isRange = isRange || key.isRange()
+		}
+	})
+	return isRange
+}
+
+// String returns the descriptor along with its checksum.
+func (d *Descriptor) String() string {
+	desc := d.exprString()
+	checksum, err := Checksum(desc)
+	if err != nil {
+		return desc
+	}
+	return desc + "#" + checksum
+}
+
+// exprString returns the script expression without a checksum.
+func (d *Descriptor) exprString() string {
+	var args []string
+	switch d.kind {
+	case kindSh, kindWsh:
+		args = append(args, d.sub.exprString())
+	case kindMulti, kindSortedMulti:
+		args = append(args, strconv.Itoa(d.threshold))
+	case kindAddr:
+		args = append(args, d.addr.String())
+	case kindRaw:
+		args = append(args, hex.EncodeToString(d.script))
+	}
+	for _, key := range d.keys {
+		args = append(args, key.String())
+	}
+	if d.tree != nil {
+		args = append(args, d.tree.String())
+	}
+	return kindNames[d.kind] + "(" + strings.Join(args, ",") + ")"
+}
+
+// String returns the taproot script tree expression.
+func (t *tapTree) String() string {
+	if t.leaf != nil {
+		return t.leaf.exprString()
+	}
+	return "{" + t.left.String() + "," + t.right.String() + "}"
+}
+
+// serializeKeys returns the serialized keys of the expression at the passed
+// index.
+func (d *Descriptor) serializeKeys(index uint32) ([][]byte, error) {
+	keys := make([][]byte, 0, len(d.keys))
+	for _, key := range d.keys {
+		serialized, err := key.serialize(index)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, serialized)
+	}
+	if d.kind == kindSortedMulti {
+		sort.Slice(keys, func(i, j int) bool {
+			return bytes.Compare(keys[i], keys[j]) < 0
+		})
+	}
+	return keys, nil
+}
+
+// Script returns the script of the expression at the passed index, which is
+// ignored unless the descriptor is a range.  For top-level descriptors, this
+// is the public key script of the output.
+func (d *Descriptor) Script(index uint32) ([]byte, error) {
+	if d.kind == kindAddr || d.kind == kindRaw {
+		return d.script, nil
+	}
+	if d.kind == kindSh || d.kind == kindWsh || d.kind == kindTr {
+		addr, err := d.Address(index)
+		if err != nil {
+			return nil, err
+		}
+		return txscript.PayToAddrScript(addr)
+	}
+
+	keys, err := d.serializeKeys(index)
+	if err != nil {
+		return nil, err
+	}
+	builder := txscript.NewScriptBuilder()
+	switch d.kind {
+	case kindPk:
+		builder.AddData(keys[0]).AddOp(txscript.OP_CHECKSIG)
+
+	case kindPkh:
+		builder.AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).
+			AddData(btcutil.Hash160(keys[0])).
+			AddOp(txscript.OP_EQUALVERIFY).
+			AddOp(txscript.OP_CHECKSIG)
+
+	case kindWpkh:
+		builder.AddOp(txscript.OP_0).AddData(btcutil.Hash160(keys[0]))
+
+	case kindMulti, kindSortedMulti:
+		builder.AddInt64(int64(d.threshold))
+		for _, key := range keys {
+			builder.AddData(key)
+		}
+		builder.AddInt64(int64(len(keys))).
+			AddOp(txscript.OP_CHECKMULTISIG)
+	}
+	return builder.Script()
+}
+
+// RedeemScript returns the redeem script of P2SH outputs and the witness
+// script of P2WSH outputs at the passed index, which is ignored unless the
+// descriptor is a range.  Nested P2WSH outputs return their witness script.
+// Descriptors of other outputs return nil.
+func (d *Descriptor) RedeemScript(index uint32) ([]byte, error) {
+	switch {
+	case d.kind == kindSh && d.sub.kind == kindWsh:
+		return d.sub.sub.Script(index)
+	case d.kind == kindSh || d.kind == kindWsh:
+		return d.sub.Script(index)
+	}
+	return nil, nil
+}
+
+// tapRoot returns the root hash of the taproot script tree at the passed index.
+func (t *tapTree) tapRoot(index uint32) ([]byte, error) {
+	if t.leaf != nil {
+		script, err := t.leaf.Script(index)
+		if err != nil {
+			return nil, err
+		}
+		leafHash := txscript.NewBaseTapLeaf(script).TapHash()
+		return leafHash[:], nil
+	}
+
+	left, err := t.left.tapRoot(index)
+	if err != nil {
+		return nil, err
+	}
+	right, err := t.right.tapRoot(index)
+	if err != nil {
+		return nil, err
+	}
+	branchHash := txscript.TapBranchHash(left, right)
+	return branchHash[:], nil
+}
+
+// Address returns the address of the output at the passed index, which is
+// ignored unless the descriptor is a range.  ErrNoAddress is returned for
+// outputs without an address, such as pay-to-pubkey and bare multisig
+// outputs.
+func (d *Descriptor) Address(index uint32) (btcutil.Address, error) {
+	switch d.kind {
+	case kindPkh, kindWpkh:
+		keys, err := d.serializeKeys(index)
+		if err != nil {
+			return nil, err
+		}
+		keyHash := btcutil.Hash160(keys[0])
+		if d.kind == kindPkh {
+			return btcutil.NewAddressPubKeyHash(keyHash, d.net)
+		}
+		return btcutil.NewAddressWitnessPubKeyHash(keyHash, d.net)
+
+	case kindSh:
+		script, err := d.sub.Script(index)
+		if err != nil {
+			return nil, err
+		}
+		if len(script) > txscript.MaxScriptElementSize {
+			return nil, fmt.Errorf("redeem script of size %d exceeds "+
+				"the maximum of %d", len(script),
+				txscript.MaxScriptElementSize)
+		}
+		return btcutil.NewAddressScriptHash(script, d.net)
+
+	case kindWsh:
+		script, err := d.sub.Script(index)
+		if err != nil {
+			return nil, err
+		}
+		scriptHash := sha256.Sum256(script)
+		return btcutil.NewAddressWitnessScriptHash(scriptHash[:], d.net)
+
+	case kindTr:
+		internalKey, err := d.keys[0].derive(index)
+		if err != nil {
+			return nil, err
+		}
+		var root []byte
+		if d.tree != nil {
+			root, err = d.tree.tapRoot(index)
+			if err != nil {
+				return nil, err
+			}
+		}
+		outputKey, err := txscript.ComputeTaprootOutputKey(internalKey,
+			root)
+		if err != nil {
+			return nil, err
+		}
+		return btcutil.NewAddressTaproot(
+			schnorr.SerializePubKey(outputKey), d.net)
+
+	case kindAddr:
+		return d.addr, nil
+
+	case kindRaw:
+		class, addrs, _, err := txscript.ExtractPkScriptAddrs(d.script,
+			d.net)
+		if err != nil || len(addrs) != 1 {
+			return nil, ErrNoAddress
+		}
+		switch class {
+		case txscript.PubKeyHashTy, txscript.ScriptHashTy,
+			txscript.WitnessV0PubKeyHashTy,
+			txscript.WitnessV0ScriptHashTy:
+
+			return addrs[0], nil
+		}
+	}
+
+	return nil, ErrNoAddress
+}
+
+// Scripts returns the public key scripts of the outputs at the indices from
+// start up to but not including end, or the only output script when the
+// descriptor isn't a range.
+func (d *Descriptor) Scripts(start, end uint32) ([][]byte, error) {
+	if !d.IsRange() {
+		script, err := d.Script(0)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{script}, nil
+	}
+	if end < start {
+		return nil, fmt.Errorf("invalid range [%d,%d)", start, end)
+	}
+
+	scripts := make([][]byte, 0, end-start)
+	for index := start; index < end; index++ {
+		script, err := d.Script(index)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
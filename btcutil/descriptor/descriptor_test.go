@@ -0,0 +1,376 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	// tv1MasterPriv is the master private key of BIP0032 test vector 1.
+	tv1MasterPriv = "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3j" +
+		"PPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+
+	// tv1ChildPub is the extended public key at m/0' of BIP0032 test
+	// vector 1.
+	tv1ChildPub = "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WE" +
+		"jWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"
+)
+
+// TestDescriptors ensures descriptors are parsed and derive the expected
+// scripts and addresses.
+func TestDescriptors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		desc    string
+		index   uint32
+		script  string
+		isRange bool
+		noAddr  bool
+	}{{
+		name: "pk",
+		desc: "pk(0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959" +
+			"f2815b16f81798)",
+		script: "210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959" +
+			"f2815b16f81798ac",
+		noAddr: true,
+	}, {
+		name: "pkh",
+		desc: "pkh(02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7ab" +
+			"ac09b95c709ee5)",
+		script: "76a91406afd46bcdfd22ef94ac122aa11f241244a37ecc88ac",
+	}, {
+		name: "wpkh",
+		desc: "wpkh(02f9308a019258c31049344f85f89d5229b531c845836f99b0" +
+			"8601f113bce036f9)",
+		script: "00147dd65592d0ab2fe0d0257d571abf032cd9db93dc",
+	}, {
+		name: "sh(wpkh)",
+		desc: "sh(wpkh(03fff97bd5755eeea420453a14355235d382f6472f8568a1" +
+			"8b2f057a1460297556))",
+		script: "a914cc6ffbc0bf31af759451068f90ba7a0272b6b33287",
+	}, {
+		name: "wsh(pkh)",
+		desc: "wsh(pkh(02e493dbf1c10d80f3581e4904930b1404cc6c13900ee075" +
+			"8474fa94abe8c4cd13))",
+		script: "0020fc5acc302aab97f821f9a61e1cc572e7968a603551e95d4ba1" +
+			"2b51df6581482f",
+	}, {
+		name: "tr",
+		desc: "tr(a34b99f22c790c4e36b2b3c2c35a36db06226e41c692fc82b8b56a" +
+			"c1c540c5bd)",
+		script: "512077aab6e066f8a7419c5ab714c12c67d25007ed55a43cadcacb4" +
+			"d7a970a093f11",
+	}, {
+		name: "multi",
+		desc: "multi(1,022f8bde4d1a07209355b4a7250a5c5128e88b84bddc619ab" +
+			"7cba8d569b240efe4,025cbdf0646e5db4eaa398f365f2ea7a0e3d4" +
+			"19b7e0330e39ce92bddedcac4f9bc)",
+		script: "5121022f8bde4d1a07209355b4a7250a5c5128e88b84bddc619ab7" +
+			"cba8d569b240efe421025cbdf0646e5db4eaa398f365f2ea7a0e3d4" +
+			"19b7e0330e39ce92bddedcac4f9bc52ae",
+		noAddr: true,
+	}, {
+		name: "sortedmulti",
+		desc: "sortedmulti(1,025cbdf0646e5db4eaa398f365f2ea7a0e3d419b7e" +
+			"0330e39ce92bddedcac4f9bc,022f8bde4d1a07209355b4a7250a5c" +
+			"5128e88b84bddc619ab7cba8d569b240efe4)",
+		script: "5121022f8bde4d1a07209355b4a7250a5c5128e88b84bddc619ab7" +
+			"cba8d569b240efe421025cbdf0646e5db4eaa398f365f2ea7a0e3d4" +
+			"19b7e0330e39ce92bddedcac4f9bc52ae",
+		noAddr: true,
+	}, {
+		name:  "hardened range from extended private key",
+		desc:  "pkh([d34db33f/44'/0'/0']" + tv1MasterPriv + "/0'/1/2'/*)",
+		index: 2,
+		// Public key at m/0'/1/2'/2 of BIP0032 test vector 1.
+		script: "76a914" + hex.EncodeToString(btcutil.Hash160(hexToBytes(
+			"02e8445082a72f29b75ca48748a914df60622a609cacfce8ed0e"+
+				"35804560741d29"))) + "88ac",
+		isRange: true,
+	}, {
+		name:  "range from extended public key",
+		desc:  "wpkh(" + tv1ChildPub + "/*)",
+		index: 1,
+		// Public key at m/0'/1 of BIP0032 test vector 1.
+		script: "0014" + hex.EncodeToString(btcutil.Hash160(hexToBytes(
+			"03501e454bf00751f24b1b489aa925215d66af2234e3891c3b21"+
+				"a52bedb3cd711c"))),
+		isRange: true,
+	}, {
+		name:   "raw",
+		desc:   "raw(deadbeef)",
+		script: "deadbeef",
+		noAddr: true,
+	}}
+
+	for _, test := range tests {
+		d, err := Parse(test.desc, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if d.IsRange() != test.isRange {
+			t.Errorf("%s: mismatched range - got %v, want %v",
+				test.name, d.IsRange(), test.isRange)
+		}
+
+		// The descriptor must round trip including its checksum.
+		checksum, err := Checksum(test.desc)
+		if err != nil {
+			t.Errorf("%s: unexpected checksum error: %v", test.name,
+				err)
+			continue
+		}
+		if d.String() != test.desc+"#"+checksum {
+			t.Errorf("%s: mismatched string - got %s", test.name, d)
+		}
+		if _, err := ParseWithChecksum(d.String(),
+			&chaincfg.MainNetParams); err != nil {
+
+			t.Errorf("%s: unexpected error parsing with checksum: %v",
+				test.name, err)
+		}
+
+		script, err := d.Script(test.index)
+		if err != nil {
+			t.Errorf("%s: unexpected script error: %v", test.name, err)
+			continue
+		}
+		if got := hex.EncodeToString(script); got != test.script {
+			t.Errorf("%s: mismatched script - got %s, want %s",
+				test.name, got, test.script)
+		}
+
+		// The address must pay to the same script.
+		addr, err := d.Address(test.index)
+		if test.noAddr {
+			if err != ErrNoAddress {
+				t.Errorf("%s: unexpected address error - got %v, "+
+					"want %v", test.name, err, ErrNoAddress)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected address error: %v", test.name,
+				err)
+			continue
+		}
+		addrScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !bytes.Equal(addrScript, script) {
+			t.Errorf("%s: address %v pays to %x instead of %x",
+				test.name, addr, addrScript, script)
+		}
+
+		// An addr descriptor of the address must have the same script.
+		addrDesc, err := Parse("addr("+addr.String()+")",
+			&chaincfg.MainNetParams)
+		if err != nil {
+			t.Errorf("%s: unexpected addr error: %v", test.name, err)
+			continue
+		}
+		addrDescScript, _ := addrDesc.Script(0)
+		if !bytes.Equal(addrDescScript, script) {
+			t.Errorf("%s: mismatched addr script - got %x, want %x",
+				test.name, addrDescScript, script)
+		}
+	}
+}
+
+// hexToBytes converts the passed hex string into bytes and will panic if there
+// is an error.  This is only provided for the hard-coded constants so errors in
+// the source code can be detected.  It will only (and must only) be called with
+// hard-coded values.
+func hexToBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in source file: " + s)
+	}
+	return b
+}
+
+// TestTaprootScriptTree ensures the outputs of taproot descriptors commit to
+// their script trees.
+func TestTaprootScriptTree(t *testing.T) {
+	t.Parallel()
+
+	const (
+		internalKey = "a34b99f22c790c4e36b2b3c2c35a36db06226e41c692fc82b8b" +
+			"56ac1c540c5bd"
+		leafKey1 = "669b8afcec803a0d323e9a17f3ea8e68e8abe5a278020a929adbe" +
+			"c52421adbd0"
+	)
+	desc := "tr(" + internalKey + ",{pk(" + leafKey1 + "),pk(" +
+		tv1ChildPub + "/*)})"
+	d, err := Parse(desc, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.IsRange() {
+		t.Fatal("taproot descriptor with ranged leaf is not a range")
+	}
+
+	// The second leaf is the x-only public key at m/0'/1 of BIP0032 test
+	// vector 1.
+	leafScript := func(key string) []byte {
+		script, err := txscript.NewScriptBuilder().
+			AddData(hexToBytes(key)).AddOp(txscript.OP_CHECKSIG).
+			Script()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return script
+	}
+	leaf1 := txscript.NewBaseTapLeaf(leafScript(leafKey1)).TapHash()
+	leaf2 := txscript.NewBaseTapLeaf(leafScript("501e454bf00751f24b1b48" +
+		"9aa925215d66af2234e3891c3b21a52bedb3cd711c")).TapHash()
+	root := txscript.TapBranchHash(leaf1[:], leaf2[:])
+	pubKey, err := btcec.ParsePubKey(hexToBytes("02"+internalKey),
+		btcec.S256())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	outputKey, err := txscript.ComputeTaprootOutputKey(pubKey, root[:])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := append([]byte{txscript.OP_1, txscript.OP_DATA_32},
+		schnorr.SerializePubKey(outputKey)...)
+
+	script, err := d.Script(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(script, want) {
+		t.Fatalf("mismatched script - got %x, want %x", script, want)
+	}
+	if d.String() != desc+"#"+mustChecksum(t, desc) {
+		t.Fatalf("mismatched string - got %s", d)
+	}
+}
+
+// mustChecksum returns the checksum of the passed descriptor.
+func mustChecksum(t *testing.T, desc string) string {
+	checksum, err := Checksum(desc)
+	if err != nil {
+		t.Fatalf("unexpected checksum error: %v", err)
+	}
+	return checksum
+}
+
+// TestParseErrors ensures invalid descriptors are rejected.
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	const (
+		key = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac0" +
+			"9b95c709ee5"
+		uncompressed = "04a34b99f22c790c4e36b2b3c2c35a36db06226e41c692fc8" +
+			"2b8b56ac1c540c5bd5b8dec5235a0fa8722476c7709c02559e3aa73a" +
+			"a03918ba2d492eea75abea235"
+	)
+
+	tests := []struct {
+		name string
+		desc string
+		err  string
+	}{{
+		name: "unknown script expression",
+		desc: "foo(" + key + ")",
+		err:  "unknown script expression",
+	}, {
+		name: "bad checksum",
+		desc: "raw(deadbeef)#89f8spxn",
+		err:  "doesn't match",
+	}, {
+		name: "short checksum",
+		desc: "raw(deadbeef)#89f8spx",
+		err:  "not 8 characters",
+	}, {
+		name: "sh inside wsh",
+		desc: "wsh(sh(pk(" + key + ")))",
+		err:  "only allowed at the top level",
+	}, {
+		name: "wpkh inside wsh",
+		desc: "wsh(wpkh(" + key + "))",
+		err:  "wpkh is only allowed",
+	}, {
+		name: "uncompressed key in segwit",
+		desc: "wpkh(" + uncompressed + ")",
+		err:  "uncompressed key",
+	}, {
+		name: "x-only key outside taproot",
+		desc: "pk(" + key[2:] + ")",
+		err:  "invalid public key",
+	}, {
+		name: "hardened derivation from public key",
+		desc: "pkh(" + tv1ChildPub + "/1'/*)",
+		err:  "hardened derivation",
+	}, {
+		name: "bad fingerprint",
+		desc: "pkh([d34db3/0']" + key + ")",
+		err:  "fingerprint",
+	}, {
+		name: "too many bare multisig keys",
+		desc: "multi(1," + key + "," + key + "," + key + "," + key + ")",
+		err:  "exceeds the maximum",
+	}, {
+		name: "threshold exceeds keys",
+		desc: "sh(multi(3," + key + "," + key + "))",
+		err:  "invalid threshold",
+	}, {
+		name: "pkh in taproot tree",
+		desc: "tr(" + key + ",pkh(" + key + "))",
+		err:  "not allowed in taproot script trees",
+	}, {
+		name: "taproot tree branch with three children",
+		desc: "tr(" + key + ",{pk(" + key + "),pk(" + key + "),pk(" +
+			key + ")})",
+		err: "instead of 2",
+	}, {
+		name: "unbalanced parentheses",
+		desc: "sh(pk(" + key + ")",
+		err:  "unbalanced",
+	}}
+
+	for _, test := range tests {
+		_, err := Parse(test.desc, &chaincfg.MainNetParams)
+		if err == nil {
+			t.Errorf("%s: parsed invalid descriptor", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: unexpected error - got %v, want %q",
+				test.name, err, test.err)
+		}
+	}
+
+	_, err := ParseWithChecksum("raw(deadbeef)", &chaincfg.MainNetParams)
+	if err == nil {
+		t.Fatal("ParseWithChecksum: parsed descriptor without checksum")
+	}
+
+	// Extended keys must be encoded for the network of the descriptor.
+	_, err = Parse("pkh("+tv1ChildPub+"/*)", &chaincfg.TestNet3Params)
+	if err == nil || !strings.Contains(err.Error(), "not for network") {
+		t.Fatalf("Parse: unexpected error for extended key of other "+
+			"network: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package descriptor implements output script descriptors as defined by BIP 380
+through BIP 386.
+
+Overview
+
+An output script descriptor is a human-readable description of a set of output
+scripts, such as
+
+	wpkh([d34db33f/84'/0'/0']xpub6DJ2dNUysrn5.../0/*)
+
+which describes the pay-to-witness-pubkey-hash outputs of the keys derived from
+an extended public key at the indices in the place of the wildcard.
+Descriptors may be followed by a # character and an 8-character checksum, which
+guards against typos when descriptors are copied by hand.
+
+Supported Descriptors
+
+The following script expressions are supported:
+
+	pk(KEY)                  pay-to-pubkey output
+	pkh(KEY)                 pay-to-pubkey-hash output
+	wpkh(KEY)                pay-to-witness-pubkey-hash output
+	sh(SCRIPT)               pay-to-script-hash output of a script
+	wsh(SCRIPT)              pay-to-witness-script-hash output of a script
+	multi(k,KEY,...)         k-of-n multisig script
+	sortedmulti(k,KEY,...)   k-of-n multisig script with sorted keys
+	tr(KEY) and tr(KEY,TREE) taproot output with an optional script tree
+	addr(ADDR)               output paying to an address
+	raw(HEX)                 output with a raw script
+
+where the script trees of taproot outputs consist of pk(KEY) leaves combined in
+pairs with braces, such as {pk(KEY1),{pk(KEY2),pk(KEY3)}}.
+
+Key expressions are hex-encoded public keys, WIF-encoded private keys or
+extended keys followed by a derivation path, which may end with a wildcard
+written as /* or /*' for unhardened and hardened derivation respectively.  They
+may be preceded by the key origin in brackets, which consists of the
+fingerprint of the master key and the derivation path from it.
+
+Since extended keys, private keys and addresses are encoded for a specific
+network, descriptors are parsed for the parameters of a network such as
+chaincfg.MainNetParams and derive the scripts and addresses of that network.
+*/
+package descriptor
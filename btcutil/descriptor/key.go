@@ -0,0 +1,294 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// wildcard identifies whether and how keys are derived from an extended key at
+// the index of a ranged descriptor.
+type wildcard uint8
+
+const (
+	// wildcardNone is used for keys which don't depend on the index.
+	wildcardNone wildcard = iota
+
+	// wildcardUnhardened is used for keys derived at the index, written as
+	// /* after the path.
+	wildcardUnhardened
+
+	// wildcardHardened is used for keys derived at the hardened index,
+	// written as /*' after the path.
+	wildcardHardened
+)
+
+// keyExpr is a parsed key expression, which is either a fixed public or private
+// key or an extended key along with the path to derive keys at.
+type keyExpr struct {
+	// fingerprint and originPath are the fingerprint of the master key
+	// and the derivation path from it to the key, which are only known
+	// when the key origin is written.
+	fingerprint []byte
+	originPath  []uint32
+
+	// key is the key as written, without its origin and derivation path.
+	key string
+
+	// pubKey is the fixed public key, which is serialized in compressed
+	// form when compressed is set.
+	pubKey     *btcec.PublicKey
+	compressed bool
+
+	// extKey is the extended key and path and wildcard describe the
+	// derivation of the keys from it.
+	extKey   *hdkeychain.ExtendedKey
+	path     []uint32
+	wildcard wildcard
+
+	// xOnly is set for keys of taproot outputs and scripts, which are
+	// serialized as their x coordinate only.
+	xOnly bool
+}
+
+// parsePath parses the passed elements of a derivation path, where hardened
+// elements are suffixed by ' or h.
+func parsePath(elems []string) ([]uint32, error) {
+	path := make([]uint32, 0, len(elems))
+	for _, elem := range elems {
+		var offset uint32
+		if strings.HasSuffix(elem, "'") || strings.HasSuffix(elem, "h") {
+			offset = hdkeychain.HardenedKeyStart
+			elem = elem[:len(elem)-1]
+		}
+		index, err := strconv.ParseUint(elem, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path element "+
+				"%q", elem)
+		}
+		path = append(path, uint32(index)+offset)
+	}
+	return path, nil
+}
+
+// formatPath returns the passed derivation path as written after a key, where
+// every element is preceded by a slash.
+func formatPath(path []uint32) string {
+	var str strings.Builder
+	for _, index := range path {
+		str.WriteByte('/')
+		if index >= hdkeychain.HardenedKeyStart {
+			str.WriteString(strconv.FormatUint(
+				uint64(index-hdkeychain.HardenedKeyStart), 10))
+			str.WriteByte('\'')
+			continue
+		}
+		str.WriteString(strconv.FormatUint(uint64(index), 10))
+	}
+	return str.String()
+}
+
+// parseKeyExpr parses the passed key expression used in the passed context.
+// Private and extended keys must be encoded for the passed network.
+func parseKeyExpr(str string, ctx scriptContext,
+	net *chaincfg.Params) (*keyExpr, error) {
+
+	k := &keyExpr{xOnly: ctx == ctxP2TR}
+
+	// The key origin is the fingerprint of the master key followed by the
+	// derivation path in brackets.
+	if strings.HasPrefix(str, "[") {
+		end := strings.IndexByte(str, ']')
+		if end == -1 {
+			return nil, fmt.Errorf("missing closing bracket of key "+
+				"origin in %q", str)
+		}
+		elems := strings.Split(str[1:end], "/")
+		fingerprint, err := hex.DecodeString(elems[0])
+		if err != nil || len(fingerprint) != 4 {
+			return nil, fmt.Errorf("invalid key origin fingerprint "+
+				"%q: expected 8 hex characters", elems[0])
+		}
+		k.fingerprint = fingerprint
+		k.originPath, err = parsePath(elems[1:])
+		if err != nil {
+			return nil, err
+		}
+		str = str[end+1:]
+	}
+	if strings.ContainsAny(str, "[]") {
+		return nil, fmt.Errorf("unexpected key origin in %q", str)
+	}
+
+	elems := strings.Split(str, "/")
+	k.key = elems[0]
+	if len(elems) == 1 {
+		// Fixed keys are either hex-encoded public keys, which are
+		// x-only keys in taproot outputs, or WIF-encoded private keys.
+		if keyBytes, err := hex.DecodeString(k.key); err == nil {
+			if len(keyBytes) == 32 && k.xOnly {
+				keyBytes = append([]byte{0x02}, keyBytes...)
+			}
+			pubKey, err := btcec.ParsePubKey(keyBytes, btcec.S256())
+			if err == nil && keyBytes[0] != 0x02 &&
+				keyBytes[0] != 0x03 && keyBytes[0] != 0x04 {
+
+				err = errors.New("hybrid public keys are not " +
+					"allowed")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid public key %q: %v",
+					k.key, err)
+			}
+			k.pubKey = pubKey
+			k.compressed = len(keyBytes) ==
+				btcec.PubKeyBytesLenCompressed
+			return k, k.checkCompressed(ctx)
+		}
+
+		if wif, err := btcutil.DecodeWIF(k.key); err == nil {
+			if !wif.IsForNet(net) {
+				return nil, fmt.Errorf("private key %q is not "+
+					"for network %s", k.key, net.Name)
+			}
+			k.pubKey = wif.PrivKey.PubKey()
+			k.compressed = wif.CompressPubKey
+			return k, k.checkCompressed(ctx)
+		}
+	}
+
+	extKey, err := hdkeychain.NewKeyFromString(k.key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %q: expected a public, "+
+			"private or extended key", k.key)
+	}
+	if !extKey.IsForNet(net) {
+		return nil, fmt.Errorf("extended key %q is not for network %s",
+			k.key, net.Name)
+	}
+	k.extKey = extKey
+	k.compressed = true
+
+	elems = elems[1:]
+	if len(elems) != 0 {
+		switch elems[len(elems)-1] {
+		case "*":
+			k.wildcard = wildcardUnhardened
+		case "*'", "*h":
+			k.wildcard = wildcardHardened
+		}
+		if k.wildcard != wildcardNone {
+			elems = elems[:len(elems)-1]
+		}
+	}
+	k.path, err = parsePath(elems)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hardened keys can only be derived from extended private keys.
+	if !extKey.IsPrivate() {
+		hardened := k.wildcard == wildcardHardened
+		for _, index := range k.path {
+			hardened = hardened || index >= hdkeychain.HardenedKeyStart
+		}
+		if hardened {
+			return nil, fmt.Errorf("hardened derivation from "+
+				"extended public key %q", k.key)
+		}
+	}
+
+	return k, nil
+}
+
+// checkCompressed returns an error when the key is uncompressed and used in a
+// context which only permits compressed keys.
+func (k *keyExpr) checkCompressed(ctx scriptContext) error {
+	if !k.compressed && ctx != ctxTop && ctx != ctxP2SH {
+		return fmt.Errorf("uncompressed key %q is not allowed in "+
+			"segwit outputs", k.key)
+	}
+	return nil
+}
+
+// isRange returns whether the key depends on the index of the descriptor.
+func (k *keyExpr) isRange() bool {
+	return k.wildcard != wildcardNone
+}
+
+// derive returns the public key at the passed index, which is ignored unless
+// the key has a wildcard.
+func (k *keyExpr) derive(index uint32) (*btcec.PublicKey, error) {
+	if k.extKey == nil {
+		return k.pubKey, nil
+	}
+
+	path := k.path
+	switch k.wildcard {
+	case wildcardUnhardened, wildcardHardened:
+		if index >= hdkeychain.HardenedKeyStart {
+			return nil, fmt.Errorf("index %d is out of range", index)
+		}
+		if k.wildcard == wildcardHardened {
+			index += hdkeychain.HardenedKeyStart
+		}
+		path = append(path[:len(path):len(path)], index)
+	}
+
+	extKey := k.extKey
+	for _, i := range path {
+		var err error
+		extKey, err = extKey.Derive(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return extKey.ECPubKey()
+}
+
+// serialize returns the serialized public key at the passed index.
+func (k *keyExpr) serialize(index uint32) ([]byte, error) {
+	pubKey, err := k.derive(index)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case k.xOnly:
+		return schnorr.SerializePubKey(pubKey), nil
+	case k.compressed:
+		return pubKey.SerializeCompressed(), nil
+	}
+	return pubKey.SerializeUncompressed(), nil
+}
+
+// String returns the key expression.
+func (k *keyExpr) String() string {
+	var str strings.Builder
+	if k.fingerprint != nil {
+		str.WriteByte('[')
+		str.WriteString(hex.EncodeToString(k.fingerprint))
+		str.WriteString(formatPath(k.originPath))
+		str.WriteByte(']')
+	}
+	str.WriteString(k.key)
+	str.WriteString(formatPath(k.path))
+	switch k.wildcard {
+	case wildcardUnhardened:
+		str.WriteString("/*")
+	case wildcardHardened:
+		str.WriteString("/*'")
+	}
+	return str.String()
+}
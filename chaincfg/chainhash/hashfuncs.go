@@ -31,3 +31,67 @@ func DoubleHashH(b []byte) Hash {
 	first := sha256.Sum256(b)
 	return Hash(sha256.Sum256(first[:]))
 }
+
+var (
+	// TagBIP0340Aux is the BIP0340 tag used to hash the auxiliary
+	// randomness which masks the private key when deriving a nonce.
+	TagBIP0340Aux = []byte("BIP0340/aux")
+
+	// TagBIP0340Nonce is the BIP0340 tag used to derive the nonce of a
+	// Schnorr signature.
+	TagBIP0340Nonce = []byte("BIP0340/nonce")
+
+	// TagBIP0340Challenge is the BIP0340 tag used to compute the challenge
+	// of a Schnorr signature.
+	TagBIP0340Challenge = []byte("BIP0340/challenge")
+
+	// TagTapSighash is the BIP0341 tag used to compute the signature hash
+	// of a taproot input.
+	TagTapSighash = []byte("TapSighash")
+
+	// TagTapLeaf is the BIP0341 tag used to compute the hash of a leaf of a
+	// taproot script tree.
+	TagTapLeaf = []byte("TapLeaf")
+
+	// TagTapBranch is the BIP0341 tag used to compute the hash of a branch
+	// of a taproot script tree.
+	TagTapBranch = []byte("TapBranch")
+
+	// TagTapTweak is the BIP0341 tag used to compute the tweak which
+	// commits a taproot output key to its internal key and script tree.
+	TagTapTweak = []byte("TapTweak")
+
+	// precomputedTags houses the hashes of the tags above so they are not
+	// hashed again every time a tagged hash is calculated.
+	precomputedTags = map[string]Hash{
+		string(TagBIP0340Aux):       sha256.Sum256(TagBIP0340Aux),
+		string(TagBIP0340Nonce):     sha256.Sum256(TagBIP0340Nonce),
+		string(TagBIP0340Challenge): sha256.Sum256(TagBIP0340Challenge),
+		string(TagTapSighash):       sha256.Sum256(TagTapSighash),
+		string(TagTapLeaf):          sha256.Sum256(TagTapLeaf),
+		string(TagTapBranch):        sha256.Sum256(TagTapBranch),
+		string(TagTapTweak):         sha256.Sum256(TagTapTweak),
+	}
+)
+
+// TaggedHash calculates the tagged hash defined in BIP0340 of the
+// concatenation of the passed messages, that is
+// sha256(sha256(tag) || sha256(tag) || msgs...).  Binding the hash to a tag
+// ensures hashes computed for one purpose can't be reused for another.
+func TaggedHash(tag []byte, msgs ...[]byte) *Hash {
+	tagHash, ok := precomputedTags[string(tag)]
+	if !ok {
+		tagHash = sha256.Sum256(tag)
+	}
+
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+
+	var hash Hash
+	copy(hash[:], h.Sum(nil))
+	return &hash
+}
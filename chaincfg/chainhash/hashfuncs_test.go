@@ -134,3 +134,31 @@ func TestDoubleHashFuncs(t *testing.T) {
 		}
 	}
 }
+
+// TestTaggedHash ensures the tagged hash is the hash of the doubled tag hash
+// followed by the messages for both precomputed and other tags.
+func TestTaggedHash(t *testing.T) {
+	tests := []struct {
+		tag  []byte
+		msgs [][]byte
+	}{
+		{TagTapLeaf, nil},
+		{TagTapTweak, [][]byte{[]byte("abc")}},
+		{[]byte("custom"), [][]byte{[]byte("ab"), []byte("c")}},
+	}
+
+	for _, test := range tests {
+		tagHash := HashB(test.tag)
+		data := append(append([]byte{}, tagHash...), tagHash...)
+		for _, msg := range test.msgs {
+			data = append(data, msg...)
+		}
+		want := HashH(data)
+
+		got := TaggedHash(test.tag, test.msgs...)
+		if *got != want {
+			t.Errorf("TaggedHash(%q): got %v, want %v", test.tag, got,
+				want)
+		}
+	}
+}
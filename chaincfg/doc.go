@@ -58,4 +58,8 @@
 // non-standard network.  As a general rule of thumb, all network parameters
 // should be unique to the network, but parameter collisions can still occur
 // (unfortunately, this is the case with regtest and testnet3 sharing magics).
+//
+// Non-standard networks may also be defined in a JSON or TOML parameter file
+// and loaded with LoadParamsFile.  The loaded parameters are checked with
+// Params.Validate and must then be registered with Register before use.
 package chaincfg
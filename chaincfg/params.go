@@ -8,6 +8,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"strings"
@@ -78,6 +79,24 @@ type Checkpoint struct {
 	Hash   *chainhash.Hash
 }
 
+// AssumeUtxo commits to a snapshot of the unspent transaction output set as of
+// a known block in the main chain.  A node may load a snapshot which matches a
+// commitment instead of building the set itself from the history of the chain,
+// which is then validated in the background.
+type AssumeUtxo struct {
+	// Height and BlockHash identify the block whose outputs the snapshot
+	// contains.
+	Height    int32
+	BlockHash *chainhash.Hash
+
+	// SnapshotHash is the hash of the serialized outputs in the snapshot.
+	SnapshotHash *chainhash.Hash
+
+	// ChainTxCount is the total number of transactions in the chain up to
+	// and including the block.
+	ChainTxCount uint64
+}
+
 // DNSSeed identifies a DNS seed.
 type DNSSeed struct {
 	// Host defines the hostname of the seed.
@@ -88,8 +107,56 @@ type DNSSeed struct {
 	HasFiltering bool
 }
 
+// DifficultyAlgorithm identifies a difficulty retarget algorithm that is used
+// to calculate the required difficulty of the next block in the chain.
+type DifficultyAlgorithm uint8
+
+const (
+	// DiffBitcoin is the original Bitcoin retarget algorithm which only
+	// adjusts the difficulty once every TargetTimespan worth of blocks.
+	DiffBitcoin DifficultyAlgorithm = iota
+
+	// DiffKimotoGravityWell is the Kimoto Gravity Well retarget algorithm
+	// which adjusts the difficulty on every block based on a variable
+	// sized window of past blocks.
+	DiffKimotoGravityWell
+)
+
+// difficultyAlgorithmStrings is a map of difficulty algorithms back to their
+// constant names for pretty printing.
+var difficultyAlgorithmStrings = map[DifficultyAlgorithm]string{
+	DiffBitcoin:           "DiffBitcoin",
+	DiffKimotoGravityWell: "DiffKimotoGravityWell",
+}
+
+// String returns the DifficultyAlgorithm as a human-readable name.
+func (a DifficultyAlgorithm) String() string {
+	if s := difficultyAlgorithmStrings[a]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown DifficultyAlgorithm (%d)", uint8(a))
+}
+
+// DifficultyFork identifies the difficulty retarget algorithm that is in
+// effect for all blocks starting at a given height.
+type DifficultyFork struct {
+	Height    int32
+	Algorithm DifficultyAlgorithm
+}
+
+// PowFork identifies the proof-of-work algorithm that is in effect for all
+// blocks starting at a given height.
+type PowFork struct {
+	Height    int32
+	Algorithm wire.PowAlgorithm
+}
+
 // ConsensusDeployment defines details related to a specific consensus rule
-// change that is voted in.  This is part of BIP0009.
+// change that is voted in.  This is part of BIP0009 and BIP0008.
+//
+// Deployments with a zero TimeoutHeight are time-based as defined by BIP0009
+// and use StartTime and ExpireTime.  Otherwise they are height-based as defined
+// by BIP0008 and use StartHeight, TimeoutHeight and LockInOnTimeout instead.
 type ConsensusDeployment struct {
 	// BitNumber defines the specific bit number within the block version
 	// this particular soft-fork deployment refers to.
@@ -102,6 +169,42 @@ type ConsensusDeployment struct {
 	// ExpireTime is the median block time after which the attempted
 	// deployment expires.
 	ExpireTime uint64
+
+	// StartHeight is the height of the block at which voting on the
+	// deployment starts.  It must be a multiple of the miner confirmation
+	// window.
+	StartHeight uint32
+
+	// TimeoutHeight is the height of the block at which the attempted
+	// deployment fails or, when LockInOnTimeout is set, is locked in.  It
+	// must be a multiple of the miner confirmation window.
+	TimeoutHeight uint32
+
+	// MinActivationHeight is the height of the block before which the
+	// deployment can't become active even when it was locked in earlier.
+	MinActivationHeight uint32
+
+	// LockInOnTimeout specifies whether blocks are required to signal for
+	// the deployment during the last confirmation window before the
+	// timeout height, which ensures it is locked in.
+	LockInOnTimeout bool
+
+	// AlwaysActive specifies whether the deployment is active for every
+	// block without being voted in.  This is only useful for test networks
+	// which exercise the new rules from the genesis block on.
+	AlwaysActive bool
+
+	// NeverActive specifies whether the deployment is never active and
+	// can't be voted in.  This is used for deployments whose rules are
+	// implemented before the network defines how they activate.
+	NeverActive bool
+}
+
+// HeightBased returns whether the deployment starts and times out at block
+// heights as defined by BIP0008 instead of at median block times as defined by
+// BIP0009.
+func (d *ConsensusDeployment) HeightBased() bool {
+	return d.TimeoutHeight != 0
 }
 
 // Constants that define the deployment offset in the deployments field of the
@@ -206,12 +309,41 @@ type Params struct {
 	// NOTE: This only applies if ReduceMinDifficulty is true.
 	MinDiffReductionTime time.Duration
 
+	// DifficultyForks defines the difficulty retarget algorithm schedule
+	// ordered from oldest to newest.  Each algorithm applies from the
+	// height of its entry up to, but not including, the height of the next
+	// entry.  Blocks before the first entry use DiffBitcoin.
+	DifficultyForks []DifficultyFork
+
+	// PowForks defines the proof-of-work algorithm schedule ordered from
+	// oldest to newest.  Each algorithm applies from the height of its
+	// entry up to, but not including, the height of the next entry.
+	// Blocks before the first entry use Lyra2REv2.
+	PowForks []PowFork
+
 	// GenerateSupported specifies whether or not CPU mining is allowed.
 	GenerateSupported bool
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints []Checkpoint
 
+	// AssumeValid is the hash of a block whose ancestors are assumed to have
+	// valid scripts by default, so their script validation is skipped during
+	// the initial sync.  It is nil when all scripts are validated.
+	AssumeValid *chainhash.Hash
+
+	// MinimumChainWork is the minimum amount of cumulative work the best
+	// known chain of headers through the assumed valid block must have
+	// before script validation is skipped for its ancestors.  It is nil when
+	// no minimum is enforced.
+	MinimumChainWork *big.Int
+
+	// AssumeUtxo lists the utxo set snapshots which may be loaded, ordered
+	// from oldest to newest.  Only the regression test network lists one,
+	// for the deterministic test chain, so snapshots can't be loaded on the
+	// other networks until commitments to real snapshots are added.
+	AssumeUtxo []AssumeUtxo
+
 	// These fields are related to voting on consensus rule changes as
 	// defined by BIP0009.
 	//
@@ -281,6 +413,20 @@ var MainNetParams = Params{
 	MinDiffReductionTime:     0,
 	GenerateSupported:        false,
 
+	// Difficulty retarget algorithms ordered from oldest to newest.
+	DifficultyForks: []DifficultyFork{
+		{0, DiffKimotoGravityWell},
+	},
+
+	// Proof-of-work algorithms ordered from oldest to newest.
+	PowForks: []PowFork{
+		{0, wire.PowScryptN},
+		{208301, wire.PowLyra2RE},
+		{347000, wire.PowLyra2REv2},
+		{1080000, wire.PowLyra2REv3},
+		{1500000, wire.PowVerthash},
+	},
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{
         {  24200, newHashFromStr("d7ed819858011474c8b0cae4ad0b9bdbb745becc4c386bc22d1220cc5a4d1787")},
@@ -296,6 +442,13 @@ var MainNetParams = Params{
         {  1474747, newHashFromStr("edf23a98cc196888635a01ba4672680df0b7c16eb48146e706b6f2e669974934")},
 	},
 
+	// There is no default assumed valid block since scripts are already not
+	// validated up to the final checkpoint.  A default must be a block after
+	// the final checkpoint and be set together with the minimum chain work
+	// of a chain burying it.
+	AssumeValid:      nil,
+	MinimumChainWork: nil,
+
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
@@ -318,6 +471,13 @@ var MainNetParams = Params{
 			StartTime:  1488326400, // Mar 1st, 2017
 			ExpireTime: 1519862400, // Mar 1st, 2018
 		},
+		// The network does not define how taproot activates, so its
+		// rules are never enforced.  Version 1 witness programs remain
+		// anyone can spend as they are for nodes which predate taproot,
+		// so the chain is still followed if taproot is activated.
+		DeploymentTaproot: {
+			NeverActive: true,
+		},
 	},
 
 	// Mempool parameters
@@ -375,9 +535,25 @@ var RegressionNetParams = Params{
 	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
 	GenerateSupported:        true,
 
+	// Proof-of-work algorithms ordered from oldest to newest.
+	PowForks: []PowFork{
+		{0, wire.PowLyra2REv2},
+	},
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{},
 
+	// The utxo snapshot of the deterministic test chain used by the
+	// blockchain package tests.
+	AssumeUtxo: []AssumeUtxo{
+		{
+			Height:       110,
+			BlockHash:    newHashFromStr("eea2aefcf1e6cecce0887e3411ec309d9e0cb2288485f1c9b4583e02e4fe4cc5"),
+			SnapshotHash: newHashFromStr("edb3a4e08250884d580bd72114054d039102282ef755e3933e2241721d60d987"),
+			ChainTxCount: 111,
+		},
+	},
+
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
@@ -400,6 +576,10 @@ var RegressionNetParams = Params{
 			StartTime:  0,             // Always available for vote
 			ExpireTime: math.MaxInt64, // Never expires.
 		},
+		DeploymentTaproot: {
+			BitNumber:    2,
+			AlwaysActive: true, // Active from the genesis block
+		},
 	},
 
 	// Mempool parameters
@@ -458,6 +638,16 @@ var TestNet3Params = Params{
 	MinDiffReductionTime:     time.Second * 150 * 2, // TargetTimePerBlock * 2
 	GenerateSupported:        false,
 
+	// Difficulty retarget algorithms ordered from oldest to newest.
+	DifficultyForks: []DifficultyFork{
+		{0, DiffKimotoGravityWell},
+	},
+
+	// Proof-of-work algorithms ordered from oldest to newest.
+	PowForks: []PowFork{
+		{0, wire.PowLyra2REv2},
+	},
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{},
 
@@ -483,6 +673,13 @@ var TestNet3Params = Params{
 			StartTime:  1488326400, // Mar 1st, 2017
 			ExpireTime: 1519862400, // Mar 1st, 2018
 		},
+		// The network does not define how taproot activates, so its
+		// rules are never enforced.  Version 1 witness programs remain
+		// anyone can spend as they are for nodes which predate taproot,
+		// so the chain is still followed if taproot is activated.
+		DeploymentTaproot: {
+			NeverActive: true,
+		},
 	},
 
 	// Mempool parameters
@@ -541,6 +738,11 @@ var SimNetParams = Params{
 	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
 	GenerateSupported:        true,
 
+	// Proof-of-work algorithms ordered from oldest to newest.
+	PowForks: []PowFork{
+		{0, wire.PowLyra2REv2},
+	},
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
 
@@ -566,6 +768,11 @@ var SimNetParams = Params{
 			StartTime:  0,             // Always available for vote
 			ExpireTime: math.MaxInt64, // Never expires.
 		},
+		DeploymentTaproot: {
+			BitNumber:  2,
+			StartTime:  0,             // Always available for vote
+			ExpireTime: math.MaxInt64, // Never expires.
+		},
 	},
 
 	// Mempool parameters
@@ -636,6 +843,11 @@ func CustomSignetParams(challenge []byte, dnsSeeds []DNSSeed) Params {
 		MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
 		GenerateSupported:        false,
 
+		// Proof-of-work algorithms ordered from oldest to newest.
+		PowForks: []PowFork{
+			{0, wire.PowLyra2REv2},
+		},
+
 		// Checkpoints ordered from oldest to newest.
 		Checkpoints: nil,
 
@@ -829,6 +1041,46 @@ func HDPrivateKeyToPublicKeyID(id []byte) ([]byte, error) {
 	return pubBytes, nil
 }
 
+// DifficultyAlgorithmAt returns the difficulty retarget algorithm that is in
+// effect for a block at the passed height according to the DifficultyForks
+// schedule.
+func (p *Params) DifficultyAlgorithmAt(height int32) DifficultyAlgorithm {
+	algorithm := DiffBitcoin
+	for _, fork := range p.DifficultyForks {
+		if height < fork.Height {
+			break
+		}
+		algorithm = fork.Algorithm
+	}
+	return algorithm
+}
+
+// PowAlgorithmAt returns the proof-of-work algorithm that is in effect for a
+// block at the passed height according to the PowForks schedule.
+func (p *Params) PowAlgorithmAt(height int32) wire.PowAlgorithm {
+	algorithm := wire.PowLyra2REv2
+	for _, fork := range p.PowForks {
+		if height < fork.Height {
+			break
+		}
+		algorithm = fork.Algorithm
+	}
+	return algorithm
+}
+
+// PowAlgorithmsFrom returns the proof-of-work algorithms which are in effect for
+// a block at the passed height or any height after it, ordered from oldest to
+// newest.
+func (p *Params) PowAlgorithmsFrom(height int32) []wire.PowAlgorithm {
+	algorithms := []wire.PowAlgorithm{p.PowAlgorithmAt(height)}
+	for _, fork := range p.PowForks {
+		if fork.Height > height {
+			algorithms = append(algorithms, fork.Algorithm)
+		}
+	}
+	return algorithms
+}
+
 // newHashFromStr converts the passed big-endian hex string into a
 // chainhash.Hash.  It only differs from the one available in chainhash in that
 // it panics on an error since it will only (and must only) be called with
@@ -8,7 +8,10 @@ import (
 	"bytes"
 	"encoding/hex"
 	"math/big"
+	"reflect"
 	"testing"
+
+	"github.com/btcsuite/btcd/wire"
 )
 
 // TestInvalidHashStr ensures the newShaHashFromStr function panics when used to
@@ -104,6 +107,102 @@ func TestSigNetPowLimit(t *testing.T) {
 	}
 }
 
+// TestDifficultyAlgorithmAt ensures the difficulty retarget algorithm for a
+// height is selected from the fork schedule.
+func TestDifficultyAlgorithmAt(t *testing.T) {
+	t.Parallel()
+
+	params := Params{
+		DifficultyForks: []DifficultyFork{
+			{10, DiffKimotoGravityWell},
+			{20, DiffBitcoin},
+		},
+	}
+	tests := []struct {
+		height int32
+		want   DifficultyAlgorithm
+	}{
+		{0, DiffBitcoin},
+		{9, DiffBitcoin},
+		{10, DiffKimotoGravityWell},
+		{19, DiffKimotoGravityWell},
+		{20, DiffBitcoin},
+		{1000000, DiffBitcoin},
+	}
+	for _, test := range tests {
+		got := params.DifficultyAlgorithmAt(test.height)
+		if got != test.want {
+			t.Errorf("height %d: got %v, want %v", test.height,
+				got, test.want)
+		}
+	}
+
+	if got := MainNetParams.DifficultyAlgorithmAt(0); got != DiffKimotoGravityWell {
+		t.Errorf("mainnet: got %v, want %v", got, DiffKimotoGravityWell)
+	}
+}
+
+// TestPowAlgorithmAt ensures the proof-of-work algorithm for a height is
+// selected from the main network fork schedule.
+func TestPowAlgorithmAt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		height int32
+		want   wire.PowAlgorithm
+	}{
+		{0, wire.PowScryptN},
+		{208300, wire.PowScryptN},
+		{208301, wire.PowLyra2RE},
+		{346999, wire.PowLyra2RE},
+		{347000, wire.PowLyra2REv2},
+		{1079999, wire.PowLyra2REv2},
+		{1080000, wire.PowLyra2REv3},
+		{1499999, wire.PowLyra2REv3},
+		{1500000, wire.PowVerthash},
+	}
+	for _, test := range tests {
+		got := MainNetParams.PowAlgorithmAt(test.height)
+		if got != test.want {
+			t.Errorf("height %d: got %v, want %v", test.height,
+				got, test.want)
+		}
+	}
+
+	// Networks without a schedule use Lyra2REv2.
+	var params Params
+	if got := params.PowAlgorithmAt(0); got != wire.PowLyra2REv2 {
+		t.Errorf("empty schedule: got %v, want %v", got,
+			wire.PowLyra2REv2)
+	}
+}
+
+// TestPowAlgorithmsFrom ensures the proof-of-work algorithms which can be in
+// effect at or after a height are listed from oldest to newest.
+func TestPowAlgorithmsFrom(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		height int32
+		want   []wire.PowAlgorithm
+	}{
+		{0, []wire.PowAlgorithm{wire.PowScryptN, wire.PowLyra2RE,
+			wire.PowLyra2REv2, wire.PowLyra2REv3, wire.PowVerthash}},
+		{347000, []wire.PowAlgorithm{wire.PowLyra2REv2,
+			wire.PowLyra2REv3, wire.PowVerthash}},
+		{1079999, []wire.PowAlgorithm{wire.PowLyra2REv2,
+			wire.PowLyra2REv3, wire.PowVerthash}},
+		{1500000, []wire.PowAlgorithm{wire.PowVerthash}},
+	}
+	for _, test := range tests {
+		got := MainNetParams.PowAlgorithmsFrom(test.height)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("height %d: got %v, want %v", test.height,
+				got, test.want)
+		}
+	}
+}
+
 // compactToBig is a copy of the blockchain.CompactToBig function. We copy it
 // here so we don't run into a circular dependency just because of a test.
 func compactToBig(compact uint32) *big.Int {
@@ -0,0 +1,690 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// ErrInvalidParams describes an error where network parameters are malformed
+// or inconsistent and therefore can't be used to define a network.
+var ErrInvalidParams = errors.New("invalid network parameters")
+
+// difficultyAlgorithmNames maps the names used for difficulty retarget
+// algorithms in parameter files to the algorithms they identify.
+var difficultyAlgorithmNames = map[string]DifficultyAlgorithm{
+	"bitcoin": DiffBitcoin,
+	"kgw":     DiffKimotoGravityWell,
+}
+
+// powAlgorithmNames maps the names used for proof-of-work algorithms in
+// parameter files to the algorithms they identify.
+var powAlgorithmNames = map[string]wire.PowAlgorithm{
+	"scryptn":   wire.PowScryptN,
+	"lyra2re":   wire.PowLyra2RE,
+	"lyra2rev2": wire.PowLyra2REv2,
+	"lyra2rev3": wire.PowLyra2REv3,
+	"verthash":  wire.PowVerthash,
+}
+
+// deploymentNames maps the names used for consensus rule change deployments
+// in parameter files to their offsets in the Deployments field.
+var deploymentNames = map[string]int{
+	"testdummy": DeploymentTestDummy,
+	"csv":       DeploymentCSV,
+	"segwit":    DeploymentSegwit,
+	"taproot":   DeploymentTaproot,
+}
+
+// paramsFile is the on-disk representation of Params.  Hashes, the genesis
+// block and other binary values are hex encoded, durations use the format
+// understood by time.ParseDuration, and algorithms and deployments are
+// referred to by name.
+type paramsFile struct {
+	Name        string        `json:"name" toml:"name"`
+	Net         uint32        `json:"net" toml:"net"`
+	DefaultPort string        `json:"defaultPort" toml:"defaultPort"`
+	DNSSeeds    []dnsSeedFile `json:"dnsSeeds" toml:"dnsSeeds"`
+
+	GenesisBlock             string `json:"genesisBlock" toml:"genesisBlock"`
+	GenesisHash              string `json:"genesisHash" toml:"genesisHash"`
+	PowLimit                 string `json:"powLimit" toml:"powLimit"`
+	PowLimitBits             uint32 `json:"powLimitBits" toml:"powLimitBits"`
+	BIP0034Height            int32  `json:"bip0034Height" toml:"bip0034Height"`
+	BIP0065Height            int32  `json:"bip0065Height" toml:"bip0065Height"`
+	BIP0066Height            int32  `json:"bip0066Height" toml:"bip0066Height"`
+	CoinbaseMaturity         uint16 `json:"coinbaseMaturity" toml:"coinbaseMaturity"`
+	SubsidyReductionInterval int32  `json:"subsidyReductionInterval" toml:"subsidyReductionInterval"`
+	TargetTimespan           string `json:"targetTimespan" toml:"targetTimespan"`
+	TargetTimePerBlock       string `json:"targetTimePerBlock" toml:"targetTimePerBlock"`
+	RetargetAdjustmentFactor int64  `json:"retargetAdjustmentFactor" toml:"retargetAdjustmentFactor"`
+	ReduceMinDifficulty      bool   `json:"reduceMinDifficulty" toml:"reduceMinDifficulty"`
+	MinDiffReductionTime     string `json:"minDiffReductionTime" toml:"minDiffReductionTime"`
+	GenerateSupported        bool   `json:"generateSupported" toml:"generateSupported"`
+
+	DifficultyForks []forkFile       `json:"difficultyForks" toml:"difficultyForks"`
+	PowForks        []forkFile       `json:"powForks" toml:"powForks"`
+	Checkpoints     []checkpointFile `json:"checkpoints" toml:"checkpoints"`
+
+	AssumeValid      string `json:"assumeValid" toml:"assumeValid"`
+	MinimumChainWork string `json:"minimumChainWork" toml:"minimumChainWork"`
+
+	AssumeUtxo []assumeUtxoFile `json:"assumeUtxo" toml:"assumeUtxo"`
+
+	RuleChangeActivationThreshold uint32                    `json:"ruleChangeActivationThreshold" toml:"ruleChangeActivationThreshold"`
+	MinerConfirmationWindow       uint32                    `json:"minerConfirmationWindow" toml:"minerConfirmationWindow"`
+	Deployments                   map[string]deploymentFile `json:"deployments" toml:"deployments"`
+
+	RelayNonStdTxs bool `json:"relayNonStdTxs" toml:"relayNonStdTxs"`
+
+	Bech32HRPSegwit         string `json:"bech32HRPSegwit" toml:"bech32HRPSegwit"`
+	PubKeyHashAddrID        uint8  `json:"pubKeyHashAddrID" toml:"pubKeyHashAddrID"`
+	ScriptHashAddrID        uint8  `json:"scriptHashAddrID" toml:"scriptHashAddrID"`
+	PrivateKeyID            uint8  `json:"privateKeyID" toml:"privateKeyID"`
+	WitnessPubKeyHashAddrID uint8  `json:"witnessPubKeyHashAddrID" toml:"witnessPubKeyHashAddrID"`
+	WitnessScriptHashAddrID uint8  `json:"witnessScriptHashAddrID" toml:"witnessScriptHashAddrID"`
+	HDPrivateKeyID          string `json:"hdPrivateKeyID" toml:"hdPrivateKeyID"`
+	HDPublicKeyID           string `json:"hdPublicKeyID" toml:"hdPublicKeyID"`
+	HDCoinType              uint32 `json:"hdCoinType" toml:"hdCoinType"`
+}
+
+// dnsSeedFile is the on-disk representation of a DNSSeed.
+type dnsSeedFile struct {
+	Host         string `json:"host" toml:"host"`
+	HasFiltering bool   `json:"hasFiltering" toml:"hasFiltering"`
+}
+
+// forkFile is the on-disk representation of an entry in either the difficulty
+// retarget or the proof-of-work algorithm schedule.
+type forkFile struct {
+	Height    int32  `json:"height" toml:"height"`
+	Algorithm string `json:"algorithm" toml:"algorithm"`
+}
+
+// checkpointFile is the on-disk representation of a Checkpoint.
+type checkpointFile struct {
+	Height int32  `json:"height" toml:"height"`
+	Hash   string `json:"hash" toml:"hash"`
+}
+
+// assumeUtxoFile is the on-disk representation of an AssumeUtxo.
+type assumeUtxoFile struct {
+	Height       int32  `json:"height" toml:"height"`
+	BlockHash    string `json:"blockHash" toml:"blockHash"`
+	SnapshotHash string `json:"snapshotHash" toml:"snapshotHash"`
+	ChainTxCount uint64 `json:"chainTxCount" toml:"chainTxCount"`
+}
+
+// deploymentFile is the on-disk representation of a ConsensusDeployment.
+type deploymentFile struct {
+	BitNumber           uint8  `json:"bitNumber" toml:"bitNumber"`
+	StartTime           uint64 `json:"startTime" toml:"startTime"`
+	ExpireTime          uint64 `json:"expireTime" toml:"expireTime"`
+	StartHeight         uint32 `json:"startHeight" toml:"startHeight"`
+	TimeoutHeight       uint32 `json:"timeoutHeight" toml:"timeoutHeight"`
+	MinActivationHeight uint32 `json:"minActivationHeight" toml:"minActivationHeight"`
+	LockInOnTimeout     bool   `json:"lockInOnTimeout" toml:"lockInOnTimeout"`
+	AlwaysActive        bool   `json:"alwaysActive" toml:"alwaysActive"`
+	NeverActive         bool   `json:"neverActive" toml:"neverActive"`
+}
+
+// paramsError returns an ErrInvalidParams error with the passed description.
+func paramsError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidParams, fmt.Sprintf(format, args...))
+}
+
+// parseHexBytes decodes the passed hex string which must decode to exactly
+// size bytes.
+func parseHexBytes(field, s string, size int) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, paramsError("%s: %v", field, err)
+	}
+	if len(b) != size {
+		return nil, paramsError("%s: got %d bytes, want %d", field,
+			len(b), size)
+	}
+	return b, nil
+}
+
+// parseDuration parses the passed duration which may be empty to mean zero.
+func parseDuration(field, s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, paramsError("%s: %v", field, err)
+	}
+	return d, nil
+}
+
+// toParams converts the on-disk representation into Params.  Only the
+// encoding of the individual values is checked here.  Params.Validate checks
+// the resulting parameters as a whole.
+func (f *paramsFile) toParams() (*Params, error) {
+	params := &Params{
+		Name:                          f.Name,
+		Net:                           wire.BitcoinNet(f.Net),
+		DefaultPort:                   f.DefaultPort,
+		PowLimitBits:                  f.PowLimitBits,
+		BIP0034Height:                 f.BIP0034Height,
+		BIP0065Height:                 f.BIP0065Height,
+		BIP0066Height:                 f.BIP0066Height,
+		CoinbaseMaturity:              f.CoinbaseMaturity,
+		SubsidyReductionInterval:      f.SubsidyReductionInterval,
+		RetargetAdjustmentFactor:      f.RetargetAdjustmentFactor,
+		ReduceMinDifficulty:           f.ReduceMinDifficulty,
+		GenerateSupported:             f.GenerateSupported,
+		RuleChangeActivationThreshold: f.RuleChangeActivationThreshold,
+		MinerConfirmationWindow:       f.MinerConfirmationWindow,
+		RelayNonStdTxs:                f.RelayNonStdTxs,
+		Bech32HRPSegwit:               f.Bech32HRPSegwit,
+		PubKeyHashAddrID:              f.PubKeyHashAddrID,
+		ScriptHashAddrID:              f.ScriptHashAddrID,
+		PrivateKeyID:                  f.PrivateKeyID,
+		WitnessPubKeyHashAddrID:       f.WitnessPubKeyHashAddrID,
+		WitnessScriptHashAddrID:       f.WitnessScriptHashAddrID,
+		HDCoinType:                    f.HDCoinType,
+	}
+
+	for _, seed := range f.DNSSeeds {
+		params.DNSSeeds = append(params.DNSSeeds, DNSSeed{
+			Host:         seed.Host,
+			HasFiltering: seed.HasFiltering,
+		})
+	}
+
+	// The genesis block is stored serialized.  Its hash is optional and is
+	// calculated from the block when it is not given.
+	serializedBlock, err := hex.DecodeString(f.GenesisBlock)
+	if err != nil {
+		return nil, paramsError("genesisBlock: %v", err)
+	}
+	if len(serializedBlock) != 0 {
+		var block wire.MsgBlock
+		err := block.Deserialize(bytes.NewReader(serializedBlock))
+		if err != nil {
+			return nil, paramsError("genesisBlock: %v", err)
+		}
+		params.GenesisBlock = &block
+		genesisHash := block.BlockHash()
+		params.GenesisHash = &genesisHash
+	}
+	if f.GenesisHash != "" {
+		genesisHash, err := chainhash.NewHashFromStr(f.GenesisHash)
+		if err != nil {
+			return nil, paramsError("genesisHash: %v", err)
+		}
+		params.GenesisHash = genesisHash
+	}
+
+	if f.PowLimit != "" {
+		powLimit, ok := new(big.Int).SetString(f.PowLimit, 16)
+		if !ok {
+			return nil, paramsError("powLimit: invalid hex "+
+				"value %q", f.PowLimit)
+		}
+		params.PowLimit = powLimit
+	}
+
+	params.TargetTimespan, err = parseDuration("targetTimespan",
+		f.TargetTimespan)
+	if err != nil {
+		return nil, err
+	}
+	params.TargetTimePerBlock, err = parseDuration("targetTimePerBlock",
+		f.TargetTimePerBlock)
+	if err != nil {
+		return nil, err
+	}
+	params.MinDiffReductionTime, err = parseDuration("minDiffReductionTime",
+		f.MinDiffReductionTime)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fork := range f.DifficultyForks {
+		algorithm, ok := difficultyAlgorithmNames[strings.ToLower(fork.Algorithm)]
+		if !ok {
+			return nil, paramsError("difficultyForks: unknown "+
+				"algorithm %q", fork.Algorithm)
+		}
+		params.DifficultyForks = append(params.DifficultyForks,
+			DifficultyFork{Height: fork.Height, Algorithm: algorithm})
+	}
+	for _, fork := range f.PowForks {
+		algorithm, ok := powAlgorithmNames[strings.ToLower(fork.Algorithm)]
+		if !ok {
+			return nil, paramsError("powForks: unknown algorithm %q",
+				fork.Algorithm)
+		}
+		params.PowForks = append(params.PowForks,
+			PowFork{Height: fork.Height, Algorithm: algorithm})
+	}
+
+	for _, checkpoint := range f.Checkpoints {
+		hash, err := chainhash.NewHashFromStr(checkpoint.Hash)
+		if err != nil {
+			return nil, paramsError("checkpoint at height %d: %v",
+				checkpoint.Height, err)
+		}
+		params.Checkpoints = append(params.Checkpoints,
+			Checkpoint{Height: checkpoint.Height, Hash: hash})
+	}
+	if f.AssumeValid != "" {
+		assumeValid, err := chainhash.NewHashFromStr(f.AssumeValid)
+		if err != nil {
+			return nil, paramsError("assumeValid: %v", err)
+		}
+		params.AssumeValid = assumeValid
+	}
+	if f.MinimumChainWork != "" {
+		minChainWork, ok := new(big.Int).SetString(f.MinimumChainWork, 16)
+		if !ok {
+			return nil, paramsError("minimumChainWork: invalid hex "+
+				"value %q", f.MinimumChainWork)
+		}
+		params.MinimumChainWork = minChainWork
+	}
+	for _, snapshot := range f.AssumeUtxo {
+		blockHash, err := chainhash.NewHashFromStr(snapshot.BlockHash)
+		if err != nil {
+			return nil, paramsError("assumeUtxo at height %d: %v",
+				snapshot.Height, err)
+		}
+		snapshotHash, err := chainhash.NewHashFromStr(snapshot.SnapshotHash)
+		if err != nil {
+			return nil, paramsError("assumeUtxo at height %d: %v",
+				snapshot.Height, err)
+		}
+		params.AssumeUtxo = append(params.AssumeUtxo, AssumeUtxo{
+			Height:       snapshot.Height,
+			BlockHash:    blockHash,
+			SnapshotHash: snapshotHash,
+			ChainTxCount: snapshot.ChainTxCount,
+		})
+	}
+
+	for name, deployment := range f.Deployments {
+		id, ok := deploymentNames[strings.ToLower(name)]
+		if !ok {
+			return nil, paramsError("deployments: unknown "+
+				"deployment %q", name)
+		}
+		params.Deployments[id] = ConsensusDeployment{
+			BitNumber:           deployment.BitNumber,
+			StartTime:           deployment.StartTime,
+			ExpireTime:          deployment.ExpireTime,
+			StartHeight:         deployment.StartHeight,
+			TimeoutHeight:       deployment.TimeoutHeight,
+			MinActivationHeight: deployment.MinActivationHeight,
+			LockInOnTimeout:     deployment.LockInOnTimeout,
+			AlwaysActive:        deployment.AlwaysActive,
+			NeverActive:         deployment.NeverActive,
+		}
+	}
+
+	hdPrivateKeyID, err := parseHexBytes("hdPrivateKeyID",
+		f.HDPrivateKeyID, len(params.HDPrivateKeyID))
+	if err != nil {
+		return nil, err
+	}
+	copy(params.HDPrivateKeyID[:], hdPrivateKeyID)
+	hdPublicKeyID, err := parseHexBytes("hdPublicKeyID", f.HDPublicKeyID,
+		len(params.HDPublicKeyID))
+	if err != nil {
+		return nil, err
+	}
+	copy(params.HDPublicKeyID[:], hdPublicKeyID)
+
+	return params, nil
+}
+
+// ParseParamsJSON parses and validates network parameters from the passed
+// JSON encoded parameter file contents.  Unknown keys are rejected so that
+// typos do not silently leave parameters unset.
+func ParseParamsJSON(data []byte) (*Params, error) {
+	var f paramsFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, paramsError("%v", err)
+	}
+	return parseParamsFile(&f)
+}
+
+// ParseParamsTOML parses and validates network parameters from the passed
+// TOML encoded parameter file contents.  Unknown keys are rejected so that
+// typos do not silently leave parameters unset.
+func ParseParamsTOML(data []byte) (*Params, error) {
+	var f paramsFile
+	md, err := toml.Decode(string(data), &f)
+	if err != nil {
+		return nil, paramsError("%v", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return nil, paramsError("unknown keys: %s",
+			strings.Join(keys, ", "))
+	}
+	return parseParamsFile(&f)
+}
+
+// parseParamsFile converts and validates the passed on-disk parameters.
+func parseParamsFile(f *paramsFile) (*Params, error) {
+	params, err := f.toParams()
+	if err != nil {
+		return nil, err
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// LoadParamsFile reads and validates network parameters from the file at the
+// passed path.  The format is chosen by the file extension, which must be
+// either .json or .toml.
+//
+// The returned parameters are not registered.  Callers which intend to use
+// them should do so with Register.
+func LoadParamsFile(path string) (*Params, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return ParseParamsJSON(data)
+	case ".toml":
+		return ParseParamsTOML(data)
+	default:
+		return nil, fmt.Errorf("unsupported network parameter file "+
+			"extension %q -- use .json or .toml", ext)
+	}
+}
+
+// isKnownPowAlgorithm returns whether the passed proof-of-work algorithm is
+// one that may be named in a parameter file.
+func isKnownPowAlgorithm(algorithm wire.PowAlgorithm) bool {
+	for _, known := range powAlgorithmNames {
+		if algorithm == known {
+			return true
+		}
+	}
+	return false
+}
+
+// checkForkHeights ensures the passed schedule heights are non-negative and
+// strictly increasing.
+func checkForkHeights(field string, heights []int32) error {
+	for i, height := range heights {
+		if height < 0 {
+			return paramsError("%s: negative height %d", field,
+				height)
+		}
+		if i > 0 && height <= heights[i-1] {
+			return paramsError("%s: height %d does not follow "+
+				"height %d", field, height, heights[i-1])
+		}
+	}
+	return nil
+}
+
+// validateDeployment ensures the passed deployment uses a consistent
+// combination of fields for the way it is activated.  Deployments which are
+// always or never active must not define any activation, deployments which time out at
+// a height as defined by BIP0008 must only use heights aligned to the passed
+// miner confirmation window, and deployments which expire at a median block
+// time as defined by BIP0009 must not use heights to start or lock in.
+func validateDeployment(d *ConsensusDeployment, window uint32) error {
+	switch {
+	case d.AlwaysActive && d.NeverActive:
+		return errors.New("deployment is both always and never active")
+
+	case d.AlwaysActive, d.NeverActive:
+		if d.StartTime != 0 || d.ExpireTime != 0 || d.StartHeight != 0 ||
+			d.TimeoutHeight != 0 || d.MinActivationHeight != 0 ||
+			d.LockInOnTimeout {
+
+			return errors.New("always or never active deployment " +
+				"defines an activation")
+		}
+
+	case d.HeightBased():
+		if d.StartTime != 0 || d.ExpireTime != 0 {
+			return errors.New("height-based deployment defines " +
+				"start or expire times")
+		}
+		if d.StartHeight%window != 0 || d.TimeoutHeight%window != 0 {
+			return fmt.Errorf("start height %d or timeout height "+
+				"%d is not a multiple of the miner confirmation "+
+				"window of %d", d.StartHeight, d.TimeoutHeight,
+				window)
+		}
+		if d.TimeoutHeight <= d.StartHeight {
+			return errors.New("times out before it starts")
+		}
+
+		// Locking in on timeout requires the blocks of the last window
+		// before the timeout to signal, so there must be a window of
+		// voting before it.
+		if d.LockInOnTimeout && d.TimeoutHeight-d.StartHeight < 2*window {
+			return errors.New("locks in on timeout without a " +
+				"window of voting before the timeout")
+		}
+
+	default:
+		if d.StartHeight != 0 || d.LockInOnTimeout {
+			return errors.New("time-based deployment defines a " +
+				"start height or locks in on timeout")
+		}
+		if d.ExpireTime < d.StartTime {
+			return errors.New("expires before it starts")
+		}
+	}
+
+	return nil
+}
+
+// bigToCompact is a copy of the blockchain.BigToCompact function.  It is copied
+// here since the blockchain package imports this one.
+func bigToCompact(n *big.Int) uint32 {
+	// No need to do any work if it's zero.
+	if n.Sign() == 0 {
+		return 0
+	}
+
+	// Since the base for the exponent is 256, the exponent can be treated
+	// as the number of bytes.  So, shift the number right or left
+	// accordingly.  This is equivalent to:
+	// mantissa = mantissa / 256^(exponent-3)
+	var mantissa uint32
+	exponent := uint(len(n.Bytes()))
+	if exponent <= 3 {
+		mantissa = uint32(n.Bits()[0])
+		mantissa <<= 8 * (3 - exponent)
+	} else {
+		// Use a copy to avoid modifying the caller's original number.
+		tn := new(big.Int).Set(n)
+		mantissa = uint32(tn.Rsh(tn, 8*(exponent-3)).Bits()[0])
+	}
+
+	// When the mantissa already has the sign bit set, the number is too
+	// large to fit into the available 23-bits, so divide the number by 256
+	// and increment the exponent accordingly.
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+
+	// Pack the exponent, sign bit, and mantissa into an unsigned 32-bit
+	// int and return it.
+	compact := uint32(exponent<<24) | mantissa
+	if n.Sign() < 0 {
+		compact |= 0x00800000
+	}
+	return compact
+}
+
+// Validate ensures the network parameters are complete and consistent enough
+// to run a network with.  It is intended to be used on parameters which are
+// not compiled in, such as those returned by LoadParamsFile, before they are
+// registered.
+func (p *Params) Validate() error {
+	if p.Name == "" {
+		return paramsError("missing name")
+	}
+	port, err := strconv.ParseUint(p.DefaultPort, 10, 16)
+	if err != nil || port == 0 {
+		return paramsError("invalid default port %q", p.DefaultPort)
+	}
+
+	if p.GenesisBlock == nil {
+		return paramsError("missing genesis block")
+	}
+	genesisHash := p.GenesisBlock.BlockHash()
+	if p.GenesisHash == nil || *p.GenesisHash != genesisHash {
+		return paramsError("genesis hash %v does not match genesis "+
+			"block hash %v", p.GenesisHash, genesisHash)
+	}
+	if p.PowLimit == nil || p.PowLimit.Sign() <= 0 {
+		return paramsError("proof of work limit must be positive")
+	}
+	if compact := bigToCompact(p.PowLimit); p.PowLimitBits != compact {
+		return paramsError("compact proof of work limit %08x does not "+
+			"match proof of work limit %x, whose compact form is %08x",
+			p.PowLimitBits, p.PowLimit, compact)
+	}
+
+	if p.SubsidyReductionInterval <= 0 {
+		return paramsError("subsidy reduction interval must be " +
+			"positive")
+	}
+	if p.TargetTimespan <= 0 || p.TargetTimePerBlock <= 0 {
+		return paramsError("target timespan and target time per " +
+			"block must be positive")
+	}
+	if p.TargetTimespan < p.TargetTimePerBlock {
+		return paramsError("target timespan %v is less than target "+
+			"time per block %v", p.TargetTimespan,
+			p.TargetTimePerBlock)
+	}
+	if p.RetargetAdjustmentFactor <= 0 {
+		return paramsError("retarget adjustment factor must be " +
+			"positive")
+	}
+	if p.ReduceMinDifficulty && p.MinDiffReductionTime <= 0 {
+		return paramsError("minimum difficulty reduction time must " +
+			"be positive when reducing the minimum difficulty")
+	}
+
+	heights := make([]int32, 0, len(p.DifficultyForks))
+	for _, fork := range p.DifficultyForks {
+		if _, ok := difficultyAlgorithmStrings[fork.Algorithm]; !ok {
+			return paramsError("difficulty fork at height %d: %v",
+				fork.Height, fork.Algorithm)
+		}
+		heights = append(heights, fork.Height)
+	}
+	if err := checkForkHeights("difficulty forks", heights); err != nil {
+		return err
+	}
+	heights = heights[:0]
+	for _, fork := range p.PowForks {
+		if !isKnownPowAlgorithm(fork.Algorithm) {
+			return paramsError("proof of work fork at height %d: %v",
+				fork.Height, fork.Algorithm)
+		}
+		heights = append(heights, fork.Height)
+	}
+	if err := checkForkHeights("proof of work forks", heights); err != nil {
+		return err
+	}
+
+	if !sort.SliceIsSorted(p.Checkpoints, func(i, j int) bool {
+		return p.Checkpoints[i].Height < p.Checkpoints[j].Height
+	}) {
+		return paramsError("checkpoints are not ordered by height")
+	}
+	for i, checkpoint := range p.Checkpoints {
+		if checkpoint.Height <= 0 || checkpoint.Hash == nil {
+			return paramsError("invalid checkpoint at height %d",
+				checkpoint.Height)
+		}
+		if i > 0 && checkpoint.Height == p.Checkpoints[i-1].Height {
+			return paramsError("duplicate checkpoint at height %d",
+				checkpoint.Height)
+		}
+	}
+
+	for i, snapshot := range p.AssumeUtxo {
+		if snapshot.Height <= 0 || snapshot.BlockHash == nil ||
+			snapshot.SnapshotHash == nil || snapshot.ChainTxCount == 0 {
+
+			return paramsError("invalid utxo snapshot at height %d",
+				snapshot.Height)
+		}
+		if i > 0 && snapshot.Height <= p.AssumeUtxo[i-1].Height {
+			return paramsError("utxo snapshots are not ordered by " +
+				"height")
+		}
+	}
+
+	if p.MinerConfirmationWindow == 0 {
+		return paramsError("miner confirmation window must be positive")
+	}
+	if p.RuleChangeActivationThreshold == 0 ||
+		p.RuleChangeActivationThreshold > p.MinerConfirmationWindow {
+
+		return paramsError("rule change activation threshold %d is "+
+			"not within the miner confirmation window of %d",
+			p.RuleChangeActivationThreshold,
+			p.MinerConfirmationWindow)
+	}
+	for id, deployment := range p.Deployments {
+		// The top three bits of the block version are reserved by
+		// BIP0009 to signal the use of version bits.
+		if deployment.BitNumber > 28 {
+			return paramsError("deployment %d: bit number %d is "+
+				"out of range", id, deployment.BitNumber)
+		}
+		if err := validateDeployment(&deployment,
+			p.MinerConfirmationWindow); err != nil {
+
+			return paramsError("deployment %d: %v", id, err)
+		}
+	}
+
+	if p.Bech32HRPSegwit == "" {
+		return paramsError("missing bech32 human-readable part")
+	}
+	if p.HDPrivateKeyID == p.HDPublicKeyID {
+		return paramsError("hierarchical deterministic private and " +
+			"public key IDs are identical")
+	}
+
+	return nil
+}
@@ -0,0 +1,538 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// testParamsFile returns the on-disk representation of a network which
+// shares the main network chain parameters under a different name and
+// network magic.
+func testParamsFile(t *testing.T) *paramsFile {
+	var genesis bytes.Buffer
+	if err := MainNetParams.GenesisBlock.Serialize(&genesis); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+
+	f := &paramsFile{
+		Name:                          "vtcfork",
+		Net:                           0x5a5b5c5d,
+		DefaultPort:                   "15999",
+		DNSSeeds:                      []dnsSeedFile{{"seed.example.com", true}},
+		GenesisBlock:                  hex.EncodeToString(genesis.Bytes()),
+		GenesisHash:                   MainNetParams.GenesisHash.String(),
+		PowLimit:                      MainNetParams.PowLimit.Text(16),
+		PowLimitBits:                  MainNetParams.PowLimitBits,
+		BIP0034Height:                 MainNetParams.BIP0034Height,
+		BIP0065Height:                 MainNetParams.BIP0065Height,
+		BIP0066Height:                 MainNetParams.BIP0066Height,
+		CoinbaseMaturity:              MainNetParams.CoinbaseMaturity,
+		SubsidyReductionInterval:      MainNetParams.SubsidyReductionInterval,
+		TargetTimespan:                MainNetParams.TargetTimespan.String(),
+		TargetTimePerBlock:            MainNetParams.TargetTimePerBlock.String(),
+		RetargetAdjustmentFactor:      MainNetParams.RetargetAdjustmentFactor,
+		AssumeValid:                   MainNetParams.Checkpoints[0].Hash.String(),
+		MinimumChainWork:              "100000",
+		RuleChangeActivationThreshold: MainNetParams.RuleChangeActivationThreshold,
+		MinerConfirmationWindow:       MainNetParams.MinerConfirmationWindow,
+		Deployments:                   make(map[string]deploymentFile),
+		RelayNonStdTxs:                MainNetParams.RelayNonStdTxs,
+		Bech32HRPSegwit:               "tvf",
+		PubKeyHashAddrID:              0x7a,
+		ScriptHashAddrID:              0x7b,
+		PrivateKeyID:                  0xfa,
+		WitnessPubKeyHashAddrID:       MainNetParams.WitnessPubKeyHashAddrID,
+		WitnessScriptHashAddrID:       MainNetParams.WitnessScriptHashAddrID,
+		HDPrivateKeyID:                "0a0b0c0d",
+		HDPublicKeyID:                 "0a0b0c0e",
+		HDCoinType:                    MainNetParams.HDCoinType,
+	}
+	difficultyNames := map[DifficultyAlgorithm]string{
+		DiffBitcoin:           "bitcoin",
+		DiffKimotoGravityWell: "kgw",
+	}
+	for _, fork := range MainNetParams.DifficultyForks {
+		f.DifficultyForks = append(f.DifficultyForks, forkFile{
+			Height:    fork.Height,
+			Algorithm: difficultyNames[fork.Algorithm],
+		})
+	}
+	for _, fork := range MainNetParams.PowForks {
+		f.PowForks = append(f.PowForks, forkFile{
+			Height: fork.Height,
+			Algorithm: strings.ToLower(strings.TrimPrefix(
+				fork.Algorithm.String(), "Pow")),
+		})
+	}
+	for _, checkpoint := range MainNetParams.Checkpoints {
+		f.Checkpoints = append(f.Checkpoints, checkpointFile{
+			Height: checkpoint.Height,
+			Hash:   checkpoint.Hash.String(),
+		})
+	}
+	f.AssumeUtxo = []assumeUtxoFile{{
+		Height:       100,
+		BlockHash:    MainNetParams.Checkpoints[0].Hash.String(),
+		SnapshotHash: MainNetParams.GenesisHash.String(),
+		ChainTxCount: 101,
+	}}
+	for name, id := range deploymentNames {
+		deployment := MainNetParams.Deployments[id]
+		f.Deployments[name] = deploymentFile{
+			BitNumber:           deployment.BitNumber,
+			StartTime:           deployment.StartTime,
+			ExpireTime:          deployment.ExpireTime,
+			StartHeight:         deployment.StartHeight,
+			TimeoutHeight:       deployment.TimeoutHeight,
+			MinActivationHeight: deployment.MinActivationHeight,
+			LockInOnTimeout:     deployment.LockInOnTimeout,
+			AlwaysActive:        deployment.AlwaysActive,
+			NeverActive:         deployment.NeverActive,
+		}
+	}
+	return f
+}
+
+// TestParseParamsJSON ensures network parameters round trip through the JSON
+// parameter file format.
+func TestParseParamsJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(testParamsFile(t))
+	if err != nil {
+		t.Fatalf("Marshal: unexpected error: %v", err)
+	}
+	params, err := ParseParamsJSON(data)
+	if err != nil {
+		t.Fatalf("ParseParamsJSON: unexpected error: %v", err)
+	}
+
+	if params.Name != "vtcfork" || params.Net != 0x5a5b5c5d ||
+		params.DefaultPort != "15999" {
+
+		t.Fatalf("ParseParamsJSON: unexpected network identity %q, "+
+			"%v, %q", params.Name, params.Net, params.DefaultPort)
+	}
+	wantSeeds := []DNSSeed{{"seed.example.com", true}}
+	if !reflect.DeepEqual(params.DNSSeeds, wantSeeds) {
+		t.Fatalf("ParseParamsJSON: unexpected DNS seeds %v",
+			params.DNSSeeds)
+	}
+	if *params.GenesisHash != *MainNetParams.GenesisHash {
+		t.Fatalf("ParseParamsJSON: unexpected genesis hash %v",
+			params.GenesisHash)
+	}
+	if params.PowLimit.Cmp(MainNetParams.PowLimit) != 0 {
+		t.Fatalf("ParseParamsJSON: unexpected proof of work limit %x",
+			params.PowLimit)
+	}
+	if params.TargetTimespan != MainNetParams.TargetTimespan ||
+		params.TargetTimePerBlock != MainNetParams.TargetTimePerBlock {
+
+		t.Fatalf("ParseParamsJSON: unexpected target times %v, %v",
+			params.TargetTimespan, params.TargetTimePerBlock)
+	}
+	if !reflect.DeepEqual(params.DifficultyForks,
+		MainNetParams.DifficultyForks) {
+
+		t.Fatalf("ParseParamsJSON: unexpected difficulty forks %v",
+			params.DifficultyForks)
+	}
+	if !reflect.DeepEqual(params.PowForks, MainNetParams.PowForks) {
+		t.Fatalf("ParseParamsJSON: unexpected proof of work forks %v",
+			params.PowForks)
+	}
+	if !reflect.DeepEqual(params.Checkpoints, MainNetParams.Checkpoints) {
+		t.Fatalf("ParseParamsJSON: unexpected checkpoints %v",
+			params.Checkpoints)
+	}
+	if *params.AssumeValid != *MainNetParams.Checkpoints[0].Hash ||
+		params.MinimumChainWork.Int64() != 0x100000 {
+
+		t.Fatalf("ParseParamsJSON: unexpected assumed valid block %v "+
+			"with minimum chain work %x", params.AssumeValid,
+			params.MinimumChainWork)
+	}
+	wantAssumeUtxo := []AssumeUtxo{{
+		Height:       100,
+		BlockHash:    MainNetParams.Checkpoints[0].Hash,
+		SnapshotHash: MainNetParams.GenesisHash,
+		ChainTxCount: 101,
+	}}
+	if !reflect.DeepEqual(params.AssumeUtxo, wantAssumeUtxo) {
+		t.Fatalf("ParseParamsJSON: unexpected utxo snapshots %v",
+			params.AssumeUtxo)
+	}
+	if params.Deployments != MainNetParams.Deployments {
+		t.Fatalf("ParseParamsJSON: unexpected deployments %v",
+			params.Deployments)
+	}
+	wantPriv := [4]byte{0x0a, 0x0b, 0x0c, 0x0d}
+	wantPub := [4]byte{0x0a, 0x0b, 0x0c, 0x0e}
+	if params.HDPrivateKeyID != wantPriv || params.HDPublicKeyID != wantPub {
+		t.Fatalf("ParseParamsJSON: unexpected HD key IDs %x, %x",
+			params.HDPrivateKeyID, params.HDPublicKeyID)
+	}
+
+	// Unknown keys must be rejected.
+	data = append([]byte(`{"nmae": "typo",`), data[1:]...)
+	_, err = ParseParamsJSON(data)
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("ParseParamsJSON: unexpected error for unknown key - "+
+			"got %v, want %v", err, ErrInvalidParams)
+	}
+}
+
+// testParamsTOML is a TOML parameter file for a small network using the
+// regression test network genesis block.
+var testParamsTOML = `
+name = "vtctoml"
+net = 0x1a2b3c4d
+defaultPort = "16000"
+powLimit = "7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+powLimitBits = 0x207fffff
+coinbaseMaturity = 100
+subsidyReductionInterval = 150
+targetTimespan = "84h"
+targetTimePerBlock = "2m30s"
+retargetAdjustmentFactor = 4
+reduceMinDifficulty = true
+minDiffReductionTime = "5m"
+generateSupported = true
+ruleChangeActivationThreshold = 108
+minerConfirmationWindow = 144
+relayNonStdTxs = true
+bech32HRPSegwit = "tvt"
+pubKeyHashAddrID = 0x6f
+scriptHashAddrID = 0xc4
+privateKeyID = 0xef
+hdPrivateKeyID = "04358394"
+hdPublicKeyID = "043587cf"
+hdCoinType = 1
+
+[[dnsSeeds]]
+host = "seed.example.org"
+
+[[difficultyForks]]
+height = 0
+algorithm = "bitcoin"
+
+[[difficultyForks]]
+height = 10
+algorithm = "kgw"
+
+[[powForks]]
+height = 0
+algorithm = "lyra2rev2"
+
+[[powForks]]
+height = 500
+algorithm = "verthash"
+
+[[checkpoints]]
+height = 100
+hash = "0000000000000000000000000000000000000000000000000000000000000001"
+
+[deployments.segwit]
+bitNumber = 1
+startTime = 0
+expireTime = 9223372036854775807
+
+[deployments.taproot]
+bitNumber = 2
+startHeight = 2016
+timeoutHeight = 10080
+minActivationHeight = 12000
+lockInOnTimeout = true
+`
+
+// TestParseParamsTOML ensures network parameters are parsed from the TOML
+// parameter file format.
+func TestParseParamsTOML(t *testing.T) {
+	t.Parallel()
+
+	var genesis bytes.Buffer
+	if err := RegressionNetParams.GenesisBlock.Serialize(&genesis); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	data := fmt.Sprintf("genesisBlock = %q\n%s",
+		hex.EncodeToString(genesis.Bytes()), testParamsTOML)
+
+	params, err := ParseParamsTOML([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseParamsTOML: unexpected error: %v", err)
+	}
+	if params.Name != "vtctoml" || params.Net != 0x1a2b3c4d {
+		t.Fatalf("ParseParamsTOML: unexpected network identity %q, %v",
+			params.Name, params.Net)
+	}
+	if *params.GenesisHash != *RegressionNetParams.GenesisHash {
+		t.Fatalf("ParseParamsTOML: genesis hash not calculated - got "+
+			"%v, want %v", params.GenesisHash,
+			RegressionNetParams.GenesisHash)
+	}
+	if params.PowLimit.Cmp(RegressionNetParams.PowLimit) != 0 {
+		t.Fatalf("ParseParamsTOML: unexpected proof of work limit %x",
+			params.PowLimit)
+	}
+	if params.MinDiffReductionTime != 5*time.Minute {
+		t.Fatalf("ParseParamsTOML: unexpected minimum difficulty "+
+			"reduction time %v", params.MinDiffReductionTime)
+	}
+	if got := params.DifficultyAlgorithmAt(10); got != DiffKimotoGravityWell {
+		t.Fatalf("ParseParamsTOML: unexpected difficulty algorithm %v",
+			got)
+	}
+	if got := params.PowAlgorithmAt(499); got != wire.PowLyra2REv2 {
+		t.Fatalf("ParseParamsTOML: unexpected proof of work "+
+			"algorithm %v", got)
+	}
+	if got := params.PowAlgorithmAt(500); got != wire.PowVerthash {
+		t.Fatalf("ParseParamsTOML: unexpected proof of work "+
+			"algorithm %v", got)
+	}
+	segwit := params.Deployments[DeploymentSegwit]
+	if segwit.BitNumber != 1 || segwit.ExpireTime != 1<<63-1 {
+		t.Fatalf("ParseParamsTOML: unexpected segwit deployment %v",
+			segwit)
+	}
+	wantTaproot := ConsensusDeployment{
+		BitNumber:           2,
+		StartHeight:         2016,
+		TimeoutHeight:       10080,
+		MinActivationHeight: 12000,
+		LockInOnTimeout:     true,
+	}
+	if taproot := params.Deployments[DeploymentTaproot]; taproot != wantTaproot {
+		t.Fatalf("ParseParamsTOML: unexpected taproot deployment %v",
+			taproot)
+	}
+	if len(params.Checkpoints) != 1 || params.Checkpoints[0].Height != 100 {
+		t.Fatalf("ParseParamsTOML: unexpected checkpoints %v",
+			params.Checkpoints)
+	}
+
+	// Unknown keys must be rejected.
+	_, err = ParseParamsTOML([]byte(data + "\n[typo]\nkey = 1\n"))
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("ParseParamsTOML: unexpected error for unknown key - "+
+			"got %v, want %v", err, ErrInvalidParams)
+	}
+}
+
+// TestParamsFileErrors ensures malformed and inconsistent parameter files are
+// rejected.
+func TestParamsFileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(f *paramsFile)
+	}{
+		{"missing name", func(f *paramsFile) { f.Name = "" }},
+		{"invalid port", func(f *paramsFile) { f.DefaultPort = "70000" }},
+		{"missing genesis", func(f *paramsFile) { f.GenesisBlock = "" }},
+		{"truncated genesis", func(f *paramsFile) {
+			f.GenesisBlock = f.GenesisBlock[:160]
+		}},
+		{"genesis hash mismatch", func(f *paramsFile) {
+			f.GenesisHash = RegressionNetParams.GenesisHash.String()
+		}},
+		{"invalid pow limit", func(f *paramsFile) { f.PowLimit = "xyz" }},
+		{"compact pow limit mismatch", func(f *paramsFile) {
+			f.PowLimitBits = RegressionNetParams.PowLimitBits
+		}},
+		{"missing compact pow limit", func(f *paramsFile) {
+			f.PowLimitBits = 0
+		}},
+		{"invalid duration", func(f *paramsFile) {
+			f.TargetTimespan = "3 days"
+		}},
+		{"timespan below spacing", func(f *paramsFile) {
+			f.TargetTimespan = "1m"
+		}},
+		{"zero subsidy interval", func(f *paramsFile) {
+			f.SubsidyReductionInterval = 0
+		}},
+		{"unknown difficulty algorithm", func(f *paramsFile) {
+			f.DifficultyForks[0].Algorithm = "dgw"
+		}},
+		{"unknown pow algorithm", func(f *paramsFile) {
+			f.PowForks[1].Algorithm = "sha256d"
+		}},
+		{"unordered pow forks", func(f *paramsFile) {
+			f.PowForks[1].Height = f.PowForks[2].Height
+		}},
+		{"unordered checkpoints", func(f *paramsFile) {
+			f.Checkpoints[0], f.Checkpoints[1] = f.Checkpoints[1],
+				f.Checkpoints[0]
+		}},
+		{"invalid checkpoint hash", func(f *paramsFile) {
+			f.Checkpoints[0].Hash = "zz"
+		}},
+		{"invalid assume valid hash", func(f *paramsFile) {
+			f.AssumeValid = "zz"
+		}},
+		{"invalid minimum chain work", func(f *paramsFile) {
+			f.MinimumChainWork = "xyz"
+		}},
+		{"invalid utxo snapshot hash", func(f *paramsFile) {
+			f.AssumeUtxo[0].SnapshotHash = "zz"
+		}},
+		{"unordered utxo snapshots", func(f *paramsFile) {
+			f.AssumeUtxo = append(f.AssumeUtxo, f.AssumeUtxo[0])
+		}},
+		{"utxo snapshot without tx count", func(f *paramsFile) {
+			f.AssumeUtxo[0].ChainTxCount = 0
+		}},
+		{"threshold above window", func(f *paramsFile) {
+			f.RuleChangeActivationThreshold = f.MinerConfirmationWindow + 1
+		}},
+		{"unknown deployment", func(f *paramsFile) {
+			f.Deployments["bip9000"] = deploymentFile{}
+		}},
+		{"deployment bit out of range", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{BitNumber: 29}
+		}},
+		{"deployment expires before start", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{StartTime: 2, ExpireTime: 1}
+		}},
+		{"always active deployment with start time", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{AlwaysActive: true,
+				StartTime: 1}
+		}},
+		{"always active deployment with timeout", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{AlwaysActive: true,
+				TimeoutHeight: 2016}
+		}},
+		{"never active deployment with expire time", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{NeverActive: true,
+				ExpireTime: 1}
+		}},
+		{"always and never active deployment", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{AlwaysActive: true,
+				NeverActive: true}
+		}},
+		{"time-based deployment with start height", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{StartHeight: 2016,
+				ExpireTime: 1}
+		}},
+		{"time-based deployment locking in on timeout", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{LockInOnTimeout: true,
+				ExpireTime: 1}
+		}},
+		{"height-based deployment with times", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{TimeoutHeight: 4032,
+				ExpireTime: 1}
+		}},
+		{"unaligned deployment start height", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{StartHeight: 1,
+				TimeoutHeight: 4032}
+		}},
+		{"unaligned deployment timeout height", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{TimeoutHeight: 4033}
+		}},
+		{"deployment times out before start", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{StartHeight: 4032,
+				TimeoutHeight: 2016}
+		}},
+		{"deployment locks in on timeout without voting", func(f *paramsFile) {
+			f.Deployments["csv"] = deploymentFile{StartHeight: 2016,
+				TimeoutHeight: 4032, LockInOnTimeout: true}
+		}},
+		{"missing bech32 prefix", func(f *paramsFile) {
+			f.Bech32HRPSegwit = ""
+		}},
+		{"short HD key ID", func(f *paramsFile) {
+			f.HDPrivateKeyID = "0a0b0c"
+		}},
+		{"identical HD key IDs", func(f *paramsFile) {
+			f.HDPublicKeyID = f.HDPrivateKeyID
+		}},
+	}
+
+	for _, test := range tests {
+		f := testParamsFile(t)
+		test.modify(f)
+		data, err := json.Marshal(f)
+		if err != nil {
+			t.Fatalf("%s: Marshal: unexpected error: %v", test.name, err)
+		}
+		_, err = ParseParamsJSON(data)
+		if !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("%s: unexpected error - got %v, want %v",
+				test.name, err, ErrInvalidParams)
+		}
+	}
+}
+
+// TestLoadParamsFile ensures parameter files are loaded according to their
+// extension and that the loaded parameters can be registered.
+func TestLoadParamsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaincfg")
+	if err != nil {
+		t.Fatalf("TempDir: unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := testParamsFile(t)
+	f.Net = 0x5a5b5c5e
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("Marshal: unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"params.json", "params.JSON"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("WriteFile: unexpected error: %v", err)
+		}
+		if _, err := LoadParamsFile(path); err != nil {
+			t.Fatalf("LoadParamsFile(%s): unexpected error: %v",
+				name, err)
+		}
+	}
+
+	// JSON is not valid TOML and unknown extensions are rejected.
+	for _, name := range []string{"params.toml", "params.yaml"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("WriteFile: unexpected error: %v", err)
+		}
+		if _, err := LoadParamsFile(path); err == nil {
+			t.Fatalf("LoadParamsFile(%s): unexpected success", name)
+		}
+	}
+
+	params, err := LoadParamsFile(filepath.Join(dir, "params.json"))
+	if err != nil {
+		t.Fatalf("LoadParamsFile: unexpected error: %v", err)
+	}
+	if err := Register(params); err != nil {
+		t.Fatalf("Register: unexpected error: %v", err)
+	}
+	if !IsPubKeyHashAddrID(params.PubKeyHashAddrID) {
+		t.Fatalf("Register: pubkey hash ID %#x not registered",
+			params.PubKeyHashAddrID)
+	}
+	if err := Register(params); err != ErrDuplicateNet {
+		t.Fatalf("Register: unexpected error - got %v, want %v", err,
+			ErrDuplicateNet)
+	}
+}
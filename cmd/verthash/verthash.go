@@ -0,0 +1,104 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/verthash"
+	flags "github.com/jessevdk/go-flags"
+)
+
+var (
+	btcdHomeDir     = btcutil.AppDataDir("btcd", false)
+	defaultDatafile = filepath.Join(btcdHomeDir, "data",
+		verthash.DefaultDatafileName)
+)
+
+type config struct {
+	Datafile string `short:"f" long:"datafile" description:"Path to the Verthash datafile"`
+	Generate bool   `short:"g" long:"generate" description:"Generate the Verthash datafile when it does not exist"`
+	Header   string `short:"H" long:"header" description:"Hex-encoded 80-byte block header to hash with the datafile once it is verified"`
+}
+
+func main() {
+	cfg := config{
+		Datafile: defaultDatafile,
+	}
+	parser := flags.NewParser(&cfg, flags.Default)
+	_, err := parser.Parse()
+	if err != nil {
+		if e, ok := err.(*flags.Error); !ok || e.Type != flags.ErrHelp {
+			parser.WriteHelp(os.Stderr)
+		}
+		return
+	}
+
+	var header []byte
+	if cfg.Header != "" {
+		header, err = hex.DecodeString(cfg.Header)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid header: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	cfg.Datafile = cleanAndExpandPath(cfg.Datafile)
+	if _, err := os.Stat(cfg.Datafile); cfg.Generate && os.IsNotExist(err) {
+		fmt.Printf("Generating %s\n", cfg.Datafile)
+		if err := verthash.Generate(cfg.Datafile); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot generate datafile: %v\n",
+				err)
+			os.Exit(1)
+		}
+	}
+
+	df, err := verthash.Open(cfg.Datafile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open datafile: %v\n", err)
+		os.Exit(1)
+	}
+	defer df.Close()
+
+	fmt.Printf("Verifying %s\n", cfg.Datafile)
+	if err := df.Verify(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		df.Close()
+		os.Exit(1)
+	}
+	fmt.Println("Datafile is valid")
+
+	if header == nil {
+		return
+	}
+	hash, err := df.PowHash(header)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot hash header: %v\n", err)
+		df.Close()
+		os.Exit(1)
+	}
+
+	// Print the hash in the same byte order as block hashes.
+	fmt.Println(hash)
+}
+
+// cleanAndExpandPath expands environment variables and leading ~ in the
+// passed path, cleans the result, and returns it.
+func cleanAndExpandPath(path string) string {
+	// Expand initial ~ to OS specific home directory.
+	if strings.HasPrefix(path, "~") {
+		homeDir := filepath.Dir(btcdHomeDir)
+		path = strings.Replace(path, "~", homeDir, 1)
+	}
+
+	// NOTE: The os.ExpandEnv doesn't work with Windows-style %VARIABLE%,
+	// but they variables can still be expanded via POSIX-style $VARIABLE.
+	return filepath.Clean(os.ExpandEnv(path))
+}
@@ -29,6 +29,7 @@ import (
 	_ "github.com/btcsuite/btcd/database/ffldb"
 	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/peer"
+	"github.com/btcsuite/btcd/verthash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/go-socks/socks"
@@ -63,6 +64,10 @@ const (
 	defaultMaxOrphanTransactions = 100
 	defaultMaxOrphanTxSize       = 100000
 	defaultSigCacheMaxSize       = 100000
+	defaultUtxoCacheMaxSizeMiB   = 250
+	pruneMinSizeMiB              = 1536
+	defaultCheckBlocks           = 6
+	defaultCheckLevel            = 3
 	sampleConfigFilename         = "sample-btcd.conf"
 	defaultTxIndex               = false
 	defaultAddrIndex             = false
@@ -100,6 +105,7 @@ type config struct {
 	AddrIndex            bool          `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available"`
 	AgentBlacklist       []string      `long:"agentblacklist" description:"A comma separated list of user-agent substrings which will cause btcd to reject any peers whose user-agent contains any of the blacklisted substrings."`
 	AgentWhitelist       []string      `long:"agentwhitelist" description:"A comma separated list of user-agent substrings which will cause btcd to require all peers' user-agents to contain one of the whitelisted substrings. The blacklist is applied before the blacklist, and an empty whitelist will allow all agents that do not fail the blacklist."`
+	AssumeValid          string        `long:"assumevalid" description:"Skip script validation for the ancestors of the block with this hash once the best known headers have enough work and bury it by two weeks of blocks (default: the block defined by the network) -- Use 0 to validate all scripts"`
 	BanDuration          time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	BanThreshold         uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
 	BlockMaxSize         uint32        `long:"blockmaxsize" description:"Maximum block size in bytes to be used when creating a block"`
@@ -108,6 +114,9 @@ type config struct {
 	BlockMinWeight       uint32        `long:"blockminweight" description:"Mininum block weight to be used when creating a block"`
 	BlockPrioritySize    uint32        `long:"blockprioritysize" description:"Size in bytes for high-priority/low-fee transactions when creating a block"`
 	BlocksOnly           bool          `long:"blocksonly" description:"Do not accept transactions from remote peers."`
+	CheckBlocks          int32         `long:"checkblocks" description:"The number of most recent blocks to verify when starting after an unclean shutdown -- Use 0 to disable the verification"`
+	CheckLevel           int32         `long:"checklevel" description:"How thorough the verification of the most recent blocks after an unclean shutdown is (0-4) -- See the verifychain RPC for the checks of each level"`
+	ChainParams          string        `long:"chainparams" description:"Path to a JSON or TOML file which defines a custom network to use instead of one of the built-in networks"`
 	ConfigFile           string        `short:"C" long:"configfile" description:"Path to configuration file"`
 	ConnectPeers         []string      `long:"connect" description:"Connect only to the specified peers at startup"`
 	CPUProfile           string        `long:"cpuprofile" description:"Write CPU profile to the specified file"`
@@ -142,11 +151,13 @@ type config struct {
 	OnionProxyPass       string        `long:"onionpass" default-mask:"-" description:"Password for onion proxy server"`
 	OnionProxyUser       string        `long:"onionuser" description:"Username for onion proxy server"`
 	Profile              string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	Prune                uint64        `long:"prune" description:"Delete old blocks from the database once the stored blocks exceed the specified target size in MiB and only serve recent blocks to peers (minimum 1536) -- Use 0 to keep all blocks"`
 	Proxy                string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyPass            string        `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
 	ProxyUser            string        `long:"proxyuser" description:"Username for proxy server"`
 	RegressionTest       bool          `long:"regtest" description:"Use the regression test network"`
 	RejectNonStd         bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
+	ReindexChainState    bool          `long:"reindex-chainstate" description:"Rebuild the chain state, including the utxo set and the optional indexes, from the blocks stored in the database"`
 	RejectReplacement    bool          `long:"rejectreplacement" description:"Reject transactions that attempt to replace existing transactions within the mempool through the Replace-By-Fee (RBF) signaling policy."`
 	RelayNonStd          bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RPCCert              string        `long:"rpccert" description:"File containing the certificate file"`
@@ -169,14 +180,18 @@ type config struct {
 	TorIsolation         bool          `long:"torisolation" description:"Enable Tor stream isolation by randomizing user credentials for each connection."`
 	TrickleInterval      time.Duration `long:"trickleinterval" description:"Minimum time between attempts to send new inventory to a connected peer"`
 	TxIndex              bool          `long:"txindex" description:"Maintain a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC"`
+	UtxoCacheMaxSizeMiB  uint          `long:"utxocachemaxsize" description:"The maximum size in MiB of the UTXO cache which holds changes to the UTXO set in memory before they are written to the database -- Use 0 to write the changes for every block immediately"`
+	UtxoFlushInterval    time.Duration `long:"utxocacheflushinterval" description:"The maximum amount of time changes to the UTXO set are held in the UTXO cache before they are written to the database"`
 	UserAgentComments    []string      `long:"uacomment" description:"Comment to add to the user agent -- See BIP 14 for more information."`
 	Upnp                 bool          `long:"upnp" description:"Use UPnP to map our listening port outside of NAT"`
+	VerthashFile         string        `long:"verthashfile" description:"Path to the Verthash datafile which is required to validate blocks mined with Verthash and is generated when it does not exist (default: verthash.dat in the data directory)"`
 	ShowVersion          bool          `short:"V" long:"version" description:"Display version information and exit"`
 	Whitelists           []string      `long:"whitelist" description:"Add an IP network or IP that will not be banned. (eg. 192.168.1.0/24 or ::1)"`
 	lookup               func(string) ([]net.IP, error)
 	oniondial            func(string, string, time.Duration) (net.Conn, error)
 	dial                 func(string, string, time.Duration) (net.Conn, error)
 	addCheckpoints       []chaincfg.Checkpoint
+	assumeValid          *chainhash.Hash
 	miningAddrs          []btcutil.Address
 	minRelayTxFee        btcutil.Amount
 	whitelists           []*net.IPNet
@@ -436,6 +451,10 @@ func loadConfig() (*config, []string, error) {
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
+		UtxoCacheMaxSizeMiB:  defaultUtxoCacheMaxSizeMiB,
+		UtxoFlushInterval:    blockchain.DefaultUtxoCacheFlushInterval,
+		CheckBlocks:          defaultCheckBlocks,
+		CheckLevel:           defaultCheckLevel,
 		Generate:             defaultGenerate,
 		TxIndex:              defaultTxIndex,
 		AddrIndex:            defaultAddrIndex,
@@ -595,10 +614,31 @@ func loadConfig() (*config, []string, error) {
 		)
 		activeNetParams.Params = &chainParams
 	}
+	if cfg.ChainParams != "" {
+		numNets++
+
+		// Load the custom network from the parameter file and register
+		// it so the address and key encodings it defines are known to
+		// the rest of the code.
+		cfg.ChainParams = cleanAndExpandPath(cfg.ChainParams)
+		chainParams, err := chaincfg.LoadParamsFile(cfg.ChainParams)
+		if err == nil {
+			err = chaincfg.Register(chainParams)
+		}
+		if err != nil {
+			str := "%s: Unable to load network parameters from " +
+				"%s: %v"
+			err := fmt.Errorf(str, funcName, cfg.ChainParams, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		activeNetParams = newCustomNetParams(chainParams)
+	}
 	if numNets > 1 {
-		str := "%s: The testnet, regtest, segnet, signet and simnet " +
-			"params can't be used together -- choose one of the " +
-			"five"
+		str := "%s: The testnet, regtest, segnet, signet, simnet " +
+			"and chainparams params can't be used together -- " +
+			"choose one of the six"
 		err := fmt.Errorf(str, funcName)
 		fmt.Fprintln(os.Stderr, err)
 		fmt.Fprintln(os.Stderr, usageMessage)
@@ -635,13 +675,21 @@ func loadConfig() (*config, []string, error) {
 	}
 	cfg.RelayNonStd = relayNonStd
 
+	// The Verthash datafile is the same for every network, so it defaults
+	// to the base data directory rather than the per-network one.
+	cfg.DataDir = cleanAndExpandPath(cfg.DataDir)
+	if cfg.VerthashFile == "" {
+		cfg.VerthashFile = filepath.Join(cfg.DataDir,
+			verthash.DefaultDatafileName)
+	}
+	cfg.VerthashFile = cleanAndExpandPath(cfg.VerthashFile)
+
 	// Append the network type to the data directory so it is "namespaced"
 	// per network.  In addition to the block database, there are other
 	// pieces of data that are saved to disk such as address manager state.
 	// All data is specific to a network, so namespacing the data directory
 	// means each individual piece of serialized data does not have to
 	// worry about changing names per network and such.
-	cfg.DataDir = cleanAndExpandPath(cfg.DataDir)
 	cfg.DataDir = filepath.Join(cfg.DataDir, netName(activeNetParams))
 
 	// Append the network type to the log directory so it is "namespaced"
@@ -924,6 +972,65 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// --prune must be large enough to hold a few block files along with
+	// the most recent blocks which are always kept.
+	if cfg.Prune != 0 && cfg.Prune < pruneMinSizeMiB {
+		err := fmt.Errorf("%s: the --prune option must be at least "+
+			"%d MiB -- parsed [%d]", funcName, pruneMinSizeMiB,
+			cfg.Prune)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --checklevel must be one of the levels of the chain verification.
+	if cfg.CheckLevel < 0 || cfg.CheckLevel > blockchain.MaxVerifyLevel {
+		err := fmt.Errorf("%s: the --checklevel option must be between "+
+			"0 and %d -- parsed [%d]", funcName,
+			blockchain.MaxVerifyLevel, cfg.CheckLevel)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --checkblocks can't be negative.
+	if cfg.CheckBlocks < 0 {
+		err := fmt.Errorf("%s: the --checkblocks option may not be "+
+			"negative -- parsed [%d]", funcName, cfg.CheckBlocks)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --prune and --txindex do not mix because the transaction index
+	// refers to transactions in blocks which are deleted by pruning.
+	if cfg.Prune != 0 && cfg.TxIndex {
+		err := fmt.Errorf("%s: the --prune and --txindex options may "+
+			"not be activated at the same time", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --prune and --addrindex do not mix because the address index relies
+	// on the transaction index.
+	if cfg.Prune != 0 && cfg.AddrIndex {
+		err := fmt.Errorf("%s: the --prune and --addrindex options may "+
+			"not be activated at the same time", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --reindex-chainstate needs all blocks, which are deleted by --prune.
+	if cfg.Prune != 0 && cfg.ReindexChainState {
+		err := fmt.Errorf("%s: the --prune and --reindex-chainstate "+
+			"options may not be activated at the same time", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Check mining addresses are valid and saved parsed versions.
 	cfg.miningAddrs = make([]btcutil.Address, 0, len(cfg.MiningAddrs))
 	for _, strAddr := range cfg.MiningAddrs {
@@ -1022,6 +1129,22 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Determine the assumed valid block.  The block defined by the network
+	// is used unless one is specified, while 0 disables the optimization.
+	cfg.assumeValid = activeNetParams.AssumeValid
+	if cfg.AssumeValid == "0" {
+		cfg.assumeValid = nil
+	} else if cfg.AssumeValid != "" {
+		cfg.assumeValid, err = chainhash.NewHashFromStr(cfg.AssumeValid)
+		if err != nil {
+			str := "%s: Error parsing assumevalid: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
 	// Tor stream isolation requires either proxy or onion proxy to be set.
 	if cfg.TorIsolation && cfg.Proxy == "" && cfg.OnionProxy == "" {
 		str := "%s: Tor stream isolation requires either proxy or " +
@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/blockchain/indexers"
 	"github.com/btcsuite/btcd/database"
 	"github.com/btcsuite/btclog"
 	flags "github.com/jessevdk/go-flags"
@@ -66,6 +68,10 @@ func realMain() error {
 	dbLog := backendLogger.Logger("BCDB")
 	dbLog.SetLevel(btclog.LevelDebug)
 	database.UseLogger(dbLog)
+	chainLog := backendLogger.Logger("CHAN")
+	blockchain.UseLogger(chainLog)
+	indxLog := backendLogger.Logger("INDX")
+	indexers.UseLogger(indxLog)
 
 	// Setup the parser options and commands.
 	appName := filepath.Base(os.Args[0])
@@ -88,6 +94,14 @@ func realMain() error {
 	parser.AddCommand("fetchblockregion",
 		"Fetch the specified block region from the database", "",
 		&blockRegionCfg)
+	parser.AddCommand("reindexchainstate",
+		"Rebuild the chain state from the blocks in the database",
+		"Wipe the chain state, which consists of the block index, the "+
+			"spend journal and the utxo set, along with the optional "+
+			"indexes and rebuild it by validating all blocks stored "+
+			"in the database again.  An interrupted reindex is "+
+			"resumed the next time the chain is loaded.",
+		&reindexCfg)
 
 	// Parse command line and invoke the Execute function for the specified
 	// command.
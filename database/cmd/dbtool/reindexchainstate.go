@@ -0,0 +1,116 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/blockchain/indexers"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/verthash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// reindexCmd defines the configuration options for the reindexchainstate
+// command.
+type reindexCmd struct {
+	UtxoCacheMaxSizeMiB uint   `long:"utxocachemaxsize" description:"The maximum size in MiB of the UTXO cache"`
+	VerthashFile        string `long:"verthashfile" description:"Path to the Verthash datafile which is required to validate blocks mined with Verthash (default: verthash.dat in the btcd home directory)"`
+}
+
+var (
+	// reindexCfg defines the configuration options for the command.
+	reindexCfg = reindexCmd{
+		UtxoCacheMaxSizeMiB: 250,
+		VerthashFile:        filepath.Join(btcdHomeDir, "data", verthash.DefaultDatafileName),
+	}
+)
+
+// loadVerthashDatafile opens and verifies the Verthash datafile and registers
+// it as the proof-of-work hash function for Verthash blocks.  Nil is returned
+// without an error when the active network never uses Verthash.
+func loadVerthashDatafile(path string) (*verthash.Datafile, error) {
+	usesVerthash := false
+	for _, fork := range activeNetParams.PowForks {
+		if fork.Algorithm == wire.PowVerthash {
+			usesVerthash = true
+			break
+		}
+	}
+	if !usesVerthash {
+		return nil, nil
+	}
+
+	log.Infof("Loading Verthash datafile from '%s'", path)
+	df, err := verthash.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load Verthash datafile: %v", err)
+	}
+	if err := df.Verify(); err != nil {
+		df.Close()
+		return nil, fmt.Errorf("unable to verify Verthash datafile "+
+			"'%s': %v", path, err)
+	}
+
+	wire.RegisterPowHashFunc(wire.PowVerthash, df.PowHash)
+	return df, nil
+}
+
+// Execute is the main entry point for the command.  It's invoked by the parser.
+func (cmd *reindexCmd) Execute(args []string) error {
+	// Setup the global config options and ensure they are valid.
+	if err := setupGlobalConfig(); err != nil {
+		return err
+	}
+
+	// The stored blocks are validated again, so the Verthash datafile is
+	// needed on networks which use it.
+	df, err := loadVerthashDatafile(cmd.VerthashFile)
+	if err != nil {
+		return err
+	}
+	if df != nil {
+		defer df.Close()
+	}
+
+	// Load the block database.
+	db, err := loadBlockDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Stop reindexing on Ctrl+C.  The progress is kept in the database, so
+	// the reindex is resumed the next time the chain is loaded.
+	interrupt := make(chan struct{})
+	addInterruptHandler(func() {
+		close(interrupt)
+	})
+
+	// Drop the optional indexes since they refer to the chain state which
+	// is rebuilt.  Dropping the tx index also drops the address index since
+	// it relies on it.
+	if err := indexers.DropTxIndex(db, interrupt); err != nil {
+		return err
+	}
+	if err := indexers.DropCfIndex(db, interrupt); err != nil {
+		return err
+	}
+
+	// Loading the chain with the reindex option wipes the chain state and
+	// replays all stored blocks.
+	_, err = blockchain.New(&blockchain.Config{
+		DB:                db,
+		ChainParams:       activeNetParams,
+		TimeSource:        blockchain.NewMedianTime(),
+		SigCache:          txscript.NewSigCache(100000),
+		UtxoCacheMaxSize:  uint64(cmd.UtxoCacheMaxSizeMiB) * 1024 * 1024,
+		ReindexChainState: true,
+		Interrupt:         interrupt,
+	})
+	return err
+}
@@ -15,6 +15,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -168,6 +170,11 @@ type blockStore struct {
 	// new blocks are written to.
 	writeCursor *writeCursor
 
+	// firstFileNum is the number of the oldest block file on disk.  It is
+	// only non-zero when older block files have been deleted by pruning.
+	// It is protected by the write cursor mutex.
+	firstFileNum uint32
+
 	// These functions are set to openFile, openWriteFile, and deleteFile by
 	// default, but are exposed here to allow the whitebox tests to replace
 	// them when working with mock files.
@@ -307,6 +314,50 @@ func (s *blockStore) deleteFile(fileNum uint32) error {
 	return nil
 }
 
+// deleteFiles closes and removes the block files for the passed flat file
+// numbers, which must be the oldest files on disk in ascending order, and
+// advances the first file number past them.  It is used to delete block files
+// once all of the blocks they contain have been pruned from the block index.
+//
+// Since the blocks are no longer referenced at that point, any errors are
+// simply logged at a warning level.  Files which could not be removed will
+// be deleted again by the next prune.
+func (s *blockStore) deleteFiles(fileNums []uint32) {
+	// Close the files when they are open for reads.  The close is done
+	// under the write lock for the file to prevent it from being closed
+	// out from under any readers currently reading from it.
+	s.obfMutex.Lock()
+	for _, fileNum := range fileNums {
+		blockFile, ok := s.openBlockFiles[fileNum]
+		if !ok {
+			continue
+		}
+
+		s.lruMutex.Lock()
+		s.openBlocksLRU.Remove(s.fileNumToLRUElem[fileNum])
+		delete(s.fileNumToLRUElem, fileNum)
+		s.lruMutex.Unlock()
+
+		blockFile.Lock()
+		_ = blockFile.file.Close()
+		blockFile.Unlock()
+		delete(s.openBlockFiles, fileNum)
+	}
+	s.obfMutex.Unlock()
+
+	for _, fileNum := range fileNums {
+		if err := s.deleteFileFunc(fileNum); err != nil {
+			log.Warnf("PRUNE: Failed to delete block file number "+
+				"%d: %v", fileNum, err)
+		}
+	}
+
+	wc := s.writeCursor
+	wc.Lock()
+	s.firstFileNum = fileNums[len(fileNums)-1] + 1
+	wc.Unlock()
+}
+
 // blockFile attempts to return an existing file handle for the passed flat file
 // number if it is already open as well as marking it as most recently used.  It
 // will also open the file when it's not already open subject to the rules
@@ -713,27 +764,39 @@ func (s *blockStore) handleRollback(oldBlockFileNum, oldBlockOffset uint32) {
 }
 
 // scanBlockFiles searches the database directory for all flat block files to
-// find the end of the most recent file.  This position is considered the
-// current write cursor which is also stored in the metadata.  Thus, it is used
-// to detect unexpected shutdowns in the middle of writes so the block files
-// can be reconciled.
-func scanBlockFiles(dbPath string) (int, uint32) {
+// find the oldest file along with the end of the most recent file.  This
+// position is considered the current write cursor which is also stored in the
+// metadata.  Thus, it is used to detect unexpected shutdowns in the middle of
+// writes so the block files can be reconciled.  The oldest file is only
+// something other than the first one when older files have been pruned.
+func scanBlockFiles(dbPath string) (int, int, uint32) {
+	firstFile := -1
 	lastFile := -1
 	fileLen := uint32(0)
-	for i := 0; ; i++ {
-		filePath := blockFilePath(dbPath, uint32(i))
+	paths, _ := filepath.Glob(filepath.Join(dbPath, "*.fdb"))
+	for _, filePath := range paths {
+		fileName := strings.TrimSuffix(filepath.Base(filePath), ".fdb")
+		fileNum, err := strconv.ParseUint(fileName, 10, 32)
+		if err != nil || filePath != blockFilePath(dbPath, uint32(fileNum)) {
+			continue
+		}
 		st, err := os.Stat(filePath)
 		if err != nil {
-			break
+			continue
 		}
-		lastFile = i
 
-		fileLen = uint32(st.Size())
+		if firstFile == -1 || int(fileNum) < firstFile {
+			firstFile = int(fileNum)
+		}
+		if int(fileNum) > lastFile {
+			lastFile = int(fileNum)
+			fileLen = uint32(st.Size())
+		}
 	}
 
-	log.Tracef("Scan found latest block file #%d with length %d", lastFile,
-		fileLen)
-	return lastFile, fileLen
+	log.Tracef("Scan found block files #%d through #%d with length %d",
+		firstFile, lastFile, fileLen)
+	return firstFile, lastFile, fileLen
 }
 
 // newBlockStore returns a new block store with the current block file number
@@ -742,8 +805,9 @@ func newBlockStore(basePath string, network wire.BitcoinNet) *blockStore {
 	// Look for the end of the latest block to file to determine what the
 	// write cursor position is from the viewpoing of the block files on
 	// disk.
-	fileNum, fileOff := scanBlockFiles(basePath)
+	firstFileNum, fileNum, fileOff := scanBlockFiles(basePath)
 	if fileNum == -1 {
+		firstFileNum = 0
 		fileNum = 0
 		fileOff = 0
 	}
@@ -761,6 +825,7 @@ func newBlockStore(basePath string, network wire.BitcoinNet) *blockStore {
 			curFileNum: uint32(fileNum),
 			curOffset:  fileOff,
 		},
+		firstFileNum: uint32(firstFileNum),
 	}
 	store.openFileFunc = store.openFile
 	store.openWriteFileFunc = store.openWriteFile
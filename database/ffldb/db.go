@@ -966,6 +966,10 @@ type transaction struct {
 	pendingBlocks    map[chainhash.Hash]int
 	pendingBlockData []pendingBlock
 
+	// Block files that need to be deleted on commit since all of the
+	// blocks they contain have been pruned.
+	pendingPrune []uint32
+
 	// Keys that need to be stored or deleted on commit.
 	pendingKeys   *treap.Mutable
 	pendingRemove *treap.Mutable
@@ -1583,6 +1587,186 @@ func (tx *transaction) FetchBlockRegions(regions []database.BlockRegion) ([][]by
 	return blockRegions, nil
 }
 
+// PruneBlocks deletes the oldest block files until the total size of the flat
+// block files is no more than the provided target size in bytes.  Pruning stops
+// at the first file containing a block for which the provided keep function
+// returns true.  The current write file is never deleted.  The block index
+// entries for the blocks in the deleted files are removed and the hashes of
+// those blocks are returned.
+//
+// The block files themselves are only deleted once the transaction has been
+// committed.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxNotWritable if attempted against a read-only transaction
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) PruneBlocks(targetSize uint64, keep func(hash *chainhash.Hash) bool) ([]chainhash.Hash, error) {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	// Ensure the transaction is writable.
+	if !tx.writable {
+		str := "prune blocks requires a writable database transaction"
+		return nil, makeDbErr(database.ErrTxNotWritable, str, nil)
+	}
+
+	// Determine the range of block files which are candidates for
+	// deletion while skipping any which are already pending deletion.
+	store := tx.db.store
+	wc := store.writeCursor
+	wc.RLock()
+	firstFileNum := store.firstFileNum
+	curFileNum, curOffset := wc.curFileNum, wc.curOffset
+	wc.RUnlock()
+	if numPending := len(tx.pendingPrune); numPending > 0 {
+		firstFileNum = tx.pendingPrune[numPending-1] + 1
+	}
+	if firstFileNum >= curFileNum {
+		return nil, nil
+	}
+
+	// Nothing to do when the block files are already within the target
+	// size.  All files other than the current write file are treated as
+	// being the maximum size since new files are only started once the
+	// previous one is full.
+	maxFileSize := uint64(store.maxBlockFileSize)
+	totalSize := uint64(curFileNum-firstFileNum)*maxFileSize +
+		uint64(curOffset)
+	if totalSize <= targetSize {
+		return nil, nil
+	}
+
+	// Determine the last file which needs to be deleted to get the total
+	// size within the target.
+	numFiles := (totalSize - targetSize + maxFileSize - 1) / maxFileSize
+	if numFiles > uint64(curFileNum-firstFileNum) {
+		numFiles = uint64(curFileNum - firstFileNum)
+	}
+	lastFileNum := firstFileNum + uint32(numFiles) - 1
+
+	// Gather the blocks stored in each of the candidate files from the
+	// block index.
+	fileBlocks := make(map[uint32][]chainhash.Hash)
+	cursor := tx.blockIdxBucket.Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		loc := deserializeBlockLoc(cursor.Value())
+		if loc.blockFileNum < firstFileNum || loc.blockFileNum > lastFileNum {
+			continue
+		}
+
+		var hash chainhash.Hash
+		copy(hash[:], cursor.Key())
+		fileBlocks[loc.blockFileNum] = append(fileBlocks[loc.blockFileNum],
+			hash)
+	}
+
+	// Delete the candidate files oldest first along with the block index
+	// entries for the blocks they contain until reaching a file with a
+	// block that must be kept.
+	var pruned []chainhash.Hash
+	fileNum := firstFileNum
+	for ; fileNum <= lastFileNum; fileNum++ {
+		hashes := fileBlocks[fileNum]
+		mustKeep := false
+		for i := range hashes {
+			if keep != nil && keep(&hashes[i]) {
+				mustKeep = true
+				break
+			}
+		}
+		if mustKeep {
+			break
+		}
+
+		for i := range hashes {
+			err := tx.blockIdxBucket.Delete(hashes[i][:])
+			if err != nil {
+				return nil, err
+			}
+		}
+		pruned = append(pruned, hashes...)
+		tx.pendingPrune = append(tx.pendingPrune, fileNum)
+	}
+	if fileNum > firstFileNum {
+		log.Debugf("Pruning %d blocks from block files %d through %d",
+			len(pruned), firstFileNum, fileNum-1)
+	}
+
+	return pruned, nil
+}
+
+// BeenPruned returns whether or not any block files have ever been deleted
+// from the database by PruneBlocks.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) BeenPruned() (bool, error) {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return false, err
+	}
+
+	// Block files are numbered from zero and only ever deleted by pruning,
+	// so the database has been pruned when the first file is missing.
+	wc := tx.db.store.writeCursor
+	wc.RLock()
+	beenPruned := tx.db.store.firstFileNum > 0
+	wc.RUnlock()
+	return beenPruned, nil
+}
+
+// FetchBlockHashes returns the hashes of all blocks stored in the database in
+// the order they were stored, followed by the blocks pending storage in the
+// transaction.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) FetchBlockHashes() ([]chainhash.Hash, error) {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	// The block index is keyed by hash, so sort the blocks by their
+	// location in the block files to get the order they were stored in.
+	type storedBlock struct {
+		hash chainhash.Hash
+		loc  blockLocation
+	}
+	var stored []storedBlock
+	cursor := tx.blockIdxBucket.Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		var block storedBlock
+		copy(block.hash[:], cursor.Key())
+		block.loc = deserializeBlockLoc(cursor.Value())
+		stored = append(stored, block)
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		a, b := &stored[i].loc, &stored[j].loc
+		if a.blockFileNum != b.blockFileNum {
+			return a.blockFileNum < b.blockFileNum
+		}
+		return a.fileOffset < b.fileOffset
+	})
+
+	hashes := make([]chainhash.Hash, 0, len(stored)+len(tx.pendingBlockData))
+	for i := range stored {
+		hashes = append(hashes, stored[i].hash)
+	}
+	for _, pending := range tx.pendingBlockData {
+		hashes = append(hashes, *pending.hash)
+	}
+	return hashes, nil
+}
+
 // close marks the transaction closed then releases any pending data, the
 // underlying snapshot, the transaction read lock, and the write lock when the
 // transaction is writable.
@@ -1593,6 +1777,9 @@ func (tx *transaction) close() {
 	tx.pendingBlocks = nil
 	tx.pendingBlockData = nil
 
+	// Clear pending block files that would have been deleted on commit.
+	tx.pendingPrune = nil
+
 	// Clear pending keys that would have been written or deleted on commit.
 	tx.pendingKeys = nil
 	tx.pendingRemove = nil
@@ -1666,7 +1853,21 @@ func (tx *transaction) writePendingAndCommit() error {
 
 	// Atomically update the database cache.  The cache automatically
 	// handles flushing to the underlying persistent storage database.
-	return tx.db.cache.commitTx(tx)
+	if err := tx.db.cache.commitTx(tx); err != nil {
+		return err
+	}
+
+	// Delete the block files whose blocks were pruned.  The metadata is
+	// flushed to persistent storage first so the block index never refers
+	// to a deleted file, even in unclean shutdown scenarios.
+	if len(tx.pendingPrune) > 0 {
+		if err := tx.db.cache.flush(); err != nil {
+			return err
+		}
+		tx.db.store.deleteFiles(tx.pendingPrune)
+	}
+
+	return nil
 }
 
 // Commit commits all changes that have been made to the root metadata bucket
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/database"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcd/btcutil"
@@ -249,6 +250,248 @@ func TestCornerCases(t *testing.T) {
 	}
 }
 
+// TestPruneBlocks ensures pruning deletes the oldest block files along with the
+// blocks they contain, honors the blocks which must be kept, and that the
+// pruned state survives reopening the database.
+func TestPruneBlocks(t *testing.T) {
+	t.Parallel()
+
+	// Create a new database to run tests against.
+	dbPath := filepath.Join(os.TempDir(), "ffldb-pruneblocks")
+	_ = os.RemoveAll(dbPath)
+	idb, err := openDB(dbPath, blockDataNet, true)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+
+	// Store 100 empty blocks with a maximum block file size which results
+	// in 11 blocks per file, since each serialized empty block takes 81
+	// bytes plus 12 bytes of overhead.  That leaves a single block in the
+	// tenth and final file.
+	idb.(*db).store.maxBlockFileSize = 1024
+	blocks := make([]*btcutil.Block, 100)
+	for i := range blocks {
+		blocks[i] = btcutil.NewBlock(&wire.MsgBlock{
+			Header: wire.BlockHeader{Nonce: uint32(i)},
+		})
+	}
+	err = idb.Update(func(tx database.Tx) error {
+		for _, block := range blocks {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+
+	// hasBlocks returns whether each of the test blocks is stored.
+	hasBlocks := func(idb database.DB) []bool {
+		var have []bool
+		err := idb.View(func(tx database.Tx) error {
+			hashes := make([]chainhash.Hash, len(blocks))
+			for i, block := range blocks {
+				hashes[i] = *block.Hash()
+			}
+			var err error
+			have, err = tx.HasBlocks(hashes)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("HasBlocks: unexpected error: %v", err)
+		}
+		return have
+	}
+
+	// checkPruned ensures exactly the blocks before the passed index have
+	// been pruned.
+	checkPruned := func(idb database.DB, firstKept int) {
+		t.Helper()
+		for i, have := range hasBlocks(idb) {
+			if have != (i >= firstKept) {
+				t.Fatalf("block %d: unexpected existence - got %v, "+
+					"want %v", i, have, i >= firstKept)
+			}
+		}
+
+		var beenPruned bool
+		err := idb.View(func(tx database.Tx) error {
+			var err error
+			beenPruned, err = tx.BeenPruned()
+			return err
+		})
+		if err != nil {
+			t.Fatalf("BeenPruned: unexpected error: %v", err)
+		}
+		if beenPruned != (firstKept > 0) {
+			t.Fatalf("BeenPruned: unexpected result - got %v, want "+
+				"%v", beenPruned, firstKept > 0)
+		}
+	}
+	checkPruned(idb, 0)
+
+	// Ensure pruning requires a writable transaction.
+	err = idb.View(func(tx database.Tx) error {
+		_, err := tx.PruneBlocks(0, nil)
+		return err
+	})
+	if !checkDbError(t, "PruneBlocks: read-only", err,
+		database.ErrTxNotWritable) {
+		return
+	}
+
+	// Ensure nothing is pruned while the files are within the target.
+	var pruned []chainhash.Hash
+	err = idb.Update(func(tx database.Tx) error {
+		var err error
+		pruned, err = tx.PruneBlocks(10*1024, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("PruneBlocks: unexpected error: %v", err)
+	}
+	if len(pruned) != 0 {
+		t.Fatalf("PruneBlocks: pruned %d blocks within the target",
+			len(pruned))
+	}
+
+	// Ensure pruning stops at the second file since it contains a block
+	// which must be kept and that the first file is only removed from
+	// disk once the transaction commits.
+	keep := func(hash *chainhash.Hash) bool {
+		return hash.IsEqual(blocks[20].Hash())
+	}
+	err = idb.Update(func(tx database.Tx) error {
+		var err error
+		pruned, err = tx.PruneBlocks(0, keep)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(blockFilePath(dbPath, 0)); err != nil {
+			t.Fatalf("block file 0 removed before commit: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("PruneBlocks: unexpected error: %v", err)
+	}
+	if len(pruned) != 11 {
+		t.Fatalf("PruneBlocks: unexpected number of pruned blocks - "+
+			"got %d, want 11", len(pruned))
+	}
+	if _, err := os.Stat(blockFilePath(dbPath, 0)); !os.IsNotExist(err) {
+		t.Fatalf("block file 0 not removed after commit: %v", err)
+	}
+	checkPruned(idb, 11)
+
+	// Prune down to three files worth of data.  The eight files left
+	// before the write file and the single block after it exceed the
+	// target by just over five files, so six more files are deleted.
+	err = idb.Update(func(tx database.Tx) error {
+		var err error
+		pruned, err = tx.PruneBlocks(3*1024, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("PruneBlocks: unexpected error: %v", err)
+	}
+	if len(pruned) != 66 {
+		t.Fatalf("PruneBlocks: unexpected number of pruned blocks - "+
+			"got %d, want 66", len(pruned))
+	}
+	checkPruned(idb, 77)
+
+	// Ensure the pruned state is loaded when the database is reopened and
+	// that the remaining blocks can still be read.
+	idb.Close()
+	idb, err = openDB(dbPath, blockDataNet, false)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error: %v", err)
+	}
+	defer idb.Close()
+	if firstFileNum := idb.(*db).store.firstFileNum; firstFileNum != 7 {
+		t.Fatalf("unexpected first block file - got %d, want 7",
+			firstFileNum)
+	}
+	checkPruned(idb, 77)
+	err = idb.View(func(tx database.Tx) error {
+		_, err := tx.FetchBlock(blocks[77].Hash())
+		return err
+	})
+	if err != nil {
+		t.Fatalf("FetchBlock: unexpected error: %v", err)
+	}
+}
+
+// TestFetchBlockHashes ensures the hashes of the stored blocks are returned in
+// the order the blocks were stored across block files and transactions.
+func TestFetchBlockHashes(t *testing.T) {
+	t.Parallel()
+
+	// Create a new database to run tests against.
+	dbPath := filepath.Join(os.TempDir(), "ffldb-fetchblockhashes")
+	_ = os.RemoveAll(dbPath)
+	idb, err := openDB(dbPath, blockDataNet, true)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+	defer idb.Close()
+
+	// Store the blocks across several block files, which results in an
+	// order that differs from the order of their hashes.
+	idb.(*db).store.maxBlockFileSize = 1024
+	blocks := make([]*btcutil.Block, 30)
+	for i := range blocks {
+		blocks[i] = btcutil.NewBlock(&wire.MsgBlock{
+			Header: wire.BlockHeader{Nonce: uint32(i)},
+		})
+	}
+	err = idb.Update(func(tx database.Tx) error {
+		for _, block := range blocks[:20] {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+
+	// The blocks pending storage in a transaction follow the stored ones.
+	err = idb.Update(func(tx database.Tx) error {
+		for _, block := range blocks[20:] {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+
+		hashes, err := tx.FetchBlockHashes()
+		if err != nil {
+			return err
+		}
+		if len(hashes) != len(blocks) {
+			t.Fatalf("FetchBlockHashes: unexpected number of hashes - "+
+				"got %d, want %d", len(hashes), len(blocks))
+		}
+		for i := range hashes {
+			if hashes[i] != *blocks[i].Hash() {
+				t.Fatalf("FetchBlockHashes: unexpected hash %d - "+
+					"got %v, want %v", i, hashes[i],
+					blocks[i].Hash())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchBlockHashes: unexpected error: %v", err)
+	}
+}
+
 // resetDatabase removes everything from the opened database associated with the
 // test context including all metadata and the mock files.
 func resetDatabase(tc *testContext) bool {
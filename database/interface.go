@@ -389,6 +389,48 @@ type Tx interface {
 	// implementations.
 	FetchBlockRegions(regions []BlockRegion) ([][]byte, error)
 
+	// PruneBlocks deletes the oldest stored blocks until the total size of
+	// the block storage is no more than the provided target size in bytes.
+	// The blocks are deleted in the order they were stored, and pruning
+	// stops early when it would delete a block for which the provided keep
+	// function returns true.  The keep function may be nil when there are no
+	// blocks which must be kept.  It returns the hashes of the deleted
+	// blocks.
+	//
+	// Depending on the backend implementation, blocks are deleted in
+	// groups, so the storage might remain somewhat larger than the target
+	// size.  The storage for the blocks is only released once the
+	// transaction has been committed.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxNotWritable if attempted against a read-only transaction
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// Other errors are possible depending on the implementation.
+	PruneBlocks(targetSize uint64, keep func(hash *chainhash.Hash) bool) ([]chainhash.Hash, error)
+
+	// BeenPruned returns whether or not any blocks have ever been deleted
+	// from the database by PruneBlocks.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// Other errors are possible depending on the implementation.
+	BeenPruned() (bool, error)
+
+	// FetchBlockHashes returns the hashes of all blocks stored in the
+	// database in the order they were stored, which allows the blocks to be
+	// processed again without any other record of them.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// Other errors are possible depending on the implementation.
+	FetchBlockHashes() ([]chainhash.Hash, error)
+
 	// ******************************************************************
 	// Methods related to both atomic metadata storage and block storage.
 	// ******************************************************************
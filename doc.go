@@ -27,6 +27,11 @@ Application Options:
       --addrindex             Maintain a full address-based transaction index
                               which makes the searchrawtransactions RPC
                               available
+      --assumevalid=          Skip script validation for the ancestors of the
+                              block with this hash once the best known headers
+                              have enough work and bury it by two weeks of
+                              blocks (default: the block defined by the
+                              network) -- Use 0 to validate all scripts
       --banduration=          How long to ban misbehaving peers.  Valid time
                               units are {s, m, h}.  Minimum 1 second (default:
                               24h0m0s)
@@ -44,6 +49,16 @@ Application Options:
                               transactions when creating a block (default:
                               50000)
       --blocksonly            Do not accept transactions from remote peers.
+      --checkblocks=          The number of most recent blocks to verify when
+                              starting after an unclean shutdown -- Use 0 to
+                              disable the verification (default: 6)
+      --checklevel=           How thorough the verification of the most recent
+                              blocks after an unclean shutdown is (0-4) -- See
+                              the verifychain RPC for the checks of each level
+                              (default: 3)
+      --chainparams=          Path to a JSON or TOML file which defines a
+                              custom network to use instead of one of the
+                              built-in networks
   -C, --configfile=           Path to configuration file
       --connect=              Connect only to the specified peers at startup
       --cpuprofile=           Write CPU profile to the specified file
@@ -110,10 +125,17 @@ Application Options:
       --onionuser=            Username for onion proxy server
       --profile=              Enable HTTP profiling on given port -- NOTE port
                               must be between 1024 and 65536
+      --prune=                Delete old blocks from the database once the
+                              stored blocks exceed the specified target size
+                              in MiB and only serve recent blocks to peers
+                              (minimum 1536) -- Use 0 to keep all blocks
       --proxy=                Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)
       --proxypass=            Password for proxy server
       --proxyuser=            Username for proxy server
       --regtest               Use the regression test network
+      --reindex-chainstate    Rebuild the chain state, including the utxo set
+                              and the optional indexes, from the blocks stored
+                              in the database
       --rejectnonstd          Reject non-standard transactions regardless of
                               the default settings for the active network.
       --relaynonstd           Relay non-standard transactions regardless of the
@@ -149,7 +171,19 @@ Application Options:
       --uacomment=            Comment to add to the user agent -- See BIP 14
                               for more information.
       --upnp                  Use UPnP to map our listening port outside of NAT
-  -V, --version               Display version information and exit
+      --utxocacheflushinterval= The maximum amount of time changes to the UTXO
+                              set are held in the UTXO cache before they are
+                              written to the database (default: 5m0s)
+      --utxocachemaxsize=     The maximum size in MiB of the UTXO cache which
+                              holds changes to the UTXO set in memory before
+                              they are written to the database -- Use 0 to
+                              write the changes for every block immediately
+                              (default: 250)
+      --verthashfile=         Path to the Verthash datafile which is required
+                              to validate blocks mined with Verthash and is
+                              generated when it does not exist (default:
+                              verthash.dat in the data directory)
+  -V, --version              Display version information and exit
       --whitelist=            Add an IP network or IP that will not be banned.
                               (eg. 192.168.1.0/24 or ::1)
 
@@ -1,6 +1,9 @@
 module github.com/btcsuite/btcd
 
 require (
+	github.com/BurntSushi/toml v0.4.1
+	github.com/aead/skein v0.0.0-20160722084837-9365ae6e95d2
+	github.com/bitgoin/lyra2rev2 v0.0.0-20161212102046-bae9ad2043bb
 	github.com/btcsuite/btcd/btcutil v1.0.0
 	github.com/btcsuite/btclog v0.0.0-20170628155309-84c8d2346e9f
 	github.com/btcsuite/go-socks v0.0.0-20170105172521-4720035b7bfd
@@ -8,6 +11,7 @@ require (
 	github.com/btcsuite/websocket v0.0.0-20150119174127-31079b680792
 	github.com/btcsuite/winsvc v1.0.0
 	github.com/davecgh/go-spew v1.1.1
+	github.com/dchest/blake256 v1.1.0
 	github.com/decred/dcrd/lru v1.0.0
 	github.com/jessevdk/go-flags v1.4.0
 	github.com/jrick/logrotate v1.0.0
@@ -16,10 +20,7 @@ require (
 
 require (
 	github.com/aead/siphash v1.0.1 // indirect
-	github.com/aead/skein v0.0.0-20160722084837-9365ae6e95d2 // indirect
-	github.com/bitgoin/lyra2rev2 v0.0.0-20161212102046-bae9ad2043bb // indirect
 	github.com/btcsuite/snappy-go v1.0.0 // indirect
-	github.com/dchest/blake256 v1.1.0 // indirect
 	github.com/kkdai/bstream v0.0.0-20161212061736-f391b8402d23 // indirect
 )
 
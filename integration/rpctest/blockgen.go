@@ -24,7 +24,8 @@ import (
 // to a value less than the target difficulty. When a successful solution is
 // found true is returned and the nonce field of the passed header is updated
 // with the solution. False is returned if no solution exists.
-func solveBlock(header *wire.BlockHeader, targetDifficulty *big.Int) bool {
+func solveBlock(header *wire.BlockHeader, targetDifficulty *big.Int,
+	powAlgo wire.PowAlgorithm) bool {
 	// sbResult is used by the solver goroutines to send results.
 	type sbResult struct {
 		found bool
@@ -44,7 +45,14 @@ func solveBlock(header *wire.BlockHeader, targetDifficulty *big.Int) bool {
 				return
 			default:
 				hdr.Nonce = i
-				hash := hdr.PowHash()
+				hash, err := hdr.PowHash(powAlgo)
+				if err != nil {
+					select {
+					case results <- sbResult{false, 0}:
+					case <-quit:
+					}
+					return
+				}
 				if blockchain.HashToBig(&hash).Cmp(targetDifficulty) <= 0 {
 					select {
 					case results <- sbResult{true, i}:
@@ -212,7 +220,8 @@ func CreateBlock(prevBlock *btcutil.Block, inclusionTxs []*btcutil.Tx,
 		}
 	}
 
-	found := solveBlock(&block.Header, net.PowLimit)
+	found := solveBlock(&block.Header, net.PowLimit,
+		net.PowAlgorithmAt(blockHeight))
 	if !found {
 		return nil, errors.New("Unable to solve block")
 	}
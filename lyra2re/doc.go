@@ -0,0 +1,23 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package lyra2re implements the Lyra2RE and Lyra2REv3 proof-of-work hashes used
+by Vertcoin.
+
+Vertcoin switched from scrypt-N to Lyra2RE at block 208301, to Lyra2REv2 at
+block 347000 and to Lyra2REv3 at block 1080000.  Lyra2REv2 is provided by the
+github.com/bitgoin/lyra2rev2 package.  This package provides the other two so
+that the headers of every era of the chain can be validated.
+
+Both hashes chain several hash functions around Lyra2, a memory-hard password
+hashing scheme:
+
+	Lyra2RE:   BLAKE-256, Keccak-256, Lyra2, Skein-256, Groestl-256
+	Lyra2REv3: BLAKE-256, Lyra2, CubeHash-256, Lyra2, BMW-256
+
+Lyra2REv3 uses a variant of Lyra2 which picks the rows it revisits differently.
+Lyra2, CubeHash-256 and BMW-256 are ported from github.com/bitgoin/lyra2rev2.
+*/
+package lyra2re
@@ -0,0 +1,183 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package lyra2re
+
+import "encoding/binary"
+
+const (
+	// groestlBlockSize is the size of a message block, and of the chaining
+	// state, of Groestl-256.
+	groestlBlockSize = 64
+
+	// groestlRounds is the number of rounds of the P and Q permutations of
+	// Groestl-256.
+	groestlRounds = 10
+)
+
+// groestlSbox is the AES S-box which Groestl uses in its SubBytes step.
+var groestlSbox = [256]byte{
+	0x63, 0x7c, 0x77, 0x7b, 0xf2, 0x6b, 0x6f, 0xc5, 0x30, 0x01, 0x67, 0x2b, 0xfe, 0xd7, 0xab, 0x76,
+	0xca, 0x82, 0xc9, 0x7d, 0xfa, 0x59, 0x47, 0xf0, 0xad, 0xd4, 0xa2, 0xaf, 0x9c, 0xa4, 0x72, 0xc0,
+	0xb7, 0xfd, 0x93, 0x26, 0x36, 0x3f, 0xf7, 0xcc, 0x34, 0xa5, 0xe5, 0xf1, 0x71, 0xd8, 0x31, 0x15,
+	0x04, 0xc7, 0x23, 0xc3, 0x18, 0x96, 0x05, 0x9a, 0x07, 0x12, 0x80, 0xe2, 0xeb, 0x27, 0xb2, 0x75,
+	0x09, 0x83, 0x2c, 0x1a, 0x1b, 0x6e, 0x5a, 0xa0, 0x52, 0x3b, 0xd6, 0xb3, 0x29, 0xe3, 0x2f, 0x84,
+	0x53, 0xd1, 0x00, 0xed, 0x20, 0xfc, 0xb1, 0x5b, 0x6a, 0xcb, 0xbe, 0x39, 0x4a, 0x4c, 0x58, 0xcf,
+	0xd0, 0xef, 0xaa, 0xfb, 0x43, 0x4d, 0x33, 0x85, 0x45, 0xf9, 0x02, 0x7f, 0x50, 0x3c, 0x9f, 0xa8,
+	0x51, 0xa3, 0x40, 0x8f, 0x92, 0x9d, 0x38, 0xf5, 0xbc, 0xb6, 0xda, 0x21, 0x10, 0xff, 0xf3, 0xd2,
+	0xcd, 0x0c, 0x13, 0xec, 0x5f, 0x97, 0x44, 0x17, 0xc4, 0xa7, 0x7e, 0x3d, 0x64, 0x5d, 0x19, 0x73,
+	0x60, 0x81, 0x4f, 0xdc, 0x22, 0x2a, 0x90, 0x88, 0x46, 0xee, 0xb8, 0x14, 0xde, 0x5e, 0x0b, 0xdb,
+	0xe0, 0x32, 0x3a, 0x0a, 0x49, 0x06, 0x24, 0x5c, 0xc2, 0xd3, 0xac, 0x62, 0x91, 0x95, 0xe4, 0x79,
+	0xe7, 0xc8, 0x37, 0x6d, 0x8d, 0xd5, 0x4e, 0xa9, 0x6c, 0x56, 0xf4, 0xea, 0x65, 0x7a, 0xae, 0x08,
+	0xba, 0x78, 0x25, 0x2e, 0x1c, 0xa6, 0xb4, 0xc6, 0xe8, 0xdd, 0x74, 0x1f, 0x4b, 0xbd, 0x8b, 0x8a,
+	0x70, 0x3e, 0xb5, 0x66, 0x48, 0x03, 0xf6, 0x0e, 0x61, 0x35, 0x57, 0xb9, 0x86, 0xc1, 0x1d, 0x9e,
+	0xe1, 0xf8, 0x98, 0x11, 0x69, 0xd9, 0x8e, 0x94, 0x9b, 0x1e, 0x87, 0xe9, 0xce, 0x55, 0x28, 0xdf,
+	0x8c, 0xa1, 0x89, 0x0d, 0xbf, 0xe6, 0x42, 0x68, 0x41, 0x99, 0x2d, 0x0f, 0xb0, 0x54, 0xbb, 0x16,
+}
+
+var (
+	// groestlShiftP and groestlShiftQ are the number of positions each
+	// row of the state is rotated to the left by the ShiftBytes step of
+	// the P and Q permutations respectively.
+	groestlShiftP = [8]int{0, 1, 2, 3, 4, 5, 6, 7}
+	groestlShiftQ = [8]int{1, 3, 5, 7, 0, 2, 4, 6}
+
+	// groestlMix is the first row of the circulant matrix the MixBytes
+	// step multiplies every column of the state with.
+	groestlMix = [8]byte{0x02, 0x02, 0x03, 0x04, 0x05, 0x03, 0x05, 0x07}
+)
+
+// gfMul multiplies two elements of GF(2^8) with the AES reduction polynomial
+// x^8 + x^4 + x^3 + x + 1.
+func gfMul(a, b byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+// groestlState is the 8x8 byte state of the Groestl-256 permutations.  The
+// state is filled column by column, so byte k of a block is stored in row
+// k%8 of column k/8.
+type groestlState [8][8]byte
+
+// load fills the state from the passed 64 bytes.
+func (s *groestlState) load(b []byte) {
+	for k := 0; k < groestlBlockSize; k++ {
+		s[k%8][k/8] = b[k]
+	}
+}
+
+// store serializes the state into the passed 64 bytes.
+func (s *groestlState) store(b []byte) {
+	for k := 0; k < groestlBlockSize; k++ {
+		b[k] = s[k%8][k/8]
+	}
+}
+
+// permute applies the P permutation to the state, or the Q permutation when
+// the passed flag is set.
+func (s *groestlState) permute(q bool) {
+	shift := &groestlShiftP
+	if q {
+		shift = &groestlShiftQ
+	}
+
+	for r := 0; r < groestlRounds; r++ {
+		// AddRoundConstant.
+		for j := 0; j < 8; j++ {
+			if q {
+				for i := 0; i < 7; i++ {
+					s[i][j] ^= 0xff
+				}
+				s[7][j] ^= byte(j<<4) ^ 0xff ^ byte(r)
+			} else {
+				s[0][j] ^= byte(j<<4) ^ byte(r)
+			}
+		}
+
+		// SubBytes and ShiftBytes.
+		var t groestlState
+		for i := 0; i < 8; i++ {
+			for j := 0; j < 8; j++ {
+				t[i][j] = groestlSbox[s[i][(j+shift[i])%8]]
+			}
+		}
+
+		// MixBytes.
+		for j := 0; j < 8; j++ {
+			for i := 0; i < 8; i++ {
+				var v byte
+				for k := 0; k < 8; k++ {
+					v ^= gfMul(groestlMix[(k-i+8)%8], t[k][j])
+				}
+				s[i][j] = v
+			}
+		}
+	}
+}
+
+// groestlCompress updates the passed chaining value with the passed message
+// block as f(h, m) = P(h ^ m) ^ Q(m) ^ h.
+func groestlCompress(h *[groestlBlockSize]byte, block []byte) {
+	var p, q groestlState
+	var hm [groestlBlockSize]byte
+	for i := range hm {
+		hm[i] = h[i] ^ block[i]
+	}
+	p.load(hm[:])
+	q.load(block)
+	p.permute(false)
+	q.permute(true)
+
+	var pb, qb [groestlBlockSize]byte
+	p.store(pb[:])
+	q.store(qb[:])
+	for i := range h {
+		h[i] ^= pb[i] ^ qb[i]
+	}
+}
+
+// groestl256 returns the Groestl-256 digest of the passed data.
+func groestl256(data []byte) []byte {
+	// The initial chaining value encodes the digest size in bits.
+	var h [groestlBlockSize]byte
+	binary.BigEndian.PutUint16(h[groestlBlockSize-2:], 256)
+
+	// Pad with a single one bit, zeros and the 64-bit number of blocks of
+	// the padded message so the length is a multiple of the block size.
+	padLen := groestlBlockSize - (len(data)+9)%groestlBlockSize
+	if padLen == groestlBlockSize {
+		padLen = 0
+	}
+	msg := make([]byte, len(data)+1+padLen+8)
+	copy(msg, data)
+	msg[len(data)] = 0x80
+	binary.BigEndian.PutUint64(msg[len(msg)-8:],
+		uint64(len(msg)/groestlBlockSize))
+
+	for i := 0; i < len(msg); i += groestlBlockSize {
+		groestlCompress(&h, msg[i:i+groestlBlockSize])
+	}
+
+	// The output transformation truncates P(h) ^ h to its last 256 bits.
+	var p groestlState
+	p.load(h[:])
+	p.permute(false)
+	var out [groestlBlockSize]byte
+	p.store(out[:])
+	for i := range out {
+		out[i] ^= h[i]
+	}
+	return out[groestlBlockSize-32:]
+}
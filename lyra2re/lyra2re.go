@@ -0,0 +1,46 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package lyra2re
+
+import (
+	"github.com/aead/skein"
+	"github.com/dchest/blake256"
+	"golang.org/x/crypto/sha3"
+)
+
+// Size is the size of a Lyra2RE or Lyra2REv3 hash in bytes.
+const Size = 32
+
+// blake256Sum returns the BLAKE-256 digest of the passed data.
+func blake256Sum(data []byte) []byte {
+	h := blake256.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Sum returns the Lyra2RE hash of the passed data.
+func Sum(data []byte) []byte {
+	keccak := sha3.NewLegacyKeccak256()
+	keccak.Write(blake256Sum(data))
+	hash := keccak.Sum(nil)
+
+	lyra2Hash := make([]byte, Size)
+	lyra2(lyra2Hash, hash, hash, 1, 8, 8, false)
+
+	var skeinHash [Size]byte
+	skein.Sum256(&skeinHash, lyra2Hash, nil)
+	return groestl256(skeinHash[:])
+}
+
+// SumV3 returns the Lyra2REv3 hash of the passed data.
+func SumV3(data []byte) []byte {
+	hash := blake256Sum(data)
+	lyra2Hash := make([]byte, Size)
+	lyra2(lyra2Hash, hash, hash, 1, 4, 4, true)
+
+	hash = cubehash256(lyra2Hash)
+	lyra2(lyra2Hash, hash, hash, 1, 4, 4, true)
+	return bmw256(lyra2Hash)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package lyra2re
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/aead/skein"
+	"github.com/bitgoin/lyra2rev2"
+	"golang.org/x/crypto/sha3"
+)
+
+// decodeHex decodes the passed hex string and panics on failure.  It is only
+// used with hard-coded test data.
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in test source: " + err.Error())
+	}
+	return b
+}
+
+// testHeader returns an 80-byte block header sized input which starts with the
+// passed prefix and is padded with zeros.
+func testHeader(prefix string) []byte {
+	header := make([]byte, 80)
+	copy(header, prefix)
+	return header
+}
+
+// TestGroestl256 ensures Groestl-256 matches the known answers published for
+// it.
+func TestGroestl256(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "",
+			want: "1a52d11d550039be16107f9c58db9ebcc417f16f736adb2502567119f0083467",
+		},
+		{
+			in:   "The quick brown fox jumps over the lazy dog",
+			want: "8c7ad62eb26a21297bc39c2d7293b4bd4d3399fa8afab29e970471739e28b301",
+		},
+		{
+			in:   "The quick brown fox jumps over the lazy dog.",
+			want: "f48290b1bcacee406a0429b993adb8fb3d065f4b09cbcdb464a631d4a0080aaf",
+		},
+	}
+
+	for _, test := range tests {
+		got := groestl256([]byte(test.in))
+		if !bytes.Equal(got, decodeHex(test.want)) {
+			t.Errorf("groestl256(%q): got %x, want %s", test.in, got,
+				test.want)
+		}
+	}
+}
+
+// TestPortedPrimitives ensures the ported Lyra2, CubeHash-256 and BMW-256 agree
+// with the lyra2rev2 package they were ported from by rebuilding the Lyra2REv2
+// chain from them.
+func TestPortedPrimitives(t *testing.T) {
+	t.Parallel()
+
+	for _, prefix := range []string{"", "test", "vertcoin", "\xff\x01"} {
+		header := testHeader(prefix)
+		want, err := lyra2rev2.Sum(header)
+		if err != nil {
+			t.Fatalf("lyra2rev2.Sum: unexpected error: %v", err)
+		}
+
+		keccak := sha3.NewLegacyKeccak256()
+		keccak.Write(blake256Sum(header))
+		hash := cubehash256(keccak.Sum(nil))
+		lyra2Hash := make([]byte, Size)
+		lyra2(lyra2Hash, hash, hash, 1, 4, 4, false)
+		var skeinHash [Size]byte
+		skein.Sum256(&skeinHash, lyra2Hash, nil)
+		got := bmw256(cubehash256(skeinHash[:]))
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("%q: got %x, want %x", prefix, got, want)
+		}
+	}
+}
+
+// TestSum ensures Sum and SumV3 keep producing the same hashes, and that both
+// differ from each other and from Lyra2REv2 for the same input.
+func TestSum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+		wantV3 string
+	}{
+		{
+			name:   "zeros",
+			prefix: "",
+			want:   "dba5e0ec33bc004cd1371371784fa34e6f8869d31fdb5aa614f45bba66ff213a",
+			wantV3: "cfae5e5045ec49d2e193d13fa49fea81ef82570241ca2a9fe5da00a4d9542ef4",
+		},
+		{
+			name:   "test",
+			prefix: "test",
+			want:   "b92a02f9f7e548094e877475fa25d79f12d749d4f5916046cfce6a08306998f9",
+			wantV3: "c4f6e24baeccda5ffdcfa724e657cc74fedbfece23972109d58c5d3ba1ab0faf",
+		},
+	}
+
+	for _, test := range tests {
+		header := testHeader(test.prefix)
+		got, gotV3 := Sum(header), SumV3(header)
+		if hex.EncodeToString(got) != test.want {
+			t.Errorf("%s: Sum: got %x, want %s", test.name, got,
+				test.want)
+		}
+		if hex.EncodeToString(gotV3) != test.wantV3 {
+			t.Errorf("%s: SumV3: got %x, want %s", test.name, gotV3,
+				test.wantV3)
+		}
+
+		v2, err := lyra2rev2.Sum(header)
+		if err != nil {
+			t.Fatalf("lyra2rev2.Sum: unexpected error: %v", err)
+		}
+		if bytes.Equal(got, gotV3) || bytes.Equal(got, v2) ||
+			bytes.Equal(gotV3, v2) {
+
+			t.Errorf("%s: hashes of different algorithms collide",
+				test.name)
+		}
+	}
+}
@@ -1120,19 +1120,13 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 	// the coinbase address itself can contain signature operations, the
 	// maximum allowed signature operations per transaction is less than
 	// the maximum allowed signature operations per block.
-	// TODO(roasbeef): last bool should be conditional on segwit activation
-	sigOpCost, err := blockchain.GetSigOpCost(tx, false, utxoView, true, true)
+	err = checkTxSigOpCost(tx, utxoView, mp.cfg.Policy.MaxSigOpCostPerTx)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
 			return nil, nil, chainRuleError(cerr)
 		}
 		return nil, nil, err
 	}
-	if sigOpCost > mp.cfg.Policy.MaxSigOpCostPerTx {
-		str := fmt.Sprintf("transaction %v sigop cost is too high: %d > %d",
-			txHash, sigOpCost, mp.cfg.Policy.MaxSigOpCostPerTx)
-		return nil, nil, txRuleError(wire.RejectNonstandard, str)
-	}
 
 	// Don't allow transactions with fees too low to get into a mined block.
 	//
@@ -1258,6 +1252,30 @@ func (mp *TxPool) MaybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit bool)
 	return hashes, txD, err
 }
 
+// ExplainStandardness evaluates every policy rule which determines whether the
+// passed transaction is standard and returns the results per rule, input and
+// output.  Unlike the checks performed when accepting a transaction, it does
+// not stop at the first violation, which makes it suitable for explaining why
+// a transaction is rejected.  Rules depending on outputs which are neither in
+// the main chain nor in the pool are not evaluated.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) ExplainStandardness(tx *btcutil.Tx) (*StandardnessReport, error) {
+	// Protect concurrent access.
+	mp.mtx.RLock()
+	utxoView, err := mp.fetchInputUtxos(tx)
+	mp.mtx.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// A standalone transaction will be mined into the next block at best,
+	// so evaluate it against the height of the next block.
+	nextBlockHeight := mp.cfg.BestHeight() + 1
+	return explainTransactionStandard(tx, utxoView, nextBlockHeight,
+		mp.cfg.MedianTimePast(), &mp.cfg.Policy), nil
+}
+
 // processOrphans is the internal function which implements the public
 // ProcessOrphans.  See the comment for ProcessOrphans for more details.
 //
@@ -79,6 +79,35 @@ func calcMinRequiredTxRelayFee(serializedSize int64, minRelayTxFee btcutil.Amoun
 	return minFee
 }
 
+// checkPrevScriptStandard ensures the public key script referenced by the
+// passed transaction input is of a standard form.
+func checkPrevScriptStandard(i int, scriptClass txscript.ScriptClass) error {
+	if scriptClass == txscript.NonStandardTy {
+		str := fmt.Sprintf("transaction input #%d has a "+
+			"non-standard script form", i)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	return nil
+}
+
+// checkP2SHSigOps ensures the passed transaction input, which must spend a
+// pay-to-script-hash output with the passed public key script, does not have
+// more than maxStandardP2SHSigOps signature operations.
+func checkP2SHSigOps(i int, txIn *wire.TxIn, originPkScript []byte) error {
+	numSigOps := txscript.GetPreciseSigOpCount(txIn.SignatureScript,
+		originPkScript, true)
+	if numSigOps > maxStandardP2SHSigOps {
+		str := fmt.Sprintf("transaction input #%d has "+
+			"%d signature operations which is more "+
+			"than the allowed max amount of %d",
+			i, numSigOps, maxStandardP2SHSigOps)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	return nil
+}
+
 // checkInputsStandard performs a series of checks on a transaction's inputs
 // to ensure they are "standard".  A standard transaction input within the
 // context of this function is one whose referenced public key script is of a
@@ -100,28 +129,41 @@ func checkInputsStandard(tx *btcutil.Tx, utxoView *blockchain.UtxoViewpoint) err
 		// function.
 		entry := utxoView.LookupEntry(txIn.PreviousOutPoint)
 		originPkScript := entry.PkScript()
-		switch txscript.GetScriptClass(originPkScript) {
-		case txscript.ScriptHashTy:
-			numSigOps := txscript.GetPreciseSigOpCount(
-				txIn.SignatureScript, originPkScript, true)
-			if numSigOps > maxStandardP2SHSigOps {
-				str := fmt.Sprintf("transaction input #%d has "+
-					"%d signature operations which is more "+
-					"than the allowed max amount of %d",
-					i, numSigOps, maxStandardP2SHSigOps)
-				return txRuleError(wire.RejectNonstandard, str)
+		scriptClass := txscript.GetScriptClass(originPkScript)
+		if err := checkPrevScriptStandard(i, scriptClass); err != nil {
+			return err
+		}
+		if scriptClass == txscript.ScriptHashTy {
+			err := checkP2SHSigOps(i, txIn, originPkScript)
+			if err != nil {
+				return err
 			}
-
-		case txscript.NonStandardTy:
-			str := fmt.Sprintf("transaction input #%d has a "+
-				"non-standard script form", i)
-			return txRuleError(wire.RejectNonstandard, str)
 		}
 	}
 
 	return nil
 }
 
+// checkTxSigOpCost ensures the cumulative signature operation cost of the
+// passed transaction does not exceed the passed maximum.  All of the outputs
+// referenced by the transaction must be available in the passed view.
+func checkTxSigOpCost(tx *btcutil.Tx, utxoView *blockchain.UtxoViewpoint,
+	maxSigOpCost int) error {
+
+	// TODO(roasbeef): last bool should be conditional on segwit activation
+	sigOpCost, err := blockchain.GetSigOpCost(tx, false, utxoView, true, true)
+	if err != nil {
+		return err
+	}
+	if sigOpCost > maxSigOpCost {
+		str := fmt.Sprintf("transaction %v sigop cost is too high: %d > %d",
+			tx.Hash(), sigOpCost, maxSigOpCost)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	return nil
+}
+
 // checkPkScriptStandard performs a series of checks on a transaction output
 // script (public key script) to ensure it is a "standard" public key script.
 // A standard public key script is one that is a recognized form, and for
@@ -275,19 +317,9 @@ func IsDust(txOut *wire.TxOut, minRelayTxFee btcutil.Amount) bool {
 	return txOut.Value*1000/GetDustThreshold(txOut) < int64(minRelayTxFee)
 }
 
-// checkTransactionStandard performs a series of checks on a transaction to
-// ensure it is a "standard" transaction.  A standard transaction is one that
-// conforms to several additional limiting cases over what is considered a
-// "sane" transaction such as having a version in the supported range, being
-// finalized, conforming to more stringent size constraints, having scripts
-// of recognized forms, and not containing "dust" outputs (those that are
-// so small it costs more to process them than they are worth).
-func checkTransactionStandard(tx *btcutil.Tx, height int32,
-	medianTimePast time.Time, minRelayTxFee btcutil.Amount,
-	maxTxVersion int32) error {
-
-	// The transaction must be a currently supported version.
-	msgTx := tx.MsgTx()
+// checkTxVersion ensures the passed transaction is a currently supported
+// version.
+func checkTxVersion(msgTx *wire.MsgTx, maxTxVersion int32) error {
 	if msgTx.Version > maxTxVersion || msgTx.Version < 1 {
 		str := fmt.Sprintf("transaction version %d is not in the "+
 			"valid range of %d-%d", msgTx.Version, 1,
@@ -295,13 +327,25 @@ func checkTransactionStandard(tx *btcutil.Tx, height int32,
 		return txRuleError(wire.RejectNonstandard, str)
 	}
 
-	// The transaction must be finalized to be standard and therefore
-	// considered for inclusion in a block.
+	return nil
+}
+
+// checkTxFinalized ensures the passed transaction is finalized and therefore
+// may be included in a block at the passed height.
+func checkTxFinalized(tx *btcutil.Tx, height int32,
+	medianTimePast time.Time) error {
+
 	if !blockchain.IsFinalizedTransaction(tx, height, medianTimePast) {
 		return txRuleError(wire.RejectNonstandard,
 			"transaction is not finalized")
 	}
 
+	return nil
+}
+
+// checkTxWeight ensures the weight of the passed transaction does not exceed
+// maxStandardTxWeight.
+func checkTxWeight(tx *btcutil.Tx) error {
 	// Since extremely large transactions with a lot of inputs can cost
 	// almost as much to process as the sender fees, limit the maximum
 	// size of a transaction.  This also helps mitigate CPU exhaustion
@@ -313,25 +357,116 @@ func checkTransactionStandard(tx *btcutil.Tx, height int32,
 		return txRuleError(wire.RejectNonstandard, str)
 	}
 
-	for i, txIn := range msgTx.TxIn {
-		// Each transaction input signature script must not exceed the
-		// maximum size allowed for a standard transaction.  See
-		// the comment on maxStandardSigScriptSize for more details.
-		sigScriptLen := len(txIn.SignatureScript)
-		if sigScriptLen > maxStandardSigScriptSize {
-			str := fmt.Sprintf("transaction input %d: signature "+
-				"script size of %d bytes is large than max "+
-				"allowed size of %d bytes", i, sigScriptLen,
-				maxStandardSigScriptSize)
-			return txRuleError(wire.RejectNonstandard, str)
+	return nil
+}
+
+// checkSigScriptSize ensures the signature script of the passed transaction
+// input does not exceed the maximum size allowed for a standard transaction.
+// See the comment on maxStandardSigScriptSize for more details.
+func checkSigScriptSize(i int, txIn *wire.TxIn) error {
+	sigScriptLen := len(txIn.SignatureScript)
+	if sigScriptLen > maxStandardSigScriptSize {
+		str := fmt.Sprintf("transaction input %d: signature "+
+			"script size of %d bytes is large than max "+
+			"allowed size of %d bytes", i, sigScriptLen,
+			maxStandardSigScriptSize)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	return nil
+}
+
+// checkSigScriptPushOnly ensures the signature script of the passed
+// transaction input only contains opcodes which push data onto the stack.
+func checkSigScriptPushOnly(i int, txIn *wire.TxIn) error {
+	if !txscript.IsPushOnlyScript(txIn.SignatureScript) {
+		str := fmt.Sprintf("transaction input %d: signature "+
+			"script is not push only", i)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	return nil
+}
+
+// checkOutputScriptStandard ensures the public key script of the passed
+// transaction output, which is of the passed class, is a standard one.
+func checkOutputScriptStandard(i int, txOut *wire.TxOut,
+	scriptClass txscript.ScriptClass) error {
+
+	err := checkPkScriptStandard(txOut.PkScript, scriptClass)
+	if err != nil {
+		// Attempt to extract a reject code from the error so
+		// it can be retained.  When not possible, fall back to
+		// a non standard error.
+		rejectCode := wire.RejectNonstandard
+		if rejCode, found := extractRejectCode(err); found {
+			rejectCode = rejCode
 		}
+		str := fmt.Sprintf("transaction output %d: %v", i, err)
+		return txRuleError(rejectCode, str)
+	}
 
-		// Each transaction input signature script must only contain
-		// opcodes which push data onto the stack.
-		if !txscript.IsPushOnlyScript(txIn.SignatureScript) {
-			str := fmt.Sprintf("transaction input %d: signature "+
-				"script is not push only", i)
-			return txRuleError(wire.RejectNonstandard, str)
+	return nil
+}
+
+// checkOutputDust ensures the passed transaction output is not "dust" based
+// on the passed minimum transaction relay fee.
+func checkOutputDust(i int, txOut *wire.TxOut,
+	minRelayTxFee btcutil.Amount) error {
+
+	if IsDust(txOut, minRelayTxFee) {
+		str := fmt.Sprintf("transaction output %d: payment "+
+			"of %d is dust", i, txOut.Value)
+		return txRuleError(wire.RejectDust, str)
+	}
+
+	return nil
+}
+
+// checkNullDataOutputs ensures a transaction does not have more than one
+// output script that only carries data.
+func checkNullDataOutputs(numNullDataOutputs int) error {
+	if numNullDataOutputs > 1 {
+		str := "more than one transaction output in a nulldata script"
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	return nil
+}
+
+// checkTransactionStandard performs a series of checks on a transaction to
+// ensure it is a "standard" transaction.  A standard transaction is one that
+// conforms to several additional limiting cases over what is considered a
+// "sane" transaction such as having a version in the supported range, being
+// finalized, conforming to more stringent size constraints, having scripts
+// of recognized forms, and not containing "dust" outputs (those that are
+// so small it costs more to process them than they are worth).
+func checkTransactionStandard(tx *btcutil.Tx, height int32,
+	medianTimePast time.Time, minRelayTxFee btcutil.Amount,
+	maxTxVersion int32) error {
+
+	// The transaction must be a currently supported version.
+	msgTx := tx.MsgTx()
+	if err := checkTxVersion(msgTx, maxTxVersion); err != nil {
+		return err
+	}
+
+	// The transaction must be finalized to be standard and therefore
+	// considered for inclusion in a block.
+	if err := checkTxFinalized(tx, height, medianTimePast); err != nil {
+		return err
+	}
+
+	if err := checkTxWeight(tx); err != nil {
+		return err
+	}
+
+	for i, txIn := range msgTx.TxIn {
+		if err := checkSigScriptSize(i, txIn); err != nil {
+			return err
+		}
+		if err := checkSigScriptPushOnly(i, txIn); err != nil {
+			return err
 		}
 	}
 
@@ -340,17 +475,9 @@ func checkTransactionStandard(tx *btcutil.Tx, height int32,
 	numNullDataOutputs := 0
 	for i, txOut := range msgTx.TxOut {
 		scriptClass := txscript.GetScriptClass(txOut.PkScript)
-		err := checkPkScriptStandard(txOut.PkScript, scriptClass)
+		err := checkOutputScriptStandard(i, txOut, scriptClass)
 		if err != nil {
-			// Attempt to extract a reject code from the error so
-			// it can be retained.  When not possible, fall back to
-			// a non standard error.
-			rejectCode := wire.RejectNonstandard
-			if rejCode, found := extractRejectCode(err); found {
-				rejectCode = rejCode
-			}
-			str := fmt.Sprintf("transaction output %d: %v", i, err)
-			return txRuleError(rejectCode, str)
+			return err
 		}
 
 		// Accumulate the number of outputs which only carry data.  For
@@ -358,21 +485,14 @@ func checkTransactionStandard(tx *btcutil.Tx, height int32,
 		// "dust".
 		if scriptClass == txscript.NullDataTy {
 			numNullDataOutputs++
-		} else if IsDust(txOut, minRelayTxFee) {
-			str := fmt.Sprintf("transaction output %d: payment "+
-				"of %d is dust", i, txOut.Value)
-			return txRuleError(wire.RejectDust, str)
+		} else if err := checkOutputDust(i, txOut, minRelayTxFee); err != nil {
+			return err
 		}
 	}
 
 	// A standard transaction must not have more than one output script that
 	// only carries data.
-	if numNullDataOutputs > 1 {
-		str := "more than one transaction output in a nulldata script"
-		return txRuleError(wire.RejectNonstandard, str)
-	}
-
-	return nil
+	return checkNullDataOutputs(numNullDataOutputs)
 }
 
 // GetTxVirtualSize computes the virtual size of a given transaction. A
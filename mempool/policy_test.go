@@ -6,9 +6,11 @@ package mempool
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -510,3 +512,172 @@ func TestCheckTransactionStandard(t *testing.T) {
 		}
 	}
 }
+
+// TestExplainTransactionStandard tests that the explainTransactionStandard API
+// reports every violated policy rule of a transaction.
+func TestExplainTransactionStandard(t *testing.T) {
+	addrHash := [20]byte{0x01}
+	addr, err := btcutil.NewAddressPubKeyHash(addrHash[:],
+		&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+
+	// Create a transaction with a pay-to-pubkey-hash output, a
+	// pay-to-script-hash output whose redeem script has too many signature
+	// operations, and a non-standard output to be spent by the tests.
+	redeemScript := bytes.Repeat([]byte{txscript.OP_CHECKSIG},
+		maxStandardP2SHSigOps+1)
+	scriptAddr, err := btcutil.NewAddressScriptHash(redeemScript,
+		&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHash: unexpected error: %v", err)
+	}
+	p2shScript, err := txscript.PayToAddrScript(scriptAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+	redeemSigScript, err := txscript.NewScriptBuilder().
+		AddData(redeemScript).Script()
+	if err != nil {
+		t.Fatalf("NewScriptBuilder: unexpected error: %v", err)
+	}
+	prevTx := wire.NewMsgTx(1)
+	prevTx.AddTxIn(&wire.TxIn{Sequence: wire.MaxTxInSequenceNum})
+	prevTx.AddTxOut(wire.NewTxOut(100000000, pkScript))
+	prevTx.AddTxOut(wire.NewTxOut(100000000, p2shScript))
+	prevTx.AddTxOut(wire.NewTxOut(100000000, []byte{txscript.OP_TRUE}))
+	prevHash := prevTx.TxHash()
+	utxoView := blockchain.NewUtxoViewpoint()
+	utxoView.AddTxOuts(btcutil.NewTx(prevTx), 100)
+
+	dummySigScript := bytes.Repeat([]byte{0x00}, 65)
+	newTxIn := func(index uint32, sigScript []byte) *wire.TxIn {
+		return &wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Hash: prevHash, Index: index},
+			SignatureScript:  sigScript,
+			Sequence:         wire.MaxTxInSequenceNum,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		tx         wire.MsgTx
+		isStandard bool
+		txRules    []StandardnessRule
+		inRules    [][]StandardnessRule
+		missing    []bool
+		outRules   [][]StandardnessRule
+	}{
+		{
+			name: "Standard transaction",
+			tx: wire.MsgTx{
+				Version: 1,
+				TxIn:    []*wire.TxIn{newTxIn(0, dummySigScript)},
+				TxOut: []*wire.TxOut{
+					wire.NewTxOut(100000000, pkScript),
+				},
+			},
+			isStandard: true,
+			txRules:    []StandardnessRule{},
+			inRules:    [][]StandardnessRule{{}},
+			missing:    []bool{false},
+			outRules:   [][]StandardnessRule{{}},
+		},
+		{
+			name: "Transaction violating several rules",
+			tx: wire.MsgTx{
+				Version: 2,
+				TxIn: []*wire.TxIn{
+					newTxIn(0, []byte{txscript.OP_CHECKSIGVERIFY}),
+					newTxIn(1, redeemSigScript),
+					newTxIn(2, dummySigScript),
+					newTxIn(3, dummySigScript),
+				},
+				TxOut: []*wire.TxOut{
+					wire.NewTxOut(0, pkScript),
+					wire.NewTxOut(100000000,
+						[]byte{txscript.OP_TRUE}),
+					wire.NewTxOut(0, []byte{txscript.OP_RETURN}),
+					wire.NewTxOut(0, []byte{txscript.OP_RETURN}),
+				},
+			},
+			isStandard: false,
+			txRules:    []StandardnessRule{RuleVersion, RuleNullData},
+			inRules: [][]StandardnessRule{
+				{RuleSigScriptPushOnly},
+				{RuleP2SHSigOps},
+				{RulePrevScriptClass},
+				{},
+			},
+			missing: []bool{false, false, false, true},
+			outRules: [][]StandardnessRule{
+				{RuleDust},
+				{RuleScriptClass},
+				{},
+				{},
+			},
+		},
+	}
+
+	// failedRules returns the rules which were not satisfied.
+	failedRules := func(results []RuleResult) []StandardnessRule {
+		failed := make([]StandardnessRule, 0)
+		for _, result := range results {
+			if !result.Passed {
+				failed = append(failed, result.Rule)
+			}
+		}
+		return failed
+	}
+
+	policy := Policy{
+		MaxTxVersion:      1,
+		MaxSigOpCostPerTx: blockchain.MaxBlockSigOpsCost / 4,
+		MinRelayTxFee:     DefaultMinRelayTxFee,
+	}
+	for _, test := range tests {
+		report := explainTransactionStandard(btcutil.NewTx(&test.tx),
+			utxoView, 300000, time.Now(), &policy)
+		if report.IsStandard() != test.isStandard {
+			t.Errorf("explainTransactionStandard (%s): unexpected "+
+				"standardness - got %v, want %v", test.name,
+				report.IsStandard(), test.isStandard)
+		}
+
+		got := failedRules(report.Results)
+		if !reflect.DeepEqual(got, test.txRules) {
+			t.Errorf("explainTransactionStandard (%s): unexpected "+
+				"transaction violations - got %v, want %v",
+				test.name, got, test.txRules)
+		}
+		for i, input := range report.Inputs {
+			got := failedRules(input.Results)
+			if !reflect.DeepEqual(got, test.inRules[i]) {
+				t.Errorf("explainTransactionStandard (%s): "+
+					"unexpected input %d violations - got %v, "+
+					"want %v", test.name, i, got,
+					test.inRules[i])
+			}
+			if input.PrevOutMissing != test.missing[i] {
+				t.Errorf("explainTransactionStandard (%s): "+
+					"unexpected missing input %d - got %v, "+
+					"want %v", test.name, i,
+					input.PrevOutMissing, test.missing[i])
+			}
+		}
+		for i, output := range report.Outputs {
+			got := failedRules(output.Results)
+			if !reflect.DeepEqual(got, test.outRules[i]) {
+				t.Errorf("explainTransactionStandard (%s): "+
+					"unexpected output %d violations - got "+
+					"%v, want %v", test.name, i, got,
+					test.outRules[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// StandardnessRule identifies one of the policy rules a transaction must
+// satisfy in order to be considered standard.
+type StandardnessRule string
+
+// These constants define the policy rules which are evaluated when explaining
+// the standardness of a transaction.
+const (
+	// RuleVersion requires the transaction version to be in the range
+	// allowed by the policy.
+	RuleVersion StandardnessRule = "version"
+
+	// RuleFinalized requires the transaction to be finalized as of the
+	// next block.
+	RuleFinalized StandardnessRule = "finalized"
+
+	// RuleWeight requires the transaction weight to not exceed the maximum
+	// standard weight.
+	RuleWeight StandardnessRule = "weight"
+
+	// RuleSigOpCost requires the cumulative signature operation cost of the
+	// transaction to not exceed the maximum allowed by the policy.
+	RuleSigOpCost StandardnessRule = "sigopcost"
+
+	// RuleNullData requires the transaction to have at most one output
+	// which only carries data.
+	RuleNullData StandardnessRule = "nulldata"
+
+	// RuleSigScriptSize requires the signature script of an input to not
+	// exceed the maximum standard size.
+	RuleSigScriptSize StandardnessRule = "sigscriptsize"
+
+	// RuleSigScriptPushOnly requires the signature script of an input to
+	// only push data.
+	RuleSigScriptPushOnly StandardnessRule = "sigscriptpushonly"
+
+	// RulePrevScriptClass requires the public key script spent by an input
+	// to be of a standard form.
+	RulePrevScriptClass StandardnessRule = "prevscriptclass"
+
+	// RuleP2SHSigOps requires an input spending a pay-to-script-hash output
+	// to not have more than the maximum standard number of signature
+	// operations.
+	RuleP2SHSigOps StandardnessRule = "p2shsigops"
+
+	// RuleScriptClass requires the public key script of an output to be of
+	// a standard form.
+	RuleScriptClass StandardnessRule = "scriptclass"
+
+	// RuleDust requires the value of an output which does not only carry
+	// data to not be dust.
+	RuleDust StandardnessRule = "dust"
+)
+
+// RuleResult is the outcome of evaluating a single policy rule against a
+// transaction.
+type RuleResult struct {
+	// Rule is the evaluated policy rule.
+	Rule StandardnessRule
+
+	// Passed is whether the transaction satisfies the rule.
+	Passed bool
+
+	// RejectCode and Reason are the code the transaction would be
+	// rejected with and a description of the violation.  They are only
+	// set when the rule is not satisfied.
+	RejectCode wire.RejectCode
+	Reason     string
+}
+
+// newRuleResult returns the result of evaluating the passed rule given the
+// error returned by its check.
+func newRuleResult(rule StandardnessRule, err error) RuleResult {
+	if err == nil {
+		return RuleResult{Rule: rule, Passed: true}
+	}
+
+	rejectCode, found := extractRejectCode(err)
+	if !found {
+		rejectCode = wire.RejectNonstandard
+	}
+	return RuleResult{
+		Rule:       rule,
+		RejectCode: rejectCode,
+		Reason:     err.Error(),
+	}
+}
+
+// InputStandardness houses the results of the policy rules evaluated against a
+// transaction input.
+type InputStandardness struct {
+	// PrevOutMissing is set when the output spent by the input is unknown,
+	// in which case the rules depending on it are not evaluated.
+	PrevOutMissing bool
+
+	// Results are the results of the evaluated rules.
+	Results []RuleResult
+}
+
+// OutputStandardness houses the results of the policy rules evaluated against a
+// transaction output.
+type OutputStandardness struct {
+	// Results are the results of the evaluated rules.
+	Results []RuleResult
+}
+
+// StandardnessReport houses the results of evaluating every policy rule
+// against a transaction.  Unlike the checks performed when accepting a
+// transaction, which stop at the first violation, it contains the result of
+// every rule which could be evaluated.
+type StandardnessReport struct {
+	// Results are the results of the rules which apply to the transaction
+	// as a whole.
+	Results []RuleResult
+
+	// Inputs and Outputs are the results of the rules which apply to the
+	// inputs and outputs of the transaction, in the same order as the
+	// inputs and outputs themselves.
+	Inputs  []InputStandardness
+	Outputs []OutputStandardness
+}
+
+// IsStandard returns whether the transaction satisfies all of the evaluated
+// policy rules.
+func (r *StandardnessReport) IsStandard() bool {
+	passed := func(results []RuleResult) bool {
+		for _, result := range results {
+			if !result.Passed {
+				return false
+			}
+		}
+		return true
+	}
+
+	if !passed(r.Results) {
+		return false
+	}
+	for _, input := range r.Inputs {
+		if !passed(input.Results) {
+			return false
+		}
+	}
+	for _, output := range r.Outputs {
+		if !passed(output.Results) {
+			return false
+		}
+	}
+	return true
+}
+
+// explainTransactionStandard evaluates every policy rule which determines
+// whether the passed transaction is standard for inclusion in a block at the
+// passed height, using the passed policy and the outputs spent by the
+// transaction in the passed view.  Rules depending on outputs which are not
+// in the view are not evaluated.
+func explainTransactionStandard(tx *btcutil.Tx,
+	utxoView *blockchain.UtxoViewpoint, height int32,
+	medianTimePast time.Time, policy *Policy) *StandardnessReport {
+
+	msgTx := tx.MsgTx()
+	report := &StandardnessReport{
+		Results: []RuleResult{
+			newRuleResult(RuleVersion,
+				checkTxVersion(msgTx, policy.MaxTxVersion)),
+			newRuleResult(RuleFinalized,
+				checkTxFinalized(tx, height, medianTimePast)),
+			newRuleResult(RuleWeight, checkTxWeight(tx)),
+		},
+		Inputs:  make([]InputStandardness, len(msgTx.TxIn)),
+		Outputs: make([]OutputStandardness, len(msgTx.TxOut)),
+	}
+
+	allPrevOuts := true
+	for i, txIn := range msgTx.TxIn {
+		input := &report.Inputs[i]
+		input.Results = []RuleResult{
+			newRuleResult(RuleSigScriptSize,
+				checkSigScriptSize(i, txIn)),
+			newRuleResult(RuleSigScriptPushOnly,
+				checkSigScriptPushOnly(i, txIn)),
+		}
+
+		entry := utxoView.LookupEntry(txIn.PreviousOutPoint)
+		if entry == nil || entry.IsSpent() {
+			input.PrevOutMissing = true
+			allPrevOuts = false
+			continue
+		}
+		originPkScript := entry.PkScript()
+		scriptClass := txscript.GetScriptClass(originPkScript)
+		input.Results = append(input.Results, newRuleResult(
+			RulePrevScriptClass, checkPrevScriptStandard(i, scriptClass)))
+		if scriptClass == txscript.ScriptHashTy {
+			input.Results = append(input.Results, newRuleResult(
+				RuleP2SHSigOps, checkP2SHSigOps(i, txIn,
+					originPkScript)))
+		}
+	}
+
+	numNullDataOutputs := 0
+	for i, txOut := range msgTx.TxOut {
+		output := &report.Outputs[i]
+		scriptClass := txscript.GetScriptClass(txOut.PkScript)
+		output.Results = []RuleResult{
+			newRuleResult(RuleScriptClass,
+				checkOutputScriptStandard(i, txOut, scriptClass)),
+		}
+		if scriptClass == txscript.NullDataTy {
+			numNullDataOutputs++
+			continue
+		}
+		output.Results = append(output.Results, newRuleResult(RuleDust,
+			checkOutputDust(i, txOut, policy.MinRelayTxFee)))
+	}
+	report.Results = append(report.Results, newRuleResult(RuleNullData,
+		checkNullDataOutputs(numNullDataOutputs)))
+
+	// The signature operation cost can only be calculated when all of the
+	// spent outputs are known.
+	if allPrevOuts {
+		report.Results = append(report.Results, newRuleResult(
+			RuleSigOpCost, checkTxSigOpCost(tx, utxoView,
+				policy.MaxSigOpCostPerTx)))
+	}
+
+	return report
+}
@@ -219,6 +219,7 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 	// Create some convenience variables.
 	header := &msgBlock.Header
 	targetDifficulty := blockchain.CompactToBig(header.Bits)
+	powAlgo := m.cfg.ChainParams.PowAlgorithmAt(blockHeight)
 
 	// Initial state.
 	lastGenerated := time.Now()
@@ -274,7 +275,13 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 			// increment the number of hashes completed for each
 			// attempt accordingly.
 			header.Nonce = i
-			hash := header.PowHash()
+			hash, err := header.PowHash(powAlgo)
+			if err != nil {
+				log.Errorf("Unable to calculate %v proof-of-work "+
+					"hash for block at height %d: %v", powAlgo,
+					blockHeight, err)
+				return false
+			}
 			hashesCompleted += 2
 
 			// The block is solved when the new block hash is less
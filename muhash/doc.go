@@ -0,0 +1,32 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package muhash implements MuHash3072, a rolling hash of a set of byte strings.
+
+MuHash Overview
+
+MuHash maps every element of a set to a number modulo the 3072-bit prime
+2^3072 - 1103717 and multiplies them together.  Since multiplication is
+commutative, the hash does not depend on the order in which elements are added,
+and since every number has an inverse, an element is removed by dividing by it.
+This makes it possible to keep the hash of a large set, such as the unspent
+transaction output set, up to date as elements are added and removed without
+ever hashing the whole set again.
+
+Elements are mapped to numbers by hashing them with SHA256 and expanding the
+digest with ChaCha20 into 384 bytes which are read as a little-endian number.
+The final hash is the SHA256 of the 384 byte little-endian serialization of the
+product.  This matches the MuHash3072 implementation in Bitcoin Core, so the
+resulting hashes can be compared directly with those it reports.
+
+Usage
+
+	h := muhash.New()
+	h.Add([]byte("a"))
+	h.Add([]byte("b"))
+	h.Remove([]byte("a"))
+	hash := h.Finalize()
+*/
+package muhash
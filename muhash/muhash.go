@@ -0,0 +1,160 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package muhash
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"golang.org/x/crypto/chacha20"
+)
+
+const (
+	// ElementSize is the size in bytes of the little-endian serialization of
+	// the numbers elements are mapped to.
+	ElementSize = 384
+
+	// SerializedSize is the size in bytes of a serialized MuHash.
+	SerializedSize = ElementSize * 2
+
+	// primeDiff is the difference between 2^3072 and the prime modulus.
+	primeDiff = 1103717
+)
+
+// prime is the modulus 2^3072 - 1103717 all calculations are done with.
+var prime = func() *big.Int {
+	p := new(big.Int).Lsh(big.NewInt(1), ElementSize*8)
+	return p.Sub(p, big.NewInt(primeDiff))
+}()
+
+// MuHash is a rolling hash of a set.  It houses the product of the numbers of
+// all added elements and the product of the numbers of all removed elements
+// separately, so that no expensive modular inversion is needed until the final
+// hash is calculated.
+//
+// The zero value is not usable.  Use New to create an instance of the hash of
+// the empty set.
+type MuHash struct {
+	numerator   big.Int
+	denominator big.Int
+}
+
+// New returns a MuHash of the empty set.
+func New() *MuHash {
+	var h MuHash
+	h.numerator.SetInt64(1)
+	h.denominator.SetInt64(1)
+	return &h
+}
+
+// Copy returns a deep copy of the hash.
+func (h *MuHash) Copy() *MuHash {
+	var c MuHash
+	c.numerator.Set(&h.numerator)
+	c.denominator.Set(&h.denominator)
+	return &c
+}
+
+// reverse reverses the passed bytes in place to convert between big-endian and
+// little-endian.
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// elementNumber returns the number the passed element maps to.  It is the
+// little-endian interpretation of the 384 bytes of ChaCha20 keystream produced
+// with the SHA256 of the element as the key.
+func elementNumber(data []byte) *big.Int {
+	key := sha256.Sum256(data)
+	var nonce [chacha20.NonceSize]byte
+	cipher, err := chacha20.NewUnauthenticatedCipher(key[:], nonce[:])
+	if err != nil {
+		// The key and nonce sizes are constant, so this is impossible.
+		panic(err)
+	}
+	var keystream [ElementSize]byte
+	cipher.XORKeyStream(keystream[:], keystream[:])
+
+	reverse(keystream[:])
+	return new(big.Int).SetBytes(keystream[:])
+}
+
+// Add adds the passed element to the set.
+func (h *MuHash) Add(data []byte) {
+	h.numerator.Mul(&h.numerator, elementNumber(data))
+	h.numerator.Mod(&h.numerator, prime)
+}
+
+// Remove removes the passed element from the set.  The element is not required
+// to have been added before, in which case the hash only matches that of a set
+// once the element is added.
+func (h *MuHash) Remove(data []byte) {
+	h.denominator.Mul(&h.denominator, elementNumber(data))
+	h.denominator.Mod(&h.denominator, prime)
+}
+
+// Combine adds all elements added to the passed hash and removes all elements
+// removed from it.  That is the result is the hash of the union of both sets.
+func (h *MuHash) Combine(other *MuHash) {
+	h.numerator.Mul(&h.numerator, &other.numerator)
+	h.numerator.Mod(&h.numerator, prime)
+	h.denominator.Mul(&h.denominator, &other.denominator)
+	h.denominator.Mod(&h.denominator, prime)
+}
+
+// value returns the number which represents the set, that is the numerator
+// divided by the denominator modulo the prime.
+func (h *MuHash) value() *big.Int {
+	inverse := new(big.Int).ModInverse(&h.denominator, prime)
+	inverse.Mul(inverse, &h.numerator)
+	return inverse.Mod(inverse, prime)
+}
+
+// Finalize returns the hash of the set.  It is the SHA256 of the little-endian
+// serialization of the number which represents the set.
+func (h *MuHash) Finalize() chainhash.Hash {
+	var data [ElementSize]byte
+	h.value().FillBytes(data[:])
+	reverse(data[:])
+	return chainhash.Hash(sha256.Sum256(data[:]))
+}
+
+// Serialize returns the serialization of the hash which can be deserialized
+// with Deserialize.  It consists of the numerator followed by the denominator,
+// both as 384 byte little-endian numbers.
+func (h *MuHash) Serialize() []byte {
+	serialized := make([]byte, SerializedSize)
+	h.numerator.FillBytes(serialized[:ElementSize])
+	reverse(serialized[:ElementSize])
+	h.denominator.FillBytes(serialized[ElementSize:])
+	reverse(serialized[ElementSize:])
+	return serialized
+}
+
+// Deserialize returns the hash which was serialized with Serialize.
+func Deserialize(serialized []byte) (*MuHash, error) {
+	if len(serialized) != SerializedSize {
+		return nil, errors.New("serialized muhash has an invalid length")
+	}
+
+	var h MuHash
+	number := make([]byte, ElementSize)
+	copy(number, serialized[:ElementSize])
+	reverse(number)
+	h.numerator.SetBytes(number)
+	copy(number, serialized[ElementSize:])
+	reverse(number)
+	h.denominator.SetBytes(number)
+	if h.numerator.Sign() == 0 || h.numerator.Cmp(prime) >= 0 ||
+		h.denominator.Sign() == 0 || h.denominator.Cmp(prime) >= 0 {
+
+		return nil, errors.New("serialized muhash is out of range")
+	}
+	return &h, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package muhash
+
+import (
+	"bytes"
+	"testing"
+)
+
+// fromInt returns the hash of a set containing the 32 byte element which starts
+// with the passed byte followed by zeros.
+func fromInt(i byte) *MuHash {
+	var element [32]byte
+	element[0] = i
+	h := New()
+	h.Add(element[:])
+	return h
+}
+
+// TestMuHash ensures the hash matches the reference implementation and that it
+// is independent of the order elements are added and removed in.
+func TestMuHash(t *testing.T) {
+	// {0} * {1} / {2} from the Bitcoin Core test suite.
+	h := fromInt(0)
+	h.Combine(fromInt(1))
+	var two [32]byte
+	two[0] = 2
+	h.Remove(two[:])
+	const want = "10d312b100cbd32ada024a6646e40d3482fcff103668d2625f10002a607d5863"
+	if got := h.Finalize(); got.String() != want {
+		t.Fatalf("Finalize: unexpected hash %v, want %s", got, want)
+	}
+
+	// The hash does not depend on the order of the elements.
+	elements := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	forward, backward := New(), New()
+	for i := range elements {
+		forward.Add(elements[i])
+		backward.Add(elements[len(elements)-1-i])
+	}
+	if forward.Finalize() != backward.Finalize() {
+		t.Fatal("Finalize: hash depends on the order of the elements")
+	}
+
+	// Removing the elements again results in the hash of the empty set,
+	// even when they are removed before being added.
+	forward.Remove(elements[1])
+	forward.Remove(elements[0])
+	forward.Remove(elements[2])
+	if forward.Finalize() != New().Finalize() {
+		t.Fatal("Finalize: removing all elements is not the empty set")
+	}
+	removed := New()
+	removed.Remove(elements[0])
+	removed.Add(elements[0])
+	if removed.Finalize() != New().Finalize() {
+		t.Fatal("Finalize: removing an element before adding it is " +
+			"not the empty set")
+	}
+}
+
+// TestMuHashSerialize ensures a hash survives a serialization round trip and
+// that invalid serializations are rejected.
+func TestMuHashSerialize(t *testing.T) {
+	h := fromInt(1)
+	h.Remove([]byte("removed"))
+	serialized := h.Serialize()
+	if len(serialized) != SerializedSize {
+		t.Fatalf("Serialize: unexpected length %d", len(serialized))
+	}
+	d, err := Deserialize(serialized)
+	if err != nil {
+		t.Fatalf("Deserialize: unexpected error: %v", err)
+	}
+	if d.Finalize() != h.Finalize() {
+		t.Fatal("Deserialize: hash does not match")
+	}
+	if !bytes.Equal(d.Serialize(), serialized) {
+		t.Fatal("Deserialize: serialization does not round trip")
+	}
+
+	if _, err := Deserialize(serialized[1:]); err == nil {
+		t.Fatal("Deserialize: accepted a short serialization")
+	}
+	if _, err := Deserialize(make([]byte, SerializedSize)); err == nil {
+		t.Fatal("Deserialize: accepted a zero numerator")
+	}
+}
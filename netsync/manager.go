@@ -6,8 +6,13 @@ package netsync
 
 import (
 	"container/list"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"math/rand"
 	"net"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -47,6 +52,15 @@ const (
 	// stallSampleInterval the interval at which we will check to see if our
 	// sync has stalled.
 	stallSampleInterval = 30 * time.Second
+
+	// unknownAssumeValidHeight is the height used for the checkpoint of the
+	// assumed valid block until its header has been received.
+	unknownAssumeValidHeight = math.MaxInt32
+
+	// maxHeaderPowRetries is the maximum number of times in a row the
+	// headers whose proof of work could not be checked are requested again
+	// from a peer before giving up on it.
+	maxHeaderPowRetries = 3
 )
 
 // zeroHash is the zero value hash (all zeros).  It is defined as a convenience.
@@ -148,10 +162,11 @@ type headerNode struct {
 // peerSyncState stores additional information that the SyncManager tracks
 // about a peer.
 type peerSyncState struct {
-	syncCandidate   bool
-	requestQueue    []*wire.InvVect
-	requestedTxns   map[chainhash.Hash]struct{}
-	requestedBlocks map[chainhash.Hash]struct{}
+	syncCandidate    bool
+	headerPowRetries int
+	requestQueue     []*wire.InvVect
+	requestedTxns    map[chainhash.Hash]struct{}
+	requestedBlocks  map[chainhash.Hash]struct{}
 }
 
 // limitAdd is a helper function for maps that require a maximum limit by
@@ -190,6 +205,10 @@ type SyncManager struct {
 	wg             sync.WaitGroup
 	quit           chan struct{}
 
+	// requestProcessShutdown is sent to when the chain can't continue
+	// and the process must be stopped.
+	requestProcessShutdown chan struct{}
+
 	// These fields should only be accessed from the blockHandler thread
 	rejectedTxns     map[chainhash.Hash]struct{}
 	requestedTxns    map[chainhash.Hash]struct{}
@@ -204,6 +223,19 @@ type SyncManager struct {
 	startHeader      *list.Element
 	nextCheckpoint   *chaincfg.Checkpoint
 
+	// The following fields are used to download the headers leading to the
+	// assumed valid block in headers-first mode once there are no more
+	// checkpoints, followed by the headers after it up to the point where
+	// it is buried deeply enough.  Blocks for the headers are only flagged
+	// as ancestors of the assumed valid block when the headers have enough
+	// work and bury it deeply enough.
+	assumeValidCheckpoint *chaincfg.Checkpoint
+	assumeValidWork       bool
+	headersWork           *big.Int
+	burialHeader          *headerNode
+	burialWork            *big.Int
+	burialBits            uint32
+
 	// An optional fee estimator.
 	feeEstimator *mempool.FeeEstimator
 }
@@ -214,6 +246,11 @@ func (sm *SyncManager) resetHeaderState(newestHash *chainhash.Hash, newestHeight
 	sm.headersFirstMode = false
 	sm.headerList.Init()
 	sm.startHeader = nil
+	sm.assumeValidWork = false
+	sm.headersWork.SetInt64(0)
+	sm.burialHeader = nil
+	sm.burialWork.SetInt64(0)
+	sm.burialBits = 0
 
 	// When there is a next checkpoint, add an entry for the latest known
 	// block into the header pool.  This allows the next downloaded header
@@ -228,17 +265,20 @@ func (sm *SyncManager) resetHeaderState(newestHash *chainhash.Hash, newestHeight
 // It returns nil when there is not one either because the height is already
 // later than the final checkpoint or some other reason such as disabled
 // checkpoints.
+//
+// The assumed valid block is treated as the checkpoint after the final one so
+// the headers leading to it are downloaded as well.
 func (sm *SyncManager) findNextHeaderCheckpoint(height int32) *chaincfg.Checkpoint {
 	checkpoints := sm.chain.Checkpoints()
 	if len(checkpoints) == 0 {
-		return nil
+		return sm.findAssumeValidCheckpoint(height)
 	}
 
 	// There is no next checkpoint if the height is already after the final
 	// checkpoint.
 	finalCheckpoint := &checkpoints[len(checkpoints)-1]
 	if height >= finalCheckpoint.Height {
-		return nil
+		return sm.findAssumeValidCheckpoint(height)
 	}
 
 	// Find the next checkpoint.
@@ -252,6 +292,58 @@ func (sm *SyncManager) findNextHeaderCheckpoint(height int32) *chaincfg.Checkpoi
 	return nextCheckpoint
 }
 
+// findAssumeValidCheckpoint returns the checkpoint for the assumed valid block
+// when its headers still need to be downloaded after the passed height.  The
+// height of the checkpoint is not known until the header of the block has been
+// received.  It returns nil when there is no assumed valid block or the block
+// is already known.
+func (sm *SyncManager) findAssumeValidCheckpoint(height int32) *chaincfg.Checkpoint {
+	checkpoint := sm.assumeValidCheckpoint
+	if checkpoint == nil || height >= checkpoint.Height {
+		return nil
+	}
+
+	exists, err := sm.chain.HaveBlock(checkpoint.Hash)
+	if err != nil {
+		log.Warnf("Unable to check for assumed valid block %v: %v",
+			checkpoint.Hash, err)
+		return nil
+	}
+	if exists {
+		sm.assumeValidCheckpoint = nil
+		return nil
+	}
+	return checkpoint
+}
+
+// abandonAssumeValid stops downloading the headers leading to the assumed valid
+// block because the sync peer does not know of it and switches to normal mode
+// by requesting the blocks after the current best block from the peer.  The
+// scripts of all blocks are validated from then on.
+func (sm *SyncManager) abandonAssumeValid(peer *peerpkg.Peer) {
+	log.Warnf("Peer %s does not know of assumed valid block %v -- "+
+		"validating all scripts", peer.Addr(),
+		sm.assumeValidCheckpoint.Hash)
+
+	sm.assumeValidCheckpoint = nil
+	sm.nextCheckpoint = nil
+	sm.headersFirstMode = false
+	sm.headerList.Init()
+	sm.startHeader = nil
+
+	locator, err := sm.chain.LatestBlockLocator()
+	if err != nil {
+		log.Errorf("Failed to get block locator for the latest block: "+
+			"%v", err)
+		return
+	}
+	err = peer.PushGetBlocksMsg(locator, &zeroHash)
+	if err != nil {
+		log.Warnf("Failed to send getblocks message to peer %s: %v",
+			peer.Addr(), err)
+	}
+}
+
 // startSync will choose the best peer among the available candidate peers to
 // download/sync the blockchain from.  When syncing is already running, it
 // simply returns.  It also examines the candidates for any which are no longer
@@ -363,9 +455,7 @@ func (sm *SyncManager) startSync() {
 
 			bestPeer.PushGetHeadersMsg(locator, sm.nextCheckpoint.Hash)
 			sm.headersFirstMode = true
-			log.Infof("Downloading headers for blocks %d to "+
-				"%d from peer %s", best.Height+1,
-				sm.nextCheckpoint.Height, bestPeer.Addr())
+			sm.logHeadersDownload(best.Height+1, bestPeer)
 		} else {
 			bestPeer.PushGetBlocksMsg(locator, &zeroHash)
 		}
@@ -380,6 +470,19 @@ func (sm *SyncManager) startSync() {
 	}
 }
 
+// logHeadersDownload logs that the headers starting at the passed height up to
+// the next checkpoint are being downloaded from the passed peer.
+func (sm *SyncManager) logHeadersDownload(startHeight int32, peer *peerpkg.Peer) {
+	if sm.nextCheckpoint.Height == unknownAssumeValidHeight {
+		log.Infof("Downloading headers for blocks %d to assumed valid "+
+			"block %v from peer %s", startHeight,
+			sm.nextCheckpoint.Hash, peer.Addr())
+		return
+	}
+	log.Infof("Downloading headers for blocks %d to %d from peer %s",
+		startHeight, sm.nextCheckpoint.Height, peer.Addr())
+}
+
 // isSyncCandidate returns whether or not the peer is a candidate to consider
 // syncing from.
 func (sm *SyncManager) isSyncCandidate(peer *peerpkg.Peer) bool {
@@ -679,9 +782,10 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	// first header in the list of headers that are being fetched, it's
 	// eligible for less validation since the headers have already been
 	// verified to link together and are valid up to the next checkpoint.
-	// Also, remove the list entry for all blocks except the checkpoint
-	// since it is needed to verify the next round of headers links
-	// properly.
+	// Only the scripts are not validated when the next checkpoint is the
+	// assumed valid block and the headers have enough work.  Also, remove
+	// the list entry for all blocks except the checkpoint since it is
+	// needed to verify the next round of headers links properly.
 	isCheckpointBlock := false
 	behaviorFlags := blockchain.BFNone
 	if sm.headersFirstMode {
@@ -689,7 +793,11 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		if firstNodeEl != nil {
 			firstNode := firstNodeEl.Value.(*headerNode)
 			if blockHash.IsEqual(firstNode.hash) {
-				behaviorFlags |= blockchain.BFFastAdd
+				if sm.nextCheckpoint != sm.assumeValidCheckpoint {
+					behaviorFlags |= blockchain.BFFastAdd
+				} else if sm.assumeValidWork {
+					behaviorFlags |= blockchain.BFAssumeValid
+				}
 				if firstNode.hash.IsEqual(sm.nextCheckpoint.Hash) {
 					isCheckpointBlock = true
 				} else {
@@ -724,6 +832,7 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 			database.ErrCorruption {
 			panic(dbErr)
 		}
+		sm.haltOnInvalidSnapshot(err)
 
 		// Convert the error into an appropriate reject message and
 		// send it.
@@ -805,8 +914,10 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		}
 	}
 
-	// Nothing more to do if we aren't in headers-first mode.
+	// Nothing more to do if we aren't in headers-first mode other than
+	// requesting more of the blocks below a loaded utxo snapshot.
 	if !sm.headersFirstMode {
+		sm.fetchSnapshotBlocks()
 		return
 	}
 
@@ -836,9 +947,8 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 				"peer %s: %v", peer.Addr(), err)
 			return
 		}
-		log.Infof("Downloading headers for blocks %d to %d from "+
-			"peer %s", prevHeight+1, sm.nextCheckpoint.Height,
-			sm.syncPeer.Addr())
+		sm.headersWork.SetInt64(0)
+		sm.logHeadersDownload(prevHeight+1, sm.syncPeer)
 		return
 	}
 
@@ -911,11 +1021,160 @@ func (sm *SyncManager) fetchHeaderBlocks() {
 	}
 }
 
+// fetchSnapshotBlocks requests the blocks below a loaded utxo snapshot from the
+// sync peer so they can be validated in the background.  They are only
+// requested once the chain is current and a few at a time to avoid slowing
+// down the sync of new blocks.
+func (sm *SyncManager) fetchSnapshotBlocks() {
+	if sm.syncPeer == nil || sm.headersFirstMode || !sm.current() {
+		return
+	}
+	syncPeerState, exists := sm.peerStates[sm.syncPeer]
+	if !exists || len(syncPeerState.requestedBlocks) >= minInFlightBlocks {
+		return
+	}
+
+	hashes := sm.chain.SnapshotBlocksNeeded(minInFlightBlocks * 2)
+	gdmsg := wire.NewMsgGetDataSizeHint(uint(len(hashes)))
+	for i := range hashes {
+		hash := &hashes[i]
+		if _, exists := sm.requestedBlocks[*hash]; exists {
+			continue
+		}
+
+		sm.requestedBlocks[*hash] = struct{}{}
+		syncPeerState.requestedBlocks[*hash] = struct{}{}
+
+		iv := wire.NewInvVect(wire.InvTypeBlock, hash)
+		if sm.syncPeer.IsWitnessEnabled() {
+			iv.Type = wire.InvTypeWitnessBlock
+		}
+		gdmsg.AddInvVect(iv)
+	}
+	if len(gdmsg.InvList) > 0 {
+		sm.syncPeer.QueueMessage(gdmsg, nil)
+	}
+}
+
+// numConnectedHeaders returns the number of leading headers of the passed
+// headers that each connect to the one before them, with the first connecting
+// to the block with the passed hash.  It is cheap compared to checking the
+// proof of work, so it is used to avoid checking the proof of work of headers
+// which are rejected anyways.
+func numConnectedHeaders(prevHash *chainhash.Hash, headers []*wire.BlockHeader) int {
+	for i, header := range headers {
+		if !prevHash.IsEqual(&header.PrevBlock) {
+			return i
+		}
+		hash := header.BlockHash()
+		prevHash = &hash
+	}
+	return len(headers)
+}
+
+// checkHeadersProofOfWork checks the proof of work of the passed headers, which
+// are expected to be consecutive and start at the passed height, with the
+// passed function using a pool of workers sized to GOMAXPROCS.  It returns the
+// number of leading headers with valid proof of work along with the error for
+// the first header that failed the check, if any.  The workers stop claiming
+// headers once one of them fails, so the headers after the first failure are
+// not all checked, while the first failure is always the one reported
+// regardless of which worker finished first.
+func checkHeadersProofOfWork(headers []*wire.BlockHeader, startHeight int32,
+	check func(*wire.BlockHeader, int32) error) (int, error) {
+
+	errs := make([]error, len(headers))
+	numWorkers := runtime.GOMAXPROCS(0)
+	if numWorkers > len(headers) {
+		numWorkers = len(headers)
+	}
+
+	// Each worker claims the next unchecked header until there are none
+	// left or a header before it failed.  Since the headers are claimed in
+	// order, all of the headers before a failed one have already been
+	// claimed and lower the index of the first failure when they fail as
+	// well.  Workers only ever write the result for the header they
+	// claimed, so the results do not need to be protected.
+	next := int32(-1)
+	firstFailure := int32(len(headers))
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for w := 0; w < numWorkers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := atomic.AddInt32(&next, 1)
+				if int(i) >= len(headers) ||
+					i > atomic.LoadInt32(&firstFailure) {
+
+					return
+				}
+				err := check(headers[i], startHeight+i)
+				if err == nil {
+					continue
+				}
+				errs[i] = err
+				for {
+					failure := atomic.LoadInt32(&firstFailure)
+					if i >= failure || atomic.CompareAndSwapInt32(
+						&firstFailure, failure, i) {
+
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	numValid := int(firstFailure)
+	if numValid == len(headers) {
+		return numValid, nil
+	}
+	return numValid, errs[numValid]
+}
+
+// handleHeaderPowFailure handles the passed error for the header with the
+// passed hash received from the passed peer whose proof of work did not pass
+// the check.  The peer is banned when the proof of work is invalid.  Otherwise,
+// the check could not be performed, so the headers are requested again with the
+// passed function a limited number of times in a row, unless the check can't
+// succeed such as when the proof-of-work algorithm is unavailable, before
+// disconnecting the peer.
+func (sm *SyncManager) handleHeaderPowFailure(peer *peerpkg.Peer,
+	state *peerSyncState, blockHash *chainhash.Hash, err error,
+	requestAgain func()) {
+
+	if _, ok := err.(blockchain.RuleError); ok {
+		reason := fmt.Sprintf("block header %v with invalid proof of "+
+			"work: %v", blockHash, err)
+		log.Warnf("Received %s from peer %s -- disconnecting", reason,
+			peer.Addr())
+		sm.peerNotifier.BanMisbehavingPeer(peer, reason)
+		peer.Disconnect()
+		return
+	}
+
+	if !errors.Is(err, wire.ErrPowAlgorithmUnavailable) &&
+		state.headerPowRetries < maxHeaderPowRetries {
+
+		state.headerPowRetries++
+		log.Errorf("Unable to check proof of work of block header %v: "+
+			"%v -- requesting it again", blockHash, err)
+		requestAgain()
+		return
+	}
+
+	log.Errorf("Unable to check proof of work of block header %v: %v -- "+
+		"disconnecting peer %s", blockHash, err, peer.Addr())
+	peer.Disconnect()
+}
+
 // handleHeadersMsg handles block header messages from all peers.  Headers are
 // requested when performing a headers-first sync.
 func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 	peer := hmsg.peer
-	_, exists := sm.peerStates[peer]
+	state, exists := sm.peerStates[peer]
 	if !exists {
 		log.Warnf("Received headers message from unknown peer %s", peer)
 		return
@@ -931,16 +1190,66 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		return
 	}
 
+	// The headers after the assumed valid block only show how deeply it is
+	// buried.
+	if sm.burialHeader != nil {
+		sm.handleBurialHeaders(peer, state, msg.Headers)
+		return
+	}
+
+	// The peer does not know of the assumed valid block when it runs out of
+	// headers before reaching it.
+	isAssumeValid := sm.nextCheckpoint == sm.assumeValidCheckpoint
+	if isAssumeValid && numHeaders < wire.MaxBlockHeadersPerMsg {
+		found := false
+		for i := range msg.Headers {
+			if msg.Headers[i].BlockHash() == *sm.nextCheckpoint.Hash {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sm.abandonAssumeValid(peer)
+			return
+		}
+	}
+
 	// Nothing to do for an empty headers message.
 	if numHeaders == 0 {
 		return
 	}
 
+	// Check the proof of work of the headers concurrently since it is by far
+	// the most expensive part of processing them.  Headers after the next
+	// checkpoint are not processed below, so they are not checked either.
+	// The heights assume the headers connect to each other, which is
+	// verified in order below before the result for each header is used.
+	// The chain keeps the verified hashes, so they are not calculated again
+	// when the blocks are processed.
+	// Only the headers which connect are checked, so a peer can't make
+	// the proof of work of a batch of headers that is rejected anyways be
+	// checked.
+	numValidPow := -1
+	var powErr error
+	if prevNodeEl := sm.headerList.Back(); prevNodeEl != nil {
+		prevNode := prevNodeEl.Value.(*headerNode)
+		startHeight := prevNode.height + 1
+		numToCheck := numConnectedHeaders(prevNode.hash, msg.Headers)
+		if n := int(sm.nextCheckpoint.Height-startHeight) + 1; n < numToCheck {
+			numToCheck = n
+		}
+		if numToCheck > 0 {
+			numValidPow, powErr = checkHeadersProofOfWork(
+				msg.Headers[:numToCheck], startHeight,
+				sm.chain.VerifyHeaderProofOfWork)
+		}
+	}
+
 	// Process all of the received headers ensuring each one connects to the
-	// previous and that checkpoints match.
+	// previous, has valid proof of work, and that checkpoints match.
 	receivedCheckpoint := false
 	var finalHash *chainhash.Hash
-	for _, blockHeader := range msg.Headers {
+	for i, blockHeader := range msg.Headers {
 		blockHash := blockHeader.BlockHash()
 		finalHash = &blockHash
 
@@ -953,17 +1262,10 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 			return
 		}
 
-		// Ensure the header properly connects to the previous one and
-		// add it to the list of headers.
+		// Ensure the header properly connects to the previous one.
 		node := headerNode{hash: &blockHash}
 		prevNode := prevNodeEl.Value.(*headerNode)
-		if prevNode.hash.IsEqual(&blockHeader.PrevBlock) {
-			node.height = prevNode.height + 1
-			e := sm.headerList.PushBack(&node)
-			if sm.startHeader == nil {
-				sm.startHeader = e
-			}
-		} else {
+		if !prevNode.hash.IsEqual(&blockHeader.PrevBlock) {
 			log.Warnf("Received block header that does not "+
 				"properly connect to the chain from peer %s "+
 				"-- disconnecting", peer.Addr())
@@ -971,6 +1273,54 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 			return
 		}
 
+		// Ensure the header has valid proof of work.  The headers are
+		// requested again starting with this one when its proof of work
+		// could not be checked, so the sync does not stall.
+		if i == numValidPow && powErr != nil {
+			sm.handleHeaderPowFailure(peer, state, &blockHash, powErr,
+				func() {
+					locator := blockchain.BlockLocator(
+						[]*chainhash.Hash{prevNode.hash})
+					err := peer.PushGetHeadersMsg(locator,
+						sm.nextCheckpoint.Hash)
+					if err != nil {
+						log.Warnf("Failed to send "+
+							"getheaders message to "+
+							"peer %s: %v",
+							peer.Addr(), err)
+					}
+				})
+			return
+		}
+		state.headerPowRetries = 0
+
+		// Add the header to the list of headers.
+		node.height = prevNode.height + 1
+		e := sm.headerList.PushBack(&node)
+		if sm.startHeader == nil {
+			sm.startHeader = e
+		}
+
+		// The height of the assumed valid block is not known in advance,
+		// so it is identified by its hash instead.  Its ancestors are only
+		// assumed to be valid when the headers leading to it and the
+		// ones after it have enough work and bury it deeply enough, so
+		// the headers after it are requested next.
+		if isAssumeValid {
+			sm.headersWork.Add(sm.headersWork,
+				blockchain.CalcWork(blockHeader.Bits))
+			if node.hash.IsEqual(sm.nextCheckpoint.Hash) {
+				sm.nextCheckpoint.Height = node.height
+				log.Infof("Downloaded block header for assumed "+
+					"valid block at height %d/hash %s",
+					node.height, node.hash)
+				sm.burialHeader = &node
+				sm.requestBurialHeaders(peer)
+				return
+			}
+			continue
+		}
+
 		// Verify the header at the next checkpoint height matches.
 		if node.height == sm.nextCheckpoint.Height {
 			if node.hash.IsEqual(sm.nextCheckpoint.Hash) {
@@ -995,15 +1345,7 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 	// When this header is a checkpoint, switch to fetching the blocks for
 	// all of the headers since the last checkpoint.
 	if receivedCheckpoint {
-		// Since the first entry of the list is always the final block
-		// that is already in the database and is only used to ensure
-		// the next header links properly, it must be removed before
-		// fetching the blocks.
-		sm.headerList.Remove(sm.headerList.Front())
-		log.Infof("Received %v block headers: Fetching blocks",
-			sm.headerList.Len())
-		sm.progressLogger.SetLastLogTime(time.Now())
-		sm.fetchHeaderBlocks()
+		sm.fetchCheckpointBlocks()
 		return
 	}
 
@@ -1019,6 +1361,114 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 	}
 }
 
+// fetchCheckpointBlocks switches to fetching the blocks for all of the headers
+// since the last checkpoint once the header at the next checkpoint has been
+// received.
+func (sm *SyncManager) fetchCheckpointBlocks() {
+	// Since the first entry of the list is always the final block that is
+	// already in the database and is only used to ensure the next header
+	// links properly, it must be removed before fetching the blocks.
+	sm.headerList.Remove(sm.headerList.Front())
+	log.Infof("Received %v block headers: Fetching blocks",
+		sm.headerList.Len())
+	sm.progressLogger.SetLastLogTime(time.Now())
+	sm.fetchHeaderBlocks()
+}
+
+// requestBurialHeaders requests the headers after the latest header that buries
+// the assumed valid block from the passed peer.
+func (sm *SyncManager) requestBurialHeaders(peer *peerpkg.Peer) {
+	locator := blockchain.BlockLocator([]*chainhash.Hash{sm.burialHeader.hash})
+	err := peer.PushGetHeadersMsg(locator, &zeroHash)
+	if err != nil {
+		log.Warnf("Failed to send getheaders message to peer %s: %v",
+			peer.Addr(), err)
+	}
+}
+
+// handleBurialHeaders handles the headers after the assumed valid block
+// received from the passed peer.  They are not downloaded, but must connect
+// and have valid proof of work, and are requested until they bury the assumed
+// valid block deeply enough or the peer runs out of them.  The blocks for the
+// headers leading to the assumed valid block are fetched afterwards.
+func (sm *SyncManager) handleBurialHeaders(peer *peerpkg.Peer,
+	state *peerSyncState, headers []*wire.BlockHeader) {
+
+
+	prevNode := sm.burialHeader
+	numConnected := numConnectedHeaders(prevNode.hash, headers)
+	numValidPow, powErr := checkHeadersProofOfWork(headers[:numConnected],
+		prevNode.height+1, sm.chain.VerifyHeaderProofOfWork)
+	for i, blockHeader := range headers {
+		blockHash := blockHeader.BlockHash()
+		if i == numConnected {
+			log.Warnf("Received block header that does not "+
+				"properly connect to the chain from peer %s "+
+				"-- disconnecting", peer.Addr())
+			peer.Disconnect()
+			return
+		}
+		if i == numValidPow {
+			// Request the headers again starting with this one when
+			// its proof of work could not be checked.
+			sm.handleHeaderPowFailure(peer, state, &blockHash, powErr,
+				func() {
+					sm.burialHeader = prevNode
+					sm.requestBurialHeaders(peer)
+				})
+			return
+		}
+		state.headerPowRetries = 0
+
+		work := blockchain.CalcWork(blockHeader.Bits)
+		sm.headersWork.Add(sm.headersWork, work)
+		sm.burialWork.Add(sm.burialWork, work)
+		sm.burialBits = blockHeader.Bits
+		prevNode = &headerNode{height: prevNode.height + 1, hash: &blockHash}
+	}
+	sm.burialHeader = prevNode
+
+	buried := blockchain.HasAssumeValidBurial(sm.burialWork,
+		sm.burialBits, sm.chainParams)
+	if !buried && len(headers) == wire.MaxBlockHeadersPerMsg {
+		sm.requestBurialHeaders(peer)
+		return
+	}
+
+	sm.burialHeader = nil
+	sm.assumeValidWork = sm.hasAssumeValidWork(buried)
+	sm.fetchCheckpointBlocks()
+}
+
+// hasAssumeValidWork returns whether or not the chain formed by the list of
+// headers leading to the assumed valid block and the headers after it has
+// enough work for the scripts of its blocks to not be validated.  The passed
+// flag indicates whether or not the headers after the assumed valid block bury
+// it deeply enough.
+func (sm *SyncManager) hasAssumeValidWork(buried bool) bool {
+	firstNode := sm.headerList.Front().Value.(*headerNode)
+	workSum, err := sm.chain.ChainWork(firstNode.hash)
+	if err != nil {
+		log.Warnf("Unable to determine the work of the headers leading "+
+			"to the assumed valid block: %v", err)
+		return false
+	}
+	workSum.Add(workSum, sm.headersWork)
+	if !blockchain.HasMinimumChainWork(workSum, sm.chainParams) {
+		log.Warnf("The headers leading to assumed valid block %v do "+
+			"not have the minimum chain work -- validating all "+
+			"scripts", sm.nextCheckpoint.Hash)
+		return false
+	}
+	if !buried {
+		log.Warnf("Assumed valid block %v is not buried deeply "+
+			"enough by the headers after it -- validating all "+
+			"scripts", sm.nextCheckpoint.Hash)
+		return false
+	}
+	return true
+}
+
 // handleNotFoundMsg handles notfound messages from all peers.
 func (sm *SyncManager) handleNotFoundMsg(nfmsg *notFoundMsg) {
 	peer := nfmsg.peer
@@ -1348,6 +1798,7 @@ out:
 				_, isOrphan, err := sm.chain.ProcessBlock(
 					msg.block, msg.flags)
 				if err != nil {
+					sm.haltOnInvalidSnapshot(err)
 					msg.reply <- processBlockResponse{
 						isOrphan: false,
 						err:      err,
@@ -1373,6 +1824,7 @@ out:
 
 		case <-stallTicker.C:
 			sm.handleStallSample()
+			sm.fetchSnapshotBlocks()
 
 		case <-sm.quit:
 			break out
@@ -1587,6 +2039,27 @@ func (sm *SyncManager) SyncPeerID() int32 {
 	return <-reply
 }
 
+// haltOnInvalidSnapshot requests the process to shut down when the passed error
+// from processing a block indicates the best chain is built on a utxo snapshot
+// which turned out to be invalid, since the chain can't continue from it.
+func (sm *SyncManager) haltOnInvalidSnapshot(err error) {
+	if !errors.Is(err, blockchain.ErrSnapshotInvalid) {
+		return
+	}
+
+	log.Criticalf("Shutting down: %v", err)
+	select {
+	case sm.requestProcessShutdown <- struct{}{}:
+	default:
+	}
+}
+
+// RequestedProcessShutdown returns a channel that is sent to when the chain
+// can't continue and the process must be shut down.
+func (sm *SyncManager) RequestedProcessShutdown() <-chan struct{} {
+	return sm.requestProcessShutdown
+}
+
 // ProcessBlock makes use of ProcessBlock on an internal instance of a block
 // chain.
 func (sm *SyncManager) ProcessBlock(block *btcutil.Block, flags blockchain.BehaviorFlags) (bool, error) {
@@ -1629,19 +2102,31 @@ func New(config *Config) (*SyncManager, error) {
 		progressLogger:  newBlockProgressLogger("Processed", log),
 		msgChan:         make(chan interface{}, config.MaxPeers*3),
 		headerList:      list.New(),
+		headersWork:     new(big.Int),
+		burialWork:      new(big.Int),
 		quit:            make(chan struct{}),
 		feeEstimator:    config.FeeEstimator,
+
+		requestProcessShutdown: make(chan struct{}),
+	}
+
+	if assumeValid := sm.chain.AssumeValid(); assumeValid != nil {
+		sm.assumeValidCheckpoint = &chaincfg.Checkpoint{
+			Height: unknownAssumeValidHeight,
+			Hash:   assumeValid,
+		}
 	}
 
 	best := sm.chain.BestSnapshot()
 	if !config.DisableCheckpoints {
 		// Initialize the next checkpoint based on the current height.
 		sm.nextCheckpoint = sm.findNextHeaderCheckpoint(best.Height)
-		if sm.nextCheckpoint != nil {
-			sm.resetHeaderState(&best.Hash, best.Height)
-		}
 	} else {
 		log.Info("Checkpoints are disabled")
+		sm.nextCheckpoint = sm.findAssumeValidCheckpoint(best.Height)
+	}
+	if sm.nextCheckpoint != nil {
+		sm.resetHeaderState(&best.Hash, best.Height)
 	}
 
 	sm.chain.Subscribe(sm.handleBlockchainNotification)
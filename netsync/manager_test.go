@@ -0,0 +1,207 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestCheckHeadersProofOfWork ensures the proof of work of every header is
+// checked at its height up to the first failure, which is the one reported
+// for valid headers, headers with invalid proof of work, and headers whose
+// proof of work could not be checked.
+func TestCheckHeadersProofOfWork(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	const (
+		startHeight = 100
+		numHeaders  = 64
+		badPowIdx   = 17
+		internalIdx = 42
+	)
+
+	// Create consecutive headers with valid proof of work except for one
+	// which claims a target no hash can satisfy.
+	headers := make([]*wire.BlockHeader, numHeaders)
+	prevHash := params.GenesisHash
+	for i := range headers {
+		header := &wire.BlockHeader{
+			Version:   4,
+			PrevBlock: *prevHash,
+			Timestamp: params.GenesisBlock.Header.Timestamp,
+			Bits:      params.PowLimitBits,
+		}
+		if i == badPowIdx {
+			header.Bits = 0x03000001
+		}
+		powAlgo := params.PowAlgorithmAt(startHeight + int32(i))
+		for i != badPowIdx && blockchain.CheckHeaderProofOfWork(header,
+			params.PowLimit, powAlgo) != nil {
+
+			header.Nonce++
+		}
+		headers[i] = header
+		hash := header.BlockHash()
+		prevHash = &hash
+	}
+
+	// The check fails with an error other than a rule error for one of the
+	// headers as if it could not be performed.
+	errInternal := errors.New("internal failure")
+	var mtx sync.Mutex
+	var checked []int32
+	check := func(header *wire.BlockHeader, height int32) error {
+		i := height - startHeight
+		if i < 0 || i >= numHeaders || headers[i] != header {
+			t.Errorf("header checked at unexpected height %d", height)
+			return nil
+		}
+		mtx.Lock()
+		checked = append(checked, i)
+		mtx.Unlock()
+		if i == internalIdx {
+			return errInternal
+		}
+		return blockchain.CheckHeaderProofOfWork(header, params.PowLimit,
+			params.PowAlgorithmAt(height))
+	}
+
+	tests := []struct {
+		name        string
+		firstIdx    int
+		lastIdx     int
+		wantValid   int
+		wantErr     error
+		wantErrCode blockchain.ErrorCode
+	}{{
+		name:      "all valid",
+		firstIdx:  0,
+		lastIdx:   badPowIdx,
+		wantValid: badPowIdx,
+	}, {
+		name:        "invalid proof of work",
+		firstIdx:    0,
+		lastIdx:     numHeaders,
+		wantValid:   badPowIdx,
+		wantErrCode: blockchain.ErrHighHash,
+	}, {
+		name:      "unable to check",
+		firstIdx:  badPowIdx + 1,
+		lastIdx:   numHeaders,
+		wantValid: internalIdx - badPowIdx - 1,
+		wantErr:   errInternal,
+	}, {
+		name:      "no headers",
+		firstIdx:  0,
+		lastIdx:   0,
+		wantValid: 0,
+	}}
+
+	for _, test := range tests {
+		checked = nil
+		numValid, err := checkHeadersProofOfWork(
+			headers[test.firstIdx:test.lastIdx],
+			startHeight+int32(test.firstIdx), check)
+		if numValid != test.wantValid {
+			t.Errorf("%s: unexpected number of valid headers - got "+
+				"%d, want %d", test.name, numValid, test.wantValid)
+		}
+		if test.wantErrCode != 0 {
+			rerr, ok := err.(blockchain.RuleError)
+			if !ok || rerr.ErrorCode != test.wantErrCode {
+				t.Errorf("%s: unexpected error - got %v, want %v",
+					test.name, err, test.wantErrCode)
+			}
+		} else if err != test.wantErr {
+			t.Errorf("%s: unexpected error - got %v, want %v",
+				test.name, err, test.wantErr)
+		}
+
+		// Every header up to the first failure must be checked exactly
+		// once, while the workers stop claiming headers after it.
+		seen := make(map[int32]bool)
+		for _, i := range checked {
+			if seen[i] {
+				t.Errorf("%s: header #%d checked more than once",
+					test.name, i)
+			}
+			seen[i] = true
+		}
+		for i := test.firstIdx; i < test.firstIdx+numValid; i++ {
+			if !seen[int32(i)] {
+				t.Errorf("%s: header #%d not checked", test.name, i)
+			}
+		}
+		if len(checked) > numValid+runtime.GOMAXPROCS(0) {
+			t.Errorf("%s: checked %d headers after the first failure",
+				test.name, len(checked)-numValid-1)
+		}
+	}
+}
+
+// TestNumConnectedHeaders ensures the number of leading headers which connect
+// to each other and to the passed block is determined correctly.
+func TestNumConnectedHeaders(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	headers := make([]*wire.BlockHeader, 10)
+	prevHash := params.GenesisHash
+	for i := range headers {
+		headers[i] = &wire.BlockHeader{
+			Version:   4,
+			PrevBlock: *prevHash,
+			Nonce:     uint32(i),
+		}
+		hash := headers[i].BlockHash()
+		prevHash = &hash
+	}
+	disconnected := *headers[6]
+	disconnected.PrevBlock = chainhash.Hash{0x01}
+
+	tests := []struct {
+		name     string
+		prevHash *chainhash.Hash
+		headers  []*wire.BlockHeader
+		want     int
+	}{{
+		name:     "all connected",
+		prevHash: params.GenesisHash,
+		headers:  headers,
+		want:     len(headers),
+	}, {
+		name:     "first does not connect",
+		prevHash: &chainhash.Hash{0x01},
+		headers:  headers,
+		want:     0,
+	}, {
+		name:     "header in the middle does not connect",
+		prevHash: params.GenesisHash,
+		headers: append(append(headers[:6:6], &disconnected),
+			headers[7:]...),
+		want: 6,
+	}, {
+		name:     "no headers",
+		prevHash: params.GenesisHash,
+		want:     0,
+	}}
+
+	for _, test := range tests {
+		got := numConnectedHeaders(test.prevHash, test.headers)
+		if got != test.want {
+			t.Errorf("%s: unexpected number of connected headers - "+
+				"got %d, want %d", test.name, got, test.want)
+		}
+	}
+}
@@ -5,6 +5,9 @@
 package main
 
 import (
+	"math"
+	"strconv"
+
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/wire"
 )
@@ -62,6 +65,23 @@ var sigNetParams = params{
 	rpcPort: "38332",
 }
 
+// newCustomNetParams returns the parameters for a custom network which is
+// defined by the passed chain parameters.  Since custom networks have no
+// well-known RPC port, the RPC server listens on the port following the
+// peer-to-peer port by default.
+func newCustomNetParams(chainParams *chaincfg.Params) *params {
+	rpcPort := chainParams.DefaultPort
+	if port, err := strconv.ParseUint(rpcPort, 10, 16); err == nil &&
+		port < math.MaxUint16 {
+
+		rpcPort = strconv.FormatUint(port+1, 10)
+	}
+	return &params{
+		Params:  chainParams,
+		rpcPort: rpcPort,
+	}
+}
+
 // netName returns the name used when referring to a bitcoin network.  At the
 // time of writing, btcd currently places blocks for testnet version 3 in the
 // data and log directory "testnet", which does not match the Name field of the
@@ -307,6 +307,43 @@ func (c *Client) GetBlockCount() (int64, error) {
 	return c.GetBlockCountAsync().Receive()
 }
 
+// FutureGetChainTipsResult is a future promise to deliver the result of a
+// GetChainTipsAsync RPC invocation (or an applicable error).
+type FutureGetChainTipsResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the tips
+// of the main chain and of all known side chains.
+func (r FutureGetChainTipsResult) Receive() ([]btcjson.GetChainTipsResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var chainTips []btcjson.GetChainTipsResult
+	err = json.Unmarshal(res, &chainTips)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainTips, nil
+}
+
+// GetChainTipsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetChainTips for the blocking version and more details.
+func (c *Client) GetChainTipsAsync() FutureGetChainTipsResult {
+	cmd := btcjson.NewGetChainTipsCmd()
+	return c.SendCmd(cmd)
+}
+
+// GetChainTips returns the tips of the main chain and of all known side chains
+// along with the length of their branches and their status.
+func (c *Client) GetChainTips() ([]btcjson.GetChainTipsResult, error) {
+	return c.GetChainTipsAsync().Receive()
+}
+
 // FutureGetChainTxStatsResult is a future promise to deliver the result of a
 // GetChainTxStatsAsync RPC invocation (or an applicable error).
 type FutureGetChainTxStatsResult chan *Response
@@ -380,6 +417,50 @@ func (c *Client) GetChainTxStatsNBlocksBlockHash(nBlocks int32, blockHash chainh
 	return c.GetChainTxStatsNBlocksBlockHashAsync(nBlocks, blockHash).Receive()
 }
 
+// FutureGetDeploymentInfoResult is a future promise to deliver the result of a
+// GetDeploymentInfoAsync RPC invocation (or an applicable error).
+type FutureGetDeploymentInfoResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the state
+// of all consensus rule change deployments.
+func (r FutureGetDeploymentInfoResult) Receive() (*btcjson.GetDeploymentInfoResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var deploymentInfo btcjson.GetDeploymentInfoResult
+	err = json.Unmarshal(res, &deploymentInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deploymentInfo, nil
+}
+
+// GetDeploymentInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetDeploymentInfo for the blocking version and more details.
+func (c *Client) GetDeploymentInfoAsync(blockHash *chainhash.Hash) FutureGetDeploymentInfoResult {
+	var hash *string
+	if blockHash != nil {
+		hash = btcjson.String(blockHash.String())
+	}
+
+	cmd := btcjson.NewGetDeploymentInfoCmd(hash)
+	return c.SendCmd(cmd)
+}
+
+// GetDeploymentInfo returns the state of all consensus rule change deployments
+// as of the block with the passed hash along with the signalling statistics of
+// their current period.  The state as of the best block is returned when the
+// hash is nil.
+func (c *Client) GetDeploymentInfo(blockHash *chainhash.Hash) (*btcjson.GetDeploymentInfoResult, error) {
+	return c.GetDeploymentInfoAsync(blockHash).Receive()
+}
+
 // FutureGetDifficultyResult is a future promise to deliver the result of a
 // GetDifficultyAsync RPC invocation (or an applicable error).
 type FutureGetDifficultyResult chan *Response
@@ -121,6 +121,19 @@ type NotificationHandlers struct {
 	// OnBlockDisconnected: it receives the block's height and header.
 	OnFilteredBlockDisconnected func(height int32, header *wire.BlockHeader)
 
+	// OnChainReorg is invoked when the longest (best) chain has been
+	// reorganized.  It receives the hash and height of the fork point and
+	// the hashes of the disconnected blocks, starting with the old best
+	// block, and of the connected blocks, ending with the new best block.
+	// It will only be invoked if a preceding call to NotifyBlocks has been
+	// made to register for the notification and the function is non-nil.
+	// It is invoked before the handlers for the individual blocks, except
+	// for reorganizations caused by invalidating, reconsidering or
+	// preferring blocks, which are performed in steps and reported after
+	// them.
+	OnChainReorg func(forkHash *chainhash.Hash, forkHeight int32,
+		detached, attached []*chainhash.Hash)
+
 	// OnRecvTx is invoked when a transaction that receives funds to a
 	// registered address is received into the memory pool and also
 	// connected to the longest (best) chain.  It will only be invoked if a
@@ -290,6 +303,25 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 		c.ntfnHandlers.OnFilteredBlockDisconnected(blockHeight,
 			blockHeader)
 
+	// OnChainReorg
+	case btcjson.ChainReorgNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnChainReorg == nil {
+			return
+		}
+
+		forkHash, forkHeight, detached, attached, err :=
+			parseChainReorgNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid chain reorg notification: "+
+				"%v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnChainReorg(forkHash, forkHeight, detached,
+			attached)
+
 	// OnRecvTx
 	case btcjson.RecvTxNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -615,6 +647,57 @@ func parseFilteredBlockDisconnectedParams(params []json.RawMessage) (int32,
 	return blockHeight, &blockHeader, nil
 }
 
+// parseChainReorgNtfnParams parses out the fork point and the hashes of the
+// detached and attached blocks from the parameters of a chainreorg
+// notification.
+func parseChainReorgNtfnParams(params []json.RawMessage) (*chainhash.Hash,
+	int32, []*chainhash.Hash, []*chainhash.Hash, error) {
+
+	if len(params) != 4 {
+		return nil, 0, nil, nil, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a string.
+	var forkHashStr string
+	err := json.Unmarshal(params[0], &forkHashStr)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+
+	// Unmarshal second parameter as an integer.
+	var forkHeight int32
+	err = json.Unmarshal(params[1], &forkHeight)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+
+	// Create hash from fork hash string.
+	forkHash, err := chainhash.NewHashFromStr(forkHashStr)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+
+	// Unmarshal the third and fourth parameters as slices of hashes.
+	var hashes [2][]*chainhash.Hash
+	for i := range hashes {
+		var hashStrs []string
+		err := json.Unmarshal(params[2+i], &hashStrs)
+		if err != nil {
+			return nil, 0, nil, nil, err
+		}
+		hashes[i] = make([]*chainhash.Hash, 0, len(hashStrs))
+		for _, hashStr := range hashStrs {
+			hash, err := chainhash.NewHashFromStr(hashStr)
+			if err != nil {
+				return nil, 0, nil, nil, err
+			}
+			hashes[i] = append(hashes[i], hash)
+		}
+	}
+
+	return forkHash, forkHeight, hashes[0], hashes[1], nil
+}
+
 func parseHexParam(param json.RawMessage) ([]byte, error) {
 	var s string
 	err := json.Unmarshal(param, &s)
@@ -205,6 +205,51 @@ func (c *Client) DecodeRawTransaction(serializedTx []byte) (*btcjson.TxRawResult
 	return c.DecodeRawTransactionAsync(serializedTx).Receive()
 }
 
+// FutureDecodeRawTransactionExplainResult is a future promise to deliver the
+// result of a DecodeRawTransactionExplainAsync RPC invocation (or an applicable
+// error).
+type FutureDecodeRawTransactionExplainResult chan *Response
+
+// Receive waits for the Response promised by the future and returns information
+// about a transaction along with the explanation of its standardness.
+func (r FutureDecodeRawTransactionExplainResult) Receive() (*btcjson.TxRawDecodeResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a decoderawtransaction result object.
+	var rawTxResult btcjson.TxRawDecodeResult
+	err = json.Unmarshal(res, &rawTxResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rawTxResult, nil
+}
+
+// DecodeRawTransactionExplainAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See DecodeRawTransactionExplain for the blocking version and more details.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) DecodeRawTransactionExplainAsync(serializedTx []byte) FutureDecodeRawTransactionExplainResult {
+	txHex := hex.EncodeToString(serializedTx)
+	cmd := btcjson.NewExplainDecodeRawTransactionCmd(txHex)
+	return c.SendCmd(cmd)
+}
+
+// DecodeRawTransactionExplain returns information about a transaction given its
+// serialized bytes along with the results of every policy rule which determines
+// whether it is standard.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) DecodeRawTransactionExplain(serializedTx []byte) (*btcjson.TxRawDecodeResult, error) {
+	return c.DecodeRawTransactionExplainAsync(serializedTx).Receive()
+}
+
 // FutureFundRawTransactionResult is a future promise to deliver the result
 // of a FutureFundRawTransactionAsync RPC invocation (or an applicable error).
 type FutureFundRawTransactionResult chan *Response
@@ -383,6 +428,61 @@ func (c *Client) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainh
 	return c.SendRawTransactionAsync(tx, allowHighFees).Receive()
 }
 
+// FutureSendRawTransactionExplainResult is a future promise to deliver the
+// result of a SendRawTransactionExplainAsync RPC invocation (or an applicable
+// error).
+type FutureSendRawTransactionExplainResult chan *Response
+
+// Receive waits for the Response promised by the future and returns whether the
+// transaction was accepted along with the explanation of its standardness.
+func (r FutureSendRawTransactionExplainResult) Receive() (*btcjson.SendRawTransactionResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a sendrawtransaction result object.
+	var sendResult btcjson.SendRawTransactionResult
+	err = json.Unmarshal(res, &sendResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sendResult, nil
+}
+
+// SendRawTransactionExplainAsync returns an instance of a type that can be used
+// to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See SendRawTransactionExplain for the blocking version and more details.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) SendRawTransactionExplainAsync(tx *wire.MsgTx, allowHighFees bool) FutureSendRawTransactionExplainResult {
+	txHex := ""
+	if tx != nil {
+		// Serialize the transaction and convert to hex string.
+		buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+		if err := tx.Serialize(buf); err != nil {
+			return newFutureError(err)
+		}
+		txHex = hex.EncodeToString(buf.Bytes())
+	}
+
+	cmd := btcjson.NewExplainSendRawTransactionCmd(txHex, &allowHighFees)
+	return c.SendCmd(cmd)
+}
+
+// SendRawTransactionExplain submits the encoded transaction to the server which
+// will then relay it to the network when it is accepted.  Instead of failing
+// when the transaction is rejected, the reason is returned along with the
+// results of every policy rule which determines whether it is standard.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) SendRawTransactionExplain(tx *wire.MsgTx, allowHighFees bool) (*btcjson.SendRawTransactionResult, error) {
+	return c.SendRawTransactionExplainAsync(tx, allowHighFees).Receive()
+}
+
 // FutureSignRawTransactionResult is a future promise to deliver the result
 // of one of the SignRawTransactionAsync family of RPC invocations (or an
 // applicable error).
@@ -882,3 +982,54 @@ func (c *Client) DecodeScriptAsync(serializedScript []byte) FutureDecodeScriptRe
 func (c *Client) DecodeScript(serializedScript []byte) (*btcjson.DecodeScriptResult, error) {
 	return c.DecodeScriptAsync(serializedScript).Receive()
 }
+
+// FutureDebugScriptResult is a future promise to deliver the result of a
+// DebugScriptAsync RPC invocation (or an applicable error).
+type FutureDebugScriptResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the trace
+// of the execution of the scripts of a transaction input.
+func (r FutureDebugScriptResult) Receive() (*btcjson.DebugScriptResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a debugscript result object.
+	var debugScriptResult btcjson.DebugScriptResult
+	err = json.Unmarshal(res, &debugScriptResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &debugScriptResult, nil
+}
+
+// DebugScriptAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See DebugScript for the blocking version and more details.
+func (c *Client) DebugScriptAsync(tx *wire.MsgTx, vin uint32) FutureDebugScriptResult {
+	txHex := ""
+	if tx != nil {
+		// Serialize the transaction and convert to hex string.
+		buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+		if err := tx.Serialize(buf); err != nil {
+			return newFutureError(err)
+		}
+		txHex = hex.EncodeToString(buf.Bytes())
+	}
+
+	cmd := btcjson.NewDebugScriptCmd(txHex, vin)
+	return c.SendCmd(cmd)
+}
+
+// DebugScript executes the scripts of the passed input of the transaction
+// against the output it spends and returns a trace of every step of the
+// execution.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) DebugScript(tx *wire.MsgTx, vin uint32) (*btcjson.DebugScriptResult, error) {
+	return c.DebugScriptAsync(tx, vin).Receive()
+}
@@ -21,6 +21,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -133,8 +134,10 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"addnode":                handleAddNode,
 	"createrawtransaction":   handleCreateRawTransaction,
 	"debuglevel":             handleDebugLevel,
+	"debugscript":            handleDebugScript,
 	"decoderawtransaction":   handleDecodeRawTransaction,
 	"decodescript":           handleDecodeScript,
+	"dumptxoutset":           handleDumpTxOutSet,
 	"estimatefee":            handleEstimateFee,
 	"generate":               handleGenerate,
 	"getaddednodeinfo":       handleGetAddedNodeInfo,
@@ -148,8 +151,10 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getblocktemplate":       handleGetBlockTemplate,
 	"getcfilter":             handleGetCFilter,
 	"getcfilterheader":       handleGetCFilterHeader,
+	"getchaintips":           handleGetChainTips,
 	"getconnectioncount":     handleGetConnectionCount,
 	"getcurrentnet":          handleGetCurrentNet,
+	"getdeploymentinfo":      handleGetDeploymentInfo,
 	"getdifficulty":          handleGetDifficulty,
 	"getgenerate":            handleGetGenerate,
 	"gethashespersec":        handleGetHashesPerSec,
@@ -164,9 +169,14 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getrawmempool":          handleGetRawMempool,
 	"getrawtransaction":      handleGetRawTransaction,
 	"gettxout":               handleGetTxOut,
+	"gettxoutsetinfo":        handleGetTxOutSetInfo,
 	"help":                   handleHelp,
+	"invalidateblock":        handleInvalidateBlock,
+	"loadtxoutset":           handleLoadTxOutSet,
 	"node":                   handleNode,
 	"ping":                   handlePing,
+	"preciousblock":          handlePreciousBlock,
+	"reconsiderblock":        handleReconsiderBlock,
 	"searchrawtransactions":  handleSearchRawTransactions,
 	"sendrawtransaction":     handleSendRawTransaction,
 	"setgenerate":            handleSetGenerate,
@@ -200,7 +210,6 @@ var rpcAskWallet = map[string]struct{}{
 	"getreceivedbyaccount":   {},
 	"getreceivedbyaddress":   {},
 	"gettransaction":         {},
-	"gettxoutsetinfo":        {},
 	"getunconfirmedbalance":  {},
 	"getwalletinfo":          {},
 	"importprivkey":          {},
@@ -231,13 +240,9 @@ var rpcAskWallet = map[string]struct{}{
 // Commands that are currently unimplemented, but should ultimately be.
 var rpcUnimplemented = map[string]struct{}{
 	"estimatepriority": {},
-	"getchaintips":     {},
 	"getmempoolentry":  {},
 	"getnetworkinfo":   {},
 	"getwork":          {},
-	"invalidateblock":  {},
-	"preciousblock":    {},
-	"reconsiderblock":  {},
 }
 
 // Commands that are available to a limited user
@@ -257,6 +262,7 @@ var rpcLimited = map[string]struct{}{
 
 	// HTTP/S-only commands
 	"createrawtransaction":  {},
+	"debugscript":           {},
 	"decoderawtransaction":  {},
 	"decodescript":          {},
 	"estimatefee":           {},
@@ -639,6 +645,121 @@ func handleDebugLevel(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return "Done.", nil
 }
 
+// debugScriptCondNames maps the entries of the conditional execution stack of
+// the script engine to the names used in debugscript results.
+var debugScriptCondNames = map[int]string{
+	txscript.OpCondFalse: "false",
+	txscript.OpCondTrue:  "true",
+	txscript.OpCondSkip:  "skip",
+}
+
+// createDebugScriptStep returns a JSON object for the passed trace of a step
+// of a script execution to be used in a debugscript result.
+func createDebugScriptStep(trace *txscript.StepTrace) btcjson.DebugScriptStep {
+	step := btcjson.DebugScriptStep{
+		Script:    trace.ScriptIdx,
+		Index:     trace.OpcodeIdx,
+		Opcode:    trace.Opcode,
+		Stack:     make([]string, 0, len(trace.Stack)),
+		AltStack:  make([]string, 0, len(trace.AltStack)),
+		CondStack: make([]string, 0, len(trace.CondStack)),
+	}
+	for _, item := range trace.Stack {
+		step.Stack = append(step.Stack, hex.EncodeToString(item))
+	}
+	for _, item := range trace.AltStack {
+		step.AltStack = append(step.AltStack, hex.EncodeToString(item))
+	}
+	for _, cond := range trace.CondStack {
+		step.CondStack = append(step.CondStack, debugScriptCondNames[cond])
+	}
+	for _, sigCheck := range trace.SigChecks {
+		step.SigChecks = append(step.SigChecks, btcjson.DebugScriptSigCheck{
+			PubKey:    hex.EncodeToString(sigCheck.PubKey),
+			Signature: hex.EncodeToString(sigCheck.Signature),
+			Valid:     sigCheck.Valid,
+		})
+	}
+	if trace.Err != nil {
+		step.Error = trace.Err.Error()
+	}
+
+	return step
+}
+
+// handleDebugScript handles debugscript commands.
+func handleDebugScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DebugScriptCmd)
+
+	// Deserialize the transaction.
+	hexStr := c.HexTx
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	serializedTx, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	var mtx wire.MsgTx
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "TX decode failed: " + err.Error(),
+		}
+	}
+	if c.Vin >= uint32(len(mtx.TxIn)) {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Input index %d does not exist for "+
+				"transaction with %d inputs", c.Vin, len(mtx.TxIn)),
+		}
+	}
+	if blockchain.IsCoinBaseTx(&mtx) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "The input of a coinbase transaction has no script to execute",
+		}
+	}
+
+	// Fetch the outputs spent by all inputs since taproot signatures
+	// commit to all of them.
+	prevOuts, err := fetchSpentOutputs(s, &mtx)
+	if err != nil {
+		return nil, err
+	}
+	prevOut := prevOuts[mtx.TxIn[c.Vin].PreviousOutPoint]
+	prevOutFetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+
+	// Execute the scripts of the input with the flags used for standard
+	// transactions while tracing every step.
+	result := &btcjson.DebugScriptResult{
+		Txid:         mtx.TxHash().String(),
+		Vin:          c.Vin,
+		ScriptPubKey: hex.EncodeToString(prevOut.PkScript),
+		Amount:       btcutil.Amount(prevOut.Value).ToBTC(),
+		Steps:        []btcjson.DebugScriptStep{},
+	}
+	vm, err := txscript.NewEngine(prevOut.PkScript, &mtx, int(c.Vin),
+		txscript.StandardVerifyFlags, nil,
+		txscript.NewTxSigHashes(&mtx, prevOutFetcher), prevOut.Value,
+		prevOutFetcher)
+	if err == nil {
+		vm.SetTracer(func(trace *txscript.StepTrace) {
+			result.Steps = append(result.Steps,
+				createDebugScriptStep(trace))
+		})
+		err = vm.Execute()
+	}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Valid = true
+	}
+
+	return result, nil
+}
+
 // witnessToHex formats the passed witness stack as a slice of hex-encoded
 // strings to be used in a JSON response.
 func witnessToHex(witness wire.TxWitness) []string {
@@ -779,6 +900,52 @@ func createTxRawResult(chainParams *chaincfg.Params, mtx *wire.MsgTx,
 	return txReply, nil
 }
 
+// createStandardnessResult converts the passed standardness report of a
+// transaction into its JSON-RPC representation.
+func createStandardnessResult(report *mempool.StandardnessReport) *btcjson.StandardnessResult {
+	createRuleResults := func(results []mempool.RuleResult) []btcjson.StandardnessRuleResult {
+		ruleResults := make([]btcjson.StandardnessRuleResult, 0,
+			len(results))
+		for _, result := range results {
+			ruleResult := btcjson.StandardnessRuleResult{
+				Rule:   string(result.Rule),
+				Passed: result.Passed,
+				Reason: result.Reason,
+			}
+			if !result.Passed {
+				ruleResult.RejectCode = result.RejectCode.String()
+			}
+			ruleResults = append(ruleResults, ruleResult)
+		}
+		return ruleResults
+	}
+
+	standardnessResult := &btcjson.StandardnessResult{
+		Standard: report.IsStandard(),
+		Rules:    createRuleResults(report.Results),
+		Vin: make([]btcjson.StandardnessVinResult, 0,
+			len(report.Inputs)),
+		Vout: make([]btcjson.StandardnessVoutResult, 0,
+			len(report.Outputs)),
+	}
+	for i, input := range report.Inputs {
+		standardnessResult.Vin = append(standardnessResult.Vin,
+			btcjson.StandardnessVinResult{
+				N:              uint32(i),
+				PrevOutMissing: input.PrevOutMissing,
+				Rules:          createRuleResults(input.Results),
+			})
+	}
+	for i, output := range report.Outputs {
+		standardnessResult.Vout = append(standardnessResult.Vout,
+			btcjson.StandardnessVoutResult{
+				N:     uint32(i),
+				Rules: createRuleResults(output.Results),
+			})
+	}
+	return standardnessResult
+}
+
 // handleDecodeRawTransaction handles decoderawtransaction commands.
 func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.DecodeRawTransactionCmd)
@@ -809,6 +976,18 @@ func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 		Vin:      createVinList(&mtx),
 		Vout:     createVoutList(&mtx, s.cfg.ChainParams, nil),
 	}
+
+	// Explain the standardness of the transaction when requested.
+	if c.Explain != nil && *c.Explain {
+		report, err := s.cfg.TxMemPool.ExplainStandardness(
+			btcutil.NewTx(&mtx))
+		if err != nil {
+			context := "Failed to explain standardness"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		txReply.Standardness = createStandardnessResult(report)
+	}
+
 	return txReply, nil
 }
 
@@ -860,6 +1039,61 @@ func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return reply, nil
 }
 
+// txOutSetPath returns the path of a utxo snapshot file passed to the
+// dumptxoutset and loadtxoutset commands.  Relative paths are relative to the
+// data directory.
+func txOutSetPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cfg.DataDir, path)
+}
+
+// handleDumpTxOutSet implements the dumptxoutset command.
+func handleDumpTxOutSet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DumpTxOutSetCmd)
+
+	path := txOutSetPath(c.Path)
+	if _, err := os.Stat(path); err == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: path + " already exists",
+		}
+	}
+
+	// Write the snapshot to a temporary file first so an incomplete
+	// snapshot is never left behind at the requested path.
+	tmpPath := path + ".incomplete"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Unable to create file: " + err.Error(),
+		}
+	}
+	snapshot, err := s.cfg.Chain.DumpUtxoSnapshot(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		context := "Failed to dump utxo snapshot"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return &btcjson.DumpTxOutSetResult{
+		CoinsWritten: snapshot.NumCoins,
+		BaseHash:     snapshot.BaseHash.String(),
+		BaseHeight:   snapshot.BaseHeight,
+		Path:         path,
+		TxOutSetHash: snapshot.SnapshotHash.String(),
+		NChainTx:     snapshot.ChainTxCount,
+	}, nil
+}
+
 // handleEstimateFee handles estimatefee commands.
 func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.EstimateFeeCmd)
@@ -1081,6 +1315,12 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		return err
 	})
 	if err != nil {
+		if s.cfg.Chain.IsBlockPruned(hash) {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCMisc,
+				Message: "Block not available (pruned data)",
+			}
+		}
 		return nil, &btcjson.RPCError{
 			Code:    btcjson.ErrRPCBlockNotFound,
 			Message: "Block not found",
@@ -1180,11 +1420,38 @@ func softForkStatus(state blockchain.ThresholdState) (string, error) {
 		return "active", nil
 	case blockchain.ThresholdFailed:
 		return "failed", nil
+	case blockchain.ThresholdMustSignal:
+		return "must_signal", nil
 	default:
 		return "", fmt.Errorf("unknown deployment state: %v", state)
 	}
 }
 
+// deploymentName returns the human readable fork-name of the passed deployment
+// ID.
+func deploymentName(deployment int) (string, error) {
+	switch deployment {
+	case chaincfg.DeploymentTestDummy:
+		return "dummy", nil
+
+	case chaincfg.DeploymentCSV:
+		return "csv", nil
+
+	case chaincfg.DeploymentSegwit:
+		return "segwit", nil
+
+	case chaincfg.DeploymentTaproot:
+		return "taproot", nil
+
+	default:
+		return "", &btcjson.RPCError{
+			Code: btcjson.ErrRPCInternal.Code,
+			Message: fmt.Sprintf("Unknown deployment %v "+
+				"detected", deployment),
+		}
+	}
+}
+
 // handleGetBlockChainInfo implements the getblockchaininfo command.
 func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Obtain a snapshot of the current best known blockchain state. We'll
@@ -1200,11 +1467,14 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 		BestBlockHash: chainSnapshot.Hash.String(),
 		Difficulty:    getDifficultyRatio(chainSnapshot.Bits, params),
 		MedianTime:    chainSnapshot.MedianTime.Unix(),
-		Pruned:        false,
+		Pruned:        chain.IsPruned(),
 		SoftForks: &btcjson.SoftForks{
 			Bip9SoftForks: make(map[string]*btcjson.Bip9SoftForkDescription),
 		},
 	}
+	if chainInfo.Pruned {
+		chainInfo.PruneHeight = chain.PruneHeight()
+	}
 
 	// Next, populate the response with information describing the current
 	// status of soft-forks deployed via the super-majority block
@@ -1245,26 +1515,9 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 	for deployment, deploymentDetails := range params.Deployments {
 		// Map the integer deployment ID into a human readable
 		// fork-name.
-		var forkName string
-		switch deployment {
-		case chaincfg.DeploymentTestDummy:
-			forkName = "dummy"
-
-		case chaincfg.DeploymentCSV:
-			forkName = "csv"
-
-		case chaincfg.DeploymentSegwit:
-			forkName = "segwit"
-
-		case chaincfg.DeploymentTaproot:
-			forkName = "taproot"
-
-		default:
-			return nil, &btcjson.RPCError{
-				Code: btcjson.ErrRPCInternal.Code,
-				Message: fmt.Sprintf("Unknown deployment %v "+
-					"detected", deployment),
-			}
+		forkName, err := deploymentName(deployment)
+		if err != nil {
+			return nil, err
 		}
 
 		// Query the chain for the current status of the deployment as
@@ -2241,6 +2494,21 @@ func handleGetCFilterHeader(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hash.String(), nil
 }
 
+// handleGetChainTips implements the getchaintips command.
+func handleGetChainTips(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	chainTips := s.cfg.Chain.ChainTips()
+	results := make([]btcjson.GetChainTipsResult, 0, len(chainTips))
+	for _, tip := range chainTips {
+		results = append(results, btcjson.GetChainTipsResult{
+			Height:    tip.Height,
+			Hash:      tip.Hash.String(),
+			BranchLen: tip.BranchLen,
+			Status:    tip.Status.String(),
+		})
+	}
+	return results, nil
+}
+
 // handleGetConnectionCount implements the getconnectioncount command.
 func handleGetConnectionCount(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return s.cfg.ConnMgr.ConnectedCount(), nil
@@ -2251,6 +2519,101 @@ func handleGetCurrentNet(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return s.cfg.ChainParams.Net, nil
 }
 
+// handleGetDeploymentInfo implements the getdeploymentinfo command.
+func handleGetDeploymentInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetDeploymentInfoCmd)
+	params := s.cfg.ChainParams
+	chain := s.cfg.Chain
+
+	// Report the state as of the best block unless a block is specified.
+	hash := &chain.BestSnapshot().Hash
+	if c.BlockHash != nil {
+		var err error
+		hash, err = chainhash.NewHashFromStr(*c.BlockHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.BlockHash)
+		}
+	}
+
+	result := &btcjson.GetDeploymentInfoResult{
+		Hash:        hash.String(),
+		Deployments: make(map[string]*btcjson.DeploymentInfo),
+	}
+	for deployment := range params.Deployments {
+		deploymentDetails := &params.Deployments[deployment]
+		forkName, err := deploymentName(deployment)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := chain.DeploymentInfo(hash, uint32(deployment))
+		if err != nil {
+			if _, ok := err.(blockchain.DeploymentError); ok {
+				context := "Failed to obtain deployment info"
+				return nil, internalRPCError(err.Error(), context)
+			}
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCBlockNotFound,
+				Message: "Block not found",
+			}
+		}
+		result.Height = info.Height
+
+		status, err := softForkStatus(info.State)
+		if err != nil {
+			return nil, internalRPCError(err.Error(), "")
+		}
+		statusNext, err := softForkStatus(info.NextState)
+		if err != nil {
+			return nil, internalRPCError(err.Error(), "")
+		}
+
+		bip9 := &btcjson.Bip9DeploymentInfo{
+			Bit:                 deploymentDetails.BitNumber,
+			MinActivationHeight: int32(deploymentDetails.MinActivationHeight),
+			Status:              status,
+			Since:               info.Since,
+			StatusNext:          statusNext,
+		}
+		deploymentType := "bip9"
+		if deploymentDetails.HeightBased() {
+			deploymentType = "bip8"
+			bip9.StartHeight = int32(deploymentDetails.StartHeight)
+			bip9.TimeoutHeight = int32(deploymentDetails.TimeoutHeight)
+			bip9.LockInOnTimeout = deploymentDetails.LockInOnTimeout
+		} else {
+			bip9.StartTime = int64(deploymentDetails.StartTime)
+			bip9.Timeout = int64(deploymentDetails.ExpireTime)
+		}
+		if stats := info.Statistics; stats != nil {
+			bip9.Statistics = &btcjson.DeploymentStatistics{
+				Period:    stats.Period,
+				Threshold: stats.Threshold,
+				Elapsed:   stats.Elapsed,
+				Count:     stats.Count,
+				Possible:  stats.Possible,
+			}
+		}
+
+		// The rules of the deployment are enforced for the next block
+		// when its state is active.
+		deploymentInfo := &btcjson.DeploymentInfo{
+			Type:   deploymentType,
+			Active: info.NextState == blockchain.ThresholdActive,
+			Bip9:   bip9,
+		}
+		switch {
+		case info.State == blockchain.ThresholdActive:
+			deploymentInfo.Height = info.Since
+		case deploymentInfo.Active:
+			deploymentInfo.Height = info.Height + 1
+		}
+		result.Deployments[forkName] = deploymentInfo
+	}
+
+	return result, nil
+}
+
 // handleGetDifficulty implements the getdifficulty command.
 func handleGetDifficulty(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	best := s.cfg.Chain.BestSnapshot()
@@ -2803,6 +3166,20 @@ func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	return txOutReply, nil
 }
 
+// handleGetTxOutSetInfo implements the gettxoutsetinfo command.
+func handleGetTxOutSetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	stats := s.cfg.Chain.UtxoSetStats()
+	return &btcjson.GetTxOutSetInfoResult{
+		Height:      int64(stats.Height),
+		BestBlock:   stats.Hash,
+		TxOuts:      int64(stats.NumUtxos),
+		BogoSize:    int64(stats.BogoSize),
+		MuHash:      stats.MuHash,
+		DiskSize:    int64(stats.SerializedSize),
+		TotalAmount: stats.TotalAmount,
+	}, nil
+}
+
 // handleHelp implements the help command.
 func handleHelp(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.HelpCmd)
@@ -2842,6 +3219,69 @@ func handleHelp(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return help, nil
 }
 
+// knownBlockHash decodes the passed block hash and ensures the block is known.
+func knownBlockHash(s *rpcServer, blockHash string) (*chainhash.Hash, error) {
+	hash, err := chainhash.NewHashFromStr(blockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(blockHash)
+	}
+	if _, err := s.cfg.Chain.HeaderByHash(hash); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+	return hash, nil
+}
+
+// handleInvalidateBlock implements the invalidateblock command.
+func handleInvalidateBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.InvalidateBlockCmd)
+
+	hash, err := knownBlockHash(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cfg.Chain.InvalidateBlock(hash); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDatabase,
+			Message: "Unable to invalidate block: " + err.Error(),
+		}
+	}
+
+	return nil, nil
+}
+
+// handleLoadTxOutSet implements the loadtxoutset command.
+func handleLoadTxOutSet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.LoadTxOutSetCmd)
+
+	path := txOutSetPath(c.Path)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Unable to open file: " + err.Error(),
+		}
+	}
+	defer f.Close()
+
+	snapshot, err := s.cfg.Chain.LoadUtxoSnapshot(f)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Unable to load utxo snapshot: " + err.Error(),
+		}
+	}
+
+	return &btcjson.LoadTxOutSetResult{
+		CoinsLoaded: snapshot.NumCoins,
+		TipHash:     snapshot.BaseHash.String(),
+		BaseHeight:  snapshot.BaseHeight,
+		Path:        path,
+	}, nil
+}
+
 // handlePing implements the ping command.
 func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Ask server to ping \o_
@@ -2855,6 +3295,42 @@ func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return nil, nil
 }
 
+// handlePreciousBlock implements the preciousblock command.
+func handlePreciousBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.PreciousBlockCmd)
+
+	hash, err := knownBlockHash(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cfg.Chain.PreciousBlock(hash); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDatabase,
+			Message: "Unable to prefer block: " + err.Error(),
+		}
+	}
+
+	return nil, nil
+}
+
+// handleReconsiderBlock implements the reconsiderblock command.
+func handleReconsiderBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.ReconsiderBlockCmd)
+
+	hash, err := knownBlockHash(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cfg.Chain.ReconsiderBlock(hash); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDatabase,
+			Message: "Unable to reconsider block: " + err.Error(),
+		}
+	}
+
+	return nil, nil
+}
+
 // retrievedTx represents a transaction that was either loaded from the
 // transaction memory pool or from the database.  When a transaction is loaded
 // from the database, it is loaded with the raw serialized bytes while the
@@ -2934,6 +3410,59 @@ func fetchInputTxos(s *rpcServer, tx *wire.MsgTx) (map[wire.OutPoint]wire.TxOut,
 	return originOutputs, nil
 }
 
+// fetchSpentOutputs fetches the outputs spent by the inputs of the passed
+// transaction.  They are fetched from the transaction index when it is enabled,
+// which also provides outputs that were already spent, and otherwise from the
+// transaction memory pool and the unspent outputs of the main chain.
+func fetchSpentOutputs(s *rpcServer, tx *wire.MsgTx) (map[wire.OutPoint]*wire.TxOut, error) {
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(tx.TxIn))
+	if s.cfg.TxIndex != nil {
+		originOutputs, err := fetchInputTxos(s, tx)
+		if err != nil {
+			return nil, err
+		}
+		for outpoint, txOut := range originOutputs {
+			txOut := txOut
+			prevOuts[outpoint] = &txOut
+		}
+		return prevOuts, nil
+	}
+
+	for _, txIn := range tx.TxIn {
+		origin := txIn.PreviousOutPoint
+		originTx, err := s.cfg.TxMemPool.FetchTransaction(&origin.Hash)
+		if err == nil {
+			txOuts := originTx.MsgTx().TxOut
+			if origin.Index >= uint32(len(txOuts)) {
+				errStr := fmt.Sprintf("unable to find output "+
+					"%v referenced from transaction %s",
+					origin, tx.TxHash())
+				return nil, internalRPCError(errStr, "")
+			}
+
+			prevOuts[origin] = txOuts[origin.Index]
+			continue
+		}
+
+		entry, err := s.cfg.Chain.FetchUtxoEntry(origin)
+		if err != nil {
+			context := "Failed to fetch unspent output"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if entry == nil || entry.IsSpent() {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCNoTxInfo,
+				Message: fmt.Sprintf("No unspent output %v is "+
+					"available (enable the transaction "+
+					"index to fetch spent outputs)", origin),
+			}
+		}
+		prevOuts[origin] = wire.NewTxOut(entry.Amount(), entry.PkScript())
+	}
+
+	return prevOuts, nil
+}
+
 // createVinListPrevOut returns a slice of JSON objects for the inputs of the
 // passed transaction.
 func createVinListPrevOut(s *rpcServer, mtx *wire.MsgTx, chainParams *chaincfg.Params, vinExtra bool, filterAddrMap map[string]struct{}) ([]btcjson.VinPrevOut, error) {
@@ -3361,8 +3890,20 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 		}
 	}
 
-	// Use 0 for the tag to represent local node.
+	// Explain the standardness of the transaction when requested.
 	tx := btcutil.NewTx(&msgTx)
+	explain := c.Explain != nil && *c.Explain
+	var standardness *btcjson.StandardnessResult
+	if explain {
+		report, err := s.cfg.TxMemPool.ExplainStandardness(tx)
+		if err != nil {
+			context := "Failed to explain standardness"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		standardness = createStandardnessResult(report)
+	}
+
+	// Use 0 for the tag to represent local node.
 	acceptedTxs, err := s.cfg.TxMemPool.ProcessTransaction(tx, false, false, 0)
 	if err != nil {
 		// When the error is a rule error, it means the transaction was
@@ -3382,6 +3923,17 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 
 		rpcsLog.Debugf("Rejected transaction %v: %v", tx.Hash(), err)
 
+		// Return the reason along with the explanation of the
+		// standardness of the transaction instead of an error when
+		// the explanation was requested.
+		if explain {
+			return &btcjson.SendRawTransactionResult{
+				TxID:         tx.Hash().String(),
+				RejectReason: err.Error(),
+				Standardness: standardness,
+			}, nil
+		}
+
 		// We'll then map the rule error to the appropriate RPC error,
 		// matching bitcoind's behavior.
 		code := btcjson.ErrRPCTxError
@@ -3439,6 +3991,13 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	iv := wire.NewInvVect(wire.InvTypeTx, txD.Tx.Hash())
 	s.cfg.ConnMgr.AddRebroadcastInventory(iv, txD)
 
+	if explain {
+		return &btcjson.SendRawTransactionResult{
+			TxID:         tx.Hash().String(),
+			Accepted:     true,
+			Standardness: standardness,
+		}, nil
+	}
 	return tx.Hash().String(), nil
 }
 
@@ -3619,39 +4178,30 @@ func handleValidateAddress(s *rpcServer, cmd interface{}, closeChan <-chan struc
 	return result, nil
 }
 
-func verifyChain(s *rpcServer, level, depth int32) error {
-	best := s.cfg.Chain.BestSnapshot()
-	finishHeight := best.Height - depth
-	if finishHeight < 0 {
-		finishHeight = 0
+// verifyChain verifies the passed number of most recent blocks of the main
+// chain at the passed level and returns the report of the verification.  An
+// error is only returned when the verification could not be performed.
+func verifyChain(s *rpcServer, level, depth int32) (*blockchain.VerifyChainReport, error) {
+	// Levels outside of the supported range are clamped to it.
+	if level < 0 {
+		level = 0
+	}
+	if level > blockchain.MaxVerifyLevel {
+		level = blockchain.MaxVerifyLevel
 	}
-	rpcsLog.Infof("Verifying chain for %d blocks at level %d",
-		best.Height-finishHeight, level)
-
-	for height := best.Height; height > finishHeight; height-- {
-		// Level 0 just looks up the block.
-		block, err := s.cfg.Chain.BlockByHeight(height)
-		if err != nil {
-			rpcsLog.Errorf("Verify is unable to fetch block at "+
-				"height %d: %v", height, err)
-			return err
-		}
 
-		// Level 1 does basic chain sanity checks.
-		if level > 0 {
-			err := blockchain.CheckBlockSanity(block,
-				s.cfg.ChainParams.PowLimit, s.cfg.TimeSource)
-			if err != nil {
-				rpcsLog.Errorf("Verify is unable to validate "+
-					"block at hash %v height %d: %v",
-					block.Hash(), height, err)
-				return err
-			}
-		}
+	report, err := s.cfg.Chain.VerifyChain(level, depth)
+	if err != nil {
+		rpcsLog.Errorf("Verify is unable to check the chain: %v", err)
+		return nil, err
+	}
+	if !report.Verified() {
+		rpcsLog.Errorf("Chain verify failed: %v", report)
+		return report, nil
 	}
-	rpcsLog.Infof("Chain verify completed successfully")
+	rpcsLog.Infof("Chain verify completed successfully: %v", report)
 
-	return nil
+	return report, nil
 }
 
 // handleVerifyChain implements the verifychain command.
@@ -3666,8 +4216,21 @@ func handleVerifyChain(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 		checkDepth = *c.CheckDepth
 	}
 
-	err := verifyChain(s, checkLevel, checkDepth)
-	return err == nil, nil
+	report, err := verifyChain(s, checkLevel, checkDepth)
+	if err != nil {
+		context := "Failed to verify chain"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	// Return the report, which details every failed check, as the error
+	// when the chain failed to verify.
+	if !report.Verified() {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDatabase,
+			Message: report.String(),
+		}
+	}
+	return true, nil
 }
 
 // handleVerifyMessage implements the verifymessage command.
@@ -4657,6 +5220,16 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 
 		// Notify registered websocket clients.
 		s.ntfnMgr.NotifyBlockDisconnected(block)
+
+	case blockchain.NTChainReorg:
+		reorg, ok := notification.Data.(*blockchain.ReorgData)
+		if !ok {
+			rpcsLog.Warnf("Chain reorg notification is not reorg data.")
+			break
+		}
+
+		// Notify registered websocket clients.
+		s.ntfnMgr.NotifyChainReorg(reorg)
 	}
 }
 
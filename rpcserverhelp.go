@@ -94,15 +94,68 @@ var helpDescsEnUS = map[string]string{
 	"vout-scriptPubKey": "The public key script used to pay coins as a JSON object",
 
 	// TxRawDecodeResult help.
-	"txrawdecoderesult-txid":     "The hash of the transaction",
-	"txrawdecoderesult-version":  "The transaction version",
-	"txrawdecoderesult-locktime": "The transaction lock time",
-	"txrawdecoderesult-vin":      "The transaction inputs as JSON objects",
-	"txrawdecoderesult-vout":     "The transaction outputs as JSON objects",
+	"txrawdecoderesult-txid":         "The hash of the transaction",
+	"txrawdecoderesult-version":      "The transaction version",
+	"txrawdecoderesult-locktime":     "The transaction lock time",
+	"txrawdecoderesult-vin":          "The transaction inputs as JSON objects",
+	"txrawdecoderesult-vout":         "The transaction outputs as JSON objects",
+	"txrawdecoderesult-standardness": "The results of the policy rules which determine whether the transaction is standard (only when explain is true)",
+
+	// StandardnessResult help.
+	"standardnessresult-standard": "Whether the transaction satisfies all of the evaluated policy rules",
+	"standardnessresult-rules":    "The results of the policy rules which apply to the transaction as a whole",
+	"standardnessresult-vin":      "The results of the policy rules which apply to the transaction inputs",
+	"standardnessresult-vout":     "The results of the policy rules which apply to the transaction outputs",
+
+	// StandardnessRuleResult help.
+	"standardnessruleresult-rule":       "The evaluated policy rule (version, finalized, weight, nulldata, sigopcost, sigscriptsize, sigscriptpushonly, prevscriptclass, p2shsigops, scriptclass or dust)",
+	"standardnessruleresult-passed":     "Whether the transaction satisfies the rule",
+	"standardnessruleresult-rejectcode": "The code the transaction is rejected with due to the rule (only when the rule is not satisfied)",
+	"standardnessruleresult-reason":     "The description of the violation of the rule (only when the rule is not satisfied)",
+
+	// StandardnessVinResult help.
+	"standardnessvinresult-n":              "The index of the input",
+	"standardnessvinresult-prevoutmissing": "Whether the output spent by the input is unknown, in which case the rules depending on it are not evaluated",
+	"standardnessvinresult-rules":          "The results of the policy rules evaluated against the input",
+
+	// StandardnessVoutResult help.
+	"standardnessvoutresult-n":     "The index of the output",
+	"standardnessvoutresult-rules": "The results of the policy rules evaluated against the output",
 
 	// DecodeRawTransactionCmd help.
 	"decoderawtransaction--synopsis": "Returns a JSON object representing the provided serialized, hex-encoded transaction.",
 	"decoderawtransaction-hextx":     "Serialized, hex-encoded transaction",
+	"decoderawtransaction-explain":   "Evaluate every policy rule which determines whether the transaction is standard and include the results",
+
+	// DebugScriptCmd help.
+	"debugscript--synopsis": "Executes the scripts of a transaction input against the output it spends with the flags used for standard transactions and returns a trace of every step.\n" +
+		"The spent outputs are fetched from the transaction index when it is enabled and otherwise from the memory pool and the unspent outputs of the main chain.",
+	"debugscript-hextx": "Serialized, hex-encoded transaction",
+	"debugscript-vin":   "The index of the input whose scripts to execute",
+
+	// DebugScriptSigCheck help.
+	"debugscriptsigcheck-pubkey":    "The hex-encoded public key the signature was checked against",
+	"debugscriptsigcheck-signature": "The hex-encoded signature including its hash type, if any",
+	"debugscriptsigcheck-valid":     "Whether the signature is valid",
+
+	// DebugScriptStep help.
+	"debugscriptstep-script":    "The index of the executed script (0 is the signature script and 1 the public key script, followed by the redeem or witness script, if any)",
+	"debugscriptstep-index":     "The index of the executed opcode within its script",
+	"debugscriptstep-opcode":    "The disassembly of the executed opcode (omitted when no opcode could be executed)",
+	"debugscriptstep-stack":     "The hex-encoded items of the data stack after the step, where the last item is the top of the stack",
+	"debugscriptstep-altstack":  "The hex-encoded items of the alternate stack after the step, where the last item is the top of the stack",
+	"debugscriptstep-condstack": "The conditional execution stack after the step ('true', 'false' or 'skip'), where the last item is the innermost conditional",
+	"debugscriptstep-sigchecks": "The signature checks performed by the step",
+	"debugscriptstep-error":     "The reason the step failed, if it did",
+
+	// DebugScriptResult help.
+	"debugscriptresult-txid":         "The hash of the transaction",
+	"debugscriptresult-vin":          "The index of the input whose scripts were executed",
+	"debugscriptresult-scriptpubkey": "The hex-encoded public key script of the spent output",
+	"debugscriptresult-amount":       "The amount of the spent output in BTC",
+	"debugscriptresult-valid":        "Whether the scripts executed successfully",
+	"debugscriptresult-error":        "The reason the execution failed, if it did",
+	"debugscriptresult-steps":        "The steps of the execution in order",
 
 	// DecodeScriptResult help.
 	"decodescriptresult-asm":       "Disassembly of the script",
@@ -115,6 +168,18 @@ var helpDescsEnUS = map[string]string{
 	"decodescript--synopsis": "Returns a JSON object with information about the provided hex-encoded script.",
 	"decodescript-hexscript": "Hex-encoded script",
 
+	// DumpTxOutSetCmd help.
+	"dumptxoutset--synopsis": "Writes a snapshot of the unspent transaction output set at the current best block to a file.",
+	"dumptxoutset-path":      "Path of the snapshot file, relative to the data directory when not absolute",
+
+	// DumpTxOutSetResult help.
+	"dumptxoutsetresult-coins_written": "The number of unspent transaction outputs written",
+	"dumptxoutsetresult-base_hash":     "The hash of the block the snapshot was taken at",
+	"dumptxoutsetresult-base_height":   "The height of the block the snapshot was taken at",
+	"dumptxoutsetresult-path":          "The absolute path of the snapshot file",
+	"dumptxoutsetresult-txoutset_hash": "The hash of the unspent transaction outputs in the snapshot",
+	"dumptxoutsetresult-nchaintx":      "The number of transactions in the chain up to and including the snapshot block",
+
 	// EstimateFeeCmd help.
 	"estimatefee--synopsis": "Estimate the fee per kilobyte in satoshis " +
 		"required for a transaction to be mined before a certain number of " +
@@ -359,6 +424,15 @@ var helpDescsEnUS = map[string]string{
 	"getcfilterheader-hash":       "The hash of the block",
 	"getcfilterheader--result0":   "The block's gcs filter header",
 
+	// GetChainTipsCmd help.
+	"getchaintips--synopsis": "Returns information about the tips of the main chain and of all known side chains.",
+
+	// GetChainTipsResult help.
+	"getchaintipsresult-height":    "The height of the chain tip",
+	"getchaintipsresult-hash":      "The hash of the chain tip",
+	"getchaintipsresult-branchlen": "The number of blocks between the tip and the main chain (0 for the main chain tip)",
+	"getchaintipsresult-status":    "The status of the branch (active, valid-fork, valid-headers, headers-only or invalid)",
+
 	// GetConnectionCountCmd help.
 	"getconnectioncount--synopsis": "Returns the number of active connections to other peers.",
 	"getconnectioncount--result0":  "The number of connections",
@@ -367,6 +441,44 @@ var helpDescsEnUS = map[string]string{
 	"getcurrentnet--synopsis": "Get bitcoin network the server is running on.",
 	"getcurrentnet--result0":  "The network identifer",
 
+	// GetDeploymentInfoCmd help.
+	"getdeploymentinfo--synopsis": "Returns the state of all consensus rule change deployments as of a block along with the signalling statistics of their current period.",
+	"getdeploymentinfo-blockhash": "The hash of the block to report the state as of (default: the best block)",
+
+	// GetDeploymentInfoResult help.
+	"getdeploymentinforesult-hash":               "The hash of the block the state is reported as of",
+	"getdeploymentinforesult-height":             "The height of the block the state is reported as of",
+	"getdeploymentinforesult-deployments":        "JSON object describing each deployment",
+	"getdeploymentinforesult-deployments--key":   "name",
+	"getdeploymentinforesult-deployments--value": "An object describing the deployment",
+	"getdeploymentinforesult-deployments--desc":  "The state of each defined deployment keyed by its name",
+
+	// DeploymentInfo help.
+	"deploymentinfo-type":   "The activation mechanism of the deployment (bip9 for time-based or bip8 for height-based deployments)",
+	"deploymentinfo-active": "Whether the rules of the deployment are enforced for the next block",
+	"deploymentinfo-height": "The height of the first block the rules of the deployment are enforced for (only when active)",
+	"deploymentinfo-bip9":   "The version bits details of the deployment",
+
+	// Bip9DeploymentInfo help.
+	"bip9deploymentinfo-bit":                   "The bit of the block version which signals for the deployment",
+	"bip9deploymentinfo-start_time":            "The median block time voting on a time-based deployment starts at",
+	"bip9deploymentinfo-timeout":               "The median block time a time-based deployment expires at",
+	"bip9deploymentinfo-start_height":          "The height voting on a height-based deployment starts at",
+	"bip9deploymentinfo-timeout_height":        "The height a height-based deployment times out at",
+	"bip9deploymentinfo-lockinontimeout":       "Whether blocks must signal for a height-based deployment before its timeout to lock it in",
+	"bip9deploymentinfo-min_activation_height": "The height before which the deployment can't become active",
+	"bip9deploymentinfo-status":                "The state of the deployment for the block (defined, started, must_signal, lockedin, active or failed)",
+	"bip9deploymentinfo-since":                 "The height of the first block with the same state",
+	"bip9deploymentinfo-status_next":           "The state of the deployment for the next block",
+	"bip9deploymentinfo-statistics":            "The signalling statistics of the current period (only when started or must_signal)",
+
+	// DeploymentStatistics help.
+	"deploymentstatistics-period":    "The number of blocks in each period",
+	"deploymentstatistics-threshold": "The number of blocks in a period which must signal to lock in the deployment",
+	"deploymentstatistics-elapsed":   "The number of blocks of the current period up to and including the block",
+	"deploymentstatistics-count":     "The number of the elapsed blocks which signal for the deployment",
+	"deploymentstatistics-possible":  "Whether the deployment can still be locked in at the end of the current period",
+
 	// GetDifficultyCmd help.
 	"getdifficulty--synopsis": "Returns the proof-of-work difficulty as a multiple of the minimum difficulty.",
 	"getdifficulty--result0":  "The difficulty",
@@ -533,6 +645,20 @@ var helpDescsEnUS = map[string]string{
 	"gettxout-vout":           "The index of the output",
 	"gettxout-includemempool": "Include the mempool when true",
 
+	// GetTxOutSetInfoCmd help.
+	"gettxoutsetinfo--synopsis": "Returns statistics about the unspent transaction output set.",
+
+	// GetTxOutSetInfoResult help.
+	"gettxoutsetinforesult-height":            "The height of the best block the statistics are for",
+	"gettxoutsetinforesult-bestblock":         "The hash of the best block the statistics are for",
+	"gettxoutsetinforesult-transactions":      "Not calculated and always omitted",
+	"gettxoutsetinforesult-txouts":            "The number of unspent transaction outputs",
+	"gettxoutsetinforesult-bogosize":          "A database independent measure of the size of the unspent transaction output set",
+	"gettxoutsetinforesult-hash_serialized_2": "Not calculated and always omitted",
+	"gettxoutsetinforesult-muhash":            "The rolling MuHash3072 of the unspent transaction output set",
+	"gettxoutsetinforesult-disk_size":         "The size of the unspent transaction output set serialized in the database format",
+	"gettxoutsetinforesult-total_amount":      "The total amount of all unspent transaction outputs in BTC",
+
 	// HelpCmd help.
 	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
 	"help-command":     "The command to retrieve help for",
@@ -541,10 +667,34 @@ var helpDescsEnUS = map[string]string{
 	"help--result0":    "List of commands",
 	"help--result1":    "Help for specified command",
 
+	// InvalidateBlockCmd help.
+	"invalidateblock--synopsis": "Permanently marks a block as invalid, as if it violated a consensus rule, and reorganizes the chain away from it.",
+	"invalidateblock-blockhash": "The hash of the block to mark as invalid",
+
+	// LoadTxOutSetCmd help.
+	"loadtxoutset--synopsis": "Loads a snapshot of the unspent transaction output set committed to by the chain parameters into an empty chain.  " +
+		"The blocks below the snapshot are downloaded and validated against it in the background.  " +
+		"Only the regression test network commits to a snapshot, so it can't be used on the other networks.",
+	"loadtxoutset-path": "Path of the snapshot file, relative to the data directory when not absolute",
+
+	// LoadTxOutSetResult help.
+	"loadtxoutsetresult-coins_loaded": "The number of unspent transaction outputs loaded",
+	"loadtxoutsetresult-tip_hash":     "The hash of the snapshot block which is now the best block",
+	"loadtxoutsetresult-base_height":  "The height of the snapshot block",
+	"loadtxoutsetresult-path":         "The absolute path of the snapshot file",
+
+	// PreciousBlockCmd help.
+	"preciousblock--synopsis": "Treats a block as if it was received before other blocks with the same work, making it the tip of the chain when it has as much work as the current tip.",
+	"preciousblock-blockhash": "The hash of the block to mark as precious",
+
 	// PingCmd help.
 	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
 		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
 
+	// ReconsiderBlockCmd help.
+	"reconsiderblock--synopsis": "Removes the invalid status of a block and its ancestors and descendants, reorganizing the chain to the chain with the most work.",
+	"reconsiderblock-blockhash": "The hash of the block to reconsider",
+
 	// SearchRawTransactionsCmd help.
 	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
 		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
@@ -566,9 +716,18 @@ var helpDescsEnUS = map[string]string{
 	"sendrawtransaction--synopsis":    "Submits the serialized, hex-encoded transaction to the local peer and relays it to the network.",
 	"sendrawtransaction-hextx":        "Serialized, hex-encoded signed transaction",
 	"sendrawtransaction-feesetting":   "Whether or not to allow insanely high fees in bitcoind < v0.19.0 or the max fee rate for bitcoind v0.19.0 and later (btcd does not yet implement this parameter, so it has no effect)",
+	"sendrawtransaction-explain":      "Evaluate every policy rule which determines whether the transaction is standard and return the results along with whether it was accepted instead of the hash of the transaction",
+	"sendrawtransaction--condition0":  "explain=false",
+	"sendrawtransaction--condition1":  "explain=true",
 	"sendrawtransaction--result0":     "The hash of the transaction",
 	"allowhighfeesormaxfeerate-value": "Either the boolean value for the allowhighfees parameter in bitcoind < v0.19.0 or the numerical value for the maxfeerate field in bitcoind v0.19.0 and later",
 
+	// SendRawTransactionResult help.
+	"sendrawtransactionresult-txid":         "The hash of the transaction",
+	"sendrawtransactionresult-accepted":     "Whether the transaction was accepted into the memory pool",
+	"sendrawtransactionresult-rejectreason": "The reason the transaction was rejected (only when it was not accepted)",
+	"sendrawtransactionresult-standardness": "The results of the policy rules which determine whether the transaction is standard",
+
 	// SetGenerateCmd help.
 	"setgenerate--synopsis":    "Set the server to generate coins (mine) or not.",
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
@@ -612,9 +771,14 @@ var helpDescsEnUS = map[string]string{
 		"The actual checks performed by the checklevel parameter are implementation specific.\n" +
 		"For btcd this is:\n" +
 		"checklevel=0 - Look up each block and ensure it can be loaded from the database.\n" +
-		"checklevel=1 - Perform basic context-free sanity checks on each block.",
+		"checklevel=1 - Perform basic context-free sanity checks on each block.\n" +
+		"checklevel=2 - Ensure the spend journal entry of each block is consistent with it.\n" +
+		"checklevel=3 - Disconnect the blocks from the tip in memory, which requires them to be consistent with the utxo set.\n" +
+		"checklevel=4 - Connect the disconnected blocks again with full validation.\n" +
+		"Levels 3 and 4 stop disconnecting blocks once they would exceed the size of the utxo cache in memory, and only the lower levels are checked for older blocks.\n" +
+		"A chain which fails to verify results in an error detailing every failed check.",
 	"verifychain-checklevel": "How thorough the block verification is",
-	"verifychain-checkdepth": "The number of blocks to check",
+	"verifychain-checkdepth": "The number of blocks to check, 0 checks all blocks",
 	"verifychain--result0":   "Whether or not the chain verified",
 
 	// VerifyMessageCmd help.
@@ -715,8 +879,10 @@ var rpcResultTypes = map[string][]interface{}{
 	"addnode":                nil,
 	"createrawtransaction":   {(*string)(nil)},
 	"debuglevel":             {(*string)(nil), (*string)(nil)},
+	"debugscript":            {(*btcjson.DebugScriptResult)(nil)},
 	"decoderawtransaction":   {(*btcjson.TxRawDecodeResult)(nil)},
 	"decodescript":           {(*btcjson.DecodeScriptResult)(nil)},
+	"dumptxoutset":           {(*btcjson.DumpTxOutSetResult)(nil)},
 	"estimatefee":            {(*float64)(nil)},
 	"generate":               {(*[]string)(nil)},
 	"getaddednodeinfo":       {(*[]string)(nil), (*[]btcjson.GetAddedNodeInfoResult)(nil)},
@@ -730,8 +896,10 @@ var rpcResultTypes = map[string][]interface{}{
 	"getblockchaininfo":      {(*btcjson.GetBlockChainInfoResult)(nil)},
 	"getcfilter":             {(*string)(nil)},
 	"getcfilterheader":       {(*string)(nil)},
+	"getchaintips":           {(*[]btcjson.GetChainTipsResult)(nil)},
 	"getconnectioncount":     {(*int32)(nil)},
 	"getcurrentnet":          {(*uint32)(nil)},
+	"getdeploymentinfo":      {(*btcjson.GetDeploymentInfoResult)(nil)},
 	"getdifficulty":          {(*float64)(nil)},
 	"getgenerate":            {(*bool)(nil)},
 	"gethashespersec":        {(*float64)(nil)},
@@ -746,11 +914,16 @@ var rpcResultTypes = map[string][]interface{}{
 	"getrawmempool":          {(*[]string)(nil), (*btcjson.GetRawMempoolVerboseResult)(nil)},
 	"getrawtransaction":      {(*string)(nil), (*btcjson.TxRawResult)(nil)},
 	"gettxout":               {(*btcjson.GetTxOutResult)(nil)},
+	"gettxoutsetinfo":        {(*btcjson.GetTxOutSetInfoResult)(nil)},
 	"node":                   nil,
 	"help":                   {(*string)(nil), (*string)(nil)},
+	"invalidateblock":        nil,
+	"loadtxoutset":           {(*btcjson.LoadTxOutSetResult)(nil)},
 	"ping":                   nil,
+	"preciousblock":          nil,
+	"reconsiderblock":        nil,
 	"searchrawtransactions":  {(*string)(nil), (*[]btcjson.SearchRawTransactionsResult)(nil)},
-	"sendrawtransaction":     {(*string)(nil)},
+	"sendrawtransaction":     {(*string)(nil), (*btcjson.SendRawTransactionResult)(nil)},
 	"setgenerate":            nil,
 	"signmessagewithprivkey": {(*string)(nil)},
 	"stop":                   {(*string)(nil)},
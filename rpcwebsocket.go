@@ -226,6 +226,19 @@ func (m *wsNotificationManager) NotifyBlockDisconnected(block *btcutil.Block) {
 	}
 }
 
+// NotifyChainReorg passes a reorganization of the best chain to the
+// notification manager for block notification processing.
+func (m *wsNotificationManager) NotifyChainReorg(reorg *blockchain.ReorgData) {
+	// As NotifyChainReorg will be called by the block manager
+	// and the RPC server may no longer be running, use a select
+	// statement to unblock enqueuing the notification once the RPC
+	// server has begun shutting down.
+	select {
+	case m.queueNotification <- (*notificationChainReorg)(reorg):
+	case <-m.quit:
+	}
+}
+
 // NotifyMempoolTx passes a transaction accepted by mempool to the
 // notification manager for transaction notification processing.  If
 // isNew is true, the tx is is a new transaction, rather than one
@@ -447,6 +460,7 @@ func (f *wsClientFilter) removeUnspentOutPoint(op *wire.OutPoint) {
 // Notification types
 type notificationBlockConnected btcutil.Block
 type notificationBlockDisconnected btcutil.Block
+type notificationChainReorg blockchain.ReorgData
 type notificationTxAcceptedByMempool struct {
 	isNew bool
 	tx    *btcutil.Tx
@@ -532,6 +546,12 @@ out:
 						block)
 				}
 
+			case *notificationChainReorg:
+				if len(blockNotifications) != 0 {
+					m.notifyChainReorg(blockNotifications,
+						(*blockchain.ReorgData)(n))
+				}
+
 			case *notificationTxAcceptedByMempool:
 				if n.isNew && len(txNotifications) != 0 {
 					m.notifyForNewTx(txNotifications, n.tx)
@@ -730,6 +750,43 @@ func (*wsNotificationManager) notifyBlockDisconnected(clients map[chan struct{}]
 	}
 }
 
+// notifyChainReorg notifies websocket clients that have registered for block
+// updates when the main chain is reorganized.  The notification is sent before
+// the notifications for the individual disconnected and connected blocks unless
+// the reorganization was performed in steps by invalidating, reconsidering or
+// preferring blocks.
+func (*wsNotificationManager) notifyChainReorg(clients map[chan struct{}]*wsClient,
+	reorg *blockchain.ReorgData) {
+
+	// Skip notification creation if no clients have requested block
+	// connected/disconnected notifications.
+	if len(clients) == 0 {
+		return
+	}
+
+	detached := make([]string, 0, len(reorg.DetachedHashes))
+	for i := range reorg.DetachedHashes {
+		detached = append(detached, reorg.DetachedHashes[i].String())
+	}
+	attached := make([]string, 0, len(reorg.AttachedHashes))
+	for i := range reorg.AttachedHashes {
+		attached = append(attached, reorg.AttachedHashes[i].String())
+	}
+
+	// Notify interested websocket clients about the reorganization.
+	ntfn := btcjson.NewChainReorgNtfn(reorg.ForkHash.String(),
+		reorg.ForkHeight, detached, attached)
+	marshalledJSON, err := btcjson.MarshalCmd(btcjson.RpcVersion1, nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal chain reorg notification: "+
+			"%v", err)
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
 // notifyFilteredBlockConnected notifies websocket clients that have registered for
 // block updates when a block is connected to the main chain.
 func (m *wsNotificationManager) notifyFilteredBlockConnected(clients map[chan struct{}]*wsClient,
@@ -138,6 +138,14 @@ type relayMsg struct {
 	data    interface{}
 }
 
+// misbehavingPeerMsg is a message sent from the sync manager to the server to
+// ban a peer which violated the consensus rules, such as by sending a block
+// header with invalid proof of work.
+type misbehavingPeerMsg struct {
+	peer   *peer.Peer
+	reason string
+}
+
 // updatePeerHeightsMsg is a message sent from the blockmanager to the server
 // after a new block has been accepted. The purpose of the message is to update
 // the heights of peers that were known to announce the block before we
@@ -219,6 +227,7 @@ type server struct {
 	newPeers             chan *serverPeer
 	donePeers            chan *serverPeer
 	banPeers             chan *serverPeer
+	misbehavingPeers     chan misbehavingPeerMsg
 	query                chan interface{}
 	relayInv             chan relayMsg
 	broadcast            chan broadcastMsg
@@ -1471,8 +1480,13 @@ func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan cha
 		return err
 	})
 	if err != nil {
-		peerLog.Tracef("Unable to fetch requested block hash %v: %v",
-			hash, err)
+		if sp.server.chain.IsBlockPruned(hash) {
+			peerLog.Debugf("Unable to serve requested block %v to "+
+				"%v: the block has been pruned", hash, sp)
+		} else {
+			peerLog.Tracef("Unable to fetch requested block hash "+
+				"%v: %v", hash, err)
+		}
 
 		if doneChan != nil {
 			doneChan <- struct{}{}
@@ -1542,8 +1556,13 @@ func (s *server) pushMerkleBlockMsg(sp *serverPeer, hash *chainhash.Hash,
 	// Fetch the raw block bytes from the database.
 	blk, err := sp.server.chain.BlockByHash(hash)
 	if err != nil {
-		peerLog.Tracef("Unable to fetch requested block hash %v: %v",
-			hash, err)
+		if blockchain.IsPrunedBlockErr(err) {
+			peerLog.Debugf("Unable to serve requested merkle block "+
+				"to %v: %v", sp, err)
+		} else {
+			peerLog.Tracef("Unable to fetch requested block hash "+
+				"%v: %v", hash, err)
+		}
 
 		if doneChan != nil {
 			doneChan <- struct{}{}
@@ -1768,6 +1787,32 @@ func (s *server) handleBanPeerMsg(state *peerState, sp *serverPeer) {
 	state.banned[host] = time.Now().Add(cfg.BanDuration)
 }
 
+// handleMisbehavingPeerMsg deals with banning and disconnecting a peer reported
+// by the sync manager for violating the consensus rules.  It is invoked from
+// the peerHandler goroutine.
+func (s *server) handleMisbehavingPeerMsg(state *peerState, msg misbehavingPeerMsg) {
+	state.forAllPeers(func(sp *serverPeer) {
+		if sp.Peer != msg.peer {
+			return
+		}
+
+		// The peer is disconnected regardless of whether banning is
+		// enabled.
+		switch {
+		case cfg.DisableBanning:
+			peerLog.Warnf("Misbehaving peer %s: %s", sp, msg.reason)
+		case sp.isWhitelisted:
+			peerLog.Debugf("Misbehaving whitelisted peer %s: %s", sp,
+				msg.reason)
+		default:
+			peerLog.Warnf("Misbehaving peer %s: %s -- banning and "+
+				"disconnecting", sp, msg.reason)
+			s.handleBanPeerMsg(state, sp)
+		}
+		sp.Disconnect()
+	})
+}
+
 // handleRelayInvMsg deals with relaying inventory to peers that are not already
 // known to have it.  It is invoked from the peerHandler goroutine.
 func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
@@ -2182,6 +2227,10 @@ out:
 		case p := <-s.banPeers:
 			s.handleBanPeerMsg(state, p)
 
+		// Peer reported by the sync manager to ban.
+		case msg := <-s.misbehavingPeers:
+			s.handleMisbehavingPeerMsg(state, msg)
+
 		// New inventory to potentially be relayed to other peers.
 		case invMsg := <-s.relayInv:
 			s.handleRelayInvMsg(state, invMsg)
@@ -2208,6 +2257,12 @@ out:
 	s.syncManager.Stop()
 	s.addrManager.Stop()
 
+	// Write the UTXO cache to the database now that no more blocks will be
+	// connected so the next start doesn't need to reapply any blocks.
+	if err := s.chain.FlushUtxoCache(blockchain.FlushRequired); err != nil {
+		srvrLog.Errorf("Unable to flush the UTXO cache: %v", err)
+	}
+
 	// Drain channels before exiting so nothing is left waiting around
 	// to send.
 cleanup:
@@ -2216,6 +2271,7 @@ cleanup:
 		case <-s.newPeers:
 		case <-s.donePeers:
 		case <-s.peerHeightsUpdate:
+		case <-s.misbehavingPeers:
 		case <-s.relayInv:
 		case <-s.broadcast:
 		case <-s.query:
@@ -2237,6 +2293,12 @@ func (s *server) BanPeer(sp *serverPeer) {
 	s.banPeers <- sp
 }
 
+// BanMisbehavingPeer bans and disconnects the passed peer for the passed reason
+// after it violated the consensus rules.
+func (s *server) BanMisbehavingPeer(p *peer.Peer, reason string) {
+	s.misbehavingPeers <- misbehavingPeerMsg{peer: p, reason: reason}
+}
+
 // RelayInventory relays the passed inventory vector to all connected peers
 // that are not already known to have it.
 func (s *server) RelayInventory(invVect *wire.InvVect, data interface{}) {
@@ -2625,6 +2687,23 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	db database.DB, chainParams *chaincfg.Params,
 	interrupt <-chan struct{}) (*server, error) {
 
+	// A database which has been pruned no longer contains the full chain,
+	// so pruning can't be disabled for it.
+	var beenPruned bool
+	if err := db.View(func(dbTx database.Tx) error {
+		var err error
+		beenPruned, err = dbTx.BeenPruned()
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if beenPruned && cfg.Prune == 0 {
+		return nil, fmt.Errorf("the block database in %s has been "+
+			"pruned, so the --prune option can't be disabled -- "+
+			"delete the database and sync again to keep all blocks",
+			cfg.DataDir)
+	}
+
 	services := defaultServices
 	if cfg.NoPeerBloomFilters {
 		services &^= wire.SFNodeBloom
@@ -2632,6 +2711,12 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	if cfg.NoCFilters {
 		services &^= wire.SFNodeCF
 	}
+	if cfg.Prune != 0 {
+		// Pruned nodes only keep the most recent blocks, so they can
+		// only signal to serve those.
+		services &^= wire.SFNodeNetwork
+		services |= wire.SFNodeNetworkLimited
+	}
 
 	amgr := addrmgr.New(cfg.DataDir, btcdLookup)
 
@@ -2661,6 +2746,7 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		newPeers:             make(chan *serverPeer, cfg.MaxPeers),
 		donePeers:            make(chan *serverPeer, cfg.MaxPeers),
 		banPeers:             make(chan *serverPeer, cfg.MaxPeers),
+		misbehavingPeers:     make(chan misbehavingPeerMsg, cfg.MaxPeers),
 		query:                make(chan interface{}),
 		relayInv:             make(chan relayMsg, cfg.MaxPeers),
 		broadcast:            make(chan broadcastMsg, cfg.MaxPeers),
@@ -2725,14 +2811,21 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	// Create a new block chain instance with the appropriate configuration.
 	var err error
 	s.chain, err = blockchain.New(&blockchain.Config{
-		DB:           s.db,
-		Interrupt:    interrupt,
-		ChainParams:  s.chainParams,
-		Checkpoints:  checkpoints,
-		TimeSource:   s.timeSource,
-		SigCache:     s.sigCache,
-		IndexManager: indexManager,
-		HashCache:    s.hashCache,
+		DB:                     s.db,
+		Interrupt:              interrupt,
+		ChainParams:            s.chainParams,
+		Checkpoints:            checkpoints,
+		TimeSource:             s.timeSource,
+		SigCache:               s.sigCache,
+		IndexManager:           indexManager,
+		HashCache:              s.hashCache,
+		UtxoCacheMaxSize:       uint64(cfg.UtxoCacheMaxSizeMiB) * 1024 * 1024,
+		UtxoCacheFlushInterval: cfg.UtxoFlushInterval,
+		PruneTarget:            cfg.Prune * 1024 * 1024,
+		AssumeValid:            cfg.assumeValid,
+		VerifyLevel:            cfg.CheckLevel,
+		VerifyDepth:            cfg.CheckBlocks,
+		ReindexChainState:      cfg.ReindexChainState,
 	})
 	if err != nil {
 		return nil, err
@@ -2808,6 +2901,12 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		return nil, err
 	}
 
+	// Signal process shutdown when the sync manager requests it.
+	go func() {
+		<-s.syncManager.RequestedProcessShutdown()
+		shutdownRequestChannel <- struct{}{}
+	}()
+
 	// Create the mining policy and block template generator based on the
 	// configuration options.
 	//
@@ -55,7 +55,7 @@ func BenchmarkCalcSigHash(b *testing.B) {
 // BenchmarkCalcWitnessSigHash benchmarks how long it takes to calculate the
 // witness signature hashes for all inputs of a transaction with many inputs.
 func BenchmarkCalcWitnessSigHash(b *testing.B) {
-	sigHashes := NewTxSigHashes(&manyInputsBenchTx)
+	sigHashes := NewTxSigHashes(&manyInputsBenchTx, nil)
 
 	b.ResetTimer()
 	b.ReportAllocs()
@@ -13,6 +13,8 @@ import (
 	"strings"
 
 	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 )
 
@@ -95,6 +97,26 @@ const (
 	// operation whose public key isn't serialized in a compressed format
 	// non-standard.
 	ScriptVerifyWitnessPubKeyType
+
+	// ScriptVerifyTaproot defines whether or not to verify a transaction
+	// output using the new taproot validation rules, that is key path and
+	// script path spends of version 1 witness programs as defined in
+	// BIP0341 and the tapscript semantics defined in BIP0342.
+	ScriptVerifyTaproot
+
+	// ScriptVerifyDiscourageUpgradeableTaprootVersion defines whether or
+	// not to consider script path spends revealing a script with an unknown
+	// leaf version non-standard.
+	ScriptVerifyDiscourageUpgradeableTaprootVersion
+
+	// ScriptVerifyDiscourageOpSuccess defines whether or not to consider
+	// tapscripts containing an OP_SUCCESSx opcode non-standard.
+	ScriptVerifyDiscourageOpSuccess
+
+	// ScriptVerifyDiscourageUpgradeablePubkeyType defines whether or not to
+	// consider signature checks within a tapscript against a public key of
+	// unknown type non-standard.
+	ScriptVerifyDiscourageUpgradeablePubkeyType
 )
 
 const (
@@ -112,11 +134,38 @@ const (
 	// payToWitnessScriptHashDataSize is the size of the witness program's
 	// data push for a pay-to-witness-script-hash output.
 	payToWitnessScriptHashDataSize = 32
+
+	// payToTaprootDataSize is the size of the witness program's data push
+	// for a pay-to-taproot output.
+	payToTaprootDataSize = 32
 )
 
 // halforder is used to tame ECDSA malleability (see BIP0062).
 var halfOrder = new(big.Int).Rsh(btcec.S256().N, 1)
 
+// taprootExecutionCtx houses the state specific to the validation of a spend of
+// a taproot output.
+type taprootExecutionCtx struct {
+	// annex is the annex of the witness, if any.
+	annex []byte
+
+	// codeSepPos is the position of the opcode of the last executed
+	// OP_CODESEPARATOR of the tapscript being executed.
+	codeSepPos uint32
+
+	// tapLeafHash is the hash of the leaf of the tapscript being executed.
+	tapLeafHash chainhash.Hash
+
+	// sigOpsBudget is the remaining budget for signature checks of the
+	// tapscript being executed.
+	sigOpsBudget int32
+
+	// mustSucceed is set when the spend is valid without executing any
+	// further scripts, such as after a valid key path spend or when the
+	// revealed script contains an OP_SUCCESSx opcode.
+	mustSucceed bool
+}
+
 // Engine is the virtual machine that executes scripts.
 type Engine struct {
 	// The following fields are set when the engine is created and must not be
@@ -145,13 +194,17 @@ type Engine struct {
 	// since transaction scripts are often executed more than once from various
 	// contexts (e.g. new block templates, when transactions are first seen
 	// prior to being mined, part of full block verification, etc).
-	flags     ScriptFlags
-	tx        wire.MsgTx
-	txIdx     int
-	version   uint16
-	bip16     bool
-	sigCache  *SigCache
-	hashCache *TxSigHashes
+	//
+	// prevOutFetcher provides the outputs spent by all inputs of the
+	// transaction, which taproot signatures commit to.
+	flags          ScriptFlags
+	tx             wire.MsgTx
+	txIdx          int
+	version        uint16
+	bip16          bool
+	sigCache       *SigCache
+	hashCache      *TxSigHashes
+	prevOutFetcher PrevOutputFetcher
 
 	// The following fields handle keeping track of the current execution state
 	// of the engine.
@@ -187,6 +240,13 @@ type Engine struct {
 	//
 	// numOps tracks the total number of non-push operations in a script and is
 	// primarily used to enforce maximum limits.
+	//
+	// taprootCtx houses the state of a taproot spend.  It is only set once
+	// the witness of a taproot spend is validated, so the script executed
+	// afterwards is always a tapscript.
+	//
+	// tracer is invoked after each step when set, and stepTrace houses the
+	// trace of the step being executed while tracing.
 	scripts         [][]byte
 	scriptIdx       int
 	opcodeIdx       int
@@ -200,6 +260,9 @@ type Engine struct {
 	witnessVersion  int
 	witnessProgram  []byte
 	inputAmount     int64
+	taprootCtx      *taprootExecutionCtx
+	tracer          Tracer
+	stepTrace       *StepTrace
 }
 
 // hasFlag returns whether the script engine instance has the passed flag set.
@@ -289,6 +352,25 @@ func isOpcodeConditional(opcode byte) bool {
 	}
 }
 
+// isOpSuccess returns whether or not the opcode is one of the OP_SUCCESSx
+// opcodes defined in BIP0342, which make a tapscript succeed without executing
+// it.  They are reserved for introducing new opcodes with soft forks.
+func isOpSuccess(opcode byte) bool {
+	switch {
+	case opcode == 80, opcode == 98,
+		opcode >= 126 && opcode <= 129,
+		opcode >= 131 && opcode <= 134,
+		opcode >= 137 && opcode <= 138,
+		opcode >= 141 && opcode <= 142,
+		opcode >= 149 && opcode <= 153,
+		opcode >= 187 && opcode <= 254:
+
+		return true
+	default:
+		return false
+	}
+}
+
 // checkMinimalDataPush returns whether or not the provided opcode is the
 // smallest possible way to represent the given data.  For example, the value 15
 // could be pushed with OP_DATA_1 15 (among other variations); however, OP_15 is
@@ -355,7 +437,8 @@ func (vm *Engine) executeOpcode(op *opcode, data []byte) error {
 	}
 
 	// Note that this includes OP_RESERVED which counts as a push operation.
-	if op.value > OP_16 {
+	// Tapscripts are not limited in the number of operations.
+	if op.value > OP_16 && vm.taprootCtx == nil {
 		vm.numOps++
 		if vm.numOps > MaxOpsPerScript {
 			str := fmt.Sprintf("exceeded max operation limit of %d",
@@ -488,6 +571,10 @@ func (vm *Engine) verifyWitnessProgram(witness [][]byte) error {
 				len(vm.witnessProgram))
 			return scriptError(ErrWitnessProgramWrongLength, errStr)
 		}
+	} else if vm.isTaprootSpend() {
+		if err := vm.verifyTaprootSpend(witness); err != nil {
+			return err
+		}
 	} else if vm.hasFlag(ScriptVerifyDiscourageUpgradeableWitnessProgram) {
 		errStr := fmt.Sprintf("new witness program versions "+
 			"invalid: %v", vm.witnessProgram)
@@ -517,6 +604,214 @@ func (vm *Engine) verifyWitnessProgram(witness [][]byte) error {
 	return nil
 }
 
+// isTaprootSpend returns whether the stored witness program is a taproot output
+// which is validated according to the taproot rules.  Version 1 witness
+// programs nested in pay-to-script-hash and of any size other than 32 bytes
+// remain anyone can spend.
+func (vm *Engine) isTaprootSpend() bool {
+	return vm.hasFlag(ScriptVerifyTaproot) && vm.isWitnessVersionActive(1) &&
+		len(vm.witnessProgram) == payToTaprootDataSize && !vm.bip16
+}
+
+// verifyTaprootSpend validates the spend of the taproot output with the stored
+// witness program using the passed witness according to BIP0341.  Key path
+// spends are fully validated by it, while for script path spends it sets up the
+// engine to execute the revealed tapscript.
+func (vm *Engine) verifyTaprootSpend(witness wire.TxWitness) error {
+	if len(witness) == 0 {
+		return scriptError(ErrWitnessProgramEmpty, "witness program "+
+			"empty passed empty witness")
+	}
+
+	vm.taprootCtx = &taprootExecutionCtx{
+		codeSepPos:   blankCodeSepValue,
+		sigOpsBudget: baseSigOpsBudget + int32(witness.SerializeSize()),
+	}
+
+	// The annex is not interpreted, but signatures commit to it.
+	if isAnnexedWitness(witness) {
+		vm.taprootCtx.annex = witness[len(witness)-1]
+		witness = witness[:len(witness)-1]
+	}
+
+	// A single remaining element is the signature of a key path spend by
+	// the output key.
+	if len(witness) == 1 {
+		err := vm.checkSchnorrSignature(witness[0], vm.witnessProgram,
+			&taprootSigHashOptions{annex: vm.taprootCtx.annex})
+		if err != nil {
+			return err
+		}
+		vm.taprootCtx.mustSucceed = true
+		return nil
+	}
+
+	// Otherwise it is a script path spend, where the last two elements are
+	// the revealed script and the control block proving the output key
+	// commits to it.
+	controlBlock, err := ParseControlBlock(witness[len(witness)-1])
+	if err != nil {
+		return err
+	}
+	witnessScript := witness[len(witness)-2]
+	err = VerifyTaprootLeafCommitment(controlBlock, vm.witnessProgram,
+		witnessScript)
+	if err != nil {
+		return err
+	}
+
+	// Scripts with unknown leaf versions are reserved for future soft forks
+	// and therefore succeed.
+	if controlBlock.LeafVersion != BaseLeafVersion {
+		if vm.hasFlag(ScriptVerifyDiscourageUpgradeableTaprootVersion) {
+			str := fmt.Sprintf("tapscript leaf version %#x is "+
+				"reserved for soft-fork upgrades",
+				controlBlock.LeafVersion)
+			return scriptError(ErrDiscourageUpgradeableTaprootVersion,
+				str)
+		}
+		vm.taprootCtx.mustSucceed = true
+		return nil
+	}
+	vm.taprootCtx.tapLeafHash = NewBaseTapLeaf(witnessScript).TapHash()
+
+	// Tapscripts containing an OP_SUCCESSx opcode succeed without being
+	// executed as long as they parse up to that opcode.
+	tokenizer := MakeScriptTokenizer(vm.version, witnessScript)
+	for tokenizer.Next() {
+		if !isOpSuccess(tokenizer.Opcode()) {
+			continue
+		}
+		if vm.hasFlag(ScriptVerifyDiscourageOpSuccess) {
+			str := fmt.Sprintf("tapscript contains OP_SUCCESS opcode "+
+				"%#x reserved for soft-fork upgrades",
+				tokenizer.Opcode())
+			return scriptError(ErrDiscourageOpSuccess, str)
+		}
+		vm.taprootCtx.mustSucceed = true
+		return nil
+	}
+	if err := tokenizer.Err(); err != nil {
+		return err
+	}
+
+	// The remaining witness elements are the initial stack of the
+	// tapscript, which is not limited in size otherwise.
+	stack := witness[:len(witness)-2]
+	if len(stack) > MaxStackSize {
+		str := fmt.Sprintf("tapscript initial stack size %d > max "+
+			"allowed %d", len(stack), MaxStackSize)
+		return scriptError(ErrStackOverflow, str)
+	}
+	for _, witElement := range stack {
+		if len(witElement) > MaxScriptElementSize {
+			str := fmt.Sprintf("element size %d exceeds max "+
+				"allowed size %d", len(witElement),
+				MaxScriptElementSize)
+			return scriptError(ErrElementTooBig, str)
+		}
+	}
+
+	vm.scripts = append(vm.scripts, witnessScript)
+	vm.SetStack(stack)
+	return nil
+}
+
+// checkSchnorrSignature returns an error if the passed signature, optionally
+// followed by its signature hash type, is not a valid BIP0340 signature of the
+// taproot signature hash of the transaction by the passed x-only public key.
+func (vm *Engine) checkSchnorrSignature(sig, pubKey []byte,
+	opts *taprootSigHashOptions) error {
+
+	hashType := SigHashDefault
+	switch len(sig) {
+	case schnorr.SignatureSize:
+	case schnorr.SignatureSize + 1:
+		// An explicit default hash type would make the signature
+		// malleable.
+		hashType = SigHashType(sig[schnorr.SignatureSize])
+		if hashType == SigHashDefault {
+			str := "taproot signature with explicit default hash type"
+			return scriptError(ErrInvalidSigHashType, str)
+		}
+		sig = sig[:schnorr.SignatureSize]
+	default:
+		str := fmt.Sprintf("invalid taproot signature length %d",
+			len(sig))
+		return scriptError(ErrInvalidTaprootSigLen, str)
+	}
+	if !isValidTaprootSigHash(hashType) {
+		str := fmt.Sprintf("invalid taproot hash type %#x", hashType)
+		return scriptError(ErrInvalidSigHashType, str)
+	}
+
+	sigHashes := vm.hashCache
+	if sigHashes == nil {
+		sigHashes = NewTxSigHashes(&vm.tx, vm.prevOutFetcher)
+	}
+	hash, err := calcTaprootSignatureHashRaw(sigHashes, hashType, &vm.tx,
+		vm.txIdx, vm.prevOutFetcher, opts)
+	if err != nil {
+		return scriptError(ErrInvalidSigHashType, err.Error())
+	}
+
+	var valid bool
+	key, err := schnorr.ParsePubKey(pubKey)
+	if err == nil {
+		schnorrSig, err := schnorr.ParseSignature(sig)
+		valid = err == nil && schnorrSig.Verify(hash, key)
+	}
+	vm.traceSigCheck(pubKey, sig, valid)
+	if !valid {
+		str := fmt.Sprintf("invalid taproot signature %x for public "+
+			"key %x", sig, pubKey)
+		return scriptError(ErrTaprootSigInvalid, str)
+	}
+	return nil
+}
+
+// checkTapscriptSig performs a signature check within a tapscript according to
+// BIP0342.  It returns whether the signature is non-empty, since non-empty
+// signatures which are not valid result in an error.  Every signature check
+// with a non-empty signature consumes part of the budget of the tapscript.
+func (vm *Engine) checkTapscriptSig(sig, pubKey []byte) (bool, error) {
+	nonEmpty := len(sig) != 0
+	if nonEmpty {
+		vm.taprootCtx.sigOpsBudget -= sigOpsDelta
+		if vm.taprootCtx.sigOpsBudget < 0 {
+			str := "tapscript exceeded its signature check budget"
+			return false, scriptError(ErrTaprootMaxSigOps, str)
+		}
+	}
+
+	switch {
+	case len(pubKey) == 0:
+		str := "tapscript signature check with empty public key"
+		return false, scriptError(ErrTaprootPubkeyIsEmpty, str)
+
+	case len(pubKey) == schnorr.PubKeyBytesLen:
+		if nonEmpty {
+			opts := &taprootSigHashOptions{
+				annex:       vm.taprootCtx.annex,
+				tapLeafHash: vm.taprootCtx.tapLeafHash[:],
+				codeSepPos:  vm.taprootCtx.codeSepPos,
+			}
+			if err := vm.checkSchnorrSignature(sig, pubKey, opts); err != nil {
+				return false, err
+			}
+		}
+
+	// Public keys of unknown types are reserved for future soft forks and
+	// their signature checks succeed.
+	case vm.hasFlag(ScriptVerifyDiscourageUpgradeablePubkeyType):
+		str := fmt.Sprintf("tapscript public key type of size %d is "+
+			"reserved for soft-fork upgrades", len(pubKey))
+		return false, scriptError(ErrDiscourageUpgradeablePubKeyType, str)
+	}
+
+	return nonEmpty, nil
+}
+
 // DisasmPC returns the string for the disassembly of the opcode that will be
 // next to execute when Step is called.
 func (vm *Engine) DisasmPC() (string, error) {
@@ -593,10 +888,18 @@ func (vm *Engine) CheckErrorCondition(finalScript bool) error {
 			"error check when script unfinished")
 	}
 
-	// If we're in version zero witness execution mode, and this was the
-	// final script, then the stack MUST be clean in order to maintain
-	// compatibility with BIP16.
-	if finalScript && vm.isWitnessVersionActive(0) && vm.dstack.Depth() != 1 {
+	// Taproot spends which succeed without executing a script, such as key
+	// path spends, do not leave anything on the stack.
+	if vm.taprootCtx != nil && vm.taprootCtx.mustSucceed {
+		return nil
+	}
+
+	// If we're in witness execution mode, and this was the final script,
+	// then the stack MUST be clean in order to maintain compatibility with
+	// BIP16.
+	if finalScript && (vm.isWitnessVersionActive(0) || vm.taprootCtx != nil) &&
+		vm.dstack.Depth() != 1 {
+
 		return scriptError(ErrEvalFalse, "witness program must "+
 			"have clean stack")
 	}
@@ -644,6 +947,21 @@ func (vm *Engine) CheckErrorCondition(finalScript bool) error {
 // The result of calling Step or any other method is undefined if an error is
 // returned.
 func (vm *Engine) Step() (done bool, err error) {
+	// Pass the trace of the step to the tracer once it completes, including
+	// the reason it failed, if any.
+	if vm.tracer != nil {
+		vm.stepTrace = &StepTrace{
+			ScriptIdx: vm.scriptIdx,
+			OpcodeIdx: vm.opcodeIdx,
+		}
+		defer func() {
+			trace := vm.stepTrace
+			vm.stepTrace = nil
+			trace.Err = err
+			vm.tracer(trace)
+		}()
+	}
+
 	// Verify the engine is pointing to a valid program counter.
 	if err := vm.checkValidPC(); err != nil {
 		return true, err
@@ -668,7 +986,13 @@ func (vm *Engine) Step() (done bool, err error) {
 	// Execute the opcode while taking into account several things such as
 	// disabled opcodes, illegal opcodes, maximum allowed operations per script,
 	// maximum script element sizes, and conditionals.
+	if vm.stepTrace != nil {
+		vm.traceOpcode(vm.tokenizer.op, vm.tokenizer.Data())
+	}
 	err = vm.executeOpcode(vm.tokenizer.op, vm.tokenizer.Data())
+	if vm.stepTrace != nil {
+		vm.traceStacks()
+	}
 	if err != nil {
 		return true, err
 	}
@@ -1116,8 +1440,13 @@ func (vm *Engine) SetAltStack(data [][]byte) {
 // NewEngine returns a new script engine for the provided public key script,
 // transaction, and input index.  The flags modify the behavior of the script
 // engine according to the description provided by each flag.
+//
+// The previous output fetcher must provide the outputs spent by all inputs of
+// the transaction in order to verify taproot signatures, which commit to them.
 func NewEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags,
-	sigCache *SigCache, hashCache *TxSigHashes, inputAmount int64) (*Engine, error) {
+	sigCache *SigCache, hashCache *TxSigHashes, inputAmount int64,
+	prevOutFetcher PrevOutputFetcher) (*Engine, error) {
+
 	const scriptVersion = 0
 
 	// The provided transaction input index must refer to a valid input.
@@ -1148,7 +1477,7 @@ func NewEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags
 	// when it should be. The same goes for segwit which will pull in
 	// additional scripts for execution from the witness stack.
 	vm := Engine{flags: flags, sigCache: sigCache, hashCache: hashCache,
-		inputAmount: inputAmount}
+		inputAmount: inputAmount, prevOutFetcher: prevOutFetcher}
 	if vm.hasFlag(ScriptVerifyCleanStack) && (!vm.hasFlag(ScriptBip16) &&
 		!vm.hasFlag(ScriptVerifyWitness)) {
 		return nil, scriptError(ErrInvalidFlags,
@@ -343,6 +343,64 @@ const (
 	// serialized in a compressed format.
 	ErrWitnessPubKeyType
 
+	// -------------------------------
+	// Failures related to taproot.
+	// -------------------------------
+
+	// ErrDiscourageUpgradeableTaprootVersion is returned if
+	// ScriptVerifyDiscourageUpgradeableTaprootVersion is set and a script
+	// path spend reveals a script with an unknown leaf version.
+	ErrDiscourageUpgradeableTaprootVersion
+
+	// ErrDiscourageOpSuccess is returned if ScriptVerifyDiscourageOpSuccess
+	// is set and a tapscript contains an OP_SUCCESSx opcode.
+	ErrDiscourageOpSuccess
+
+	// ErrDiscourageUpgradeablePubKeyType is returned if
+	// ScriptVerifyDiscourageUpgradeablePubkeyType is set and a signature is
+	// checked against a public key of unknown type within a tapscript.
+	ErrDiscourageUpgradeablePubKeyType
+
+	// ErrTaprootSigInvalid is returned when a non-empty Schnorr signature of
+	// a taproot key path spend or a tapscript signature check fails to
+	// verify.
+	ErrTaprootSigInvalid
+
+	// ErrInvalidTaprootSigLen is returned when a Schnorr signature within a
+	// taproot spend is neither 64 nor 65 bytes or is 65 bytes with an
+	// explicit default signature hash type.
+	ErrInvalidTaprootSigLen
+
+	// ErrTaprootPubkeyIsEmpty is returned when a signature is checked
+	// against an empty public key within a tapscript.
+	ErrTaprootPubkeyIsEmpty
+
+	// ErrTaprootMaxSigOps is returned when the signature checks of a
+	// tapscript exceed the budget granted by the size of its witness.
+	ErrTaprootMaxSigOps
+
+	// ErrTapscriptCheckMultisig is returned when OP_CHECKMULTISIG or
+	// OP_CHECKMULTISIGVERIFY is executed within a tapscript.
+	ErrTapscriptCheckMultisig
+
+	// ErrControlBlockTooSmall is returned when a control block is smaller
+	// than the leaf version and internal key it must contain.
+	ErrControlBlockTooSmall
+
+	// ErrControlBlockInvalidLength is returned when the inclusion proof of a
+	// control block is not a whole number of at most 128 hashes.
+	ErrControlBlockInvalidLength
+
+	// ErrTaprootMerkleProofInvalid is returned when the output key of a
+	// taproot output does not commit to the revealed script and the
+	// inclusion proof of the control block.
+	ErrTaprootMerkleProofInvalid
+
+	// ErrTaprootOutputKeyParityMismatch is returned when the parity of the
+	// y coordinate of the computed output key does not match the one given
+	// in the control block.
+	ErrTaprootOutputKeyParityMismatch
+
 	// numErrorCodes is the maximum error code number used in tests.  This
 	// entry MUST be the last entry in the enum.
 	numErrorCodes
@@ -415,6 +473,19 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrMinimalIf:                          "ErrMinimalIf",
 	ErrWitnessPubKeyType:                  "ErrWitnessPubKeyType",
 	ErrDiscourageUpgradableWitnessProgram: "ErrDiscourageUpgradableWitnessProgram",
+
+	ErrDiscourageUpgradeableTaprootVersion: "ErrDiscourageUpgradeableTaprootVersion",
+	ErrDiscourageOpSuccess:                 "ErrDiscourageOpSuccess",
+	ErrDiscourageUpgradeablePubKeyType:     "ErrDiscourageUpgradeablePubKeyType",
+	ErrTaprootSigInvalid:                   "ErrTaprootSigInvalid",
+	ErrInvalidTaprootSigLen:                "ErrInvalidTaprootSigLen",
+	ErrTaprootPubkeyIsEmpty:                "ErrTaprootPubkeyIsEmpty",
+	ErrTaprootMaxSigOps:                    "ErrTaprootMaxSigOps",
+	ErrTapscriptCheckMultisig:              "ErrTapscriptCheckMultisig",
+	ErrControlBlockTooSmall:                "ErrControlBlockTooSmall",
+	ErrControlBlockInvalidLength:           "ErrControlBlockInvalidLength",
+	ErrTaprootMerkleProofInvalid:           "ErrTaprootMerkleProofInvalid",
+	ErrTaprootOutputKeyParityMismatch:      "ErrTaprootOutputKeyParityMismatch",
 }
 
 // String returns the ErrorCode as a human-readable name.
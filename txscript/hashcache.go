@@ -11,24 +11,116 @@ import (
 	"github.com/btcsuite/btcd/wire"
 )
 
-// TxSigHashes houses the partial set of sighashes introduced within BIP0143.
-// This partial set of sighashes may be re-used within each input across a
-// transaction when validating all inputs. As a result, validation complexity
-// for SigHashAll can be reduced by a polynomial factor.
+// PrevOutputFetcher is an interface used to supply the previous outputs
+// spent by the inputs of a transaction.  They are needed to calculate the
+// BIP0341 signature hash of taproot inputs, which commits to the amounts and
+// public key scripts of all spent outputs.
+type PrevOutputFetcher interface {
+	// FetchPrevOutput returns the output the passed outpoint refers to or
+	// nil if it is not known.
+	FetchPrevOutput(wire.OutPoint) *wire.TxOut
+}
+
+// CannedPrevOutputFetcher is an implementation of PrevOutputFetcher that
+// returns the same output for every outpoint.  It is useful for transactions
+// which spend a single output.
+type CannedPrevOutputFetcher struct {
+	pkScript []byte
+	amt      int64
+}
+
+// NewCannedPrevOutputFetcher returns a PrevOutputFetcher which returns an output
+// with the passed public key script and amount for every outpoint.
+func NewCannedPrevOutputFetcher(pkScript []byte, amt int64) *CannedPrevOutputFetcher {
+	return &CannedPrevOutputFetcher{
+		pkScript: pkScript,
+		amt:      amt,
+	}
+}
+
+// FetchPrevOutput returns the canned output for the passed outpoint.
+//
+// This is part of the PrevOutputFetcher interface.
+func (c *CannedPrevOutputFetcher) FetchPrevOutput(wire.OutPoint) *wire.TxOut {
+	return wire.NewTxOut(c.amt, c.pkScript)
+}
+
+// MultiPrevOutFetcher is an implementation of PrevOutputFetcher backed by a map
+// of outpoints to the outputs they refer to.
+type MultiPrevOutFetcher struct {
+	prevOuts map[wire.OutPoint]*wire.TxOut
+}
+
+// NewMultiPrevOutFetcher returns a PrevOutputFetcher backed by the passed map,
+// which may be nil.
+func NewMultiPrevOutFetcher(prevOuts map[wire.OutPoint]*wire.TxOut) *MultiPrevOutFetcher {
+	if prevOuts == nil {
+		prevOuts = make(map[wire.OutPoint]*wire.TxOut)
+	}
+
+	return &MultiPrevOutFetcher{
+		prevOuts: prevOuts,
+	}
+}
+
+// FetchPrevOutput returns the output the passed outpoint refers to or nil if
+// it was not added to the fetcher.
+//
+// This is part of the PrevOutputFetcher interface.
+func (m *MultiPrevOutFetcher) FetchPrevOutput(op wire.OutPoint) *wire.TxOut {
+	return m.prevOuts[op]
+}
+
+// AddPrevOut adds the output the passed outpoint refers to.
+func (m *MultiPrevOutFetcher) AddPrevOut(op wire.OutPoint, txOut *wire.TxOut) {
+	m.prevOuts[op] = txOut
+}
+
+// TxSigHashes houses the partial set of sighashes introduced within BIP0143
+// and BIP0341.  This partial set of sighashes may be re-used within each input
+// across a transaction when validating all inputs. As a result, validation
+// complexity for SigHashAll can be reduced by a polynomial factor.
+//
+// The BIP0341 sighashes are single sha256 hashes, while the BIP0143 ones are
+// the double sha256 of the same data.  The BIP0341 sighashes are only
+// calculated when the transaction spends a taproot output.
 type TxSigHashes struct {
 	HashPrevOuts chainhash.Hash
 	HashSequence chainhash.Hash
 	HashOutputs  chainhash.Hash
+
+	HashPrevOutsV1     chainhash.Hash
+	HashSequenceV1     chainhash.Hash
+	HashOutputsV1      chainhash.Hash
+	HashInputAmountsV1 chainhash.Hash
+	HashInputScriptsV1 chainhash.Hash
 }
 
 // NewTxSigHashes computes, and returns the cached sighashes of the given
-// transaction.
-func NewTxSigHashes(tx *wire.MsgTx) *TxSigHashes {
-	return &TxSigHashes{
-		HashPrevOuts: calcHashPrevOuts(tx),
-		HashSequence: calcHashSequence(tx),
-		HashOutputs:  calcHashOutputs(tx),
+// transaction.  The passed fetcher must return the outputs spent by the
+// transaction in order to calculate the sighashes of taproot inputs.
+func NewTxSigHashes(tx *wire.MsgTx, prevOutFetcher PrevOutputFetcher) *TxSigHashes {
+	// The BIP0143 sighashes are the sha256 of the BIP0341 ones.
+	hashPrevOuts := calcHashPrevOuts(tx)
+	hashSequence := calcHashSequence(tx)
+	hashOutputs := calcHashOutputs(tx)
+	sigHashes := TxSigHashes{
+		HashPrevOuts: chainhash.HashH(hashPrevOuts[:]),
+		HashSequence: chainhash.HashH(hashSequence[:]),
+		HashOutputs:  chainhash.HashH(hashOutputs[:]),
 	}
+
+	if spendsTaprootOutput(tx, prevOutFetcher) {
+		sigHashes.HashPrevOutsV1 = hashPrevOuts
+		sigHashes.HashSequenceV1 = hashSequence
+		sigHashes.HashOutputsV1 = hashOutputs
+		sigHashes.HashInputAmountsV1 = calcHashInputAmounts(tx,
+			prevOutFetcher)
+		sigHashes.HashInputScriptsV1 = calcHashInputScripts(tx,
+			prevOutFetcher)
+	}
+
+	return &sigHashes
 }
 
 // HashCache houses a set of partial sighashes keyed by txid. The set of partial
@@ -51,10 +143,12 @@ func NewHashCache(maxSize uint) *HashCache {
 }
 
 // AddSigHashes computes, then adds the partial sighashes for the passed
+// transaction.  The passed fetcher must return the outputs spent by the
 // transaction.
-func (h *HashCache) AddSigHashes(tx *wire.MsgTx) {
+func (h *HashCache) AddSigHashes(tx *wire.MsgTx, prevOutFetcher PrevOutputFetcher) {
+	sigHashes := NewTxSigHashes(tx, prevOutFetcher)
 	h.Lock()
-	h.sigHashes[tx.TxHash()] = NewTxSigHashes(tx)
+	h.sigHashes[tx.TxHash()] = sigHashes
 	h.Unlock()
 }
 
@@ -0,0 +1,50 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package miniscript implements miniscript, a structured representation of a
+subset of bitcoin scripts which can be analyzed, composed and satisfied
+generically, along with a compiler from spending policies to miniscript.
+
+Miniscript expressions such as
+
+	and_v(v:pk(K1),or_d(pk(K2),older(144)))
+
+are parsed with Parse, which type checks them according to the miniscript type
+system.  The type of an expression tells whether it is valid at all and which
+guarantees it offers, most importantly whether every satisfaction requires a
+signature and whether third parties are unable to modify its satisfactions,
+which is what CheckSane verifies.
+
+Spending policies such as
+
+	or(99@pk(K1),1@and(pk(K2),older(144)))
+
+are parsed with ParsePolicy and compiled to a miniscript with a low expected
+spending cost using the optional probabilities of the branches of
+disjunctions.
+
+Miniscripts are currently compiled to the witness scripts of pay-to-witness-
+script-hash (P2WSH) outputs, where public keys are hex-encoded compressed
+public keys.  The maximum size of the witness spending such an output is
+known upfront, and the witness itself is produced by Satisfy from the
+signatures, preimages and timelocks made available by a Satisfier.
+
+The package has the following limitations:
+
+  - Tapscript is not supported, so there are no multi_a expressions, x-only
+    keys or tapscript resource limits
+  - Keys must be hex-encoded compressed public keys rather than key
+    expressions such as extended public keys with origins
+  - Miniscripts are only parsed from expressions, not decoded from scripts
+  - The compiler keeps the cheapest candidate of every type for each
+    sub-policy, so the result is not guaranteed to be the cheapest miniscript
+    overall, and only the branches of or policies may be given probabilities
+  - The operation count checked by CheckSane counts every opcode of the
+    script rather than the ones executed by its satisfactions, which rejects
+    some scripts that would be within the limit
+
+More info: https://bitcoin.sipa.be/miniscript/
+*/
+package miniscript
@@ -0,0 +1,763 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package miniscript
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	// maxStandardP2WSHScriptSize is the maximum size of a witness script
+	// which is relayed and mined by default.
+	maxStandardP2WSHScriptSize = 3600
+
+	// maxStandardP2WSHStackItems is the maximum number of witness stack
+	// items, not including the witness script, which is relayed and mined
+	// by default.
+	maxStandardP2WSHStackItems = 100
+)
+
+// fragment identifies the kind of a miniscript expression.
+type fragment uint8
+
+// These constants define the miniscript fragments.  The wrappers are
+// expressions with a single sub-expression written as a prefix of it, such as
+// v:pk(K).
+const (
+	fragJust0     fragment = iota // 0
+	fragJust1                     // 1
+	fragPkK                       // pk_k(key)
+	fragPkH                       // pk_h(key)
+	fragOlder                     // older(n)
+	fragAfter                     // after(n)
+	fragSha256                    // sha256(h)
+	fragHash256                   // hash256(h)
+	fragRipemd160                 // ripemd160(h)
+	fragHash160                   // hash160(h)
+	fragAndOr                     // andor(X,Y,Z)
+	fragAndV                      // and_v(X,Y)
+	fragAndB                      // and_b(X,Y)
+	fragOrB                       // or_b(X,Z)
+	fragOrC                       // or_c(X,Z)
+	fragOrD                       // or_d(X,Z)
+	fragOrI                       // or_i(X,Z)
+	fragThresh                    // thresh(k,X1,...,Xn)
+	fragMulti                     // multi(k,key1,...,keyn)
+	fragWrapA                     // a:X
+	fragWrapS                     // s:X
+	fragWrapC                     // c:X
+	fragWrapD                     // d:X
+	fragWrapV                     // v:X
+	fragWrapJ                     // j:X
+	fragWrapN                     // n:X
+)
+
+// fragmentNames maps the fragments which are written as name(args) to their
+// names.
+var fragmentNames = map[fragment]string{
+	fragJust0:     "0",
+	fragJust1:     "1",
+	fragPkK:       "pk_k",
+	fragPkH:       "pk_h",
+	fragOlder:     "older",
+	fragAfter:     "after",
+	fragSha256:    "sha256",
+	fragHash256:   "hash256",
+	fragRipemd160: "ripemd160",
+	fragHash160:   "hash160",
+	fragAndOr:     "andor",
+	fragAndV:      "and_v",
+	fragAndB:      "and_b",
+	fragOrB:       "or_b",
+	fragOrC:       "or_c",
+	fragOrD:       "or_d",
+	fragOrI:       "or_i",
+	fragThresh:    "thresh",
+	fragMulti:     "multi",
+}
+
+// wrapperFragments maps the letters of the wrappers to their fragments.
+var wrapperFragments = map[byte]fragment{
+	'a': fragWrapA,
+	's': fragWrapS,
+	'c': fragWrapC,
+	'd': fragWrapD,
+	'v': fragWrapV,
+	'j': fragWrapJ,
+	'n': fragWrapN,
+}
+
+// combinatorFragments maps the names of the fragments which combine a fixed
+// number of sub-expressions to their fragments.
+var combinatorFragments = map[string]fragment{
+	"andor": fragAndOr,
+	"and_v": fragAndV,
+	"and_b": fragAndB,
+	"or_b":  fragOrB,
+	"or_c":  fragOrC,
+	"or_d":  fragOrD,
+	"or_i":  fragOrI,
+}
+
+// hashFragments maps the names of the hash fragments to their fragments and
+// the size of their hashes.
+var hashFragments = map[string]struct {
+	fragment fragment
+	size     int
+}{
+	"sha256":    {fragSha256, sha256.Size},
+	"hash256":   {fragHash256, sha256.Size},
+	"ripemd160": {fragRipemd160, 20},
+	"hash160":   {fragHash160, 20},
+}
+
+// Miniscript is a type checked miniscript expression.
+type Miniscript struct {
+	fragment fragment
+
+	// k is the threshold of thresh and multi expressions or the value of
+	// timelocks.
+	k uint32
+
+	// keys are the serialized public keys of pk_k, pk_h and multi
+	// expressions.
+	keys [][]byte
+
+	// hash is the hash of sha256, hash256, ripemd160 and hash160
+	// expressions.
+	hash []byte
+
+	// subs are the sub-expressions in the order they are written.
+	subs []*Miniscript
+
+	typ Type
+}
+
+// newMiniscript returns a new type checked miniscript expression for the passed
+// fragment and arguments.  An error is returned when the sub-expressions don't
+// have the types the fragment requires.
+func newMiniscript(frag fragment, k uint32, keys [][]byte, hash []byte,
+	subs ...*Miniscript) (*Miniscript, error) {
+
+	node := &Miniscript{
+		fragment: frag,
+		k:        k,
+		keys:     keys,
+		hash:     hash,
+		subs:     subs,
+	}
+	node.typ = computeType(node)
+	if !node.typ.IsValid() {
+		return nil, fmt.Errorf("%v is invalid since its arguments don't "+
+			"have the required types", node)
+	}
+	return node, nil
+}
+
+// wrap returns the passed expression wrapped by the wrapper with the passed
+// letter.  Besides the wrapper fragments, the letters t, l and u are shorthands
+// for and_v(X,1), or_i(0,X) and or_i(X,0) respectively.
+func wrap(letter byte, sub *Miniscript) (*Miniscript, error) {
+	if frag, ok := wrapperFragments[letter]; ok {
+		return newMiniscript(frag, 0, nil, nil, sub)
+	}
+
+	switch letter {
+	case 't':
+		one, _ := newMiniscript(fragJust1, 0, nil, nil)
+		return newMiniscript(fragAndV, 0, nil, nil, sub, one)
+	case 'l':
+		zero, _ := newMiniscript(fragJust0, 0, nil, nil)
+		return newMiniscript(fragOrI, 0, nil, nil, zero, sub)
+	case 'u':
+		zero, _ := newMiniscript(fragJust0, 0, nil, nil)
+		return newMiniscript(fragOrI, 0, nil, nil, sub, zero)
+	}
+
+	return nil, fmt.Errorf("unknown wrapper %q", letter)
+}
+
+// Parse parses and type checks the passed miniscript expression, which must be
+// of type B as required for a complete script.  The result isn't necessarily
+// sane, which can be checked with CheckSane.
+func Parse(expr string) (*Miniscript, error) {
+	tree, err := parseExprTree(expr)
+	if err != nil {
+		return nil, err
+	}
+	node, err := miniscriptFromTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	if !node.typ.has(typeB) {
+		return nil, fmt.Errorf("%v is of type %v instead of B", node,
+			node.typ)
+	}
+	return node, nil
+}
+
+// miniscriptFromTree returns the miniscript expression of the passed parsed
+// tree.
+func miniscriptFromTree(tree *exprTree) (*Miniscript, error) {
+	// The wrappers of an expression are written as letters before a colon,
+	// where the rightmost one is applied first.
+	if idx := strings.IndexByte(tree.name, ':'); idx != -1 {
+		wrappers := tree.name[:idx]
+		if wrappers == "" {
+			return nil, fmt.Errorf("missing wrappers in %v", tree)
+		}
+		node, err := miniscriptFromTree(&exprTree{
+			name: tree.name[idx+1:],
+			args: tree.args,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := len(wrappers) - 1; i >= 0; i-- {
+			node, err = wrap(wrappers[i], node)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return node, nil
+	}
+
+	switch tree.name {
+	case "0", "1":
+		if err := tree.checkArgs(0); err != nil {
+			return nil, err
+		}
+		frag := fragJust0
+		if tree.name == "1" {
+			frag = fragJust1
+		}
+		return newMiniscript(frag, 0, nil, nil)
+
+	case "pk_k", "pk_h", "pk", "pkh":
+		if err := tree.checkArgs(1); err != nil {
+			return nil, err
+		}
+		arg, err := tree.leaf(0)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseKey(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		// The pk and pkh expressions are shorthands for c:pk_k and
+		// c:pk_h respectively.
+		frag := fragPkK
+		if tree.name == "pk_h" || tree.name == "pkh" {
+			frag = fragPkH
+		}
+		node, err := newMiniscript(frag, 0, [][]byte{key}, nil)
+		if err != nil || tree.name == "pk_k" || tree.name == "pk_h" {
+			return node, err
+		}
+		return wrap('c', node)
+
+	case "older", "after":
+		if err := tree.checkArgs(1); err != nil {
+			return nil, err
+		}
+		arg, err := tree.leaf(0)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseTimelock(arg)
+		if err != nil {
+			return nil, err
+		}
+		frag := fragOlder
+		if tree.name == "after" {
+			frag = fragAfter
+		}
+		return newMiniscript(frag, value, nil, nil)
+
+	case "sha256", "hash256", "ripemd160", "hash160":
+		if err := tree.checkArgs(1); err != nil {
+			return nil, err
+		}
+		arg, err := tree.leaf(0)
+		if err != nil {
+			return nil, err
+		}
+		hashFrag := hashFragments[tree.name]
+		hash, err := parseHash(arg, hashFrag.size)
+		if err != nil {
+			return nil, err
+		}
+		return newMiniscript(hashFrag.fragment, 0, nil, hash)
+
+	case "multi":
+		if len(tree.args) < 2 {
+			return nil, fmt.Errorf("multi requires a threshold and " +
+				"at least one key")
+		}
+		numKeys := len(tree.args) - 1
+		if numKeys > txscript.MaxPubKeysPerMultiSig {
+			return nil, fmt.Errorf("multi with %d keys exceeds the "+
+				"maximum of %d", numKeys,
+				txscript.MaxPubKeysPerMultiSig)
+		}
+		arg, err := tree.leaf(0)
+		if err != nil {
+			return nil, err
+		}
+		k, err := parseThreshold(arg, numKeys)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([][]byte, 0, numKeys)
+		for i := 1; i < len(tree.args); i++ {
+			arg, err := tree.leaf(i)
+			if err != nil {
+				return nil, err
+			}
+			key, err := parseKey(arg)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+		return newMiniscript(fragMulti, k, keys, nil)
+
+	case "thresh":
+		if len(tree.args) < 2 {
+			return nil, fmt.Errorf("thresh requires a threshold and " +
+				"at least one sub-expression")
+		}
+		arg, err := tree.leaf(0)
+		if err != nil {
+			return nil, err
+		}
+		k, err := parseThreshold(arg, len(tree.args)-1)
+		if err != nil {
+			return nil, err
+		}
+		subs, err := miniscriptsFromTrees(tree.args[1:])
+		if err != nil {
+			return nil, err
+		}
+		return newMiniscript(fragThresh, k, nil, nil, subs...)
+
+	case "and_n":
+		// and_n(X,Y) is a shorthand for andor(X,Y,0).
+		if err := tree.checkArgs(2); err != nil {
+			return nil, err
+		}
+		subs, err := miniscriptsFromTrees(tree.args)
+		if err != nil {
+			return nil, err
+		}
+		zero, _ := newMiniscript(fragJust0, 0, nil, nil)
+		return newMiniscript(fragAndOr, 0, nil, nil, subs[0], subs[1],
+			zero)
+	}
+
+	if frag, ok := combinatorFragments[tree.name]; ok {
+		numArgs := 2
+		if frag == fragAndOr {
+			numArgs = 3
+		}
+		if err := tree.checkArgs(numArgs); err != nil {
+			return nil, err
+		}
+		subs, err := miniscriptsFromTrees(tree.args)
+		if err != nil {
+			return nil, err
+		}
+		return newMiniscript(frag, 0, nil, nil, subs...)
+	}
+
+	return nil, fmt.Errorf("unknown miniscript fragment %q", tree.name)
+}
+
+// miniscriptsFromTrees returns the miniscript expressions of the passed parsed
+// trees.
+func miniscriptsFromTrees(trees []*exprTree) ([]*Miniscript, error) {
+	nodes := make([]*Miniscript, 0, len(trees))
+	for _, tree := range trees {
+		node, err := miniscriptFromTree(tree)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// unwrap returns the letter and the sub-expression of the passed expression
+// when it's written as a wrapper, including the t, l and u shorthands.
+func (m *Miniscript) unwrap() (byte, *Miniscript) {
+	for letter, frag := range wrapperFragments {
+		if m.fragment == frag {
+			return letter, m.subs[0]
+		}
+	}
+
+	switch {
+	case m.fragment == fragAndV && m.subs[1].fragment == fragJust1:
+		return 't', m.subs[0]
+	case m.fragment == fragOrI && m.subs[0].fragment == fragJust0:
+		return 'l', m.subs[1]
+	case m.fragment == fragOrI && m.subs[1].fragment == fragJust0:
+		return 'u', m.subs[0]
+	}
+
+	return 0, nil
+}
+
+// String returns the miniscript expression, using the pk, pkh and and_n
+// shorthands where possible.
+func (m *Miniscript) String() string {
+	var wrappers []byte
+	node := m
+	for {
+		// c:pk_k and c:pk_h are written as pk and pkh.
+		if node.fragment == fragWrapC && (node.subs[0].fragment == fragPkK ||
+			node.subs[0].fragment == fragPkH) {
+
+			break
+		}
+		letter, sub := node.unwrap()
+		if sub == nil {
+			break
+		}
+		wrappers = append(wrappers, letter)
+		node = sub
+	}
+
+	expr := node.exprString()
+	if len(wrappers) == 0 {
+		return expr
+	}
+	return string(wrappers) + ":" + expr
+}
+
+// exprString returns the expression without its wrappers.
+func (m *Miniscript) exprString() string {
+	var args []string
+	name := fragmentNames[m.fragment]
+	switch m.fragment {
+	case fragJust0, fragJust1:
+		return name
+
+	case fragWrapC:
+		// Only c:pk_k and c:pk_h are written without their wrapper.
+		name = "pk"
+		if m.subs[0].fragment == fragPkH {
+			name = "pkh"
+		}
+		args = append(args, hex.EncodeToString(m.subs[0].keys[0]))
+
+	case fragPkK, fragPkH:
+		args = append(args, hex.EncodeToString(m.keys[0]))
+
+	case fragOlder, fragAfter:
+		args = append(args, strconv.FormatUint(uint64(m.k), 10))
+
+	case fragSha256, fragHash256, fragRipemd160, fragHash160:
+		args = append(args, hex.EncodeToString(m.hash))
+
+	case fragMulti:
+		args = append(args, strconv.FormatUint(uint64(m.k), 10))
+		for _, key := range m.keys {
+			args = append(args, hex.EncodeToString(key))
+		}
+
+	case fragThresh:
+		args = append(args, strconv.FormatUint(uint64(m.k), 10))
+		for _, sub := range m.subs {
+			args = append(args, sub.String())
+		}
+
+	case fragAndOr:
+		subs := m.subs
+		if subs[2].fragment == fragJust0 {
+			name = "and_n"
+			subs = subs[:2]
+		}
+		for _, sub := range subs {
+			args = append(args, sub.String())
+		}
+
+	default:
+		for _, sub := range m.subs {
+			args = append(args, sub.String())
+		}
+	}
+
+	return name + "(" + strings.Join(args, ",") + ")"
+}
+
+// Type returns the type of the expression.
+func (m *Miniscript) Type() Type {
+	return m.typ
+}
+
+// pushData appends a canonical push of the passed data to the script.
+func pushData(script, data []byte) []byte {
+	push, _ := txscript.NewScriptBuilder().AddData(data).Script()
+	return append(script, push...)
+}
+
+// pushInt appends a canonical push of the passed number to the script.
+func pushInt(script []byte, value int64) []byte {
+	push, _ := txscript.NewScriptBuilder().AddInt64(value).Script()
+	return append(script, push...)
+}
+
+// hashOpcodes maps the hash fragments to the opcodes hashing their preimages.
+var hashOpcodes = map[fragment]byte{
+	fragSha256:    txscript.OP_SHA256,
+	fragHash256:   txscript.OP_HASH256,
+	fragRipemd160: txscript.OP_RIPEMD160,
+	fragHash160:   txscript.OP_HASH160,
+}
+
+// verifyOpcodes maps the opcodes which have a VERIFY variant to that variant.
+var verifyOpcodes = map[byte]byte{
+	txscript.OP_EQUAL:         txscript.OP_EQUALVERIFY,
+	txscript.OP_CHECKSIG:      txscript.OP_CHECKSIGVERIFY,
+	txscript.OP_CHECKMULTISIG: txscript.OP_CHECKMULTISIGVERIFY,
+	txscript.OP_NUMEQUAL:      txscript.OP_NUMEQUALVERIFY,
+}
+
+// Script returns the script of the miniscript expression, which is the witness
+// script of the P2WSH output it's spent from.
+func (m *Miniscript) Script() []byte {
+	return m.appendScript(nil)
+}
+
+// appendScript appends the script of the expression to the passed script.
+func (m *Miniscript) appendScript(script []byte) []byte {
+	switch m.fragment {
+	case fragJust0:
+		return append(script, txscript.OP_0)
+
+	case fragJust1:
+		return append(script, txscript.OP_1)
+
+	case fragPkK:
+		return pushData(script, m.keys[0])
+
+	case fragPkH:
+		script = append(script, txscript.OP_DUP, txscript.OP_HASH160)
+		script = pushData(script, btcutil.Hash160(m.keys[0]))
+		return append(script, txscript.OP_EQUALVERIFY)
+
+	case fragOlder:
+		script = pushInt(script, int64(m.k))
+		return append(script, txscript.OP_CHECKSEQUENCEVERIFY)
+
+	case fragAfter:
+		script = pushInt(script, int64(m.k))
+		return append(script, txscript.OP_CHECKLOCKTIMEVERIFY)
+
+	case fragSha256, fragHash256, fragRipemd160, fragHash160:
+		script = append(script, txscript.OP_SIZE)
+		script = pushInt(script, preimageSize)
+		script = append(script, txscript.OP_EQUALVERIFY,
+			hashOpcodes[m.fragment])
+		script = pushData(script, m.hash)
+		return append(script, txscript.OP_EQUAL)
+
+	case fragAndOr:
+		script = m.subs[0].appendScript(script)
+		script = append(script, txscript.OP_NOTIF)
+		script = m.subs[2].appendScript(script)
+		script = append(script, txscript.OP_ELSE)
+		script = m.subs[1].appendScript(script)
+		return append(script, txscript.OP_ENDIF)
+
+	case fragAndV:
+		script = m.subs[0].appendScript(script)
+		return m.subs[1].appendScript(script)
+
+	case fragAndB:
+		script = m.subs[0].appendScript(script)
+		script = m.subs[1].appendScript(script)
+		return append(script, txscript.OP_BOOLAND)
+
+	case fragOrB:
+		script = m.subs[0].appendScript(script)
+		script = m.subs[1].appendScript(script)
+		return append(script, txscript.OP_BOOLOR)
+
+	case fragOrC:
+		script = m.subs[0].appendScript(script)
+		script = append(script, txscript.OP_NOTIF)
+		script = m.subs[1].appendScript(script)
+		return append(script, txscript.OP_ENDIF)
+
+	case fragOrD:
+		script = m.subs[0].appendScript(script)
+		script = append(script, txscript.OP_IFDUP, txscript.OP_NOTIF)
+		script = m.subs[1].appendScript(script)
+		return append(script, txscript.OP_ENDIF)
+
+	case fragOrI:
+		script = append(script, txscript.OP_IF)
+		script = m.subs[0].appendScript(script)
+		script = append(script, txscript.OP_ELSE)
+		script = m.subs[1].appendScript(script)
+		return append(script, txscript.OP_ENDIF)
+
+	case fragThresh:
+		for i, sub := range m.subs {
+			script = sub.appendScript(script)
+			if i != 0 {
+				script = append(script, txscript.OP_ADD)
+			}
+		}
+		script = pushInt(script, int64(m.k))
+		return append(script, txscript.OP_EQUAL)
+
+	case fragMulti:
+		script = pushInt(script, int64(m.k))
+		for _, key := range m.keys {
+			script = pushData(script, key)
+		}
+		script = pushInt(script, int64(len(m.keys)))
+		return append(script, txscript.OP_CHECKMULTISIG)
+
+	case fragWrapA:
+		script = append(script, txscript.OP_TOALTSTACK)
+		script = m.subs[0].appendScript(script)
+		return append(script, txscript.OP_FROMALTSTACK)
+
+	case fragWrapS:
+		script = append(script, txscript.OP_SWAP)
+		return m.subs[0].appendScript(script)
+
+	case fragWrapC:
+		script = m.subs[0].appendScript(script)
+		return append(script, txscript.OP_CHECKSIG)
+
+	case fragWrapD:
+		script = append(script, txscript.OP_DUP, txscript.OP_IF)
+		script = m.subs[0].appendScript(script)
+		return append(script, txscript.OP_ENDIF)
+
+	case fragWrapV:
+		// Expressions without the x property end with an opcode which
+		// has a VERIFY variant, which replaces it.
+		script = m.subs[0].appendScript(script)
+		if m.subs[0].typ.has(typeX) {
+			return append(script, txscript.OP_VERIFY)
+		}
+		last := len(script) - 1
+		script[last] = verifyOpcodes[script[last]]
+		return script
+
+	case fragWrapJ:
+		script = append(script, txscript.OP_SIZE, txscript.OP_0NOTEQUAL,
+			txscript.OP_IF)
+		script = m.subs[0].appendScript(script)
+		return append(script, txscript.OP_ENDIF)
+
+	case fragWrapN:
+		script = m.subs[0].appendScript(script)
+		return append(script, txscript.OP_0NOTEQUAL)
+	}
+
+	return script
+}
+
+// PkScript returns the public key script of the P2WSH output which is spent
+// with the miniscript as its witness script.
+func (m *Miniscript) PkScript() []byte {
+	scriptHash := sha256.Sum256(m.Script())
+	pkScript, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_0).
+		AddData(scriptHash[:]).Script()
+	return pkScript
+}
+
+// walk calls the passed function for the expression and all of its
+// sub-expressions.
+func (m *Miniscript) walk(f func(node *Miniscript)) {
+	f(m)
+	for _, sub := range m.subs {
+		sub.walk(f)
+	}
+}
+
+// CheckSane returns an error when the miniscript isn't sane.  A miniscript is
+// sane when:
+//   - every satisfaction requires a signature
+//   - it can always be satisfied without third parties being able to modify
+//     the satisfaction
+//   - no satisfaction requires timelocks which can't be satisfied together
+//   - it doesn't contain a public key more than once
+//   - its script and satisfactions are within the standardness and consensus
+//     limits of P2WSH outputs
+func (m *Miniscript) CheckSane() error {
+	switch {
+	case !m.typ.has(typeS):
+		return fmt.Errorf("%v can be satisfied without a signature", m)
+	case !m.typ.has(typeM):
+		return fmt.Errorf("%v has malleable satisfactions", m)
+	case !m.typ.has(typeK2):
+		return fmt.Errorf("%v requires both a time and a height "+
+			"timelock of the same kind", m)
+	}
+
+	keys := make(map[string]struct{})
+	var duplicate []byte
+	numOps := 0
+	m.walk(func(node *Miniscript) {
+		for _, key := range node.keys {
+			if _, ok := keys[string(key)]; ok {
+				duplicate = key
+			}
+			keys[string(key)] = struct{}{}
+		}
+
+		// The public keys of multisig expressions count towards the
+		// operation limit.
+		if node.fragment == fragMulti {
+			numOps += len(node.keys)
+		}
+	})
+	if duplicate != nil {
+		return fmt.Errorf("%v contains the public key %x more than once",
+			m, duplicate)
+	}
+
+	script := m.Script()
+	if len(script) > maxStandardP2WSHScriptSize {
+		return fmt.Errorf("script size %d exceeds the maximum of %d",
+			len(script), maxStandardP2WSHScriptSize)
+	}
+	tokenizer := txscript.MakeScriptTokenizer(0, script)
+	for tokenizer.Next() {
+		if tokenizer.Opcode() > txscript.OP_16 {
+			numOps++
+		}
+	}
+	if numOps > txscript.MaxOpsPerScript {
+		return fmt.Errorf("script with %d operations exceeds the "+
+			"maximum of %d", numOps, txscript.MaxOpsPerScript)
+	}
+
+	sat, _ := m.maxWitness()
+	if sat.valid && sat.items > maxStandardP2WSHStackItems {
+		return fmt.Errorf("satisfaction with %d witness items exceeds "+
+			"the maximum of %d", sat.items,
+			maxStandardP2WSHStackItems)
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package miniscript
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Test public keys used throughout the tests.
+const (
+	testKey1 = "03d01115d548e7561b15c38f004d734633687cf4419620095bc5b0f47070afe85a"
+	testKey2 = "025601570cb47f238d2b0286db4a990fa0f3ba28d1a319f5e7cf55c2a2444da7cc"
+	testKey3 = "03daed4f2be3a8bf278e70132fb0beb7522f570e144bf615c07e996d443dee8729"
+	testKey4 = "024ce119c96e2fa357200b559b2f7dd5a5f02d5290aff74b03f3e471b273211c97"
+)
+
+// TestParse ensures valid miniscript expressions are parsed, type checked and
+// encoded to the expected scripts.
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		expr   string
+		typ    string
+		script string
+		sane   bool
+	}{{
+		name:   "pk",
+		expr:   "pk(" + testKey1 + ")",
+		typ:    "Bondusemk",
+		script: "21" + testKey1 + "ac",
+		sane:   true,
+	}, {
+		name:   "pkh",
+		expr:   "pkh(" + testKey1 + ")",
+		typ:    "Bndusemk",
+		script: "76a914dd100be7d9aea5721158ebde6d6a1fd8fff93bb188ac",
+		sane:   true,
+	}, {
+		name:   "older",
+		expr:   "older(144)",
+		typ:    "Bzfmxhk",
+		script: "029000b2",
+	}, {
+		name:   "verify pk",
+		expr:   "and_v(v:pk(" + testKey1 + "),older(144))",
+		typ:    "Bonsfmxhk",
+		script: "21" + testKey1 + "ad029000b2",
+		sane:   true,
+	}, {
+		name:   "wrapped after",
+		expr:   "lltvln:after(1231488000)",
+		typ:    "Bdumxik",
+		script: "6300676300676300670400046749b1926869516868",
+	}, {
+		name: "multi with timelock",
+		expr: "uuj:and_v(v:multi(2," + testKey1 + "," + testKey2 +
+			"),after(1231488000))",
+		script: "6363829263522103d01115d548e7561b15c38f004d734633687cf44" +
+			"19620095bc5b0f47070afe85a21025601570cb47f238d2b0286db" +
+			"4a990fa0f3ba28d1a319f5e7cf55c2a2444da7cc52af0400046749" +
+			"b168670068670068",
+		sane: true,
+	}, {
+		name: "or_b of multi and timelock",
+		expr: "or_b(un:multi(2," + testKey3 + "," + testKey4 +
+			"),al:older(16))",
+		script: "63522103daed4f2be3a8bf278e70132fb0beb7522f570e144bf615" +
+			"c07e996d443dee872921024ce119c96e2fa357200b559b2f7dd5a5" +
+			"f02d5290aff74b03f3e471b273211c9752ae926700686b630067" +
+			"60b2686c9b",
+	}, {
+		name:   "mixed relative timelocks",
+		expr:   "j:and_v(vdv:after(1567547623),older(2016))",
+		script: "829263766304e7e06e5db169686902e007b268",
+	}, {
+		name: "hashes",
+		expr: "t:and_v(vu:hash256(131772552c01444cd81360818376a040b7" +
+			"c3b2b7b0a53550ee3edde216cec61b),v:sha256(ec4916dd28fc" +
+			"4c10d78e287ca5d9cc51ee1ae73cbfde08c6b37324cbfaac8bc5))",
+		script: "6382012088aa20131772552c01444cd81360818376a040b7c3b2b7" +
+			"b0a53550ee3edde216cec61b876700686982012088a820ec4916dd" +
+			"28fc4c10d78e287ca5d9cc51ee1ae73cbfde08c6b37324cbfaac8b" +
+			"c58851",
+	}, {
+		name: "thresh",
+		expr: "thresh(2,pk(" + testKey1 + "),s:pk(" + testKey2 +
+			"),s:pk(" + testKey3 + "))",
+		script: "21" + testKey1 + "ac7c21" + testKey2 + "ac937c21" +
+			testKey3 + "ac935287",
+		sane: true,
+	}, {
+		name:   "and_n",
+		expr:   "and_n(pk(" + testKey1 + "),older(144))",
+		script: "21" + testKey1 + "ac640067029000b268",
+		sane:   true,
+	}}
+
+	for _, test := range tests {
+		m, err := Parse(test.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if got := m.String(); got != test.expr {
+			t.Errorf("%s: mismatched expression - got %s, want %s",
+				test.name, got, test.expr)
+		}
+		if test.typ != "" && m.Type().String() != test.typ {
+			t.Errorf("%s: mismatched type - got %v, want %s",
+				test.name, m.Type(), test.typ)
+		}
+		if got := hex.EncodeToString(m.Script()); got != test.script {
+			t.Errorf("%s: mismatched script - got %s, want %s",
+				test.name, got, test.script)
+		}
+		err = m.CheckSane()
+		if (err == nil) != test.sane {
+			t.Errorf("%s: mismatched sanity - got %v, want sane %v",
+				test.name, err, test.sane)
+		}
+	}
+}
+
+// TestParseErrors ensures invalid miniscript expressions are rejected.
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		expr string
+		err  string
+	}{{
+		name: "empty",
+		expr: "",
+		err:  "missing expression",
+	}, {
+		name: "unknown fragment",
+		expr: "pk_x(" + testKey1 + ")",
+		err:  "unknown miniscript fragment",
+	}, {
+		name: "unknown wrapper",
+		expr: "x:pk(" + testKey1 + ")",
+		err:  "unknown wrapper",
+	}, {
+		name: "unbalanced parentheses",
+		expr: "pk(" + testKey1,
+		err:  "missing closing parenthesis",
+	}, {
+		name: "trailing characters",
+		expr: "pk(" + testKey1 + "))",
+		err:  "unexpected characters",
+	}, {
+		name: "uncompressed key",
+		expr: "pk(04" + testKey1[2:] + testKey1[2:] + ")",
+		err:  "invalid public key",
+	}, {
+		name: "zero timelock",
+		expr: "older(0)",
+		err:  "invalid timelock",
+	}, {
+		name: "short hash",
+		expr: "sha256(ec4916dd)",
+		err:  "invalid hash",
+	}, {
+		name: "threshold too large",
+		expr: "multi(3," + testKey1 + "," + testKey2 + ")",
+		err:  "invalid threshold",
+	}, {
+		name: "invalid argument types",
+		expr: "and_v(pk(" + testKey1 + "),older(144))",
+		err:  "required types",
+	}, {
+		name: "not of type B",
+		expr: "v:pk(" + testKey1 + ")",
+		err:  "instead of B",
+	}}
+
+	for _, test := range tests {
+		_, err := Parse(test.expr)
+		if err == nil {
+			t.Errorf("%s: parsed invalid expression", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: unexpected error - got %v, want %q",
+				test.name, err, test.err)
+		}
+	}
+}
+
+// TestCheckSane ensures expressions which aren't sane are detected.
+func TestCheckSane(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		expr string
+		err  string
+	}{{
+		name: "no signature",
+		expr: "older(144)",
+		err:  "without a signature",
+	}, {
+		name: "malleable",
+		expr: "and_v(v:pk(" + testKey1 + "),or_i(older(144)," +
+			"after(100)))",
+		err: "malleable",
+	}, {
+		name: "mixed timelocks",
+		expr: "and_v(v:pk(" + testKey1 + "),and_v(v:older(144)," +
+			"older(4194305)))",
+		err: "timelock",
+	}, {
+		name: "duplicate keys",
+		expr: "and_v(v:pk(" + testKey1 + "),pk(" + testKey1 + "))",
+		err:  "more than once",
+	}}
+
+	for _, test := range tests {
+		m, err := Parse(test.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		err = m.CheckSane()
+		if err == nil {
+			t.Errorf("%s: insane expression passed", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: unexpected error - got %v, want %q",
+				test.name, err, test.err)
+		}
+	}
+}
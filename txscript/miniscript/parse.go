@@ -0,0 +1,153 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package miniscript
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// exprTree is a parsed expression of the form name(arg,...) which is the
+// common syntax of miniscript and policy expressions.  Arguments which are
+// not expressions themselves, such as keys and numbers, are trees without
+// arguments.
+type exprTree struct {
+	name string
+	args []*exprTree
+}
+
+// parseExprTree parses the passed expression into a tree.
+func parseExprTree(expr string) (*exprTree, error) {
+	tree, rest, err := parseExprTreePrefix(expr)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("unexpected characters %q after the "+
+			"expression", rest)
+	}
+	return tree, nil
+}
+
+// parseExprTreePrefix parses the expression at the start of the passed string
+// into a tree and returns the remainder of the string.
+func parseExprTreePrefix(expr string) (*exprTree, string, error) {
+	end := strings.IndexAny(expr, "(),")
+	if end == -1 {
+		end = len(expr)
+	}
+	if end == 0 {
+		return nil, "", fmt.Errorf("missing expression at %q", expr)
+	}
+	tree := &exprTree{name: expr[:end]}
+	if strings.ContainsAny(tree.name, " \t\r\n") {
+		return nil, "", fmt.Errorf("invalid whitespace in %q", tree.name)
+	}
+
+	expr = expr[end:]
+	if !strings.HasPrefix(expr, "(") {
+		return tree, expr, nil
+	}
+	expr = expr[1:]
+	for {
+		arg, rest, err := parseExprTreePrefix(expr)
+		if err != nil {
+			return nil, "", err
+		}
+		tree.args = append(tree.args, arg)
+
+		switch {
+		case strings.HasPrefix(rest, ","):
+			expr = rest[1:]
+		case strings.HasPrefix(rest, ")"):
+			return tree, rest[1:], nil
+		default:
+			return nil, "", fmt.Errorf("missing closing parenthesis "+
+				"of %s", tree.name)
+		}
+	}
+}
+
+// String returns the expression the tree was parsed from.
+func (t *exprTree) String() string {
+	if len(t.args) == 0 {
+		return t.name
+	}
+	args := make([]string, 0, len(t.args))
+	for _, arg := range t.args {
+		args = append(args, arg.String())
+	}
+	return t.name + "(" + strings.Join(args, ",") + ")"
+}
+
+// checkArgs returns an error when the tree doesn't have the passed number of
+// arguments.
+func (t *exprTree) checkArgs(num int) error {
+	if len(t.args) != num {
+		return fmt.Errorf("%s takes %d arguments instead of %d", t.name,
+			num, len(t.args))
+	}
+	return nil
+}
+
+// leaf returns the argument at the passed index, which must not have
+// arguments itself.
+func (t *exprTree) leaf(idx int) (string, error) {
+	arg := t.args[idx]
+	if len(arg.args) != 0 {
+		return "", fmt.Errorf("unexpected expression %s as argument "+
+			"of %s", arg, t.name)
+	}
+	return arg.name, nil
+}
+
+// parseKey parses a hex-encoded compressed public key.
+func parseKey(str string) ([]byte, error) {
+	key, err := hex.DecodeString(str)
+	if err != nil || len(key) != 33 {
+		return nil, fmt.Errorf("invalid public key %q: expected a "+
+			"hex-encoded compressed public key", str)
+	}
+	if _, err := btcec.ParsePubKey(key, btcec.S256()); err != nil {
+		return nil, fmt.Errorf("invalid public key %q: %v", str, err)
+	}
+	return key, nil
+}
+
+// parseHash parses a hex-encoded hash of the passed size.
+func parseHash(str string, size int) ([]byte, error) {
+	hash, err := hex.DecodeString(str)
+	if err != nil || len(hash) != size {
+		return nil, fmt.Errorf("invalid hash %q: expected %d "+
+			"hex-encoded bytes", str, size)
+	}
+	return hash, nil
+}
+
+// parseTimelock parses the value of a relative or absolute timelock, which
+// must be between 1 and 2^31-1.
+func parseTimelock(str string) (uint32, error) {
+	value, err := strconv.ParseUint(str, 10, 32)
+	if err != nil || value == 0 || value >= 1<<31 {
+		return 0, fmt.Errorf("invalid timelock %q: expected a number "+
+			"between 1 and %d", str, uint32(1<<31-1))
+	}
+	return uint32(value), nil
+}
+
+// parseThreshold parses the threshold of an expression with the passed number
+// of sub-expressions or keys, which must be between 1 and that number.
+func parseThreshold(str string, num int) (uint32, error) {
+	k, err := strconv.ParseUint(str, 10, 32)
+	if err != nil || k == 0 || k > uint64(num) {
+		return 0, fmt.Errorf("invalid threshold %q: expected a number "+
+			"between 1 and %d", str, num)
+	}
+	return uint32(k), nil
+}
@@ -0,0 +1,492 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package miniscript
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// policyKind identifies the kind of a policy expression.
+type policyKind uint8
+
+// These constants define the kinds of policy expressions.
+const (
+	policyKey    policyKind = iota // pk(key)
+	policyOlder                    // older(n)
+	policyAfter                    // after(n)
+	policyHash                     // sha256(h), hash256(h), ...
+	policyAnd                      // and(X,Y)
+	policyOr                       // or([n@]X,[n@]Y)
+	policyThresh                   // thresh(k,X1,...,Xn)
+)
+
+// Policy is a parsed spending policy, which describes the conditions under
+// which an output can be spent without specifying the script.
+type Policy struct {
+	kind policyKind
+
+	// k is the threshold of thresh policies or the value of timelocks.
+	k uint32
+
+	// key is the serialized public key of pk policies.
+	key []byte
+
+	// hashFrag and hash are the hash fragment and hash of hash policies.
+	hashFrag fragment
+	hash     []byte
+
+	// subs are the sub-policies and weights the relative probabilities
+	// of the sub-policies of or policies being used to spend the output.
+	subs    []*Policy
+	weights []uint32
+}
+
+// ParsePolicy parses the passed policy expression, which consists of pk(K),
+// older(n), after(n), sha256(h), hash256(h), ripemd160(h), hash160(h),
+// and(X,Y), or(X,Y) and thresh(k,X1,...,Xn) expressions.  The sub-policies of
+// or expressions can be prefixed with their relative probability of being
+// used, such as or(9@pk(K1),1@pk(K2)).
+func ParsePolicy(expr string) (*Policy, error) {
+	tree, err := parseExprTree(expr)
+	if err != nil {
+		return nil, err
+	}
+	return policyFromTree(tree)
+}
+
+// policyFromTree returns the policy of the passed parsed tree.
+func policyFromTree(tree *exprTree) (*Policy, error) {
+	switch tree.name {
+	case "pk":
+		if err := tree.checkArgs(1); err != nil {
+			return nil, err
+		}
+		arg, err := tree.leaf(0)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseKey(arg)
+		if err != nil {
+			return nil, err
+		}
+		return &Policy{kind: policyKey, key: key}, nil
+
+	case "older", "after":
+		if err := tree.checkArgs(1); err != nil {
+			return nil, err
+		}
+		arg, err := tree.leaf(0)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseTimelock(arg)
+		if err != nil {
+			return nil, err
+		}
+		kind := policyOlder
+		if tree.name == "after" {
+			kind = policyAfter
+		}
+		return &Policy{kind: kind, k: value}, nil
+
+	case "sha256", "hash256", "ripemd160", "hash160":
+		if err := tree.checkArgs(1); err != nil {
+			return nil, err
+		}
+		arg, err := tree.leaf(0)
+		if err != nil {
+			return nil, err
+		}
+		hashFrag := hashFragments[tree.name]
+		hash, err := parseHash(arg, hashFrag.size)
+		if err != nil {
+			return nil, err
+		}
+		return &Policy{
+			kind:     policyHash,
+			hashFrag: hashFrag.fragment,
+			hash:     hash,
+		}, nil
+
+	case "and":
+		if err := tree.checkArgs(2); err != nil {
+			return nil, err
+		}
+		subs, err := policiesFromTrees(tree.args)
+		if err != nil {
+			return nil, err
+		}
+		return &Policy{kind: policyAnd, subs: subs}, nil
+
+	case "or":
+		if err := tree.checkArgs(2); err != nil {
+			return nil, err
+		}
+		subs := make([]*Policy, 0, len(tree.args))
+		weights := make([]uint32, 0, len(tree.args))
+		for _, arg := range tree.args {
+			weight := uint64(1)
+			if idx := strings.IndexByte(arg.name, '@'); idx != -1 {
+				var err error
+				weight, err = strconv.ParseUint(arg.name[:idx],
+					10, 32)
+				if err != nil || weight == 0 {
+					return nil, fmt.Errorf("invalid "+
+						"probability %q: expected a "+
+						"positive number",
+						arg.name[:idx])
+				}
+				arg = &exprTree{
+					name: arg.name[idx+1:],
+					args: arg.args,
+				}
+			}
+			sub, err := policyFromTree(arg)
+			if err != nil {
+				return nil, err
+			}
+			subs = append(subs, sub)
+			weights = append(weights, uint32(weight))
+		}
+		return &Policy{kind: policyOr, subs: subs, weights: weights}, nil
+
+	case "thresh":
+		if len(tree.args) < 2 {
+			return nil, fmt.Errorf("thresh requires a threshold and " +
+				"at least one sub-policy")
+		}
+		arg, err := tree.leaf(0)
+		if err != nil {
+			return nil, err
+		}
+		k, err := parseThreshold(arg, len(tree.args)-1)
+		if err != nil {
+			return nil, err
+		}
+		subs, err := policiesFromTrees(tree.args[1:])
+		if err != nil {
+			return nil, err
+		}
+		return &Policy{kind: policyThresh, k: k, subs: subs}, nil
+	}
+
+	return nil, fmt.Errorf("unknown policy %q", tree.name)
+}
+
+// policiesFromTrees returns the policies of the passed parsed trees.
+func policiesFromTrees(trees []*exprTree) ([]*Policy, error) {
+	policies := make([]*Policy, 0, len(trees))
+	for _, tree := range trees {
+		policy, err := policyFromTree(tree)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// String returns the policy expression of the policy.
+func (p *Policy) String() string {
+	switch p.kind {
+	case policyKey:
+		return "pk(" + hex.EncodeToString(p.key) + ")"
+	case policyOlder:
+		return fmt.Sprintf("older(%d)", p.k)
+	case policyAfter:
+		return fmt.Sprintf("after(%d)", p.k)
+	case policyHash:
+		return fragmentNames[p.hashFrag] + "(" +
+			hex.EncodeToString(p.hash) + ")"
+	}
+
+	var args []string
+	if p.kind == policyThresh {
+		args = append(args, strconv.FormatUint(uint64(p.k), 10))
+	}
+	for i, sub := range p.subs {
+		arg := sub.String()
+		if p.kind == policyOr && p.weights[i] != 1 {
+			arg = fmt.Sprintf("%d@%s", p.weights[i], arg)
+		}
+		args = append(args, arg)
+	}
+	name := "thresh"
+	switch p.kind {
+	case policyAnd:
+		name = "and"
+	case policyOr:
+		name = "or"
+	}
+	return name + "(" + strings.Join(args, ",") + ")"
+}
+
+// compilerWrappers are the letters of the wrappers the compiler tries on every
+// candidate expression.
+const compilerWrappers = "ascdvjn"
+
+// candidate is a compiled expression along with its expected spending cost.
+type candidate struct {
+	node *Miniscript
+	str  string
+	cost float64
+}
+
+// newCandidate returns the passed expression as a candidate along with its
+// expected spending cost, which consists of the size of its script and the
+// sizes of its witnesses weighted by the passed probabilities of the
+// expression being satisfied and dissatisfied respectively.
+func newCandidate(node *Miniscript, pSat, pDissat float64) *candidate {
+	sat, nsat := node.maxWitness()
+	cost := float64(len(node.Script()))
+	for _, term := range []struct {
+		p    float64
+		size witnessSize
+	}{{pSat, sat}, {pDissat, nsat}} {
+		if term.p == 0 {
+			continue
+		}
+		if !term.size.valid {
+			cost = math.Inf(1)
+			break
+		}
+		cost += term.p * float64(term.size.size)
+	}
+	return &candidate{node: node, str: node.String(), cost: cost}
+}
+
+// betterThan returns whether the candidate is cheaper than the passed one,
+// where ties are broken by the expressions to keep compilation deterministic.
+func (c *candidate) betterThan(o *candidate) bool {
+	if c.cost != o.cost {
+		return c.cost < o.cost
+	}
+	return c.str < o.str
+}
+
+// candidates houses the cheapest compiled expression of every type for a
+// policy.
+type candidates map[Type]*candidate
+
+// add adds the passed expression unless it's invalid or a cheaper one of the
+// same type already exists, and returns whether it was added.
+func (cs candidates) add(node *Miniscript, err error, pSat,
+	pDissat float64) bool {
+
+	if err != nil {
+		return false
+	}
+	c := newCandidate(node, pSat, pDissat)
+	if existing, ok := cs[node.typ]; ok && !c.betterThan(existing) {
+		return false
+	}
+	cs[node.typ] = c
+	return true
+}
+
+// sorted returns the candidates ordered by their expressions so compilation
+// doesn't depend on the iteration order of maps.
+func (cs candidates) sorted() []*candidate {
+	sorted := make([]*candidate, 0, len(cs))
+	for _, c := range cs {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].str < sorted[j].str
+	})
+	return sorted
+}
+
+// best returns the cheapest candidate which has all of the passed types and
+// properties, or nil when there is none.
+func (cs candidates) best(props Type) *candidate {
+	var best *candidate
+	for _, c := range cs {
+		if c.node.typ.has(props) && (best == nil || c.betterThan(best)) {
+			best = c
+		}
+	}
+	return best
+}
+
+// addWrapped adds the candidates wrapped by every wrapper until no cheaper
+// candidate of any type is found.
+func (cs candidates) addWrapped(pSat, pDissat float64) {
+	for {
+		added := false
+		for _, c := range cs.sorted() {
+			for i := 0; i < len(compilerWrappers); i++ {
+				node, err := wrap(compilerWrappers[i], c.node)
+				if cs.add(node, err, pSat, pDissat) {
+					added = true
+				}
+			}
+		}
+		if !added {
+			return
+		}
+	}
+}
+
+// compile returns the candidate expressions of the policy for the passed
+// probabilities of it being satisfied and dissatisfied.
+func (p *Policy) compile(pSat, pDissat float64) candidates {
+	cs := make(candidates)
+	switch p.kind {
+	case policyKey:
+		key := [][]byte{p.key}
+		node, err := newMiniscript(fragPkK, 0, key, nil)
+		cs.add(node, err, pSat, pDissat)
+		node, err = newMiniscript(fragPkH, 0, key, nil)
+		cs.add(node, err, pSat, pDissat)
+
+	case policyOlder, policyAfter:
+		frag := fragOlder
+		if p.kind == policyAfter {
+			frag = fragAfter
+		}
+		node, err := newMiniscript(frag, p.k, nil, nil)
+		cs.add(node, err, pSat, pDissat)
+
+	case policyHash:
+		node, err := newMiniscript(p.hashFrag, 0, nil, p.hash)
+		cs.add(node, err, pSat, pDissat)
+
+	case policyAnd:
+		subs := [2]candidates{
+			p.subs[0].compile(pSat, pDissat),
+			p.subs[1].compile(pSat, pDissat),
+		}
+		zero, _ := newMiniscript(fragJust0, 0, nil, nil)
+		for _, order := range [][2]int{{0, 1}, {1, 0}} {
+			for _, x := range subs[order[0]].sorted() {
+				for _, y := range subs[order[1]].sorted() {
+					for _, frag := range []fragment{
+						fragAndV, fragAndB,
+					} {
+						node, err := newMiniscript(frag,
+							0, nil, nil, x.node,
+							y.node)
+						cs.add(node, err, pSat, pDissat)
+					}
+					node, err := newMiniscript(fragAndOr,
+						0, nil, nil, x.node, y.node,
+						zero)
+					cs.add(node, err, pSat, pDissat)
+				}
+			}
+		}
+
+	case policyOr:
+		total := float64(p.weights[0]) + float64(p.weights[1])
+		probs := [2]float64{
+			float64(p.weights[0]) / total,
+			float64(p.weights[1]) / total,
+		}
+		subs := [2]candidates{
+			p.subs[0].compile(pSat*probs[0],
+				pDissat+pSat*probs[1]),
+			p.subs[1].compile(pSat*probs[1],
+				pDissat+pSat*probs[0]),
+		}
+		for _, order := range [][2]int{{0, 1}, {1, 0}} {
+			for _, x := range subs[order[0]].sorted() {
+				for _, z := range subs[order[1]].sorted() {
+					for _, frag := range []fragment{
+						fragOrB, fragOrC, fragOrD,
+						fragOrI,
+					} {
+						node, err := newMiniscript(frag,
+							0, nil, nil, x.node,
+							z.node)
+						cs.add(node, err, pSat, pDissat)
+					}
+				}
+			}
+		}
+
+	case policyThresh:
+		p.compileThresh(cs, pSat, pDissat)
+	}
+
+	cs.addWrapped(pSat, pDissat)
+	return cs
+}
+
+// compileThresh adds the candidate expressions of a thresh policy for the
+// passed probabilities of it being satisfied and dissatisfied.
+func (p *Policy) compileThresh(cs candidates, pSat, pDissat float64) {
+	// Thresholds of keys only can be compiled to multisig expressions.
+	keys := make([][]byte, 0, len(p.subs))
+	for _, sub := range p.subs {
+		if sub.kind == policyKey {
+			keys = append(keys, sub.key)
+		}
+	}
+	if len(keys) == len(p.subs) {
+		node, err := newMiniscript(fragMulti, p.k, keys, nil)
+		cs.add(node, err, pSat, pDissat)
+	}
+
+	// Otherwise, the first sub-expression must be of type Bdu and the
+	// others of type Wdu.  Every sub-expression is satisfied with the
+	// probability of k of the n sub-policies being satisfied.
+	n := float64(len(p.subs))
+	subSat := pSat * float64(p.k) / n
+	subDissat := pDissat + pSat*(n-float64(p.k))/n
+	subs := make([]*Miniscript, 0, len(p.subs))
+	for i, sub := range p.subs {
+		props := typeW | typeD | typeU
+		if i == 0 {
+			props = typeB | typeD | typeU
+		}
+		best := sub.compile(subSat, subDissat).best(props)
+		if best == nil {
+			return
+		}
+		subs = append(subs, best.node)
+	}
+	node, err := newMiniscript(fragThresh, p.k, nil, nil, subs...)
+	cs.add(node, err, pSat, pDissat)
+}
+
+// Compile returns the sane miniscript with the lowest expected spending cost
+// the compiler finds which implements the policy, taking the probabilities of
+// the branches of or policies into account.  An error is returned when no such miniscript is
+// found, such as when the policy contains a public key more than once or can
+// be satisfied without a signature.
+func (p *Policy) Compile() (*Miniscript, error) {
+	cs := p.compile(1, 0)
+
+	var best *candidate
+	var bestErr error
+	for _, c := range cs.sorted() {
+		if !c.node.typ.has(typeB) {
+			continue
+		}
+		if best != nil && !c.betterThan(best) {
+			continue
+		}
+		if err := c.node.CheckSane(); err != nil {
+			bestErr = err
+			continue
+		}
+		best = c
+	}
+	if best == nil {
+		if bestErr == nil {
+			bestErr = errors.New("no miniscript of type B found")
+		}
+		return nil, fmt.Errorf("unable to compile policy %v: %v", p,
+			bestErr)
+	}
+	return best.node, nil
+}
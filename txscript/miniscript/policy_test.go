@@ -0,0 +1,154 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package miniscript
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestCompile ensures policies are compiled to the expected sane miniscripts
+// which can be satisfied by the expected keys.
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	privKeys, pubKeys := testPrivKeys(3)
+
+	tests := []struct {
+		name     string
+		policy   string
+		expr     string
+		signers  []int
+		version  int32
+		sequence uint32
+	}{{
+		name:     "pk",
+		policy:   "pk(%[1]s)",
+		expr:     "pk(%[1]s)",
+		signers:  []int{0},
+		sequence: wire.MaxTxInSequenceNum,
+	}, {
+		name:     "and with timelock",
+		policy:   "and(pk(%[1]s),older(144))",
+		expr:     "and_v(v:pk(%[1]s),older(144))",
+		signers:  []int{0},
+		version:  2,
+		sequence: 144,
+	}, {
+		name:     "threshold of keys",
+		policy:   "thresh(2,pk(%[1]s),pk(%[2]s),pk(%[3]s))",
+		expr:     "multi(2,%[1]s,%[2]s,%[3]s)",
+		signers:  []int{0, 2},
+		sequence: wire.MaxTxInSequenceNum,
+	}, {
+		name:     "likely key with timelocked recovery",
+		policy:   "or(99@pk(%[1]s),and(pk(%[2]s),older(144)))",
+		expr:     "or_d(pk(%[1]s),and_v(v:pk(%[2]s),older(144)))",
+		signers:  []int{0},
+		sequence: wire.MaxTxInSequenceNum,
+	}, {
+		name:     "timelocked recovery",
+		policy:   "or(99@pk(%[1]s),and(pk(%[2]s),older(144)))",
+		expr:     "or_d(pk(%[1]s),and_v(v:pk(%[2]s),older(144)))",
+		signers:  []int{1},
+		version:  2,
+		sequence: 144,
+	}}
+
+	for _, test := range tests {
+		policy, err := ParsePolicy(fmt.Sprintf(test.policy, pubKeys...))
+		if err != nil {
+			t.Errorf("%s: unexpected parse error: %v", test.name, err)
+			continue
+		}
+		if got := policy.String(); got != fmt.Sprintf(test.policy,
+			pubKeys...) {
+
+			t.Errorf("%s: mismatched policy - got %s", test.name, got)
+		}
+		m, err := policy.Compile()
+		if err != nil {
+			t.Errorf("%s: unexpected compile error: %v", test.name,
+				err)
+			continue
+		}
+		if want := fmt.Sprintf(test.expr, pubKeys...); m.String() != want {
+			t.Errorf("%s: mismatched miniscript - got %v, want %s",
+				test.name, m, want)
+		}
+
+		signers := make([]*btcec.PrivateKey, 0, len(test.signers))
+		for _, idx := range test.signers {
+			signers = append(signers, privKeys[idx])
+		}
+		tx, satisfier, err := testSpendTx(m, signers, test.version,
+			test.sequence, 0)
+		if err != nil {
+			t.Errorf("%s: unable to sign: %v", test.name, err)
+			continue
+		}
+		witness, err := m.Satisfy(satisfier)
+		if err != nil {
+			t.Errorf("%s: unexpected satisfy error: %v", test.name,
+				err)
+			continue
+		}
+		tx.TxIn[0].Witness = witness
+		if err := executeTestSpend(m, tx); err != nil {
+			t.Errorf("%s: invalid witness: %v", test.name, err)
+		}
+	}
+}
+
+// TestPolicyErrors ensures invalid policies and policies without sane
+// miniscripts are rejected.
+func TestPolicyErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{{
+		name:   "unknown policy",
+		policy: "pkh(" + testKey1 + ")",
+		err:    "unknown policy",
+	}, {
+		name:   "zero probability",
+		policy: "or(0@pk(" + testKey1 + "),pk(" + testKey2 + "))",
+		err:    "invalid probability",
+	}, {
+		name:   "and with three arguments",
+		policy: "and(pk(" + testKey1 + "),pk(" + testKey2 + "),older(1))",
+		err:    "takes 2 arguments",
+	}, {
+		name:   "no signature",
+		policy: "or(pk(" + testKey1 + "),older(144))",
+		err:    "unable to compile",
+	}, {
+		name:   "duplicate keys",
+		policy: "and(pk(" + testKey1 + "),pk(" + testKey1 + "))",
+		err:    "unable to compile",
+	}}
+
+	for _, test := range tests {
+		policy, err := ParsePolicy(test.policy)
+		if err == nil {
+			_, err = policy.Compile()
+		}
+		if err == nil {
+			t.Errorf("%s: compiled invalid policy", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: unexpected error - got %v, want %q",
+				test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,658 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package miniscript
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"golang.org/x/crypto/ripemd160"
+)
+
+const (
+	// preimageSize is the size of the preimages of hash expressions.
+	preimageSize = 32
+
+	// maxSigSize is the maximum size of an ECDSA signature including its
+	// hash type.
+	maxSigSize = 73
+)
+
+// Satisfier provides the signatures, preimages and timelock information needed
+// to satisfy a miniscript.
+type Satisfier interface {
+	// Signature returns the signature, including its hash type, by the
+	// passed serialized public key of the transaction spending the output,
+	// or nil when it's not available.
+	Signature(pubKey []byte) []byte
+
+	// Preimage returns the 32-byte preimage of the passed hash for the
+	// hash function of the passed hash fragment name, which is one of
+	// sha256, hash256, ripemd160 and hash160, or nil when it's not
+	// available.
+	Preimage(hashFunc string, hash []byte) []byte
+
+	// CheckOlder returns whether the relative timelock with the passed
+	// value is satisfied by the input spending the output.
+	CheckOlder(sequence uint32) bool
+
+	// CheckAfter returns whether the absolute timelock with the passed
+	// value is satisfied by the transaction spending the output.
+	CheckAfter(lockTime uint32) bool
+}
+
+// BasicSatisfier is a Satisfier which provides a fixed set of signatures and
+// preimages for a transaction input.
+type BasicSatisfier struct {
+	// Signatures maps hex-encoded public keys to their signatures
+	// including the hash type.
+	Signatures map[string][]byte
+
+	// Preimages are the available preimages of hashes.
+	Preimages [][]byte
+
+	// TxVersion and LockTime are the version and lock time of the spending
+	// transaction and Sequence is the sequence number of the spending
+	// input.
+	TxVersion int32
+	LockTime  uint32
+	Sequence  uint32
+}
+
+// Ensure BasicSatisfier implements the Satisfier interface.
+var _ Satisfier = (*BasicSatisfier)(nil)
+
+// Signature returns the signature by the passed public key.
+//
+// This is part of the Satisfier interface.
+func (s *BasicSatisfier) Signature(pubKey []byte) []byte {
+	return s.Signatures[hex.EncodeToString(pubKey)]
+}
+
+// hashPreimage returns the hash of the passed preimage with the hash function
+// of the passed hash fragment name.
+func hashPreimage(hashFunc string, preimage []byte) []byte {
+	switch hashFunc {
+	case "sha256":
+		hash := sha256.Sum256(preimage)
+		return hash[:]
+	case "hash256":
+		return chainhash.DoubleHashB(preimage)
+	case "ripemd160":
+		hasher := ripemd160.New()
+		hasher.Write(preimage)
+		return hasher.Sum(nil)
+	case "hash160":
+		return btcutil.Hash160(preimage)
+	}
+	return nil
+}
+
+// Preimage returns the preimage of the passed hash among the available
+// preimages.
+//
+// This is part of the Satisfier interface.
+func (s *BasicSatisfier) Preimage(hashFunc string, hash []byte) []byte {
+	for _, preimage := range s.Preimages {
+		if len(preimage) != preimageSize {
+			continue
+		}
+		if bytes.Equal(hashPreimage(hashFunc, preimage), hash) {
+			return preimage
+		}
+	}
+	return nil
+}
+
+// CheckOlder returns whether the sequence number of the input satisfies the
+// relative timelock according to BIP0068 and BIP0112.
+//
+// This is part of the Satisfier interface.
+func (s *BasicSatisfier) CheckOlder(sequence uint32) bool {
+	const typeMask = wire.SequenceLockTimeIsSeconds
+	return s.TxVersion >= 2 &&
+		s.Sequence&wire.SequenceLockTimeDisabled == 0 &&
+		s.Sequence&typeMask == sequence&typeMask &&
+		s.Sequence&wire.SequenceLockTimeMask >=
+			sequence&wire.SequenceLockTimeMask
+}
+
+// CheckAfter returns whether the lock time of the transaction satisfies the
+// absolute timelock according to BIP0065.
+//
+// This is part of the Satisfier interface.
+func (s *BasicSatisfier) CheckAfter(lockTime uint32) bool {
+	const threshold = txscript.LockTimeThreshold
+	return s.Sequence != wire.MaxTxInSequenceNum &&
+		(s.LockTime < threshold) == (lockTime < threshold) &&
+		s.LockTime >= lockTime
+}
+
+// witness is a candidate witness stack satisfying or dissatisfying an
+// expression, where the last item is the top of the stack.
+type witness struct {
+	stack [][]byte
+
+	// available is whether the witness could be produced.  hasSig is
+	// whether it contains a signature, and malleable is whether third
+	// parties could replace it with another witness.
+	available bool
+	hasSig    bool
+	malleable bool
+}
+
+var (
+	// unavailable is a witness which can't be produced.
+	unavailable = witness{}
+
+	// emptyWitness is a witness without any items.
+	emptyWitness = witness{available: true}
+
+	// zeroWitness and oneWitness are witnesses with a single item which is
+	// zero and one respectively.
+	zeroWitness = witness{stack: [][]byte{{}}, available: true}
+	oneWitness  = witness{stack: [][]byte{{1}}, available: true}
+)
+
+// newWitness returns a witness with the passed item when it's not nil and an
+// unavailable one otherwise.
+func newWitness(item []byte) witness {
+	if item == nil {
+		return unavailable
+	}
+	return witness{stack: [][]byte{item}, available: true}
+}
+
+// withSig returns the witness marked as containing a signature.
+func (w witness) withSig() witness {
+	w.hasSig = true
+	return w
+}
+
+// withMalleable returns the witness marked as malleable.
+func (w witness) withMalleable() witness {
+	w.malleable = true
+	return w
+}
+
+// then returns the passed witness on top of the witness, which is the witness
+// of an expression executed before the one of the witness.
+func (w witness) then(top witness) witness {
+	if !w.available || !top.available {
+		return unavailable
+	}
+	stack := make([][]byte, 0, len(w.stack)+len(top.stack))
+	stack = append(stack, w.stack...)
+	stack = append(stack, top.stack...)
+	return witness{
+		stack:     stack,
+		available: true,
+		hasSig:    w.hasSig || top.hasSig,
+		malleable: w.malleable || top.malleable,
+	}
+}
+
+// size returns the serialized size of the items of the witness.
+func (w witness) size() int {
+	size := 0
+	for _, item := range w.stack {
+		size += wire.VarIntSerializeSize(uint64(len(item))) + len(item)
+	}
+	return size
+}
+
+// choose returns the witness which should be used when either of the passed
+// witnesses can be.  Witnesses which require a signature can always be
+// replaced by third parties with those that don't, so the latter are
+// preferred.  Otherwise, non-malleable witnesses are preferred over malleable
+// ones, followed by the smaller witness.
+func choose(a, b witness) witness {
+	switch {
+	case !a.available:
+		return b
+	case !b.available:
+		return a
+	case !a.hasSig && b.hasSig:
+		return a
+	case !b.hasSig && a.hasSig:
+		return b
+	}
+
+	if !a.hasSig && !b.hasSig {
+		// When neither requires a signature, third parties can use
+		// either one.
+		a.malleable = true
+		b.malleable = true
+	} else {
+		if b.malleable && !a.malleable {
+			return a
+		}
+		if a.malleable && !b.malleable {
+			return b
+		}
+	}
+
+	if a.size() <= b.size() {
+		return a
+	}
+	return b
+}
+
+// satisfaction houses the best witnesses satisfying and dissatisfying an
+// expression.
+type satisfaction struct {
+	sat, nsat witness
+}
+
+// satisfy returns the best witnesses satisfying and dissatisfying the
+// expression with what the passed satisfier provides.
+func (m *Miniscript) satisfy(s Satisfier) satisfaction {
+	subs := make([]satisfaction, 0, len(m.subs))
+	for _, sub := range m.subs {
+		subs = append(subs, sub.satisfy(s))
+	}
+	var x, y, z satisfaction
+	switch len(subs) {
+	case 3:
+		z = subs[2]
+		fallthrough
+	case 2:
+		y = subs[1]
+		fallthrough
+	case 1:
+		x = subs[0]
+	}
+
+	switch m.fragment {
+	case fragJust0:
+		return satisfaction{sat: unavailable, nsat: emptyWitness}
+
+	case fragJust1:
+		return satisfaction{sat: emptyWitness, nsat: unavailable}
+
+	case fragPkK:
+		sig := newWitness(s.Signature(m.keys[0])).withSig()
+		return satisfaction{sat: sig, nsat: zeroWitness}
+
+	case fragPkH:
+		key := newWitness(m.keys[0])
+		sig := newWitness(s.Signature(m.keys[0])).withSig()
+		return satisfaction{
+			sat:  sig.then(key),
+			nsat: zeroWitness.then(key),
+		}
+
+	case fragOlder:
+		if s.CheckOlder(m.k) {
+			return satisfaction{sat: emptyWitness, nsat: unavailable}
+		}
+		return satisfaction{sat: unavailable, nsat: unavailable}
+
+	case fragAfter:
+		if s.CheckAfter(m.k) {
+			return satisfaction{sat: emptyWitness, nsat: unavailable}
+		}
+		return satisfaction{sat: unavailable, nsat: unavailable}
+
+	case fragSha256, fragHash256, fragRipemd160, fragHash160:
+		// Any other value of the right size dissatisfies a hash
+		// expression, so the dissatisfaction is malleable.
+		preimage := s.Preimage(fragmentNames[m.fragment], m.hash)
+		zero32 := newWitness(make([]byte, preimageSize)).withMalleable()
+		return satisfaction{sat: newWitness(preimage), nsat: zero32}
+
+	case fragAndOr:
+		return satisfaction{
+			sat: choose(y.sat.then(x.sat), z.sat.then(x.nsat)),
+			nsat: choose(y.nsat.then(x.sat).withMalleable(),
+				z.nsat.then(x.nsat)),
+		}
+
+	case fragAndV:
+		return satisfaction{
+			sat:  y.sat.then(x.sat),
+			nsat: y.nsat.then(x.sat),
+		}
+
+	case fragAndB:
+		nsat := choose(y.nsat.then(x.nsat),
+			y.sat.then(x.nsat).withMalleable())
+		nsat = choose(nsat, y.nsat.then(x.sat).withMalleable())
+		return satisfaction{sat: y.sat.then(x.sat), nsat: nsat}
+
+	case fragOrB:
+		sat := choose(z.nsat.then(x.sat), z.sat.then(x.nsat))
+		sat = choose(sat, z.sat.then(x.sat).withMalleable())
+		return satisfaction{sat: sat, nsat: z.nsat.then(x.nsat)}
+
+	case fragOrC:
+		return satisfaction{
+			sat:  choose(x.sat, y.sat.then(x.nsat)),
+			nsat: unavailable,
+		}
+
+	case fragOrD:
+		return satisfaction{
+			sat:  choose(x.sat, y.sat.then(x.nsat)),
+			nsat: y.nsat.then(x.nsat),
+		}
+
+	case fragOrI:
+		return satisfaction{
+			sat: choose(x.sat.then(oneWitness),
+				y.sat.then(zeroWitness)),
+			nsat: choose(x.nsat.then(oneWitness),
+				y.nsat.then(zeroWitness)),
+		}
+
+	case fragThresh:
+		return satisfyThresh(int(m.k), subs)
+
+	case fragMulti:
+		return m.satisfyMulti(s)
+
+	case fragWrapA, fragWrapS, fragWrapC, fragWrapN:
+		return x
+
+	case fragWrapD:
+		return satisfaction{sat: x.sat.then(oneWitness), nsat: zeroWitness}
+
+	case fragWrapV:
+		return satisfaction{sat: x.sat, nsat: unavailable}
+
+	case fragWrapJ:
+		// The dissatisfaction of the sub-expression is an alternative
+		// to the empty one when its top item is nonzero.
+		nsat := zeroWitness
+		if x.nsat.available && !x.nsat.hasSig {
+			nsat = nsat.withMalleable()
+		}
+		return satisfaction{sat: x.sat, nsat: nsat}
+	}
+
+	return satisfaction{sat: unavailable, nsat: unavailable}
+}
+
+// satisfyThresh returns the best witnesses satisfying and dissatisfying a
+// thresh expression with the passed threshold and satisfactions of its
+// sub-expressions.
+func satisfyThresh(k int, subs []satisfaction) satisfaction {
+	// sats[j] is the best witness satisfying exactly j of the last i
+	// sub-expressions, which is built up one sub-expression at a time.
+	sats := []witness{emptyWitness}
+	for i := len(subs) - 1; i >= 0; i-- {
+		sub := subs[i]
+		next := make([]witness, 0, len(sats)+1)
+		next = append(next, sats[0].then(sub.nsat))
+		for j := 1; j < len(sats); j++ {
+			next = append(next, choose(sats[j].then(sub.nsat),
+				sats[j-1].then(sub.sat)))
+		}
+		next = append(next, sats[len(sats)-1].then(sub.sat))
+		sats = next
+	}
+
+	// Satisfying any number of sub-expressions other than the threshold
+	// dissatisfies the expression, but only satisfying none of them is
+	// canonical.
+	nsat := unavailable
+	for j := range sats {
+		if j == k {
+			continue
+		}
+		if j != 0 {
+			sats[j] = sats[j].withMalleable()
+		}
+		nsat = choose(nsat, sats[j])
+	}
+	return satisfaction{sat: sats[k], nsat: nsat}
+}
+
+// satisfyMulti returns the best witnesses satisfying and dissatisfying a multi
+// expression.
+func (m *Miniscript) satisfyMulti(s Satisfier) satisfaction {
+	// sats[j] is the best witness with j signatures by the first i keys,
+	// which starts with the extra item CHECKMULTISIG consumes.
+	sats := []witness{zeroWitness}
+	for _, key := range m.keys {
+		sig := newWitness(s.Signature(key)).withSig()
+		next := make([]witness, 0, len(sats)+1)
+		next = append(next, sats[0])
+		for j := 1; j < len(sats); j++ {
+			next = append(next, choose(sats[j], sats[j-1].then(sig)))
+		}
+		next = append(next, sats[len(sats)-1].then(sig))
+		sats = next
+	}
+
+	nsat := zeroWitness
+	for i := uint32(0); i < m.k; i++ {
+		nsat = nsat.then(zeroWitness)
+	}
+	return satisfaction{sat: sats[m.k], nsat: nsat}
+}
+
+// Satisfy returns the witness spending the P2WSH output of the miniscript with
+// what the passed satisfier provides, which consists of the satisfaction of the
+// miniscript followed by its script.  An error is returned when the miniscript
+// can't be satisfied, or only with satisfactions which don't require a
+// signature or third parties could modify.
+func (m *Miniscript) Satisfy(s Satisfier) (wire.TxWitness, error) {
+	sat := m.satisfy(s).sat
+	switch {
+	case !sat.available:
+		return nil, errors.New("the miniscript can't be satisfied with " +
+			"the available signatures, preimages and timelocks")
+	case !sat.hasSig || sat.malleable:
+		return nil, errors.New("the miniscript can only be satisfied " +
+			"malleably with the available signatures, preimages " +
+			"and timelocks")
+	}
+
+	witness := make(wire.TxWitness, 0, len(sat.stack)+1)
+	witness = append(witness, sat.stack...)
+	return append(witness, m.Script()), nil
+}
+
+// witnessSize is the maximum size of the witnesses satisfying or
+// dissatisfying an expression.
+type witnessSize struct {
+	// valid is whether such witnesses exist.  items is the maximum number
+	// of stack items and size the maximum serialized size of the items.
+	valid bool
+	items int
+	size  int
+}
+
+var (
+	// noWitness is the size of an expression without witnesses.
+	noWitness = witnessSize{}
+
+	// emptyWitnessSize is the size of a witness without items.
+	emptyWitnessSize = witnessSize{valid: true}
+)
+
+// itemSize returns the size of a witness with a single item of the passed
+// size.
+func itemSize(size int) witnessSize {
+	return witnessSize{
+		valid: true,
+		items: 1,
+		size:  wire.VarIntSerializeSize(uint64(size)) + size,
+	}
+}
+
+// add returns the size of the witness concatenated with the passed one.
+func (w witnessSize) add(o witnessSize) witnessSize {
+	if !w.valid || !o.valid {
+		return noWitness
+	}
+	return witnessSize{
+		valid: true,
+		items: w.items + o.items,
+		size:  w.size + o.size,
+	}
+}
+
+// max returns the maximum of the witness sizes.
+func (w witnessSize) max(o witnessSize) witnessSize {
+	switch {
+	case !w.valid:
+		return o
+	case !o.valid:
+		return w
+	}
+	if o.items > w.items {
+		w.items = o.items
+	}
+	if o.size > w.size {
+		w.size = o.size
+	}
+	return w
+}
+
+// maxWitness returns the maximum size of the canonical witnesses satisfying
+// and dissatisfying the expression respectively.  Non-canonical witnesses,
+// which are never used by non-malleable satisfactions, are not considered.
+func (m *Miniscript) maxWitness() (witnessSize, witnessSize) {
+	var sats, nsats []witnessSize
+	for _, sub := range m.subs {
+		sat, nsat := sub.maxWitness()
+		sats = append(sats, sat)
+		nsats = append(nsats, nsat)
+	}
+
+	zero, one := itemSize(0), itemSize(1)
+	switch m.fragment {
+	case fragJust0:
+		return noWitness, emptyWitnessSize
+
+	case fragJust1:
+		return emptyWitnessSize, noWitness
+
+	case fragPkK:
+		return itemSize(maxSigSize), zero
+
+	case fragPkH:
+		key := itemSize(len(m.keys[0]))
+		return itemSize(maxSigSize).add(key), zero.add(key)
+
+	case fragOlder, fragAfter:
+		return emptyWitnessSize, noWitness
+
+	case fragSha256, fragHash256, fragRipemd160, fragHash160:
+		return itemSize(preimageSize), itemSize(preimageSize)
+
+	case fragAndOr:
+		sat := sats[0].add(sats[1]).max(nsats[0].add(sats[2]))
+		return sat, nsats[0].add(nsats[2])
+
+	case fragAndV:
+		return sats[0].add(sats[1]), noWitness
+
+	case fragAndB:
+		return sats[0].add(sats[1]), nsats[0].add(nsats[1])
+
+	case fragOrB:
+		sat := sats[0].add(nsats[1]).max(nsats[0].add(sats[1]))
+		return sat, nsats[0].add(nsats[1])
+
+	case fragOrC:
+		return sats[0].max(nsats[0].add(sats[1])), noWitness
+
+	case fragOrD:
+		sat := sats[0].max(nsats[0].add(sats[1]))
+		return sat, nsats[0].add(nsats[1])
+
+	case fragOrI:
+		sat := sats[0].add(one).max(sats[1].add(zero))
+		nsat := nsats[0].add(one).max(nsats[1].add(zero))
+		return sat, nsat
+
+	case fragThresh:
+		return maxThreshWitness(int(m.k), sats, nsats)
+
+	case fragMulti:
+		sat := zero
+		nsat := zero
+		for i := uint32(0); i < m.k; i++ {
+			sat = sat.add(itemSize(maxSigSize))
+			nsat = nsat.add(zero)
+		}
+		return sat, nsat
+
+	case fragWrapA, fragWrapS, fragWrapC, fragWrapN:
+		return sats[0], nsats[0]
+
+	case fragWrapD:
+		return sats[0].add(one), zero
+
+	case fragWrapV:
+		return sats[0], noWitness
+
+	case fragWrapJ:
+		return sats[0], zero
+	}
+
+	return noWitness, noWitness
+}
+
+// maxThreshWitness returns the maximum size of the canonical witnesses
+// satisfying and dissatisfying a thresh expression with the passed threshold
+// and witness sizes of its sub-expressions.
+func maxThreshWitness(k int, sats, nsats []witnessSize) (witnessSize,
+	witnessSize) {
+
+	nsat := emptyWitnessSize
+	for _, subNsat := range nsats {
+		nsat = nsat.add(subNsat)
+	}
+
+	// The satisfaction with the maximum size satisfies the k
+	// sub-expressions whose satisfactions are the largest compared to
+	// their dissatisfactions.  The number of items is maximized the same
+	// way.
+	var sizeDiffs, itemDiffs []int
+	for i := range sats {
+		if !sats[i].valid {
+			continue
+		}
+		sizeDiffs = append(sizeDiffs, sats[i].size-nsats[i].size)
+		itemDiffs = append(itemDiffs, sats[i].items-nsats[i].items)
+	}
+	if len(sizeDiffs) < k || !nsat.valid {
+		return noWitness, nsat
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizeDiffs)))
+	sort.Sort(sort.Reverse(sort.IntSlice(itemDiffs)))
+	sat := nsat
+	for i := 0; i < k; i++ {
+		sat.size += sizeDiffs[i]
+		sat.items += itemDiffs[i]
+	}
+	return sat, nsat
+}
+
+// MaxWitnessSize returns the maximum serialized size of the witness spending
+// the P2WSH output of the miniscript with a canonical satisfaction, including
+// the witness script.  An error is returned when the miniscript can't be
+// satisfied at all.
+func (m *Miniscript) MaxWitnessSize() (int, error) {
+	sat, _ := m.maxWitness()
+	if !sat.valid {
+		return 0, errors.New("the miniscript can't be satisfied")
+	}
+
+	scriptLen := len(m.Script())
+	return wire.VarIntSerializeSize(uint64(sat.items+1)) + sat.size +
+		wire.VarIntSerializeSize(uint64(scriptLen)) + scriptLen, nil
+}
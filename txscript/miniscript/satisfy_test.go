@@ -0,0 +1,310 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package miniscript
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// testSpendAmount is the amount of the P2WSH outputs spent by the tests.
+const testSpendAmount = 100000
+
+// testPrivKeys returns the passed number of deterministic private keys along
+// with their hex-encoded compressed public keys.
+func testPrivKeys(num int) ([]*btcec.PrivateKey, []interface{}) {
+	privKeys := make([]*btcec.PrivateKey, 0, num)
+	pubKeys := make([]interface{}, 0, num)
+	for i := 0; i < num; i++ {
+		var scalar [32]byte
+		scalar[31] = byte(i + 1)
+		privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), scalar[:])
+		privKeys = append(privKeys, privKey)
+		pubKeys = append(pubKeys,
+			hex.EncodeToString(pubKey.SerializeCompressed()))
+	}
+	return privKeys, pubKeys
+}
+
+// testSpendTx returns a transaction spending the P2WSH output of the passed
+// miniscript along with the signatures of the passed keys and a satisfier
+// providing them.
+func testSpendTx(m *Miniscript, privKeys []*btcec.PrivateKey, version int32,
+	sequence, lockTime uint32) (*wire.MsgTx, *BasicSatisfier, error) {
+
+	tx := wire.NewMsgTx(version)
+	tx.LockTime = lockTime
+	prevOut := wire.NewOutPoint(&chainhash.Hash{0x01}, 0)
+	txIn := wire.NewTxIn(prevOut, nil, nil)
+	txIn.Sequence = sequence
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(testSpendAmount-1000, []byte{txscript.OP_TRUE}))
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(m.PkScript(),
+		testSpendAmount)
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+	satisfier := &BasicSatisfier{
+		Signatures: make(map[string][]byte),
+		TxVersion:  version,
+		LockTime:   lockTime,
+		Sequence:   sequence,
+	}
+	for _, privKey := range privKeys {
+		sig, err := txscript.RawTxInWitnessSignature(tx, sigHashes, 0,
+			testSpendAmount, m.Script(), txscript.SigHashAll, privKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		pubKey := privKey.PubKey().SerializeCompressed()
+		satisfier.Signatures[hex.EncodeToString(pubKey)] = sig
+	}
+	return tx, satisfier, nil
+}
+
+// executeTestSpend executes the witness of the first input of the passed
+// transaction spending the P2WSH output of the passed miniscript.
+func executeTestSpend(m *Miniscript, tx *wire.MsgTx) error {
+	fetcher := txscript.NewCannedPrevOutputFetcher(m.PkScript(),
+		testSpendAmount)
+	vm, err := txscript.NewEngine(m.PkScript(), tx, 0,
+		txscript.StandardVerifyFlags, nil,
+		txscript.NewTxSigHashes(tx, fetcher), testSpendAmount, fetcher)
+	if err != nil {
+		return err
+	}
+	return vm.Execute()
+}
+
+// TestSatisfy ensures miniscripts are satisfied with the available signatures,
+// preimages and timelocks, and that the resulting witnesses are valid and
+// within the maximum witness size.
+func TestSatisfy(t *testing.T) {
+	t.Parallel()
+
+	privKeys, pubKeys := testPrivKeys(3)
+	preimage := make([]byte, 32)
+	preimage[0] = 0x42
+	hash := sha256.Sum256(preimage)
+
+	tests := []struct {
+		name      string
+		expr      string
+		signers   []int
+		preimages [][]byte
+		version   int32
+		sequence  uint32
+		lockTime  uint32
+		items     int
+		valid     bool
+	}{{
+		name:     "pk",
+		expr:     "pk(%[1]s)",
+		signers:  []int{0},
+		sequence: wire.MaxTxInSequenceNum,
+		items:    1,
+		valid:    true,
+	}, {
+		name:     "pk without signature",
+		expr:     "pk(%[1]s)",
+		sequence: wire.MaxTxInSequenceNum,
+	}, {
+		name:     "pkh",
+		expr:     "pkh(%[1]s)",
+		signers:  []int{0},
+		sequence: wire.MaxTxInSequenceNum,
+		items:    2,
+		valid:    true,
+	}, {
+		name:     "multi with one missing signature",
+		expr:     "multi(2,%[1]s,%[2]s,%[3]s)",
+		signers:  []int{0, 2},
+		sequence: wire.MaxTxInSequenceNum,
+		items:    3,
+		valid:    true,
+	}, {
+		name:     "or_d with first key",
+		expr:     "or_d(pk(%[1]s),and_v(v:pk(%[2]s),older(144)))",
+		signers:  []int{0, 1},
+		sequence: wire.MaxTxInSequenceNum,
+		items:    1,
+		valid:    true,
+	}, {
+		name:     "or_d with timelocked key",
+		expr:     "or_d(pk(%[1]s),and_v(v:pk(%[2]s),older(144)))",
+		signers:  []int{1},
+		version:  2,
+		sequence: 144,
+		items:    2,
+		valid:    true,
+	}, {
+		name:     "or_d with unexpired timelock",
+		expr:     "or_d(pk(%[1]s),and_v(v:pk(%[2]s),older(144)))",
+		signers:  []int{1},
+		version:  2,
+		sequence: 143,
+	}, {
+		name:     "after",
+		expr:     "and_v(v:pk(%[1]s),after(500))",
+		signers:  []int{0},
+		sequence: 0,
+		lockTime: 500,
+		items:    1,
+		valid:    true,
+	}, {
+		name:     "after with final sequence",
+		expr:     "and_v(v:pk(%[1]s),after(500))",
+		signers:  []int{0},
+		sequence: wire.MaxTxInSequenceNum,
+		lockTime: 500,
+	}, {
+		name: "hash preimage",
+		expr: "and_v(v:pk(%[1]s),sha256(" +
+			hex.EncodeToString(hash[:]) + "))",
+		signers:   []int{0},
+		preimages: [][]byte{preimage},
+		sequence:  wire.MaxTxInSequenceNum,
+		items:     2,
+		valid:     true,
+	}, {
+		name:     "thresh",
+		expr:     "thresh(2,pk(%[1]s),s:pk(%[2]s),s:pk(%[3]s))",
+		signers:  []int{1, 2},
+		sequence: wire.MaxTxInSequenceNum,
+		items:    3,
+		valid:    true,
+	}, {
+		name:     "andor",
+		expr:     "andor(pk(%[1]s),older(144),pk(%[2]s))",
+		signers:  []int{1},
+		sequence: wire.MaxTxInSequenceNum,
+		items:    2,
+		valid:    true,
+	}, {
+		name:     "or_i",
+		expr:     "or_i(pk(%[1]s),pk(%[2]s))",
+		signers:  []int{1},
+		sequence: wire.MaxTxInSequenceNum,
+		items:    2,
+		valid:    true,
+	}, {
+		name:     "timelock only",
+		expr:     "or_i(pk(%[1]s),older(144))",
+		version:  2,
+		sequence: 144,
+	}}
+
+	for _, test := range tests {
+		m, err := Parse(fmt.Sprintf(test.expr, pubKeys...))
+		if err != nil {
+			t.Errorf("%s: unexpected parse error: %v", test.name, err)
+			continue
+		}
+		signers := make([]*btcec.PrivateKey, 0, len(test.signers))
+		for _, idx := range test.signers {
+			signers = append(signers, privKeys[idx])
+		}
+		tx, satisfier, err := testSpendTx(m, signers, test.version,
+			test.sequence, test.lockTime)
+		if err != nil {
+			t.Errorf("%s: unable to sign: %v", test.name, err)
+			continue
+		}
+		satisfier.Preimages = test.preimages
+
+		witness, err := m.Satisfy(satisfier)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: satisfied unsatisfiable miniscript",
+					test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if len(witness) != test.items+1 {
+			t.Errorf("%s: mismatched number of witness items - "+
+				"got %d, want %d", test.name, len(witness)-1,
+				test.items)
+		}
+
+		tx.TxIn[0].Witness = witness
+		if err := executeTestSpend(m, tx); err != nil {
+			t.Errorf("%s: invalid witness: %v", test.name, err)
+			continue
+		}
+
+		maxSize, err := m.MaxWitnessSize()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if size := witness.SerializeSize(); size > maxSize {
+			t.Errorf("%s: witness size %d exceeds the maximum of %d",
+				test.name, size, maxSize)
+		}
+	}
+}
+
+// TestMaxWitnessSize ensures the maximum witness size of miniscripts is
+// calculated correctly.
+func TestMaxWitnessSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		expr string
+		size int
+	}{{
+		// 1 item count + 74 signature + 1 + 35 script.
+		name: "pk",
+		expr: "pk(" + testKey1 + ")",
+		size: 111,
+	}, {
+		// 1 item count + 1 dummy + 2 * 74 signatures + 1 + 71 script.
+		name: "multi",
+		expr: "multi(2," + testKey1 + "," + testKey2 + ")",
+		size: 222,
+	}, {
+		// The largest satisfaction is the dissatisfaction of the first
+		// key followed by the signature of the second one.
+		name: "or_d",
+		expr: "or_d(pk(" + testKey1 + "),pk(" + testKey2 + "))",
+		size: 1 + 74 + 1 + 1 + 73,
+	}}
+
+	for _, test := range tests {
+		m, err := Parse(test.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		size, err := m.MaxWitnessSize()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if size != test.size {
+			t.Errorf("%s: mismatched size - got %d, want %d",
+				test.name, size, test.size)
+		}
+	}
+
+	m, err := Parse("and_v(v:pk(" + testKey1 + "),0)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := m.MaxWitnessSize(); err == nil {
+		t.Fatal("MaxWitnessSize: no error for unsatisfiable miniscript")
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package miniscript
+
+import (
+	"strings"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Type describes the type of a miniscript expression.  It consists of exactly
+// one of the basic types B, V, K and W when the expression is valid, along with
+// the properties the expression has.
+//
+// The basic types are:
+//   - B: consumes its inputs and pushes a nonzero value on success and an exact
+//     zero on failure
+//   - V: consumes its inputs and pushes nothing on success, while it can't
+//     fail without aborting the script
+//   - K: consumes its inputs and pushes a public key for a signature check
+//   - W: like B, but operates on the element below the top of the stack
+//
+// The correctness properties are:
+//   - z: consumes exactly zero stack elements
+//   - o: consumes exactly one stack element
+//   - n: the top element of the input is nonzero for every satisfaction
+//   - d: can be dissatisfied without a signature
+//   - u: pushes exactly one on success instead of any nonzero value
+//
+// The malleability properties are:
+//   - s: every satisfaction requires a signature
+//   - f: can't be dissatisfied without aborting the script
+//   - e: has a unique dissatisfaction which third parties can't modify
+//   - m: has a non-malleable satisfaction for every available combination of
+//     signatures, preimages and timelocks
+//
+// The remaining properties are:
+//   - x: the last opcode is not one of EQUAL, CHECKSIG, CHECKMULTISIG and
+//     NUMEQUAL, so verifying its result requires an additional VERIFY
+//   - g, h, i, j: contains a relative time, relative height, absolute time
+//     or absolute height timelock respectively
+//   - k: no satisfaction requires both a time and a height timelock of the
+//     same kind, which can't be satisfied together
+type Type uint32
+
+const (
+	typeB Type = 1 << iota
+	typeV
+	typeK
+	typeW
+	typeZ
+	typeO
+	typeN
+	typeD
+	typeU
+	typeS
+	typeF
+	typeE
+	typeM
+	typeX
+	typeG
+	typeH
+	typeI
+	typeJ
+	typeK2
+
+	// typeBase is the set of basic types.
+	typeBase = typeB | typeV | typeK | typeW
+
+	// typeTimelocks is the set of timelock properties which are inherited
+	// from all sub-expressions.
+	typeTimelocks = typeG | typeH | typeI | typeJ
+)
+
+// typeLetters are the letters representing the basic types and properties in
+// order of the bits representing them.
+const typeLetters = "BVKWzondusfemxghijk"
+
+// String returns the basic type of the type followed by the letters of its
+// properties, such as "Bdemsu", or an empty string for an invalid type.
+func (t Type) String() string {
+	var str strings.Builder
+	for i := range typeLetters {
+		if t&(1<<uint(i)) != 0 {
+			str.WriteByte(typeLetters[i])
+		}
+	}
+	return str.String()
+}
+
+// IsValid returns whether the type has a basic type, which is the case for
+// valid expressions only.
+func (t Type) IsValid() bool {
+	return t&typeBase != 0
+}
+
+// has returns whether the type has all of the passed types and properties.
+func (t Type) has(props Type) bool {
+	return t&props == props
+}
+
+// typeIf returns the passed type when the condition holds and an empty type
+// otherwise.
+func typeIf(cond bool, t Type) Type {
+	if cond {
+		return t
+	}
+	return 0
+}
+
+// timelocksConflict returns whether the timelocks of the passed types can't be
+// satisfied together since they are a time and a height lock of the same kind.
+func timelocksConflict(x, y Type) bool {
+	return (x.has(typeG) && y.has(typeH)) || (x.has(typeH) && y.has(typeG)) ||
+		(x.has(typeI) && y.has(typeJ)) || (x.has(typeJ) && y.has(typeI))
+}
+
+// conjunctionTimelocks returns the timelock properties of an expression which
+// requires both of the passed sub-expressions to be satisfied.
+func conjunctionTimelocks(x, y Type) Type {
+	return (x|y)&typeTimelocks |
+		typeIf((x&y).has(typeK2) && !timelocksConflict(x, y), typeK2)
+}
+
+// disjunctionTimelocks returns the timelock properties of an expression which
+// requires either of the passed sub-expressions to be satisfied.
+func disjunctionTimelocks(x, y Type) Type {
+	return (x|y)&typeTimelocks | (x & y & typeK2)
+}
+
+// computeType returns the type of the passed node from the types of its
+// sub-expressions, which are already computed.  It returns an invalid type when
+// the sub-expressions don't have the types the fragment requires.
+func computeType(node *Miniscript) Type {
+	var x, y, z Type
+	switch len(node.subs) {
+	case 3:
+		z = node.subs[2].typ
+		fallthrough
+	case 2:
+		y = node.subs[1].typ
+		fallthrough
+	case 1:
+		x = node.subs[0].typ
+	}
+
+	switch node.fragment {
+	case fragJust0:
+		return typeB | typeZ | typeU | typeD | typeE | typeM | typeS |
+			typeX | typeK2
+
+	case fragJust1:
+		return typeB | typeZ | typeU | typeF | typeM | typeX | typeK2
+
+	case fragPkK:
+		return typeK | typeO | typeN | typeU | typeD | typeE | typeM |
+			typeS | typeX | typeK2
+
+	case fragPkH:
+		return typeK | typeN | typeU | typeD | typeE | typeM | typeS |
+			typeX | typeK2
+
+	case fragOlder:
+		isTime := node.k&wire.SequenceLockTimeIsSeconds != 0
+		return typeIf(isTime, typeG) | typeIf(!isTime, typeH) | typeB |
+			typeZ | typeF | typeM | typeX | typeK2
+
+	case fragAfter:
+		isTime := node.k >= txscript.LockTimeThreshold
+		return typeIf(isTime, typeI) | typeIf(!isTime, typeJ) | typeB |
+			typeZ | typeF | typeM | typeX | typeK2
+
+	case fragSha256, fragHash256, fragRipemd160,
+		fragHash160:
+
+		return typeB | typeO | typeN | typeU | typeD | typeM | typeK2
+
+	case fragAndOr:
+		return y&z&(typeB|typeK|typeV) |
+			x&y&z&typeZ |
+			typeIf((x|y&z).has(typeO) && (x|y&z).has(typeZ), (x|y&z)&typeO) |
+			y&z&typeU |
+			typeIf(x.has(typeS) || y.has(typeF), z&typeF|x&z&typeE) |
+			z&typeD |
+			typeIf(x.has(typeE) && (x|y|z).has(typeS), x&y&z&typeM) |
+			z&(x|y)&typeS |
+			typeX |
+			(x|y|z)&typeTimelocks |
+			typeIf((x&y&z).has(typeK2) && !timelocksConflict(x, y), typeK2)
+
+	case fragAndV:
+		return typeIf(x.has(typeV), y&(typeK|typeV|typeB)) |
+			x&typeN | typeIf(x.has(typeZ), y&typeN) |
+			typeIf((x|y).has(typeO) && (x|y).has(typeZ), (x|y)&typeO) |
+			x&y&(typeD|typeM|typeZ) |
+			(x|y)&typeS |
+			typeIf(y.has(typeF) || x.has(typeS), typeF) |
+			y&(typeU|typeX) |
+			conjunctionTimelocks(x, y)
+
+	case fragAndB:
+		return typeIf(y.has(typeW), x&typeB) |
+			typeIf((x|y).has(typeO) && (x|y).has(typeZ), (x|y)&typeO) |
+			x&typeN | typeIf(x.has(typeZ), y&typeN) |
+			typeIf((x&y).has(typeS), x&y&typeE) |
+			x&y&(typeD|typeZ|typeM) |
+			typeIf((x&y).has(typeF) || x.has(typeS|typeF) ||
+				y.has(typeS|typeF), typeF) |
+			(x|y)&typeS |
+			typeU | typeX |
+			conjunctionTimelocks(x, y)
+
+	case fragOrB:
+		return typeIf(x.has(typeB|typeD) && y.has(typeW|typeD), typeB) |
+			typeIf((x|y).has(typeO) && (x|y).has(typeZ), (x|y)&typeO) |
+			typeIf((x|y).has(typeS) && (x&y).has(typeE), x&y&typeM) |
+			x&y&(typeZ|typeS|typeE) |
+			typeD | typeU | typeX |
+			disjunctionTimelocks(x, y)
+
+	case fragOrD:
+		return typeIf(x.has(typeB|typeD|typeU), y&typeB) |
+			typeIf(y.has(typeZ), x&typeO) |
+			typeIf(x.has(typeE) && (x|y).has(typeS), x&y&typeM) |
+			x&y&(typeZ|typeS) |
+			y&(typeU|typeF|typeD|typeE) |
+			typeX |
+			disjunctionTimelocks(x, y)
+
+	case fragOrC:
+		return typeIf(x.has(typeB|typeD|typeU), y&typeV) |
+			typeIf(y.has(typeZ), x&typeO) |
+			typeIf(x.has(typeE) && (x|y).has(typeS), x&y&typeM) |
+			x&y&(typeZ|typeS) |
+			typeF | typeX |
+			disjunctionTimelocks(x, y)
+
+	case fragOrI:
+		return x&y&(typeV|typeB|typeK|typeU|typeF|typeS) |
+			typeIf((x&y).has(typeZ), typeO) |
+			typeIf((x|y).has(typeF), (x|y)&typeE) |
+			typeIf((x|y).has(typeS), x&y&typeM) |
+			(x|y)&typeD |
+			typeX |
+			disjunctionTimelocks(x, y)
+
+	case fragThresh:
+		return threshType(node)
+
+	case fragMulti:
+		return typeB | typeN | typeU | typeD | typeE | typeM | typeS | typeK2
+
+	case fragWrapA:
+		return typeIf(x.has(typeB), typeW) |
+			x&(typeTimelocks|typeK2) |
+			x&(typeU|typeD|typeF|typeE|typeM|typeS) |
+			typeX
+
+	case fragWrapS:
+		return typeIf(x.has(typeB|typeO), typeW) |
+			x&(typeTimelocks|typeK2) |
+			x&(typeU|typeD|typeF|typeE|typeM|typeS|typeX)
+
+	case fragWrapC:
+		return typeIf(x.has(typeK), typeB) |
+			x&(typeTimelocks|typeK2) |
+			x&(typeO|typeN|typeD|typeF|typeE|typeM) |
+			typeU | typeS
+
+	case fragWrapD:
+		// Note the result is not u since the argument of the IF is only
+		// required to be exactly one by policy.
+		return typeIf(x.has(typeV|typeZ), typeB) |
+			typeIf(x.has(typeZ), typeO) |
+			typeIf(x.has(typeF), typeE) |
+			x&(typeTimelocks|typeK2) |
+			x&(typeM|typeS) |
+			typeN | typeD | typeX
+
+	case fragWrapV:
+		return typeIf(x.has(typeB), typeV) |
+			x&(typeTimelocks|typeK2) |
+			x&(typeZ|typeO|typeN|typeM|typeS) |
+			typeF | typeX
+
+	case fragWrapJ:
+		return typeIf(x.has(typeB|typeN), typeB) |
+			typeIf(x.has(typeF), typeE) |
+			x&(typeTimelocks|typeK2) |
+			x&(typeO|typeU|typeM|typeS) |
+			typeN | typeD | typeX
+
+	case fragWrapN:
+		return x&(typeTimelocks|typeK2) |
+			x&(typeB|typeZ|typeO|typeN|typeD|typeF|typeE|typeM|typeS) |
+			typeU | typeX
+	}
+
+	return 0
+}
+
+// threshType returns the type of the passed thresh node from the types of its
+// sub-expressions.
+func threshType(node *Miniscript) Type {
+	allE, allM := true, true
+	var args, numS int
+	timelocks := typeK2
+	for i, sub := range node.subs {
+		t := sub.typ
+
+		// The first sub-expression must be Bdu and the remaining ones
+		// Wdu.
+		required := typeW | typeD | typeU
+		if i == 0 {
+			required = typeB | typeD | typeU
+		}
+		if !t.has(required) {
+			return 0
+		}
+
+		allE = allE && t.has(typeE)
+		allM = allM && t.has(typeM)
+		if t.has(typeS) {
+			numS++
+		}
+		switch {
+		case t.has(typeZ):
+		case t.has(typeO):
+			args++
+		default:
+			args += 2
+		}
+
+		// The timelocks conflict when more than one sub-expression
+		// needs to be satisfied and two of them have timelocks which
+		// can't be satisfied together.
+		timelocks = (timelocks|t)&typeTimelocks |
+			typeIf((timelocks&t).has(typeK2) && (node.k <= 1 ||
+				!timelocksConflict(timelocks, t)), typeK2)
+	}
+
+	n := len(node.subs)
+	k := int(node.k)
+	return typeB | typeD | typeU |
+		typeIf(args == 0, typeZ) |
+		typeIf(args == 1, typeO) |
+		typeIf(allE && numS == n, typeE) |
+		typeIf(allE && allM && numS >= n-k, typeM) |
+		typeIf(numS >= n-k+1, typeS) |
+		timelocks
+}
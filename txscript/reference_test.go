@@ -6,11 +6,13 @@ package txscript
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
@@ -192,6 +194,14 @@ func parseScriptFlags(flagStr string) (ScriptFlags, error) {
 			flags |= ScriptVerifyMinimalIf
 		case "WITNESS_PUBKEYTYPE":
 			flags |= ScriptVerifyWitnessPubKeyType
+		case "TAPROOT":
+			flags |= ScriptVerifyTaproot
+		case "DISCOURAGE_UPGRADABLE_TAPROOT_VERSION":
+			flags |= ScriptVerifyDiscourageUpgradeableTaprootVersion
+		case "DISCOURAGE_OP_SUCCESS":
+			flags |= ScriptVerifyDiscourageOpSuccess
+		case "DISCOURAGE_UPGRADABLE_PUBKEYTYPE":
+			flags |= ScriptVerifyDiscourageUpgradeablePubkeyType
 		default:
 			return flags, fmt.Errorf("invalid flag: %s", flag)
 		}
@@ -443,8 +453,10 @@ func testScripts(t *testing.T, tests [][]interface{}, useSigCache bool) {
 		// used, then create a new engine to execute the scripts.
 		tx := createSpendingTx(witness, scriptSig, scriptPubKey,
 			int64(inputAmt))
-		vm, err := NewEngine(scriptPubKey, tx, 0, flags, sigCache, nil,
+		prevOutFetcher := NewCannedPrevOutputFetcher(scriptPubKey,
 			int64(inputAmt))
+		vm, err := NewEngine(scriptPubKey, tx, 0, flags, sigCache, nil,
+			int64(inputAmt), prevOutFetcher)
 		if err == nil {
 			err = vm.Execute()
 		}
@@ -640,6 +652,14 @@ testloop:
 			prevOuts[*wire.NewOutPoint(prevhash, idx)] = v
 		}
 
+		prevOutFetcher := NewMultiPrevOutFetcher(nil)
+		for op, prevOut := range prevOuts {
+			prevOutFetcher.AddPrevOut(op, &wire.TxOut{
+				Value:    prevOut.inputVal,
+				PkScript: prevOut.pkScript,
+			})
+		}
+
 		for k, txin := range tx.MsgTx().TxIn {
 			prevOut, ok := prevOuts[txin.PreviousOutPoint]
 			if !ok {
@@ -651,7 +671,7 @@ testloop:
 			// input fails the transaction has failed. (some of the
 			// test txns have good inputs, too..
 			vm, err := NewEngine(prevOut.pkScript, tx.MsgTx(), k,
-				flags, nil, nil, prevOut.inputVal)
+				flags, nil, nil, prevOut.inputVal, prevOutFetcher)
 			if err != nil {
 				continue testloop
 			}
@@ -795,6 +815,14 @@ testloop:
 			prevOuts[*wire.NewOutPoint(prevhash, idx)] = v
 		}
 
+		prevOutFetcher := NewMultiPrevOutFetcher(nil)
+		for op, prevOut := range prevOuts {
+			prevOutFetcher.AddPrevOut(op, &wire.TxOut{
+				Value:    prevOut.inputVal,
+				PkScript: prevOut.pkScript,
+			})
+		}
+
 		for k, txin := range tx.MsgTx().TxIn {
 			prevOut, ok := prevOuts[txin.PreviousOutPoint]
 			if !ok {
@@ -803,7 +831,7 @@ testloop:
 				continue testloop
 			}
 			vm, err := NewEngine(prevOut.pkScript, tx.MsgTx(), k,
-				flags, nil, nil, prevOut.inputVal)
+				flags, nil, nil, prevOut.inputVal, prevOutFetcher)
 			if err != nil {
 				t.Errorf("test (%d:%v:%d) failed to create "+
 					"script: %v", i, test, k, err)
@@ -878,3 +906,180 @@ func TestCalcSignatureHash(t *testing.T) {
 		}
 	}
 }
+
+// scriptAssetsEnv is the environment variable which points to the BIP0341 and
+// BIP0342 script assets test vectors.  They are generated by the taproot
+// functional test of Bitcoin Core and are too large to be kept in the
+// repository, so TestScriptAssets is skipped when the variable isn't set, just
+// like the script_assets_test of Bitcoin Core is without its data directory.
+const scriptAssetsEnv = "BTCD_SCRIPT_ASSETS"
+
+// scriptAssetsSpend is the signature script and witness of a spend of a script
+// assets test.
+type scriptAssetsSpend struct {
+	ScriptSig string   `json:"scriptSig"`
+	Witness   []string `json:"witness"`
+}
+
+// scriptAssetsTest is a single script assets test.  Every test spends one of
+// the inputs of a transaction with a spend which must succeed, one which must
+// fail, or both.
+type scriptAssetsTest struct {
+	Tx       string             `json:"tx"`
+	Prevouts []string           `json:"prevouts"`
+	Index    int                `json:"index"`
+	Flags    string             `json:"flags"`
+	Final    bool               `json:"final"`
+	Comment  string             `json:"comment"`
+	Success  *scriptAssetsSpend `json:"success"`
+	Failure  *scriptAssetsSpend `json:"failure"`
+}
+
+// scriptAssetsConsensusFlags are the consensus flags of the script assets
+// tests.  Every test is executed with all valid combinations of them.
+var scriptAssetsConsensusFlags = []ScriptFlags{
+	ScriptBip16, ScriptVerifyDERSignatures, ScriptStrictMultiSig,
+	ScriptVerifyCheckLockTimeVerify, ScriptVerifyCheckSequenceVerify,
+	ScriptVerifyWitness, ScriptVerifyTaproot,
+}
+
+// parseSerializedTxOut parses a transaction output serialized as its value
+// followed by its variable length public key script.
+func parseSerializedTxOut(serialized []byte) (*wire.TxOut, error) {
+	if len(serialized) < 8 {
+		return nil, errors.New("truncated output value")
+	}
+	value := int64(binary.LittleEndian.Uint64(serialized))
+	pkScript, err := wire.ReadVarBytes(bytes.NewReader(serialized[8:]), 0,
+		wire.MaxMessagePayload, "pkScript")
+	if err != nil {
+		return nil, err
+	}
+	return wire.NewTxOut(value, pkScript), nil
+}
+
+// allScriptAssetsFlags returns all combinations of the script assets consensus
+// flags, except for those which enable witness validation without P2SH or
+// taproot validation without witness validation.
+func allScriptAssetsFlags() []ScriptFlags {
+	var combinations []ScriptFlags
+	for i := 0; i < 1<<len(scriptAssetsConsensusFlags); i++ {
+		var flags ScriptFlags
+		for j, flag := range scriptAssetsConsensusFlags {
+			if i&(1<<j) != 0 {
+				flags |= flag
+			}
+		}
+		if flags&ScriptVerifyWitness != 0 && flags&ScriptBip16 == 0 {
+			continue
+		}
+		if flags&ScriptVerifyTaproot != 0 &&
+			flags&ScriptVerifyWitness == 0 {
+
+			continue
+		}
+		combinations = append(combinations, flags)
+	}
+	return combinations
+}
+
+// TestScriptAssets ensures the engine agrees with the BIP0341 and BIP0342
+// script assets test vectors.  A successful spend must be valid with any subset
+// of the flags of its test, or with all flags when the test is final, while a
+// failing spend must be invalid with any superset of them.
+func TestScriptAssets(t *testing.T) {
+	path := os.Getenv(scriptAssetsEnv)
+	if path == "" {
+		t.Skipf("%s is not set", scriptAssetsEnv)
+	}
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read script assets: %v", err)
+	}
+	var tests []scriptAssetsTest
+	if err := json.Unmarshal(file, &tests); err != nil {
+		t.Fatalf("unable to parse script assets: %v", err)
+	}
+
+	allFlags := allScriptAssetsFlags()
+	for i, test := range tests {
+		serializedTx, err := hex.DecodeString(test.Tx)
+		if err != nil {
+			t.Fatalf("test #%d: invalid tx: %v", i, err)
+		}
+		var tx wire.MsgTx
+		if err := tx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+			t.Fatalf("test #%d: invalid tx: %v", i, err)
+		}
+		if len(test.Prevouts) != len(tx.TxIn) || test.Index >= len(tx.TxIn) {
+			t.Fatalf("test #%d: mismatched prevouts", i)
+		}
+		prevOutFetcher := NewMultiPrevOutFetcher(nil)
+		for j, prevOutHex := range test.Prevouts {
+			serialized, err := hex.DecodeString(prevOutHex)
+			if err != nil {
+				t.Fatalf("test #%d: invalid prevout: %v", i, err)
+			}
+			prevOut, err := parseSerializedTxOut(serialized)
+			if err != nil {
+				t.Fatalf("test #%d: invalid prevout: %v", i, err)
+			}
+			prevOutFetcher.AddPrevOut(tx.TxIn[j].PreviousOutPoint,
+				prevOut)
+		}
+		testFlags, err := parseScriptFlags(test.Flags)
+		if err != nil {
+			t.Fatalf("test #%d: %v", i, err)
+		}
+		prevOut := prevOutFetcher.FetchPrevOutput(
+			tx.TxIn[test.Index].PreviousOutPoint)
+
+		// verify executes the passed spend of the input with the passed
+		// flags.
+		verify := func(spend *scriptAssetsSpend, flags ScriptFlags) error {
+			sigScript, err := hex.DecodeString(spend.ScriptSig)
+			if err != nil {
+				t.Fatalf("test #%d: invalid scriptSig: %v", i, err)
+			}
+			witness := make(wire.TxWitness, 0, len(spend.Witness))
+			for _, elem := range spend.Witness {
+				b, err := hex.DecodeString(elem)
+				if err != nil {
+					t.Fatalf("test #%d: invalid witness: %v",
+						i, err)
+				}
+				witness = append(witness, b)
+			}
+			tx.TxIn[test.Index].SignatureScript = sigScript
+			tx.TxIn[test.Index].Witness = witness
+
+			sigHashes := NewTxSigHashes(&tx, prevOutFetcher)
+			vm, err := NewEngine(prevOut.PkScript, &tx, test.Index,
+				flags, nil, sigHashes, prevOut.Value,
+				prevOutFetcher)
+			if err != nil {
+				return err
+			}
+			return vm.Execute()
+		}
+
+		for _, flags := range allFlags {
+			if test.Success != nil && (test.Final ||
+				flags&testFlags == flags) {
+
+				if err := verify(test.Success, flags); err != nil {
+					t.Errorf("test #%d (%s): success spend "+
+						"failed with flags %v: %v", i,
+						test.Comment, flags, err)
+				}
+			}
+			if test.Failure != nil && flags&testFlags == testFlags {
+				if err := verify(test.Failure, flags); err == nil {
+					t.Errorf("test #%d (%s): failure spend "+
+						"succeeded with flags %v", i,
+						test.Comment, flags)
+				}
+			}
+		}
+	}
+}
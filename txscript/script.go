@@ -27,6 +27,7 @@ type SigHashType uint32
 // Hash type bits from the end of a signature.
 const (
 	SigHashOld          SigHashType = 0x0
+	SigHashDefault      SigHashType = 0x0
 	SigHashAll          SigHashType = 0x1
 	SigHashNone         SigHashType = 0x2
 	SigHashSingle       SigHashType = 0x3
@@ -90,6 +91,12 @@ func IsPayToWitnessPubKeyHash(script []byte) bool {
 	return isWitnessPubKeyHashScript(script)
 }
 
+// IsPayToTaproot returns true if the script is in the standard pay-to-taproot
+// (P2TR) format, false otherwise.
+func IsPayToTaproot(script []byte) bool {
+	return isWitnessTaprootScript(script)
+}
+
 // IsWitnessProgram returns true if the passed script is a valid witness
 // program which is encoded according to the passed witness program version. A
 // witness program must be a small integer (from 0-16), followed by 2-40 bytes
@@ -297,7 +304,8 @@ func removeOpcodeByData(script []byte, dataToRemove []byte) []byte {
 // can be re-used when validating all inputs spending segwit outputs, with a
 // signature hash type of SigHashAll. This allows validation to re-use previous
 // hashing computation, reducing the complexity of validating SigHashAll inputs
-// from  O(N^2) to O(N).
+// from  O(N^2) to O(N).  The hash is the single sha256 used by BIP0341, the
+// BIP0143 one is its sha256.
 func calcHashPrevOuts(tx *wire.MsgTx) chainhash.Hash {
 	var b bytes.Buffer
 	for _, in := range tx.TxIn {
@@ -312,7 +320,7 @@ func calcHashPrevOuts(tx *wire.MsgTx) chainhash.Hash {
 		b.Write(buf[:])
 	}
 
-	return chainhash.DoubleHashH(b.Bytes())
+	return chainhash.HashH(b.Bytes())
 }
 
 // calcHashSequence computes an aggregated hash of each of the sequence numbers
@@ -320,7 +328,7 @@ func calcHashPrevOuts(tx *wire.MsgTx) chainhash.Hash {
 // when validating all inputs spending segwit outputs, which include signatures
 // using the SigHashAll sighash type. This allows validation to re-use previous
 // hashing computation, reducing the complexity of validating SigHashAll inputs
-// from O(N^2) to O(N).
+// from O(N^2) to O(N).  Like calcHashPrevOuts, it returns a single sha256.
 func calcHashSequence(tx *wire.MsgTx) chainhash.Hash {
 	var b bytes.Buffer
 	for _, in := range tx.TxIn {
@@ -329,21 +337,57 @@ func calcHashSequence(tx *wire.MsgTx) chainhash.Hash {
 		b.Write(buf[:])
 	}
 
-	return chainhash.DoubleHashH(b.Bytes())
+	return chainhash.HashH(b.Bytes())
 }
 
 // calcHashOutputs computes a hash digest of all outputs created by the
 // transaction encoded using the wire format. This single hash can be re-used
 // when validating all inputs spending witness programs, which include
 // signatures using the SigHashAll sighash type. This allows computation to be
-// cached, reducing the total hashing complexity from O(N^2) to O(N).
+// cached, reducing the total hashing complexity from O(N^2) to O(N).  Like
+// calcHashPrevOuts, it returns a single sha256.
 func calcHashOutputs(tx *wire.MsgTx) chainhash.Hash {
 	var b bytes.Buffer
 	for _, out := range tx.TxOut {
 		wire.WriteTxOut(&b, 0, 0, out)
 	}
 
-	return chainhash.DoubleHashH(b.Bytes())
+	return chainhash.HashH(b.Bytes())
+}
+
+// calcHashInputAmounts computes the single sha256 of the amounts of all the
+// outputs spent by the passed transaction as defined in BIP0341.
+func calcHashInputAmounts(tx *wire.MsgTx, prevOutFetcher PrevOutputFetcher) chainhash.Hash {
+	var b bytes.Buffer
+	for _, in := range tx.TxIn {
+		var amount int64
+		if prevOut := prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint); prevOut != nil {
+			amount = prevOut.Value
+		}
+
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], uint64(amount))
+		b.Write(buf[:])
+	}
+
+	return chainhash.HashH(b.Bytes())
+}
+
+// calcHashInputScripts computes the single sha256 of the length prefixed
+// public key scripts of all the outputs spent by the passed transaction as
+// defined in BIP0341.
+func calcHashInputScripts(tx *wire.MsgTx, prevOutFetcher PrevOutputFetcher) chainhash.Hash {
+	var b bytes.Buffer
+	for _, in := range tx.TxIn {
+		var pkScript []byte
+		if prevOut := prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint); prevOut != nil {
+			pkScript = prevOut.PkScript
+		}
+
+		wire.WriteVarBytes(&b, 0, pkScript)
+	}
+
+	return chainhash.HashH(b.Bytes())
 }
 
 // calcWitnessSignatureHashRaw computes the sighash digest of a transaction's
@@ -474,6 +518,180 @@ func CalcWitnessSigHash(script []byte, sigHashes *TxSigHashes, hType SigHashType
 	return calcWitnessSignatureHashRaw(script, sigHashes, hType, tx, idx, amt)
 }
 
+// taprootSigHashOptions houses the parts of the BIP0341 signature message which
+// depend on how the taproot output is spent.
+type taprootSigHashOptions struct {
+	// annex is the annex of the witness of the input, if any.
+	annex []byte
+
+	// tapLeafHash is the hash of the leaf of the executed tapscript.  It is
+	// nil for key path spends.
+	tapLeafHash []byte
+
+	// codeSepPos is the opcode position of the last executed
+	// OP_CODESEPARATOR of the executed tapscript.
+	codeSepPos uint32
+}
+
+// blankCodeSepValue is the code separator position committed to by tapscript
+// signatures when no OP_CODESEPARATOR has been executed.
+const blankCodeSepValue = 0xffffffff
+
+// isValidTaprootSigHash returns whether the passed hash type is valid for a
+// taproot signature.
+func isValidTaprootSigHash(hashType SigHashType) bool {
+	switch hashType {
+	case SigHashDefault, SigHashAll, SigHashNone, SigHashSingle,
+		SigHashAll | SigHashAnyOneCanPay,
+		SigHashNone | SigHashAnyOneCanPay,
+		SigHashSingle | SigHashAnyOneCanPay:
+
+		return true
+	}
+	return false
+}
+
+// calcTaprootSignatureHashRaw computes the signature hash of a taproot input
+// as defined in BIP0341, extended for tapscript signatures as defined in
+// BIP0342 when the passed options contain a leaf hash.  Like its BIP0143
+// counterpart, it makes use of the pre-calculated sighash fragments of the
+// passed TxSigHashes, which must include the BIP0341 ones.
+func calcTaprootSignatureHashRaw(sigHashes *TxSigHashes, hashType SigHashType,
+	tx *wire.MsgTx, idx int, prevOutFetcher PrevOutputFetcher,
+	opts *taprootSigHashOptions) ([]byte, error) {
+
+	if !isValidTaprootSigHash(hashType) {
+		return nil, fmt.Errorf("invalid taproot sighash type 0x%x",
+			hashType)
+	}
+	if idx > len(tx.TxIn)-1 {
+		return nil, fmt.Errorf("idx %d but %d txins", idx, len(tx.TxIn))
+	}
+	outputType := hashType & 0x03
+	if outputType == SigHashSingle && idx >= len(tx.TxOut) {
+		return nil, fmt.Errorf("idx %d but %d txouts for "+
+			"SIGHASH_SINGLE", idx, len(tx.TxOut))
+	}
+	anyoneCanPay := hashType&SigHashAnyOneCanPay == SigHashAnyOneCanPay
+
+	// The signature message starts with the epoch, the hash type and the
+	// transaction version and lock time.
+	var sigMsg bytes.Buffer
+	sigMsg.WriteByte(0x00)
+	sigMsg.WriteByte(byte(hashType))
+	var bUint32 [4]byte
+	binary.LittleEndian.PutUint32(bUint32[:], uint32(tx.Version))
+	sigMsg.Write(bUint32[:])
+	binary.LittleEndian.PutUint32(bUint32[:], tx.LockTime)
+	sigMsg.Write(bUint32[:])
+
+	// Unless only the input being signed is committed to, commit to the
+	// outpoints, amounts, public key scripts and sequence numbers of all
+	// inputs.
+	if !anyoneCanPay {
+		sigMsg.Write(sigHashes.HashPrevOutsV1[:])
+		sigMsg.Write(sigHashes.HashInputAmountsV1[:])
+		sigMsg.Write(sigHashes.HashInputScriptsV1[:])
+		sigMsg.Write(sigHashes.HashSequenceV1[:])
+	}
+
+	// Commit to all outputs unless none or only the one with the same
+	// index are signed.
+	if outputType != SigHashNone && outputType != SigHashSingle {
+		sigMsg.Write(sigHashes.HashOutputsV1[:])
+	}
+
+	// The spend type encodes whether this is a script path spend and
+	// whether an annex is present.
+	var spendType byte
+	if opts.tapLeafHash != nil {
+		spendType |= 0x02
+	}
+	if opts.annex != nil {
+		spendType |= 0x01
+	}
+	sigMsg.WriteByte(spendType)
+
+	// Commit to the input being signed.
+	txIn := tx.TxIn[idx]
+	if anyoneCanPay {
+		var prevOut *wire.TxOut
+		if prevOutFetcher != nil {
+			prevOut = prevOutFetcher.FetchPrevOutput(
+				txIn.PreviousOutPoint)
+		}
+		if prevOut == nil {
+			return nil, fmt.Errorf("output %v spent by input %d is "+
+				"unknown", txIn.PreviousOutPoint, idx)
+		}
+
+		sigMsg.Write(txIn.PreviousOutPoint.Hash[:])
+		binary.LittleEndian.PutUint32(bUint32[:],
+			txIn.PreviousOutPoint.Index)
+		sigMsg.Write(bUint32[:])
+		var bAmount [8]byte
+		binary.LittleEndian.PutUint64(bAmount[:], uint64(prevOut.Value))
+		sigMsg.Write(bAmount[:])
+		wire.WriteVarBytes(&sigMsg, 0, prevOut.PkScript)
+		binary.LittleEndian.PutUint32(bUint32[:], txIn.Sequence)
+		sigMsg.Write(bUint32[:])
+	} else {
+		binary.LittleEndian.PutUint32(bUint32[:], uint32(idx))
+		sigMsg.Write(bUint32[:])
+	}
+
+	if opts.annex != nil {
+		var annex bytes.Buffer
+		wire.WriteVarBytes(&annex, 0, opts.annex)
+		sigMsg.Write(chainhash.HashB(annex.Bytes()))
+	}
+
+	if outputType == SigHashSingle {
+		var output bytes.Buffer
+		wire.WriteTxOut(&output, 0, 0, tx.TxOut[idx])
+		sigMsg.Write(chainhash.HashB(output.Bytes()))
+	}
+
+	// Tapscript signatures additionally commit to the executed leaf, the
+	// key version and the position of the last executed code separator.
+	if opts.tapLeafHash != nil {
+		sigMsg.Write(opts.tapLeafHash)
+		sigMsg.WriteByte(0x00)
+		binary.LittleEndian.PutUint32(bUint32[:], opts.codeSepPos)
+		sigMsg.Write(bUint32[:])
+	}
+
+	return chainhash.TaggedHash(chainhash.TagTapSighash, sigMsg.Bytes())[:], nil
+}
+
+// CalcTaprootSignatureHash computes the BIP0341 signature hash of a key path
+// spend of the specified input of the target transaction observing the desired
+// sig hash type.  The passed fetcher must return the outputs spent by all the
+// inputs of the transaction.
+func CalcTaprootSignatureHash(sigHashes *TxSigHashes, hType SigHashType,
+	tx *wire.MsgTx, idx int, prevOutFetcher PrevOutputFetcher) ([]byte, error) {
+
+	return calcTaprootSignatureHashRaw(sigHashes, hType, tx, idx,
+		prevOutFetcher, &taprootSigHashOptions{})
+}
+
+// CalcTapscriptSignatureHash computes the BIP0342 signature hash of a script
+// path spend of the specified input of the target transaction which executes
+// the script of the passed leaf, observing the desired sig hash type.  The
+// passed fetcher must return the outputs spent by all the inputs of the
+// transaction.
+func CalcTapscriptSignatureHash(sigHashes *TxSigHashes, hType SigHashType,
+	tx *wire.MsgTx, idx int, prevOutFetcher PrevOutputFetcher,
+	tapLeaf TapLeaf) ([]byte, error) {
+
+	tapLeafHash := tapLeaf.TapHash()
+	return calcTaprootSignatureHashRaw(sigHashes, hType, tx, idx,
+		prevOutFetcher, &taprootSigHashOptions{
+			tapLeafHash: tapLeafHash[:],
+			codeSepPos:  blankCodeSepValue,
+		})
+}
+
 // shallowCopyTx creates a shallow copy of the transaction for use when
 // calculating the signature hash.  It is used over the Copy method on the
 // transaction itself since that is a deep copy and therefore does more work and
@@ -42,7 +42,11 @@ const (
 		ScriptVerifyWitness |
 		ScriptVerifyDiscourageUpgradeableWitnessProgram |
 		ScriptVerifyMinimalIf |
-		ScriptVerifyWitnessPubKeyType
+		ScriptVerifyWitnessPubKeyType |
+		ScriptVerifyTaproot |
+		ScriptVerifyDiscourageUpgradeableTaprootVersion |
+		ScriptVerifyDiscourageOpSuccess |
+		ScriptVerifyDiscourageUpgradeablePubkeyType
 )
 
 // ScriptClass is an enumeration for the list of standard types of script.
@@ -387,6 +391,27 @@ func isWitnessScriptHashScript(script []byte) bool {
 	return extractWitnessScriptHash(script) != nil
 }
 
+// extractWitnessV1TaprootProgram extracts the output key from the passed script
+// if it is a standard pay-to-taproot script.  It will return nil otherwise.
+func extractWitnessV1TaprootProgram(script []byte) []byte {
+	// A pay-to-taproot script is of the form:
+	//   OP_1 OP_DATA_32 <32-byte-output-key>
+	if len(script) == 34 &&
+		script[0] == OP_1 &&
+		script[1] == OP_DATA_32 {
+
+		return script[2:34]
+	}
+
+	return nil
+}
+
+// isWitnessTaprootScript returns whether or not the passed script is a
+// standard pay-to-taproot script.
+func isWitnessTaprootScript(script []byte) bool {
+	return extractWitnessV1TaprootProgram(script) != nil
+}
+
 // extractWitnessProgramInfo returns the version and program if the passed
 // script constitutes a valid witness program. The last return value indicates
 // whether or not the script is a valid witness program.
@@ -758,6 +783,13 @@ func payToWitnessScriptHashScript(scriptHash []byte) ([]byte, error) {
 	return NewScriptBuilder().AddOp(OP_0).AddData(scriptHash).Script()
 }
 
+// payToTaprootScript creates a new script to pay to a version 1 witness
+// program with the passed taproot output key.  The passed key is expected to be
+// valid.
+func payToTaprootScript(outputKey []byte) ([]byte, error) {
+	return NewScriptBuilder().AddOp(OP_1).AddData(outputKey).Script()
+}
+
 // payToPubkeyScript creates a new script to pay a transaction output to a
 // public key. It is expected that the input is a valid pubkey.
 func payToPubKeyScript(serializedPubKey []byte) ([]byte, error) {
@@ -804,6 +836,12 @@ func PayToAddrScript(addr btcutil.Address) ([]byte, error) {
 				nilAddrErrStr)
 		}
 		return payToWitnessScriptHashScript(addr.ScriptAddress())
+	case *btcutil.AddressTaproot:
+		if addr == nil {
+			return nil, scriptError(ErrUnsupportedAddress,
+				nilAddrErrStr)
+		}
+		return payToTaprootScript(addr.ScriptAddress())
 	}
 
 	str := fmt.Sprintf("unable to generate payment script for unsupported "+
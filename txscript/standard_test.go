@@ -637,6 +637,13 @@ func TestPayToAddrScript(t *testing.T) {
 			err)
 	}
 
+	p2trMain, err := btcutil.NewAddressTaproot(hexToBytes("a60869f0dbcf1dc"+
+		"659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c"),
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Unable to create taproot address: %v", err)
+	}
+
 	// Errors used in the tests below defined here for convenience and to
 	// keep the horizontal test size shorter.
 	errUnsupportedAddress := scriptError(ErrUnsupportedAddress, "")
@@ -683,11 +690,19 @@ func TestPayToAddrScript(t *testing.T) {
 				"CHECKSIG",
 			nil,
 		},
+		// pay-to-taproot address on mainnet
+		{
+			p2trMain,
+			"1 DATA_32 0xa60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc4" +
+				"87053f1dc6880949dc684c",
+			nil,
+		},
 
 		// Supported address types with nil pointers.
 		{(*btcutil.AddressPubKeyHash)(nil), "", errUnsupportedAddress},
 		{(*btcutil.AddressScriptHash)(nil), "", errUnsupportedAddress},
 		{(*btcutil.AddressPubKey)(nil), "", errUnsupportedAddress},
+		{(*btcutil.AddressTaproot)(nil), "", errUnsupportedAddress},
 
 		// Unsupported address type.
 		{&bogusAddress{}, "", errUnsupportedAddress},
@@ -0,0 +1,238 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TapscriptLeafVersion is the version of the script of a leaf of a taproot
+// script tree.  It determines the semantics the script is executed with.
+type TapscriptLeafVersion uint8
+
+const (
+	// BaseLeafVersion is the leaf version of tapscript, the script language
+	// defined in BIP0342.
+	BaseLeafVersion TapscriptLeafVersion = 0xc0
+
+	// TaprootAnnexTag is the first byte of the annex, an optional last
+	// element of the witness of a taproot spend which is reserved for future
+	// extensions.
+	TaprootAnnexTag = 0x50
+
+	// ControlBlockBaseSize is the size of a control block without any
+	// inclusion proof, that is the leaf version and output key parity byte
+	// followed by the internal key.
+	ControlBlockBaseSize = 33
+
+	// ControlBlockNodeSize is the size of every hash of the inclusion proof
+	// of a control block.
+	ControlBlockNodeSize = 32
+
+	// ControlBlockMaxNodeCount is the maximum number of hashes the
+	// inclusion proof of a control block may contain.
+	ControlBlockMaxNodeCount = 128
+
+	// ControlBlockMaxSize is the maximum size of a control block.
+	ControlBlockMaxSize = ControlBlockBaseSize +
+		ControlBlockNodeSize*ControlBlockMaxNodeCount
+
+	// taprootLeafMask masks the leaf version out of the first byte of a
+	// control block, whose lowest bit is the output key parity.
+	taprootLeafMask = 0xfe
+
+	// sigOpsDelta is the amount the signature check budget of a tapscript
+	// is reduced by for every signature check with a non-empty signature.
+	sigOpsDelta = 50
+
+	// baseSigOpsBudget is the signature check budget every tapscript is
+	// granted in addition to the size of its witness.
+	baseSigOpsBudget = 50
+)
+
+// TapLeaf is a leaf of a taproot script tree.
+type TapLeaf struct {
+	// LeafVersion is the version of the script.
+	LeafVersion TapscriptLeafVersion
+
+	// Script is the script committed to by the leaf.
+	Script []byte
+}
+
+// NewTapLeaf returns a leaf with the passed version and script.
+func NewTapLeaf(leafVersion TapscriptLeafVersion, script []byte) TapLeaf {
+	return TapLeaf{
+		LeafVersion: leafVersion,
+		Script:      script,
+	}
+}
+
+// NewBaseTapLeaf returns a leaf with the passed tapscript.
+func NewBaseTapLeaf(script []byte) TapLeaf {
+	return NewTapLeaf(BaseLeafVersion, script)
+}
+
+// TapHash returns the hash of the leaf, which is the tagged hash of the leaf
+// version followed by the length prefixed script.
+func (t TapLeaf) TapHash() chainhash.Hash {
+	var leaf bytes.Buffer
+	leaf.Grow(1 + wire.VarIntSerializeSize(uint64(len(t.Script))) +
+		len(t.Script))
+	leaf.WriteByte(byte(t.LeafVersion))
+	_ = wire.WriteVarBytes(&leaf, 0, t.Script)
+	return *chainhash.TaggedHash(chainhash.TagTapLeaf, leaf.Bytes())
+}
+
+// TapBranchHash returns the hash of the branch of a taproot script tree with
+// the passed child hashes.  The children are sorted, so the order they are
+// passed in does not matter.
+func TapBranchHash(a, b []byte) chainhash.Hash {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return *chainhash.TaggedHash(chainhash.TagTapBranch, a, b)
+}
+
+// ComputeTaprootOutputKey returns the output key of a taproot output with the
+// passed internal key and the passed root hash of its script tree, which is
+// nil for outputs without a script tree.  Only the x coordinate of the internal
+// key is committed to, so keys with either y coordinate result in the same
+// output key.  An error is returned in the cryptographically negligible case
+// that the tweak is not a valid scalar.
+func ComputeTaprootOutputKey(internalKey *btcec.PublicKey,
+	scriptRoot []byte) (*btcec.PublicKey, error) {
+
+	return schnorr.TweakTaprootPubKey(internalKey, scriptRoot)
+}
+
+// ControlBlock is the parsed last element of the witness of a taproot script
+// path spend.  It proves that the output key commits to the revealed script.
+type ControlBlock struct {
+	// InternalKey is the internal key of the output.
+	InternalKey *btcec.PublicKey
+
+	// OutputKeyYIsOdd is whether the y coordinate of the output key is odd.
+	OutputKeyYIsOdd bool
+
+	// LeafVersion is the leaf version of the revealed script.
+	LeafVersion TapscriptLeafVersion
+
+	// InclusionProof is the concatenation of the hashes of the siblings of
+	// the nodes on the path from the leaf of the revealed script to the
+	// root of the script tree.
+	InclusionProof []byte
+}
+
+// ParseControlBlock parses the passed serialized control block.
+func ParseControlBlock(ctrlBlock []byte) (*ControlBlock, error) {
+	switch {
+	case len(ctrlBlock) < ControlBlockBaseSize:
+		str := fmt.Sprintf("control block of size %d is smaller than "+
+			"the minimum size of %d", len(ctrlBlock),
+			ControlBlockBaseSize)
+		return nil, scriptError(ErrControlBlockTooSmall, str)
+
+	case len(ctrlBlock) > ControlBlockMaxSize,
+		(len(ctrlBlock)-ControlBlockBaseSize)%ControlBlockNodeSize != 0:
+
+		str := fmt.Sprintf("control block of size %d does not contain "+
+			"a valid inclusion proof", len(ctrlBlock))
+		return nil, scriptError(ErrControlBlockInvalidLength, str)
+	}
+
+	internalKey, err := schnorr.ParsePubKey(ctrlBlock[1:ControlBlockBaseSize])
+	if err != nil {
+		str := fmt.Sprintf("invalid control block internal key: %v", err)
+		return nil, scriptError(ErrTaprootMerkleProofInvalid, str)
+	}
+
+	return &ControlBlock{
+		InternalKey:     internalKey,
+		OutputKeyYIsOdd: ctrlBlock[0]&0x01 == 0x01,
+		LeafVersion:     TapscriptLeafVersion(ctrlBlock[0] & taprootLeafMask),
+		InclusionProof:  ctrlBlock[ControlBlockBaseSize:],
+	}, nil
+}
+
+// ToBytes returns the serialization of the control block.
+func (c *ControlBlock) ToBytes() []byte {
+	ctrlBlock := make([]byte, 0, ControlBlockBaseSize+len(c.InclusionProof))
+	leafVersion := byte(c.LeafVersion)
+	if c.OutputKeyYIsOdd {
+		leafVersion |= 0x01
+	}
+	ctrlBlock = append(ctrlBlock, leafVersion)
+	ctrlBlock = append(ctrlBlock, schnorr.SerializePubKey(c.InternalKey)...)
+	return append(ctrlBlock, c.InclusionProof...)
+}
+
+// RootHash returns the root hash of the script tree the inclusion proof of the
+// control block leads to from the leaf of the passed script.
+func (c *ControlBlock) RootHash(revealedScript []byte) []byte {
+	hash := NewTapLeaf(c.LeafVersion, revealedScript).TapHash()
+	for i := 0; i < len(c.InclusionProof); i += ControlBlockNodeSize {
+		node := c.InclusionProof[i : i+ControlBlockNodeSize]
+		hash = TapBranchHash(hash[:], node)
+	}
+	return hash[:]
+}
+
+// VerifyTaprootLeafCommitment returns an error if the passed output key of a
+// taproot output does not commit to the passed script according to the passed
+// control block.
+func VerifyTaprootLeafCommitment(controlBlock *ControlBlock,
+	taprootWitnessProgram []byte, revealedScript []byte) error {
+
+	rootHash := controlBlock.RootHash(revealedScript)
+	outputKey, err := ComputeTaprootOutputKey(controlBlock.InternalKey,
+		rootHash)
+	if err != nil {
+		return scriptError(ErrTaprootMerkleProofInvalid, err.Error())
+	}
+
+	if !bytes.Equal(schnorr.SerializePubKey(outputKey), taprootWitnessProgram) {
+		str := fmt.Sprintf("output key %x does not commit to the "+
+			"revealed script", taprootWitnessProgram)
+		return scriptError(ErrTaprootMerkleProofInvalid, str)
+	}
+
+	if controlBlock.OutputKeyYIsOdd != (outputKey.Y.Bit(0) == 1) {
+		str := "control block output key parity does not match"
+		return scriptError(ErrTaprootOutputKeyParityMismatch, str)
+	}
+
+	return nil
+}
+
+// isAnnexedWitness returns whether the last element of the passed witness of a
+// taproot spend is an annex.
+func isAnnexedWitness(witness wire.TxWitness) bool {
+	if len(witness) < 2 {
+		return false
+	}
+	lastElement := witness[len(witness)-1]
+	return len(lastElement) > 0 && lastElement[0] == TaprootAnnexTag
+}
+
+// spendsTaprootOutput returns whether any of the inputs of the passed
+// transaction spends a pay-to-taproot output according to the passed fetcher.
+func spendsTaprootOutput(tx *wire.MsgTx, prevOutFetcher PrevOutputFetcher) bool {
+	if prevOutFetcher == nil {
+		return false
+	}
+	for _, txIn := range tx.TxIn {
+		prevOut := prevOutFetcher.FetchPrevOutput(txIn.PreviousOutPoint)
+		if prevOut != nil && isWitnessTaprootScript(prevOut.PkScript) {
+			return true
+		}
+	}
+	return false
+}
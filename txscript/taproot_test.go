@@ -0,0 +1,731 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcec/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestTaprootScriptPubKeyVectors ensures the leaf hashes, merkle roots, output
+// keys and control blocks of taproot outputs are calculated according to the
+// scriptPubKey test vectors from BIP0341.
+func TestTaprootScriptPubKeyVectors(t *testing.T) {
+	t.Parallel()
+
+	type leaf struct {
+		version TapscriptLeafVersion
+		script  string
+	}
+	tests := []struct {
+		internalKey   string
+		leaves        []leaf
+		leafHashes    []string
+		merkleRoot    string
+		tweak         string
+		outputKey     string
+		controlBlocks []string
+	}{{
+		internalKey: "d6889cb081036e0faefa3a35157ad71086b123b2b144b649798b494c300a961d",
+		tweak:       "b86e7be8f39bab32a6f2c0443abbc210f0edac0e2c53d501b36b64437d9c6c70",
+		outputKey:   "53a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343",
+	}, {
+		internalKey: "187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27",
+		leaves: []leaf{{
+			version: BaseLeafVersion,
+			script:  "20d85a959b0290bf19bb89ed43c916be835475d013da4b362117393e25a48229b8ac",
+		}},
+		leafHashes:    []string{"5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21"},
+		merkleRoot:    "5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21",
+		tweak:         "cbd8679ba636c1110ea247542cfbd964131a6be84f873f7f3b62a777528ed001",
+		outputKey:     "147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3",
+		controlBlocks: []string{"c1187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27"},
+	}, {
+		internalKey: "93478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820",
+		leaves: []leaf{{
+			version: BaseLeafVersion,
+			script:  "20b617298552a72ade070667e86ca63b8f5789a9fe8731ef91202a91c9f3459007ac",
+		}},
+		leafHashes:    []string{"c525714a7f49c28aedbbba78c005931a81c234b2f6c99a73e4d06082adc8bf2b"},
+		merkleRoot:    "c525714a7f49c28aedbbba78c005931a81c234b2f6c99a73e4d06082adc8bf2b",
+		tweak:         "6af9e28dbf9d6aaf027696e2598a5b3d056f5fd2355a7fd5a37a0e5008132d30",
+		outputKey:     "e4d810fd50586274face62b8a807eb9719cef49c04177cc6b76a9a4251d5450e",
+		controlBlocks: []string{"c093478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820"},
+	}, {
+		internalKey: "ee4fe085983462a184015d1f782d6a5f8b9c2b60130aff050ce221ecf3786592",
+		leaves: []leaf{{
+			version: BaseLeafVersion,
+			script:  "20387671353e273264c495656e27e39ba899ea8fee3bb69fb2a680e22093447d48ac",
+		}, {
+			version: 250,
+			script:  "06424950333431",
+		}},
+		leafHashes: []string{
+			"8ad69ec7cf41c2a4001fd1f738bf1e505ce2277acdcaa63fe4765192497f47a7",
+			"f224a923cd0021ab202ab139cc56802ddb92dcfc172b9212261a539df79a112a",
+		},
+		merkleRoot: "6c2dc106ab816b73f9d07e3cd1ef2c8c1256f519748e0813e4edd2405d277bef",
+		tweak:      "9e0517edc8259bb3359255400b23ca9507f2a91cd1e4250ba068b4eafceba4a9",
+		outputKey:  "712447206d7a5238acc7ff53fbe94a3b64539ad291c7cdbc490b7577e4b17df5",
+		controlBlocks: []string{
+			"c0ee4fe085983462a184015d1f782d6a5f8b9c2b60130aff050ce221ecf3786592f224a923cd0021ab202ab139cc56802ddb92dcfc172b9212261a539df79a112a",
+			"faee4fe085983462a184015d1f782d6a5f8b9c2b60130aff050ce221ecf37865928ad69ec7cf41c2a4001fd1f738bf1e505ce2277acdcaa63fe4765192497f47a7",
+		},
+	}}
+
+	for i, test := range tests {
+		internalKey, err := schnorr.ParsePubKey(hexToBytes(test.internalKey))
+		if err != nil {
+			t.Errorf("#%d: unexpected internal key error: %v", i, err)
+			continue
+		}
+
+		var leafHashes [][]byte
+		for j, leaf := range test.leaves {
+			tapLeaf := NewTapLeaf(leaf.version, hexToBytes(leaf.script))
+			leafHash := tapLeaf.TapHash()
+			if !bytes.Equal(leafHash[:], hexToBytes(test.leafHashes[j])) {
+				t.Errorf("#%d: unexpected leaf hash %x for leaf %d",
+					i, leafHash[:], j)
+			}
+			leafHashes = append(leafHashes, leafHash[:])
+		}
+
+		var merkleRoot []byte
+		switch len(leafHashes) {
+		case 1:
+			merkleRoot = leafHashes[0]
+		case 2:
+			branchHash := TapBranchHash(leafHashes[0], leafHashes[1])
+			merkleRoot = branchHash[:]
+		}
+		if !bytes.Equal(merkleRoot, hexToBytes(test.merkleRoot)) {
+			t.Errorf("#%d: unexpected merkle root %x", i, merkleRoot)
+			continue
+		}
+
+		tweak := chainhash.TaggedHash(chainhash.TagTapTweak,
+			hexToBytes(test.internalKey), merkleRoot)
+		if !bytes.Equal(tweak[:], hexToBytes(test.tweak)) {
+			t.Errorf("#%d: unexpected tweak %x", i, tweak[:])
+		}
+
+		outputKey, err := ComputeTaprootOutputKey(internalKey, merkleRoot)
+		if err != nil {
+			t.Errorf("#%d: unexpected output key error: %v", i, err)
+			continue
+		}
+		outputKeyBytes := schnorr.SerializePubKey(outputKey)
+		if !bytes.Equal(outputKeyBytes, hexToBytes(test.outputKey)) {
+			t.Errorf("#%d: unexpected output key %x", i,
+				outputKeyBytes)
+			continue
+		}
+
+		// Ensure the control blocks round trip and prove that the
+		// output key commits to the scripts of their leaves.
+		for j, ctrlBlockHex := range test.controlBlocks {
+			ctrlBlockBytes := hexToBytes(ctrlBlockHex)
+			ctrlBlock, err := ParseControlBlock(ctrlBlockBytes)
+			if err != nil {
+				t.Errorf("#%d: unexpected control block %d "+
+					"error: %v", i, j, err)
+				continue
+			}
+			if !bytes.Equal(ctrlBlock.ToBytes(), ctrlBlockBytes) {
+				t.Errorf("#%d: control block %d does not round "+
+					"trip", i, j)
+			}
+			script := hexToBytes(test.leaves[j].script)
+			err = VerifyTaprootLeafCommitment(ctrlBlock,
+				outputKeyBytes, script)
+			if err != nil {
+				t.Errorf("#%d: unexpected commitment error for "+
+					"control block %d: %v", i, j, err)
+			}
+		}
+	}
+}
+
+// TestTaprootKeyPathSpendVectors ensures the signature hashes of key path
+// spends are calculated and their signatures are validated according to the
+// keyPathSpending test vectors from BIP0341.
+func TestTaprootKeyPathSpendVectors(t *testing.T) {
+	t.Parallel()
+
+	const rawTx = "02000000097de20cbff686da83a54981d2b9bab3586f4ca7e48f57f" +
+		"5b55963115f3b334e9c010000000000000000d7b7cab57b1393ace2d064f4d4a2" +
+		"cb8af6def61273e127517d44759b6dafdd990000000000fffffffff8e1f583384" +
+		"333689228c5d28eac13366be082dc57441760d957275419a41842000000000" +
+		"0fffffffff0689180aa63b30cb162a73c6d2a38b7eeda2a83ece74310fda0843a" +
+		"d604853b0100000000feffffffaa5202bdf6d8ccd2ee0f0202afbbb7461d9264a" +
+		"25e5bfd3c5a52ee1239e0ba6c0000000000feffffff956149bdc66faa968eb2be" +
+		"2d2faa29718acbfe3941215893a2a3446d32acd050000000000000000000e664b" +
+		"9773b88c09c32cb70a2a3e4da0ced63b7ba3b22f848531bbb1d5d5f4c94010000" +
+		"000000000000e9aa6b8e6c9de67619e6a3924ae25696bb7b694bb677a632a74ef" +
+		"7eadfd4eabf0000000000ffffffffa778eb6a263dc090464cd125c466b5a99667" +
+		"720b1c110468831d058aa1b82af10100000000ffffffff0200ca9a3b000000001" +
+		"976a91406afd46bcdfd22ef94ac122aa11f241244a37ecc88ac807840cb000000" +
+		"0020ac9a87f5594be208f8532db38cff670c450ed2fea8fcdefcc9a663f78bab9" +
+		"62b0065cd1d"
+	tx := wire.NewMsgTx(2)
+	if err := tx.Deserialize(bytes.NewReader(hexToBytes(rawTx))); err != nil {
+		t.Fatalf("unable to deserialize transaction: %v", err)
+	}
+
+	utxos := []struct {
+		pkScript string
+		amount   int64
+	}{
+		{"512053a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343", 420000000},
+		{"5120147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3", 462000000},
+		{"76a914751e76e8199196d454941c45d1b3a323f1433bd688ac", 294000000},
+		{"5120e4d810fd50586274face62b8a807eb9719cef49c04177cc6b76a9a4251d5450e", 504000000},
+		{"512091b64d5324723a985170e4dc5a0f84c041804f2cd12660fa5dec09fc21783605", 630000000},
+		{"00147dd65592d0ab2fe0d0257d571abf032cd9db93dc", 378000000},
+		{"512075169f4001aa68f15bbed28b218df1d0a62cbbcf1188c6665110c293c907b831", 672000000},
+		{"5120712447206d7a5238acc7ff53fbe94a3b64539ad291c7cdbc490b7577e4b17df5", 546000000},
+		{"512077e30a5522dd9f894c3f8b8bd4c4b2cf82ca7da8a3ea6a239655c39c050ab220", 588000000},
+	}
+	prevOutFetcher := NewMultiPrevOutFetcher(nil)
+	for i, utxo := range utxos {
+		prevOutFetcher.AddPrevOut(tx.TxIn[i].PreviousOutPoint, &wire.TxOut{
+			Value:    utxo.amount,
+			PkScript: hexToBytes(utxo.pkScript),
+		})
+	}
+
+	// Ensure the BIP0341 sighash fragments match.
+	sigHashes := NewTxSigHashes(tx, prevOutFetcher)
+	fragments := []struct {
+		name string
+		got  chainhash.Hash
+		want string
+	}{
+		{"hashAmounts", sigHashes.HashInputAmountsV1, "58a6964a4f5f8f0b642ded0a8a553be7622a719da71d1f5befcefcdee8e0fde6"},
+		{"hashOutputs", sigHashes.HashOutputsV1, "a2e6dab7c1f0dcd297c8d61647fd17d821541ea69c3cc37dcbad7f90d4eb4bc5"},
+		{"hashPrevouts", sigHashes.HashPrevOutsV1, "e3b33bb4ef3a52ad1fffb555c0d82828eb22737036eaeb02a235d82b909c4c3f"},
+		{"hashScriptPubkeys", sigHashes.HashInputScriptsV1, "23ad0f61ad2bca5ba6a7693f50fce988e17c3780bf2b1e720cfbb38fbdd52e21"},
+		{"hashSequences", sigHashes.HashSequenceV1, "18959c7221ab5ce9e26c3cd67b22c24f8baa54bac281d8e6b05e400e6c3a957e"},
+	}
+	for _, fragment := range fragments {
+		if !bytes.Equal(fragment.got[:], hexToBytes(fragment.want)) {
+			t.Errorf("unexpected %s %x", fragment.name, fragment.got[:])
+		}
+	}
+
+	tests := []struct {
+		txIdx      int
+		privKey    string
+		merkleRoot string
+		hashType   SigHashType
+		sigHash    string
+		witness    string
+	}{{
+		txIdx:    0,
+		privKey:  "6b973d88838f27366ed61c9ad6367663045cb456e28335c109e30717ae0c6baa",
+		hashType: SigHashSingle,
+		sigHash:  "2514a6272f85cfa0f45eb907fcb0d121b808ed37c6ea160a5a9046ed5526d555",
+		witness:  "ed7c1647cb97379e76892be0cacff57ec4a7102aa24296ca39af7541246d8ff14d38958d4cc1e2e478e4d4a764bbfd835b16d4e314b72937b29833060b87276c03",
+	}, {
+		txIdx:      1,
+		privKey:    "1e4da49f6aaf4e5cd175fe08a32bb5cb4863d963921255f33d3bc31e1343907f",
+		merkleRoot: "5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21",
+		hashType:   SigHashSingle | SigHashAnyOneCanPay,
+		sigHash:    "325a644af47e8a5a2591cda0ab0723978537318f10e6a63d4eed783b96a71a4d",
+		witness:    "052aedffc554b41f52b521071793a6b88d6dbca9dba94cf34c83696de0c1ec35ca9c5ed4ab28059bd606a4f3a657eec0bb96661d42921b5f50a95ad33675b54f83",
+	}, {
+		txIdx:      3,
+		privKey:    "d3c7af07da2d54f7a7735d3d0fc4f0a73164db638b2f2f7c43f711f6d4aa7e64",
+		merkleRoot: "c525714a7f49c28aedbbba78c005931a81c234b2f6c99a73e4d06082adc8bf2b",
+		hashType:   SigHashAll,
+		sigHash:    "bf013ea93474aa67815b1b6cc441d23b64fa310911d991e713cd34c7f5d46669",
+		witness:    "ff45f742a876139946a149ab4d9185574b98dc919d2eb6754f8abaa59d18b025637a3aa043b91817739554f4ed2026cf8022dbd83e351ce1fabc272841d2510a01",
+	}, {
+		txIdx:      4,
+		privKey:    "f36bb07a11e469ce941d16b63b11b9b9120a84d9d87cff2c84a8d4affb438f4e",
+		merkleRoot: "ccbd66c6f7e8fdab47b3a486f59d28262be857f30d4773f2d5ea47f7761ce0e2",
+		hashType:   SigHashDefault,
+		sigHash:    "4f900a0bae3f1446fd48490c2958b5a023228f01661cda3496a11da502a7f7ef",
+		witness:    "b4010dd48a617db09926f729e79c33ae0b4e94b79f04a1ae93ede6315eb3669de185a17d2b0ac9ee09fd4c64b678a0b61a0a86fa888a273c8511be83bfd6810f",
+	}, {
+		txIdx:      6,
+		privKey:    "415cfe9c15d9cea27d8104d5517c06e9de48e2f986b695e4f5ffebf230e725d8",
+		merkleRoot: "2f6b2c5397b6d68ca18e09a3f05161668ffe93a988582d55c6f07bd5b3329def",
+		hashType:   SigHashNone,
+		sigHash:    "15f25c298eb5cdc7eb1d638dd2d45c97c4c59dcaec6679cfc16ad84f30876b85",
+		witness:    "a3785919a2ce3c4ce26f298c3d51619bc474ae24014bcdd31328cd8cfbab2eff3395fa0a16fe5f486d12f22a9cedded5ae74feb4bbe5351346508c5405bcfee002",
+	}, {
+		txIdx:      7,
+		privKey:    "c7b0e81f0a9a0b0499e112279d718cca98e79a12e2f137c72ae5b213aad0d103",
+		merkleRoot: "6c2dc106ab816b73f9d07e3cd1ef2c8c1256f519748e0813e4edd2405d277bef",
+		hashType:   SigHashNone | SigHashAnyOneCanPay,
+		sigHash:    "cd292de50313804dabe4685e83f923d2969577191a3e1d2882220dca88cbeb10",
+		witness:    "ea0c6ba90763c2d3a296ad82ba45881abb4f426b3f87af162dd24d5109edc1cdd11915095ba47c3a9963dc1e6c432939872bc49212fe34c632cd3ab9fed429c482",
+	}, {
+		txIdx:      8,
+		privKey:    "77863416be0d0665e517e1c375fd6f75839544eca553675ef7fdf4949518ebaa",
+		merkleRoot: "ab179431c28d3b68fb798957faf5497d69c883c6fb1e1cd9f81483d87bac90cc",
+		hashType:   SigHashAll | SigHashAnyOneCanPay,
+		sigHash:    "cccb739eca6c13a8a89e6e5cd317ffe55669bbda23f2fd37b0f18755e008edd2",
+		witness:    "bbc9584a11074e83bc8c6759ec55401f0ae7b03ef290c3139814f545b58a9f8127258000874f44bc46db7646322107d4d86aec8e73b8719a61fff761d75b5dd981",
+	}}
+
+	for _, test := range tests {
+		sigHash, err := CalcTaprootSignatureHash(sigHashes, test.hashType,
+			tx, test.txIdx, prevOutFetcher)
+		if err != nil {
+			t.Errorf("input %d: unexpected sighash error: %v",
+				test.txIdx, err)
+			continue
+		}
+		if !bytes.Equal(sigHash, hexToBytes(test.sigHash)) {
+			t.Errorf("input %d: unexpected sighash %x", test.txIdx,
+				sigHash)
+			continue
+		}
+
+		// The vectors are signed without auxiliary randomness.
+		privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(),
+			hexToBytes(test.privKey))
+		var merkleRoot []byte
+		if test.merkleRoot != "" {
+			merkleRoot = hexToBytes(test.merkleRoot)
+		}
+		outputPrivKey, err := schnorr.TweakTaprootPrivKey(privKey,
+			merkleRoot)
+		if err != nil {
+			t.Errorf("input %d: unexpected tweak error: %v",
+				test.txIdx, err)
+			continue
+		}
+		schnorrSig, err := schnorr.Sign(outputPrivKey, sigHash)
+		if err != nil {
+			t.Errorf("input %d: unexpected signing error: %v",
+				test.txIdx, err)
+			continue
+		}
+		sig := schnorrSig.Serialize()
+		if test.hashType != SigHashDefault {
+			sig = append(sig, byte(test.hashType))
+		}
+		if !bytes.Equal(sig, hexToBytes(test.witness)) {
+			t.Errorf("input %d: unexpected witness %x", test.txIdx,
+				sig)
+			continue
+		}
+
+		// Ensure the signed input is valid both with and without
+		// cached sighashes.
+		spendTx := tx.Copy()
+		spendTx.TxIn[test.txIdx].Witness = wire.TxWitness{sig}
+		prevOut := prevOutFetcher.FetchPrevOutput(
+			spendTx.TxIn[test.txIdx].PreviousOutPoint)
+		for _, hashCache := range []*TxSigHashes{nil, sigHashes} {
+			vm, err := NewEngine(prevOut.PkScript, spendTx,
+				test.txIdx, StandardVerifyFlags, nil, hashCache,
+				prevOut.Value, prevOutFetcher)
+			if err == nil {
+				err = vm.Execute()
+			}
+			if err != nil {
+				t.Errorf("input %d: unexpected engine error: %v",
+					test.txIdx, err)
+			}
+		}
+	}
+}
+
+// tapscriptTestCase describes a script path spend of a taproot output used by
+// TestTapscriptExecution.
+type tapscriptTestCase struct {
+	name string
+
+	// leafVersion and script define the revealed leaf.
+	leafVersion TapscriptLeafVersion
+	script      []byte
+
+	// stack returns the witness elements preceding the revealed script
+	// given the signature hash function of the spend.
+	stack func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte
+
+	// annex is the optional annex of the witness.
+	annex []byte
+
+	// flags are the flags to execute the spend with in addition to the
+	// standard verify flags.  noStandard executes it with only the
+	// consensus flags instead.
+	noStandard bool
+
+	// err is the expected error or nil for valid spends.
+	err error
+}
+
+// TestTapscriptExecution ensures the script path spends of taproot outputs are
+// validated according to BIP0341 and the tapscript semantics of BIP0342.
+func TestTapscriptExecution(t *testing.T) {
+	t.Parallel()
+
+	privKey1, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x01}, 32))
+	privKey2, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x02}, 32))
+	internalKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x03}, 32))
+	privKey3, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x04}, 32))
+	pubKey1 := schnorr.SerializePubKey(privKey1.PubKey())
+	pubKey2 := schnorr.SerializePubKey(privKey2.PubKey())
+	pubKey3 := schnorr.SerializePubKey(privKey3.PubKey())
+
+	mustBuild := func(b *ScriptBuilder) []byte {
+		script, err := b.Script()
+		if err != nil {
+			t.Fatalf("unable to build script: %v", err)
+		}
+		return script
+	}
+	checkSigScript := mustBuild(NewScriptBuilder().AddData(pubKey1).
+		AddOp(OP_CHECKSIG))
+	multiSigAddScript := mustBuild(NewScriptBuilder().AddData(pubKey1).
+		AddOp(OP_CHECKSIG).AddData(pubKey2).AddOp(OP_CHECKSIGADD).
+		AddOp(OP_2).AddOp(OP_NUMEQUAL))
+	threshold2of3Script := mustBuild(NewScriptBuilder().AddData(pubKey1).
+		AddOp(OP_CHECKSIG).AddData(pubKey2).AddOp(OP_CHECKSIGADD).
+		AddData(pubKey3).AddOp(OP_CHECKSIGADD).AddOp(OP_2).
+		AddOp(OP_NUMEQUAL))
+
+	tests := []tapscriptTestCase{{
+		name:   "valid checksig",
+		script: checkSigScript,
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{sign(privKey1, SigHashDefault)}
+		},
+	}, {
+		name:   "valid checksig with explicit hash type and annex",
+		script: checkSigScript,
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{sign(privKey1, SigHashSingle|SigHashAnyOneCanPay)}
+		},
+		annex: []byte{TaprootAnnexTag, 0x01},
+	}, {
+		name:   "signature by wrong key",
+		script: checkSigScript,
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{sign(privKey2, SigHashDefault)}
+		},
+		err: scriptError(ErrTaprootSigInvalid, ""),
+	}, {
+		name:   "empty signature",
+		script: checkSigScript,
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{nil}
+		},
+		err: scriptError(ErrEvalFalse, ""),
+	}, {
+		name:   "explicit default hash type",
+		script: checkSigScript,
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{append(sign(privKey1, SigHashDefault), 0x00)}
+		},
+		err: scriptError(ErrInvalidSigHashType, ""),
+	}, {
+		name:   "valid 2-of-2 checksigadd",
+		script: multiSigAddScript,
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{
+				sign(privKey2, SigHashAll),
+				sign(privKey1, SigHashDefault),
+			}
+		},
+	}, {
+		name:   "checksigadd missing signature",
+		script: multiSigAddScript,
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{nil, sign(privKey1, SigHashDefault)}
+		},
+		err: scriptError(ErrEvalFalse, ""),
+	}, {
+		name:   "valid 2-of-3 checksigadd with empty signature",
+		script: threshold2of3Script,
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{
+				sign(privKey3, SigHashDefault),
+				nil,
+				sign(privKey1, SigHashNone),
+			}
+		},
+	}, {
+		name:   "2-of-3 checksigadd with one signature",
+		script: threshold2of3Script,
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{nil, nil, sign(privKey1, SigHashDefault)}
+		},
+		err: scriptError(ErrEvalFalse, ""),
+	}, {
+		name: "checkmultisig disabled",
+		script: mustBuild(NewScriptBuilder().AddOp(OP_1).AddData(pubKey1).
+			AddOp(OP_1).AddOp(OP_CHECKMULTISIG)),
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{nil, sign(privKey1, SigHashDefault)}
+		},
+		err: scriptError(ErrTapscriptCheckMultisig, ""),
+	}, {
+		name: "signature commits to code separator position",
+		script: mustBuild(NewScriptBuilder().AddOp(OP_CODESEPARATOR).
+			AddData(pubKey1).AddOp(OP_CHECKSIG)),
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{sign(privKey1, SigHashDefault)}
+		},
+		err: scriptError(ErrTaprootSigInvalid, ""),
+	}, {
+		name: "empty public key",
+		script: mustBuild(NewScriptBuilder().AddOp(OP_0).
+			AddOp(OP_CHECKSIG)),
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{sign(privKey1, SigHashDefault)}
+		},
+		err: scriptError(ErrTaprootPubkeyIsEmpty, ""),
+	}, {
+		name: "unknown public key type",
+		script: mustBuild(NewScriptBuilder().AddData([]byte{0x01}).
+			AddOp(OP_CHECKSIG)),
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{{0x01}}
+		},
+		err: scriptError(ErrDiscourageUpgradeablePubKeyType, ""),
+	}, {
+		name: "unknown public key type consensus",
+		script: mustBuild(NewScriptBuilder().AddData([]byte{0x01}).
+			AddOp(OP_CHECKSIG)),
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{{0x01}}
+		},
+		noStandard: true,
+	}, {
+		name:   "minimal if",
+		script: mustBuild(NewScriptBuilder().AddOp(OP_IF).AddOp(OP_ENDIF).AddOp(OP_1)),
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{{0x02}}
+		},
+		noStandard: true,
+		err:        scriptError(ErrMinimalIf, ""),
+	}, {
+		name:   "op_success",
+		script: []byte{OP_RETURN, 0x50},
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return nil
+		},
+		noStandard: true,
+	}, {
+		// OP_SUCCESS187 succeeds even in an unexecuted branch.
+		name:   "op_success in unexecuted branch",
+		script: []byte{OP_0, OP_IF, 0xbb, OP_ENDIF, OP_0},
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return nil
+		},
+		noStandard: true,
+	}, {
+		// OP_SUCCESS80 succeeds before the truncated push after it
+		// is decoded.
+		name:   "op_success before undecodable opcode",
+		script: []byte{0x50, OP_PUSHDATA1},
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return nil
+		},
+		noStandard: true,
+	}, {
+		name:   "op_success discouraged",
+		script: []byte{OP_RETURN, 0x50},
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return nil
+		},
+		err: scriptError(ErrDiscourageOpSuccess, ""),
+	}, {
+		name:        "unknown leaf version",
+		leafVersion: 0xc2,
+		script:      []byte{OP_RETURN},
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return nil
+		},
+		noStandard: true,
+	}, {
+		name:        "unknown leaf version discouraged",
+		leafVersion: 0xc2,
+		script:      []byte{OP_RETURN},
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return nil
+		},
+		err: scriptError(ErrDiscourageUpgradeableTaprootVersion, ""),
+	}, {
+		name:   "clean stack",
+		script: []byte{OP_1},
+		stack: func(sign func(*btcec.PrivateKey, SigHashType) []byte) [][]byte {
+			return [][]byte{{0x01}}
+		},
+		noStandard: true,
+		err:        scriptError(ErrEvalFalse, ""),
+	}}
+
+	// consensusFlags are the flags required to validate taproot spends
+	// without any of the policy flags.
+	const consensusFlags = ScriptBip16 | ScriptVerifyWitness |
+		ScriptVerifyTaproot
+
+	for _, test := range tests {
+		leafVersion := test.leafVersion
+		if leafVersion == 0 {
+			leafVersion = BaseLeafVersion
+		}
+
+		// Commit to the leaf of the test along with another leaf so the
+		// control block contains an inclusion proof.
+		tapLeaf := NewTapLeaf(leafVersion, test.script)
+		leafHash := tapLeaf.TapHash()
+		otherLeafHash := NewBaseTapLeaf([]byte{OP_TRUE}).TapHash()
+		rootHash := TapBranchHash(leafHash[:], otherLeafHash[:])
+		outputKey, err := ComputeTaprootOutputKey(internalKey.PubKey(),
+			rootHash[:])
+		if err != nil {
+			t.Fatalf("%s: unexpected output key error: %v", test.name,
+				err)
+		}
+		pkScript := mustBuild(NewScriptBuilder().AddOp(OP_1).
+			AddData(schnorr.SerializePubKey(outputKey)))
+		ctrlBlock := ControlBlock{
+			InternalKey:     internalKey.PubKey(),
+			OutputKeyYIsOdd: outputKey.Y.Bit(0) == 1,
+			LeafVersion:     leafVersion,
+			InclusionProof:  otherLeafHash[:],
+		}
+
+		tx := wire.NewMsgTx(2)
+		tx.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: 1}})
+		tx.AddTxOut(&wire.TxOut{Value: 1000, PkScript: pkScript})
+		prevOutFetcher := NewCannedPrevOutputFetcher(pkScript, 2000)
+		sigHashes := NewTxSigHashes(tx, prevOutFetcher)
+		sign := func(privKey *btcec.PrivateKey, hashType SigHashType) []byte {
+			hash, err := calcTaprootSignatureHashRaw(sigHashes, hashType,
+				tx, 0, prevOutFetcher, &taprootSigHashOptions{
+					annex:       test.annex,
+					tapLeafHash: leafHash[:],
+					codeSepPos:  blankCodeSepValue,
+				})
+			if err != nil {
+				t.Fatalf("%s: unexpected sighash error: %v",
+					test.name, err)
+			}
+			schnorrSig, err := schnorr.Sign(privKey, hash)
+			if err != nil {
+				t.Fatalf("%s: unexpected signing error: %v",
+					test.name, err)
+			}
+			sig := schnorrSig.Serialize()
+			if hashType != SigHashDefault {
+				sig = append(sig, byte(hashType))
+			}
+			return sig
+		}
+
+		witness := wire.TxWitness(test.stack(sign))
+		witness = append(witness, test.script, ctrlBlock.ToBytes())
+		if test.annex != nil {
+			witness = append(witness, test.annex)
+		}
+		tx.TxIn[0].Witness = witness
+
+		flags := StandardVerifyFlags
+		if test.noStandard {
+			flags = consensusFlags
+		}
+		vm, err := NewEngine(pkScript, tx, 0, flags, nil, nil, 2000,
+			prevOutFetcher)
+		if err == nil {
+			err = vm.Execute()
+		}
+
+		if err := tstCheckScriptError(err, test.err); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
+
+// TestTaprootWitnessValidation ensures malformed taproot witnesses are rejected
+// and that version 1 witness programs remain anyone can spend when taproot is
+// not enforced.
+func TestTaprootWitnessValidation(t *testing.T) {
+	t.Parallel()
+
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x04}, 32))
+	outputKey, err := ComputeTaprootOutputKey(privKey.PubKey(), nil)
+	if err != nil {
+		t.Fatalf("unexpected output key error: %v", err)
+	}
+	pkScript := append([]byte{OP_1, OP_DATA_32},
+		schnorr.SerializePubKey(outputKey)...)
+
+	tests := []struct {
+		name    string
+		witness wire.TxWitness
+		flags   ScriptFlags
+		err     error
+	}{{
+		name:  "empty witness",
+		flags: StandardVerifyFlags,
+		err:   scriptError(ErrWitnessProgramEmpty, ""),
+	}, {
+		name:    "invalid signature length",
+		witness: wire.TxWitness{make([]byte, 63)},
+		flags:   StandardVerifyFlags,
+		err:     scriptError(ErrInvalidTaprootSigLen, ""),
+	}, {
+		name:    "invalid signature",
+		witness: wire.TxWitness{make([]byte, 64)},
+		flags:   StandardVerifyFlags,
+		err:     scriptError(ErrTaprootSigInvalid, ""),
+	}, {
+		name:    "invalid hash type",
+		witness: wire.TxWitness{append(make([]byte, 64), 0x04)},
+		flags:   StandardVerifyFlags,
+		err:     scriptError(ErrInvalidSigHashType, ""),
+	}, {
+		name:    "control block too small",
+		witness: wire.TxWitness{{OP_TRUE}, make([]byte, 32)},
+		flags:   StandardVerifyFlags,
+		err:     scriptError(ErrControlBlockTooSmall, ""),
+	}, {
+		name:    "control block invalid length",
+		witness: wire.TxWitness{{OP_TRUE}, make([]byte, 34)},
+		flags:   StandardVerifyFlags,
+		err:     scriptError(ErrControlBlockInvalidLength, ""),
+	}, {
+		name: "control block not committed to",
+		witness: wire.TxWitness{{OP_TRUE}, append([]byte{0xc0},
+			schnorr.SerializePubKey(privKey.PubKey())...)},
+		flags: StandardVerifyFlags,
+		err:   scriptError(ErrTaprootMerkleProofInvalid, ""),
+	}, {
+		name:    "taproot not enforced",
+		witness: wire.TxWitness{make([]byte, 64)},
+		flags:   ScriptBip16 | ScriptVerifyWitness,
+	}}
+
+	for _, test := range tests {
+		tx := wire.NewMsgTx(2)
+		tx.AddTxIn(&wire.TxIn{Witness: test.witness})
+		tx.AddTxOut(&wire.TxOut{Value: 1000})
+		prevOutFetcher := NewCannedPrevOutputFetcher(pkScript, 2000)
+		vm, err := NewEngine(pkScript, tx, 0, test.flags, nil, nil, 2000,
+			prevOutFetcher)
+		if err == nil {
+			err = vm.Execute()
+		}
+
+		if err := tstCheckScriptError(err, test.err); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"strings"
+)
+
+// SigCheck describes the outcome of a signature check performed while
+// executing a script.
+type SigCheck struct {
+	// PubKey is the serialized public key the signature was checked
+	// against.
+	PubKey []byte
+
+	// Signature is the signature which was checked, including its hash
+	// type when present.
+	Signature []byte
+
+	// Valid is whether the signature is valid.
+	Valid bool
+}
+
+// StepTrace describes a single step of the execution of a script by an
+// Engine.  It is passed to the tracer of the engine once the step completes.
+type StepTrace struct {
+	// ScriptIdx and OpcodeIdx identify the opcode which was executed the
+	// same way the disassembly of the engine does.  Index 0 of the scripts
+	// is the signature script and 1 is the public key script, followed by
+	// the redeem or witness script, if any.
+	ScriptIdx int
+	OpcodeIdx int
+
+	// Opcode is the disassembly of the executed opcode along with its data,
+	// if any.  It is empty when no opcode could be executed.
+	Opcode string
+
+	// Stack, AltStack and CondStack are the data, alternate and
+	// conditional execution stacks right after the opcode was executed,
+	// where the last item is the top of the stack.  The entries of the
+	// conditional execution stack are one of OpCondFalse, OpCondTrue or
+	// OpCondSkip.  They are nil when no opcode could be executed.
+	Stack     [][]byte
+	AltStack  [][]byte
+	CondStack []int
+
+	// SigChecks are the outcomes of the signature checks performed during
+	// the step, in the order they were performed.  Note that the signature
+	// check of a taproot key path spend is performed by the step which
+	// completes the public key script.
+	SigChecks []SigCheck
+
+	// Err is the reason the step failed, if any.
+	Err error
+}
+
+// Tracer is the type of function invoked by an Engine after each step of the
+// execution with a trace of that step.  The trace is not accessed by the engine
+// afterwards.
+type Tracer func(step *StepTrace)
+
+// SetTracer sets the tracer invoked after each step of the execution.  A nil
+// tracer disables tracing, which is the default.
+func (vm *Engine) SetTracer(tracer Tracer) {
+	vm.tracer = tracer
+}
+
+// traceOpcode records the opcode executed by the current step in its trace.
+func (vm *Engine) traceOpcode(op *opcode, data []byte) {
+	var buf strings.Builder
+	disasmOpcode(&buf, op, data, false)
+	vm.stepTrace.Opcode = buf.String()
+}
+
+// traceStacks records the current state of the stacks in the trace of the
+// current step.
+func (vm *Engine) traceStacks() {
+	vm.stepTrace.Stack = vm.GetStack()
+	vm.stepTrace.AltStack = vm.GetAltStack()
+	vm.stepTrace.CondStack = make([]int, len(vm.condStack))
+	copy(vm.stepTrace.CondStack, vm.condStack)
+}
+
+// traceSigCheck records the outcome of a signature check in the trace of the
+// current step when the execution is traced.
+func (vm *Engine) traceSigCheck(pubKey, sig []byte, valid bool) {
+	if vm.stepTrace == nil {
+		return
+	}
+	vm.stepTrace.SigChecks = append(vm.stepTrace.SigChecks, SigCheck{
+		PubKey:    pubKey,
+		Signature: sig,
+		Valid:     valid,
+	})
+}
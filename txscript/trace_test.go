@@ -0,0 +1,164 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TestEngineTracer ensures the tracer of an engine is passed a trace of every
+// step with the executed opcode, the resulting stacks, the signature checks and
+// the reason a step failed.
+func TestEngineTracer(t *testing.T) {
+	t.Parallel()
+
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("NewPrivateKey: unexpected error: %v", err)
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	sig, err := privKey.Sign(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Sign: unexpected error: %v", err)
+	}
+	sigBytes := append(sig.Serialize(), byte(SigHashAll))
+
+	// The signature does not commit to the transaction, so the signature
+	// check fails.
+	pkScript, err := NewScriptBuilder().AddOp(OP_IF).AddData(sigBytes).
+		AddData(pubKey).AddOp(OP_CHECKSIG).AddOp(OP_NOT).AddOp(OP_DUP).
+		AddOp(OP_TOALTSTACK).AddOp(OP_ENDIF).Script()
+	if err != nil {
+		t.Fatalf("Script: unexpected error: %v", err)
+	}
+	tx := &wire.MsgTx{
+		Version: 1,
+		TxIn: []*wire.TxIn{{
+			SignatureScript: []byte{OP_TRUE},
+			Sequence:        wire.MaxTxInSequenceNum,
+		}},
+		TxOut: []*wire.TxOut{{Value: 1}},
+	}
+
+	var steps []*StepTrace
+	tracer := func(step *StepTrace) {
+		steps = append(steps, step)
+	}
+	vm, err := NewEngine(pkScript, tx, 0, 0, nil, nil, 0, nil)
+	if err != nil {
+		t.Fatalf("NewEngine: unexpected error: %v", err)
+	}
+	vm.SetTracer(tracer)
+	if err := vm.Execute(); err != nil {
+		t.Fatalf("Execute: unexpected error: %v", err)
+	}
+
+	one := []byte{1}
+	tests := []struct {
+		scriptIdx int
+		opcodeIdx int
+		opcode    string
+		stack     [][]byte
+		altStack  [][]byte
+		condStack []int
+		sigChecks int
+	}{
+		{0, 0, "OP_1", [][]byte{one}, nil, nil, 0},
+		{1, 0, "OP_IF", nil, nil, []int{OpCondTrue}, 0},
+		{1, 3, "OP_CHECKSIG", [][]byte{nil}, nil, []int{OpCondTrue}, 1},
+		{1, 6, "OP_TOALTSTACK", [][]byte{one}, [][]byte{one},
+			[]int{OpCondTrue}, 0},
+		{1, 7, "OP_ENDIF", [][]byte{one}, [][]byte{one}, nil, 0},
+	}
+	if len(steps) != 9 {
+		t.Fatalf("unexpected number of steps: got %d, want 9",
+			len(steps))
+	}
+	for _, test := range tests {
+		var step *StepTrace
+		for _, s := range steps {
+			if s.ScriptIdx == test.scriptIdx &&
+				s.OpcodeIdx == test.opcodeIdx {
+
+				step = s
+				break
+			}
+		}
+		if step == nil {
+			t.Fatalf("missing step %02x:%04x", test.scriptIdx,
+				test.opcodeIdx)
+		}
+		if step.Opcode != test.opcode {
+			t.Fatalf("%s: unexpected opcode %q", test.opcode,
+				step.Opcode)
+		}
+		if !equalStacks(step.Stack, test.stack) ||
+			!equalStacks(step.AltStack, test.altStack) {
+
+			t.Fatalf("%s: unexpected stacks %x and %x", test.opcode,
+				step.Stack, step.AltStack)
+		}
+		if len(step.CondStack) != len(test.condStack) ||
+			(len(test.condStack) != 0 &&
+				!reflect.DeepEqual(step.CondStack, test.condStack)) {
+
+			t.Fatalf("%s: unexpected condition stack %v",
+				test.opcode, step.CondStack)
+		}
+		if len(step.SigChecks) != test.sigChecks {
+			t.Fatalf("%s: unexpected signature checks %v",
+				test.opcode, step.SigChecks)
+		}
+		if step.Err != nil {
+			t.Fatalf("%s: unexpected error %v", test.opcode,
+				step.Err)
+		}
+	}
+	sigCheck := steps[4].SigChecks[0]
+	if sigCheck.Valid || !bytes.Equal(sigCheck.PubKey, pubKey) ||
+		!bytes.Equal(sigCheck.Signature, sigBytes) {
+
+		t.Fatalf("unexpected signature check %+v", sigCheck)
+	}
+
+	// The trace of a failing step includes the reason it failed.
+	tx.TxIn[0].SignatureScript = []byte{OP_FALSE}
+	vm, err = NewEngine([]byte{OP_VERIFY}, tx, 0, 0, nil, nil, 0, nil)
+	if err != nil {
+		t.Fatalf("NewEngine: unexpected error: %v", err)
+	}
+	steps = nil
+	vm.SetTracer(tracer)
+	if err := vm.Execute(); !IsErrorCode(err, ErrVerify) {
+		t.Fatalf("Execute: unexpected error: %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("unexpected number of steps: got %d, want 2",
+			len(steps))
+	}
+	if step := steps[1]; step.Opcode != "OP_VERIFY" ||
+		!IsErrorCode(step.Err, ErrVerify) {
+
+		t.Fatalf("unexpected failing step %+v", step)
+	}
+}
+
+// equalStacks returns whether the passed stacks have the same items.
+func equalStacks(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
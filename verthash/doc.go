@@ -0,0 +1,44 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package verthash implements the Verthash proof-of-work hash used by Vertcoin.
+
+Verthash Overview
+
+Verthash is a memory-hard hash function.  In addition to the block header being
+hashed, every hash reads 4096 pseudo-randomly selected 32-byte chunks from a
+large datafile, commonly named verthash.dat.  The datafile is derived
+deterministically from a public seed, so every node works with identical
+contents, and its SHA256 digest is well known.
+
+This package provides the means to open the datafile, which is memory-mapped
+where the platform supports it so that hashing does not need to keep a copy of
+the file on the heap, to verify its contents against the known digest, and to
+calculate Verthash hashes with it.
+
+Hooking Into Block Validation
+
+A Datafile provides a PowHash method which matches wire.PowHashFunc so it can
+be registered as the hash function for the Verthash proof-of-work algorithm:
+
+	df, err := verthash.Open(path)
+	if err != nil {
+		// Handle error
+	}
+	defer df.Close()
+	if err := df.Verify(); err != nil {
+		// Handle error
+	}
+	wire.RegisterPowHashFunc(wire.PowVerthash, df.PowHash)
+
+Generating the Datafile
+
+The datafile is the graph of a proof of space whose every node is a SHA3-256
+hash of nodes created before it.  Generate creates it and checks the result
+against DatafileSHA256.  The whole datafile is built in memory, so generating
+it needs DatafileSize bytes of memory.  A datafile created by Vertcoin Core is
+identical and may be used instead.
+*/
+package verthash
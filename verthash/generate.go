@@ -0,0 +1,241 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package verthash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// nodeSize is the size of every node of the graph the datafile is made
+	// of, which is the size of a SHA3-256 digest.
+	nodeSize = 32
+
+	// datafileIndex is the index of the graph the datafile is made of,
+	// which determines its size.
+	datafileIndex = 17
+
+	// datafileSeed is the string whose SHA3-256 digest seeds the hashes of
+	// all nodes of the datafile.
+	datafileSeed = "Verthash Proof-of-Space Datafile"
+)
+
+// numXi returns the number of nodes of a graph with the passed index.
+func numXi(index int64) int64 {
+	return (1 << uint64(index)) * (index + 1) * index
+}
+
+// graph builds the proof-of-space graph the datafile consists of.  Every node
+// is the SHA3-256 hash of the seed, the id of the node and the nodes it depends
+// on, which are always created before it.
+//
+// Node ids have the bit above the size of the graph set, so the node with id
+// i is stored at position i &^ pow2 in the data.
+type graph struct {
+	data []byte
+	pow2 int64
+	seed [nodeSize]byte
+	buf  []byte
+}
+
+// node returns the data of the node with the passed id.
+func (g *graph) node(id int64) []byte {
+	offset := (id &^ g.pow2) * nodeSize
+	return g.data[offset : offset+nodeSize]
+}
+
+// newNode creates the node with the passed id from the nodes with the passed
+// parent ids.  The id is hashed as a zero padded signed varint.
+func (g *graph) newNode(id int64, parents ...int64) {
+	var encodedID [nodeSize]byte
+	binary.PutVarint(encodedID[:], id)
+
+	input := append(g.buf[:0], g.seed[:]...)
+	input = append(input, encodedID[:]...)
+	for _, parent := range parents {
+		input = append(input, g.node(parent)...)
+	}
+	digest := sha3.Sum256(input)
+	copy(g.node(id), digest[:])
+	g.buf = input
+}
+
+// butterfly adds a butterfly network with 2^index nodes per level to the
+// graph, which uses the last 2^index nodes created as its first level.  Every
+// node of the following levels depends on the node of the previous level at
+// the same position and on the one whose position differs in a single bit.
+func (g *graph) butterfly(index int64, count *int64) {
+	if index == 0 {
+		index = 1
+	}
+	numLevels := 2 * index
+	perLevel := int64(1) << uint64(index)
+	begin := *count - perLevel
+	for level := int64(1); level < numLevels; level++ {
+		shift := index - level
+		if level > numLevels/2 {
+			shift = level - numLevels/2
+		}
+		for i := int64(0); i < perLevel; i++ {
+			prev := i + 1<<uint64(shift)
+			if (i>>uint64(shift))&1 != 0 {
+				prev = i - 1<<uint64(shift)
+			}
+			g.newNode(*count, begin+(level-1)*perLevel+prev,
+				*count-perLevel)
+			*count++
+		}
+	}
+}
+
+// Stages of building a graph of a given index from two graphs of the index
+// below it.  They are built in this order.
+const (
+	stageCompress = iota
+	stageFirstSources
+	stageSecondSources
+	stageExpand
+	stageSinks
+)
+
+// xiStage is a stage of building a graph of the index on the work stack of
+// build.
+type xiStage struct {
+	index int64
+	stage int
+}
+
+// build creates all nodes of a graph with the passed index.  The graph is
+// defined recursively and built with an explicit stack of the remaining
+// stages of every graph being built, deepest last.
+func (g *graph) build(index int64) {
+	count := g.pow2
+	for i := int64(0); i < 1<<uint64(index); i++ {
+		g.newNode(count)
+		count++
+	}
+	if index == 1 {
+		g.butterfly(index, &count)
+		return
+	}
+
+	pushStages := func(stack []xiStage, index int64) []xiStage {
+		for stage := stageSinks; stage >= stageCompress; stage-- {
+			stack = append(stack, xiStage{index: index, stage: stage})
+		}
+		return stack
+	}
+	stack := pushStages(nil, index)
+	for len(stack) != 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		half := int64(1) << uint64(s.index-1)
+		switch s.stage {
+		case stageCompress:
+			// Combine the sources pairwise into a level half as wide.
+			sources := count - 2*half
+			for i := int64(0); i < half; i++ {
+				g.newNode(count, sources+i, sources+i+half)
+				count++
+			}
+
+		case stageFirstSources, stageSecondSources, stageExpand:
+			// Copy the last level, which is the output of the
+			// previous butterfly or smaller graph, as the input of
+			// the next one.
+			first := count
+			for i := int64(0); i < half; i++ {
+				g.newNode(first+i, first-half+i)
+				count++
+			}
+
+		case stageSinks:
+			// Every sink depends on the last level and on one of the
+			// sources of the graph.
+			sinks := count
+			sources := sinks + 2*half - numXi(s.index)
+			for i := int64(0); i < half; i++ {
+				g.newNode(sinks+i, sinks-half+i, sources+i)
+				g.newNode(sinks+i+half, sinks-half+i, sources+i+half)
+				count += 2
+			}
+		}
+
+		switch {
+		case s.stage == stageCompress || s.stage == stageExpand:
+			g.butterfly(s.index-1, &count)
+
+		case s.stage == stageFirstSources || s.stage == stageSecondSources:
+			if s.index == 2 {
+				g.butterfly(s.index-1, &count)
+			} else {
+				stack = pushStages(stack, s.index-1)
+			}
+		}
+	}
+}
+
+// generateGraph returns the contents of a datafile made of the graph with the
+// passed index.
+func generateGraph(index int64) []byte {
+	size := numXi(index)
+	var log2 uint64
+	for x := size; x > 1; x >>= 1 {
+		log2++
+	}
+	g := &graph{
+		data: make([]byte, size*nodeSize),
+		pow2: 1 << (log2 + 1),
+		seed: sha3.Sum256([]byte(datafileSeed)),
+	}
+	g.build(index)
+	return g.data
+}
+
+// Generate creates the Verthash datafile at the passed path and ensures it
+// matches DatafileSHA256.  The file is written to a temporary file in the same
+// directory first, so an interrupted generation never leaves a partial
+// datafile at the path.
+//
+// Every part of the datafile depends on parts throughout it, so the whole
+// datafile is built in memory, which needs DatafileSize bytes, and takes a
+// while.
+func Generate(path string) error {
+	data := generateGraph(datafileIndex)
+	if sum := sha256.Sum256(data); !bytes.Equal(sum[:], DatafileSHA256) {
+		return fmt.Errorf("%w: generated %x, want %x",
+			ErrDatafileChecksum, sum, DatafileSHA256)
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path),
+		filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
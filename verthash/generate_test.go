@@ -0,0 +1,103 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package verthash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+)
+
+// TestGenerateGraph ensures graphs of small indexes have the expected number
+// of nodes and contents.  The datafile is the graph of index 17, which is
+// checked against DatafileSHA256 by TestDatafile.
+func TestGenerateGraph(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		index  int64
+		sha256 string
+	}{
+		{1, "a4b1b54046506e1da8f47603a885740c3eb66df1c6ff1a05cd2fbbf2b4944ddf"},
+		{2, "669f8d6c7ffac4de22d32fb10964579a1f0098a31d8361dcc8a84c57d59289ad"},
+		{3, "f3e24536c0b9787665939f3d0e5d069cde105d9f023d31473482cbd32a70ce6b"},
+		{10, "505c995309939f06b39b8b78d2c58d7bd7457b0988a883a5d389739bbab6239b"},
+	}
+
+	for _, test := range tests {
+		data := generateGraph(test.index)
+		if want := numXi(test.index) * nodeSize; int64(len(data)) != want {
+			t.Errorf("index %d: unexpected size - got %d, want %d",
+				test.index, len(data), want)
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); got != test.sha256 {
+			t.Errorf("index %d: unexpected sha256 - got %s, want %s",
+				test.index, got, test.sha256)
+		}
+	}
+
+	if numXi(datafileIndex)*nodeSize != DatafileSize {
+		t.Fatalf("graph of index %d does not have the datafile size",
+			datafileIndex)
+	}
+}
+
+// TestDatafile ensures the datafile at the path in the BTCD_VERTHASH_DATAFILE
+// environment variable, which is generated first when it does not exist,
+// matches DatafileSHA256 and produces the expected hashes.  The test is skipped
+// when the variable is not set since generating the datafile takes a while and
+// needs DatafileSize bytes of memory.
+func TestDatafile(t *testing.T) {
+	path := os.Getenv("BTCD_VERTHASH_DATAFILE")
+	if path == "" {
+		t.Skip("BTCD_VERTHASH_DATAFILE is not set")
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := Generate(path); err != nil {
+			t.Fatalf("Generate: unexpected error: %v", err)
+		}
+	}
+	df, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: unexpected error: %v", err)
+	}
+	defer df.Close()
+	if err := df.Verify(); err != nil {
+		t.Fatalf("Verify: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		header []byte
+		hash   string
+	}{
+		{
+			name:   "zero header",
+			header: make([]byte, headerSize),
+			hash:   "7b70a77f737077ee151f5dae7094469ca0063ffdb6ddbae8fe598dd9d6dad06a",
+		},
+		{
+			name:   "test header",
+			header: testHeader(),
+			hash:   "29ad2ec18d9c9f596f2508f311b5797092f9db9b685729f7bda5733a6801f410",
+		},
+	}
+
+	for _, test := range tests {
+		hash, err := df.PowHash(test.header)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if hash.String() != test.hash {
+			t.Errorf("%s: unexpected hash - got %v, want %s",
+				test.name, hash, test.hash)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build windows plan9
+
+package verthash
+
+import (
+	"io/ioutil"
+)
+
+// mapFile reads the entire file at the passed path into memory since memory
+// mapping is not supported on this platform.
+func mapFile(path string) ([]byte, func() error, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package verthash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// DatafileSize is the size in bytes of a valid Verthash datafile.
+	DatafileSize = 1283457024
+
+	// DefaultDatafileName is the name conventionally given to the Verthash
+	// datafile.
+	DefaultDatafileName = "verthash.dat"
+
+	// headerSize is the size of the serialized block header that is hashed.
+	headerSize = 80
+
+	// hashOutSize is the size of the resulting hash and of each chunk
+	// which is read from the datafile.
+	hashOutSize = 32
+
+	// p0Size is the size of each SHA3-512 digest that makes up the seek
+	// index material.
+	p0Size = 64
+
+	// iterations is the number of SHA3-512 digests that make up the seek
+	// index material.
+	iterations = 8
+
+	// subsetSize is the total size of the seek index material.
+	subsetSize = p0Size * iterations
+
+	// rotations is the number of times the seek index material is rotated
+	// in order to produce the full set of seek indexes.
+	rotations = 32
+
+	// numIndexes is the number of datafile chunks read for every hash.
+	numIndexes = 4096
+
+	// byteAlignment is the alignment of the chunks read from the datafile.
+	byteAlignment = 16
+
+	// fnvOffsetBasis and fnvPrime are the 32-bit FNV-1a parameters.
+	fnvOffsetBasis = 0x811c9dc5
+	fnvPrime       = 0x1000193
+)
+
+// DatafileSHA256 is the SHA256 digest of the only valid Verthash datafile.
+var DatafileSHA256 = mustDecodeHex("a55531e843cd56b010114aaf6325b0d529ecf88f8ad47639b6ededafd721aa48")
+
+var (
+	// ErrDatafileSize describes an error where the datafile does not have
+	// the expected size.
+	ErrDatafileSize = errors.New("verthash datafile has an invalid size")
+
+	// ErrDatafileChecksum describes an error where the contents of the
+	// datafile do not match DatafileSHA256.
+	ErrDatafileChecksum = errors.New("verthash datafile checksum mismatch")
+
+	// ErrHeaderSize describes an error where the data to be hashed is not a
+	// serialized block header.
+	ErrHeaderSize = errors.New("verthash input is not a serialized " +
+		"block header")
+)
+
+// mustDecodeHex decodes the passed hex string and panics on failure.  It must
+// only be called with hard-coded values.
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Datafile provides access to the contents of a Verthash datafile and
+// calculates Verthash hashes with it.
+//
+// The methods of a Datafile are safe for concurrent access, however, it must
+// not be used after Close has been called.
+type Datafile struct {
+	data  []byte
+	close func() error
+}
+
+// newDatafile returns a Datafile backed by the passed data.  The contents
+// must be at least hashOutSize bytes and the number of bytes beyond that a
+// multiple of byteAlignment so that every seek lands on a full chunk.
+func newDatafile(data []byte, close func() error) (*Datafile, error) {
+	if len(data) < hashOutSize || (len(data)-hashOutSize)%byteAlignment != 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrDatafileSize, len(data))
+	}
+	return &Datafile{data: data, close: close}, nil
+}
+
+// Open opens the Verthash datafile at the passed path.  The file is
+// memory-mapped where supported and read into memory otherwise.
+//
+// Only the size of the file is checked.  Callers should call Verify before
+// trusting hashes produced with the returned datafile.
+func Open(path string) (*Datafile, error) {
+	data, closeFn, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != DatafileSize {
+		closeFn()
+		return nil, fmt.Errorf("%w: %s is %d bytes, want %d",
+			ErrDatafileSize, path, len(data), DatafileSize)
+	}
+	return newDatafile(data, closeFn)
+}
+
+// Close releases the resources associated with the datafile.
+func (d *Datafile) Close() error {
+	if d.close == nil {
+		return nil
+	}
+	err := d.close()
+	d.close = nil
+	d.data = nil
+	return err
+}
+
+// Verify ensures the contents of the datafile match DatafileSHA256.
+func (d *Datafile) Verify() error {
+	return VerifyReader(bytes.NewReader(d.data))
+}
+
+// VerifyReader ensures the data read from the passed reader until EOF matches
+// DatafileSHA256.  It allows a datafile to be checked without opening it.
+func VerifyReader(r io.Reader) error {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return err
+	}
+	if n != DatafileSize {
+		return fmt.Errorf("%w: %d bytes, want %d", ErrDatafileSize, n,
+			DatafileSize)
+	}
+	if sum := h.Sum(nil); !bytes.Equal(sum, DatafileSHA256) {
+		return fmt.Errorf("%w: got %x, want %x", ErrDatafileChecksum,
+			sum, DatafileSHA256)
+	}
+	return nil
+}
+
+// fnv1a combines the passed values with a single round of 32-bit FNV-1a.
+func fnv1a(a, b uint32) uint32 {
+	return (a ^ b) * fnvPrime
+}
+
+// Sum calculates the Verthash hash of the passed serialized block header.
+func (d *Datafile) Sum(header []byte) ([hashOutSize]byte, error) {
+	var result [hashOutSize]byte
+	if len(header) != headerSize {
+		return result, fmt.Errorf("%w: %d bytes", ErrHeaderSize,
+			len(header))
+	}
+
+	// The seek index material is produced by repeatedly hashing the header
+	// with its first byte incremented before each round.  Work on a copy
+	// so the caller's header is left intact.
+	var input [headerSize]byte
+	copy(input[:], header)
+	var p0 [subsetSize]byte
+	for i := 0; i < iterations; i++ {
+		input[0]++
+		digest := sha3.Sum512(input[:])
+		copy(p0[i*p0Size:], digest[:])
+	}
+
+	// Expand the seek index material into the full set of seek indexes by
+	// appending it repeatedly, rotating every word left by one bit each
+	// time.
+	var p0Index [subsetSize / 4]uint32
+	for i := range p0Index {
+		p0Index[i] = binary.LittleEndian.Uint32(p0[i*4:])
+	}
+	var seekIndexes [numIndexes]uint32
+	for x := 0; x < rotations; x++ {
+		copy(seekIndexes[x*len(p0Index):], p0Index[:])
+		for y := range p0Index {
+			p0Index[y] = p0Index[y]<<1 | p0Index[y]>>31
+		}
+	}
+
+	// The result starts as the SHA3-256 digest of the header and every word
+	// of it is mixed with the words of each chunk read from the datafile.
+	// The location of each chunk depends on all of the chunks read before
+	// it through the value accumulator.
+	p1 := sha3.Sum256(header)
+	var p1Words [hashOutSize / 4]uint32
+	for i := range p1Words {
+		p1Words[i] = binary.LittleEndian.Uint32(p1[i*4:])
+	}
+	data := d.data
+	mdiv := uint32((len(data)-hashOutSize)/byteAlignment) + 1
+	valueAccumulator := uint32(fnvOffsetBasis)
+	for _, seekIndex := range seekIndexes {
+		offset := (fnv1a(seekIndex, valueAccumulator) % mdiv) * byteAlignment
+		chunk := data[offset : offset+hashOutSize]
+		for i := range p1Words {
+			value := binary.LittleEndian.Uint32(chunk[i*4:])
+			p1Words[i] = fnv1a(p1Words[i], value)
+			valueAccumulator = fnv1a(valueAccumulator, value)
+		}
+	}
+
+	for i, word := range p1Words {
+		binary.LittleEndian.PutUint32(result[i*4:], word)
+	}
+	return result, nil
+}
+
+// PowHash calculates the Verthash proof-of-work hash of the passed serialized
+// block header.  It matches the signature of wire.PowHashFunc so it may be
+// registered for wire.PowVerthash.
+func (d *Datafile) PowHash(header []byte) (chainhash.Hash, error) {
+	sum, err := d.Sum(header)
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
+	return chainhash.Hash(sum), nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package verthash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// testHeader returns a deterministic 80-byte input for hashing.
+func testHeader() []byte {
+	header := make([]byte, headerSize)
+	for i := range header {
+		header[i] = byte(i * 7)
+	}
+	return header
+}
+
+// testDatafile returns a Datafile backed by numChunks 16-byte aligned chunks
+// of deterministic data following an initial 32-byte chunk.
+func testDatafile(t *testing.T, numChunks int) *Datafile {
+	data := make([]byte, hashOutSize+numChunks*byteAlignment)
+	for i := 0; i < len(data)/4; i++ {
+		binary.LittleEndian.PutUint32(data[i*4:], uint32(i)*0x9e3779b9)
+	}
+	df, err := newDatafile(data, nil)
+	if err != nil {
+		t.Fatalf("newDatafile: unexpected error: %v", err)
+	}
+	return df
+}
+
+// TestSumSingleChunk ensures the hash is calculated correctly when the
+// datafile only contains a single chunk, in which case every seek reads the
+// same chunk and the result can be derived without the seek indexes.
+func TestSumSingleChunk(t *testing.T) {
+	t.Parallel()
+
+	df := testDatafile(t, 0)
+	header := testHeader()
+
+	p1 := sha3.Sum256(header)
+	var want [hashOutSize]byte
+	for i := 0; i < hashOutSize/4; i++ {
+		word := binary.LittleEndian.Uint32(p1[i*4:])
+		value := binary.LittleEndian.Uint32(df.data[i*4:])
+		for j := 0; j < numIndexes; j++ {
+			word = fnv1a(word, value)
+		}
+		binary.LittleEndian.PutUint32(want[i*4:], word)
+	}
+
+	got, err := df.Sum(header)
+	if err != nil {
+		t.Fatalf("Sum: unexpected error: %v", err)
+	}
+	if got != want {
+		t.Fatalf("Sum: got %x, want %x", got, want)
+	}
+}
+
+// TestSum ensures the hash depends on both the header and the contents of the
+// datafile and that the passed header is not modified.
+func TestSum(t *testing.T) {
+	t.Parallel()
+
+	df := testDatafile(t, 1<<16)
+	header := testHeader()
+	origHeader := append([]byte(nil), header...)
+
+	hash, err := df.Sum(header)
+	if err != nil {
+		t.Fatalf("Sum: unexpected error: %v", err)
+	}
+	if !bytes.Equal(header, origHeader) {
+		t.Fatalf("Sum: header modified - got %x, want %x", header,
+			origHeader)
+	}
+
+	// Hashing again must give the same result.
+	hash2, err := df.Sum(header)
+	if err != nil {
+		t.Fatalf("Sum: unexpected error: %v", err)
+	}
+	if hash != hash2 {
+		t.Fatalf("Sum: nondeterministic result - got %x, want %x", hash2,
+			hash)
+	}
+
+	// A different header must give a different result.
+	header[79] ^= 0x01
+	hash3, err := df.Sum(header)
+	if err != nil {
+		t.Fatalf("Sum: unexpected error: %v", err)
+	}
+	if hash3 == hash {
+		t.Fatal("Sum: header change did not change the hash")
+	}
+	header[79] ^= 0x01
+
+	// A different datafile must give a different result.
+	df2 := testDatafile(t, 1<<16)
+	for i := range df2.data {
+		df2.data[i] ^= 0x5a
+	}
+	hash4, err := df2.Sum(header)
+	if err != nil {
+		t.Fatalf("Sum: unexpected error: %v", err)
+	}
+	if hash4 == hash {
+		t.Fatal("Sum: datafile change did not change the hash")
+	}
+
+	powHash, err := df.PowHash(header)
+	if err != nil {
+		t.Fatalf("PowHash: unexpected error: %v", err)
+	}
+	if [hashOutSize]byte(powHash) != hash {
+		t.Fatalf("PowHash: got %x, want %x", powHash, hash)
+	}
+}
+
+// TestSumErrors ensures inputs which are not serialized block headers and
+// datafiles with invalid sizes are rejected.
+func TestSumErrors(t *testing.T) {
+	t.Parallel()
+
+	df := testDatafile(t, 1)
+	for _, size := range []int{0, headerSize - 1, headerSize + 1} {
+		_, err := df.Sum(make([]byte, size))
+		if !errors.Is(err, ErrHeaderSize) {
+			t.Errorf("Sum(%d bytes): unexpected error - got %v, "+
+				"want %v", size, err, ErrHeaderSize)
+		}
+	}
+
+	for _, size := range []int{0, hashOutSize - 1, hashOutSize + 1} {
+		_, err := newDatafile(make([]byte, size), nil)
+		if !errors.Is(err, ErrDatafileSize) {
+			t.Errorf("newDatafile(%d bytes): unexpected error - got "+
+				"%v, want %v", size, err, ErrDatafileSize)
+		}
+	}
+}
+
+// TestOpenInvalid ensures files which are not valid datafiles are rejected
+// when opened or verified.
+func TestOpenInvalid(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "verthash")
+	if err != nil {
+		t.Fatalf("TempDir: unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, DefaultDatafileName)
+	data := make([]byte, 1024)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("WriteFile: unexpected error: %v", err)
+	}
+
+	if _, err := Open(path); !errors.Is(err, ErrDatafileSize) {
+		t.Fatalf("Open: unexpected error - got %v, want %v", err,
+			ErrDatafileSize)
+	}
+	if _, err := Open(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Fatalf("Open: unexpected error - got %v, want not exist", err)
+	}
+
+	err = VerifyReader(bytes.NewReader(data))
+	if !errors.Is(err, ErrDatafileSize) {
+		t.Fatalf("VerifyReader: unexpected error - got %v, want %v", err,
+			ErrDatafileSize)
+	}
+}
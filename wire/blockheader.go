@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
-    "github.com/bitgoin/lyra2rev2"
 )
 
 // MaxBlockHeaderPayload is the maximum number of bytes a block header can be.
@@ -57,18 +56,23 @@ func (h *BlockHeader) BlockHash() chainhash.Hash {
 	return chainhash.DoubleHashH(buf.Bytes())
 }
 
-// PowHash returns the litecoin scrypt hash of this block header. This value is
-// used to check the PoW on blocks advertised on the network.
-func (h *BlockHeader) PowHash() chainhash.Hash {
-	var powHash chainhash.Hash
+// PowHash returns the proof-of-work hash of this block header calculated with
+// the passed algorithm.  This value is used to check the PoW on blocks
+// advertised on the network.  The algorithm in effect for a block depends on
+// its height and is defined by the chain parameters of the network.
+//
+// ErrPowAlgorithmUnavailable is returned when there is no hash function
+// registered for the algorithm.
+func (h *BlockHeader) PowHash(algo PowAlgorithm) (chainhash.Hash, error) {
+	fn, err := lookupPowHashFunc(algo)
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
 
 	buf := bytes.NewBuffer(make([]byte, 0, MaxBlockHeaderPayload))
 	_ = writeBlockHeader(buf, 0, h)
 
-	lyra2rev2Hash, _ := lyra2rev2.Sum(buf.Bytes())
-	copy(powHash[:], lyra2rev2Hash)
-
-	return powHash
+	return fn(buf.Bytes())
 }
 
 // BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
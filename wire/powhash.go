@@ -0,0 +1,197 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bitgoin/lyra2rev2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/lyra2re"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PowAlgorithm identifies the hash function that is used to calculate the
+// proof-of-work hash of a block header.
+type PowAlgorithm uint8
+
+// These constants define the proof-of-work algorithms Vertcoin has used over
+// its history in the order they were introduced.
+const (
+	// PowScryptN is scrypt with r=1, p=1 and an N parameter that grows with
+	// the timestamp of the block header.
+	PowScryptN PowAlgorithm = iota
+
+	// PowLyra2RE is the original Lyra2RE chained hash.
+	PowLyra2RE
+
+	// PowLyra2REv2 is the Lyra2REv2 chained hash.
+	PowLyra2REv2
+
+	// PowLyra2REv3 is the Lyra2REv3 chained hash.
+	PowLyra2REv3
+
+	// PowVerthash is the memory-hard Verthash algorithm which requires a
+	// large datafile in order to compute.
+	PowVerthash
+)
+
+// powAlgorithmStrings is a map of proof-of-work algorithms back to their
+// constant names for pretty printing.
+var powAlgorithmStrings = map[PowAlgorithm]string{
+	PowScryptN:   "PowScryptN",
+	PowLyra2RE:   "PowLyra2RE",
+	PowLyra2REv2: "PowLyra2REv2",
+	PowLyra2REv3: "PowLyra2REv3",
+	PowVerthash:  "PowVerthash",
+}
+
+// String returns the PowAlgorithm as a human-readable name.
+func (a PowAlgorithm) String() string {
+	if s := powAlgorithmStrings[a]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown PowAlgorithm (%d)", uint8(a))
+}
+
+// ErrPowAlgorithmUnavailable describes an error where the proof-of-work hash
+// of a block header was requested for an algorithm that does not have a hash
+// function registered.
+var ErrPowAlgorithmUnavailable = errors.New("proof-of-work algorithm is " +
+	"not available")
+
+// PowHashFunc calculates the proof-of-work hash of a serialized block header.
+type PowHashFunc func(header []byte) (chainhash.Hash, error)
+
+var (
+	powHashFuncsMtx sync.RWMutex
+	powHashFuncs    = map[PowAlgorithm]PowHashFunc{
+		PowScryptN:   scryptNHash,
+		PowLyra2RE:   lyra2REHash,
+		PowLyra2REv2: lyra2REv2Hash,
+		PowLyra2REv3: lyra2REv3Hash,
+	}
+)
+
+// RegisterPowHashFunc registers the hash function used to calculate the
+// proof-of-work hash for the passed algorithm, replacing any function that was
+// previously registered for it.  This allows algorithms which need external
+// state, such as the Verthash datafile, to be provided by other packages.
+//
+// This function is safe for concurrent access.
+func RegisterPowHashFunc(algo PowAlgorithm, fn PowHashFunc) {
+	powHashFuncsMtx.Lock()
+	powHashFuncs[algo] = fn
+	powHashFuncsMtx.Unlock()
+}
+
+// HavePowHashFunc returns whether or not a hash function is registered for the
+// passed proof-of-work algorithm.
+//
+// This function is safe for concurrent access.
+func HavePowHashFunc(algo PowAlgorithm) bool {
+	powHashFuncsMtx.RLock()
+	_, ok := powHashFuncs[algo]
+	powHashFuncsMtx.RUnlock()
+	return ok
+}
+
+// lookupPowHashFunc returns the hash function registered for the passed
+// proof-of-work algorithm.
+//
+// This function is safe for concurrent access.
+func lookupPowHashFunc(algo PowAlgorithm) (PowHashFunc, error) {
+	powHashFuncsMtx.RLock()
+	fn, ok := powHashFuncs[algo]
+	powHashFuncsMtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrPowAlgorithmUnavailable,
+			algo)
+	}
+	return fn, nil
+}
+
+const (
+	// scryptNChainStartTime is the timestamp the scrypt N factor schedule
+	// is measured from.
+	scryptNChainStartTime = 1389306217
+
+	// scryptNMinNFactor and scryptNMaxNFactor bound the scrypt N factor.
+	// The N parameter is 2^(N factor + 1).
+	scryptNMinNFactor = 10
+	scryptNMaxNFactor = 30
+)
+
+// scryptNFactor returns the scrypt N factor for a block with the passed
+// timestamp.  The factor grows logarithmically with the time elapsed since the
+// start of the chain.
+func scryptNFactor(timestamp int64) uint {
+	if timestamp <= scryptNChainStartTime {
+		return scryptNMinNFactor
+	}
+
+	var l int64
+	s := timestamp - scryptNChainStartTime
+	for (s >> 1) > 3 {
+		l++
+		s >>= 1
+	}
+	s &= 3
+
+	n := (l*158 + s*28 - 2670) / 100
+	switch {
+	case n < scryptNMinNFactor:
+		return scryptNMinNFactor
+	case n > scryptNMaxNFactor:
+		return scryptNMaxNFactor
+	}
+	return uint(n)
+}
+
+// scryptNHash calculates the scrypt-N proof-of-work hash of a serialized block
+// header.  The serialized header is used as both the password and the salt.
+func scryptNHash(header []byte) (chainhash.Hash, error) {
+	timestamp := int64(binary.LittleEndian.Uint32(header[68:72]))
+	n := 1 << (scryptNFactor(timestamp) + 1)
+
+	var powHash chainhash.Hash
+	key, err := scrypt.Key(header, header, n, 1, 1, chainhash.HashSize)
+	if err != nil {
+		return powHash, err
+	}
+	copy(powHash[:], key)
+	return powHash, nil
+}
+
+// lyra2REHash calculates the Lyra2RE proof-of-work hash of a serialized block
+// header.
+func lyra2REHash(header []byte) (chainhash.Hash, error) {
+	var powHash chainhash.Hash
+	copy(powHash[:], lyra2re.Sum(header))
+	return powHash, nil
+}
+
+// lyra2REv2Hash calculates the Lyra2REv2 proof-of-work hash of a serialized
+// block header.
+func lyra2REv2Hash(header []byte) (chainhash.Hash, error) {
+	var powHash chainhash.Hash
+	sum, err := lyra2rev2.Sum(header)
+	if err != nil {
+		return powHash, err
+	}
+	copy(powHash[:], sum)
+	return powHash, nil
+}
+
+// lyra2REv3Hash calculates the Lyra2REv3 proof-of-work hash of a serialized
+// block header.
+func lyra2REv3Hash(header []byte) (chainhash.Hash, error) {
+	var powHash chainhash.Hash
+	copy(powHash[:], lyra2re.SumV3(header))
+	return powHash, nil
+}
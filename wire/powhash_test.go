@@ -0,0 +1,201 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bitgoin/lyra2rev2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/lyra2re"
+)
+
+// vtcGenesisHeader is the header of the Vertcoin main network genesis block,
+// which was mined with scrypt-N.
+var vtcGenesisHeader = BlockHeader{
+	Version: 1,
+	MerkleRoot: chainhash.Hash([chainhash.HashSize]byte{
+		0xe7, 0x23, 0x01, 0xfc, 0x49, 0x32, 0x3e, 0xe1,
+		0x51, 0xcf, 0x10, 0x48, 0x23, 0x0f, 0x03, 0x2c,
+		0xa5, 0x89, 0x75, 0x3b, 0xa7, 0x08, 0x62, 0x22,
+		0xa5, 0xc0, 0x23, 0xe3, 0xa0, 0x8c, 0xf3, 0x4a,
+	}),
+	Timestamp: time.Unix(1389311371, 0),
+	Bits:      0x1e0ffff0,
+	Nonce:     5749262,
+}
+
+// powHashToBig converts a proof-of-work hash into a big.Int for comparisons
+// against a target.
+func powHashToBig(hash *chainhash.Hash) *big.Int {
+	buf := *hash
+	for i := 0; i < chainhash.HashSize/2; i++ {
+		buf[i], buf[chainhash.HashSize-1-i] = buf[chainhash.HashSize-1-i], buf[i]
+	}
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// TestScryptNGenesis ensures the scrypt-N proof-of-work hash of the main
+// network genesis block is calculated correctly and satisfies its claimed
+// target.
+func TestScryptNGenesis(t *testing.T) {
+	t.Parallel()
+
+	hash, err := vtcGenesisHeader.PowHash(PowScryptN)
+	if err != nil {
+		t.Fatalf("PowHash: unexpected error: %v", err)
+	}
+	wantHash, err := chainhash.NewHashFromStr("000005cc425e3c06dd1416866440a70dc9eb4710b2e9c71653c8e197493cbbb9")
+	if err != nil {
+		t.Fatalf("NewHashFromStr: unexpected error: %v", err)
+	}
+	if hash != *wantHash {
+		t.Fatalf("PowHash: got %v, want %v", hash, wantHash)
+	}
+
+	// 0x1e0ffff0 expands to 0x0ffff0 << (8 * (0x1e - 3)).
+	target := new(big.Int).Lsh(big.NewInt(0x0ffff0), 8*(0x1e-3))
+	if powHashToBig(&hash).Cmp(target) > 0 {
+		t.Fatalf("scrypt-N hash %v does not satisfy genesis target %064x",
+			hash, target)
+	}
+}
+
+// TestScryptNFactor ensures the scrypt N factor schedule is clamped to its
+// bounds and grows with the elapsed time.
+func TestScryptNFactor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		timestamp int64
+		want      uint
+	}{
+		{0, scryptNMinNFactor},
+		{scryptNChainStartTime, scryptNMinNFactor},
+		{1389311371, scryptNMinNFactor}, // Genesis block.
+		{1417392000, scryptNMinNFactor}, // December 2014.
+		{scryptNChainStartTime + 1<<26, 11},
+		{scryptNChainStartTime + 1<<40, 30},
+		{scryptNChainStartTime + 1<<62, scryptNMaxNFactor},
+	}
+	for _, test := range tests {
+		got := scryptNFactor(test.timestamp)
+		if got != test.want {
+			t.Errorf("scryptNFactor(%d): got %d, want %d",
+				test.timestamp, got, test.want)
+		}
+	}
+}
+
+// TestBuiltinPowHashFuncs ensures every proof-of-work algorithm apart from
+// Verthash, which needs its datafile, has a hash function registered by default
+// and that the Lyra2 based ones hash the serialized header.
+func TestBuiltinPowHashFuncs(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := vtcGenesisHeader.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	v2, err := lyra2rev2.Sum(buf.Bytes())
+	if err != nil {
+		t.Fatalf("lyra2rev2.Sum: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		algo PowAlgorithm
+		want []byte
+	}{
+		{PowLyra2RE, lyra2re.Sum(buf.Bytes())},
+		{PowLyra2REv2, v2},
+		{PowLyra2REv3, lyra2re.SumV3(buf.Bytes())},
+	}
+	for _, test := range tests {
+		if !HavePowHashFunc(test.algo) {
+			t.Errorf("HavePowHashFunc: no function for %v", test.algo)
+			continue
+		}
+		got, err := vtcGenesisHeader.PowHash(test.algo)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", test.algo, err)
+			continue
+		}
+		if !bytes.Equal(got[:], test.want) {
+			t.Errorf("%v: got %x, want %x", test.algo, got[:],
+				test.want)
+		}
+	}
+	if !HavePowHashFunc(PowScryptN) {
+		t.Errorf("HavePowHashFunc: no function for %v", PowScryptN)
+	}
+}
+
+// TestPowHashRegistry ensures proof-of-work hash functions can be registered
+// and that unavailable algorithms are reported.
+func TestPowHashRegistry(t *testing.T) {
+	// A deliberately unused algorithm identifier is used so the test does
+	// not interfere with the real hash functions.
+	const testAlgo = PowAlgorithm(0xff)
+
+	if HavePowHashFunc(testAlgo) {
+		t.Fatalf("HavePowHashFunc: unexpected function for %v", testAlgo)
+	}
+	_, err := vtcGenesisHeader.PowHash(testAlgo)
+	if !errors.Is(err, ErrPowAlgorithmUnavailable) {
+		t.Fatalf("PowHash: unexpected error - got %v, want %v", err,
+			ErrPowAlgorithmUnavailable)
+	}
+
+	want := chainhash.Hash{0x01, 0x02, 0x03}
+	var gotHeader []byte
+	RegisterPowHashFunc(testAlgo, func(header []byte) (chainhash.Hash, error) {
+		gotHeader = header
+		return want, nil
+	})
+	defer func() {
+		powHashFuncsMtx.Lock()
+		delete(powHashFuncs, testAlgo)
+		powHashFuncsMtx.Unlock()
+	}()
+
+	got, err := vtcGenesisHeader.PowHash(testAlgo)
+	if err != nil {
+		t.Fatalf("PowHash: unexpected error: %v", err)
+	}
+	if got != want {
+		t.Fatalf("PowHash: got %v, want %v", got, want)
+	}
+	if len(gotHeader) != blockHeaderLen {
+		t.Fatalf("PowHash: hash function got %d bytes, want %d",
+			len(gotHeader), blockHeaderLen)
+	}
+}
+
+// TestPowAlgorithmStringer tests the stringized output for the PowAlgorithm
+// type.
+func TestPowAlgorithmStringer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   PowAlgorithm
+		want string
+	}{
+		{PowScryptN, "PowScryptN"},
+		{PowLyra2RE, "PowLyra2RE"},
+		{PowLyra2REv2, "PowLyra2REv2"},
+		{PowLyra2REv3, "PowLyra2REv3"},
+		{PowVerthash, "PowVerthash"},
+		{0xff, "Unknown PowAlgorithm (255)"},
+	}
+	for _, test := range tests {
+		if got := test.in.String(); got != test.want {
+			t.Errorf("String: got %s, want %s", got, test.want)
+		}
+	}
+}